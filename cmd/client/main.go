@@ -5,6 +5,7 @@ import (
 	"os"
 
 	"github.com/YuarenArt/marketgo/internal/app_cmd"
+	"github.com/YuarenArt/marketgo/internal/client"
 
 	"log"
 
@@ -18,9 +19,37 @@ func main() {
 		log.Println("No .env file found or error loading .env")
 	}
 	cfg := config.NewConfig()
+	if err := logging.Validate(cfg); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	// Аргументы после флагов - команда для выполнения без интерактивного режима
+	command := cfg.Args
+	script := cfg.Script != "" || cfg.Commands != ""
 	appLogger := logging.NewLogger(cfg)
+	if len(command) > 0 || script {
+		// stdout остаётся только для результата команды
+		appLogger = logging.NewWriterLogger(os.Stderr, logging.WithConfig(cfg))
+	}
+
+	var opts []client.ClientOption
+	if cfg.ReplayProtection {
+		opts = append(opts, client.WithRequestNonces())
+	}
+	api := client.NewClient(cfg.APIURL, appLogger, opts...)
 
-	if err := app_cmd.NewApp(appLogger, cfg).Run(); err != nil {
+	app := app_cmd.NewApp(api, appLogger, cfg)
+	if script {
+		commands, err := app_cmd.ScriptCommands(cfg.Script, cfg.Commands)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Ошибка: %v\n", err)
+			os.Exit(app_cmd.ExitValidation)
+		}
+		os.Exit(app.RunScript(commands))
+	}
+	if len(command) > 0 {
+		os.Exit(app.RunCommand(command))
+	}
+	if err := app.Run(); err != nil {
 		fmt.Fprintf(os.Stderr, "Ошибка: %v\n", err)
 		os.Exit(1)
 	}
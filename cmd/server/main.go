@@ -2,7 +2,6 @@ package main
 
 import (
 	"context"
-	"fmt"
 	"github.com/YuarenArt/marketgo/pkg/metrics"
 	"log"
 	"os"
@@ -15,14 +14,18 @@ import (
 	"github.com/YuarenArt/marketgo/internal/server"
 	"github.com/YuarenArt/marketgo/internal/server/handlers"
 	"github.com/YuarenArt/marketgo/pkg/logging"
+	"github.com/YuarenArt/marketgo/pkg/webhook"
 	"github.com/joho/godotenv"
 )
 
+// webhookDrainTimeout - сколько ждать отправки накопленных вебхуков после остановки сервера
+const webhookDrainTimeout = 10 * time.Second
+
 // @title Marketplace API
 // @version 1.0
 // @description API для онлайн-маркетплейса с авторизацией и объявлениями
 // @host localhost:8080
-// @BasePath /
+// @BasePath /api/v1
 // @schemes http
 
 // @securityDefinitions.apikey BearerAuth
@@ -39,42 +42,96 @@ func main() {
 	}
 
 	cfg := config.NewConfig()
+	if err := logging.Validate(cfg); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	if err := cfg.DB.Validate(); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	if err := cfg.APILog.Validate(); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	if err := cfg.Webhooks.Validate(); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
 	appLogger := logging.NewLogger(cfg)
-	apiLogger := logging.NewFileLogger("logs/api.log")
+	apiLog, err := logging.NewRotatingWriter(cfg.APILog.Path, logging.RotationFromConfig(cfg.APILog))
+	if err != nil {
+		log.Fatalf("Failed to open API log: %v", err)
+	}
+	apiLogger := logging.NewWriterLogger(apiLog, logging.WithConfig(cfg))
+	var auditLog *logging.RotatingWriter
+	var auditLogger *logging.AuditLogger
+	if cfg.AuditLog {
+		// Журнал аудита не ротируется и не удаляется автоматически: сроки хранения определяет его владелец
+		auditLog, err = logging.NewRotatingWriter(cfg.AuditLogFile, logging.RotateOptions{})
+		if err != nil {
+			log.Fatalf("Failed to open audit log: %v", err)
+		}
+		auditLogger = logging.NewAuditLogger(auditLog)
+	}
 
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
-	dsn := fmt.Sprintf(
-		"postgres://%s:%s@%s:%s/%s?sslmode=disable",
-		cfg.DB.User, cfg.DB.Password,
-		cfg.DB.Host, cfg.DB.Port,
-		cfg.DB.DBName,
-	)
+	dsn := cfg.DB.DSN()
+	replicaDSNs := cfg.DB.ReplicaDSNs()
 
-	handler, err := handlers.NewHandler(
+	metrics := metrics.NewMetrics(nil)
+	handlerOpts := []handlers.HandlerOption{
 		handlers.WithLogger(appLogger),
+		handlers.WithAuditLogger(auditLogger),
+		handlers.WithMetrics(metrics),
+	}
+	// Вебхуки отправляются фоновым обработчиком, который останавливается после HTTP-сервера
+	var hooks *webhook.Dispatcher
+	if len(cfg.Webhooks.URLs) > 0 {
+		hooks = webhook.NewDispatcher(webhook.Config{
+			URLs:        cfg.Webhooks.URLs,
+			Secret:      cfg.Webhooks.Secret,
+			QueueSize:   int(cfg.Webhooks.QueueSize),
+			MaxAttempts: int(cfg.Webhooks.MaxAttempts),
+			Timeout:     cfg.Webhooks.Timeout,
+		}, appLogger)
+		hooks.Start()
+		handlerOpts = append(handlerOpts, handlers.WithAdEvents(hooks))
+	}
+	handler, err := handlers.NewHandler(append(handlerOpts,
 		handlers.WithConfig(ctx, dsn, cfg,
-			db.WithMaxConns(200),
-			db.WithMinConns(20),
-			db.WithConnMaxLifetime(30*time.Minute),
-			db.WithConnIdleLifetime(5*time.Minute),
+			db.WithMaxConns(int32(cfg.DB.MaxConns)),
+			db.WithMinConns(int32(cfg.DB.MinConns)),
+			db.WithConnMaxLifetime(cfg.DB.ConnMaxLifetime),
+			db.WithConnIdleLifetime(cfg.DB.ConnIdleLifetime),
+			db.WithRetry(db.DefaultRetryAttempts, db.DefaultRetryBase),
+			db.WithReadReplicas(replicaDSNs...),
 		),
-	)
+	)...)
 	if err != nil {
 		appLogger.Error("Failed to initialize handler", "error", err)
 		log.Fatal()
 	}
 
-	metrics := metrics.NewMetrics()
 	srv := server.NewServer(cfg, appLogger, apiLogger, handler, metrics)
-	go func() {
-		if err := srv.Start(ctx); err != nil {
-			appLogger.Error("Server error", "error", err)
-			os.Exit(1)
+	// Start возвращается после остановки сервера по ctx, когда запросы уже не пишутся в api.log
+	err = srv.Start(ctx)
+	if hooks != nil {
+		drainCtx, cancel := context.WithTimeout(context.Background(), webhookDrainTimeout)
+		if closeErr := hooks.Close(drainCtx); closeErr != nil {
+			appLogger.Error("Webhook queue was not fully delivered", "error", closeErr)
 		}
-	}()
-
-	<-ctx.Done()
+		cancel()
+	}
+	if closeErr := apiLog.Close(); closeErr != nil {
+		appLogger.Error("Failed to close API log", "error", closeErr)
+	}
+	if auditLog != nil {
+		if closeErr := auditLog.Close(); closeErr != nil {
+			appLogger.Error("Failed to close audit log", "error", closeErr)
+		}
+	}
+	if err != nil {
+		appLogger.Error("Server error", "error", err)
+		os.Exit(1)
+	}
 	appLogger.Info("Server stopped")
 }
@@ -3,11 +3,15 @@ package app_cmd
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"github.com/YuarenArt/marketgo/internal/client"
 	"github.com/YuarenArt/marketgo/internal/config"
 	"github.com/YuarenArt/marketgo/internal/server/services"
+	"github.com/YuarenArt/marketgo/pkg/apierror"
+	"github.com/YuarenArt/marketgo/pkg/buildinfo"
 	"github.com/YuarenArt/marketgo/pkg/logging"
+	"io"
 	"os"
 	"strconv"
 	"strings"
@@ -15,21 +19,103 @@ import (
 
 // App представляет консольное приложение для работы с MarketGo API
 type App struct {
-	client *client.Client
+	client client.ClientAPI
 	logger logging.Logger
+
+	// format - формат вывода результатов команд (FormatPlain, FormatTable или FormatJSON),
+	// out - поток для результатов; ошибки и журнал в него не попадают
+	format string
+	out    io.Writer
+
+	// readPassword запрашивает пароль, не переданный аргументом
+	readPassword passwordPrompt
+
+	// verbose выводит команды сценария перед выполнением, continueOnError не прерывает сценарий на ошибке
+	verbose         bool
+	continueOnError bool
+
+	// feed - параметры ленты, открытой командой feed; Cursor указывает на следующую страницу
+	feed services.GetAdsRequest
+	// listing - последний запрос list-ads, страницы которого листают next и prev;
+	// nil, если последней открыта лента feed
+	listing *services.GetAdsRequest
+
+	// credentials хранит токены между запусками; saved - последние сохранённые токены
+	credentials *credentialsStore
+	saved       credentials
 }
 
-// NewApp создает новое консольное приложение
-func NewApp(logger logging.Logger, cfg *config.Config) *App {
-	return &App{
-		client: client.NewClient(cfg.APIURL, logger),
+// NewApp создает новое консольное приложение, выполняющее команды через api
+func NewApp(api client.ClientAPI, logger logging.Logger, cfg *config.Config) *App {
+	a := &App{
+		client: api,
 		logger: logger,
+		out:    os.Stdout,
+
+		readPassword: terminalPassword,
+
+		verbose:         cfg.Verbose,
+		continueOnError: cfg.ContinueOnError,
 	}
+	format, err := parseFormat(cfg.OutputFormat)
+	if err != nil {
+		logger.Warn("Используется формат вывода plain", "error", err)
+		format = FormatPlain
+	}
+	a.format = format
+	a.loadCredentials(cfg.CredentialsFile)
+	return a
+}
+
+// loadCredentials восстанавливает вход из файла path, сохранённого прошлым запуском.
+// Повреждённый или недоступный файл не мешает работе: приложение запускается без входа.
+func (a *App) loadCredentials(path string) {
+	store, err := newCredentialsStore(path)
+	if err != nil {
+		a.logger.Warn("Токены не будут сохраняться между запусками", "error", err)
+		return
+	}
+	a.credentials = store
+
+	creds, err := store.load()
+	if err != nil {
+		a.logger.Warn("Не удалось прочитать сохранённые токены, требуется вход", "error", err)
+		return
+	}
+	a.client.SetToken(creds.Token)
+	a.client.SetRefreshToken(creds.RefreshToken)
+	a.saved = creds
+}
+
+// saveCredentials сохраняет токены клиента, если они изменились после команды:
+// после login и обновления токенов файл перезаписывается, после logout - удаляется
+func (a *App) saveCredentials() {
+	if a.credentials == nil {
+		return
+	}
+	token, refreshToken := a.client.Tokens()
+	current := credentials{Token: token, RefreshToken: refreshToken}
+	if current == a.saved {
+		return
+	}
+
+	var err error
+	if current == (credentials{}) {
+		err = a.credentials.clear()
+	} else {
+		err = a.credentials.save(current)
+	}
+	if err != nil {
+		a.logger.Warn("Не удалось сохранить токены", "error", err)
+		return
+	}
+	a.saved = current
 }
 
 // Run запускает приложение в интерактивном режиме
 func (a *App) Run() error {
 	fmt.Println("Консольное приложение MarketGo. Введите 'help' для списка команд.")
+	a.printAnnouncements()
 	scanner := bufio.NewScanner(os.Stdin)
 	for {
 		fmt.Print("> ")
@@ -46,14 +132,68 @@ func (a *App) Run() error {
 		}
 		if err := a.executeCommand(input); err != nil {
 			a.logger.Error("Ошибка выполнения команды", "command", input, "error", err)
-			fmt.Fprintf(os.Stderr, "Ошибка: %v\n", err)
+			var command string
+			if args, _ := splitArgs(input); len(args) > 0 {
+				command = args[0]
+			}
+			a.printError(command, err)
 		}
+		a.saveCredentials()
+	}
+}
+
+// RunCommand выполняет одну команду args без интерактивного режима и возвращает код завершения
+// процесса (см. ExitCode). Результат выводится в stdout, ошибки - в stderr.
+func (a *App) RunCommand(args []string) int {
+	err := a.executeArgs(args)
+	a.saveCredentials()
+	if err != nil {
+		a.logger.Error("Ошибка выполнения команды", "command", args[0], "error", err)
+		a.printError(args[0], err)
+	}
+	return ExitCode(err)
+}
+
+// printError выводит ошибку команды command понятным пользователю сообщением
+func (a *App) printError(command string, err error) {
+	var apiErr *client.APIError
+	switch {
+	case errors.As(err, &apiErr) && apiErr.Code == apierror.CodeChallengeRequired:
+		fmt.Fprintf(os.Stderr, "Ошибка: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Сервер требует пройти проверку (%s, ключ сайта %q): введите 'captcha <token>' и повторите команду\n", apiErr.Challenge, apiErr.SiteKey)
+	case errors.Is(err, client.ErrUnauthorized) && command != "login" && command != "register":
+		fmt.Fprintln(os.Stderr, "Ошибка: сессия истекла, выполните login")
+	case errors.Is(err, client.ErrRateLimited):
+		fmt.Fprintln(os.Stderr, "Ошибка: слишком много запросов, повторите позже")
+	default:
+		fmt.Fprintf(os.Stderr, "Ошибка: %v\n", err)
+	}
+}
+
+// printAnnouncements выводит действующие объявления администрации.
+// Ошибки не прерывают работу приложения, так как объявления носят справочный характер.
+func (a *App) printAnnouncements() {
+	announcements, err := a.client.GetAnnouncements(context.Background())
+	if err != nil {
+		a.logger.Warn("Не удалось получить объявления администрации", "error", err)
+		return
+	}
+	for _, an := range announcements {
+		fmt.Printf("[%s] %s\n", strings.ToUpper(an.Severity), an.Message)
 	}
 }
 
 // executeCommand парсит и выполняет команду
 func (a *App) executeCommand(input string) error {
-	args := strings.Fields(input)
+	args, err := splitArgs(input)
+	if err != nil {
+		return fmt.Errorf("разбор команды: %w", err)
+	}
+	return a.executeArgs(args)
+}
+
+// executeArgs выполняет команду args[0] с аргументами args[1:]
+func (a *App) executeArgs(args []string) error {
 	if len(args) == 0 {
 		return nil
 	}
@@ -72,6 +212,42 @@ func (a *App) executeCommand(input string) error {
 		return a.handleCreateAd(args)
 	case "list-ads":
 		return a.handleListAds(args)
+	case "list-my-ads":
+		return a.handleListMyAds(args)
+	case "show-ad":
+		return a.handleShowAd(args)
+	case "update-ad":
+		return a.handleUpdateAd(args)
+	case "delete-ad":
+		return a.handleDeleteAd(args)
+	case "renew-ad":
+		return a.handleRenewAd(args)
+	case "similar":
+		return a.handleSimilar(args)
+	case "favorite":
+		return a.handleFavorite(args)
+	case "unfavorite":
+		return a.handleUnfavorite(args)
+	case "list-favorites":
+		return a.handleListFavorites(args)
+	case "feed":
+		return a.handleFeed(args)
+	case "next":
+		return a.handleNext()
+	case "prev":
+		return a.handlePrev()
+	case "export-ads":
+		return a.handleExportAds(args)
+	case "logout":
+		return a.handleLogout()
+	case "whoami":
+		return a.handleWhoami()
+	case "delete-account":
+		return a.handleDeleteAccount(args)
+	case "captcha":
+		return a.handleCaptcha(args)
+	case "version":
+		return a.handleVersion()
 	default:
 		return fmt.Errorf("неизвестная команда: %s. Введите 'help' для списка команд", command)
 	}
@@ -80,21 +256,46 @@ func (a *App) executeCommand(input string) error {
 // handleHelp выводит справку по командам
 func (a *App) handleHelp() error {
 	fmt.Println(`Доступные команды:
-  register <login> <password> - Регистрация нового пользователя
-  login <login> <password> - Аутентификация пользователя
-  create-ad <title> <text> <price> [image_url] - Создание нового объявления
-  list-ads [page] [page_size] [sort_by] [sort_order] [min_price] [max_price] - Получение списка объявлений
-  exit - Выход из приложения`)
+  register <login> [password] - Регистрация нового пользователя
+  login <login> [password] - Аутентификация пользователя
+  logout - Выход с отзывом токенов
+  create-ad <title> <text> <price> [image_url|file] - Создание нового объявления; локальный файл изображения загружается на сервер
+  whoami - Профиль текущего пользователя
+  delete-account [password] - Удаление своей учётной записи вместе с объявлениями
+  list-ads [page] [page_size] [sort_by] [sort_order] [min_price] [max_price] [city,...] - Получение списка объявлений
+  list-my-ads [page] [page_size] [sort_by] [sort_order] - Получение своих объявлений
+  export-ads <file.csv|-> [page_size] [sort_by] [sort_order] [min_price] [max_price] [city,...] [--bom] - Выгрузка всех объявлений в CSV
+  show-ad <id> - Просмотр объявления
+  update-ad <id> <field=value>... - Изменение своего объявления (поля title, text, price, image_url, tags через запятую, city)
+  delete-ad <id> - Удаление своего объявления
+  renew-ad <id> - Продление публикации своего объявления; архивное снова становится активным
+  similar <id> [limit] - Похожие объявления других продавцов (не больше 20)
+  favorite <id> - Добавление объявления в избранное
+  unfavorite <id> - Удаление объявления из избранного
+  list-favorites [page] [page_size] - Получение избранных объявлений, начиная с добавленных последними
+  feed [page_size] [sort_by] [sort_order] - Лента объявлений с курсорной пагинацией
+  next - Следующая страница ленты или последнего list-ads
+  prev - Предыдущая страница последнего list-ads
+  captcha <token> - Передать токен пройденной CAPTCHA со следующим запросом
+  version - Версия клиента и сервера
+  exit - Выход из приложения
+
+Аргументы с пробелами заключаются в кавычки: create-ad "Детский велосипед" "Почти новый" 150000
+Без аргумента password пароль берётся из MARKETGO_PASSWORD или запрашивается без отображения ввода`)
 	return nil
 }
 
 // handleRegister обрабатывает команду регистрации
 func (a *App) handleRegister(args []string) error {
-	if len(args) < 2 {
-		return fmt.Errorf("команда register требует логин и пароль")
+	if len(args) < 1 {
+		return fmt.Errorf("команда register требует логин")
+	}
+	password, err := a.password(args[1:], true)
+	if err != nil {
+		return err
 	}
 	ctx := context.Background()
-	input := &services.InputUserInfo{Login: args[0], Password: args[1]}
+	input := &services.InputUserInfo{Login: args[0], Password: password}
 	user, err := a.client.Register(ctx, input)
 	if err != nil {
 		return fmt.Errorf("регистрация: %w", err)
@@ -106,11 +307,15 @@ func (a *App) handleRegister(args []string) error {
 
 // handleLogin обрабатывает команду входа
 func (a *App) handleLogin(args []string) error {
-	if len(args) < 2 {
-		return fmt.Errorf("команда login требует логин и пароль")
+	if len(args) < 1 {
+		return fmt.Errorf("команда login требует логин")
+	}
+	password, err := a.password(args[1:], false)
+	if err != nil {
+		return err
 	}
 	ctx := context.Background()
-	input := &services.InputUserInfo{Login: args[0], Password: args[1]}
+	input := &services.InputUserInfo{Login: args[0], Password: password}
 	if err := a.client.Login(ctx, input); err != nil {
 		return fmt.Errorf("вход: %w", err)
 	}
@@ -119,6 +324,54 @@ func (a *App) handleLogin(args []string) error {
 	return nil
 }
 
+// handleWhoami выводит профиль текущего пользователя
+func (a *App) handleWhoami() error {
+	profile, err := a.client.Me(context.Background())
+	if err != nil {
+		return fmt.Errorf("профиль: %w", err)
+	}
+	return a.printProfile(profile)
+}
+
+// handleVersion выводит версию клиента и, если сервер доступен, версию сервера
+func (a *App) handleVersion() error {
+	printBuildInfo("Клиент", buildinfo.Get())
+	info, err := a.client.GetServerVersion(context.Background())
+	if err != nil {
+		a.logger.Warn("Не удалось получить версию сервера", "error", err)
+		fmt.Println("Сервер: недоступен")
+		return nil
+	}
+	printBuildInfo("Сервер", info)
+	return nil
+}
+
+func printBuildInfo(name string, info buildinfo.Info) {
+	fmt.Printf("%s: %s (коммит %s, собран %s, %s)\n", name, info.Version, info.Commit, info.BuildDate, info.GoVersion)
+}
+
+// handleLogout отзывает токены текущего пользователя
+func (a *App) handleLogout() error {
+	if err := a.client.Logout(context.Background()); err != nil {
+		return fmt.Errorf("выход: %w", err)
+	}
+	fmt.Println("Выход выполнен")
+	return nil
+}
+
+// handleDeleteAccount удаляет учётную запись текущего пользователя
+func (a *App) handleDeleteAccount(args []string) error {
+	password, err := a.password(args, false)
+	if err != nil {
+		return err
+	}
+	if err := a.client.DeleteMe(context.Background(), password); err != nil {
+		return fmt.Errorf("удаление учётной записи: %w", err)
+	}
+	fmt.Println("Учётная запись удалена")
+	return nil
+}
+
 // handleCreateAd обрабатывает команду создания объявления
 func (a *App) handleCreateAd(args []string) error {
 	if len(args) < 3 {
@@ -128,11 +381,13 @@ func (a *App) handleCreateAd(args []string) error {
 	if err != nil {
 		return fmt.Errorf("цена должна быть числом: %w", err)
 	}
+	ctx := context.Background()
 	imageURL := ""
 	if len(args) > 3 {
-		imageURL = args[3]
+		if imageURL, err = a.imageURL(ctx, args[3]); err != nil {
+			return err
+		}
 	}
-	ctx := context.Background()
 	req := &services.CreateAdRequest{
 		Title:    args[0],
 		Text:     args[1],
@@ -148,8 +403,330 @@ func (a *App) handleCreateAd(args []string) error {
 	return nil
 }
 
+// imageURL возвращает адрес изображения для объявления: http- и https-адреса передаются как есть,
+// остальное считается путём к локальному файлу, который сначала загружается на сервер
+func (a *App) imageURL(ctx context.Context, value string) (string, error) {
+	if strings.HasPrefix(value, "http://") || strings.HasPrefix(value, "https://") {
+		return value, nil
+	}
+	image, err := a.client.UploadImage(ctx, value)
+	if err != nil {
+		return "", fmt.Errorf("загрузка изображения: %w", err)
+	}
+	a.printInfo("Изображение загружено: %s\n", image.URL)
+	return image.URL, nil
+}
+
 // handleListAds обрабатывает команду получения списка объявлений
 func (a *App) handleListAds(args []string) error {
+	req, err := parseListArgs(args)
+	if err != nil {
+		return err
+	}
+	paged, err := a.fetchAds(req)
+	if err != nil {
+		return err
+	}
+	a.listing = &req
+	a.feed = services.GetAdsRequest{}
+	return a.printPage(paged)
+}
+
+// fetchAds получает страницу объявлений по req
+func (a *App) fetchAds(req services.GetAdsRequest) (services.PagedAds, error) {
+	paged, err := a.client.GetAds(context.Background(), req)
+	if err != nil {
+		return services.PagedAds{}, fmt.Errorf("получение объявлений: %w", err)
+	}
+	a.logger.Info("Объявления получены", "page", req.Page, "count", len(paged.Items))
+	return paged, nil
+}
+
+// printPage выводит страницу объявлений и её номер, если сервер сообщил число страниц
+func (a *App) printPage(paged services.PagedAds) error {
+	if err := a.printAds(paged.Items); err != nil {
+		return err
+	}
+	if paged.TotalPages > 0 {
+		a.printInfo("Страница %d из %d (всего объявлений: %d)\n", paged.Page, paged.TotalPages, paged.Total)
+	}
+	return nil
+}
+
+// turnPage выводит соседнюю страницу последнего list-ads: следующую при delta = 1, предыдущую при -1.
+// Выход за первую или последнюю страницу не считается ошибкой: выводится сообщение, номер страницы не меняется.
+func (a *App) turnPage(delta int) error {
+	req := *a.listing
+	req.Page += delta
+	if req.Page < 1 {
+		fmt.Fprintln(a.out, "Это первая страница")
+		return nil
+	}
+	paged, err := a.fetchAds(req)
+	if err != nil {
+		return err
+	}
+	if len(paged.Items) == 0 && delta > 0 {
+		fmt.Fprintf(a.out, "Страница %d пуста: больше объявлений нет\n", req.Page)
+		return nil
+	}
+	a.listing.Page = req.Page
+	return a.printPage(paged)
+}
+
+// handleListMyAds обрабатывает команду получения своих объявлений
+func (a *App) handleListMyAds(args []string) error {
+	req, err := parsePageArgs(args)
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+	ads, err := a.client.GetMyAds(ctx, req)
+	if err != nil {
+		return fmt.Errorf("получение своих объявлений: %w", err)
+	}
+
+	if err := a.printAds(ads); err != nil {
+		return err
+	}
+	a.logger.Info("Свои объявления получены", "page", req.Page, "count", len(ads))
+	return nil
+}
+
+// handleShowAd выводит объявление по ID
+func (a *App) handleShowAd(args []string) error {
+	id, err := parseAdID("show-ad", args)
+	if err != nil {
+		return err
+	}
+	ad, err := a.client.GetAd(context.Background(), id)
+	if err != nil {
+		return adError(id, err)
+	}
+	return a.printAd(ad)
+}
+
+// handleUpdateAd изменяет поля своего объявления, заданные аргументами вида field=value
+func (a *App) handleUpdateAd(args []string) error {
+	id, err := parseAdID("update-ad", args)
+	if err != nil {
+		return err
+	}
+	if len(args) < 2 {
+		return fmt.Errorf("команда update-ad требует хотя бы одно поле: title=..., text=..., price=..., image_url=..., tags=... или city=...")
+	}
+
+	var req services.UpdateAdRequest
+	for _, arg := range args[1:] {
+		field, value, ok := strings.Cut(arg, "=")
+		if !ok {
+			return fmt.Errorf("аргумент %q должен иметь вид field=value", arg)
+		}
+		switch field {
+		case "title":
+			req.Title = &value
+		case "text":
+			req.Text = &value
+		case "image_url":
+			req.ImageURL = &value
+		case "price":
+			price, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return fmt.Errorf("цена должна быть числом: %w", err)
+			}
+			req.Price = &price
+		case "tags":
+			// Пустое значение удаляет все теги
+			tags := []string{}
+			if value != "" {
+				tags = strings.Split(value, ",")
+			}
+			req.Tags = &tags
+		case "city":
+			// Пустое значение убирает город
+			req.City = &value
+		default:
+			return fmt.Errorf("неизвестное поле %q: допустимы title, text, price, image_url, tags, city", field)
+		}
+	}
+
+	ad, err := a.client.UpdateAd(context.Background(), id, req)
+	if err != nil {
+		return adError(id, err)
+	}
+	fmt.Printf("Объявление изменено: ID=%d, Title=%s, Price=%d\n", ad.ID, ad.Title, ad.Price)
+	return nil
+}
+
+// handleDeleteAd удаляет своё объявление
+func (a *App) handleDeleteAd(args []string) error {
+	id, err := parseAdID("delete-ad", args)
+	if err != nil {
+		return err
+	}
+	if err := a.client.DeleteAd(context.Background(), id); err != nil {
+		return adError(id, err)
+	}
+	fmt.Printf("Объявление %d удалено\n", id)
+	return nil
+}
+
+// handleRenewAd продлевает публикацию своего объявления
+func (a *App) handleRenewAd(args []string) error {
+	id, err := parseAdID("renew-ad", args)
+	if err != nil {
+		return err
+	}
+	ad, err := a.client.RenewAd(context.Background(), id)
+	if err != nil {
+		return adError(id, err)
+	}
+	fmt.Fprintf(a.out, "Объявление %d продлено до %s\n", ad.ID, ad.ExpiresAt.Local().Format("2006-01-02 15:04"))
+	return nil
+}
+
+// handleSimilar выводит объявления, похожие на указанное
+func (a *App) handleSimilar(args []string) error {
+	id, err := parseAdID("similar", args)
+	if err != nil {
+		return err
+	}
+	limit := 0
+	if len(args) > 1 {
+		limit, err = strconv.Atoi(args[1])
+		if err != nil || limit <= 0 {
+			return fmt.Errorf("limit должен быть положительным числом: %q", args[1])
+		}
+	}
+	ads, err := a.client.GetSimilarAds(context.Background(), id, limit)
+	if err != nil {
+		return adError(id, err)
+	}
+
+	if err := a.printAds(ads); err != nil {
+		return err
+	}
+	a.logger.Info("Похожие объявления получены", "ad_id", id, "count", len(ads))
+	return nil
+}
+
+// handleFavorite добавляет объявление в избранное
+func (a *App) handleFavorite(args []string) error {
+	id, err := parseAdID("favorite", args)
+	if err != nil {
+		return err
+	}
+	if err := a.client.FavoriteAd(context.Background(), id); err != nil {
+		return adError(id, err)
+	}
+	fmt.Fprintf(a.out, "Объявление %d добавлено в избранное\n", id)
+	return nil
+}
+
+// handleUnfavorite убирает объявление из избранного
+func (a *App) handleUnfavorite(args []string) error {
+	id, err := parseAdID("unfavorite", args)
+	if err != nil {
+		return err
+	}
+	if err := a.client.UnfavoriteAd(context.Background(), id); err != nil {
+		return adError(id, err)
+	}
+	fmt.Fprintf(a.out, "Объявление %d убрано из избранного\n", id)
+	return nil
+}
+
+// handleListFavorites обрабатывает команду получения избранных объявлений
+func (a *App) handleListFavorites(args []string) error {
+	req, err := parsePageArgs(args)
+	if err != nil {
+		return err
+	}
+	ads, err := a.client.GetFavorites(context.Background(), req)
+	if err != nil {
+		return fmt.Errorf("получение избранного: %w", err)
+	}
+
+	if err := a.printAds(ads); err != nil {
+		return err
+	}
+	a.logger.Info("Избранное получено", "page", req.Page, "count", len(ads))
+	return nil
+}
+
+// parseAdID разбирает ID объявления из первого аргумента команды command
+func parseAdID(command string, args []string) (int, error) {
+	if len(args) < 1 {
+		return 0, fmt.Errorf("команда %s требует ID объявления", command)
+	}
+	id, err := strconv.Atoi(args[0])
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("ID объявления должен быть положительным числом: %q", args[0])
+	}
+	return id, nil
+}
+
+// adError поясняет ошибку операции с объявлением id
+func adError(id int, err error) error {
+	switch {
+	case errors.Is(err, client.ErrNotFound):
+		return fmt.Errorf("объявление %d не найдено: %w", id, err)
+	case errors.Is(err, client.ErrForbidden):
+		return fmt.Errorf("объявление %d принадлежит другому пользователю: %w", id, err)
+	default:
+		return fmt.Errorf("объявление %d: %w", id, err)
+	}
+}
+
+// handleFeed открывает ленту объявлений с курсорной пагинацией и выводит первую страницу
+func (a *App) handleFeed(args []string) error {
+	req, err := parsePageArgs(append([]string{"1"}, args...))
+	if err != nil {
+		return err
+	}
+	a.feed = req
+	a.listing = nil
+	return a.fetchFeed()
+}
+
+// handleNext выводит следующую страницу последнего list-ads или ленты, открытой командой feed
+func (a *App) handleNext() error {
+	if a.listing != nil {
+		return a.turnPage(1)
+	}
+	if a.feed.Cursor == "" {
+		return fmt.Errorf("нет следующей страницы: выполните list-ads или откройте ленту командой feed")
+	}
+	return a.fetchFeed()
+}
+
+// handlePrev выводит предыдущую страницу последнего list-ads
+func (a *App) handlePrev() error {
+	if a.listing == nil {
+		return fmt.Errorf("нет предыдущей страницы: сначала выполните list-ads")
+	}
+	return a.turnPage(-1)
+}
+
+func (a *App) fetchFeed() error {
+	page, err := a.client.GetAdsAfterCursor(context.Background(), a.feed)
+	if err != nil {
+		return fmt.Errorf("получение ленты: %w", err)
+	}
+
+	if err := a.printAds(page.Ads); err != nil {
+		return err
+	}
+	a.feed.Cursor = page.NextCursor
+	if page.NextCursor != "" {
+		a.printInfo("Следующая страница: next\n")
+	}
+	a.logger.Info("Лента получена", "count", len(page.Ads))
+	return nil
+}
+
+// parsePageArgs разбирает аргументы [page] [page_size] [sort_by] [sort_order]
+func parsePageArgs(args []string) (services.GetAdsRequest, error) {
 	req := services.GetAdsRequest{
 		Page:      1,
 		PageSize:  10,
@@ -159,14 +736,14 @@ func (a *App) handleListAds(args []string) error {
 	if len(args) > 0 {
 		page, err := strconv.Atoi(args[0])
 		if err != nil {
-			return fmt.Errorf("page должен быть числом: %w", err)
+			return req, fmt.Errorf("page должен быть числом: %w", err)
 		}
 		req.Page = page
 	}
 	if len(args) > 1 {
 		pageSize, err := strconv.Atoi(args[1])
 		if err != nil {
-			return fmt.Errorf("page_size должен быть числом: %w", err)
+			return req, fmt.Errorf("page_size должен быть числом: %w", err)
 		}
 		req.PageSize = pageSize
 	}
@@ -176,52 +753,41 @@ func (a *App) handleListAds(args []string) error {
 	if len(args) > 3 {
 		req.SortOrder = args[3]
 	}
+	return req, nil
+}
+
+// parseListArgs разбирает аргументы list-ads: параметры страницы, диапазон цен и города через запятую
+func parseListArgs(args []string) (services.GetAdsRequest, error) {
+	req, err := parsePageArgs(args)
+	if err != nil {
+		return req, err
+	}
 	if len(args) > 4 {
 		minPrice, err := strconv.ParseInt(args[4], 10, 64)
 		if err != nil {
-			return fmt.Errorf("min_price должен быть числом: %w", err)
+			return req, fmt.Errorf("min_price должен быть числом: %w", err)
 		}
 		req.MinPrice = minPrice
 	}
 	if len(args) > 5 {
 		maxPrice, err := strconv.ParseInt(args[5], 10, 64)
 		if err != nil {
-			return fmt.Errorf("max_price должен быть числом: %w", err)
+			return req, fmt.Errorf("max_price должен быть числом: %w", err)
 		}
 		req.MaxPrice = maxPrice
 	}
-	ctx := context.Background()
-	ads, err := a.client.GetAds(ctx, req)
-	if err != nil {
-		return fmt.Errorf("получение объявлений: %w", err)
-	}
-
-	if len(ads) == 0 {
-		fmt.Println("Объявления не найдены.")
-		a.logger.Info("Объявления получены", "page", req.Page, "count", len(ads))
-		return nil
+	if len(args) > 6 {
+		req.Cities = strings.Split(args[6], ",")
 	}
+	return req, nil
+}
 
-	fmt.Printf("Найдено объявлений: %d\n\n", len(ads))
-	for i, ad := range ads {
-		// Форматируем дату
-		createdAt := ad.CreatedAt.Format("2006-01-02 15:04:05")
-		// Выводим блок для каждого объявления
-		fmt.Println("=============================================================")
-		fmt.Printf("Объявление %d\n", ad.ID)
-		fmt.Println("-------------------------------------------------------------")
-		fmt.Printf("Заголовок:      %s\n", ad.Title)
-		fmt.Printf("Текст:          %s\n", ad.Text)
-		fmt.Printf("Цена:           %d\n", ad.Price)
-		fmt.Printf("URL изображения:%s\n", ad.ImageURL)
-		fmt.Printf("Создано:        %s\n", createdAt)
-		fmt.Println("=============================================================")
-		// Добавляем пустую строку между объявлениями, кроме последнего
-		if i < len(ads)-1 {
-			fmt.Println()
-		}
+// handleCaptcha сохраняет токен CAPTCHA для следующего запроса
+func (a *App) handleCaptcha(args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("команда captcha требует токен")
 	}
-
-	a.logger.Info("Объявления получены", "page", req.Page, "count", len(ads))
+	a.client.SetCaptchaToken(args[0])
+	fmt.Println("Токен сохранён, повторите команду")
 	return nil
 }
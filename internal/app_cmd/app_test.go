@@ -0,0 +1,106 @@
+package app_cmd
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/YuarenArt/marketgo/internal/client"
+	"github.com/YuarenArt/marketgo/internal/config"
+	"github.com/YuarenArt/marketgo/pkg/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAdsServer имитирует эндпоинты объявлений: объявление 1 принадлежит пользователю,
+// объявление 2 - другому пользователю, остальных нет
+func fakeAdsServer(t *testing.T, requests *[]string, bodies *[]map[string]any) *httptest.Server {
+	mux := http.NewServeMux()
+	ad := `{"id": 1, "title": "Велосипед", "text": "Горный", "price": 300, "image_url": "https://example.com/1.jpg"}`
+	mux.HandleFunc("/api/v1/ads/1", func(w http.ResponseWriter, r *http.Request) {
+		*requests = append(*requests, r.Method+" "+r.URL.Path)
+		if r.Method == http.MethodPatch {
+			var body map[string]any
+			data, _ := io.ReadAll(r.Body)
+			_ = json.Unmarshal(data, &body)
+			*bodies = append(*bodies, body)
+		}
+		if r.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(ad))
+	})
+	mux.HandleFunc("/api/v1/ads/2", func(w http.ResponseWriter, r *http.Request) {
+		*requests = append(*requests, r.Method+" "+r.URL.Path)
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"code": "forbidden", "message": "insufficient permissions"}`))
+	})
+	mux.HandleFunc("/api/v1/ads/", func(w http.ResponseWriter, r *http.Request) {
+		*requests = append(*requests, r.Method+" "+r.URL.Path)
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"code": "not_found", "message": "объявление не найдено"}`))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestAdCommands(t *testing.T) {
+	var requests []string
+	var bodies []map[string]any
+	srv := fakeAdsServer(t, &requests, &bodies)
+	app := NewApp(client.NewClient(srv.URL, logging.NewLogger(nil)), logging.NewLogger(nil), &config.Config{CredentialsFile: filepath.Join(t.TempDir(), "credentials.json")})
+
+	t.Run("show-ad", func(t *testing.T) {
+		requests = nil
+		require.NoError(t, app.executeCommand("show-ad 1"))
+		assert.Equal(t, []string{"GET /api/v1/ads/1"}, requests)
+	})
+
+	t.Run("update-ad sends only given fields", func(t *testing.T) {
+		requests, bodies = nil, nil
+		require.NoError(t, app.executeCommand("update-ad 1 title=Самокат price=150"))
+		assert.Equal(t, []string{"PATCH /api/v1/ads/1"}, requests)
+		require.Len(t, bodies, 1)
+		assert.Equal(t, map[string]any{"title": "Самокат", "price": 150.0}, bodies[0])
+	})
+
+	t.Run("delete-ad", func(t *testing.T) {
+		requests = nil
+		require.NoError(t, app.executeCommand("delete-ad 1"))
+		assert.Equal(t, []string{"DELETE /api/v1/ads/1"}, requests)
+	})
+
+	t.Run("missing ad", func(t *testing.T) {
+		err := app.executeCommand("show-ad 42")
+		assert.ErrorIs(t, err, client.ErrNotFound)
+		assert.Contains(t, err.Error(), "объявление 42 не найдено")
+	})
+
+	t.Run("foreign ad", func(t *testing.T) {
+		err := app.executeCommand("delete-ad 2")
+		assert.ErrorIs(t, err, client.ErrForbidden)
+		assert.Contains(t, err.Error(), "принадлежит другому пользователю")
+	})
+
+	t.Run("invalid arguments are rejected without a request", func(t *testing.T) {
+		requests = nil
+		for _, input := range []string{
+			"show-ad",
+			"show-ad abc",
+			"delete-ad -1",
+			"update-ad 1",
+			"update-ad 1 title",
+			"update-ad 1 color=red",
+			"update-ad 1 price=cheap",
+		} {
+			assert.Error(t, app.executeCommand(input), input)
+		}
+		assert.Empty(t, requests)
+	})
+}
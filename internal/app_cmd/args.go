@@ -0,0 +1,99 @@
+package app_cmd
+
+import (
+	"errors"
+	"strings"
+	"unicode"
+)
+
+// splitArgs разбивает строку команды на аргументы по пробелам с учётом кавычек, как оболочка:
+// внутри двойных кавычек обратная косая черта экранирует " и \, внутри одинарных всё берётся
+// как есть, а вне кавычек \ экранирует любой следующий символ. Пустые кавычки дают пустой аргумент.
+func splitArgs(input string) ([]string, error) {
+	var (
+		args    []string
+		current strings.Builder
+		inArg   bool
+		quote   rune
+		escaped bool
+	)
+
+	for _, r := range input {
+		switch {
+		case escaped:
+			if quote == '"' && r != '"' && r != '\\' {
+				current.WriteRune('\\')
+			}
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped, inArg = true, true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote, inArg = r, true
+		case unicode.IsSpace(r):
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	switch {
+	case escaped:
+		return nil, errors.New("строка команды заканчивается на \\")
+	case quote != 0:
+		return nil, errors.New("незакрытая кавычка " + string(quote))
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
+
+// splitCommands разбивает строку на команды по ";" и переводам строк вне кавычек.
+// Кавычки и экранирование сохраняются для splitArgs; пустые команды пропускаются.
+func splitCommands(input string) []string {
+	var (
+		commands []string
+		current  strings.Builder
+		quote    rune
+		escaped  bool
+	)
+	flush := func() {
+		if command := strings.TrimSpace(current.String()); command != "" {
+			commands = append(commands, command)
+		}
+		current.Reset()
+	}
+
+	for _, r := range input {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == ';' || r == '\n':
+			flush()
+			continue
+		}
+		current.WriteRune(r)
+	}
+	flush()
+	return commands
+}
@@ -0,0 +1,62 @@
+package app_cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/YuarenArt/marketgo/internal/client/clienttest"
+	"github.com/YuarenArt/marketgo/internal/db"
+	"github.com/YuarenArt/marketgo/internal/server/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnitSplitArgs(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{"plain words", "list-ads 1  10", []string{"list-ads", "1", "10"}},
+		{"title with spaces", `create-ad "Детский велосипед" "Почти новый, самовывоз" 150000`,
+			[]string{"create-ad", "Детский велосипед", "Почти новый, самовывоз", "150000"}},
+		{"single quotes", `create-ad 'Стол "Лофт"' 'Дуб, 120 см' 9000`, []string{"create-ad", `Стол "Лофт"`, "Дуб, 120 см", "9000"}},
+		{"escaped quote in double quotes", `create-ad "Книга \"Мастер и Маргарита\"" Букинист 500`,
+			[]string{"create-ad", `Книга "Мастер и Маргарита"`, "Букинист", "500"}},
+		{"backslash kept in double quotes", `create-ad "C:\path" текст 1`, []string{"create-ad", `C:\path`, "текст", "1"}},
+		{"backslash in single quotes is literal", `x 'a\'`, []string{"x", `a\`}},
+		{"escaped space outside quotes", `create-ad Детский\ велосипед текст 1`, []string{"create-ad", "Детский велосипед", "текст", "1"}},
+		{"empty quoted strings", `update-ad 1 "" ''`, []string{"update-ad", "1", "", ""}},
+		{"quotes join adjacent text", `update-ad 1 title="Новый заголовок"`, []string{"update-ad", "1", "title=Новый заголовок"}},
+		{"only spaces", "   ", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := splitArgs(tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	for _, input := range []string{`create-ad "Велосипед Горный 100`, `create-ad 'Велосипед`, `create-ad Велосипед\`} {
+		t.Run("invalid "+input, func(t *testing.T) {
+			_, err := splitArgs(input)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestUnitCreateAdQuoted(t *testing.T) {
+	var got *services.CreateAdRequest
+	api := &clienttest.Mock{PostAddFunc: func(_ context.Context, req *services.CreateAdRequest) (db.Ad, error) {
+		got = req
+		return db.Ad{ID: 1}, nil
+	}}
+	app := newMockApp(t, api)
+
+	require.NoError(t, app.executeCommand(`create-ad "Детский велосипед" "Почти новый, самовывоз" 150000`))
+	assert.Equal(t, &services.CreateAdRequest{Title: "Детский велосипед", Text: "Почти новый, самовывоз", Price: 150000}, got)
+
+	err := app.executeCommand(`create-ad "Детский велосипед 150000`)
+	assert.ErrorContains(t, err, "незакрытая кавычка")
+}
@@ -0,0 +1,215 @@
+package app_cmd
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/YuarenArt/marketgo/internal/client/clienttest"
+	"github.com/YuarenArt/marketgo/internal/config"
+	"github.com/YuarenArt/marketgo/internal/db"
+	"github.com/YuarenArt/marketgo/internal/server/services"
+	"github.com/YuarenArt/marketgo/pkg/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMockApp(t *testing.T, api *clienttest.Mock) *App {
+	app := NewApp(api, logging.NewLogger(nil), &config.Config{CredentialsFile: filepath.Join(t.TempDir(), "credentials.json")})
+	app.readPassword = func(string) (string, error) { return "", errNoTerminal }
+	return app
+}
+
+func TestUnitCreateAdArgs(t *testing.T) {
+	var got *services.CreateAdRequest
+	api := &clienttest.Mock{PostAddFunc: func(_ context.Context, req *services.CreateAdRequest) (db.Ad, error) {
+		got = req
+		return db.Ad{ID: 1, Title: req.Title, Price: req.Price}, nil
+	}}
+	app := newMockApp(t, api)
+
+	t.Run("image url is optional", func(t *testing.T) {
+		require.NoError(t, app.handleCreateAd([]string{"Велосипед", "Горный", "300"}))
+		assert.Equal(t, &services.CreateAdRequest{Title: "Велосипед", Text: "Горный", Price: 300}, got)
+	})
+
+	t.Run("all arguments", func(t *testing.T) {
+		require.NoError(t, app.handleCreateAd([]string{"Самокат", "Детский", "150", "https://example.com/1.jpg"}))
+		assert.Equal(t, &services.CreateAdRequest{Title: "Самокат", Text: "Детский", Price: 150, ImageURL: "https://example.com/1.jpg"}, got)
+	})
+
+	t.Run("local file is uploaded first", func(t *testing.T) {
+		var uploaded string
+		api.UploadImageFunc = func(_ context.Context, path string) (services.UploadedImage, error) {
+			uploaded = path
+			return services.UploadedImage{URL: "http://localhost:8080/uploads/abc.png"}, nil
+		}
+		t.Cleanup(func() { api.UploadImageFunc = nil })
+
+		require.NoError(t, app.handleCreateAd([]string{"Самокат", "Детский", "150", "photos/scooter.png"}))
+		assert.Equal(t, "photos/scooter.png", uploaded)
+		assert.Equal(t, "http://localhost:8080/uploads/abc.png", got.ImageURL)
+		assert.Equal(t, []string{"UploadImage", "PostAdd"}, api.Calls()[len(api.Calls())-2:])
+	})
+
+	t.Run("invalid arguments do not call the api", func(t *testing.T) {
+		calls := len(api.Calls())
+		assert.Error(t, app.handleCreateAd([]string{"Велосипед", "Горный"}))
+		assert.Error(t, app.handleCreateAd([]string{"Велосипед", "Горный", "дёшево"}))
+		assert.Len(t, api.Calls(), calls)
+	})
+}
+
+func TestUnitListAdsArgs(t *testing.T) {
+	var got services.GetAdsRequest
+	api := &clienttest.Mock{GetAdsFunc: func(_ context.Context, req services.GetAdsRequest) (services.PagedAds, error) {
+		got = req
+		return services.PagedAds{}, nil
+	}}
+	app := newMockApp(t, api)
+
+	tests := []struct {
+		name string
+		args []string
+		want services.GetAdsRequest
+	}{
+		{"defaults", nil, services.GetAdsRequest{Page: 1, PageSize: 10, SortBy: "created_at", SortOrder: "DESC"}},
+		{"page and size", []string{"3", "20"}, services.GetAdsRequest{Page: 3, PageSize: 20, SortBy: "created_at", SortOrder: "DESC"}},
+		{"sorting", []string{"1", "10", "price", "ASC"}, services.GetAdsRequest{Page: 1, PageSize: 10, SortBy: "price", SortOrder: "ASC"}},
+		{
+			"price range", []string{"1", "10", "price", "ASC", "100", "500"},
+			services.GetAdsRequest{Page: 1, PageSize: 10, SortBy: "price", SortOrder: "ASC", MinPrice: 100, MaxPrice: 500},
+		},
+		{
+			"cities", []string{"1", "10", "price", "ASC", "0", "500", "Москва,Казань"},
+			services.GetAdsRequest{Page: 1, PageSize: 10, SortBy: "price", SortOrder: "ASC", MaxPrice: 500, Cities: []string{"Москва", "Казань"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, app.handleListAds(tt.args))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("invalid numbers do not call the api", func(t *testing.T) {
+		calls := len(api.Calls())
+		for _, args := range [][]string{
+			{"first"},
+			{"1", "many"},
+			{"1", "10", "price", "ASC", "cheap"},
+			{"1", "10", "price", "ASC", "100", "expensive"},
+		} {
+			assert.Error(t, app.handleListAds(args), args)
+		}
+		assert.Len(t, api.Calls(), calls)
+	})
+}
+
+func TestUnitListAdsPaging(t *testing.T) {
+	var pages []int
+	api := &clienttest.Mock{GetAdsFunc: func(_ context.Context, req services.GetAdsRequest) (services.PagedAds, error) {
+		pages = append(pages, req.Page)
+		assert.Equal(t, "price", req.SortBy, "filters are kept between pages")
+		assert.Equal(t, int64(100), req.MinPrice, "filters are kept between pages")
+		if req.Page > 3 {
+			return services.PagedAds{Page: req.Page}, nil
+		}
+		return services.PagedAds{Items: []db.Ad{{ID: req.Page}}, Page: req.Page}, nil
+	}}
+	app := newMockApp(t, api)
+	var out bytes.Buffer
+	app.out = &out
+
+	assert.Error(t, app.executeCommand("prev"), "prev without list-ads")
+	require.NoError(t, app.executeCommand("list-ads 2 10 price ASC 100"))
+	for _, command := range []string{"prev", "prev", "next", "next", "next", "prev"} {
+		require.NoError(t, app.executeCommand(command), command)
+	}
+	assert.Equal(t, []int{2, 1, 2, 3, 4, 2}, pages)
+	assert.Contains(t, out.String(), "Это первая страница")
+	assert.Contains(t, out.String(), "Страница 4 пуста")
+	assert.Equal(t, 2, app.listing.Page)
+}
+
+func TestUnitFavoriteCommands(t *testing.T) {
+	var favorited, unfavorited int
+	var listed services.GetAdsRequest
+	api := &clienttest.Mock{
+		FavoriteAdFunc:   func(_ context.Context, id int) error { favorited = id; return nil },
+		UnfavoriteAdFunc: func(_ context.Context, id int) error { unfavorited = id; return nil },
+		GetFavoritesFunc: func(_ context.Context, req services.GetAdsRequest) ([]db.Ad, error) {
+			listed = req
+			return []db.Ad{{ID: 7, Title: "Велосипед", IsFavorite: true}}, nil
+		},
+	}
+	app := newMockApp(t, api)
+	var out bytes.Buffer
+	app.out = &out
+
+	require.NoError(t, app.executeCommand("favorite 7"))
+	assert.Equal(t, 7, favorited)
+	assert.Contains(t, out.String(), "Объявление 7 добавлено в избранное")
+
+	require.NoError(t, app.executeCommand("list-favorites 2 5"))
+	assert.Equal(t, 2, listed.Page)
+	assert.Equal(t, 5, listed.PageSize)
+	assert.Contains(t, out.String(), "В избранном:    да")
+
+	require.NoError(t, app.executeCommand("unfavorite 7"))
+	assert.Equal(t, 7, unfavorited)
+
+	calls := len(api.Calls())
+	assert.Error(t, app.executeCommand("favorite"))
+	assert.Error(t, app.executeCommand("favorite abc"))
+	assert.Error(t, app.executeCommand("list-favorites many"))
+	assert.Len(t, api.Calls(), calls)
+}
+
+func TestUnitRenewAdCommand(t *testing.T) {
+	expiresAt := time.Date(2030, 5, 1, 12, 0, 0, 0, time.Local)
+	var renewed int
+	api := &clienttest.Mock{RenewAdFunc: func(_ context.Context, id int) (db.Ad, error) {
+		renewed = id
+		return db.Ad{ID: id, ExpiresAt: expiresAt}, nil
+	}}
+	app := newMockApp(t, api)
+	var out bytes.Buffer
+	app.out = &out
+
+	require.NoError(t, app.executeCommand("renew-ad 7"))
+	assert.Equal(t, 7, renewed)
+	assert.Contains(t, out.String(), "Объявление 7 продлено до 2030-05-01 12:00")
+
+	calls := len(api.Calls())
+	assert.Error(t, app.executeCommand("renew-ad"))
+	assert.Error(t, app.executeCommand("renew-ad abc"))
+	assert.Len(t, api.Calls(), calls)
+}
+
+func TestUnitSimilarCommand(t *testing.T) {
+	var requested, limit int
+	api := &clienttest.Mock{GetSimilarAdsFunc: func(_ context.Context, id, n int) ([]db.Ad, error) {
+		requested, limit = id, n
+		return []db.Ad{{ID: 9, Title: "Велосипед горный"}}, nil
+	}}
+	app := newMockApp(t, api)
+	var out bytes.Buffer
+	app.out = &out
+
+	require.NoError(t, app.executeCommand("similar 7"))
+	assert.Equal(t, 7, requested)
+	assert.Zero(t, limit, "server default is used without a limit argument")
+	assert.Contains(t, out.String(), "Велосипед горный")
+
+	require.NoError(t, app.executeCommand("similar 7 3"))
+	assert.Equal(t, 3, limit)
+
+	calls := len(api.Calls())
+	assert.Error(t, app.executeCommand("similar"))
+	assert.Error(t, app.executeCommand("similar 7 0"))
+	assert.Error(t, app.executeCommand("similar 7 many"))
+	assert.Len(t, api.Calls(), calls)
+}
@@ -0,0 +1,86 @@
+package app_cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// credentials - токены, сохраняемые между запусками консольного клиента
+type credentials struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// credentialsStore хранит токены в JSON-файле, доступном только владельцу (0600)
+type credentialsStore struct {
+	path string
+}
+
+// newCredentialsStore возвращает хранилище в файле path или, если он не задан,
+// в ~/.config/marketgo/credentials.json (каталог настроек пользователя)
+func newCredentialsStore(path string) (*credentialsStore, error) {
+	if path == "" {
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return nil, fmt.Errorf("каталог настроек: %w", err)
+		}
+		path = filepath.Join(dir, "marketgo", "credentials.json")
+	}
+	return &credentialsStore{path: path}, nil
+}
+
+// load читает сохранённые токены; без файла возвращает пустые credentials
+func (s *credentialsStore) load() (credentials, error) {
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return credentials{}, nil
+	}
+	if err != nil {
+		return credentials{}, fmt.Errorf("чтение %s: %w", s.path, err)
+	}
+	var creds credentials
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return credentials{}, fmt.Errorf("разбор %s: %w", s.path, err)
+	}
+	return creds, nil
+}
+
+// save записывает токены, заменяя файл целиком, чтобы он не остался записанным наполовину
+func (s *credentialsStore) save(creds credentials) error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return fmt.Errorf("создание каталога: %w", err)
+	}
+	data, err := json.Marshal(creds)
+	if err != nil {
+		return fmt.Errorf("сериализация: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".credentials-*")
+	if err != nil {
+		return fmt.Errorf("создание файла: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("запись %s: %w", s.path, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("запись %s: %w", s.path, err)
+	}
+	// CreateTemp создаёт файл с правами 0600
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("запись %s: %w", s.path, err)
+	}
+	return nil
+}
+
+// clear удаляет сохранённые токены
+func (s *credentialsStore) clear() error {
+	if err := os.Remove(s.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("удаление %s: %w", s.path, err)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package app_cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/YuarenArt/marketgo/internal/client"
+	"github.com/YuarenArt/marketgo/internal/config"
+	"github.com/YuarenArt/marketgo/pkg/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCredentialsStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "marketgo", "credentials.json")
+	store, err := newCredentialsStore(path)
+	require.NoError(t, err)
+
+	creds, err := store.load()
+	require.NoError(t, err)
+	assert.Empty(t, creds, "missing file means logged out")
+
+	want := credentials{Token: "jwt", RefreshToken: "refresh"}
+	require.NoError(t, store.save(want))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	creds, err = store.load()
+	require.NoError(t, err)
+	assert.Equal(t, want, creds)
+
+	require.NoError(t, store.clear())
+	assert.NoFileExists(t, path)
+	require.NoError(t, store.clear(), "clearing twice is not an error")
+}
+
+func TestAppCredentials(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/login":
+			_, _ = w.Write([]byte(`{"token": "jwt", "refresh_token": "refresh", "expires_in": 900}`))
+		case "/api/v1/logout":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "credentials.json")
+	newApp := func() *App {
+		return NewApp(client.NewClient(srv.URL, logging.NewLogger(nil)), logging.NewLogger(nil), &config.Config{CredentialsFile: path})
+	}
+	run := func(a *App, input string) {
+		require.NoError(t, a.executeCommand(input))
+		a.saveCredentials()
+	}
+
+	run(newApp(), "login seller s3cure-horse7")
+	assert.FileExists(t, path)
+
+	restored := newApp()
+	token, refreshToken := restored.client.Tokens()
+	assert.Equal(t, "jwt", token)
+	assert.Equal(t, "refresh", refreshToken)
+
+	run(restored, "logout")
+	assert.NoFileExists(t, path)
+
+	t.Run("corrupted file is ignored", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(path, []byte("{not json"), 0o600))
+		a := newApp()
+		token, _ := a.client.Tokens()
+		assert.Empty(t, token)
+
+		run(a, "login seller s3cure-horse7")
+		store, err := newCredentialsStore(path)
+		require.NoError(t, err)
+		creds, err := store.load()
+		require.NoError(t, err)
+		assert.Equal(t, credentials{Token: "jwt", RefreshToken: "refresh"}, creds)
+	})
+}
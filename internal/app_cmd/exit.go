@@ -0,0 +1,55 @@
+package app_cmd
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+
+	"github.com/YuarenArt/marketgo/internal/client"
+)
+
+// Коды завершения процесса в режиме одной команды
+const (
+	ExitOK = 0
+	// ExitError - прочие ошибки сервера, например конфликт или внутренняя ошибка
+	ExitError = 1
+	// ExitValidation - неверные аргументы команды или данные, отклонённые сервером
+	ExitValidation = 2
+	// ExitAuth - требуется вход или недостаточно прав
+	ExitAuth = 3
+	// ExitNetwork - сервер недоступен: ошибка соединения, таймаут или 502, 503, 504
+	ExitNetwork = 4
+)
+
+// ExitCode возвращает код завершения процесса для ошибки команды
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	if errors.Is(err, client.ErrUnauthorized) || errors.Is(err, client.ErrForbidden) {
+		return ExitAuth
+	}
+
+	var apiErr *client.APIError
+	if errors.As(err, &apiErr) {
+		switch {
+		case apiErr.StatusCode == http.StatusBadGateway || apiErr.StatusCode == http.StatusServiceUnavailable ||
+			apiErr.StatusCode == http.StatusGatewayTimeout:
+			return ExitNetwork
+		case errors.Is(err, client.ErrValidation):
+			return ExitValidation
+		default:
+			return ExitError
+		}
+	}
+
+	var urlErr *url.Error
+	var netErr net.Error
+	if errors.As(err, &urlErr) || errors.As(err, &netErr) || errors.Is(err, context.DeadlineExceeded) {
+		return ExitNetwork
+	}
+	// Ошибки без ответа сервера возникают при проверке аргументов команды
+	return ExitValidation
+}
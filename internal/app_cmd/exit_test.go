@@ -0,0 +1,65 @@
+package app_cmd
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/YuarenArt/marketgo/internal/client"
+	"github.com/YuarenArt/marketgo/internal/client/clienttest"
+	"github.com/YuarenArt/marketgo/internal/db"
+	"github.com/YuarenArt/marketgo/internal/server/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnitExitCodes(t *testing.T) {
+	apiError := func(status int, code string) error {
+		return &client.APIError{StatusCode: status, Code: code, Message: "ошибка"}
+	}
+	tests := []struct {
+		name  string
+		input string
+		err   error
+		want  int
+	}{
+		{"success", "show-ad 1", nil, ExitOK},
+		{"unknown command", "frobnicate", nil, ExitValidation},
+		{"missing arguments", "show-ad", nil, ExitValidation},
+		{"unbalanced quotes", `show-ad "1`, nil, ExitValidation},
+		{"server validation", "show-ad 1", apiError(http.StatusBadRequest, "invalid_input"), ExitValidation},
+		{"expired session", "show-ad 1", apiError(http.StatusUnauthorized, "unauthorized"), ExitAuth},
+		{"forbidden", "show-ad 1", apiError(http.StatusForbidden, "forbidden"), ExitAuth},
+		{"not found", "show-ad 1", apiError(http.StatusNotFound, "not_found"), ExitError},
+		{"server error", "show-ad 1", apiError(http.StatusInternalServerError, "internal"), ExitError},
+		{"database unavailable", "show-ad 1", apiError(http.StatusServiceUnavailable, "unavailable"), ExitNetwork},
+		{"connection refused", "show-ad 1", &url.Error{Op: "Get", URL: "http://localhost:8080", Err: errors.New("connection refused")}, ExitNetwork},
+		{"timeout", "show-ad 1", context.DeadlineExceeded, ExitNetwork},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &clienttest.Mock{GetAdFunc: func(context.Context, int) (db.Ad, error) {
+				return db.Ad{ID: 1}, tt.err
+			}}
+			app := newMockApp(t, api)
+			assert.Equal(t, tt.want, ExitCode(app.executeCommand(tt.input)))
+		})
+	}
+}
+
+func TestUnitRunCommand(t *testing.T) {
+	api := &clienttest.Mock{LoginFunc: func(_ context.Context, input *services.InputUserInfo) error {
+		if input.Password != "s3cure-horse7" {
+			return &client.APIError{StatusCode: http.StatusUnauthorized, Code: "unauthorized", Message: "invalid credentials"}
+		}
+		return nil
+	}}
+	app := newMockApp(t, api)
+
+	assert.Equal(t, ExitOK, app.RunCommand([]string{"login", "seller", "s3cure-horse7"}))
+	assert.Equal(t, ExitAuth, app.RunCommand([]string{"login", "seller", "wrong"}))
+	assert.Equal(t, ExitValidation, app.RunCommand([]string{"login"}))
+	assert.Equal(t, ExitOK, app.RunCommand(nil))
+}
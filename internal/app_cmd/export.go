@@ -0,0 +1,127 @@
+package app_cmd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/YuarenArt/marketgo/internal/server/services"
+)
+
+// exportPageSize - размер страницы при экспорте, если он не задан аргументом
+const exportPageSize = 100
+
+// utf8BOM помечает файл как UTF-8 для Excel, который иначе читает CSV в локальной кодировке
+const utf8BOM = "\ufeff"
+
+// exportHeader - столбцы CSV, выгружаемого командой export-ads
+var exportHeader = []string{"id", "title", "price", "author", "created_at", "image_url"}
+
+// handleExportAds выгружает все страницы объявлений в CSV-файл или, если вместо файла указан "-", в stdout.
+// Аргументы после файла - фильтры list-ads без номера страницы; --bom добавляет в начало UTF-8 BOM.
+func (a *App) handleExportAds(args []string) error {
+	if len(args) < 1 {
+		return fmt.Errorf("команда export-ads требует имя файла или -")
+	}
+	path := args[0]
+
+	var filters []string
+	bom := false
+	for _, arg := range args[1:] {
+		if arg == "--bom" {
+			bom = true
+			continue
+		}
+		filters = append(filters, arg)
+	}
+	req, err := parseListArgs(append([]string{"1"}, filters...))
+	if err != nil {
+		return err
+	}
+	if len(filters) == 0 {
+		req.PageSize = exportPageSize
+	}
+
+	// при выводе в stdout итог пишется в stderr, чтобы не попасть в CSV
+	var count int
+	summary := a.out
+	if path == "-" {
+		summary = os.Stderr
+		count, err = a.exportAds(a.out, req, bom)
+	} else {
+		count, err = a.exportFile(path, req, bom)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(summary, "Экспортировано объявлений: %d\n", count)
+	a.logger.Info("Объявления экспортированы", "path", path, "count", count)
+	return nil
+}
+
+// exportFile выгружает объявления в файл path, заменяя его содержимое
+func (a *App) exportFile(path string, req services.GetAdsRequest, bom bool) (int, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return 0, fmt.Errorf("создание файла: %w", err)
+	}
+	count, err := a.exportAds(file, req, bom)
+	if closeErr := file.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("запись %s: %w", path, closeErr)
+	}
+	return count, err
+}
+
+// exportAds записывает в w объявления всех страниц, начиная с req.Page, и возвращает их число.
+// Каждая страница сбрасывается в w сразу после получения, поэтому при ошибке
+// на очередной странице уже выгруженные строки сохраняются.
+func (a *App) exportAds(w io.Writer, req services.GetAdsRequest, bom bool) (int, error) {
+	if bom {
+		if _, err := io.WriteString(w, utf8BOM); err != nil {
+			return 0, fmt.Errorf("запись: %w", err)
+		}
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(exportHeader); err != nil {
+		return 0, fmt.Errorf("запись: %w", err)
+	}
+
+	count := 0
+	for {
+		paged, err := a.fetchAds(req)
+		if err != nil {
+			cw.Flush()
+			return count, fmt.Errorf("экспорт прерван на странице %d (записано объявлений: %d): %w", req.Page, count, err)
+		}
+		for _, ad := range paged.Items {
+			record := []string{
+				strconv.Itoa(ad.ID),
+				ad.Title,
+				strconv.FormatInt(ad.Price, 10),
+				ad.Author,
+				ad.CreatedAt.Format(time.RFC3339),
+				ad.ImageURL,
+			}
+			if err := cw.Write(record); err != nil {
+				return count, fmt.Errorf("запись: %w", err)
+			}
+			count++
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return count, fmt.Errorf("запись: %w", err)
+		}
+
+		last := len(paged.Items) < req.PageSize
+		if paged.TotalPages > 0 {
+			last = req.Page >= paged.TotalPages
+		}
+		if last {
+			return count, nil
+		}
+		req.Page++
+	}
+}
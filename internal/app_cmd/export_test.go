@@ -0,0 +1,108 @@
+package app_cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/YuarenArt/marketgo/internal/client"
+	"github.com/YuarenArt/marketgo/internal/config"
+	"github.com/YuarenArt/marketgo/internal/db"
+	"github.com/YuarenArt/marketgo/internal/server/services"
+	"github.com/YuarenArt/marketgo/pkg/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakePagedServer отдаёт три страницы объявлений по два, два и одному объявлению;
+// на странице failPage отвечает ошибкой 500
+func fakePagedServer(t *testing.T, failPage int, pages *[]int) *httptest.Server {
+	created := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		*pages = append(*pages, page)
+		if page == failPage {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		var items []db.Ad
+		for id := (page-1)*2 + 1; id <= min(page*2, 5); id++ {
+			items = append(items, db.Ad{ID: id, Title: "Объявление, №" + strconv.Itoa(id), Price: int64(id * 100), Author: "seller", CreatedAt: created})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(services.PagedAds{Items: items, Total: 5, Page: page, PageSize: 2, TotalPages: 3})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestExportAds(t *testing.T) {
+	newApp := func(t *testing.T, failPage int, pages *[]int) (*App, *bytes.Buffer) {
+		srv := fakePagedServer(t, failPage, pages)
+		app := NewApp(client.NewClient(srv.URL, logging.NewLogger(nil)), logging.NewLogger(nil),
+			&config.Config{CredentialsFile: filepath.Join(t.TempDir(), "credentials.json")})
+		var out bytes.Buffer
+		app.out = &out
+		return app, &out
+	}
+	readCSV := func(t *testing.T, data string) [][]string {
+		records, err := csv.NewReader(strings.NewReader(data)).ReadAll()
+		require.NoError(t, err)
+		return records
+	}
+
+	t.Run("all pages are written to the file", func(t *testing.T) {
+		var pages []int
+		app, out := newApp(t, 0, &pages)
+		path := filepath.Join(t.TempDir(), "ads.csv")
+		require.NoError(t, app.executeCommand("export-ads "+path+" 2 price ASC"))
+		assert.Equal(t, []int{1, 2, 3}, pages)
+		assert.Contains(t, out.String(), "Экспортировано объявлений: 5")
+
+		data, err := os.ReadFile(path)
+		require.NoError(t, err)
+		records := readCSV(t, string(data))
+		require.Len(t, records, 6)
+		assert.Equal(t, exportHeader, records[0])
+		assert.Equal(t, []string{"1", "Объявление, №1", "100", "seller", "2025-03-01T12:00:00Z", ""}, records[1])
+		assert.Equal(t, "5", records[5][0])
+	})
+
+	t.Run("dash streams to stdout with bom", func(t *testing.T) {
+		var pages []int
+		app, out := newApp(t, 0, &pages)
+		require.NoError(t, app.executeCommand("export-ads - 2 --bom"))
+		require.True(t, strings.HasPrefix(out.String(), utf8BOM))
+		records := readCSV(t, strings.TrimPrefix(out.String(), utf8BOM))
+		assert.Len(t, records, 6, "summary is not mixed into csv")
+	})
+
+	t.Run("api error keeps written pages", func(t *testing.T) {
+		var pages []int
+		app, _ := newApp(t, 2, &pages)
+		path := filepath.Join(t.TempDir(), "ads.csv")
+		err := app.executeCommand("export-ads " + path + " 2")
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "странице 2")
+		assert.Equal(t, []int{1, 2}, pages)
+
+		data, readErr := os.ReadFile(path)
+		require.NoError(t, readErr)
+		assert.Len(t, readCSV(t, string(data)), 3)
+	})
+
+	t.Run("missing file argument", func(t *testing.T) {
+		var pages []int
+		app, _ := newApp(t, 0, &pages)
+		assert.Error(t, app.executeCommand("export-ads"))
+		assert.Empty(t, pages)
+	})
+}
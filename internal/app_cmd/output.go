@@ -0,0 +1,165 @@
+package app_cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"unicode/utf8"
+
+	"github.com/YuarenArt/marketgo/internal/db"
+	"github.com/YuarenArt/marketgo/internal/server/services"
+)
+
+// Форматы вывода результатов команд
+const (
+	// FormatPlain - текстовые блоки для чтения человеком (по умолчанию)
+	FormatPlain = "plain"
+	// FormatTable - таблица с выровненными столбцами
+	FormatTable = "table"
+	// FormatJSON - JSON для скриптов: массив для списков, объект для одной записи
+	FormatJSON = "json"
+)
+
+// maxCellRunes - длина, до которой обрезается текст в ячейке таблицы
+const maxCellRunes = 60
+
+// parseFormat проверяет формат вывода; пустое значение означает FormatPlain
+func parseFormat(format string) (string, error) {
+	switch format = strings.ToLower(strings.TrimSpace(format)); format {
+	case "":
+		return FormatPlain, nil
+	case FormatPlain, FormatTable, FormatJSON:
+		return format, nil
+	default:
+		return "", fmt.Errorf("неизвестный формат вывода %q: ожидается plain, table или json", format)
+	}
+}
+
+// printAds выводит список объявлений в выбранном формате
+func (a *App) printAds(ads []db.Ad) error {
+	switch a.format {
+	case FormatJSON:
+		if ads == nil {
+			ads = []db.Ad{}
+		}
+		return a.printJSON(ads)
+	case FormatTable:
+		return a.printAdsTable(ads)
+	default:
+		a.printAdsPlain(ads)
+		return nil
+	}
+}
+
+// printAd выводит одно объявление; в JSON - объектом, а не массивом
+func (a *App) printAd(ad db.Ad) error {
+	if a.format == FormatJSON {
+		return a.printJSON(ad)
+	}
+	return a.printAds([]db.Ad{ad})
+}
+
+// printProfile выводит профиль пользователя в выбранном формате
+func (a *App) printProfile(profile services.Profile) error {
+	switch a.format {
+	case FormatJSON:
+		return a.printJSON(profile)
+	case FormatTable:
+		w := tabwriter.NewWriter(a.out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tЛОГИН\tЗАРЕГИСТРИРОВАН\tОБЪЯВЛЕНИЙ")
+		fmt.Fprintf(w, "%d\t%s\t%s\t%d\n", profile.ID, tableCell(profile.Login),
+			profile.CreatedAt.Format("2006-01-02"), profile.AdsCount)
+		return w.Flush()
+	default:
+		fmt.Fprintf(a.out, "ID=%d, Login=%s, зарегистрирован %s, объявлений: %d\n",
+			profile.ID, profile.Login, profile.CreatedAt.Format("2006-01-02"), profile.AdsCount)
+		return nil
+	}
+}
+
+// printInfo выводит служебную строку (номер страницы, подсказку); в JSON она опускается,
+// чтобы stdout оставался корректным JSON
+func (a *App) printInfo(format string, args ...interface{}) {
+	if a.format == FormatJSON {
+		return
+	}
+	fmt.Fprintf(a.out, format, args...)
+}
+
+// printJSON выводит v в stdout одной JSON-записью
+func (a *App) printJSON(v interface{}) error {
+	enc := json.NewEncoder(a.out)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("вывод JSON: %w", err)
+	}
+	return nil
+}
+
+// printAdsTable выводит объявления таблицей; длинные значения обрезаются до maxCellRunes символов
+func (a *App) printAdsTable(ads []db.Ad) error {
+	if len(ads) == 0 {
+		fmt.Fprintln(a.out, "Объявления не найдены.")
+		return nil
+	}
+	w := tabwriter.NewWriter(a.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tЗАГОЛОВОК\tЦЕНА\tСОЗДАНО\tТЕКСТ")
+	for _, ad := range ads {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", ad.ID, tableCell(ad.Title), strconv.FormatInt(ad.Price, 10),
+			ad.CreatedAt.Format("2006-01-02 15:04"), tableCell(ad.Text))
+	}
+	return w.Flush()
+}
+
+// tableCell готовит значение для ячейки таблицы: переводы строк и табуляции заменяются пробелами,
+// текст длиннее maxCellRunes обрезается с многоточием
+func tableCell(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if utf8.RuneCountInString(s) <= maxCellRunes {
+		return s
+	}
+	runes := []rune(s)
+	return strings.TrimRight(string(runes[:maxCellRunes-1]), " ") + "…"
+}
+
+// printAdsPlain выводит объявления в виде текстовых блоков
+func (a *App) printAdsPlain(ads []db.Ad) {
+	if len(ads) == 0 {
+		fmt.Fprintln(a.out, "Объявления не найдены.")
+		return
+	}
+
+	fmt.Fprintf(a.out, "Найдено объявлений: %d\n\n", len(ads))
+	for i, ad := range ads {
+		// Форматируем дату
+		createdAt := ad.CreatedAt.Format("2006-01-02 15:04:05")
+		// Выводим блок для каждого объявления
+		fmt.Fprintln(a.out, "=============================================================")
+		fmt.Fprintf(a.out, "Объявление %d\n", ad.ID)
+		fmt.Fprintln(a.out, "-------------------------------------------------------------")
+		fmt.Fprintf(a.out, "Заголовок:      %s\n", ad.Title)
+		fmt.Fprintf(a.out, "Текст:          %s\n", ad.Text)
+		fmt.Fprintf(a.out, "Цена:           %d\n", ad.Price)
+		fmt.Fprintf(a.out, "URL изображения:%s\n", ad.ImageURL)
+		if len(ad.Tags) > 0 {
+			fmt.Fprintf(a.out, "Теги:           %s\n", strings.Join(ad.Tags, ", "))
+		}
+		if ad.City != "" {
+			fmt.Fprintf(a.out, "Город:          %s\n", ad.City)
+		}
+		if ad.IsFavorite {
+			fmt.Fprintln(a.out, "В избранном:    да")
+		}
+		fmt.Fprintf(a.out, "Создано:        %s\n", createdAt)
+		if !ad.ExpiresAt.IsZero() {
+			fmt.Fprintf(a.out, "Активно до:     %s\n", ad.ExpiresAt.Format("2006-01-02 15:04:05"))
+		}
+		fmt.Fprintln(a.out, "=============================================================")
+		// Добавляем пустую строку между объявлениями, кроме последнего
+		if i < len(ads)-1 {
+			fmt.Fprintln(a.out)
+		}
+	}
+}
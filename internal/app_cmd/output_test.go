@@ -0,0 +1,107 @@
+package app_cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/YuarenArt/marketgo/internal/client/clienttest"
+	"github.com/YuarenArt/marketgo/internal/config"
+	"github.com/YuarenArt/marketgo/internal/db"
+	"github.com/YuarenArt/marketgo/internal/server/services"
+	"github.com/YuarenArt/marketgo/pkg/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutputFormats(t *testing.T) {
+	created := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	long := strings.Repeat("очень длинное описание ", 10)
+	ads := []db.Ad{
+		{ID: 1, Title: "Велосипед", Text: "Горный", Price: 300, CreatedAt: created},
+		{ID: 22, Title: "Самокат", Text: long, Price: 15000, CreatedAt: created},
+	}
+	api := &clienttest.Mock{
+		GetAdsFunc: func(context.Context, services.GetAdsRequest) (services.PagedAds, error) {
+			return services.PagedAds{Items: ads, Page: 1, TotalPages: 1, Total: 2}, nil
+		},
+		GetAdFunc: func(context.Context, int) (db.Ad, error) { return ads[0], nil },
+		MeFunc: func(context.Context) (services.Profile, error) {
+			return services.Profile{User: db.User{ID: 7, Login: "seller", CreatedAt: created}, AdsCount: 2}, nil
+		},
+	}
+	newApp := func(t *testing.T, format string) (*App, *bytes.Buffer) {
+		app := NewApp(api, logging.NewLogger(nil), &config.Config{
+			CredentialsFile: filepath.Join(t.TempDir(), "credentials.json"),
+			OutputFormat:    format,
+		})
+		var out bytes.Buffer
+		app.out = &out
+		return app, &out
+	}
+
+	t.Run("json list is an array", func(t *testing.T) {
+		app, out := newApp(t, FormatJSON)
+		require.NoError(t, app.executeCommand("list-ads"))
+		var got []db.Ad
+		require.NoError(t, json.Unmarshal(out.Bytes(), &got), out.String())
+		assert.Equal(t, ads, got)
+	})
+
+	t.Run("json show-ad and whoami are objects", func(t *testing.T) {
+		app, out := newApp(t, FormatJSON)
+		require.NoError(t, app.executeCommand("show-ad 1"))
+		var ad db.Ad
+		require.NoError(t, json.Unmarshal(out.Bytes(), &ad), out.String())
+		assert.Equal(t, ads[0], ad)
+
+		out.Reset()
+		require.NoError(t, app.executeCommand("whoami"))
+		var profile map[string]any
+		require.NoError(t, json.Unmarshal(out.Bytes(), &profile), out.String())
+		assert.Equal(t, "seller", profile["login"])
+		assert.Equal(t, 2.0, profile["ads_count"])
+	})
+
+	t.Run("table aligns columns and truncates text", func(t *testing.T) {
+		app, out := newApp(t, FormatTable)
+		require.NoError(t, app.executeCommand("list-ads"))
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		require.Len(t, lines, 4)
+		header, first, second := lines[0], lines[1], lines[2]
+		column := utf8.RuneCountInString(header[:strings.Index(header, "ЦЕНА")])
+		assert.Equal(t, column, utf8.RuneCountInString(first[:strings.Index(first, "300")]))
+		assert.Equal(t, column, utf8.RuneCountInString(second[:strings.Index(second, "15000")]))
+
+		text := strings.TrimSpace(second[strings.Index(second, "2025-03-01 12:00")+len("2025-03-01 12:00"):])
+		assert.Equal(t, tableCell(long), text)
+		assert.LessOrEqual(t, utf8.RuneCountInString(text), maxCellRunes)
+		assert.True(t, strings.HasSuffix(text, "…"))
+		assert.Contains(t, lines[3], "Страница 1 из 1")
+	})
+
+	t.Run("plain is the default", func(t *testing.T) {
+		app, out := newApp(t, "")
+		require.NoError(t, app.executeCommand("show-ad 1"))
+		assert.Contains(t, out.String(), "Заголовок:      Велосипед")
+	})
+
+	t.Run("unknown format falls back to plain", func(t *testing.T) {
+		app, _ := newApp(t, "yaml")
+		assert.Equal(t, FormatPlain, app.format)
+	})
+}
+
+func TestTableCell(t *testing.T) {
+	assert.Equal(t, "Горный велосипед", tableCell("Горный\nвелосипед"))
+	short := strings.Repeat("я", maxCellRunes)
+	assert.Equal(t, short, tableCell(short))
+	truncated := tableCell(strings.Repeat("я", maxCellRunes+1))
+	assert.Equal(t, maxCellRunes, utf8.RuneCountInString(truncated))
+	assert.True(t, strings.HasSuffix(truncated, "…"))
+}
@@ -0,0 +1,63 @@
+package app_cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"golang.org/x/term"
+)
+
+// passwordEnv - переменная окружения с паролем для запуска из скриптов и CI
+const passwordEnv = "MARKETGO_PASSWORD"
+
+// errNoTerminal возвращается, когда пароль нельзя запросить: stdin не является терминалом
+var errNoTerminal = errors.New("stdin не является терминалом")
+
+// passwordPrompt запрашивает у пользователя пароль, не отображая ввод
+type passwordPrompt func(prompt string) (string, error)
+
+// terminalPassword запрашивает пароль в терминале с отключённым эхо.
+// Приглашение выводится в stderr, чтобы не смешиваться с результатом команды.
+func terminalPassword(prompt string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", errNoTerminal
+	}
+	fmt.Fprint(os.Stderr, prompt)
+	password, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("чтение пароля: %w", err)
+	}
+	return string(password), nil
+}
+
+// password возвращает пароль из аргумента, если он передан, затем из MARKETGO_PASSWORD,
+// иначе запрашивает его в терминале; confirm требует ввести пароль повторно
+func (a *App) password(args []string, confirm bool) (string, error) {
+	if len(args) > 0 {
+		return args[0], nil
+	}
+	if password := os.Getenv(passwordEnv); password != "" {
+		return password, nil
+	}
+
+	password, err := a.readPassword("Пароль: ")
+	if err != nil {
+		return "", fmt.Errorf("пароль не передан: укажите его аргументом, в %s или введите в терминале: %w", passwordEnv, err)
+	}
+	if password == "" {
+		return "", fmt.Errorf("пароль не может быть пустым")
+	}
+	if confirm {
+		repeated, err := a.readPassword("Повторите пароль: ")
+		if err != nil {
+			return "", err
+		}
+		if repeated != password {
+			return "", fmt.Errorf("пароли не совпадают")
+		}
+	}
+	return password, nil
+}
@@ -0,0 +1,94 @@
+package app_cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/YuarenArt/marketgo/internal/client/clienttest"
+	"github.com/YuarenArt/marketgo/internal/db"
+	"github.com/YuarenArt/marketgo/internal/server/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// typedPasswords возвращает passwordPrompt, который по очереди выдаёт answers
+// и записывает показанные приглашения в prompts
+func typedPasswords(prompts *[]string, answers ...string) passwordPrompt {
+	return func(prompt string) (string, error) {
+		*prompts = append(*prompts, prompt)
+		if len(answers) == 0 {
+			return "", errNoTerminal
+		}
+		answer := answers[0]
+		answers = answers[1:]
+		return answer, nil
+	}
+}
+
+func TestUnitPasswordPrompt(t *testing.T) {
+	var got []*services.InputUserInfo
+	api := &clienttest.Mock{
+		LoginFunc: func(_ context.Context, input *services.InputUserInfo) error {
+			got = append(got, input)
+			return nil
+		},
+		RegisterFunc: func(_ context.Context, input *services.InputUserInfo) (db.User, error) {
+			got = append(got, input)
+			return db.User{ID: 1, Login: input.Login}, nil
+		},
+	}
+	app := newMockApp(t, api)
+	setup := func(t *testing.T, env string, answers ...string) *[]string {
+		got = nil
+		t.Setenv(passwordEnv, env)
+		var prompts []string
+		app.readPassword = typedPasswords(&prompts, answers...)
+		return &prompts
+	}
+
+	t.Run("argument is used without a prompt", func(t *testing.T) {
+		prompts := setup(t, "from-env", "typed")
+		require.NoError(t, app.handleLogin([]string{"seller", "s3cure-horse7"}))
+		require.Len(t, got, 1)
+		assert.Equal(t, "s3cure-horse7", got[0].Password)
+		assert.Empty(t, *prompts)
+	})
+
+	t.Run("environment is used before the prompt", func(t *testing.T) {
+		prompts := setup(t, "from-env", "typed")
+		require.NoError(t, app.handleLogin([]string{"seller"}))
+		require.Len(t, got, 1)
+		assert.Equal(t, "from-env", got[0].Password)
+		assert.Empty(t, *prompts)
+	})
+
+	t.Run("login prompts once", func(t *testing.T) {
+		prompts := setup(t, "", "typed")
+		require.NoError(t, app.handleLogin([]string{"seller"}))
+		require.Len(t, got, 1)
+		assert.Equal(t, "typed", got[0].Password)
+		assert.Len(t, *prompts, 1)
+	})
+
+	t.Run("register asks for confirmation", func(t *testing.T) {
+		prompts := setup(t, "", "s3cure-horse7", "s3cure-horse7")
+		require.NoError(t, app.handleRegister([]string{"seller"}))
+		require.Len(t, got, 1)
+		assert.Equal(t, "s3cure-horse7", got[0].Password)
+		assert.Len(t, *prompts, 2)
+	})
+
+	t.Run("rejected input does not call the api", func(t *testing.T) {
+		setup(t, "", "s3cure-horse7", "s3cure-horse8")
+		assert.ErrorContains(t, app.handleRegister([]string{"seller"}), "не совпадают")
+
+		setup(t, "", "")
+		assert.ErrorContains(t, app.handleLogin([]string{"seller"}), "пустым")
+
+		setup(t, "")
+		err := app.handleLogin([]string{"seller"})
+		assert.ErrorIs(t, err, errNoTerminal)
+		assert.ErrorContains(t, err, passwordEnv)
+		assert.Empty(t, got)
+	})
+}
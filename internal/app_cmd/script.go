@@ -0,0 +1,79 @@
+package app_cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ScriptCommands собирает команды сценария: сначала из файла path ("-" - stdin), затем из inline,
+// где они разделены ";". В файле команда занимает строку; пустые строки и комментарии (#) пропускаются.
+func ScriptCommands(path, inline string) ([]string, error) {
+	var commands []string
+	if path != "" {
+		r := io.Reader(os.Stdin)
+		if path != "-" {
+			file, err := os.Open(path)
+			if err != nil {
+				return nil, fmt.Errorf("открытие сценария: %w", err)
+			}
+			defer file.Close()
+			r = file
+		}
+		fromFile, err := readScript(r)
+		if err != nil {
+			return nil, fmt.Errorf("чтение сценария %s: %w", path, err)
+		}
+		commands = append(commands, fromFile...)
+	}
+	return append(commands, splitCommands(inline)...), nil
+}
+
+// readScript читает команды из r по одной в строке
+func readScript(r io.Reader) ([]string, error) {
+	var commands []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		commands = append(commands, line)
+	}
+	return commands, scanner.Err()
+}
+
+// RunScript выполняет commands по очереди в одном сеансе, так что вход, выполненный одной командой,
+// действует для следующих. Сценарий прерывается на первой ошибке, если не задан --continue-on-error.
+// Команда exit завершает сценарий. Возвращает код завершения последней неудачной команды или ExitOK.
+func (a *App) RunScript(commands []string) int {
+	code := ExitOK
+	for i, command := range commands {
+		if command == "exit" {
+			break
+		}
+		if a.verbose {
+			fmt.Fprintf(a.out, "> %s\n", command)
+		}
+		err := a.executeCommand(command)
+		a.saveCredentials()
+		if err == nil {
+			continue
+		}
+
+		a.logger.Error("Ошибка выполнения команды", "command", command, "error", err)
+		fmt.Fprintf(os.Stderr, "Команда %d (%s) завершилась ошибкой\n", i+1, command)
+		var name string
+		if args, _ := splitArgs(command); len(args) > 0 {
+			name = args[0]
+		}
+		a.printError(name, err)
+		code = ExitCode(err)
+		if !a.continueOnError {
+			return code
+		}
+	}
+	return code
+}
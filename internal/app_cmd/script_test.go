@@ -0,0 +1,89 @@
+package app_cmd
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/YuarenArt/marketgo/internal/client"
+	"github.com/YuarenArt/marketgo/internal/client/clienttest"
+	"github.com/YuarenArt/marketgo/internal/db"
+	"github.com/YuarenArt/marketgo/internal/server/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// seedingMock - клиент, создающий объявления только после входа
+func seedingMock() *clienttest.Mock {
+	api := &clienttest.Mock{}
+	api.LoginFunc = func(context.Context, *services.InputUserInfo) error {
+		api.SetToken("jwt")
+		return nil
+	}
+	api.PostAddFunc = func(_ context.Context, req *services.CreateAdRequest) (db.Ad, error) {
+		if token, _ := api.Tokens(); token == "" {
+			return db.Ad{}, &client.APIError{StatusCode: http.StatusUnauthorized, Code: "unauthorized", Message: "missing token"}
+		}
+		return db.Ad{ID: 1, Title: req.Title, Price: req.Price}, nil
+	}
+	return api
+}
+
+func TestUnitRunScript(t *testing.T) {
+	script := filepath.Join(t.TempDir(), "seed.txt")
+	require.NoError(t, os.WriteFile(script, []byte(`# демо-данные
+register seller s3cure-horse7
+
+login seller s3cure-horse7
+create-ad "Детский велосипед" "Почти новый" 150000
+create-ad Самокат Детский 5000
+`), 0o600))
+
+	t.Run("login persists across lines", func(t *testing.T) {
+		api := seedingMock()
+		app := newMockApp(t, api)
+		commands, err := ScriptCommands(script, "")
+		require.NoError(t, err)
+		require.Len(t, commands, 4)
+		assert.Equal(t, ExitOK, app.RunScript(commands))
+		assert.Equal(t, []string{"Register", "Login", "PostAdd", "PostAdd"}, api.Calls())
+	})
+
+	t.Run("first error aborts", func(t *testing.T) {
+		api := seedingMock()
+		app := newMockApp(t, api)
+		code := app.RunScript([]string{"create-ad Велосипед Горный 300", "login seller s3cure-horse7"})
+		assert.Equal(t, ExitAuth, code)
+		assert.Equal(t, []string{"PostAdd"}, api.Calls())
+	})
+
+	t.Run("continue on error runs every command", func(t *testing.T) {
+		api := seedingMock()
+		app := newMockApp(t, api)
+		app.continueOnError = true
+		code := app.RunScript([]string{"create-ad Велосипед Горный 300", "login seller s3cure-horse7", "create-ad Велосипед Горный 300"})
+		assert.Equal(t, ExitAuth, code)
+		assert.Equal(t, []string{"PostAdd", "Login", "PostAdd"}, api.Calls())
+	})
+
+	t.Run("inline commands with verbose echo", func(t *testing.T) {
+		api := seedingMock()
+		app := newMockApp(t, api)
+		var out bytes.Buffer
+		app.out, app.verbose = &out, true
+		commands, err := ScriptCommands("", `login seller s3cure-horse7; create-ad "Стол; дубовый" Крепкий 900`)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"login seller s3cure-horse7", `create-ad "Стол; дубовый" Крепкий 900`}, commands)
+		assert.Equal(t, ExitOK, app.RunScript(commands))
+		assert.Contains(t, out.String(), "> login seller s3cure-horse7\n")
+		assert.Contains(t, out.String(), `> create-ad "Стол; дубовый" Крепкий 900`)
+	})
+
+	t.Run("missing script file", func(t *testing.T) {
+		_, err := ScriptCommands(filepath.Join(t.TempDir(), "missing.txt"), "")
+		assert.Error(t, err)
+	})
+}
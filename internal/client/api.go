@@ -0,0 +1,45 @@
+package client
+
+import (
+	"context"
+
+	"github.com/YuarenArt/marketgo/internal/db"
+	"github.com/YuarenArt/marketgo/internal/server/services"
+	"github.com/YuarenArt/marketgo/pkg/buildinfo"
+)
+
+// ClientAPI - методы MarketGo API, которые предоставляет Client.
+// Код, зависящий от ClientAPI, а не от *Client, можно тестировать без HTTP-сервера
+// с заглушкой из пакета clienttest.
+type ClientAPI interface {
+	SetToken(token string)
+	SetRefreshToken(token string)
+	Tokens() (token, refreshToken string)
+	SetCaptchaToken(token string)
+
+	Register(ctx context.Context, input *services.InputUserInfo) (db.User, error)
+	Login(ctx context.Context, input *services.InputUserInfo) error
+	Refresh(ctx context.Context) error
+	Logout(ctx context.Context) error
+	Me(ctx context.Context) (services.Profile, error)
+	DeleteMe(ctx context.Context, password string) error
+
+	PostAdd(ctx context.Context, adReq *services.CreateAdRequest) (db.Ad, error)
+	GetAd(ctx context.Context, id int) (db.Ad, error)
+	UpdateAd(ctx context.Context, id int, req services.UpdateAdRequest) (db.Ad, error)
+	DeleteAd(ctx context.Context, id int) error
+	RenewAd(ctx context.Context, id int) (db.Ad, error)
+	GetSimilarAds(ctx context.Context, id, limit int) ([]db.Ad, error)
+	GetAds(ctx context.Context, req services.GetAdsRequest) (services.PagedAds, error)
+	GetAdsAfterCursor(ctx context.Context, req services.GetAdsRequest) (services.AdsPage, error)
+	GetMyAds(ctx context.Context, req services.GetAdsRequest) ([]db.Ad, error)
+	FavoriteAd(ctx context.Context, id int) error
+	UnfavoriteAd(ctx context.Context, id int) error
+	GetFavorites(ctx context.Context, req services.GetAdsRequest) ([]db.Ad, error)
+	UploadImage(ctx context.Context, path string) (services.UploadedImage, error)
+
+	GetAnnouncements(ctx context.Context) ([]db.Announcement, error)
+	GetServerVersion(ctx context.Context) (buildinfo.Info, error)
+}
+
+var _ ClientAPI = (*Client)(nil)
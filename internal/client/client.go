@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"compress/gzip"
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -11,10 +13,14 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/YuarenArt/marketgo/internal/db"
 	"github.com/YuarenArt/marketgo/internal/server/services"
+	"github.com/YuarenArt/marketgo/pkg/apierror"
+	"github.com/YuarenArt/marketgo/pkg/buildinfo"
 	"github.com/YuarenArt/marketgo/pkg/logging"
 )
 
@@ -23,9 +29,19 @@ const (
 	contentType         = "Content-Type"
 	acceptEncoding      = "Accept-Encoding"
 	authHeader          = "X-Auth-Token"
-	pathRegister        = "/register"
-	pathLogin           = "/login"
-	pathAds             = "/ads"
+	nonceHeader         = "X-Request-Nonce"
+	captchaHeader       = "X-Captcha-Token"
+	apiPrefix           = "/api/v1"
+	pathRegister        = apiPrefix + "/register"
+	pathLogin           = apiPrefix + "/login"
+	pathRefresh         = apiPrefix + "/refresh"
+	pathLogout          = apiPrefix + "/logout"
+	pathAds             = apiPrefix + "/ads"
+	pathMyAds           = apiPrefix + "/ads/my"
+	pathFavorites       = apiPrefix + "/favorites"
+	pathAnnouncements   = apiPrefix + "/announcements"
+	pathMe              = apiPrefix + "/users/me"
+	pathVersion         = "/version"
 	jsonContentType     = "application/json"
 	gzipEncoding        = "gzip"
 	errMsgMarshalFailed = "Не удалось сериализовать данные"
@@ -34,40 +50,239 @@ const (
 	errMsgDecodeFailed  = "Не удалось декодировать ответ"
 )
 
-// APIError представляет ошибку API с кодом статуса и сообщением
+// APIError представляет ошибку API с кодом статуса, машиночитаемым кодом и сообщением
 type APIError struct {
 	StatusCode int
-	Message    string
+	// Code - код ошибки из apierror; по нему клиент выбирает реакцию на ошибку
+	Code    string
+	Message string
+	// Details - дополнительные поля ошибки, например field и reason для weak_password
+	Details map[string]any
+	// Challenge - тип проверки (например, captcha), которую сервер требует пройти перед повтором запроса
+	Challenge string
+	// SiteKey - публичный ключ CAPTCHA для Challenge
+	SiteKey string
+	// RetryAfter - время до повтора из заголовка Retry-After, если сервер его прислал
+	RetryAfter time.Duration
 }
 
 func (e *APIError) Error() string {
 	return fmt.Sprintf("запрос не выполнен: %s (статус: %d)", e.Message, e.StatusCode)
 }
 
-// Client представляет HTTP-клиент для выполнения API-запросов
+// Ошибки, с которыми APIError совпадает в errors.Is по коду ошибки, а если сервер его не прислал - по статусу
+var (
+	ErrUnauthorized = errors.New("требуется авторизация")
+	ErrForbidden    = errors.New("недостаточно прав")
+	ErrNotFound     = errors.New("не найдено")
+	ErrValidation   = errors.New("некорректные данные запроса")
+	ErrRateLimited  = errors.New("слишком много запросов")
+)
+
+// errorKinds - коды apierror, соответствующие ошибкам клиента
+var errorKinds = map[string]error{
+	apierror.CodeUnauthorized:      ErrUnauthorized,
+	apierror.CodeForbidden:         ErrForbidden,
+	apierror.CodeNotFound:          ErrNotFound,
+	apierror.CodeInvalidInput:      ErrValidation,
+	apierror.CodeWeakPassword:      ErrValidation,
+	apierror.CodeRateLimited:       ErrRateLimited,
+	apierror.CodeChallengeRequired: ErrRateLimited,
+}
+
+// statusKinds - ошибки клиента по статусу ответа без кода
+var statusKinds = map[int]error{
+	http.StatusUnauthorized:    ErrUnauthorized,
+	http.StatusForbidden:       ErrForbidden,
+	http.StatusNotFound:        ErrNotFound,
+	http.StatusBadRequest:      ErrValidation,
+	http.StatusTooManyRequests: ErrRateLimited,
+}
+
+// Is позволяет проверять вид ошибки через errors.Is(err, client.ErrNotFound)
+func (e *APIError) Is(target error) bool {
+	if e.Code != "" {
+		if kind, ok := errorKinds[e.Code]; ok {
+			return kind == target
+		}
+		return false
+	}
+	return statusKinds[e.StatusCode] == target
+}
+
+// UserAgent возвращает User-Agent клиента по умолчанию: marketgo-cli/<версия сборки>
+func UserAgent() string {
+	return "marketgo-cli/" + buildinfo.Version
+}
+
+// DefaultTimeout - ограничение времени одного запроса, если в контексте вызова нет своего срока
+const DefaultTimeout = 10 * time.Second
+
+// Client представляет HTTP-клиент для выполнения API-запросов.
+// Client безопасен для одновременного использования из нескольких горутин.
 type Client struct {
-	client  *http.Client
+	client *http.Client
+	// timeout ограничивает запрос, контекст которого не содержит срока; 0 - без ограничения
+	timeout time.Duration
 	logger  logging.Logger
 	baseURL string
-	token   string
+	nonces  bool
+	// headers отправляются с каждым запросом; заголовки, которые выставляет сам клиент, их перекрывают
+	headers http.Header
+
+	// mu защищает токены и adsCache
+	mu    sync.Mutex
+	token string
+	// refreshToken обменивается на новый токен, когда сервер отвечает 401
+	refreshToken string
+	// captchaToken отправляется со следующим запросом после требования пройти CAPTCHA
+	captchaToken string
+	// adsCache - последние страницы GetAds с их ETag по строке запроса; при 304 ответ берётся отсюда
+	adsCache map[string]cachedAds
+	// refreshMu не даёт одновременно обновлять токены: после 401 в нескольких горутинах
+	// refresh-токен обменивается один раз, а остальные запросы повторяются с новым токеном
+	refreshMu sync.Mutex
+
+	retry RetryPolicy
+	// compressMin - тела запросов от этого размера сжимаются gzip; 0 - не сжимаются
+	compressMin int
+}
+
+// cachedAds - страница объявлений и ETag, с которым сервер её вернул
+type cachedAds struct {
+	etag string
+	page services.PagedAds
+}
+
+// conditional - результат условного GET-запроса: ETag отправляется в If-None-Match,
+// а при ответе 304 NotModified выставляется вместо декодирования тела в result
+type conditional struct {
+	etag        string
+	notModified bool
+	result      interface{}
+}
+
+// ClientOption описывает функцию настройки Client
+type ClientOption func(c *Client)
+
+// WithRequestNonces включает отправку уникального X-Request-Nonce с каждым изменяющим запросом.
+// Требуется серверам с включённой защитой от повторов (REPLAY_PROTECTION).
+func WithRequestNonces() ClientOption {
+	return func(c *Client) {
+		c.nonces = true
+	}
+}
+
+// WithHTTPClient задаёт http.Client для запросов, например с настроенным TLS или прокси.
+// Timeout переданного клиента становится таймаутом запросов, как при WithTimeout.
+func WithHTTPClient(hc *http.Client) ClientOption {
+	return func(c *Client) {
+		c.client = hc
+		c.timeout = hc.Timeout
+	}
+}
+
+// WithTransport задаёт транспорт запросов, не изменяя переданный ранее http.Client
+func WithTransport(rt http.RoundTripper) ClientOption {
+	return func(c *Client) {
+		hc := *c.client
+		hc.Transport = rt
+		c.client = &hc
+	}
+}
+
+// WithTimeout задаёт ограничение времени одного запроса (DefaultTimeout по умолчанию); 0 снимает ограничение.
+// Срок из контекста вызова имеет приоритет над таймаутом, поэтому долгие запросы
+// выполняются с собственным context.WithTimeout.
+func WithTimeout(d time.Duration) ClientOption {
+	return func(c *Client) {
+		c.timeout = d
+	}
+}
+
+// WithHeader добавляет заголовок, отправляемый с каждым запросом, например идентификатор арендатора
+// или трассировки. Так можно заменить User-Agent, но не Content-Type, заголовки авторизации
+// и другие заголовки, которые клиент выставляет сам.
+func WithHeader(key, value string) ClientOption {
+	return func(c *Client) {
+		if protectedHeaders[http.CanonicalHeaderKey(key)] {
+			c.logger.Warn("Заголовок выставляется клиентом и не может быть задан опцией", "header", key)
+			return
+		}
+		c.headers.Set(key, value)
+	}
+}
+
+// protectedHeaders - заголовки, которые клиент выставляет сам в зависимости от запроса
+var protectedHeaders = map[string]bool{
+	contentType:                            true,
+	acceptEncoding:                         true,
+	"Content-Encoding":                     true,
+	"If-None-Match":                        true,
+	http.CanonicalHeaderKey(authHeader):    true,
+	http.CanonicalHeaderKey(nonceHeader):   true,
+	http.CanonicalHeaderKey(captchaHeader): true,
+}
+
+// WithRequestCompression включает сжатие gzip тел запросов размером от minSize байт.
+// Сервер должен распаковывать такие запросы (DecompressMiddleware); короткие тела сжимать невыгодно.
+func WithRequestCompression(minSize int) ClientOption {
+	return func(c *Client) {
+		c.compressMin = minSize
+	}
 }
 
 // NewClient создает новый HTTP-клиент с заданной базовой URL и логгером
-func NewClient(baseURL string, logger logging.Logger) *Client {
-	return &Client{
-		client: &http.Client{
-			Timeout: 10 * time.Second, // Таймаут 10 секунд
-		},
-		logger:  logger,
-		baseURL: baseURL,
+func NewClient(baseURL string, logger logging.Logger, opts ...ClientOption) *Client {
+	c := &Client{
+		client:   &http.Client{},
+		timeout:  DefaultTimeout,
+		logger:   logger,
+		baseURL:  baseURL,
+		adsCache: make(map[string]cachedAds),
+		retry:    DefaultRetryPolicy,
+		headers:  http.Header{"User-Agent": {UserAgent()}},
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.client.Timeout != 0 {
+		// Таймаут применяется в send, чтобы не обрезать срок из контекста вызова
+		hc := *c.client
+		hc.Timeout = 0
+		c.client = &hc
+	}
+	return c
 }
 
 // SetToken обновляет токен авторизации клиента
 func (c *Client) SetToken(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.token = token
 }
 
+// SetRefreshToken обновляет refresh-токен клиента
+func (c *Client) SetRefreshToken(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.refreshToken = token
+}
+
+// Tokens возвращает текущие токен авторизации и refresh-токен, например чтобы сохранить их между запусками
+func (c *Client) Tokens() (token, refreshToken string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.token, c.refreshToken
+}
+
+// setTokens сохраняет пару токенов
+func (c *Client) setTokens(token, refreshToken string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.token, c.refreshToken = token, refreshToken
+}
+
 // marshalBody сериализует данные в JSON
 func marshalBody(data interface{}) ([]byte, error) {
 	body, err := json.Marshal(data)
@@ -77,18 +292,96 @@ func marshalBody(data interface{}) ([]byte, error) {
 	return body, nil
 }
 
-// doRequest выполняет HTTP-запрос и декодирует ответ
+// doRequest выполняет HTTP-запрос и декодирует ответ.
+// Временные ошибки повторяются по политике повторов клиента (см. sendWithRetry).
+// Если авторизованный запрос получил 401 и у клиента есть refresh-токен, токены обновляются
+// и запрос повторяется один раз. Если токен уже обновила другая горутина, запрос сразу
+// повторяется с новым токеном.
 func (c *Client) doRequest(ctx context.Context, method, path string, body io.Reader, useAuth bool, result interface{}, logContext ...interface{}) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = io.ReadAll(body); err != nil {
+			c.logger.Error(errMsgRequestFailed, append(logContext, "error", err)...)
+			return fmt.Errorf("чтение тела запроса: %w", err)
+		}
+	}
+
+	usedToken, refreshToken := c.Tokens()
+	err := c.sendWithRetry(ctx, method, path, payload, useAuth, result, logContext...)
+	var apiErr *APIError
+	if !useAuth || refreshToken == "" || !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		return err
+	}
+	if refreshErr := c.refreshAfter(ctx, usedToken); refreshErr != nil {
+		return err
+	}
+	return c.sendWithRetry(ctx, method, path, payload, useAuth, result, logContext...)
+}
+
+// send выполняет один HTTP-запрос с телом payload и декодирует ответ
+func (c *Client) send(ctx context.Context, method, path string, payload []byte, useAuth bool, result interface{}, logContext ...interface{}) error {
+	if _, ok := ctx.Deadline(); !ok && c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	bodyType := requestContentType(ctx)
+	compressed := c.compressMin > 0 && len(payload) >= c.compressMin && bodyType == jsonContentType
+	if compressed {
+		var err error
+		if payload, err = gzipPayload(payload); err != nil {
+			c.logger.Error(errMsgGzipFailed, append(logContext, "error", err)...)
+			return fmt.Errorf("Gzip: %w", err)
+		}
+	}
+
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
 	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
 	if err != nil {
 		c.logger.Error(errMsgRequestFailed, append(logContext, "error", err)...)
 		return fmt.Errorf("создание запроса: %w", err)
 	}
 
-	req.Header.Set(contentType, jsonContentType)
+	for key, values := range c.headers {
+		req.Header[key] = values
+	}
+	req.Header.Set(contentType, bodyType)
+	if compressed {
+		req.Header.Set("Content-Encoding", gzipEncoding)
+	}
 	req.Header.Set(acceptEncoding, gzipEncoding)
+	c.mu.Lock()
+	token, captchaToken := c.token, c.captchaToken
+	c.captchaToken = ""
+	c.mu.Unlock()
+
 	if useAuth {
-		req.Header.Set(authHeader, c.token)
+		req.Header.Set(authHeader, token)
+		if c.nonces && method != http.MethodGet {
+			nonce, err := newNonce()
+			if err != nil {
+				c.logger.Error(errMsgRequestFailed, append(logContext, "error", err)...)
+				return fmt.Errorf("генерация nonce: %w", err)
+			}
+			req.Header.Set(nonceHeader, nonce)
+		}
+	}
+
+	if captchaToken != "" {
+		req.Header.Set(captchaHeader, captchaToken)
+	}
+
+	cond, isConditional := result.(*conditional)
+	if isConditional {
+		if cond.etag != "" {
+			req.Header.Set("If-None-Match", cond.etag)
+		}
+		result = cond.result
 	}
 
 	resp, err := c.client.Do(req)
@@ -98,16 +391,34 @@ func (c *Client) doRequest(ctx context.Context, method, path string, body io.Rea
 	}
 	defer resp.Body.Close()
 
+	if isConditional {
+		if resp.StatusCode == http.StatusNotModified {
+			cond.notModified = true
+			return nil
+		}
+		if resp.StatusCode == http.StatusOK {
+			cond.etag = resp.Header.Get("ETag")
+		}
+	}
+
 	// Проверяем статус ответа
-	if resp.StatusCode != http.StatusOK {
-		var errResp map[string]string
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		var errResp apierror.Error
 		_ = json.NewDecoder(resp.Body).Decode(&errResp)
-		msg := errResp["error"]
+		msg := errResp.Message
 		if msg == "" {
 			msg = fmt.Sprintf("код статуса %d", resp.StatusCode)
 		}
-		c.logger.Error(errMsgRequestFailed, append(logContext, "status", resp.StatusCode, "error", msg)...)
-		return &APIError{StatusCode: resp.StatusCode, Message: msg}
+		c.logger.Error(errMsgRequestFailed, append(logContext, "status", resp.StatusCode, "code", errResp.Code, "error", msg)...)
+		apiErr := &APIError{StatusCode: resp.StatusCode, Code: errResp.Code, Message: msg, Details: errResp.Details}
+		apiErr.Challenge, _ = errResp.Details["challenge"].(string)
+		apiErr.SiteKey, _ = errResp.Details["site_key"].(string)
+		apiErr.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+		return apiErr
+	}
+
+	if resp.StatusCode == http.StatusNoContent || result == nil {
+		return nil
 	}
 
 	reader := resp.Body
@@ -129,6 +440,35 @@ func (c *Client) doRequest(ctx context.Context, method, path string, body io.Rea
 	return nil
 }
 
+// SetCaptchaToken задаёт токен пройденной CAPTCHA, который будет отправлен со следующим запросом
+func (c *Client) SetCaptchaToken(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.captchaToken = token
+}
+
+// gzipPayload сжимает тело запроса gzip
+func gzipPayload(payload []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(payload); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// newNonce возвращает случайный nonce запроса
+func newNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
 // Register регистрирует нового пользователя
 func (c *Client) Register(ctx context.Context, input *services.InputUserInfo) (db.User, error) {
 	if input == nil || input.Login == "" {
@@ -164,19 +504,82 @@ func (c *Client) Login(ctx context.Context, input *services.InputUserInfo) error
 		return err
 	}
 
-	var result struct {
-		Token string `json:"token"`
-	}
-	err = c.doRequest(ctx, http.MethodPost, pathLogin, bytes.NewBuffer(body), false, &result, "login", input.Login)
+	var tokens services.TokenPair
+	err = c.doRequest(ctx, http.MethodPost, pathLogin, bytes.NewBuffer(body), false, &tokens, "login", input.Login)
 	if err != nil {
 		return err
 	}
 
-	c.SetToken(result.Token)
+	c.SetToken(tokens.Token)
+	c.SetRefreshToken(tokens.RefreshToken)
 	c.logger.Info("Вход успешен", "login", input.Login)
 	return nil
 }
 
+// Refresh обменивает сохранённый refresh-токен на новую пару токенов.
+// Если сервер отклонил refresh-токен, оба токена сбрасываются и нужно войти заново.
+func (c *Client) Refresh(ctx context.Context) error {
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
+	return c.refresh(ctx)
+}
+
+// refreshAfter обновляет токены после 401 на запрос с токеном usedToken,
+// если их ещё не обновила другая горутина
+func (c *Client) refreshAfter(ctx context.Context, usedToken string) error {
+	c.refreshMu.Lock()
+	defer c.refreshMu.Unlock()
+	if token, _ := c.Tokens(); token != usedToken {
+		return nil
+	}
+	return c.refresh(ctx)
+}
+
+// refresh обменивает refresh-токен на новую пару; вызывается под refreshMu
+func (c *Client) refresh(ctx context.Context) error {
+	_, refreshToken := c.Tokens()
+	if refreshToken == "" {
+		return errors.New("refresh-токен не задан")
+	}
+
+	body, err := marshalBody(services.RefreshRequest{RefreshToken: refreshToken})
+	if err != nil {
+		c.logger.Error(errMsgMarshalFailed, "error", err)
+		return err
+	}
+
+	var tokens services.TokenPair
+	if err := c.doRequest(ctx, http.MethodPost, pathRefresh, bytes.NewBuffer(body), false, &tokens); err != nil {
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusUnauthorized {
+			c.setTokens("", "")
+		}
+		return err
+	}
+
+	c.setTokens(tokens.Token, tokens.RefreshToken)
+	c.logger.Debug("Токен обновлён")
+	return nil
+}
+
+// Logout отзывает токен доступа и refresh-токен клиента на сервере и сбрасывает их
+func (c *Client) Logout(ctx context.Context) error {
+	_, refreshToken := c.Tokens()
+	body, err := marshalBody(services.LogoutRequest{RefreshToken: refreshToken})
+	if err != nil {
+		c.logger.Error(errMsgMarshalFailed, "error", err)
+		return err
+	}
+	if err := c.doRequest(ctx, http.MethodPost, pathLogout, bytes.NewBuffer(body), true, nil); err != nil {
+		return err
+	}
+
+	c.SetToken("")
+	c.SetRefreshToken("")
+	c.logger.Info("Выход выполнен")
+	return nil
+}
+
 // PostAdd создает новое объявление
 func (c *Client) PostAdd(ctx context.Context, adReq *services.CreateAdRequest) (db.Ad, error) {
 	if adReq == nil || adReq.Title == "" {
@@ -199,13 +602,280 @@ func (c *Client) PostAdd(ctx context.Context, adReq *services.CreateAdRequest) (
 	return ad, nil
 }
 
-// GetAds получает список объявлений с фильтрацией и сортировкой
-func (c *Client) GetAds(ctx context.Context, req services.GetAdsRequest) ([]db.Ad, error) {
+// GetAd получает объявление по ID
+func (c *Client) GetAd(ctx context.Context, id int) (db.Ad, error) {
+	if id <= 0 {
+		c.logger.Error("Некорректный ID объявления", "ad_id", id)
+		return db.Ad{}, fmt.Errorf("некорректный ID объявления: %d", id)
+	}
+
+	var ad db.Ad
+	if err := c.doRequest(ctx, http.MethodGet, adPath(id), nil, true, &ad, "ad_id", id); err != nil {
+		return db.Ad{}, err
+	}
+
+	c.logger.Info("Объявление получено", "ad_id", ad.ID)
+	return ad, nil
+}
+
+// UpdateAd изменяет заданные поля объявления владельца
+func (c *Client) UpdateAd(ctx context.Context, id int, req services.UpdateAdRequest) (db.Ad, error) {
+	if id <= 0 {
+		c.logger.Error("Некорректный ID объявления", "ad_id", id)
+		return db.Ad{}, fmt.Errorf("некорректный ID объявления: %d", id)
+	}
+	if req.IsEmpty() {
+		return db.Ad{}, errors.New("не указано ни одного изменяемого поля")
+	}
+
+	body, err := marshalBody(req)
+	if err != nil {
+		c.logger.Error(errMsgMarshalFailed, "ad_id", id, "error", err)
+		return db.Ad{}, err
+	}
+
+	var ad db.Ad
+	if err := c.doRequest(ctx, http.MethodPatch, adPath(id), bytes.NewBuffer(body), true, &ad, "ad_id", id); err != nil {
+		return db.Ad{}, err
+	}
+
+	c.logger.Info("Объявление изменено", "ad_id", ad.ID)
+	return ad, nil
+}
+
+// DeleteAd удаляет объявление владельца
+func (c *Client) DeleteAd(ctx context.Context, id int) error {
+	if id <= 0 {
+		c.logger.Error("Некорректный ID объявления", "ad_id", id)
+		return fmt.Errorf("некорректный ID объявления: %d", id)
+	}
+
+	if err := c.doRequest(ctx, http.MethodDelete, adPath(id), nil, true, nil, "ad_id", id); err != nil {
+		return err
+	}
+
+	c.logger.Info("Объявление удалено", "ad_id", id)
+	return nil
+}
+
+// RenewAd продлевает публикацию своего объявления id; архивное объявление снова становится активным
+func (c *Client) RenewAd(ctx context.Context, id int) (db.Ad, error) {
+	if id <= 0 {
+		c.logger.Error("Некорректный ID объявления", "ad_id", id)
+		return db.Ad{}, fmt.Errorf("некорректный ID объявления: %d", id)
+	}
+
+	var ad db.Ad
+	if err := c.doRequest(ctx, http.MethodPost, adPath(id)+"/renew", nil, true, &ad, "ad_id", id); err != nil {
+		return db.Ad{}, err
+	}
+
+	c.logger.Info("Объявление продлено", "ad_id", ad.ID, "expires_at", ad.ExpiresAt)
+	return ad, nil
+}
+
+// GetSimilarAds получает до limit объявлений, похожих на объявление id.
+// При limit <= 0 сервер возвращает количество по умолчанию.
+func (c *Client) GetSimilarAds(ctx context.Context, id, limit int) ([]db.Ad, error) {
+	if id <= 0 {
+		c.logger.Error("Некорректный ID объявления", "ad_id", id)
+		return nil, fmt.Errorf("некорректный ID объявления: %d", id)
+	}
+
+	path := adPath(id) + "/similar"
+	if limit > 0 {
+		path += "?" + url.Values{"limit": []string{strconv.Itoa(limit)}}.Encode()
+	}
+
+	var ads []db.Ad
+	if err := c.doRequest(ctx, http.MethodGet, path, nil, true, &ads, "ad_id", id); err != nil {
+		return nil, err
+	}
+
+	c.logger.Info("Похожие объявления получены", "ad_id", id, "count", len(ads))
+	return ads, nil
+}
+
+// adPath возвращает путь объявления id
+func adPath(id int) string {
+	return pathAds + "/" + strconv.Itoa(id)
+}
+
+// GetAds получает страницу объявлений с фильтрацией, сортировкой и метаданными пагинации.
+// Если сервер вернул массив без метаданных, TotalPages и Total в ответе равны нулю.
+// Повторный запрос той же страницы отправляет ETag прошлого ответа; при 304 возвращается сохранённая страница.
+func (c *Client) GetAds(ctx context.Context, req services.GetAdsRequest) (services.PagedAds, error) {
+	if req.Page < 1 || req.PageSize < 1 || req.PageSize > 100 {
+		c.logger.Error("Некорректные параметры", "page", req.Page, "page_size", req.PageSize)
+		return services.PagedAds{}, fmt.Errorf("некорректные параметры: page=%d, page_size=%d", req.Page, req.PageSize)
+	}
+
+	query := pageQuery(req)
+	query.Set("include_meta", "true")
+	setAdsFilterQuery(query, req)
+
+	path := pathAds + "?" + query.Encode()
+	c.mu.Lock()
+	cached, hasCached := c.adsCache[path]
+	c.mu.Unlock()
+
+	var raw json.RawMessage
+	cond := &conditional{etag: cached.etag, result: &raw}
+	if err := c.doRequest(ctx, http.MethodGet, path, nil, true, cond, "page", req.Page); err != nil {
+		return services.PagedAds{}, err
+	}
+	if cond.notModified && hasCached {
+		c.logger.Info("Объявления не изменились", "page", cached.page.Page, "count", len(cached.page.Items))
+		return cached.page, nil
+	}
+
+	var paged services.PagedAds
+	if trimmed := bytes.TrimSpace(raw); len(trimmed) > 0 && trimmed[0] == '[' {
+		paged.Page, paged.PageSize = req.Page, req.PageSize
+		if err := json.Unmarshal(trimmed, &paged.Items); err != nil {
+			c.logger.Error(errMsgDecodeFailed, "page", req.Page, "error", err)
+			return services.PagedAds{}, fmt.Errorf("декодирование: %w", err)
+		}
+	} else if err := json.Unmarshal(raw, &paged); err != nil {
+		c.logger.Error(errMsgDecodeFailed, "page", req.Page, "error", err)
+		return services.PagedAds{}, fmt.Errorf("декодирование: %w", err)
+	}
+	// старые версии сервера отдавали null вместо пустого списка
+	if paged.Items == nil {
+		paged.Items = []db.Ad{}
+	}
+	if cond.etag != "" {
+		c.mu.Lock()
+		c.adsCache[path] = cachedAds{etag: cond.etag, page: paged}
+		c.mu.Unlock()
+	}
+
+	c.logger.Info("Объявления получены", "page", paged.Page, "count", len(paged.Items), "total", paged.Total)
+	return paged, nil
+}
+
+// GetAdsAfterCursor получает страницу ленты объявлений после req.Cursor.
+// Пустой курсор запрашивает первую страницу; NextCursor ответа передаётся в следующий вызов.
+func (c *Client) GetAdsAfterCursor(ctx context.Context, req services.GetAdsRequest) (services.AdsPage, error) {
+	if req.PageSize < 1 || req.PageSize > 100 {
+		c.logger.Error("Некорректные параметры", "page_size", req.PageSize)
+		return services.AdsPage{}, fmt.Errorf("некорректные параметры: page_size=%d", req.PageSize)
+	}
+
+	query := pageQuery(req)
+	query.Del("page")
+	query.Set("cursor", req.Cursor)
+	setAdsFilterQuery(query, req)
+
+	var page services.AdsPage
+	if err := c.doRequest(ctx, http.MethodGet, pathAds+"?"+query.Encode(), nil, true, &page, "cursor", req.Cursor); err != nil {
+		return services.AdsPage{}, err
+	}
+
+	c.logger.Info("Объявления получены", "cursor", req.Cursor, "count", len(page.Ads))
+	return page, nil
+}
+
+// setAdsFilterQuery добавляет в query фильтры списка объявлений
+func setAdsFilterQuery(query url.Values, req services.GetAdsRequest) {
+	if req.Query != "" {
+		query.Set("q", req.Query)
+	}
+	if req.MinPrice > 0 {
+		query.Set("min_price", strconv.FormatInt(req.MinPrice, 10))
+	}
+	if req.MaxPrice > 0 {
+		query.Set("max_price", strconv.FormatInt(req.MaxPrice, 10))
+	}
+	if len(req.Statuses) > 0 {
+		query.Set("status", strings.Join(req.Statuses, ","))
+	}
+	for _, tag := range req.Tags {
+		query.Add("tag", tag)
+	}
+	if len(req.Cities) > 0 {
+		query.Set("city", strings.Join(req.Cities, ","))
+	}
+	if !req.CreatedFrom.IsZero() {
+		query.Set("created_from", req.CreatedFrom.Format(time.RFC3339))
+	}
+	if !req.CreatedTo.IsZero() {
+		query.Set("created_to", req.CreatedTo.Format(time.RFC3339))
+	}
+}
+
+// GetMyAds получает объявления текущего пользователя с пагинацией и сортировкой
+func (c *Client) GetMyAds(ctx context.Context, req services.GetAdsRequest) ([]db.Ad, error) {
+	if req.Page < 1 || req.PageSize < 1 || req.PageSize > 100 {
+		c.logger.Error("Некорректные параметры", "page", req.Page, "page_size", req.PageSize)
+		return nil, fmt.Errorf("некорректные параметры: page=%d, page_size=%d", req.Page, req.PageSize)
+	}
+
+	query := pageQuery(req)
+
+	var ads []db.Ad
+	if err := c.doRequest(ctx, http.MethodGet, pathMyAds+"?"+query.Encode(), nil, true, &ads, "page", req.Page); err != nil {
+		return nil, err
+	}
+
+	c.logger.Info("Свои объявления получены", "page", req.Page, "count", len(ads))
+	return ads, nil
+}
+
+// FavoriteAd добавляет объявление id в избранное; повторное добавление не считается ошибкой
+func (c *Client) FavoriteAd(ctx context.Context, id int) error {
+	if id <= 0 {
+		c.logger.Error("Некорректный ID объявления", "ad_id", id)
+		return fmt.Errorf("некорректный ID объявления: %d", id)
+	}
+
+	if err := c.doRequest(ctx, http.MethodPost, adPath(id)+"/favorite", nil, true, nil, "ad_id", id); err != nil {
+		return err
+	}
+
+	c.logger.Info("Объявление добавлено в избранное", "ad_id", id)
+	return nil
+}
+
+// UnfavoriteAd убирает объявление id из избранного
+func (c *Client) UnfavoriteAd(ctx context.Context, id int) error {
+	if id <= 0 {
+		c.logger.Error("Некорректный ID объявления", "ad_id", id)
+		return fmt.Errorf("некорректный ID объявления: %d", id)
+	}
+
+	if err := c.doRequest(ctx, http.MethodDelete, adPath(id)+"/favorite", nil, true, nil, "ad_id", id); err != nil {
+		return err
+	}
+
+	c.logger.Info("Объявление убрано из избранного", "ad_id", id)
+	return nil
+}
+
+// GetFavorites получает страницу избранных объявлений текущего пользователя.
+// Сортировка и фильтры запроса не передаются: избранное упорядочено по времени добавления.
+func (c *Client) GetFavorites(ctx context.Context, req services.GetAdsRequest) ([]db.Ad, error) {
 	if req.Page < 1 || req.PageSize < 1 || req.PageSize > 100 {
 		c.logger.Error("Некорректные параметры", "page", req.Page, "page_size", req.PageSize)
 		return nil, fmt.Errorf("некорректные параметры: page=%d, page_size=%d", req.Page, req.PageSize)
 	}
 
+	query := url.Values{
+		"page":      []string{strconv.Itoa(req.Page)},
+		"page_size": []string{strconv.Itoa(req.PageSize)},
+	}
+
+	var ads []db.Ad
+	if err := c.doRequest(ctx, http.MethodGet, pathFavorites+"?"+query.Encode(), nil, true, &ads, "page", req.Page); err != nil {
+		return nil, err
+	}
+
+	c.logger.Info("Избранное получено", "page", req.Page, "count", len(ads))
+	return ads, nil
+}
+
+// pageQuery формирует параметры пагинации и сортировки запроса
+func pageQuery(req services.GetAdsRequest) url.Values {
 	query := url.Values{
 		"page":      []string{strconv.Itoa(req.Page)},
 		"page_size": []string{strconv.Itoa(req.PageSize)},
@@ -216,18 +886,54 @@ func (c *Client) GetAds(ctx context.Context, req services.GetAdsRequest) ([]db.A
 	if req.SortOrder != "" {
 		query.Set("sort_order", req.SortOrder)
 	}
-	if req.MinPrice > 0 {
-		query.Set("min_price", strconv.FormatInt(req.MinPrice, 10))
+	return query
+}
+
+// Me получает профиль текущего пользователя
+func (c *Client) Me(ctx context.Context) (services.Profile, error) {
+	var profile services.Profile
+	if err := c.doRequest(ctx, http.MethodGet, pathMe, nil, true, &profile); err != nil {
+		return services.Profile{}, err
 	}
-	if req.MaxPrice > 0 {
-		query.Set("max_price", strconv.FormatInt(req.MaxPrice, 10))
+
+	c.logger.Info("Профиль получен", "user_id", profile.ID)
+	return profile, nil
+}
+
+// DeleteMe удаляет учётную запись текущего пользователя, подтверждая её паролем.
+// После удаления токен сбрасывается.
+func (c *Client) DeleteMe(ctx context.Context, password string) error {
+	body, err := marshalBody(services.DeleteAccountRequest{Password: password})
+	if err != nil {
+		c.logger.Error(errMsgMarshalFailed, "error", err)
+		return err
+	}
+	if err := c.doRequest(ctx, http.MethodDelete, pathMe, bytes.NewBuffer(body), true, nil); err != nil {
+		return err
 	}
 
-	var ads []db.Ad
-	if err := c.doRequest(ctx, http.MethodGet, pathAds+"?"+query.Encode(), nil, true, &ads, "page", req.Page); err != nil {
+	c.SetToken("")
+	c.SetRefreshToken("")
+	c.logger.Info("Учётная запись удалена")
+	return nil
+}
+
+// GetAnnouncements получает действующие объявления администрации. Авторизация не требуется.
+func (c *Client) GetAnnouncements(ctx context.Context) ([]db.Announcement, error) {
+	var announcements []db.Announcement
+	if err := c.doRequest(ctx, http.MethodGet, pathAnnouncements, nil, false, &announcements); err != nil {
 		return nil, err
 	}
 
-	c.logger.Info("Объявления получены", "page", req.Page, "count", len(ads))
-	return ads, nil
+	c.logger.Debug("Объявления администрации получены", "count", len(announcements))
+	return announcements, nil
+}
+
+// GetServerVersion получает сведения о сборке сервера
+func (c *Client) GetServerVersion(ctx context.Context) (buildinfo.Info, error) {
+	var info buildinfo.Info
+	if err := c.doRequest(ctx, http.MethodGet, pathVersion, nil, false, &info); err != nil {
+		return buildinfo.Info{}, err
+	}
+	return info, nil
 }
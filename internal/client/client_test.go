@@ -0,0 +1,153 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/YuarenArt/marketgo/pkg/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestClientOptions(t *testing.T) {
+	noRetry := WithRetry(RetryPolicy{Attempts: 1})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(200 * time.Millisecond):
+		case <-r.Context().Done():
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"version": "v1"}`))
+	}))
+	defer slow.Close()
+
+	t.Run("custom transport is used", func(t *testing.T) {
+		var seen string
+		transport := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			seen = r.URL.Path
+			return http.DefaultTransport.RoundTrip(r)
+		})
+		c := NewClient(slow.URL, logging.NewLogger(nil), WithTransport(transport))
+		_, err := c.GetServerVersion(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, pathVersion, seen)
+	})
+
+	t.Run("transport does not modify the caller's client", func(t *testing.T) {
+		hc := &http.Client{}
+		NewClient(slow.URL, logging.NewLogger(nil), WithHTTPClient(hc), WithTransport(http.DefaultTransport))
+		assert.Nil(t, hc.Transport)
+	})
+
+	t.Run("client timeout applies without a deadline", func(t *testing.T) {
+		c := NewClient(slow.URL, logging.NewLogger(nil), noRetry, WithTimeout(50*time.Millisecond))
+		_, err := c.GetServerVersion(context.Background())
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("context deadline overrides the client timeout", func(t *testing.T) {
+		hc := &http.Client{Timeout: 50 * time.Millisecond}
+		c := NewClient(slow.URL, logging.NewLogger(nil), noRetry, WithHTTPClient(hc))
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		info, err := c.GetServerVersion(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "v1", info.Version)
+		assert.Equal(t, 50*time.Millisecond, hc.Timeout)
+	})
+}
+
+func TestAPIErrorKinds(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		kind   error
+	}{
+		{"unauthorized code", http.StatusUnauthorized, `{"code": "unauthorized", "message": "invalid token"}`, ErrUnauthorized},
+		{"forbidden code", http.StatusForbidden, `{"code": "forbidden", "message": "insufficient permissions"}`, ErrForbidden},
+		{"not found code", http.StatusNotFound, `{"code": "not_found", "message": "объявление не найдено"}`, ErrNotFound},
+		{"invalid input code", http.StatusBadRequest, `{"code": "invalid_input", "message": "некорректные данные"}`, ErrValidation},
+		{"weak password code", http.StatusBadRequest, `{"code": "weak_password", "message": "weak password"}`, ErrValidation},
+		{"rate limited code", http.StatusTooManyRequests, `{"code": "rate_limited", "message": "too many requests"}`, ErrRateLimited},
+		{"challenge code", http.StatusTooManyRequests, `{"code": "challenge_required", "message": "captcha verification required"}`, ErrRateLimited},
+		{"unauthorized status without code", http.StatusUnauthorized, `{"error": "unauthorized"}`, ErrUnauthorized},
+		{"not found status without code", http.StatusNotFound, ``, ErrNotFound},
+		{"bad request status without code", http.StatusBadRequest, ``, ErrValidation},
+		{"too many requests status without code", http.StatusTooManyRequests, ``, ErrRateLimited},
+		{"other code", http.StatusConflict, `{"code": "conflict", "message": "login already exists"}`, nil},
+	}
+	kinds := []error{ErrUnauthorized, ErrForbidden, ErrNotFound, ErrValidation, ErrRateLimited}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer srv.Close()
+
+			c := NewClient(srv.URL, logging.NewLogger(nil), WithRetry(RetryPolicy{Attempts: 1}))
+			_, err := c.GetServerVersion(context.Background())
+			var apiErr *APIError
+			require.ErrorAs(t, err, &apiErr)
+			assert.Equal(t, tt.status, apiErr.StatusCode)
+			for _, kind := range kinds {
+				assert.Equal(t, kind == tt.kind, errors.Is(err, kind), kind.Error())
+			}
+		})
+	}
+}
+
+func TestDefaultHeaders(t *testing.T) {
+	var got http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id": 1}`))
+	}))
+	defer srv.Close()
+
+	t.Run("user agent and custom headers are sent", func(t *testing.T) {
+		c := NewClient(srv.URL, logging.NewLogger(nil), WithHeader("X-Tenant-ID", "acme"), WithHeader("traceparent", "00-abc-def-01"))
+		_, err := c.GetServerVersion(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, UserAgent(), got.Get("User-Agent"))
+		assert.Regexp(t, `^marketgo-cli/.+`, got.Get("User-Agent"))
+		assert.Equal(t, "acme", got.Get("X-Tenant-ID"))
+		assert.Equal(t, "00-abc-def-01", got.Get("Traceparent"))
+	})
+
+	t.Run("user agent can be replaced", func(t *testing.T) {
+		c := NewClient(srv.URL, logging.NewLogger(nil), WithHeader("User-Agent", "shop-sync/2.0"))
+		_, err := c.GetServerVersion(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"shop-sync/2.0"}, got.Values("User-Agent"))
+	})
+
+	t.Run("library headers are not overridden", func(t *testing.T) {
+		c := NewClient(srv.URL, logging.NewLogger(nil),
+			WithHeader("Content-Type", "text/plain"), WithHeader(authHeader, "forged"))
+		c.SetToken("jwt")
+		_, err := c.Me(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []string{jsonContentType}, got.Values("Content-Type"))
+		assert.Equal(t, []string{"jwt"}, got.Values(authHeader))
+
+		_, err = c.GetServerVersion(context.Background())
+		require.NoError(t, err)
+		assert.Empty(t, got.Values(authHeader), "unauthenticated requests carry no token")
+	})
+}
@@ -0,0 +1,259 @@
+// Package clienttest содержит заглушку client.ClientAPI для модульных тестов без HTTP-сервера.
+package clienttest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/YuarenArt/marketgo/internal/client"
+	"github.com/YuarenArt/marketgo/internal/db"
+	"github.com/YuarenArt/marketgo/internal/server/services"
+	"github.com/YuarenArt/marketgo/pkg/buildinfo"
+)
+
+// Mock реализует client.ClientAPI. Поведение метода задаётся полем ...Func; если оно не задано,
+// метод возвращает нулевые значения без ошибки. Токены хранятся как в настоящем клиенте.
+// Все вызовы записываются в Calls по имени метода.
+type Mock struct {
+	RegisterFunc          func(ctx context.Context, input *services.InputUserInfo) (db.User, error)
+	LoginFunc             func(ctx context.Context, input *services.InputUserInfo) error
+	RefreshFunc           func(ctx context.Context) error
+	LogoutFunc            func(ctx context.Context) error
+	MeFunc                func(ctx context.Context) (services.Profile, error)
+	DeleteMeFunc          func(ctx context.Context, password string) error
+	PostAddFunc           func(ctx context.Context, adReq *services.CreateAdRequest) (db.Ad, error)
+	GetAdFunc             func(ctx context.Context, id int) (db.Ad, error)
+	UpdateAdFunc          func(ctx context.Context, id int, req services.UpdateAdRequest) (db.Ad, error)
+	DeleteAdFunc          func(ctx context.Context, id int) error
+	RenewAdFunc           func(ctx context.Context, id int) (db.Ad, error)
+	GetSimilarAdsFunc     func(ctx context.Context, id, limit int) ([]db.Ad, error)
+	GetAdsFunc            func(ctx context.Context, req services.GetAdsRequest) (services.PagedAds, error)
+	GetAdsAfterCursorFunc func(ctx context.Context, req services.GetAdsRequest) (services.AdsPage, error)
+	GetMyAdsFunc          func(ctx context.Context, req services.GetAdsRequest) ([]db.Ad, error)
+	FavoriteAdFunc        func(ctx context.Context, id int) error
+	UnfavoriteAdFunc      func(ctx context.Context, id int) error
+	GetFavoritesFunc      func(ctx context.Context, req services.GetAdsRequest) ([]db.Ad, error)
+	UploadImageFunc       func(ctx context.Context, path string) (services.UploadedImage, error)
+	GetAnnouncementsFunc  func(ctx context.Context) ([]db.Announcement, error)
+	GetServerVersionFunc  func(ctx context.Context) (buildinfo.Info, error)
+
+	mu           sync.Mutex
+	calls        []string
+	token        string
+	refreshToken string
+	captchaToken string
+}
+
+var _ client.ClientAPI = (*Mock)(nil)
+
+// Calls возвращает имена вызванных методов в порядке вызова
+func (m *Mock) Calls() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]string(nil), m.calls...)
+}
+
+// CaptchaToken возвращает токен, переданный в SetCaptchaToken
+func (m *Mock) CaptchaToken() string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.captchaToken
+}
+
+func (m *Mock) record(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, name)
+}
+
+func (m *Mock) SetToken(token string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.token = token
+}
+
+func (m *Mock) SetRefreshToken(token string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.refreshToken = token
+}
+
+func (m *Mock) Tokens() (token, refreshToken string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.token, m.refreshToken
+}
+
+func (m *Mock) SetCaptchaToken(token string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.captchaToken = token
+}
+
+func (m *Mock) Register(ctx context.Context, input *services.InputUserInfo) (db.User, error) {
+	m.record("Register")
+	if m.RegisterFunc != nil {
+		return m.RegisterFunc(ctx, input)
+	}
+	return db.User{}, nil
+}
+
+func (m *Mock) Login(ctx context.Context, input *services.InputUserInfo) error {
+	m.record("Login")
+	if m.LoginFunc != nil {
+		return m.LoginFunc(ctx, input)
+	}
+	return nil
+}
+
+func (m *Mock) Refresh(ctx context.Context) error {
+	m.record("Refresh")
+	if m.RefreshFunc != nil {
+		return m.RefreshFunc(ctx)
+	}
+	return nil
+}
+
+func (m *Mock) Logout(ctx context.Context) error {
+	m.record("Logout")
+	if m.LogoutFunc != nil {
+		return m.LogoutFunc(ctx)
+	}
+	return nil
+}
+
+func (m *Mock) Me(ctx context.Context) (services.Profile, error) {
+	m.record("Me")
+	if m.MeFunc != nil {
+		return m.MeFunc(ctx)
+	}
+	return services.Profile{}, nil
+}
+
+func (m *Mock) DeleteMe(ctx context.Context, password string) error {
+	m.record("DeleteMe")
+	if m.DeleteMeFunc != nil {
+		return m.DeleteMeFunc(ctx, password)
+	}
+	return nil
+}
+
+func (m *Mock) PostAdd(ctx context.Context, adReq *services.CreateAdRequest) (db.Ad, error) {
+	m.record("PostAdd")
+	if m.PostAddFunc != nil {
+		return m.PostAddFunc(ctx, adReq)
+	}
+	return db.Ad{}, nil
+}
+
+func (m *Mock) GetAd(ctx context.Context, id int) (db.Ad, error) {
+	m.record("GetAd")
+	if m.GetAdFunc != nil {
+		return m.GetAdFunc(ctx, id)
+	}
+	return db.Ad{}, nil
+}
+
+func (m *Mock) UpdateAd(ctx context.Context, id int, req services.UpdateAdRequest) (db.Ad, error) {
+	m.record("UpdateAd")
+	if m.UpdateAdFunc != nil {
+		return m.UpdateAdFunc(ctx, id, req)
+	}
+	return db.Ad{}, nil
+}
+
+func (m *Mock) DeleteAd(ctx context.Context, id int) error {
+	m.record("DeleteAd")
+	if m.DeleteAdFunc != nil {
+		return m.DeleteAdFunc(ctx, id)
+	}
+	return nil
+}
+
+func (m *Mock) RenewAd(ctx context.Context, id int) (db.Ad, error) {
+	m.record("RenewAd")
+	if m.RenewAdFunc != nil {
+		return m.RenewAdFunc(ctx, id)
+	}
+	return db.Ad{}, nil
+}
+
+func (m *Mock) GetSimilarAds(ctx context.Context, id, limit int) ([]db.Ad, error) {
+	m.record("GetSimilarAds")
+	if m.GetSimilarAdsFunc != nil {
+		return m.GetSimilarAdsFunc(ctx, id, limit)
+	}
+	return nil, nil
+}
+
+func (m *Mock) GetAds(ctx context.Context, req services.GetAdsRequest) (services.PagedAds, error) {
+	m.record("GetAds")
+	if m.GetAdsFunc != nil {
+		return m.GetAdsFunc(ctx, req)
+	}
+	return services.PagedAds{}, nil
+}
+
+func (m *Mock) GetAdsAfterCursor(ctx context.Context, req services.GetAdsRequest) (services.AdsPage, error) {
+	m.record("GetAdsAfterCursor")
+	if m.GetAdsAfterCursorFunc != nil {
+		return m.GetAdsAfterCursorFunc(ctx, req)
+	}
+	return services.AdsPage{}, nil
+}
+
+func (m *Mock) GetMyAds(ctx context.Context, req services.GetAdsRequest) ([]db.Ad, error) {
+	m.record("GetMyAds")
+	if m.GetMyAdsFunc != nil {
+		return m.GetMyAdsFunc(ctx, req)
+	}
+	return nil, nil
+}
+
+func (m *Mock) FavoriteAd(ctx context.Context, id int) error {
+	m.record("FavoriteAd")
+	if m.FavoriteAdFunc != nil {
+		return m.FavoriteAdFunc(ctx, id)
+	}
+	return nil
+}
+
+func (m *Mock) UnfavoriteAd(ctx context.Context, id int) error {
+	m.record("UnfavoriteAd")
+	if m.UnfavoriteAdFunc != nil {
+		return m.UnfavoriteAdFunc(ctx, id)
+	}
+	return nil
+}
+
+func (m *Mock) GetFavorites(ctx context.Context, req services.GetAdsRequest) ([]db.Ad, error) {
+	m.record("GetFavorites")
+	if m.GetFavoritesFunc != nil {
+		return m.GetFavoritesFunc(ctx, req)
+	}
+	return nil, nil
+}
+
+func (m *Mock) UploadImage(ctx context.Context, path string) (services.UploadedImage, error) {
+	m.record("UploadImage")
+	if m.UploadImageFunc != nil {
+		return m.UploadImageFunc(ctx, path)
+	}
+	return services.UploadedImage{}, nil
+}
+
+func (m *Mock) GetAnnouncements(ctx context.Context) ([]db.Announcement, error) {
+	m.record("GetAnnouncements")
+	if m.GetAnnouncementsFunc != nil {
+		return m.GetAnnouncementsFunc(ctx)
+	}
+	return nil, nil
+}
+
+func (m *Mock) GetServerVersion(ctx context.Context) (buildinfo.Info, error) {
+	m.record("GetServerVersion")
+	if m.GetServerVersionFunc != nil {
+		return m.GetServerVersionFunc(ctx)
+	}
+	return buildinfo.Info{}, nil
+}
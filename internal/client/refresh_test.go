@@ -0,0 +1,108 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/YuarenArt/marketgo/pkg/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// authServer принимает только токен "fresh" и выдаёт его в обмен на refresh-токен "refresh-1"
+func authServer(t *testing.T) (*httptest.Server, *atomic.Int32, *atomic.Int32) {
+	var refreshes, unauthorized atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc(pathRefresh, func(w http.ResponseWriter, r *http.Request) {
+		refreshes.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		var body struct {
+			RefreshToken string `json:"refresh_token"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.RefreshToken != "refresh-1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"code": "unauthorized", "message": "invalid refresh token"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"token": "fresh", "refresh_token": "refresh-2", "expires_in": 3600}`))
+	})
+	mux.HandleFunc(pathMe, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Header.Get(authHeader) != "fresh" {
+			unauthorized.Add(1)
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"code": "unauthorized", "message": "invalid token"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"id": 1, "login": "seller", "ads_count": 2}`))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv, &refreshes, &unauthorized
+}
+
+func newAuthClient(url string) *Client {
+	c := NewClient(url, logging.NewLogger(nil))
+	c.SetToken("expired")
+	c.SetRefreshToken("refresh-1")
+	return c
+}
+
+func TestTokenRefresh(t *testing.T) {
+	t.Run("request is retried after refresh", func(t *testing.T) {
+		srv, refreshes, unauthorized := authServer(t)
+		c := newAuthClient(srv.URL)
+
+		profile, err := c.Me(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "seller", profile.Login)
+		assert.Equal(t, int32(1), refreshes.Load())
+		assert.Equal(t, int32(1), unauthorized.Load())
+
+		token, refreshToken := c.Tokens()
+		assert.Equal(t, "fresh", token)
+		assert.Equal(t, "refresh-2", refreshToken)
+	})
+
+	t.Run("concurrent requests refresh once", func(t *testing.T) {
+		srv, refreshes, _ := authServer(t)
+		c := newAuthClient(srv.URL)
+
+		var wg sync.WaitGroup
+		errs := make(chan error, 10)
+		for range 10 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := c.Me(context.Background())
+				errs <- err
+			}()
+		}
+		wg.Wait()
+		close(errs)
+
+		for err := range errs {
+			assert.NoError(t, err)
+		}
+		assert.Equal(t, int32(1), refreshes.Load())
+	})
+
+	t.Run("rejected refresh token clears the session", func(t *testing.T) {
+		srv, refreshes, _ := authServer(t)
+		c := newAuthClient(srv.URL)
+		c.SetRefreshToken("revoked")
+
+		_, err := c.Me(context.Background())
+		assert.ErrorIs(t, err, ErrUnauthorized)
+		assert.Equal(t, int32(1), refreshes.Load())
+
+		token, refreshToken := c.Tokens()
+		assert.Empty(t, token)
+		assert.Empty(t, refreshToken)
+	})
+}
@@ -0,0 +1,138 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math"
+	mathrand "math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy задаёт повторы запросов после временных ошибок
+type RetryPolicy struct {
+	// Attempts - общее число попыток, включая первую; 1 отключает повторы
+	Attempts int
+	// BaseDelay - задержка перед первым повтором; каждая следующая вдвое больше
+	BaseDelay time.Duration
+	// MaxDelay ограничивает задержку между попытками
+	MaxDelay time.Duration
+	// Jitter - доля задержки (от 0 до 1), на которую она случайно отклоняется
+	Jitter float64
+}
+
+// DefaultRetryPolicy - повторы по умолчанию: до 3 попыток с задержкой от 200 мс
+var DefaultRetryPolicy = RetryPolicy{
+	Attempts:  3,
+	BaseDelay: 200 * time.Millisecond,
+	MaxDelay:  5 * time.Second,
+	Jitter:    0.2,
+}
+
+// WithRetry задаёт политику повторов запросов
+func WithRetry(policy RetryPolicy) ClientOption {
+	return func(c *Client) {
+		c.retry = policy
+	}
+}
+
+type allowRetryKey struct{}
+
+// AllowRetry разрешает повторять изменяющие запросы, выполняемые с возвращённым контекстом.
+// Вызывающий код гарантирует, что повтор безопасен, например запрос несёт ключ идемпотентности.
+// GET-запросы повторяются всегда.
+func AllowRetry(ctx context.Context) context.Context {
+	return context.WithValue(ctx, allowRetryKey{}, true)
+}
+
+// retryAllowed сообщает, можно ли повторить запрос method
+func retryAllowed(ctx context.Context, method string) bool {
+	if method == http.MethodGet {
+		return true
+	}
+	allowed, _ := ctx.Value(allowRetryKey{}).(bool)
+	return allowed
+}
+
+// sendWithRetry выполняет send и повторяет его после ошибок соединения и ответов 429, 502, 503 и 504.
+// Задержка берётся из Retry-After, если сервер его прислал, иначе растёт экспоненциально.
+// Отмена ctx прерывает ожидание сразу.
+func (c *Client) sendWithRetry(ctx context.Context, method, path string, payload []byte, useAuth bool, result interface{}, logContext ...interface{}) error {
+	attempts := c.retry.Attempts
+	if attempts < 1 || !retryAllowed(ctx, method) {
+		attempts = 1
+	}
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = c.send(ctx, method, path, payload, useAuth, result, logContext...)
+		if err == nil || attempt >= attempts || ctx.Err() != nil {
+			return err
+		}
+		delay, ok := c.retryDelay(err, attempt)
+		if !ok {
+			return err
+		}
+
+		c.logger.Debug("Повтор запроса", append(logContext, "method", method, "path", path,
+			"attempt", attempt+1, "delay", delay, "error", err)...)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// retryDelay сообщает, стоит ли повторять запрос после err, и через какое время
+func (c *Client) retryDelay(err error, attempt int) (time.Duration, bool) {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+			if apiErr.RetryAfter > 0 {
+				return apiErr.RetryAfter, true
+			}
+		case http.StatusBadGateway, http.StatusGatewayTimeout:
+		default:
+			return 0, false
+		}
+		return c.backoff(attempt), true
+	}
+
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return c.backoff(attempt), true
+	}
+	return 0, false
+}
+
+// backoff возвращает задержку перед повтором после попытки attempt
+func (c *Client) backoff(attempt int) time.Duration {
+	delay := float64(c.retry.BaseDelay) * math.Pow(2, float64(attempt-1))
+	if c.retry.MaxDelay > 0 && delay > float64(c.retry.MaxDelay) {
+		delay = float64(c.retry.MaxDelay)
+	}
+	if c.retry.Jitter > 0 {
+		delay += delay * c.retry.Jitter * (2*mathrand.Float64() - 1)
+	}
+	return time.Duration(delay)
+}
+
+// parseRetryAfter разбирает заголовок Retry-After в секундах или в формате HTTP-даты
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return time.Until(at)
+	}
+	return 0
+}
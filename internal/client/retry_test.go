@@ -0,0 +1,129 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/YuarenArt/marketgo/internal/server/services"
+	"github.com/YuarenArt/marketgo/pkg/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyServer возвращает сервер, который первые failures запросов обрабатывает fail, а затем отвечает успешно
+func flakyServer(t *testing.T, failures int32, fail http.HandlerFunc) (*httptest.Server, *atomic.Int32) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= failures {
+			fail(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id": 1, "login": "seller", "version": "v1"}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+func unavailable(w http.ResponseWriter, _ *http.Request) {
+	w.WriteHeader(http.StatusServiceUnavailable)
+}
+
+func resetConnection(w http.ResponseWriter, _ *http.Request) {
+	conn, _, err := w.(http.Hijacker).Hijack()
+	if err == nil {
+		_ = conn.Close()
+	}
+}
+
+func TestRetry(t *testing.T) {
+	policy := RetryPolicy{Attempts: 3, BaseDelay: time.Millisecond, MaxDelay: 10 * time.Millisecond}
+	newClient := func(url string) *Client {
+		return NewClient(url, logging.NewLogger(nil), WithRetry(policy))
+	}
+	register := func(ctx context.Context, c *Client) error {
+		_, err := c.Register(ctx, &services.InputUserInfo{Login: "seller", Password: "s3cure-horse7"})
+		return err
+	}
+
+	t.Run("get succeeds after unavailable responses", func(t *testing.T) {
+		srv, calls := flakyServer(t, 2, unavailable)
+		info, err := newClient(srv.URL).GetServerVersion(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "v1", info.Version)
+		assert.Equal(t, int32(3), calls.Load())
+	})
+
+	t.Run("get succeeds after connection reset", func(t *testing.T) {
+		srv, calls := flakyServer(t, 1, resetConnection)
+		_, err := newClient(srv.URL).GetServerVersion(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("gives up after all attempts", func(t *testing.T) {
+		srv, calls := flakyServer(t, 5, unavailable)
+		_, err := newClient(srv.URL).GetServerVersion(context.Background())
+		var apiErr *APIError
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, http.StatusServiceUnavailable, apiErr.StatusCode)
+		assert.Equal(t, int32(3), calls.Load())
+	})
+
+	t.Run("client errors are not retried", func(t *testing.T) {
+		srv, calls := flakyServer(t, 1, func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+		})
+		_, err := newClient(srv.URL).GetServerVersion(context.Background())
+		require.Error(t, err)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("post is retried only when allowed", func(t *testing.T) {
+		srv, calls := flakyServer(t, 1, unavailable)
+		require.Error(t, register(context.Background(), newClient(srv.URL)))
+		assert.Equal(t, int32(1), calls.Load())
+
+		srv, calls = flakyServer(t, 1, unavailable)
+		require.NoError(t, register(AllowRetry(context.Background()), newClient(srv.URL)))
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("retry-after is respected", func(t *testing.T) {
+		srv, calls := flakyServer(t, 1, func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+		})
+		start := time.Now()
+		_, err := newClient(srv.URL).GetServerVersion(context.Background())
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, time.Since(start), time.Second)
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("cancellation aborts the wait", func(t *testing.T) {
+		srv, calls := flakyServer(t, 1, func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("Retry-After", "60")
+			w.WriteHeader(http.StatusServiceUnavailable)
+		})
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		_, err := newClient(srv.URL).GetServerVersion(ctx)
+		require.Error(t, err)
+		assert.Less(t, time.Since(start), 5*time.Second)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	assert.Equal(t, 3*time.Second, parseRetryAfter("3"))
+	assert.Zero(t, parseRetryAfter(""))
+	assert.Zero(t, parseRetryAfter("soon"))
+	at := time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)
+	assert.InDelta(t, time.Minute.Seconds(), parseRetryAfter(at).Seconds(), 2)
+}
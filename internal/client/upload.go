@@ -0,0 +1,76 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/YuarenArt/marketgo/internal/server/services"
+)
+
+const (
+	pathAdImages = apiPrefix + "/ads/images"
+	// imageFormField - поле формы, в котором сервер ожидает изображение
+	imageFormField = "image"
+)
+
+type contentTypeKey struct{}
+
+// withContentType задаёт Content-Type тела запроса, выполняемого с возвращённым контекстом,
+// вместо application/json. Такие тела не сжимаются.
+func withContentType(ctx context.Context, value string) context.Context {
+	return context.WithValue(ctx, contentTypeKey{}, value)
+}
+
+// requestContentType возвращает Content-Type тела запроса из контекста
+func requestContentType(ctx context.Context) string {
+	if value, ok := ctx.Value(contentTypeKey{}).(string); ok {
+		return value
+	}
+	return jsonContentType
+}
+
+// UploadImage загружает изображение из файла path и возвращает его адрес для image_url.
+// Сервер принимает JPEG, PNG и WebP до 5 МиБ и определяет тип по содержимому файла.
+func (c *Client) UploadImage(ctx context.Context, path string) (services.UploadedImage, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return services.UploadedImage{}, fmt.Errorf("открытие изображения: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return services.UploadedImage{}, fmt.Errorf("открытие изображения: %w", err)
+	}
+	if info.Size() > services.MaxImageSize {
+		return services.UploadedImage{}, fmt.Errorf("изображение %s больше 5 МиБ", path)
+	}
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, err := form.CreateFormFile(imageFormField, filepath.Base(path))
+	if err != nil {
+		return services.UploadedImage{}, fmt.Errorf("формирование запроса: %w", err)
+	}
+	if _, err := io.Copy(part, file); err != nil {
+		return services.UploadedImage{}, fmt.Errorf("чтение изображения: %w", err)
+	}
+	if err := form.Close(); err != nil {
+		return services.UploadedImage{}, fmt.Errorf("формирование запроса: %w", err)
+	}
+
+	var image services.UploadedImage
+	ctx = withContentType(ctx, form.FormDataContentType())
+	if err := c.doRequest(ctx, http.MethodPost, pathAdImages, &body, true, &image, "path", path); err != nil {
+		return services.UploadedImage{}, err
+	}
+
+	c.logger.Info("Изображение загружено", "path", path, "url", image.URL)
+	return image, nil
+}
@@ -0,0 +1,169 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	AnnouncementSeverityInfo     = "info"
+	AnnouncementSeverityWarning  = "warning"
+	AnnouncementSeverityCritical = "critical"
+
+	maxAnnouncementLength = 500
+
+	ErrMsgAnnouncementNotFound      = "объявление администрации не найдено"
+	ErrMsgInvalidAnnouncementText   = "текст объявления администрации должен содержать от 1 до 500 символов"
+	ErrMsgInvalidSeverity           = "важность должна быть info, warning или critical"
+	ErrMsgInvalidAnnouncementWindow = "время окончания должно быть позже времени начала"
+)
+
+var (
+	ErrAnnouncementNotFound      = newKindError(ErrNotFound, ErrMsgAnnouncementNotFound)
+	ErrInvalidAnnouncementText   = newKindError(ErrInvalid, ErrMsgInvalidAnnouncementText)
+	ErrInvalidSeverity           = newKindError(ErrInvalid, ErrMsgInvalidSeverity)
+	ErrInvalidAnnouncementWindow = newKindError(ErrInvalid, ErrMsgInvalidAnnouncementWindow)
+)
+
+// Announcement представляет объявление администрации (например, о техработах).
+// Объявление активно с StartsAt и до EndsAt; EndsAt == nil означает бессрочное объявление.
+type Announcement struct {
+	ID        int        `json:"id"`
+	Message   string     `json:"message"`
+	Severity  string     `json:"severity"`
+	StartsAt  time.Time  `json:"starts_at"`
+	EndsAt    *time.Time `json:"ends_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// ActiveAt сообщает, действует ли объявление в момент t.
+func (a Announcement) ActiveAt(t time.Time) bool {
+	if t.Before(a.StartsAt) {
+		return false
+	}
+	return a.EndsAt == nil || t.Before(*a.EndsAt)
+}
+
+// CreateAnnouncement создаёт объявление администрации.
+func (s *DBService) CreateAnnouncement(ctx context.Context, a Announcement) (Announcement, error) {
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+
+	if err := validateAnnouncement(a); err != nil {
+		return Announcement{}, err
+	}
+
+	created, err := scanAnnouncement(s.pool.QueryRow(ctx, QueryCreateAnnouncement,
+		a.Message, a.Severity, a.StartsAt.UTC(), utcOrNil(a.EndsAt),
+	))
+	if err != nil {
+		return Announcement{}, fmt.Errorf("failed to create announcement: %w", classifyError(err))
+	}
+	return created, nil
+}
+
+// UpdateAnnouncement полностью заменяет поля объявления администрации id.
+func (s *DBService) UpdateAnnouncement(ctx context.Context, id int, a Announcement) (Announcement, error) {
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+
+	if err := validateAnnouncement(a); err != nil {
+		return Announcement{}, err
+	}
+
+	updated, err := scanAnnouncement(s.pool.QueryRow(ctx, QueryUpdateAnnouncement,
+		a.Message, a.Severity, a.StartsAt.UTC(), utcOrNil(a.EndsAt), id,
+	))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Announcement{}, ErrAnnouncementNotFound
+		}
+		return Announcement{}, fmt.Errorf("failed to update announcement: %w", classifyError(err))
+	}
+	return updated, nil
+}
+
+// DeleteAnnouncement удаляет объявление администрации id.
+func (s *DBService) DeleteAnnouncement(ctx context.Context, id int) error {
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+
+	tag, err := s.pool.Exec(ctx, QueryDeleteAnnouncement, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete announcement: %w", classifyError(err))
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrAnnouncementNotFound
+	}
+	return nil
+}
+
+// Announcements возвращает все объявления администрации,
+// упорядоченные по убыванию важности и времени начала.
+func (s *DBService) Announcements(ctx context.Context) ([]Announcement, error) {
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := s.pool.Query(ctx, QueryGetAnnouncements)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query announcements: %w", classifyError(err))
+	}
+	defer rows.Close()
+
+	announcements := make([]Announcement, 0)
+	for rows.Next() {
+		a, err := scanAnnouncement(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query announcements: %w", classifyError(err))
+		}
+		announcements = append(announcements, a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during rows iteration: %w", classifyError(err))
+	}
+
+	return announcements, nil
+}
+
+// scanAnnouncement считывает объявление администрации из строки результата.
+func scanAnnouncement(row pgx.Row) (Announcement, error) {
+	var a Announcement
+	err := row.Scan(&a.ID, &a.Message, &a.Severity, &a.StartsAt, &a.EndsAt, &a.CreatedAt)
+	return a, err
+}
+
+// validateAnnouncement выполняет валидацию объявления администрации.
+func validateAnnouncement(a Announcement) error {
+	length := utf8.RuneCountInString(strings.TrimSpace(a.Message))
+	if length == 0 || length > maxAnnouncementLength {
+		return ErrInvalidAnnouncementText
+	}
+
+	switch a.Severity {
+	case AnnouncementSeverityInfo, AnnouncementSeverityWarning, AnnouncementSeverityCritical:
+	default:
+		return ErrInvalidSeverity
+	}
+
+	if a.EndsAt != nil && !a.EndsAt.After(a.StartsAt) {
+		return ErrInvalidAnnouncementWindow
+	}
+
+	return nil
+}
+
+// utcOrNil приводит необязательную временную метку к UTC.
+func utcOrNil(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
@@ -0,0 +1,97 @@
+package db
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func batchAd(userID, n int) Ad {
+	return Ad{
+		Title:    fmt.Sprintf("Batch ad %d", n),
+		Text:     "Ad inserted by batch",
+		ImageURL: "https://example.com/batch.png",
+		Price:    int64(100 + n),
+		UserID:   userID,
+	}
+}
+
+func TestCreateAdsBatch(t *testing.T) {
+	require.NoError(t, clearTables(testCtx, testDB))
+	user, err := testDB.CreateUser(testCtx, "batchuser", "pass")
+	require.NoError(t, err)
+
+	t.Run("valid ads are copied", func(t *testing.T) {
+		ads := []Ad{batchAd(user.ID, 1), batchAd(user.ID, 2), batchAd(user.ID, 3)}
+
+		inserted, err := testDB.CreateAdsBatch(testCtx, ads)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), inserted)
+
+		count, err := testDB.CountUserAds(testCtx, user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 3, count)
+	})
+
+	t.Run("invalid ads are reported and skipped", func(t *testing.T) {
+		badTitle := batchAd(user.ID, 5)
+		badTitle.Title = "A"
+		withTranslation := batchAd(user.ID, 6)
+		withTranslation.Translations = map[string]AdTranslation{"en": {Title: "Batch ad", Text: "Text"}}
+		ads := []Ad{batchAd(user.ID, 4), badTitle, batchAd(999999, 7), withTranslation}
+
+		inserted, err := testDB.CreateAdsBatch(testCtx, ads)
+		assert.Equal(t, int64(1), inserted)
+
+		var batchErr *AdsBatchError
+		require.ErrorAs(t, err, &batchErr)
+		require.Len(t, batchErr.Rows, 3)
+		assert.Equal(t, 1, batchErr.Rows[0].Index)
+		assert.ErrorIs(t, batchErr.Rows[0], ErrInvalidTitleLength)
+		assert.Equal(t, 2, batchErr.Rows[1].Index)
+		assert.ErrorIs(t, batchErr.Rows[1], ErrUserNotFound)
+		assert.Equal(t, 3, batchErr.Rows[2].Index)
+		assert.ErrorIs(t, batchErr.Rows[2], ErrBatchTranslations)
+	})
+
+	t.Run("empty batch inserts nothing", func(t *testing.T) {
+		inserted, err := testDB.CreateAdsBatch(testCtx, nil)
+		require.NoError(t, err)
+		assert.Zero(t, inserted)
+	})
+}
+
+// BenchmarkCreateAds сравнивает вставку пакета через COPY с построчными CreateAd:
+// go test -run '^$' -bench CreateAds ./internal/db/
+func BenchmarkCreateAds(b *testing.B) {
+	const batchSize = 500
+
+	require.NoError(b, clearTables(testCtx, testDB))
+	user, err := testDB.CreateUser(testCtx, "benchuser", "pass")
+	require.NoError(b, err)
+
+	ads := make([]Ad, batchSize)
+	for i := range ads {
+		ads[i] = batchAd(user.ID, i)
+	}
+
+	b.Run("CopyFrom", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := testDB.CreateAdsBatch(testCtx, ads); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("PerRow", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			for _, ad := range ads {
+				if _, err := testDB.CreateAd(testCtx, ad); err != nil {
+					b.Fatal(err)
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,50 @@
+package db
+
+import (
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// MaxCityLength - максимальная длина города в символах
+	MaxCityLength = 100
+
+	ErrMsgInvalidCity = "город должен содержать не более 100 символов"
+)
+
+var ErrInvalidCity = newKindError(ErrInvalid, ErrMsgInvalidCity)
+
+// NormalizeCity убирает крайние пробелы и проверяет длину города в символах.
+// Пустая строка допустима и означает, что город не указан.
+func NormalizeCity(city string) (string, error) {
+	city = strings.TrimSpace(city)
+	if utf8.RuneCountInString(city) > MaxCityLength {
+		return "", ErrInvalidCity
+	}
+	return city, nil
+}
+
+// NormalizeCityFilter приводит города фильтра к нижнему регистру, убирает пустые значения и повторы.
+func NormalizeCityFilter(cities []string) ([]string, error) {
+	var normalized []string
+	for _, raw := range cities {
+		city, err := NormalizeCity(raw)
+		if err != nil {
+			return nil, err
+		}
+		city = strings.ToLower(city)
+		if city == "" || slices.Contains(normalized, city) {
+			continue
+		}
+		normalized = append(normalized, city)
+	}
+	return normalized, nil
+}
+
+// ValidateCity проверяет длину города без крайних пробелов в символах.
+// Те же правила применяет валидатор ad_city при разборе запросов API.
+func ValidateCity(city string) error {
+	_, err := NormalizeCity(city)
+	return err
+}
@@ -0,0 +1,105 @@
+package db
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeCity(t *testing.T) {
+	t.Run("trimmed, case kept", func(t *testing.T) {
+		city, err := NormalizeCity("  Нижний Новгород ")
+		require.NoError(t, err)
+		assert.Equal(t, "Нижний Новгород", city)
+	})
+
+	t.Run("length is counted in runes", func(t *testing.T) {
+		_, err := NormalizeCity(strings.Repeat("я", MaxCityLength))
+		assert.NoError(t, err)
+
+		_, err = NormalizeCity(strings.Repeat("я", MaxCityLength+1))
+		assert.ErrorIs(t, err, ErrInvalidCity)
+		assert.ErrorIs(t, err, ErrInvalid)
+	})
+
+	t.Run("filter is lowercased, deduplicated and skips blanks", func(t *testing.T) {
+		cities, err := NormalizeCityFilter([]string{" Москва", "", "МОСКВА", "Казань", "  "})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"москва", "казань"}, cities)
+
+		cities, err = NormalizeCityFilter([]string{" "})
+		require.NoError(t, err)
+		assert.Empty(t, cities)
+	})
+}
+
+func TestAdCity(t *testing.T) {
+	require.NoError(t, clearTables(testCtx, testDB))
+	user, err := testDB.CreateUser(testCtx, "cityuser", "pass")
+	require.NoError(t, err)
+
+	create := func(title string, price int64, city string) Ad {
+		ad, err := testDB.CreateAd(testCtx, Ad{Title: title, Text: "Text", Price: price, UserID: user.ID, City: city})
+		require.NoError(t, err)
+		return ad
+	}
+	bike := create("Bike", 3000, " Москва ")
+	create("Sofa", 2000, "москва")
+	scooter := create("Scooter", 1000, "Казань")
+	create("Lamp", 500, "")
+
+	titles := func(ads []Ad) []string {
+		result := make([]string, 0, len(ads))
+		for _, ad := range ads {
+			result = append(result, ad.Title)
+		}
+		return result
+	}
+
+	t.Run("city is trimmed on create and returned", func(t *testing.T) {
+		assert.Equal(t, "Москва", bike.City)
+
+		ad, err := testDB.Ad(testCtx, bike.ID, user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Москва", ad.City)
+	})
+
+	t.Run("too long city is rejected", func(t *testing.T) {
+		_, err := testDB.CreateAd(testCtx, Ad{Title: "Bad", Text: "Text", Price: 100, UserID: user.ID, City: strings.Repeat("x", MaxCityLength+1)})
+		assert.ErrorIs(t, err, ErrInvalidCity)
+	})
+
+	t.Run("filter ignores case and accepts several cities", func(t *testing.T) {
+		ads, err := testDB.Ads(testCtx, user.ID, 1, 10, "price", "ASC", AdsFilter{MaxPrice: maxPrice, Cities: []string{"МОСКВА"}})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Sofa", "Bike"}, titles(ads))
+
+		filter := AdsFilter{MaxPrice: maxPrice, Cities: []string{"казань", " москва "}}
+		ads, err = testDB.Ads(testCtx, user.ID, 1, 10, "price", "ASC", filter)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Scooter", "Sofa", "Bike"}, titles(ads))
+
+		count, err := testDB.CountAds(testCtx, filter)
+		require.NoError(t, err)
+		assert.Equal(t, 3, count)
+	})
+
+	t.Run("empty filter keeps all ads", func(t *testing.T) {
+		ads, err := testDB.Ads(testCtx, user.ID, 1, 10, "price", "ASC", AdsFilter{MaxPrice: maxPrice, Cities: []string{""}})
+		require.NoError(t, err)
+		assert.Len(t, ads, 4)
+	})
+
+	t.Run("update replaces city", func(t *testing.T) {
+		city := " Самара"
+		ad, err := testDB.UpdateAd(testCtx, scooter.ID, user.ID, AdUpdate{City: &city})
+		require.NoError(t, err)
+		assert.Equal(t, "Самара", ad.City)
+
+		ads, err := testDB.Ads(testCtx, user.ID, 1, 10, "price", "ASC", AdsFilter{MaxPrice: maxPrice, Cities: []string{"казань"}})
+		require.NoError(t, err)
+		assert.Empty(t, ads)
+	})
+}
@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"net/url"
 	"strings"
+	"sync/atomic"
 	"time"
+	"unicode/utf8"
 
 	"github.com/jackc/pgx/v5/pgconn"
 
@@ -16,22 +18,42 @@ import (
 
 const (
 	defaultPingTimeoutSecond = 5 * time.Second
+	suggestQueryTimeout      = 50 * time.Millisecond
+	// DefaultQueryTimeout - ограничение времени запроса к базе данных по умолчанию
+	DefaultQueryTimeout = 3 * time.Second
 
 	minTitleLength = 2
 	maxTitleLength = 100
 	maxTextLength  = 2000
 	minPrice       = 1
 	maxPrice       = 100_000_000
+	// maxImageURLLength - размер столбца ads.image_url
+	maxImageURLLength = 200
+
+	AdStatusActive   = "active"
+	AdStatusSold     = "sold"
+	AdStatusArchived = "archived"
+
+	RoleUser  = "user"
+	RoleAdmin = "admin"
 
 	ErrMsgUserNotFound       = "пользователь с указанным ID не существует"
-	ErrMsgInvalidSortBy      = "допустима сортировка только по полям created_at или price"
+	ErrMsgLoginNotFound      = "пользователь с таким логином не найден"
+	ErrMsgInvalidSortBy      = "допустима сортировка только по полям created_at, price или title"
 	ErrMsgInvalidSortOrder   = "сортировка должна быть ASC или DESC"
 	ErrMsgInvalidTitleLength = "заголовок должен содержать от 2 до 100 символов"
 	ErrMsgInvalidTextLength  = "текст должен содержать от 1 до 2000 символов"
-	ErrMsgInvalidImageURL    = "некорректный формат URL изображения"
+	ErrMsgInvalidImageURL    = "URL изображения должен быть http- или https-адресом длиной до 200 символов"
 	ErrMsgInvalidPrice       = "цена должна быть в диапазоне от 1 до 100 000 000 (копеек)"
+	ErrMsgInvalidPriceRange  = "минимальная цена не может превышать максимальную"
 	ErrMsgInvalidUserID      = "некорректный идентификатор пользователя"
-	ErrMsgUserAlreadyExists  = "пользователь с таким логином уже существует"
+	ErrMsgLoginTaken         = "пользователь с таким логином уже существует"
+	ErrMsgAdNotFound         = "объявление не найдено"
+	ErrMsgNotAdOwner         = "объявление принадлежит другому пользователю"
+	ErrMsgEmptyUpdate        = "не указано ни одного поля для обновления"
+	ErrMsgInvalidStatus      = "статус должен быть active, sold или archived"
+	ErrMsgCursorMismatch     = "курсор получен для другой сортировки"
+	ErrMsgInvalidRole        = "роль должна быть user или admin"
 )
 
 func newError(msg string) error {
@@ -39,20 +61,45 @@ func newError(msg string) error {
 }
 
 var (
-	ErrUserNotFound       = newError(ErrMsgUserNotFound)
-	ErrInvalidSortBy      = newError(ErrMsgInvalidSortBy)
-	ErrInvalidSortOrder   = newError(ErrMsgInvalidSortOrder)
-	ErrInvalidTitleLength = newError(ErrMsgInvalidTitleLength)
-	ErrInvalidTextLength  = newError(ErrMsgInvalidTextLength)
-	ErrInvalidImageURL    = newError(ErrMsgInvalidImageURL)
-	ErrInvalidPrice       = newError(ErrMsgInvalidPrice)
-	ErrInvalidUserID      = newError(ErrMsgInvalidUserID)
-	ErrUserAlreadyExists  = newError(ErrMsgUserAlreadyExists)
+	ErrUserNotFound       = newKindError(ErrNotFound, ErrMsgUserNotFound)
+	ErrLoginNotFound      = newKindError(ErrNotFound, ErrMsgLoginNotFound)
+	ErrInvalidSortBy      = newKindError(ErrInvalid, ErrMsgInvalidSortBy)
+	ErrInvalidSortOrder   = newKindError(ErrInvalid, ErrMsgInvalidSortOrder)
+	ErrInvalidTitleLength = newKindError(ErrInvalid, ErrMsgInvalidTitleLength)
+	ErrInvalidTextLength  = newKindError(ErrInvalid, ErrMsgInvalidTextLength)
+	ErrInvalidImageURL    = newKindError(ErrInvalid, ErrMsgInvalidImageURL)
+	ErrInvalidPrice       = newKindError(ErrInvalid, ErrMsgInvalidPrice)
+	ErrInvalidPriceRange  = newKindError(ErrInvalid, ErrMsgInvalidPriceRange)
+	ErrInvalidUserID      = newKindError(ErrInvalid, ErrMsgInvalidUserID)
+	ErrLoginTaken         = newKindError(ErrConflict, ErrMsgLoginTaken)
+	ErrAdNotFound         = newKindError(ErrNotFound, ErrMsgAdNotFound)
+	ErrNotAdOwner         = newKindError(ErrForbidden, ErrMsgNotAdOwner)
+	ErrEmptyUpdate        = newKindError(ErrInvalid, ErrMsgEmptyUpdate)
+	ErrInvalidStatus      = newKindError(ErrInvalid, ErrMsgInvalidStatus)
+	ErrCursorMismatch     = newKindError(ErrInvalid, ErrMsgCursorMismatch)
+	ErrInvalidRole        = newKindError(ErrInvalid, ErrMsgInvalidRole)
 )
 
+// sortColumns сопоставляет допустимые значения sort_by выражениям ORDER BY.
+// В запрос подставляются только выражения из этого списка.
+var sortColumns = map[string]string{
+	"created_at": "a.created_at",
+	"price":      "a.price",
+	"title":      "lower(a.title)",
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
 // DBService предоставляет методы для взаимодействия с базой данных PostgreSQL.
 type DBService struct {
-	pool *pgxpool.Pool
+	pool          *pgxpool.Pool
+	queryTimeout  time.Duration
+	retryAttempts int
+	retryBase     time.Duration
+	onRetry       func()
+	// replicas - пулы реплик для читающих запросов, выбираемые по кругу через nextReplica
+	replicas    []*replica
+	nextReplica atomic.Uint64
 }
 
 // User представляет пользователя системы.
@@ -61,64 +108,153 @@ type User struct {
 	Login     string    `json:"login"`
 	Password  string    `json:"-"`
 	CreatedAt time.Time `json:"created_at"`
+	Role      string    `json:"role"`
 }
 
 // Ad представляет объявление. Цена указана в копейках.
 type Ad struct {
-	ID        int       `json:"id"`
-	Title     string    `json:"title"`
-	Text      string    `json:"text"`
-	ImageURL  string    `json:"image_url"`
-	Price     int64     `json:"price"`
-	UserID    int       `json:"user_id"`
-	Author    string    `json:"author"`
-	IsMine    bool      `json:"is_mine,omitempty"`
-	CreatedAt time.Time `json:"created_at"`
+	ID         int       `json:"id"`
+	Title      string    `json:"title"`
+	Text       string    `json:"text"`
+	ImageURL   string    `json:"image_url"`
+	Price      int64     `json:"price"`
+	UserID     int       `json:"user_id"`
+	Status     string    `json:"status"`
+	Author     string    `json:"author"`
+	IsMine     bool      `json:"is_mine,omitempty"`
+	Reserved   bool      `json:"reserved,omitempty"`
+	IsFavorite bool      `json:"is_favorite,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	// Lang - код языка отданного перевода; пустой, если отдан оригинал
+	Lang         string                   `json:"lang,omitempty"`
+	Translations map[string]AdTranslation `json:"translations,omitempty"`
+	// Tags - теги в нижнем регистре без повторов, см. NormalizeTags
+	Tags []string `json:"tags,omitempty"`
+	// City - город без крайних пробелов, см. NormalizeCity
+	City string `json:"city,omitempty"`
+	// ExpiresAt - срок публикации; после него активное объявление скрывается из списков и архивируется
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// AdUpdate описывает частичное обновление объявления.
+// Поля со значением nil не изменяются.
+type AdUpdate struct {
+	Title    *string
+	Text     *string
+	ImageURL *string
+	Price    *int64
+	// Tags заменяет все теги объявления; пустой срез удаляет их
+	Tags *[]string
+	// City заменяет город; пустая строка убирает его
+	City *string
+}
+
+// IsEmpty сообщает, что обновление не содержит ни одного поля.
+func (u AdUpdate) IsEmpty() bool {
+	return u.Title == nil && u.Text == nil && u.ImageURL == nil && u.Price == nil && u.Tags == nil && u.City == nil
+}
+
+// dbConfig - параметры создания DBService, изменяемые опциями
+type dbConfig struct {
+	pool           *pgxpool.Config
+	skipMigrations bool
+	queryTimeout   time.Duration
+	retryAttempts  int
+	retryBase      time.Duration
+	onRetry        func()
+	replicaDSNs    []string
 }
 
 // DBOption определяет функцию, изменяющую конфигурацию подключения.
-type DBOption func(*pgxpool.Config)
+type DBOption func(*dbConfig)
 
 // WithMaxConns задаёт максимальное количество соединений в пуле.
 func WithMaxConns(n int32) DBOption {
-	return func(cfg *pgxpool.Config) {
-		cfg.MaxConns = n
+	return func(cfg *dbConfig) {
+		cfg.pool.MaxConns = n
 	}
 }
 
 // WithMinConns задаёт минимальное количество соединений в пуле.
 func WithMinConns(n int32) DBOption {
-	return func(cfg *pgxpool.Config) {
-		cfg.MinConns = n
+	return func(cfg *dbConfig) {
+		cfg.pool.MinConns = n
 	}
 }
 
 // WithConnMaxLifetime задаёт максимальное время жизни соединения.
 func WithConnMaxLifetime(d time.Duration) DBOption {
-	return func(cfg *pgxpool.Config) {
-		cfg.MaxConnLifetime = d
+	return func(cfg *dbConfig) {
+		cfg.pool.MaxConnLifetime = d
 	}
 }
 
 // WithConnIdleLifetime задаёт время жизни неактивного соединения.
 func WithConnIdleLifetime(d time.Duration) DBOption {
-	return func(cfg *pgxpool.Config) {
-		cfg.MaxConnIdleTime = d
+	return func(cfg *dbConfig) {
+		cfg.pool.MaxConnIdleTime = d
+	}
+}
+
+// WithSkipMigrations отключает применение миграций схемы при создании DBService.
+// Схемой тогда управляют отдельно, например вызовом Migrate.
+func WithSkipMigrations() DBOption {
+	return func(cfg *dbConfig) {
+		cfg.skipMigrations = true
+	}
+}
+
+// WithQueryTimeout ограничивает время выполнения каждого метода DBService.
+// По истечении d метод возвращает ошибку, распознаваемую IsTimeout; неположительное d снимает ограничение.
+// По умолчанию используется DefaultQueryTimeout.
+func WithQueryTimeout(d time.Duration) DBOption {
+	return func(cfg *dbConfig) {
+		cfg.queryTimeout = d
+	}
+}
+
+// validatePool проверяет параметры пула до подключения, чтобы ошибка настройки
+// была понятна и не маскировалась ошибкой соединения
+func (cfg *dbConfig) validatePool() error {
+	pool := cfg.pool
+	switch {
+	case pool.MaxConns < 1:
+		return fmt.Errorf("invalid pool config: MaxConns must be positive, got %d", pool.MaxConns)
+	case pool.MinConns < 0 || pool.MinConns > pool.MaxConns:
+		return fmt.Errorf("invalid pool config: MinConns must be between 0 and MaxConns (%d), got %d", pool.MaxConns, pool.MinConns)
+	case pool.MaxConnLifetime < 0:
+		return fmt.Errorf("invalid pool config: ConnMaxLifetime must not be negative, got %s", pool.MaxConnLifetime)
+	case pool.MaxConnIdleTime < 0:
+		return fmt.Errorf("invalid pool config: ConnIdleLifetime must not be negative, got %s", pool.MaxConnIdleTime)
 	}
+	return nil
 }
 
-// NewDBService создаёт сервис базы данных с заданными параметрами.
+// NewDBService создаёт сервис базы данных с заданными параметрами
+// и применяет миграции схемы, если не передана WithSkipMigrations.
 func NewDBService(ctx context.Context, dsn string, opts ...DBOption) (*DBService, error) {
-	cfg, err := pgxpool.ParseConfig(dsn)
+	poolCfg, err := pgxpool.ParseConfig(dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse DSN: %w", err)
 	}
 
+	// Все временные метки хранятся и агрегируются в UTC
+	poolCfg.ConnConfig.RuntimeParams["timezone"] = "UTC"
+
+	cfg := &dbConfig{
+		pool:          poolCfg,
+		queryTimeout:  DefaultQueryTimeout,
+		retryAttempts: DefaultRetryAttempts,
+		retryBase:     DefaultRetryBase,
+	}
 	for _, opt := range opts {
 		opt(cfg)
 	}
+	if err := cfg.validatePool(); err != nil {
+		return nil, err
+	}
 
-	pool, err := pgxpool.NewWithConfig(ctx, cfg)
+	pool, err := pgxpool.NewWithConfig(ctx, cfg.pool)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create connection pool: %w", err)
 	}
@@ -131,161 +267,898 @@ func NewDBService(ctx context.Context, dsn string, opts ...DBOption) (*DBService
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	if _, err := pool.Exec(ctx, CreateDb); err != nil {
-		pool.Close()
-		return nil, fmt.Errorf("failed to initialize schema: %w", err)
+	s := &DBService{
+		pool:          pool,
+		queryTimeout:  cfg.queryTimeout,
+		retryAttempts: cfg.retryAttempts,
+		retryBase:     cfg.retryBase,
+		onRetry:       cfg.onRetry,
+	}
+	if !cfg.skipMigrations {
+		if err := s.Migrate(ctx); err != nil {
+			pool.Close()
+			return nil, fmt.Errorf("failed to initialize schema: %w", err)
+		}
+	}
+
+	for _, dsn := range cfg.replicaDSNs {
+		r, err := newReplica(ctx, dsn, cfg.pool)
+		if err != nil {
+			s.Close()
+			return nil, err
+		}
+		s.replicas = append(s.replicas, r)
 	}
+	// Недоступные при запуске реплики не мешают старту и подключатся после успешной проверки
+	s.CheckReplicas(ctx)
+	return s, nil
+}
 
-	return &DBService{pool: pool}, nil
+// withQueryTimeout ограничивает ctx временем queryTimeout
+func (s *DBService) withQueryTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.queryTimeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, s.queryTimeout)
 }
 
 // Close закрывает соединение с базой данных.
 func (s *DBService) Close() error {
+	for _, r := range s.replicas {
+		r.pool.Close()
+	}
 	s.pool.Close()
 	return nil
 }
 
 // CreateUser создаёт нового пользователя в базе данных с переданным логином и хешированным паролем.
+// Если логин занят, возвращает ErrLoginTaken.
 func (s *DBService) CreateUser(ctx context.Context, login, hashedPassword string) (User, error) {
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+
 	var user User
 	err := s.pool.QueryRow(ctx, QueryCreateUser, login, hashedPassword).Scan(
-		&user.ID, &user.Login, &user.CreatedAt,
+		&user.ID, &user.Login, &user.CreatedAt, &user.Role,
 	)
 	if err != nil {
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) {
-			if pgErr.Code == "23505" && strings.Contains(pgErr.ConstraintName, "users_login_key") {
-				return User{}, ErrUserAlreadyExists
+			if pgErr.Code == pgUniqueViolation && strings.Contains(pgErr.ConstraintName, "users_login_key") {
+				return User{}, wrapError(ErrLoginTaken, err)
 			}
 		}
-		return User{}, fmt.Errorf("failed to create user: %w", err)
+		return User{}, fmt.Errorf("failed to create user: %w", classifyError(err))
 	}
 	return user, nil
 }
 
 // UserByLogin возвращает пользователя по логину.
 func (s *DBService) UserByLogin(ctx context.Context, login string) (User, error) {
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+
 	var user User
-	err := s.pool.QueryRow(ctx, QueryGetUserByLogin, login).Scan(
-		&user.ID, &user.Login, &user.Password, &user.CreatedAt,
+	err := s.retryRead(ctx, func() error {
+		return s.reader().QueryRow(ctx, QueryGetUserByLogin, login).Scan(
+			&user.ID, &user.Login, &user.Password, &user.CreatedAt, &user.Role,
+		)
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return User{}, wrapError(ErrLoginNotFound, err)
+		}
+		return User{}, fmt.Errorf("failed to get user: %w", classifyError(err))
+	}
+	return user, nil
+}
+
+// UserByID возвращает пользователя по id без пароля.
+func (s *DBService) UserByID(ctx context.Context, id int) (User, error) {
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+
+	var user User
+	err := s.retryRead(ctx, func() error {
+		return s.reader().QueryRow(ctx, QueryGetUserById, id).Scan(
+			&user.ID, &user.Login, &user.CreatedAt, &user.Role,
+		)
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return User{}, wrapError(ErrUserNotFound, err)
+		}
+		return User{}, fmt.Errorf("failed to get user: %w", classifyError(err))
+	}
+	return user, nil
+}
+
+// SetUserRole назначает пользователю с логином login роль role.
+func (s *DBService) SetUserRole(ctx context.Context, login, role string) (User, error) {
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+
+	if role != RoleUser && role != RoleAdmin {
+		return User{}, ErrInvalidRole
+	}
+
+	var user User
+	err := s.pool.QueryRow(ctx, QuerySetUserRole, login, role).Scan(
+		&user.ID, &user.Login, &user.CreatedAt, &user.Role,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return User{}, fmt.Errorf("user not found: %w", err)
+			return User{}, ErrLoginNotFound
 		}
-		return User{}, fmt.Errorf("failed to get user: %w", err)
+		return User{}, fmt.Errorf("failed to set user role: %w", classifyError(err))
 	}
 	return user, nil
 }
 
+// DeleteUser удаляет пользователя userID вместе с его объявлениями, уведомлениями и бронированиями.
+func (s *DBService) DeleteUser(ctx context.Context, userID int) error {
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+
+	tag, err := s.pool.Exec(ctx, QueryDeleteUser, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete user: %w", classifyError(err))
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// CountUserAds возвращает количество неудалённых объявлений пользователя во всех статусах.
+func (s *DBService) CountUserAds(ctx context.Context, userID int) (int, error) {
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+
+	var count int
+	err := s.retryRead(ctx, func() error {
+		return s.reader().QueryRow(ctx, QueryCountUserAds, userID).Scan(&count)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count user ads: %w", classifyError(err))
+	}
+	return count, nil
+}
+
 // CreateAd создаёт новое объявление.
 func (s *DBService) CreateAd(ctx context.Context, ad Ad) (Ad, error) {
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+
 	if err := validateAd(ad); err != nil {
 		return Ad{}, err
 	}
+	// Теги и город уже проверены validateAd
+	tags, _ := NormalizeTags(ad.Tags)
+	city, _ := NormalizeCity(ad.City)
+	expiresAt := adExpiresAt(ad)
 
 	var user User
 	err := s.pool.QueryRow(ctx, QueryGetUserById, ad.UserID).Scan(
-		&user.ID, &user.Login, &user.CreatedAt,
+		&user.ID, &user.Login, &user.CreatedAt, &user.Role,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return Ad{}, ErrUserNotFound
+			return Ad{}, wrapError(ErrUserNotFound, err)
 		}
-		return Ad{}, fmt.Errorf("failed to verify user: %w", err)
+		return Ad{}, fmt.Errorf("failed to verify user: %w", classifyError(err))
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return Ad{}, fmt.Errorf("failed to begin transaction: %w", classifyError(err))
 	}
+	defer tx.Rollback(ctx)
 
 	var createdAd Ad
-	err = s.pool.QueryRow(ctx, QueryCreateAd, ad.Title, ad.Text, ad.ImageURL, ad.Price, ad.UserID).Scan(
+	err = tx.QueryRow(ctx, QueryCreateAd, ad.Title, ad.Text, ad.ImageURL, ad.Price, ad.UserID, nonNilTags(tags), city, expiresAt).Scan(
 		&createdAd.ID, &createdAd.Title, &createdAd.Text, &createdAd.ImageURL,
-		&createdAd.Price, &createdAd.UserID, &createdAd.CreatedAt, &createdAd.Author, &createdAd.IsMine,
+		&createdAd.Price, &createdAd.UserID, &createdAd.Status, &createdAd.CreatedAt, &createdAd.Tags,
+		&createdAd.City, &createdAd.ExpiresAt, &createdAd.Author, &createdAd.IsMine,
 	)
 	if err != nil {
-		return Ad{}, fmt.Errorf("failed to create ad: %w", err)
+		return Ad{}, fmt.Errorf("failed to create ad: %w", classifyError(err))
 	}
 
+	for lang, tr := range ad.Translations {
+		if _, err := tx.Exec(ctx, QueryCreateAdTranslation, createdAd.ID, lang, tr.Title, tr.Text); err != nil {
+			return Ad{}, fmt.Errorf("failed to create ad translation: %w", classifyError(err))
+		}
+	}
+	if uploadedImageURL.MatchString(ad.ImageURL) {
+		if err := linkAdImage(ctx, tx, createdAd.ID); err != nil {
+			return Ad{}, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return Ad{}, fmt.Errorf("failed to commit transaction: %w", classifyError(err))
+	}
+
+	if len(ad.Translations) > 0 {
+		createdAd.Translations = ad.Translations
+	}
 	return createdAd, nil
 }
 
+// AdsFilter описывает фильтры списка объявлений.
+// Нулевые CreatedFrom и CreatedTo не ограничивают дату создания.
+// Если Statuses не заданы, возвращаются только активные объявления.
+// Ненулевой SellerID оставляет только объявления этого продавца.
+// Tags оставляет объявления, у которых есть все перечисленные теги; теги нормализуются как в NormalizeTags.
+// Cities оставляет объявления из любого перечисленного города без учёта регистра.
+// Активные объявления с истёкшим сроком публикации возвращаются только при IncludeExpired.
+type AdsFilter struct {
+	MinPrice    int64
+	MaxPrice    int64
+	CreatedFrom time.Time
+	CreatedTo   time.Time
+	Statuses    []string
+	SellerID    int
+	Tags        []string
+	Cities      []string
+	// IncludeExpired нужен владельцу, чтобы видеть и продлевать истёкшие объявления
+	IncludeExpired bool
+}
+
 // Ads возвращает список объявлений по фильтрам и сортировке.
+// Границы диапазонов цены и даты создания включаются; MinPrice больше MaxPrice - ошибка ErrInvalidPriceRange.
+// При равных значениях поля сортировки порядок определяется по id в том же направлении,
+// поэтому страницы не пересекаются и не пропускают объявления.
 func (s *DBService) Ads(
 	ctx context.Context,
 	userID int,
 	page, size int,
 	sortBy, sortOrder string,
-	minPrice, maxPrice int64,
+	filter AdsFilter,
 ) ([]Ad, error) {
-	if sortBy != "created_at" && sortBy != "price" {
-		return nil, ErrInvalidSortBy
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+
+	return s.queryAds(ctx, userID, size, (page-1)*size, sortBy, sortOrder, filter, nil)
+}
+
+// AdsCursor - позиция последнего отданного объявления для keyset-пагинации.
+// Из значений сортировки используется только поле, соответствующее SortBy.
+type AdsCursor struct {
+	SortBy    string
+	SortOrder string
+	ID        int
+	CreatedAt time.Time
+	Price     int64
+	Title     string
+}
+
+// AdsAfterCursor возвращает до size объявлений, следующих за after, с теми же фильтрами
+// и сортировкой, что и Ads, но без OFFSET. При after == nil возвращается первая страница.
+// Второе значение - курсор следующей страницы или nil, если объявлений больше нет.
+func (s *DBService) AdsAfterCursor(
+	ctx context.Context,
+	userID int,
+	size int,
+	sortBy, sortOrder string,
+	filter AdsFilter,
+	after *AdsCursor,
+) ([]Ad, *AdsCursor, error) {
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+
+	if after != nil && (after.SortBy != sortBy || after.SortOrder != sortOrder) {
+		return nil, nil, ErrCursorMismatch
 	}
-	if sortOrder != "ASC" && sortOrder != "DESC" {
-		return nil, ErrInvalidSortOrder
+	if size < 1 {
+		return []Ad{}, nil, nil
 	}
 
-	offset := (page - 1) * size
-	query := fmt.Sprintf(QueryGetAds, sortBy, sortOrder)
+	ads, err := s.queryAds(ctx, userID, size+1, 0, sortBy, sortOrder, filter, after)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(ads) <= size {
+		return ads, nil, nil
+	}
 
-	rows, err := s.pool.Query(ctx, query, userID, minPrice, maxPrice, size, offset)
+	ads = ads[:size]
+	last := ads[size-1]
+	next := &AdsCursor{
+		SortBy:    sortBy,
+		SortOrder: sortOrder,
+		ID:        last.ID,
+		CreatedAt: last.CreatedAt,
+		Price:     last.Price,
+		Title:     last.Title,
+	}
+	return ads, next, nil
+}
+
+// queryAds выполняет QueryGetAds; при заданном after вместо смещения добавляется условие keyset-пагинации.
+func (s *DBService) queryAds(
+	ctx context.Context,
+	userID int,
+	limit, offset int,
+	sortBy, sortOrder string,
+	filter AdsFilter,
+	after *AdsCursor,
+) ([]Ad, error) {
+	if err := validateSort(sortBy, sortOrder); err != nil {
+		return nil, err
+	}
+
+	where, args, err := adsConditions(filter, []any{userID, limit, offset})
 	if err != nil {
-		return nil, fmt.Errorf("failed to query ads: %w", err)
+		return nil, err
 	}
-	defer rows.Close()
 
+	if after != nil {
+		var key any
+		switch sortBy {
+		case "price":
+			key = after.Price
+		case "title":
+			key = after.Title
+		default:
+			key = after.CreatedAt.UTC()
+		}
+		args = append(args, key, after.ID)
+		keyExpr := fmt.Sprintf("$%d", len(args)-1)
+		if sortBy == "title" {
+			keyExpr = fmt.Sprintf("lower($%d)", len(args)-1)
+		}
+		op := ">"
+		if sortOrder == "DESC" {
+			op = "<"
+		}
+		where += fmt.Sprintf(" AND (%s, a.id) %s (%s, $%d)", sortColumns[sortBy], op, keyExpr, len(args))
+	}
+
+	query := fmt.Sprintf(QueryGetAds, where, sortColumns[sortBy], sortOrder, sortOrder)
+	return s.readAds(ctx, query, args)
+}
+
+// readAds выполняет запрос списка объявлений, повторяя его при временных ошибках
+func (s *DBService) readAds(ctx context.Context, query string, args []any) ([]Ad, error) {
 	var ads []Ad
+	err := s.retryRead(ctx, func() error {
+		rows, err := s.reader().Query(ctx, query, args...)
+		if err != nil {
+			return fmt.Errorf("failed to query ads: %w", classifyError(err))
+		}
+		defer rows.Close()
+
+		ads, err = scanAds(rows)
+		return err
+	})
+	return ads, err
+}
+
+// CountAds возвращает количество объявлений, подходящих под фильтр, с теми же условиями, что и Ads
+func (s *DBService) CountAds(ctx context.Context, filter AdsFilter) (int, error) {
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+
+	where, args, err := adsConditions(filter, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	err = s.retryRead(ctx, func() error {
+		return s.reader().QueryRow(ctx, fmt.Sprintf(QueryCountAds, where), args...).Scan(&count)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count ads: %w", classifyError(err))
+	}
+	return count, nil
+}
+
+// adsConditions проверяет фильтр и возвращает условия WHERE списка объявлений,
+// дописывая их параметры к args
+func adsConditions(filter AdsFilter, args []any) (string, []any, error) {
+	if filter.MinPrice > filter.MaxPrice {
+		return "", nil, ErrInvalidPriceRange
+	}
+	statuses := filter.Statuses
+	if len(statuses) == 0 {
+		statuses = []string{AdStatusActive}
+	}
+	for _, status := range statuses {
+		if err := validateStatus(status); err != nil {
+			return "", nil, err
+		}
+	}
+	tags, err := NormalizeTags(filter.Tags)
+	if err != nil {
+		return "", nil, err
+	}
+	cities, err := NormalizeCityFilter(filter.Cities)
+	if err != nil {
+		return "", nil, err
+	}
+
+	args = append(args, filter.MinPrice, filter.MaxPrice, statuses)
+	var conditions strings.Builder
+	fmt.Fprintf(&conditions, "a.price >= $%d AND a.price <= $%d AND a.status = ANY($%d) AND a.deleted_at IS NULL",
+		len(args)-2, len(args)-1, len(args))
+	if !filter.CreatedFrom.IsZero() {
+		args = append(args, filter.CreatedFrom.UTC())
+		fmt.Fprintf(&conditions, " AND a.created_at >= $%d", len(args))
+	}
+	if !filter.CreatedTo.IsZero() {
+		args = append(args, filter.CreatedTo.UTC())
+		fmt.Fprintf(&conditions, " AND a.created_at <= $%d", len(args))
+	}
+	if filter.SellerID != 0 {
+		args = append(args, filter.SellerID)
+		fmt.Fprintf(&conditions, " AND a.user_id = $%d", len(args))
+	}
+	if len(tags) > 0 {
+		args = append(args, tags)
+		fmt.Fprintf(&conditions, " AND a.tags @> $%d::text[]", len(args))
+	}
+	if len(cities) > 0 {
+		args = append(args, cities)
+		fmt.Fprintf(&conditions, " AND lower(a.city) = ANY($%d::text[])", len(args))
+	}
+	if !filter.IncludeExpired {
+		conditions.WriteString(" AND (a.status <> 'active' OR a.expires_at > CURRENT_TIMESTAMP)")
+	}
+	return conditions.String(), args, nil
+}
+
+// AdsByUser возвращает объявления пользователя userID с пагинацией и сортировкой,
+// аналогичными Ads, во всех статусах, включая истёкшие. Поле IsMine у всех объявлений равно true.
+func (s *DBService) AdsByUser(
+	ctx context.Context,
+	userID int,
+	page, size int,
+	sortBy, sortOrder string,
+) ([]Ad, error) {
+	return s.Ads(ctx, userID, page, size, sortBy, sortOrder, AdsFilter{
+		MaxPrice:       maxPrice,
+		Statuses:       []string{AdStatusActive, AdStatusSold, AdStatusArchived},
+		SellerID:       userID,
+		IncludeExpired: true,
+	})
+}
+
+// scanAds считывает объявления из результата запроса.
+func scanAds(rows pgx.Rows) ([]Ad, error) {
+	ads := make([]Ad, 0)
 	for rows.Next() {
-		var ad Ad
-		err := rows.Scan(
-			&ad.ID, &ad.Title, &ad.Text, &ad.ImageURL, &ad.Price,
-			&ad.UserID, &ad.CreatedAt, &ad.Author, &ad.IsMine,
-		)
+		ad, err := scanAd(rows)
 		if err != nil {
-			return nil, fmt.Errorf("failed to query ads: %w", err)
+			return nil, err
 		}
 		ads = append(ads, ad)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error during rows iteration: %w", err)
+		return nil, fmt.Errorf("error during rows iteration: %w", classifyError(err))
 	}
 
 	return ads, nil
 }
 
+// scanAd читает текущую строку запроса списка объявлений
+func scanAd(rows pgx.Rows) (Ad, error) {
+	var ad Ad
+	err := rows.Scan(
+		&ad.ID, &ad.Title, &ad.Text, &ad.ImageURL, &ad.Price,
+		&ad.UserID, &ad.Status, &ad.CreatedAt, &ad.Tags, &ad.City, &ad.ExpiresAt, &ad.Author, &ad.IsMine, &ad.Reserved, &ad.IsFavorite,
+	)
+	if err != nil {
+		return Ad{}, fmt.Errorf("failed to query ads: %w", classifyError(err))
+	}
+	return ad, nil
+}
+
+// UpdateAd частично обновляет объявление adID, принадлежащее userID.
+// Изменяются только переданные поля, остальные сохраняют текущие значения.
+func (s *DBService) UpdateAd(ctx context.Context, adID, userID int, upd AdUpdate) (Ad, error) {
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+
+	if upd.IsEmpty() {
+		return Ad{}, ErrEmptyUpdate
+	}
+	if err := validateAdUpdate(upd); err != nil {
+		return Ad{}, err
+	}
+
+	var (
+		sets []string
+		args []interface{}
+	)
+	set := func(column string, value interface{}) {
+		args = append(args, value)
+		sets = append(sets, fmt.Sprintf("%s = $%d", column, len(args)))
+	}
+	if upd.Title != nil {
+		set("title", *upd.Title)
+	}
+	if upd.Text != nil {
+		set("text", *upd.Text)
+	}
+	if upd.ImageURL != nil {
+		set("image_url", *upd.ImageURL)
+	}
+	if upd.Price != nil {
+		set("price", *upd.Price)
+	}
+	if upd.Tags != nil {
+		// Теги и город уже проверены validateAdUpdate
+		tags, _ := NormalizeTags(*upd.Tags)
+		set("tags", nonNilTags(tags))
+	}
+	if upd.City != nil {
+		city, _ := NormalizeCity(*upd.City)
+		set("city", city)
+	}
+	args = append(args, adID)
+	query := fmt.Sprintf(QueryUpdateAd, strings.Join(sets, ", "), len(args))
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return Ad{}, fmt.Errorf("failed to begin transaction: %w", classifyError(err))
+	}
+	defer tx.Rollback(ctx)
+
+	var ownerID int
+	if err := tx.QueryRow(ctx, QueryLockAdOwner, adID).Scan(&ownerID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Ad{}, ErrAdNotFound
+		}
+		return Ad{}, fmt.Errorf("failed to get ad: %w", classifyError(err))
+	}
+	if ownerID != userID {
+		return Ad{}, ErrNotAdOwner
+	}
+
+	var ad Ad
+	err = tx.QueryRow(ctx, query, args...).Scan(
+		&ad.ID, &ad.Title, &ad.Text, &ad.ImageURL, &ad.Price,
+		&ad.UserID, &ad.Status, &ad.CreatedAt, &ad.Tags, &ad.City, &ad.ExpiresAt, &ad.Author,
+	)
+	if err != nil {
+		return Ad{}, fmt.Errorf("failed to update ad: %w", classifyError(err))
+	}
+	ad.IsMine = true
+
+	if upd.ImageURL != nil {
+		if err := unlinkAdImage(ctx, tx, adID); err != nil {
+			return Ad{}, err
+		}
+		if uploadedImageURL.MatchString(ad.ImageURL) {
+			if err := linkAdImage(ctx, tx, adID); err != nil {
+				return Ad{}, err
+			}
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return Ad{}, fmt.Errorf("failed to commit transaction: %w", classifyError(err))
+	}
+
+	return ad, nil
+}
+
+// SetAdStatus меняет статус объявления adID, принадлежащего userID.
+func (s *DBService) SetAdStatus(ctx context.Context, adID, userID int, status string) (Ad, error) {
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+
+	if err := validateStatus(status); err != nil {
+		return Ad{}, err
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return Ad{}, fmt.Errorf("failed to begin transaction: %w", classifyError(err))
+	}
+	defer tx.Rollback(ctx)
+
+	var ownerID int
+	if err := tx.QueryRow(ctx, QueryLockAdOwner, adID).Scan(&ownerID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Ad{}, ErrAdNotFound
+		}
+		return Ad{}, fmt.Errorf("failed to get ad: %w", classifyError(err))
+	}
+	if ownerID != userID {
+		return Ad{}, ErrNotAdOwner
+	}
+
+	var ad Ad
+	err = tx.QueryRow(ctx, QuerySetAdStatus, status, adID).Scan(
+		&ad.ID, &ad.Title, &ad.Text, &ad.ImageURL, &ad.Price,
+		&ad.UserID, &ad.Status, &ad.CreatedAt, &ad.Tags, &ad.City, &ad.ExpiresAt, &ad.Author,
+	)
+	if err != nil {
+		return Ad{}, fmt.Errorf("failed to update ad status: %w", classifyError(err))
+	}
+	ad.IsMine = true
+
+	if err := tx.Commit(ctx); err != nil {
+		return Ad{}, fmt.Errorf("failed to commit transaction: %w", classifyError(err))
+	}
+
+	return ad, nil
+}
+
+// Ad возвращает объявление adID. Удалённые объявления не возвращаются.
+func (s *DBService) Ad(ctx context.Context, adID, userID int) (Ad, error) {
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+
+	var ad Ad
+	err := s.retryRead(ctx, func() error {
+		return s.reader().QueryRow(ctx, QueryGetAd, adID, userID).Scan(
+			&ad.ID, &ad.Title, &ad.Text, &ad.ImageURL, &ad.Price,
+			&ad.UserID, &ad.Status, &ad.CreatedAt, &ad.Tags, &ad.City, &ad.ExpiresAt, &ad.Author, &ad.IsMine, &ad.Reserved, &ad.IsFavorite,
+		)
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Ad{}, ErrAdNotFound
+		}
+		return Ad{}, fmt.Errorf("failed to get ad: %w", classifyError(err))
+	}
+	return ad, nil
+}
+
+// DeleteAd помечает объявление adID, принадлежащее userID, удалённым.
+// Строка остаётся в базе данных для истории, но больше не возвращается запросами.
+func (s *DBService) DeleteAd(ctx context.Context, adID, userID int) error {
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", classifyError(err))
+	}
+	defer tx.Rollback(ctx)
+
+	var ownerID int
+	if err := tx.QueryRow(ctx, QueryLockAdOwner, adID).Scan(&ownerID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrAdNotFound
+		}
+		return fmt.Errorf("failed to get ad: %w", classifyError(err))
+	}
+	if ownerID != userID {
+		return ErrNotAdOwner
+	}
+
+	if _, err := tx.Exec(ctx, QuerySoftDeleteAd, adID); err != nil {
+		return fmt.Errorf("failed to delete ad: %w", classifyError(err))
+	}
+	if err := unlinkAdImage(ctx, tx, adID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", classifyError(err))
+	}
+	return nil
+}
+
+// RemoveAd помечает объявление adID удалённым без проверки владельца и запоминает модератора moderatorID.
+func (s *DBService) RemoveAd(ctx context.Context, adID, moderatorID int) error {
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", classifyError(err))
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, QueryRemoveAd, adID, moderatorID)
+	if err != nil {
+		return fmt.Errorf("failed to remove ad: %w", classifyError(err))
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrAdNotFound
+	}
+	if err := unlinkAdImage(ctx, tx, adID); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", classifyError(err))
+	}
+	return nil
+}
+
+// PurgeAd безвозвратно удаляет объявление adID. Используется только в тестах.
+func (s *DBService) PurgeAd(ctx context.Context, adID int) error {
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+
+	if _, err := s.pool.Exec(ctx, QueryPurgeAd, adID); err != nil {
+		return fmt.Errorf("failed to purge ad: %w", classifyError(err))
+	}
+	return nil
+}
+
+// SuggestTitleWords возвращает слова из заголовков объявлений, начинающиеся с prefix,
+// упорядоченные по частоте. Префикс ожидается уже нормализованным (в нижнем регистре).
+func (s *DBService) SuggestTitleWords(ctx context.Context, prefix string, limit int) ([]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, suggestQueryTimeout)
+	defer cancel()
+
+	rows, err := s.pool.Query(ctx, QuerySuggestTitleWords, escapeLike(prefix), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query suggestions: %w", classifyError(err))
+	}
+	defer rows.Close()
+
+	words := make([]string, 0, limit)
+	for rows.Next() {
+		var (
+			word string
+			freq int64
+		)
+		if err := rows.Scan(&word, &freq); err != nil {
+			return nil, fmt.Errorf("failed to query suggestions: %w", classifyError(err))
+		}
+		words = append(words, word)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during rows iteration: %w", classifyError(err))
+	}
+
+	return words, nil
+}
+
 // Exec выполняет SQL-запрос без возврата строк.
 func (s *DBService) Exec(ctx context.Context, sql string, arguments ...interface{}) error {
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+
 	_, err := s.pool.Exec(ctx, sql, arguments...)
 	if err != nil {
-		return fmt.Errorf("failed to execute SQL query: %w", err)
+		return fmt.Errorf("failed to execute SQL query: %w", classifyError(err))
 	}
 	return nil
 }
 
+// validateSort проверяет поле и порядок сортировки по белому списку.
+func validateSort(sortBy, sortOrder string) error {
+	if _, ok := sortColumns[sortBy]; !ok {
+		return ErrInvalidSortBy
+	}
+	if sortOrder != "ASC" && sortOrder != "DESC" {
+		return ErrInvalidSortOrder
+	}
+	return nil
+}
+
+// validateStatus проверяет, что статус объявления допустим.
+func validateStatus(status string) error {
+	switch status {
+	case AdStatusActive, AdStatusSold, AdStatusArchived:
+		return nil
+	default:
+		return ErrInvalidStatus
+	}
+}
+
+// escapeLike экранирует спецсимволы шаблона LIKE.
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
+
 // validateAd выполняет валидацию объявления.
 func validateAd(ad Ad) error {
-	title := strings.TrimSpace(ad.Title)
-	if len(title) < minTitleLength || len(title) > maxTitleLength {
-		return ErrInvalidTitleLength
+	if err := ValidateTitle(ad.Title); err != nil {
+		return err
+	}
+	if err := ValidateText(ad.Text); err != nil {
+		return err
+	}
+	if err := ValidateImageURL(ad.ImageURL); err != nil {
+		return err
+	}
+	if err := validatePrice(ad.Price); err != nil {
+		return err
+	}
+	if err := validateTranslations(ad.Translations); err != nil {
+		return err
+	}
+	if _, err := NormalizeTags(ad.Tags); err != nil {
+		return err
+	}
+	if _, err := NormalizeCity(ad.City); err != nil {
+		return err
 	}
 
-	text := strings.TrimSpace(ad.Text)
-	if len(text) == 0 || len(text) > maxTextLength {
-		return ErrInvalidTextLength
+	if ad.UserID <= 0 {
+		return ErrInvalidUserID
 	}
 
-	if ad.ImageURL != "" {
-		_, err := url.ParseRequestURI(ad.ImageURL)
-		if err != nil {
-			return ErrInvalidImageURL
+	return nil
+}
+
+// validateAdUpdate проверяет только переданные поля обновления.
+func validateAdUpdate(upd AdUpdate) error {
+	if upd.Title != nil {
+		if err := ValidateTitle(*upd.Title); err != nil {
+			return err
+		}
+	}
+	if upd.Text != nil {
+		if err := ValidateText(*upd.Text); err != nil {
+			return err
+		}
+	}
+	if upd.ImageURL != nil {
+		if err := ValidateImageURL(*upd.ImageURL); err != nil {
+			return err
+		}
+	}
+	if upd.Price != nil {
+		if err := validatePrice(*upd.Price); err != nil {
+			return err
+		}
+	}
+	if upd.Tags != nil {
+		if _, err := NormalizeTags(*upd.Tags); err != nil {
+			return err
 		}
 	}
+	if upd.City != nil {
+		if _, err := NormalizeCity(*upd.City); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	if ad.Price < minPrice || ad.Price > maxPrice {
-		return ErrInvalidPrice
+// ValidateTitle проверяет длину заголовка без крайних пробелов в символах, а не байтах.
+// Те же правила применяет валидатор ad_title при разборе запросов API.
+func ValidateTitle(title string) error {
+	n := utf8.RuneCountInString(strings.TrimSpace(title))
+	if n < minTitleLength || n > maxTitleLength {
+		return ErrInvalidTitleLength
 	}
+	return nil
+}
 
-	if ad.UserID <= 0 {
-		return ErrInvalidUserID
+// ValidateText проверяет длину текста объявления аналогично ValidateTitle (валидатор ad_text).
+func ValidateText(text string) error {
+	n := utf8.RuneCountInString(strings.TrimSpace(text))
+	if n == 0 || n > maxTextLength {
+		return ErrInvalidTextLength
 	}
+	return nil
+}
+
+// ValidateImageURL проверяет, что непустой URL изображения - абсолютный http(s)-адрес с хостом
+// длиной не более 200 символов (размер столбца image_url). Те же правила применяет валидатор ad_image_url.
+func ValidateImageURL(imageURL string) error {
+	if imageURL == "" {
+		return nil
+	}
+	if utf8.RuneCountInString(imageURL) > maxImageURLLength {
+		return ErrInvalidImageURL
+	}
+	u, err := url.ParseRequestURI(imageURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ErrInvalidImageURL
+	}
+	return nil
+}
 
+func validatePrice(price int64) error {
+	if price < minPrice || price > maxPrice {
+		return ErrInvalidPrice
+	}
 	return nil
 }
@@ -0,0 +1,996 @@
+// Package dbtest содержит хранилище в памяти, заменяющее db.DBService в модульных тестах сервисов.
+package dbtest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/YuarenArt/marketgo/internal/db"
+)
+
+type ad struct {
+	db.Ad
+	deleted bool
+	// favorites - время добавления в избранное по ID пользователя
+	favorites map[int]time.Time
+}
+
+// image - учтённое загруженное изображение
+type image struct {
+	name      string
+	updatedAt time.Time
+}
+
+// uploadedName извлекает имя загруженного файла из image_url, как запросы ссылок на изображения db.DBService
+var uploadedName = regexp.MustCompile(`/uploads/([A-Za-z0-9_.-]+)$`)
+
+type refreshToken struct {
+	userID    int
+	expiresAt time.Time
+	revoked   bool
+}
+
+// Store хранит пользователей, объявления, избранное, токены, уведомления и изображения в памяти и возвращает
+// те же ошибки, что и db.DBService. Поля объявлений не проверяются: это покрыто тестами пакета db.
+// Настройки уведомлений не поддерживаются - уведомления создаются всегда.
+type Store struct {
+	// Now возвращает текущее время; тесты могут подменить его
+	Now func() time.Time
+
+	mu            sync.Mutex
+	users         []db.User
+	ads           []*ad
+	translations  map[int]map[string]db.AdTranslation
+	refresh       map[string]*refreshToken
+	revoked       map[string]time.Time
+	notifications []db.Notification
+	images        map[string]*image
+	// adImages - хеш изображения, на которое ссылается объявление, по ID объявления
+	adImages map[int]string
+}
+
+// NewStore создает пустое хранилище
+func NewStore() *Store {
+	return &Store{
+		Now:          time.Now,
+		translations: make(map[int]map[string]db.AdTranslation),
+		refresh:      make(map[string]*refreshToken),
+		revoked:      make(map[string]time.Time),
+		images:       make(map[string]*image),
+		adImages:     make(map[int]string),
+	}
+}
+
+// CreateUser создаёт пользователя; занятый логин возвращает db.ErrLoginTaken
+func (s *Store) CreateUser(_ context.Context, login, hashedPassword string) (db.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.userByLogin(login); ok {
+		return db.User{}, db.ErrLoginTaken
+	}
+	user := db.User{
+		ID:        len(s.users) + 1,
+		Login:     login,
+		Password:  hashedPassword,
+		CreatedAt: s.Now().UTC(),
+		Role:      db.RoleUser,
+	}
+	s.users = append(s.users, user)
+	user.Password = ""
+	return user, nil
+}
+
+// UserByLogin возвращает пользователя по логину вместе с хешем пароля
+func (s *Store) UserByLogin(_ context.Context, login string) (db.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, ok := s.userByLogin(login)
+	if !ok {
+		return db.User{}, db.ErrLoginNotFound
+	}
+	return s.users[i], nil
+}
+
+// UserByID возвращает пользователя по id без пароля
+func (s *Store) UserByID(_ context.Context, id int) (db.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, ok := s.userByID(id)
+	if !ok {
+		return db.User{}, db.ErrUserNotFound
+	}
+	user := s.users[i]
+	user.Password = ""
+	return user, nil
+}
+
+// SetUserRole назначает пользователю с логином login роль role
+func (s *Store) SetUserRole(_ context.Context, login, role string) (db.User, error) {
+	if role != db.RoleUser && role != db.RoleAdmin {
+		return db.User{}, db.ErrInvalidRole
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, ok := s.userByLogin(login)
+	if !ok {
+		return db.User{}, db.ErrLoginNotFound
+	}
+	s.users[i].Role = role
+	user := s.users[i]
+	user.Password = ""
+	return user, nil
+}
+
+// DeleteUser удаляет пользователя userID вместе с его объявлениями и уведомлениями.
+// Объявления помечаются удалёнными, чтобы ID оставшихся объявлений не менялись.
+func (s *Store) DeleteUser(_ context.Context, userID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, ok := s.userByID(userID)
+	if !ok {
+		return db.ErrUserNotFound
+	}
+	s.users[i] = db.User{}
+	for _, a := range s.ads {
+		if a.UserID == userID {
+			a.deleted = true
+			delete(s.adImages, a.ID)
+		}
+	}
+	s.notifications = slices.DeleteFunc(s.notifications, func(n db.Notification) bool { return n.UserID == userID })
+	return nil
+}
+
+// CountUserAds возвращает количество неудалённых объявлений пользователя во всех статусах
+func (s *Store) CountUserAds(_ context.Context, userID int) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count := 0
+	for _, a := range s.ads {
+		if !a.deleted && a.UserID == userID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// CreateRefreshToken сохраняет хеш refresh-токена пользователя userID
+func (s *Store) CreateRefreshToken(_ context.Context, userID int, tokenHash string, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.refresh[tokenHash] = &refreshToken{userID: userID, expiresAt: expiresAt}
+	return nil
+}
+
+// RotateRefreshToken отзывает токен oldHash и сохраняет вместо него newHash; возвращает владельца токена
+func (s *Store) RotateRefreshToken(_ context.Context, oldHash, newHash string, expiresAt time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, ok := s.refresh[oldHash]
+	switch {
+	case !ok:
+		return 0, db.ErrRefreshTokenNotFound
+	case token.revoked:
+		return 0, db.ErrRefreshTokenRevoked
+	case !token.expiresAt.After(s.Now()):
+		return 0, db.ErrRefreshTokenExpired
+	}
+	token.revoked = true
+	s.refresh[newHash] = &refreshToken{userID: token.userID, expiresAt: expiresAt}
+	return token.userID, nil
+}
+
+// RevokeUserRefreshToken отзывает refresh-токен tokenHash пользователя userID
+func (s *Store) RevokeUserRefreshToken(_ context.Context, userID int, tokenHash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if token, ok := s.refresh[tokenHash]; ok && token.userID == userID {
+		token.revoked = true
+	}
+	return nil
+}
+
+// RevokeToken вносит токен jti в список отозванных до expiresAt
+func (s *Store) RevokeToken(_ context.Context, jti string, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.revoked[jti] = expiresAt
+	return nil
+}
+
+// IsTokenRevoked сообщает, отозван ли токен jti
+func (s *Store) IsTokenRevoked(_ context.Context, jti string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.revoked[jti]
+	return ok, nil
+}
+
+// DeleteExpiredRevocations удаляет отозванные токены, истёкшие до before
+func (s *Store) DeleteExpiredRevocations(_ context.Context, before time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var deleted int64
+	for jti, expiresAt := range s.revoked {
+		if expiresAt.Before(before) {
+			delete(s.revoked, jti)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// CreateAd создаёт активное объявление пользователя ad.UserID
+func (s *Store) CreateAd(_ context.Context, newAd db.Ad) (db.Ad, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i, ok := s.userByID(newAd.UserID)
+	if !ok {
+		return db.Ad{}, db.ErrUserNotFound
+	}
+	tags, err := db.NormalizeTags(newAd.Tags)
+	if err != nil {
+		return db.Ad{}, err
+	}
+	city, err := db.NormalizeCity(newAd.City)
+	if err != nil {
+		return db.Ad{}, err
+	}
+	expiresAt := newAd.ExpiresAt
+	if expiresAt.IsZero() {
+		expiresAt = s.Now().Add(db.DefaultAdTTL)
+	}
+	created := db.Ad{
+		ID:        len(s.ads) + 1,
+		Title:     newAd.Title,
+		Text:      newAd.Text,
+		ImageURL:  newAd.ImageURL,
+		Price:     newAd.Price,
+		UserID:    newAd.UserID,
+		Status:    db.AdStatusActive,
+		Author:    s.users[i].Login,
+		CreatedAt: s.Now().UTC(),
+		Tags:      tags,
+		City:      city,
+		ExpiresAt: expiresAt.UTC(),
+	}
+	s.ads = append(s.ads, &ad{Ad: created})
+	s.linkImage(created.ID, created.ImageURL)
+	if len(newAd.Translations) > 0 {
+		s.translations[created.ID] = newAd.Translations
+		created.Translations = newAd.Translations
+	}
+	created.IsMine = true
+	return created, nil
+}
+
+// Ad возвращает неудалённое объявление adID
+func (s *Store) Ad(_ context.Context, adID, userID int) (db.Ad, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a, ok := s.ad(adID)
+	if !ok {
+		return db.Ad{}, db.ErrAdNotFound
+	}
+	return view(a, userID), nil
+}
+
+// Ads возвращает страницу объявлений по фильтрам и сортировке, как db.DBService.Ads
+func (s *Store) Ads(_ context.Context, userID, page, size int, sortBy, sortOrder string, filter db.AdsFilter) ([]db.Ad, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ads, err := s.filter(filter, sortBy, sortOrder)
+	if err != nil {
+		return nil, err
+	}
+	return views(paginate(ads, (page-1)*size, size), userID), nil
+}
+
+// AdsAfterCursor возвращает до size объявлений, следующих за after, как db.DBService.AdsAfterCursor
+func (s *Store) AdsAfterCursor(
+	_ context.Context,
+	userID, size int,
+	sortBy, sortOrder string,
+	filter db.AdsFilter,
+	after *db.AdsCursor,
+) ([]db.Ad, *db.AdsCursor, error) {
+	if after != nil && (after.SortBy != sortBy || after.SortOrder != sortOrder) {
+		return nil, nil, db.ErrCursorMismatch
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ads, err := s.filter(filter, sortBy, sortOrder)
+	if err != nil {
+		return nil, nil, err
+	}
+	if size < 1 {
+		return []db.Ad{}, nil, nil
+	}
+	if after != nil {
+		cursorAd := &ad{Ad: db.Ad{ID: after.ID, CreatedAt: after.CreatedAt, Price: after.Price, Title: after.Title}}
+		ads = slices.DeleteFunc(ads, func(a *ad) bool {
+			c := compare(a, cursorAd, sortBy)
+			return (sortOrder == "ASC" && c <= 0) || (sortOrder == "DESC" && c >= 0)
+		})
+	}
+	if len(ads) <= size {
+		return views(ads, userID), nil, nil
+	}
+
+	ads = ads[:size]
+	last := ads[size-1]
+	next := &db.AdsCursor{
+		SortBy:    sortBy,
+		SortOrder: sortOrder,
+		ID:        last.ID,
+		CreatedAt: last.CreatedAt,
+		Price:     last.Price,
+		Title:     last.Title,
+	}
+	return views(ads, userID), next, nil
+}
+
+// StreamAds передаёт fn до limit объявлений, как db.DBService.StreamAds.
+// fn вызывается без блокировки хранилища.
+func (s *Store) StreamAds(
+	_ context.Context,
+	userID, limit int,
+	sortBy, sortOrder string,
+	filter db.AdsFilter,
+	fn func(db.Ad) error,
+) error {
+	s.mu.Lock()
+	ads, err := s.filter(filter, sortBy, sortOrder)
+	var result []db.Ad
+	if err == nil {
+		result = views(paginate(ads, 0, limit), userID)
+	}
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	for _, ad := range result {
+		if err := fn(ad); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AdsByIDs возвращает подходящие под фильтр объявления с указанными ID в порядке ids
+func (s *Store) AdsByIDs(_ context.Context, userID int, ids []int, filter db.AdsFilter) ([]db.Ad, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ads, err := s.filter(filter, "created_at", "ASC")
+	if err != nil {
+		return nil, err
+	}
+	result := make([]db.Ad, 0, len(ids))
+	for _, id := range ids {
+		if i := slices.IndexFunc(ads, func(a *ad) bool { return a.ID == id }); i >= 0 {
+			result = append(result, view(ads[i], userID))
+		}
+	}
+	return result, nil
+}
+
+// AdsByUser возвращает объявления пользователя userID во всех статусах
+func (s *Store) AdsByUser(ctx context.Context, userID, page, size int, sortBy, sortOrder string) ([]db.Ad, error) {
+	return s.Ads(ctx, userID, page, size, sortBy, sortOrder, db.AdsFilter{
+		MaxPrice:       100_000_000,
+		Statuses:       []string{db.AdStatusActive, db.AdStatusSold, db.AdStatusArchived},
+		SellerID:       userID,
+		IncludeExpired: true,
+	})
+}
+
+// CountAds возвращает количество объявлений, подходящих под фильтр
+func (s *Store) CountAds(_ context.Context, filter db.AdsFilter) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ads, err := s.filter(filter, "created_at", "ASC")
+	if err != nil {
+		return 0, err
+	}
+	return len(ads), nil
+}
+
+// UpdateAd частично обновляет объявление adID, принадлежащее userID
+func (s *Store) UpdateAd(_ context.Context, adID, userID int, upd db.AdUpdate) (db.Ad, error) {
+	if upd.IsEmpty() {
+		return db.Ad{}, db.ErrEmptyUpdate
+	}
+	var tags []string
+	if upd.Tags != nil {
+		var err error
+		if tags, err = db.NormalizeTags(*upd.Tags); err != nil {
+			return db.Ad{}, err
+		}
+	}
+	var city string
+	if upd.City != nil {
+		var err error
+		if city, err = db.NormalizeCity(*upd.City); err != nil {
+			return db.Ad{}, err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a, err := s.ownAd(adID, userID)
+	if err != nil {
+		return db.Ad{}, err
+	}
+	if upd.Title != nil {
+		a.Title = *upd.Title
+	}
+	if upd.Text != nil {
+		a.Text = *upd.Text
+	}
+	if upd.ImageURL != nil {
+		a.ImageURL = *upd.ImageURL
+		delete(s.adImages, a.ID)
+		s.linkImage(a.ID, a.ImageURL)
+	}
+	if upd.Price != nil {
+		a.Price = *upd.Price
+	}
+	if upd.Tags != nil {
+		a.Tags = tags
+	}
+	if upd.City != nil {
+		a.City = city
+	}
+	return view(a, userID), nil
+}
+
+// SetAdStatus меняет статус объявления adID, принадлежащего userID
+func (s *Store) SetAdStatus(_ context.Context, adID, userID int, status string) (db.Ad, error) {
+	if err := validateStatus(status); err != nil {
+		return db.Ad{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a, err := s.ownAd(adID, userID)
+	if err != nil {
+		return db.Ad{}, err
+	}
+	a.Status = status
+	return view(a, userID), nil
+}
+
+// RenewAd продлевает объявление adID, принадлежащее userID, до expiresAt; архивное снова становится активным
+func (s *Store) RenewAd(_ context.Context, adID, userID int, expiresAt time.Time) (db.Ad, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a, err := s.ownAd(adID, userID)
+	if err != nil {
+		return db.Ad{}, err
+	}
+	if a.Status == db.AdStatusSold {
+		return db.Ad{}, db.ErrAdNotRenewable
+	}
+	a.Status = db.AdStatusActive
+	a.ExpiresAt = expiresAt.UTC()
+	return view(a, userID), nil
+}
+
+// ExpireAds переводит активные объявления с истёкшим сроком публикации в archived и возвращает их
+func (s *Store) ExpireAds(_ context.Context) ([]db.Ad, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.Now()
+	expired := make([]db.Ad, 0)
+	for _, a := range s.ads {
+		if a.deleted || a.Status != db.AdStatusActive || a.ExpiresAt.After(now) {
+			continue
+		}
+		a.Status = db.AdStatusArchived
+		expired = append(expired, view(a, 0))
+	}
+	return expired, nil
+}
+
+// DeleteAd помечает объявление adID, принадлежащее userID, удалённым
+func (s *Store) DeleteAd(_ context.Context, adID, userID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a, err := s.ownAd(adID, userID)
+	if err != nil {
+		return err
+	}
+	a.deleted = true
+	delete(s.adImages, a.ID)
+	return nil
+}
+
+// RemoveAd помечает объявление adID удалённым без проверки владельца
+func (s *Store) RemoveAd(_ context.Context, adID, _ int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a, ok := s.ad(adID)
+	if !ok {
+		return db.ErrAdNotFound
+	}
+	a.deleted = true
+	delete(s.adImages, a.ID)
+	return nil
+}
+
+// AddFavorite добавляет неудалённое объявление adID в избранное userID; повторное добавление ничего не меняет
+func (s *Store) AddFavorite(_ context.Context, userID, adID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a, ok := s.ad(adID)
+	if !ok {
+		return db.ErrAdNotFound
+	}
+	if a.favorites == nil {
+		a.favorites = make(map[int]time.Time)
+	}
+	if _, ok := a.favorites[userID]; !ok {
+		a.favorites[userID] = s.Now()
+	}
+	return nil
+}
+
+// RemoveFavorite убирает объявление adID из избранного userID
+func (s *Store) RemoveFavorite(_ context.Context, userID, adID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if adID >= 1 && adID <= len(s.ads) {
+		delete(s.ads[adID-1].favorites, userID)
+	}
+	return nil
+}
+
+// Favorites возвращает страницу неудалённых избранных объявлений userID, начиная с добавленных последними
+func (s *Store) Favorites(_ context.Context, userID, page, size int) ([]db.Ad, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var ads []*ad
+	for _, a := range s.ads {
+		if _, ok := a.favorites[userID]; ok && !a.deleted {
+			ads = append(ads, a)
+		}
+	}
+	sort.SliceStable(ads, func(i, j int) bool {
+		ti, tj := ads[i].favorites[userID], ads[j].favorites[userID]
+		if !ti.Equal(tj) {
+			return ti.After(tj)
+		}
+		return ads[i].ID > ads[j].ID
+	})
+	return views(paginate(ads, (page-1)*size, size), userID), nil
+}
+
+// FavoriteAdIDs возвращает, какие из объявлений adIDs находятся в избранном userID
+func (s *Store) FavoriteAdIDs(_ context.Context, userID int, adIDs []int) (map[int]bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	favorites := make(map[int]bool)
+	for _, id := range adIDs {
+		if id >= 1 && id <= len(s.ads) {
+			if _, ok := s.ads[id-1].favorites[userID]; ok {
+				favorites[id] = true
+			}
+		}
+	}
+	return favorites, nil
+}
+
+// SuggestTitleWords возвращает слова из заголовков активных объявлений, начинающиеся с prefix,
+// по убыванию частоты
+func (s *Store) SuggestTitleWords(_ context.Context, prefix string, limit int) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	freq := make(map[string]int)
+	for _, a := range s.ads {
+		if a.deleted || a.Status != db.AdStatusActive {
+			continue
+		}
+		for _, word := range strings.Fields(strings.ToLower(a.Title)) {
+			if strings.HasPrefix(word, prefix) {
+				freq[word]++
+			}
+		}
+	}
+
+	words := make([]string, 0, len(freq))
+	for word := range freq {
+		words = append(words, word)
+	}
+	sort.Slice(words, func(i, j int) bool {
+		if freq[words[i]] != freq[words[j]] {
+			return freq[words[i]] > freq[words[j]]
+		}
+		return words[i] < words[j]
+	})
+	return paginate(words, 0, limit), nil
+}
+
+// AdTranslations возвращает переводы объявлений adIDs, сохранённые при создании
+func (s *Store) AdTranslations(_ context.Context, adIDs []int) (map[int]map[string]db.AdTranslation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make(map[int]map[string]db.AdTranslation)
+	for _, id := range adIDs {
+		if tr, ok := s.translations[id]; ok {
+			result[id] = tr
+		}
+	}
+	return result, nil
+}
+
+// SearchAds ищет подстроку query без учёта регистра в заголовке и тексте объявлений.
+// Релевантность не вычисляется: найденные объявления упорядочены по возрастанию id.
+func (s *Store) SearchAds(_ context.Context, query string, filter db.AdsFilter, limit, offset int) ([]int, int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ads, err := s.filter(filter, "created_at", "ASC")
+	if err != nil {
+		return nil, 0, err
+	}
+	query = strings.ToLower(query)
+	ids := make([]int, 0)
+	for _, a := range ads {
+		if strings.Contains(strings.ToLower(a.Title+" "+a.Text), query) {
+			ids = append(ids, a.ID)
+		}
+	}
+	slices.Sort(ids)
+	return paginate(ids, offset, limit), len(ids), nil
+}
+
+// SimilarAds возвращает активные объявления других продавцов, в заголовке или тексте которых
+// есть слова заголовка adID. Совпадения в заголовке весят вдвое больше, при равенстве
+// выше объявления с более близкой ценой.
+func (s *Store) SimilarAds(_ context.Context, adID, userID, limit int) ([]db.Ad, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	src, ok := s.ad(adID)
+	if !ok {
+		return nil, db.ErrAdNotFound
+	}
+	terms := strings.Fields(strings.ToLower(src.Title))
+
+	now := s.Now()
+	scores := make(map[int]int)
+	var ads []*ad
+	for _, a := range s.ads {
+		if a.deleted || a.ID == src.ID || a.UserID == src.UserID ||
+			a.Status != db.AdStatusActive || !a.ExpiresAt.After(now) {
+			continue
+		}
+		title := strings.Fields(strings.ToLower(a.Title))
+		text := strings.Fields(strings.ToLower(a.Text))
+		score := 0
+		for _, term := range terms {
+			if slices.Contains(title, term) {
+				score += 2
+			}
+			if slices.Contains(text, term) {
+				score++
+			}
+		}
+		if score > 0 {
+			scores[a.ID] = score
+			ads = append(ads, a)
+		}
+	}
+	distance := func(a *ad) int64 {
+		if a.Price > src.Price {
+			return a.Price - src.Price
+		}
+		return src.Price - a.Price
+	}
+	sort.Slice(ads, func(i, j int) bool {
+		if scores[ads[i].ID] != scores[ads[j].ID] {
+			return scores[ads[i].ID] > scores[ads[j].ID]
+		}
+		if c := cmpInt64(distance(ads[i]), distance(ads[j])); c != 0 {
+			return c < 0
+		}
+		return ads[i].ID < ads[j].ID
+	})
+	return views(paginate(ads, 0, limit), userID), nil
+}
+
+// CreateNotification создаёт уведомление пользователя userID
+func (s *Store) CreateNotification(_ context.Context, userID int, notificationType string, payload any) (db.Notification, bool, error) {
+	if !slices.Contains(db.NotificationTypes, notificationType) {
+		return db.Notification{}, false, db.ErrUnknownNotificationType
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return db.Notification{}, false, fmt.Errorf("failed to marshal notification payload: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := db.Notification{
+		ID:        len(s.notifications) + 1,
+		UserID:    userID,
+		Type:      notificationType,
+		Payload:   data,
+		CreatedAt: s.Now().UTC(),
+	}
+	s.notifications = append(s.notifications, n)
+	return n, true, nil
+}
+
+// Notifications возвращает уведомления пользователя userID в порядке создания
+func (s *Store) Notifications(userID int) []db.Notification {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var result []db.Notification
+	for _, n := range s.notifications {
+		if n.UserID == userID {
+			result = append(result, n)
+		}
+	}
+	return result
+}
+
+// RegisterImage учитывает изображение, как db.DBService.RegisterImage
+func (s *Store) RegisterImage(_ context.Context, hash, name string, _ int) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if img, ok := s.images[hash]; ok {
+		img.updatedAt = s.Now()
+		return img.name, false, nil
+	}
+	s.images[hash] = &image{name: name, updatedAt: s.Now()}
+	return name, true, nil
+}
+
+// CollectImages удаляет изображения без ссылок объявлений, не загружавшиеся с unusedBefore,
+// как db.DBService.CollectImages. Запись изображения, которое не удалось удалить, сохраняется.
+func (s *Store) CollectImages(_ context.Context, unusedBefore time.Time, remove func(name string) error) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	referenced := make(map[string]bool)
+	for _, hash := range s.adImages {
+		referenced[hash] = true
+	}
+
+	removed := make([]string, 0)
+	for hash, img := range s.images {
+		if referenced[hash] || !img.updatedAt.Before(unusedBefore) {
+			continue
+		}
+		if err := remove(img.name); err != nil {
+			return removed, fmt.Errorf("failed to remove image %s: %w", img.name, err)
+		}
+		delete(s.images, hash)
+		removed = append(removed, img.name)
+	}
+	sort.Strings(removed)
+	return removed, nil
+}
+
+// linkImage записывает ссылку объявления adID на загруженное изображение из imageURL
+func (s *Store) linkImage(adID int, imageURL string) {
+	m := uploadedName.FindStringSubmatch(imageURL)
+	if m == nil {
+		return
+	}
+	for hash, img := range s.images {
+		if img.name == m[1] {
+			s.adImages[adID] = hash
+			return
+		}
+	}
+}
+
+func (s *Store) userByLogin(login string) (int, bool) {
+	i := slices.IndexFunc(s.users, func(u db.User) bool { return u.ID != 0 && u.Login == login })
+	return i, i >= 0
+}
+
+func (s *Store) userByID(id int) (int, bool) {
+	if id < 1 || id > len(s.users) || s.users[id-1].ID == 0 {
+		return 0, false
+	}
+	return id - 1, true
+}
+
+func (s *Store) ad(adID int) (*ad, bool) {
+	if adID < 1 || adID > len(s.ads) || s.ads[adID-1].deleted {
+		return nil, false
+	}
+	return s.ads[adID-1], true
+}
+
+func (s *Store) ownAd(adID, userID int) (*ad, error) {
+	a, ok := s.ad(adID)
+	if !ok {
+		return nil, db.ErrAdNotFound
+	}
+	if a.UserID != userID {
+		return nil, db.ErrNotAdOwner
+	}
+	return a, nil
+}
+
+// filter возвращает неудалённые объявления, подходящие под фильтр, в порядке сортировки
+func (s *Store) filter(filter db.AdsFilter, sortBy, sortOrder string) ([]*ad, error) {
+	if sortBy != "created_at" && sortBy != "price" && sortBy != "title" {
+		return nil, db.ErrInvalidSortBy
+	}
+	if sortOrder != "ASC" && sortOrder != "DESC" {
+		return nil, db.ErrInvalidSortOrder
+	}
+	if filter.MinPrice > filter.MaxPrice {
+		return nil, db.ErrInvalidPriceRange
+	}
+	statuses := filter.Statuses
+	if len(statuses) == 0 {
+		statuses = []string{db.AdStatusActive}
+	}
+	for _, status := range statuses {
+		if err := validateStatus(status); err != nil {
+			return nil, err
+		}
+	}
+	tags, err := db.NormalizeTags(filter.Tags)
+	if err != nil {
+		return nil, err
+	}
+	cities, err := db.NormalizeCityFilter(filter.Cities)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.Now()
+	ads := make([]*ad, 0, len(s.ads))
+	for _, a := range s.ads {
+		switch {
+		case a.deleted,
+			!filter.IncludeExpired && a.Status == db.AdStatusActive && !a.ExpiresAt.After(now),
+			a.Price < filter.MinPrice || a.Price > filter.MaxPrice,
+			!slices.Contains(statuses, a.Status),
+			!filter.CreatedFrom.IsZero() && a.CreatedAt.Before(filter.CreatedFrom),
+			!filter.CreatedTo.IsZero() && a.CreatedAt.After(filter.CreatedTo),
+			filter.SellerID != 0 && a.UserID != filter.SellerID,
+			!hasTags(a.Tags, tags),
+			len(cities) > 0 && !slices.Contains(cities, strings.ToLower(a.City)):
+			continue
+		}
+		ads = append(ads, a)
+	}
+
+	sort.SliceStable(ads, func(i, j int) bool {
+		c := compare(ads[i], ads[j], sortBy)
+		if sortOrder == "DESC" {
+			return c > 0
+		}
+		return c < 0
+	})
+	return ads, nil
+}
+
+// compare сравнивает объявления по полю сортировки, при равенстве - по id
+func compare(a, b *ad, sortBy string) int {
+	var c int
+	switch sortBy {
+	case "price":
+		c = cmpInt64(a.Price, b.Price)
+	case "title":
+		c = strings.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title))
+	default:
+		c = a.CreatedAt.Compare(b.CreatedAt)
+	}
+	if c != 0 {
+		return c
+	}
+	return cmpInt64(int64(a.ID), int64(b.ID))
+}
+
+func cmpInt64(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// hasTags сообщает, что среди tags есть все теги want
+func hasTags(tags, want []string) bool {
+	for _, tag := range want {
+		if !slices.Contains(tags, tag) {
+			return false
+		}
+	}
+	return true
+}
+
+func validateStatus(status string) error {
+	switch status {
+	case db.AdStatusActive, db.AdStatusSold, db.AdStatusArchived:
+		return nil
+	default:
+		return db.ErrInvalidStatus
+	}
+}
+
+// view возвращает копию объявления с признаками is_mine и is_favorite для userID
+func view(a *ad, userID int) db.Ad {
+	v := a.Ad
+	v.Tags = slices.Clone(a.Tags)
+	v.IsMine = userID != 0 && v.UserID == userID
+	_, v.IsFavorite = a.favorites[userID]
+	return v
+}
+
+func views(ads []*ad, userID int) []db.Ad {
+	result := make([]db.Ad, 0, len(ads))
+	for _, a := range ads {
+		result = append(result, view(a, userID))
+	}
+	return result
+}
+
+func paginate[T any](items []T, offset, limit int) []T {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(items) {
+		return items[:0]
+	}
+	items = items[offset:]
+	if limit >= 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	return items
+}
@@ -0,0 +1,108 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	ErrMsgNotFound    = "запись не найдена"
+	ErrMsgConflict    = "запись конфликтует с существующими данными"
+	ErrMsgUnavailable = "база данных недоступна"
+	ErrMsgTimeout     = "превышено время ожидания запроса к базе данных"
+	ErrMsgInvalid     = "некорректные данные запроса"
+	ErrMsgForbidden   = "недостаточно прав для операции"
+
+	pgUniqueViolation     = "23505"
+	pgForeignKeyViolation = "23503"
+)
+
+// Общие виды ошибок DBService. Более точные ошибки пакета (ErrLoginNotFound, ErrLoginTaken,
+// ErrInvalidPrice, ErrNotAdOwner и т.д.) также распознаются через errors.Is как один из этих видов.
+var (
+	ErrNotFound    = newError(ErrMsgNotFound)
+	ErrConflict    = newError(ErrMsgConflict)
+	ErrUnavailable = newError(ErrMsgUnavailable)
+	ErrTimeout     = newError(ErrMsgTimeout)
+	// ErrInvalid - данные не прошли проверку
+	ErrInvalid = newError(ErrMsgInvalid)
+	// ErrForbidden - операция запрещена пользователю
+	ErrForbidden = newError(ErrMsgForbidden)
+)
+
+// kindError - ошибка пакета, относящаяся к одному из общих видов ошибок
+type kindError struct {
+	msg  string
+	kind error
+}
+
+func newKindError(kind error, msg string) error {
+	return &kindError{msg: msg, kind: kind}
+}
+
+func (e *kindError) Error() string { return e.msg }
+
+func (e *kindError) Unwrap() error { return e.kind }
+
+// Message возвращает текст ошибки пакета из цепочки err без обёрток и подробностей pgx,
+// чтобы его можно было показать клиенту. Если в цепочке нет ошибки пакета, возвращает "".
+func Message(err error) string {
+	var kindErr *kindError
+	if errors.As(err, &kindErr) {
+		return kindErr.msg
+	}
+	for _, sentinel := range []error{
+		ErrRefreshTokenNotFound, ErrRefreshTokenRevoked, ErrRefreshTokenExpired,
+		ErrNotFound, ErrConflict, ErrInvalid, ErrForbidden, ErrTimeout, ErrUnavailable,
+	} {
+		if errors.Is(err, sentinel) {
+			return sentinel.Error()
+		}
+	}
+	return ""
+}
+
+// causeError - ошибка пакета с исходной ошибкой pgx в цепочке; текст берётся из ошибки пакета
+type causeError struct {
+	sentinel error
+	cause    error
+}
+
+// wrapError возвращает sentinel, сохраняя cause доступной для errors.Is и errors.As
+func wrapError(sentinel, cause error) error {
+	return &causeError{sentinel: sentinel, cause: cause}
+}
+
+func (e *causeError) Error() string { return e.sentinel.Error() }
+
+func (e *causeError) Unwrap() []error { return []error{e.sentinel, e.cause} }
+
+// classifyError дополняет ошибку pgx общим видом: pgx.ErrNoRows - ErrNotFound,
+// нарушение уникальности или внешнего ключа - ErrConflict, истечение времени запроса - ErrTimeout,
+// ошибка соединения - ErrUnavailable. Остальные ошибки возвращаются без изменений.
+func classifyError(err error) error {
+	var pgErr *pgconn.PgError
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, pgx.ErrNoRows):
+		return fmt.Errorf("%w: %w", ErrNotFound, err)
+	case errors.As(err, &pgErr) && (pgErr.Code == pgUniqueViolation || pgErr.Code == pgForeignKeyViolation):
+		return fmt.Errorf("%w: %w", ErrConflict, err)
+	case IsTimeout(err):
+		return fmt.Errorf("%w: %w", ErrTimeout, err)
+	case IsUnavailable(err):
+		return fmt.Errorf("%w: %w", ErrUnavailable, err)
+	default:
+		return err
+	}
+}
+
+// IsTimeout сообщает, что запрос прерван по истечении времени ожидания, в том числе заданного WithQueryTimeout
+func IsTimeout(err error) bool {
+	return errors.Is(err, ErrTimeout) || errors.Is(err, context.DeadlineExceeded)
+}
@@ -0,0 +1,97 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// DefaultAdTTL - срок публикации объявления, если ExpiresAt не задан при создании
+const DefaultAdTTL = 30 * 24 * time.Hour
+
+const ErrMsgAdNotRenewable = "проданное объявление нельзя продлить"
+
+var ErrAdNotRenewable = newKindError(ErrConflict, ErrMsgAdNotRenewable)
+
+// adExpiresAt возвращает срок публикации нового объявления: ad.ExpiresAt или DefaultAdTTL от текущего момента
+func adExpiresAt(ad Ad) time.Time {
+	if ad.ExpiresAt.IsZero() {
+		return time.Now().UTC().Add(DefaultAdTTL)
+	}
+	return ad.ExpiresAt.UTC()
+}
+
+// RenewAd продлевает публикацию объявления adID, принадлежащего userID, до expiresAt.
+// Архивное объявление снова становится активным; проданное продлить нельзя - ErrAdNotRenewable.
+func (s *DBService) RenewAd(ctx context.Context, adID, userID int, expiresAt time.Time) (Ad, error) {
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return Ad{}, fmt.Errorf("failed to begin transaction: %w", classifyError(err))
+	}
+	defer tx.Rollback(ctx)
+
+	var ownerID int
+	var status string
+	if err := tx.QueryRow(ctx, QueryLockAdForRenew, adID).Scan(&ownerID, &status); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Ad{}, ErrAdNotFound
+		}
+		return Ad{}, fmt.Errorf("failed to get ad: %w", classifyError(err))
+	}
+	if ownerID != userID {
+		return Ad{}, ErrNotAdOwner
+	}
+	if status == AdStatusSold {
+		return Ad{}, ErrAdNotRenewable
+	}
+
+	var ad Ad
+	err = tx.QueryRow(ctx, QueryRenewAd, expiresAt.UTC(), adID).Scan(
+		&ad.ID, &ad.Title, &ad.Text, &ad.ImageURL, &ad.Price,
+		&ad.UserID, &ad.Status, &ad.CreatedAt, &ad.Tags, &ad.City, &ad.ExpiresAt, &ad.Author,
+	)
+	if err != nil {
+		return Ad{}, fmt.Errorf("failed to renew ad: %w", classifyError(err))
+	}
+	ad.IsMine = true
+
+	if err := tx.Commit(ctx); err != nil {
+		return Ad{}, fmt.Errorf("failed to commit transaction: %w", classifyError(err))
+	}
+	return ad, nil
+}
+
+// ExpireAds переводит активные объявления с истёкшим сроком публикации в archived и возвращает их
+func (s *DBService) ExpireAds(ctx context.Context) ([]Ad, error) {
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := s.pool.Query(ctx, QueryExpireAds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expire ads: %w", classifyError(err))
+	}
+	defer rows.Close()
+
+	ads := make([]Ad, 0)
+	for rows.Next() {
+		var ad Ad
+		err := rows.Scan(
+			&ad.ID, &ad.Title, &ad.Text, &ad.ImageURL, &ad.Price,
+			&ad.UserID, &ad.Status, &ad.CreatedAt, &ad.Tags, &ad.City, &ad.ExpiresAt, &ad.Author,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan expired ad: %w", classifyError(err))
+		}
+		ads = append(ads, ad)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to expire ads: %w", classifyError(err))
+	}
+	return ads, nil
+}
@@ -0,0 +1,95 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdExpiry(t *testing.T) {
+	require.NoError(t, clearTables(testCtx, testDB))
+	seller, err := testDB.CreateUser(testCtx, "expiryseller", "pass")
+	require.NoError(t, err)
+	other, err := testDB.CreateUser(testCtx, "expiryother", "pass")
+	require.NoError(t, err)
+
+	create := func(title string, expiresAt time.Time) Ad {
+		ad, err := testDB.CreateAd(testCtx, Ad{Title: title, Text: "Text", Price: 100, UserID: seller.ID, ExpiresAt: expiresAt})
+		require.NoError(t, err)
+		return ad
+	}
+	fresh := create("Fresh", time.Time{})
+	stale := create("Stale", time.Now().Add(-time.Minute))
+
+	titles := func(ads []Ad) []string {
+		result := make([]string, 0, len(ads))
+		for _, ad := range ads {
+			result = append(result, ad.Title)
+		}
+		return result
+	}
+
+	t.Run("zero expiry defaults to the ad ttl", func(t *testing.T) {
+		assert.WithinDuration(t, time.Now().Add(DefaultAdTTL), fresh.ExpiresAt, time.Minute)
+	})
+
+	t.Run("expired ads are hidden from public listings", func(t *testing.T) {
+		filter := AdsFilter{MaxPrice: maxPrice}
+		ads, err := testDB.Ads(testCtx, other.ID, 1, 10, "title", "ASC", filter)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Fresh"}, titles(ads))
+
+		count, err := testDB.CountAds(testCtx, filter)
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+
+		ads, err = testDB.AdsByUser(testCtx, seller.ID, 1, 10, "title", "ASC")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Fresh", "Stale"}, titles(ads), "owner sees expired ads")
+
+		ad, err := testDB.Ad(testCtx, stale.ID, other.ID)
+		require.NoError(t, err)
+		assert.Equal(t, AdStatusActive, ad.Status)
+	})
+
+	t.Run("expire archives only overdue active ads", func(t *testing.T) {
+		expired, err := testDB.ExpireAds(testCtx)
+		require.NoError(t, err)
+		require.Len(t, expired, 1)
+		assert.Equal(t, stale.ID, expired[0].ID)
+		assert.Equal(t, AdStatusArchived, expired[0].Status)
+		assert.Equal(t, "expiryseller", expired[0].Author)
+
+		expired, err = testDB.ExpireAds(testCtx)
+		require.NoError(t, err)
+		assert.Empty(t, expired)
+	})
+
+	t.Run("renew reactivates an archived ad", func(t *testing.T) {
+		expiresAt := time.Now().Add(time.Hour)
+		ad, err := testDB.RenewAd(testCtx, stale.ID, seller.ID, expiresAt)
+		require.NoError(t, err)
+		assert.Equal(t, AdStatusActive, ad.Status)
+		assert.WithinDuration(t, expiresAt, ad.ExpiresAt, time.Millisecond)
+
+		ads, err := testDB.Ads(testCtx, other.ID, 1, 10, "title", "ASC", AdsFilter{MaxPrice: maxPrice})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Fresh", "Stale"}, titles(ads))
+	})
+
+	t.Run("renew errors", func(t *testing.T) {
+		_, err := testDB.RenewAd(testCtx, stale.ID, other.ID, time.Now().Add(time.Hour))
+		assert.ErrorIs(t, err, ErrNotAdOwner)
+
+		_, err = testDB.RenewAd(testCtx, 999999, seller.ID, time.Now().Add(time.Hour))
+		assert.ErrorIs(t, err, ErrAdNotFound)
+
+		_, err = testDB.SetAdStatus(testCtx, fresh.ID, seller.ID, AdStatusSold)
+		require.NoError(t, err)
+		_, err = testDB.RenewAd(testCtx, fresh.ID, seller.ID, time.Now().Add(time.Hour))
+		assert.ErrorIs(t, err, ErrAdNotRenewable)
+		assert.ErrorIs(t, err, ErrConflict)
+	})
+}
@@ -0,0 +1,48 @@
+package db
+
+import (
+	"context"
+	"fmt"
+)
+
+// StreamAds передаёт fn до limit объявлений по фильтрам и сортировке Ads по мере чтения строк,
+// не собирая результат в памяти. Ошибка fn прекращает чтение и возвращается как есть.
+// Запрос не повторяется при временных ошибках и не ограничивается таймаутом запросов:
+// часть объявлений к этому моменту уже передана, а длительность выгрузки ограничивает ctx.
+func (s *DBService) StreamAds(
+	ctx context.Context,
+	userID int,
+	limit int,
+	sortBy, sortOrder string,
+	filter AdsFilter,
+	fn func(Ad) error,
+) error {
+	if err := validateSort(sortBy, sortOrder); err != nil {
+		return err
+	}
+	where, args, err := adsConditions(filter, []any{userID, limit, 0})
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf(QueryGetAds, where, sortColumns[sortBy], sortOrder, sortOrder)
+	rows, err := s.reader().Query(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to query ads: %w", classifyError(err))
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		ad, err := scanAd(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(ad); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error during rows iteration: %w", classifyError(err))
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamAds(t *testing.T) {
+	require.NoError(t, clearTables(testCtx, testDB))
+	user, err := testDB.CreateUser(testCtx, "streamer", "pass")
+	require.NoError(t, err)
+
+	for i := 1; i <= 5; i++ {
+		_, err := testDB.CreateAd(testCtx, Ad{Title: fmt.Sprintf("Ad %d", i), Text: "text", Price: int64(i * 100), UserID: user.ID})
+		require.NoError(t, err)
+	}
+	filter := AdsFilter{MinPrice: 200, MaxPrice: maxPrice}
+
+	collect := func(limit int, filter AdsFilter) ([]Ad, error) {
+		var ads []Ad
+		err := testDB.StreamAds(testCtx, user.ID, limit, "price", "ASC", filter, func(ad Ad) error {
+			ads = append(ads, ad)
+			return nil
+		})
+		return ads, err
+	}
+
+	t.Run("same rows as Ads", func(t *testing.T) {
+		streamed, err := collect(10, filter)
+		require.NoError(t, err)
+		paged, err := testDB.Ads(testCtx, user.ID, 1, 10, "price", "ASC", filter)
+		require.NoError(t, err)
+		assert.Equal(t, paged, streamed)
+		require.Len(t, streamed, 4)
+		assert.True(t, streamed[0].IsMine)
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		streamed, err := collect(2, filter)
+		require.NoError(t, err)
+		assert.Len(t, streamed, 2)
+	})
+
+	t.Run("callback error stops reading", func(t *testing.T) {
+		errStop := errors.New("stop")
+		calls := 0
+		err := testDB.StreamAds(testCtx, user.ID, 10, "price", "ASC", filter, func(Ad) error {
+			calls++
+			return errStop
+		})
+		assert.ErrorIs(t, err, errStop)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("invalid filter", func(t *testing.T) {
+		_, err := collect(10, AdsFilter{MinPrice: 500, MaxPrice: 100})
+		assert.ErrorIs(t, err, ErrInvalidPriceRange)
+		err = testDB.StreamAds(testCtx, user.ID, 10, "author", "ASC", filter, func(Ad) error { return nil })
+		assert.ErrorIs(t, err, ErrInvalidSortBy)
+	})
+}
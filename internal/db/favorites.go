@@ -0,0 +1,103 @@
+package db
+
+import (
+	"context"
+	"fmt"
+)
+
+// AddFavorite добавляет объявление adID в избранное пользователя userID.
+// Повторное добавление ничего не меняет. Своё объявление добавить можно,
+// для отсутствующего или удалённого возвращается ErrAdNotFound.
+func (s *DBService) AddFavorite(ctx context.Context, userID, adID int) error {
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+
+	var exists bool
+	if err := s.pool.QueryRow(ctx, QueryAddFavorite, userID, adID).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to add favorite: %w", classifyError(err))
+	}
+	if !exists {
+		return ErrAdNotFound
+	}
+	return nil
+}
+
+// RemoveFavorite убирает объявление adID из избранного пользователя userID.
+// Удаление отсутствующей записи не считается ошибкой.
+func (s *DBService) RemoveFavorite(ctx context.Context, userID, adID int) error {
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+
+	if _, err := s.pool.Exec(ctx, QueryRemoveFavorite, userID, adID); err != nil {
+		return fmt.Errorf("failed to remove favorite: %w", classifyError(err))
+	}
+	return nil
+}
+
+// Favorites возвращает избранные объявления пользователя userID во всех статусах,
+// начиная с добавленных последними. Удалённые объявления пропускаются.
+func (s *DBService) Favorites(ctx context.Context, userID, page, size int) ([]Ad, error) {
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+
+	return s.readAds(ctx, QueryGetFavorites, []any{userID, size, (page - 1) * size})
+}
+
+// FavoriteAdIDs возвращает, какие из объявлений adIDs находятся в избранном пользователя userID.
+func (s *DBService) FavoriteAdIDs(ctx context.Context, userID int, adIDs []int) (map[int]bool, error) {
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+
+	favorites := make(map[int]bool)
+	if len(adIDs) == 0 {
+		return favorites, nil
+	}
+
+	rows, err := s.reader().Query(ctx, QueryGetFavoriteAdIDs, userID, adIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query favorites: %w", classifyError(err))
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var adID int
+		if err := rows.Scan(&adID); err != nil {
+			return nil, fmt.Errorf("failed to query favorites: %w", classifyError(err))
+		}
+		favorites[adID] = true
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during rows iteration: %w", classifyError(err))
+	}
+
+	return favorites, nil
+}
+
+// FavoritedBy возвращает ID пользователей, добавивших объявление adID в избранное.
+// Владелец объявления не включается.
+func (s *DBService) FavoritedBy(ctx context.Context, adID int) ([]int, error) {
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := s.pool.Query(ctx, QueryGetFavoritedBy, adID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query favorites: %w", classifyError(err))
+	}
+	defer rows.Close()
+
+	userIDs := make([]int, 0)
+	for rows.Next() {
+		var userID int
+		if err := rows.Scan(&userID); err != nil {
+			return nil, fmt.Errorf("failed to query favorites: %w", classifyError(err))
+		}
+		userIDs = append(userIDs, userID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during rows iteration: %w", classifyError(err))
+	}
+
+	return userIDs, nil
+}
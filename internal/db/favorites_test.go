@@ -0,0 +1,126 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFavorites(t *testing.T) {
+	require.NoError(t, clearTables(testCtx, testDB))
+	seller, err := testDB.CreateUser(testCtx, "favseller", "pass")
+	require.NoError(t, err)
+	buyer, err := testDB.CreateUser(testCtx, "favbuyer", "pass")
+	require.NoError(t, err)
+
+	create := func(title string) Ad {
+		ad, err := testDB.CreateAd(testCtx, Ad{Title: title, Text: "Text", Price: 100, UserID: seller.ID})
+		require.NoError(t, err)
+		return ad
+	}
+	bike := create("Bike")
+	sofa := create("Sofa")
+	lamp := create("Lamp")
+
+	titles := func(ads []Ad) []string {
+		result := make([]string, 0, len(ads))
+		for _, ad := range ads {
+			result = append(result, ad.Title)
+		}
+		return result
+	}
+
+	t.Run("adding twice is idempotent", func(t *testing.T) {
+		require.NoError(t, testDB.AddFavorite(testCtx, buyer.ID, bike.ID))
+		require.NoError(t, testDB.AddFavorite(testCtx, buyer.ID, bike.ID))
+
+		var count int
+		err := testDB.pool.QueryRow(testCtx, "SELECT COUNT(*) FROM favorites WHERE user_id = $1", buyer.ID).Scan(&count)
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("own ad can be favorited", func(t *testing.T) {
+		require.NoError(t, testDB.AddFavorite(testCtx, seller.ID, bike.ID))
+
+		ad, err := testDB.Ad(testCtx, bike.ID, seller.ID)
+		require.NoError(t, err)
+		assert.True(t, ad.IsMine)
+		assert.True(t, ad.IsFavorite)
+	})
+
+	t.Run("missing ad is not found", func(t *testing.T) {
+		err := testDB.AddFavorite(testCtx, buyer.ID, 999999)
+		assert.ErrorIs(t, err, ErrAdNotFound)
+	})
+
+	t.Run("is_favorite is set for the requesting user only", func(t *testing.T) {
+		ad, err := testDB.Ad(testCtx, bike.ID, buyer.ID)
+		require.NoError(t, err)
+		assert.True(t, ad.IsFavorite)
+
+		ad, err = testDB.Ad(testCtx, sofa.ID, buyer.ID)
+		require.NoError(t, err)
+		assert.False(t, ad.IsFavorite)
+
+		ads, err := testDB.Ads(testCtx, buyer.ID, 1, 10, "title", "ASC", AdsFilter{MaxPrice: 10000})
+		require.NoError(t, err)
+		require.Len(t, ads, 3)
+		assert.Equal(t, []bool{true, false, false}, []bool{ads[0].IsFavorite, ads[1].IsFavorite, ads[2].IsFavorite})
+
+		ads, err = testDB.AdsByIDs(testCtx, buyer.ID, []int{sofa.ID, bike.ID}, AdsFilter{MaxPrice: 10000})
+		require.NoError(t, err)
+		require.Len(t, ads, 2)
+		assert.False(t, ads[0].IsFavorite)
+		assert.True(t, ads[1].IsFavorite)
+
+		ads, err = testDB.Ads(testCtx, 0, 1, 10, "title", "ASC", AdsFilter{MaxPrice: 10000})
+		require.NoError(t, err)
+		for _, ad := range ads {
+			assert.False(t, ad.IsFavorite)
+		}
+	})
+
+	t.Run("favorites are listed newest first in any status", func(t *testing.T) {
+		require.NoError(t, testDB.AddFavorite(testCtx, buyer.ID, sofa.ID))
+		require.NoError(t, testDB.AddFavorite(testCtx, buyer.ID, lamp.ID))
+		_, err := testDB.SetAdStatus(testCtx, sofa.ID, seller.ID, AdStatusSold)
+		require.NoError(t, err)
+
+		ads, err := testDB.Favorites(testCtx, buyer.ID, 1, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Lamp", "Sofa", "Bike"}, titles(ads))
+		for _, ad := range ads {
+			assert.True(t, ad.IsFavorite)
+		}
+
+		ads, err = testDB.Favorites(testCtx, buyer.ID, 2, 2)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Bike"}, titles(ads))
+
+		favorites, err := testDB.FavoriteAdIDs(testCtx, buyer.ID, []int{bike.ID, sofa.ID, 999999})
+		require.NoError(t, err)
+		assert.Equal(t, map[int]bool{bike.ID: true, sofa.ID: true}, favorites)
+	})
+
+	t.Run("removing is idempotent", func(t *testing.T) {
+		require.NoError(t, testDB.RemoveFavorite(testCtx, buyer.ID, lamp.ID))
+		require.NoError(t, testDB.RemoveFavorite(testCtx, buyer.ID, lamp.ID))
+
+		ads, err := testDB.Favorites(testCtx, buyer.ID, 1, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Sofa", "Bike"}, titles(ads))
+	})
+
+	t.Run("deleted ad is hidden and cannot be favorited", func(t *testing.T) {
+		require.NoError(t, testDB.DeleteAd(testCtx, sofa.ID, seller.ID))
+
+		ads, err := testDB.Favorites(testCtx, buyer.ID, 1, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Bike"}, titles(ads))
+
+		err = testDB.AddFavorite(testCtx, seller.ID, sofa.ID)
+		assert.ErrorIs(t, err, ErrAdNotFound)
+	})
+}
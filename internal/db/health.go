@@ -0,0 +1,80 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// HealthCheckTimeout - сколько Health ждёт ответа базы данных
+const HealthCheckTimeout = time.Second
+
+// WithErrorObserver передаёт observe результат каждого SQL-запроса: ошибку или nil при успехе.
+// Используется для отслеживания доступности базы данных.
+func WithErrorObserver(observe func(error)) DBOption {
+	return func(cfg *dbConfig) {
+		cfg.pool.ConnConfig.Tracer = errorTracer{observe: observe}
+	}
+}
+
+type errorTracer struct {
+	observe func(error)
+}
+
+func (t errorTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, _ pgx.TraceQueryStartData) context.Context {
+	return ctx
+}
+
+func (t errorTracer) TraceQueryEnd(_ context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	t.observe(data.Err)
+}
+
+// Ping проверяет, что база данных принимает соединения
+func (s *DBService) Ping(ctx context.Context) error {
+	return s.pool.Ping(ctx)
+}
+
+// Health выполняет SELECT 1 с ограничением HealthCheckTimeout и возвращает ошибку, если база не ответила.
+// В отличие от Ping проверяет, что сервер выполняет запросы, а не только принимает соединения.
+func (s *DBService) Health(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, HealthCheckTimeout)
+	defer cancel()
+
+	var one int
+	if err := s.pool.QueryRow(ctx, QueryHealthCheck).Scan(&one); err != nil {
+		return fmt.Errorf("health check failed: %w", classifyError(err))
+	}
+	return nil
+}
+
+// IsUnavailable сообщает, что ошибка вызвана недоступностью базы данных
+// (ошибка соединения или сервер завершает работу), а не самим запросом.
+func IsUnavailable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, ErrUnavailable) {
+		return true
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		// 08xxx - ошибки соединения, 57P0x - сервер останавливается или ещё не готов
+		return strings.HasPrefix(pgErr.Code, "08") || strings.HasPrefix(pgErr.Code, "57P")
+	}
+
+	var connErr *pgconn.ConnectError
+	var netErr net.Error
+	return errors.As(err, &connErr) ||
+		errors.As(err, &netErr) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		pgconn.Timeout(err)
+}
@@ -0,0 +1,151 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ImageCollectBatch - сколько неиспользуемых изображений удаляется за один вызов CollectImages
+const ImageCollectBatch = 500
+
+// uploadedImageURL совпадает с адресами загруженных изображений, как выражение в запросах ссылок на изображения
+var uploadedImageURL = regexp.MustCompile(`/uploads/[A-Za-z0-9_.-]+$`)
+
+// RegisterImage учитывает загруженное изображение с хешем содержимого hash под именем name.
+// Повторная загрузка того же содержимого возвращает имя первой загрузки и created = false
+// и откладывает удаление изображения сборкой мусора. Одновременные загрузки одного содержимого
+// безопасны: запись создаётся одна благодаря уникальному hash.
+func (s *DBService) RegisterImage(ctx context.Context, hash, name string, size int) (stored string, created bool, err error) {
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+
+	if err := s.pool.QueryRow(ctx, QueryRegisterImage, hash, name, size).Scan(&stored, &created); err != nil {
+		return "", false, fmt.Errorf("failed to register image: %w", classifyError(err))
+	}
+	return stored, created, nil
+}
+
+// linkAdImage записывает ссылку объявления adID на загруженное изображение из его image_url.
+// Изображение, помеченное к удалению, снова считается используемым.
+func linkAdImage(ctx context.Context, tx pgx.Tx, adID int) error {
+	if _, err := tx.Exec(ctx, QueryLinkAdImage, adID); err != nil {
+		return fmt.Errorf("failed to link ad image: %w", classifyError(err))
+	}
+	return nil
+}
+
+// unlinkAdImage удаляет ссылку объявления adID на изображение
+func unlinkAdImage(ctx context.Context, tx pgx.Tx, adID int) error {
+	if _, err := tx.Exec(ctx, QueryUnlinkAdImage, adID); err != nil {
+		return fmt.Errorf("failed to unlink ad image: %w", classifyError(err))
+	}
+	return nil
+}
+
+// CollectImages удаляет изображения, на которые не ссылается ни одно объявление и которые
+// не загружались с unusedBefore, не больше ImageCollectBatch за вызов. Сначала изображения помечаются
+// удалёнными одним запросом, затем каждое удаляется в своей транзакции: запись блокируется, ссылки
+// проверяются повторно, вызывается remove с именем файла и только после этого удаляется запись.
+// Если remove или фиксация завершились ошибкой, изображение остаётся помеченным и удаляется
+// при следующем вызове; повторная загрузка или ссылка объявления снимает пометку.
+// Возвращает имена изображений, чьи записи удалены.
+func (s *DBService) CollectImages(ctx context.Context, unusedBefore time.Time, remove func(name string) error) ([]string, error) {
+	marked, err := s.markUnusedImages(ctx, unusedBefore)
+	if err != nil {
+		return nil, err
+	}
+
+	removed := make([]string, 0, len(marked))
+	for _, hash := range marked {
+		name, ok, err := s.deleteImage(ctx, hash, remove)
+		if err != nil {
+			return removed, err
+		}
+		if ok {
+			removed = append(removed, name)
+		}
+	}
+	return removed, nil
+}
+
+// markUnusedImages помечает неиспользуемые изображения удалёнными и возвращает хеши всех помеченных,
+// включая оставшиеся от прошлых вызовов
+func (s *DBService) markUnusedImages(ctx context.Context, unusedBefore time.Time) ([]string, error) {
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+
+	if _, err := s.pool.Exec(ctx, QueryMarkUnusedImages, unusedBefore.UTC(), ImageCollectBatch); err != nil {
+		return nil, fmt.Errorf("failed to mark unused images: %w", classifyError(err))
+	}
+
+	rows, err := s.pool.Query(ctx, QueryDeletedImages, ImageCollectBatch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query deleted images: %w", classifyError(err))
+	}
+	defer rows.Close()
+
+	var hashes []string
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			return nil, fmt.Errorf("failed to query deleted images: %w", classifyError(err))
+		}
+		hashes = append(hashes, hash)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during rows iteration: %w", classifyError(err))
+	}
+	return hashes, nil
+}
+
+// deleteImage удаляет помеченное изображение hash, если на него по-прежнему нет ссылок.
+// ok = false, если изображение уже удалено, обрабатывается другим вызовом или снова используется.
+func (s *DBService) deleteImage(ctx context.Context, hash string, remove func(name string) error) (name string, ok bool, err error) {
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to begin transaction: %w", classifyError(err))
+	}
+	defer tx.Rollback(ctx)
+
+	// Блокировка не даёт загрузке того же содержимого и ссылкам объявлений изменить запись,
+	// пока удаляется файл
+	if err := tx.QueryRow(ctx, QueryLockDeletedImage, hash).Scan(&name); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", false, nil
+		}
+		return "", false, fmt.Errorf("failed to lock image: %w", classifyError(err))
+	}
+
+	var referenced bool
+	if err := tx.QueryRow(ctx, QueryImageReferenced, hash).Scan(&referenced); err != nil {
+		return "", false, fmt.Errorf("failed to count image references: %w", classifyError(err))
+	}
+	if referenced {
+		if _, err := tx.Exec(ctx, QueryRestoreImage, hash); err != nil {
+			return "", false, fmt.Errorf("failed to restore image: %w", classifyError(err))
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return "", false, fmt.Errorf("failed to commit transaction: %w", classifyError(err))
+		}
+		return "", false, nil
+	}
+
+	if err := remove(name); err != nil {
+		return "", false, fmt.Errorf("failed to remove image %s: %w", name, err)
+	}
+	if _, err := tx.Exec(ctx, QueryDeleteImage, hash); err != nil {
+		return "", false, fmt.Errorf("failed to delete image: %w", classifyError(err))
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return "", false, fmt.Errorf("failed to commit transaction: %w", classifyError(err))
+	}
+	return name, true, nil
+}
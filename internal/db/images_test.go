@@ -0,0 +1,189 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImages(t *testing.T) {
+	require.NoError(t, clearTables(testCtx, testDB))
+	_, err := testDB.pool.Exec(testCtx, "TRUNCATE TABLE images CASCADE")
+	require.NoError(t, err)
+
+	seller, err := testDB.CreateUser(testCtx, "imgseller", "pass")
+	require.NoError(t, err)
+
+	hash := func(i int) string { return fmt.Sprintf("%064x", i) }
+	count := func(t *testing.T) int {
+		var n int
+		require.NoError(t, testDB.pool.QueryRow(testCtx, "SELECT COUNT(*) FROM images").Scan(&n))
+		return n
+	}
+	refs := func(t *testing.T, hash string) int {
+		var n int
+		require.NoError(t, testDB.pool.QueryRow(testCtx, "SELECT COUNT(*) FROM ad_images WHERE hash = $1", hash).Scan(&n))
+		return n
+	}
+	var removed []string
+	remove := func(name string) error {
+		removed = append(removed, name)
+		return nil
+	}
+	later := func() time.Time { return time.Now().Add(time.Hour) }
+
+	t.Run("identical uploads are registered once", func(t *testing.T) {
+		name, created, err := testDB.RegisterImage(testCtx, hash(1), "first.png", 100)
+		require.NoError(t, err)
+		assert.True(t, created)
+		assert.Equal(t, "first.png", name)
+
+		name, created, err = testDB.RegisterImage(testCtx, hash(1), "first.png", 100)
+		require.NoError(t, err)
+		assert.False(t, created)
+		assert.Equal(t, "first.png", name)
+		assert.Equal(t, 1, count(t))
+	})
+
+	t.Run("concurrent identical uploads create one record", func(t *testing.T) {
+		var (
+			wg      sync.WaitGroup
+			mu      sync.Mutex
+			created int
+		)
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, ok, err := testDB.RegisterImage(testCtx, hash(2), "second.png", 200)
+				assert.NoError(t, err)
+				mu.Lock()
+				defer mu.Unlock()
+				if ok {
+					created++
+				}
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, 1, created)
+		assert.Equal(t, 2, count(t))
+	})
+
+	t.Run("recent images are kept", func(t *testing.T) {
+		removed = nil
+		names, err := testDB.CollectImages(testCtx, time.Now().Add(-time.Hour), remove)
+		require.NoError(t, err)
+		assert.Empty(t, names)
+		assert.Empty(t, removed)
+	})
+
+	t.Run("image is collected after its last reference is gone", func(t *testing.T) {
+		first, err := testDB.CreateAd(testCtx, Ad{Title: "Bike", Text: "Text", ImageURL: "https://market.example/uploads/first.png", Price: 100, UserID: seller.ID})
+		require.NoError(t, err)
+		second, err := testDB.CreateAd(testCtx, Ad{Title: "Bike 2", Text: "Text", ImageURL: "https://market.example/uploads/first.png", Price: 100, UserID: seller.ID})
+		require.NoError(t, err)
+		assert.Equal(t, 2, refs(t, hash(1)))
+
+		removed = nil
+		names, err := testDB.CollectImages(testCtx, later(), remove)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"second.png"}, names, "unreferenced image is collected")
+		assert.Equal(t, []string{"second.png"}, removed)
+
+		require.NoError(t, testDB.DeleteAd(testCtx, first.ID, seller.ID))
+		assert.Equal(t, 1, refs(t, hash(1)))
+		names, err = testDB.CollectImages(testCtx, later(), remove)
+		require.NoError(t, err)
+		assert.Empty(t, names, "image is still referenced by the second ad")
+
+		require.NoError(t, testDB.DeleteAd(testCtx, second.ID, seller.ID))
+		assert.Equal(t, 0, refs(t, hash(1)))
+		names, err = testDB.CollectImages(testCtx, later(), remove)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"first.png"}, names)
+		assert.Equal(t, 0, count(t))
+	})
+
+	t.Run("references follow image_url changes", func(t *testing.T) {
+		_, _, err := testDB.RegisterImage(testCtx, hash(4), "fourth.png", 400)
+		require.NoError(t, err)
+		_, _, err = testDB.RegisterImage(testCtx, hash(5), "fifth.png", 500)
+		require.NoError(t, err)
+
+		ad, err := testDB.CreateAd(testCtx, Ad{Title: "Sofa", Text: "Text", ImageURL: "https://market.example/uploads/fourth.png", Price: 100, UserID: seller.ID})
+		require.NoError(t, err)
+
+		url := "https://market.example/uploads/fifth.png"
+		_, err = testDB.UpdateAd(testCtx, ad.ID, seller.ID, AdUpdate{ImageURL: &url})
+		require.NoError(t, err)
+		assert.Equal(t, 0, refs(t, hash(4)))
+		assert.Equal(t, 1, refs(t, hash(5)))
+
+		url = "https://cdn.example/sofa.png"
+		_, err = testDB.UpdateAd(testCtx, ad.ID, seller.ID, AdUpdate{ImageURL: &url})
+		require.NoError(t, err)
+		assert.Equal(t, 0, refs(t, hash(5)))
+
+		names, err := testDB.CollectImages(testCtx, later(), remove)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"fourth.png", "fifth.png"}, names)
+	})
+
+	t.Run("batch and moderated ads keep references", func(t *testing.T) {
+		_, _, err := testDB.RegisterImage(testCtx, hash(6), "sixth.png", 600)
+		require.NoError(t, err)
+
+		inserted, err := testDB.CreateAdsBatch(testCtx, []Ad{
+			{Title: "Lamp", Text: "Text", ImageURL: "https://market.example/uploads/sixth.png", Price: 100, UserID: seller.ID},
+			{Title: "Lamp 2", Text: "Text", ImageURL: "https://cdn.example/lamp.png", Price: 100, UserID: seller.ID},
+		})
+		require.NoError(t, err)
+		require.EqualValues(t, 2, inserted)
+		assert.Equal(t, 1, refs(t, hash(6)))
+
+		var adID int
+		require.NoError(t, testDB.pool.QueryRow(testCtx, "SELECT ad_id FROM ad_images WHERE hash = $1", hash(6)).Scan(&adID))
+		require.NoError(t, testDB.RemoveAd(testCtx, adID, seller.ID))
+		assert.Equal(t, 0, refs(t, hash(6)))
+
+		names, err := testDB.CollectImages(testCtx, later(), remove)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"sixth.png"}, names)
+	})
+
+	t.Run("failed removal keeps the record until a later run", func(t *testing.T) {
+		_, _, err := testDB.RegisterImage(testCtx, hash(3), "third.png", 300)
+		require.NoError(t, err)
+
+		_, err = testDB.CollectImages(testCtx, later(), func(string) error { return errors.New("disk failure") })
+		require.Error(t, err)
+		assert.Equal(t, 1, count(t))
+
+		removed = nil
+		names, err := testDB.CollectImages(testCtx, later(), remove)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"third.png"}, names)
+		assert.Equal(t, 0, count(t))
+	})
+
+	t.Run("new reference keeps a marked image", func(t *testing.T) {
+		_, _, err := testDB.RegisterImage(testCtx, hash(7), "seventh.png", 700)
+		require.NoError(t, err)
+
+		_, err = testDB.CollectImages(testCtx, later(), func(string) error { return errors.New("disk failure") })
+		require.Error(t, err)
+		_, err = testDB.CreateAd(testCtx, Ad{Title: "Chair", Text: "Text", ImageURL: "https://market.example/uploads/seventh.png", Price: 100, UserID: seller.ID})
+		require.NoError(t, err)
+		assert.Equal(t, 1, refs(t, hash(7)))
+
+		names, err := testDB.CollectImages(testCtx, later(), remove)
+		require.NoError(t, err)
+		assert.Empty(t, names)
+		assert.Equal(t, 1, count(t))
+	})
+}
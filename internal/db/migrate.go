@@ -0,0 +1,143 @@
+package db
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// migrationLockID - ключ advisory-блокировки, под которой применяются миграции.
+// Экземпляры, запущенные одновременно, применяют миграции по очереди.
+const migrationLockID = 7_246_013_518
+
+const (
+	queryCreateSchemaMigrations = `
+        CREATE TABLE IF NOT EXISTS schema_migrations (
+            version INTEGER PRIMARY KEY,
+            name VARCHAR(255) NOT NULL,
+            applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+        )
+    `
+	queryAppliedMigrations = `SELECT version FROM schema_migrations`
+	queryRecordMigration   = `INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migration - SQL-файл migrations/<версия>_<название>.sql
+type migration struct {
+	version int
+	name    string
+	sql     string
+}
+
+// loadMigrations читает встроенные миграции, упорядоченные по версии
+func loadMigrations() ([]migration, error) {
+	entries, err := migrationFiles.ReadDir("migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := make([]migration, 0, len(entries))
+	seen := make(map[int]string, len(entries))
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), ".sql")
+		prefix, _, _ := strings.Cut(name, "_")
+		version, err := strconv.Atoi(prefix)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("invalid migration file name %q: expected <version>_<name>.sql", entry.Name())
+		}
+		if prev, ok := seen[version]; ok {
+			return nil, fmt.Errorf("duplicate migration version %d: %s and %s", version, prev, entry.Name())
+		}
+		seen[version] = entry.Name()
+
+		sql, err := migrationFiles.ReadFile(path.Join("migrations", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, migration{version: version, name: name, sql: string(sql)})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].version < migrations[j].version })
+	return migrations, nil
+}
+
+// Migrate применяет ещё не применённые миграции схемы по возрастанию версии и записывает их
+// в schema_migrations. Каждая миграция выполняется в отдельной транзакции; повторный вызов ничего не меняет.
+func (s *DBService) Migrate(ctx context.Context) error {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return fmt.Errorf("failed to load migrations: %w", err)
+	}
+
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection: %w", classifyError(err))
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, "SELECT pg_advisory_lock($1)", migrationLockID); err != nil {
+		return fmt.Errorf("failed to lock migrations: %w", classifyError(err))
+	}
+	defer conn.Exec(context.WithoutCancel(ctx), "SELECT pg_advisory_unlock($1)", migrationLockID)
+
+	if _, err := conn.Exec(ctx, queryCreateSchemaMigrations); err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", classifyError(err))
+	}
+
+	rows, err := conn.Query(ctx, queryAppliedMigrations)
+	if err != nil {
+		return fmt.Errorf("failed to read applied migrations: %w", classifyError(err))
+	}
+	applied := make(map[int]bool)
+	for rows.Next() {
+		var version int
+		if err := rows.Scan(&version); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to read applied migrations: %w", err)
+		}
+		applied[version] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read applied migrations: %w", classifyError(err))
+	}
+
+	for _, m := range migrations {
+		if applied[m.version] {
+			continue
+		}
+		if err := applyMigration(ctx, conn.Conn(), m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyMigration выполняет миграцию m и запись о ней в одной транзакции
+func applyMigration(ctx context.Context, conn *pgx.Conn, m migration) error {
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin migration %s: %w", m.name, classifyError(err))
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, m.sql); err != nil {
+		return fmt.Errorf("failed to apply migration %s: %w", m.name, classifyError(err))
+	}
+	if _, err := tx.Exec(ctx, queryRecordMigration, m.version, m.name); err != nil {
+		return fmt.Errorf("failed to record migration %s: %w", m.name, classifyError(err))
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit migration %s: %w", m.name, classifyError(err))
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package db
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	migrations, err := loadMigrations()
+	require.NoError(t, err)
+	require.NotEmpty(t, migrations)
+	assert.Equal(t, 1, migrations[0].version)
+	for i := 1; i < len(migrations); i++ {
+		assert.Greater(t, migrations[i].version, migrations[i-1].version)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	container, err := postgres.Run(testCtx,
+		"postgres:15-alpine",
+		postgres.WithDatabase("migratedb"),
+		postgres.WithUsername("testuser"),
+		postgres.WithPassword("testpass"),
+		testcontainers.WithWaitStrategy(
+			wait.ForLog("database system is ready to accept connections").
+				WithOccurrence(2).
+				WithStartupTimeout(10*time.Second),
+		),
+	)
+	require.NoError(t, err)
+	defer func() { _ = container.Terminate(testCtx) }()
+
+	dsn, err := container.ConnectionString(testCtx, "sslmode=disable")
+	require.NoError(t, err)
+
+	migrations, err := loadMigrations()
+	require.NoError(t, err)
+
+	appliedVersions := func(t *testing.T, s *DBService) []int {
+		t.Helper()
+		rows, err := s.pool.Query(testCtx, "SELECT version FROM schema_migrations ORDER BY version")
+		require.NoError(t, err)
+		defer rows.Close()
+		var versions []int
+		for rows.Next() {
+			var v int
+			require.NoError(t, rows.Scan(&v))
+			versions = append(versions, v)
+		}
+		require.NoError(t, rows.Err())
+		return versions
+	}
+
+	t.Run("skip migrations leaves the database empty", func(t *testing.T) {
+		s, err := NewDBService(testCtx, dsn, WithSkipMigrations())
+		require.NoError(t, err)
+		defer s.Close()
+
+		var exists bool
+		err = s.pool.QueryRow(testCtx, "SELECT to_regclass('users') IS NOT NULL").Scan(&exists)
+		require.NoError(t, err)
+		assert.False(t, exists)
+	})
+
+	t.Run("concurrent starts apply every migration once", func(t *testing.T) {
+		const instances = 4
+		services := make([]*DBService, instances)
+		errs := make([]error, instances)
+		var wg sync.WaitGroup
+		for i := 0; i < instances; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				services[i], errs[i] = NewDBService(testCtx, dsn)
+			}(i)
+		}
+		wg.Wait()
+		for i := 0; i < instances; i++ {
+			require.NoError(t, errs[i])
+			defer services[i].Close()
+		}
+
+		expected := make([]int, 0, len(migrations))
+		for _, m := range migrations {
+			expected = append(expected, m.version)
+		}
+		assert.Equal(t, expected, appliedVersions(t, services[0]))
+
+		_, err := services[0].CreateUser(testCtx, "migrated", "pass")
+		assert.NoError(t, err)
+	})
+
+	t.Run("repeated migrate is a no-op", func(t *testing.T) {
+		s, err := NewDBService(testCtx, dsn, WithSkipMigrations())
+		require.NoError(t, err)
+		defer s.Close()
+
+		before := appliedVersions(t, s)
+		require.NoError(t, s.Migrate(testCtx))
+		assert.Equal(t, before, appliedVersions(t, s))
+	})
+}
@@ -0,0 +1,4 @@
+-- Теги объявлений: значения уже нормализованы (нижний регистр, без повторов).
+-- Фильтр по тегам (tags @> ...) использует GIN-индекс.
+ALTER TABLE ads ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+CREATE INDEX IF NOT EXISTS idx_ads_tags ON ads USING GIN (tags);
@@ -0,0 +1,4 @@
+-- Город объявления: хранится без крайних пробелов, пустая строка - город не указан.
+-- Фильтр сравнивает lower(city), поэтому индекс построен по выражению.
+ALTER TABLE ads ADD COLUMN IF NOT EXISTS city VARCHAR(100) NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS idx_ads_city ON ads (lower(city));
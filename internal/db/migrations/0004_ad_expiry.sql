@@ -0,0 +1,8 @@
+-- Срок публикации объявления: активное объявление с истёкшим expires_at не показывается в списках
+-- и переводится в archived фоновой задачей. Существующим объявлениям срок отсчитывается от применения
+-- миграции со сроком по умолчанию (30 дней, как DefaultAdTTL), чтобы после обновления они не архивировались разом.
+-- Новым объявлениям срок задаёт приложение по AD_TTL, поэтому значения по умолчанию у столбца нет.
+ALTER TABLE ads ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP;
+UPDATE ads SET expires_at = CURRENT_TIMESTAMP + INTERVAL '30 days' WHERE expires_at IS NULL;
+ALTER TABLE ads ALTER COLUMN expires_at SET NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_ads_active_expires_at ON ads (expires_at) WHERE status = 'active' AND deleted_at IS NULL;
@@ -0,0 +1,20 @@
+-- Загруженные изображения хранятся один раз под именем из SHA-256 содержимого.
+-- ad_images - ссылки объявлений на изображения: строка добавляется при создании объявления
+-- с адресом загруженного изображения и удаляется при смене адреса или удалении объявления.
+-- Фоновая задача помечает изображения без ссылок, не загружавшиеся дольше срока ожидания, удалёнными
+-- (deleted_at), затем удаляет их файлы и записи.
+CREATE TABLE IF NOT EXISTS images (
+    hash CHAR(64) PRIMARY KEY,
+    name VARCHAR(128) NOT NULL UNIQUE,
+    size BIGINT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP
+);
+CREATE TABLE IF NOT EXISTS ad_images (
+    ad_id INTEGER PRIMARY KEY REFERENCES ads(id) ON DELETE CASCADE,
+    hash CHAR(64) NOT NULL REFERENCES images(hash)
+);
+CREATE INDEX IF NOT EXISTS idx_ad_images_hash ON ad_images (hash);
+CREATE INDEX IF NOT EXISTS idx_images_updated_at ON images (updated_at) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_images_deleted_at ON images (deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,265 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// NotificationTypeAdStatusChanged - статус объявления пользователя изменился
+	NotificationTypeAdStatusChanged = "ad_status_changed"
+	// NotificationTypePriceDrop - цена объявления из избранного снизилась
+	NotificationTypePriceDrop = "price_drop"
+
+	ErrMsgNotificationNotFound    = "уведомление не найдено"
+	ErrMsgUnknownNotificationType = "неизвестный тип уведомления"
+)
+
+var (
+	ErrNotificationNotFound    = newKindError(ErrNotFound, ErrMsgNotificationNotFound)
+	ErrUnknownNotificationType = newKindError(ErrInvalid, ErrMsgUnknownNotificationType)
+)
+
+// NotificationTypes перечисляет известные типы уведомлений.
+// Настройки пользователя могут содержать только эти типы.
+var NotificationTypes = []string{
+	NotificationTypeAdStatusChanged,
+	NotificationTypePriceDrop,
+}
+
+// Notification представляет уведомление в ленте пользователя.
+type Notification struct {
+	ID        int             `json:"id"`
+	UserID    int             `json:"-"`
+	Type      string          `json:"type"`
+	Payload   json.RawMessage `json:"payload"`
+	ReadAt    *time.Time      `json:"read_at,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// PriceDropPayload - данные уведомления о снижении цены.
+// OldPrice - цена до первого снижения в окне дедупликации, NewPrice - текущая цена.
+type PriceDropPayload struct {
+	AdID     int    `json:"ad_id"`
+	Title    string `json:"title"`
+	OldPrice int64  `json:"old_price"`
+	NewPrice int64  `json:"new_price"`
+}
+
+// Preferences описывает, какие типы уведомлений пользователь хочет получать.
+// Отсутствующий тип считается включённым.
+type Preferences map[string]bool
+
+// Enabled сообщает, включены ли уведомления типа notificationType
+func (p Preferences) Enabled(notificationType string) bool {
+	enabled, ok := p[notificationType]
+	return !ok || enabled
+}
+
+// CreateNotification создаёт уведомление пользователя userID, если этот тип не отключён в его настройках.
+// Возвращает false, если уведомление не создано из-за настроек.
+func (s *DBService) CreateNotification(ctx context.Context, userID int, notificationType string, payload any) (Notification, bool, error) {
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+
+	if err := validateNotificationType(notificationType); err != nil {
+		return Notification{}, false, err
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return Notification{}, false, fmt.Errorf("failed to marshal notification payload: %w", err)
+	}
+
+	n, err := scanNotification(s.pool.QueryRow(ctx, QueryCreateNotification, userID, notificationType, data))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Notification{}, false, nil
+		}
+		return Notification{}, false, fmt.Errorf("failed to create notification: %w", classifyError(err))
+	}
+	return n, true, nil
+}
+
+// NotifyPriceDrop создаёт уведомление о снижении цены или, если с since уже было уведомление
+// по этому объявлению, обновляет в нём текущую цену и снова помечает непрочитанным.
+// Возвращает false, если уведомление не создано из-за настроек пользователя.
+func (s *DBService) NotifyPriceDrop(ctx context.Context, userID int, drop PriceDropPayload, since time.Time) (Notification, bool, error) {
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return Notification{}, false, fmt.Errorf("failed to begin transaction: %w", classifyError(err))
+	}
+	defer tx.Rollback(ctx)
+
+	var n Notification
+	existing, err := scanNotification(tx.QueryRow(ctx, QueryLockRecentPriceDrop, userID, drop.AdID, since.UTC()))
+	switch {
+	case err == nil:
+		var prev PriceDropPayload
+		if err := json.Unmarshal(existing.Payload, &prev); err != nil {
+			return Notification{}, false, fmt.Errorf("failed to unmarshal notification payload: %w", err)
+		}
+		drop.OldPrice = prev.OldPrice
+		data, err := json.Marshal(drop)
+		if err != nil {
+			return Notification{}, false, fmt.Errorf("failed to marshal notification payload: %w", err)
+		}
+		n, err = scanNotification(tx.QueryRow(ctx, QueryRefreshNotification, existing.ID, data))
+		if err != nil {
+			return Notification{}, false, fmt.Errorf("failed to update notification: %w", classifyError(err))
+		}
+	case errors.Is(err, pgx.ErrNoRows):
+		data, err := json.Marshal(drop)
+		if err != nil {
+			return Notification{}, false, fmt.Errorf("failed to marshal notification payload: %w", err)
+		}
+		n, err = scanNotification(tx.QueryRow(ctx, QueryCreateNotification, userID, NotificationTypePriceDrop, data))
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Notification{}, false, nil
+		}
+		if err != nil {
+			return Notification{}, false, fmt.Errorf("failed to create notification: %w", classifyError(err))
+		}
+	default:
+		return Notification{}, false, fmt.Errorf("failed to get notification: %w", classifyError(err))
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return Notification{}, false, fmt.Errorf("failed to commit transaction: %w", classifyError(err))
+	}
+	return n, true, nil
+}
+
+// Notifications возвращает уведомления пользователя: сначала непрочитанные, затем по убыванию даты.
+func (s *DBService) Notifications(ctx context.Context, userID, page, size int) ([]Notification, error) {
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+
+	offset := (page - 1) * size
+	rows, err := s.pool.Query(ctx, QueryGetNotifications, userID, size, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query notifications: %w", classifyError(err))
+	}
+	defer rows.Close()
+
+	notifications := make([]Notification, 0)
+	for rows.Next() {
+		n, err := scanNotification(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query notifications: %w", classifyError(err))
+		}
+		notifications = append(notifications, n)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during rows iteration: %w", classifyError(err))
+	}
+
+	return notifications, nil
+}
+
+// MarkNotificationRead отмечает уведомление id пользователя userID прочитанным.
+// Повторная отметка не меняет время прочтения.
+func (s *DBService) MarkNotificationRead(ctx context.Context, userID, id int) (Notification, error) {
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+
+	n, err := scanNotification(s.pool.QueryRow(ctx, QueryMarkNotificationRead, id, userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Notification{}, ErrNotificationNotFound
+		}
+		return Notification{}, fmt.Errorf("failed to mark notification read: %w", classifyError(err))
+	}
+	return n, nil
+}
+
+// MarkAllNotificationsRead отмечает прочитанными все уведомления пользователя и возвращает их количество
+func (s *DBService) MarkAllNotificationsRead(ctx context.Context, userID int) (int64, error) {
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+
+	tag, err := s.pool.Exec(ctx, QueryMarkAllNotificationsRead, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark notifications read: %w", classifyError(err))
+	}
+	return tag.RowsAffected(), nil
+}
+
+// DeleteReadNotifications удаляет уведомления, прочитанные раньше before
+func (s *DBService) DeleteReadNotifications(ctx context.Context, before time.Time) (int64, error) {
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+
+	tag, err := s.pool.Exec(ctx, QueryDeleteReadNotifications, before.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete read notifications: %w", classifyError(err))
+	}
+	return tag.RowsAffected(), nil
+}
+
+// UserPreferences возвращает настройки уведомлений пользователя
+func (s *DBService) UserPreferences(ctx context.Context, userID int) (Preferences, error) {
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+
+	var prefs Preferences
+	if err := s.pool.QueryRow(ctx, QueryGetUserPreferences, userID).Scan(&prefs); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get preferences: %w", classifyError(err))
+	}
+	return prefs, nil
+}
+
+// UpdateUserPreferences объединяет переданные настройки с текущими и возвращает результат
+func (s *DBService) UpdateUserPreferences(ctx context.Context, userID int, prefs Preferences) (Preferences, error) {
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+
+	for notificationType := range prefs {
+		if err := validateNotificationType(notificationType); err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := json.Marshal(prefs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal preferences: %w", err)
+	}
+
+	var updated Preferences
+	if err := s.pool.QueryRow(ctx, QueryUpdateUserPreferences, data, userID).Scan(&updated); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to update preferences: %w", classifyError(err))
+	}
+	return updated, nil
+}
+
+// scanNotification считывает уведомление из строки результата.
+func scanNotification(row pgx.Row) (Notification, error) {
+	var n Notification
+	err := row.Scan(&n.ID, &n.UserID, &n.Type, &n.Payload, &n.ReadAt, &n.CreatedAt)
+	return n, err
+}
+
+// validateNotificationType проверяет, что тип уведомления известен
+func validateNotificationType(notificationType string) error {
+	for _, t := range NotificationTypes {
+		if t == notificationType {
+			return nil
+		}
+	}
+	return ErrUnknownNotificationType
+}
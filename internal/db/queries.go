@@ -4,58 +4,635 @@ const (
 	QueryCreateUser = `
         INSERT INTO users (login, password)
         VALUES ($1, $2)
-        RETURNING id, login, created_at
+        RETURNING id, login, created_at, role
     `
 
 	QueryGetUserByLogin = `
-		SELECT id, login, password, created_at
+		SELECT id, login, password, created_at, role
 		FROM users
 		WHERE login = $1
 	`
 
 	QueryCreateAd = `
-    INSERT INTO ads (title, text, image_url, price, user_id)
-    VALUES ($1, $2, $3, $4, $5)
-    RETURNING id, title, text, image_url, price, user_id, created_at,
+    INSERT INTO ads (title, text, image_url, price, user_id, tags, city, expires_at)
+    VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+    RETURNING id, title, text, image_url, price, user_id, status, created_at, tags, city, expires_at,
               (SELECT login FROM users WHERE id = $5) AS login,
               CASE WHEN user_id = $5 THEN true ELSE false END AS is_mine
 	`
 
 	QueryGetAds = `
-        SELECT a.id, a.title, a.text, a.image_url, a.price, a.user_id, a.created_at,
+        SELECT a.id, a.title, a.text, a.image_url, a.price, a.user_id, a.status, a.created_at, a.tags, a.city, a.expires_at,
                u.login,
-               CASE WHEN a.user_id = $1 THEN true ELSE false END AS is_mine
+               CASE WHEN a.user_id = $1 THEN true ELSE false END AS is_mine,
+               EXISTS (
+                   SELECT 1 FROM reservations r
+                   WHERE r.ad_id = a.id AND r.status = 'active' AND r.expires_at > CURRENT_TIMESTAMP
+               ) AS reserved,
+               f.user_id IS NOT NULL AS is_favorite
         FROM ads a
         JOIN users u ON a.user_id = u.id
-        WHERE a.price >= $2 AND a.price <= $3
-        ORDER BY a.%s %s
-        LIMIT $4 OFFSET $5
+        LEFT JOIN favorites f ON f.ad_id = a.id AND f.user_id = $1
+        WHERE %s
+        ORDER BY %s %s, a.id %s
+        LIMIT $2 OFFSET $3
+    `
+
+	QueryCountAds = `
+        SELECT COUNT(*)
+        FROM ads a
+        WHERE %s
+    `
+
+	QuerySearchAds = `
+        SELECT a.id, COUNT(*) OVER () AS total
+        FROM ads a
+        WHERE to_tsvector('simple', a.title || ' ' || a.text) @@ plainto_tsquery('simple', $1)
+          AND %s
+        ORDER BY ts_rank(
+                     setweight(to_tsvector('simple', a.title), 'A') || setweight(to_tsvector('simple', a.text), 'B'),
+                     plainto_tsquery('simple', $1)
+                 ) DESC, a.id ASC
+        LIMIT $2 OFFSET $3
+    `
+
+	QueryCountSearchAds = `
+        SELECT COUNT(*)
+        FROM ads a
+        WHERE to_tsvector('simple', a.title || ' ' || a.text) @@ plainto_tsquery('simple', $1)
+          AND %s
+    `
+
+	QueryGetAdsByIDs = `
+        SELECT a.id, a.title, a.text, a.image_url, a.price, a.user_id, a.status, a.created_at, a.tags, a.city, a.expires_at,
+               u.login,
+               CASE WHEN a.user_id = $1 THEN true ELSE false END AS is_mine,
+               EXISTS (
+                   SELECT 1 FROM reservations r
+                   WHERE r.ad_id = a.id AND r.status = 'active' AND r.expires_at > CURRENT_TIMESTAMP
+               ) AS reserved,
+               f.user_id IS NOT NULL AS is_favorite
+        FROM ads a
+        JOIN users u ON a.user_id = u.id
+        LEFT JOIN favorites f ON f.ad_id = a.id AND f.user_id = $1
+        WHERE a.id = ANY($2::int[])
+          AND %s
+        ORDER BY array_position($2::int[], a.id)
+    `
+
+	QueryGetAd = `
+        SELECT a.id, a.title, a.text, a.image_url, a.price, a.user_id, a.status, a.created_at, a.tags, a.city, a.expires_at,
+               u.login,
+               CASE WHEN a.user_id = $2 THEN true ELSE false END AS is_mine,
+               EXISTS (
+                   SELECT 1 FROM reservations r
+                   WHERE r.ad_id = a.id AND r.status = 'active' AND r.expires_at > CURRENT_TIMESTAMP
+               ) AS reserved,
+               f.user_id IS NOT NULL AS is_favorite
+        FROM ads a
+        JOIN users u ON a.user_id = u.id
+        LEFT JOIN favorites f ON f.ad_id = a.id AND f.user_id = $2
+        WHERE a.id = $1
+          AND a.deleted_at IS NULL
+    `
+
+	QueryLockAdOwner = `
+        SELECT user_id
+        FROM ads
+        WHERE id = $1 AND deleted_at IS NULL
+        FOR UPDATE
+    `
+
+	QuerySoftDeleteAd = `
+        UPDATE ads
+        SET deleted_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+        WHERE id = $1
+    `
+
+	QueryRemoveAd = `
+        UPDATE ads
+        SET deleted_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP, deleted_by = $2
+        WHERE id = $1 AND deleted_at IS NULL
+    `
+
+	QueryLockAdForReservation = `
+        SELECT user_id, status
+        FROM ads
+        WHERE id = $1 AND deleted_at IS NULL
+        FOR UPDATE
+    `
+
+	QueryExpireAdReservations = `
+        UPDATE reservations
+        SET status = 'expired', updated_at = CURRENT_TIMESTAMP
+        WHERE ad_id = $1 AND status = 'active' AND expires_at <= CURRENT_TIMESTAMP
+    `
+
+	QueryExpireReservations = `
+        UPDATE reservations
+        SET status = 'expired', updated_at = CURRENT_TIMESTAMP
+        WHERE status = 'active' AND expires_at <= CURRENT_TIMESTAMP
+    `
+
+	QueryGetActiveReservation = `
+        SELECT id, ad_id, buyer_id, status, expires_at, created_at, updated_at
+        FROM reservations
+        WHERE ad_id = $1 AND status = 'active'
+        FOR UPDATE
+    `
+
+	QueryGetReservation = `
+        SELECT id, ad_id, buyer_id, status, expires_at, created_at, updated_at
+        FROM reservations
+        WHERE id = $1 AND ad_id = $2
+        FOR UPDATE
+    `
+
+	QueryCreateReservation = `
+        INSERT INTO reservations (ad_id, buyer_id, expires_at)
+        VALUES ($1, $2, $3)
+        RETURNING id, ad_id, buyer_id, status, expires_at, created_at, updated_at
+    `
+
+	QuerySetReservationStatus = `
+        UPDATE reservations
+        SET status = $2, updated_at = CURRENT_TIMESTAMP
+        WHERE id = $1
+        RETURNING id, ad_id, buyer_id, status, expires_at, created_at, updated_at
+    `
+
+	QueryMarkAdSold = `
+        UPDATE ads
+        SET status = 'sold', buyer_id = $2, updated_at = CURRENT_TIMESTAMP
+        WHERE id = $1
+    `
+
+	QueryCountSitemapAds = `
+        SELECT COUNT(*)
+        FROM ads
+        WHERE status = 'active' AND deleted_at IS NULL AND expires_at > CURRENT_TIMESTAMP
+    `
+
+	QueryGetSitemapAds = `
+        SELECT id, COALESCE(updated_at, created_at)
+        FROM ads
+        WHERE status = 'active' AND deleted_at IS NULL AND expires_at > CURRENT_TIMESTAMP
+        ORDER BY id
+        LIMIT $1 OFFSET $2
+    `
+
+	QueryPurgeAd = `
+        DELETE FROM ads
+        WHERE id = $1
+    `
+
+	QueryUpdateAd = `
+        UPDATE ads a
+        SET %s, updated_at = CURRENT_TIMESTAMP
+        FROM users u
+        WHERE a.id = $%d AND u.id = a.user_id
+        RETURNING a.id, a.title, a.text, a.image_url, a.price, a.user_id, a.status, a.created_at, a.tags, a.city, a.expires_at, u.login
+    `
+
+	QuerySetAdStatus = `
+        UPDATE ads a
+        SET status = $1, updated_at = CURRENT_TIMESTAMP
+        FROM users u
+        WHERE a.id = $2 AND u.id = a.user_id
+        RETURNING a.id, a.title, a.text, a.image_url, a.price, a.user_id, a.status, a.created_at, a.tags, a.city, a.expires_at, u.login
+    `
+
+	QueryLockAdForRenew = `
+        SELECT user_id, status
+        FROM ads
+        WHERE id = $1 AND deleted_at IS NULL
+        FOR UPDATE
+    `
+
+	QueryRenewAd = `
+        UPDATE ads a
+        SET expires_at = $1, status = 'active', updated_at = CURRENT_TIMESTAMP
+        FROM users u
+        WHERE a.id = $2 AND u.id = a.user_id
+        RETURNING a.id, a.title, a.text, a.image_url, a.price, a.user_id, a.status, a.created_at, a.tags, a.city, a.expires_at, u.login
+    `
+
+	QueryExpireAds = `
+        UPDATE ads a
+        SET status = 'archived', updated_at = CURRENT_TIMESTAMP
+        FROM users u
+        WHERE a.status = 'active' AND a.deleted_at IS NULL AND a.expires_at <= CURRENT_TIMESTAMP
+          AND u.id = a.user_id
+        RETURNING a.id, a.title, a.text, a.image_url, a.price, a.user_id, a.status, a.created_at, a.tags, a.city, a.expires_at, u.login
+    `
+
+	QuerySuggestTitleWords = `
+        SELECT w.word, COUNT(*) AS freq
+        FROM (
+            SELECT a.title
+            FROM ads a
+            WHERE a.status = 'active' AND a.deleted_at IS NULL AND a.expires_at > CURRENT_TIMESTAMP
+            UNION ALL
+            SELECT t.title
+            FROM ad_translations t
+            JOIN ads a ON a.id = t.ad_id
+            WHERE a.status = 'active' AND a.deleted_at IS NULL AND a.expires_at > CURRENT_TIMESTAMP
+        ) titles,
+             LATERAL regexp_split_to_table(lower(titles.title), '[\s[:punct:]]+') AS w(word)
+        WHERE lower(titles.title) LIKE '%' || $1 || '%'
+          AND w.word LIKE $1 || '%'
+        GROUP BY w.word
+        ORDER BY freq DESC, w.word ASC
+        LIMIT $2
+    `
+
+	QueryRollupDailyStats = `
+        INSERT INTO daily_stats (day, ads_created, users_registered)
+        SELECT d::date,
+               (SELECT COUNT(*) FROM ads WHERE created_at >= d AND created_at < d + INTERVAL '1 day'),
+               (SELECT COUNT(*) FROM users WHERE created_at >= d AND created_at < d + INTERVAL '1 day')
+        FROM generate_series($1::timestamp, $2::timestamp, INTERVAL '1 day') AS d
+        ON CONFLICT (day) DO UPDATE
+        SET ads_created = EXCLUDED.ads_created,
+            users_registered = EXCLUDED.users_registered,
+            updated_at = CURRENT_TIMESTAMP
+    `
+
+	QueryLastStatsDay = `
+        SELECT MAX(day)::timestamp FROM daily_stats
+    `
+
+	QueryFirstActivityDay = `
+        SELECT date_trunc('day', MIN(created_at))
+        FROM (
+            SELECT created_at FROM users
+            UNION ALL
+            SELECT created_at FROM ads
+        ) t
+    `
+
+	QueryGetDailyStats = `
+        SELECT d::timestamp, COALESCE(s.%s, 0)
+        FROM generate_series($1::timestamp, $2::timestamp, INTERVAL '1 day') AS d
+        LEFT JOIN daily_stats s ON s.day = d::date
+        ORDER BY d
     `
 
 	QueryGetUserById = `
-        SELECT id, login, created_at
+        SELECT id, login, created_at, role
+        FROM users
+        WHERE id = $1
+    `
+
+	QuerySetUserRole = `
+        UPDATE users
+        SET role = $2
+        WHERE login = $1
+        RETURNING id, login, created_at, role
+    `
+
+	QueryDeleteUser = `
+        DELETE FROM users
+        WHERE id = $1
+    `
+
+	QueryCountUserAds = `
+        SELECT COUNT(*)
+        FROM ads
+        WHERE user_id = $1 AND deleted_at IS NULL
+    `
+
+	QueryCreateRefreshToken = `
+        INSERT INTO refresh_tokens (user_id, token_hash, expires_at)
+        VALUES ($1, $2, $3)
+    `
+
+	QueryLockRefreshToken = `
+        SELECT id, user_id, expires_at, revoked_at
+        FROM refresh_tokens
+        WHERE token_hash = $1
+        FOR UPDATE
+    `
+
+	QueryRevokeRefreshToken = `
+        UPDATE refresh_tokens
+        SET revoked_at = CURRENT_TIMESTAMP
+        WHERE id = $1
+    `
+
+	QueryRevokeUserRefreshToken = `
+        UPDATE refresh_tokens
+        SET revoked_at = CURRENT_TIMESTAMP
+        WHERE token_hash = $1 AND user_id = $2 AND revoked_at IS NULL
+    `
+
+	QueryRevokeToken = `
+        INSERT INTO revoked_tokens (jti, expires_at)
+        VALUES ($1, $2)
+        ON CONFLICT (jti) DO NOTHING
+    `
+
+	QueryIsTokenRevoked = `
+        SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE jti = $1)
+    `
+
+	QueryDeleteExpiredRevocations = `
+        DELETE FROM revoked_tokens
+        WHERE expires_at < $1
+    `
+
+	QueryCreateAnnouncement = `
+        INSERT INTO announcements (message, severity, starts_at, ends_at)
+        VALUES ($1, $2, $3, $4)
+        RETURNING id, message, severity, starts_at, ends_at, created_at
+    `
+
+	QueryUpdateAnnouncement = `
+        UPDATE announcements
+        SET message = $1, severity = $2, starts_at = $3, ends_at = $4
+        WHERE id = $5
+        RETURNING id, message, severity, starts_at, ends_at, created_at
+    `
+
+	QueryDeleteAnnouncement = `
+        DELETE FROM announcements
+        WHERE id = $1
+    `
+
+	QueryGetAnnouncements = `
+        SELECT id, message, severity, starts_at, ends_at, created_at
+        FROM announcements
+        ORDER BY CASE severity
+                     WHEN 'critical' THEN 0
+                     WHEN 'warning' THEN 1
+                     ELSE 2
+                 END,
+                 starts_at DESC,
+                 id DESC
+    `
+
+	QueryCreateNotification = `
+        INSERT INTO notifications (user_id, type, payload)
+        SELECT u.id, $2::text, $3::jsonb
+        FROM users u
+        WHERE u.id = $1
+          AND COALESCE((u.preferences ->> $2::text)::boolean, true)
+        RETURNING id, user_id, type, payload, read_at, created_at
+    `
+
+	QueryLockRecentPriceDrop = `
+        SELECT id, user_id, type, payload, read_at, created_at
+        FROM notifications
+        WHERE user_id = $1
+          AND type = 'price_drop'
+          AND (payload ->> 'ad_id')::int = $2
+          AND created_at >= $3
+        ORDER BY created_at DESC
+        LIMIT 1
+        FOR UPDATE
+    `
+
+	QueryRefreshNotification = `
+        UPDATE notifications
+        SET payload = $2, read_at = NULL
+        WHERE id = $1
+        RETURNING id, user_id, type, payload, read_at, created_at
+    `
+
+	QueryGetFavoritedBy = `
+        SELECT f.user_id
+        FROM favorites f
+        JOIN ads a ON a.id = f.ad_id
+        WHERE f.ad_id = $1
+          AND f.user_id <> a.user_id
+        ORDER BY f.user_id
+    `
+
+	QueryAddFavorite = `
+        WITH ad AS (
+            SELECT id FROM ads WHERE id = $2 AND deleted_at IS NULL
+        ), ins AS (
+            INSERT INTO favorites (user_id, ad_id)
+            SELECT $1, id FROM ad
+            ON CONFLICT (user_id, ad_id) DO NOTHING
+        )
+        SELECT EXISTS (SELECT 1 FROM ad)
+    `
+
+	QueryRemoveFavorite = `
+        DELETE FROM favorites
+        WHERE user_id = $1 AND ad_id = $2
+    `
+
+	QueryGetFavorites = `
+        SELECT a.id, a.title, a.text, a.image_url, a.price, a.user_id, a.status, a.created_at, a.tags, a.city, a.expires_at,
+               u.login,
+               CASE WHEN a.user_id = $1 THEN true ELSE false END AS is_mine,
+               EXISTS (
+                   SELECT 1 FROM reservations r
+                   WHERE r.ad_id = a.id AND r.status = 'active' AND r.expires_at > CURRENT_TIMESTAMP
+               ) AS reserved,
+               true AS is_favorite
+        FROM favorites f
+        JOIN ads a ON a.id = f.ad_id
+        JOIN users u ON a.user_id = u.id
+        WHERE f.user_id = $1
+          AND a.deleted_at IS NULL
+        ORDER BY f.created_at DESC, f.ad_id DESC
+        LIMIT $2 OFFSET $3
+    `
+
+	QueryGetSimilarAds = `
+        WITH src AS (
+            SELECT id, user_id, price,
+                   replace(plainto_tsquery('simple', title)::text, '&', '|')::tsquery AS terms
+            FROM ads
+            WHERE id = $2 AND deleted_at IS NULL
+        )
+        SELECT a.id, a.title, a.text, a.image_url, a.price, a.user_id, a.status, a.created_at, a.tags, a.city, a.expires_at,
+               u.login,
+               CASE WHEN a.user_id = $1 THEN true ELSE false END AS is_mine,
+               EXISTS (
+                   SELECT 1 FROM reservations r
+                   WHERE r.ad_id = a.id AND r.status = 'active' AND r.expires_at > CURRENT_TIMESTAMP
+               ) AS reserved,
+               f.user_id IS NOT NULL AS is_favorite
+        FROM src
+        JOIN ads a ON to_tsvector('simple', a.title || ' ' || a.text) @@ src.terms
+        JOIN users u ON a.user_id = u.id
+        LEFT JOIN favorites f ON f.ad_id = a.id AND f.user_id = $1
+        WHERE a.id <> src.id
+          AND a.user_id <> src.user_id
+          AND a.status = 'active'
+          AND a.deleted_at IS NULL
+          AND a.expires_at > CURRENT_TIMESTAMP
+        ORDER BY ts_rank(
+                     setweight(to_tsvector('simple', a.title), 'A') || setweight(to_tsvector('simple', a.text), 'B'),
+                     src.terms
+                 ) DESC,
+                 abs(a.price - src.price) ASC,
+                 a.id ASC
+        LIMIT $3
+    `
+
+	QueryAdExists = `
+        SELECT EXISTS (SELECT 1 FROM ads WHERE id = $1 AND deleted_at IS NULL)
+    `
+
+	QueryGetFavoriteAdIDs = `
+        SELECT ad_id
+        FROM favorites
+        WHERE user_id = $1 AND ad_id = ANY($2::int[])
+    `
+
+	QueryGetNotifications = `
+        SELECT id, user_id, type, payload, read_at, created_at
+        FROM notifications
+        WHERE user_id = $1
+        ORDER BY read_at IS NULL DESC, created_at DESC, id DESC
+        LIMIT $2 OFFSET $3
+    `
+
+	QueryMarkNotificationRead = `
+        UPDATE notifications
+        SET read_at = COALESCE(read_at, CURRENT_TIMESTAMP)
+        WHERE id = $1 AND user_id = $2
+        RETURNING id, user_id, type, payload, read_at, created_at
+    `
+
+	QueryMarkAllNotificationsRead = `
+        UPDATE notifications
+        SET read_at = CURRENT_TIMESTAMP
+        WHERE user_id = $1 AND read_at IS NULL
+    `
+
+	QueryDeleteReadNotifications = `
+        DELETE FROM notifications
+        WHERE read_at IS NOT NULL AND read_at < $1
+    `
+
+	QueryGetUserPreferences = `
+        SELECT preferences
         FROM users
         WHERE id = $1
     `
 
-	CreateDb = `
-        CREATE TABLE IF NOT EXISTS users (
-            id SERIAL PRIMARY KEY,
-            login VARCHAR(20) UNIQUE NOT NULL,
-            password VARCHAR(255) NOT NULL,
-            created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-        );
-        CREATE TABLE IF NOT EXISTS ads (
-            id SERIAL PRIMARY KEY,
-            title VARCHAR(100) NOT NULL,
-            text TEXT NOT NULL,
-            image_url VARCHAR(200) NOT NULL,
-            price BIGINT NOT NULL,
-            user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
-            created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-        );
-        CREATE INDEX IF NOT EXISTS idx_ads_user_id ON ads(user_id);
-        CREATE INDEX IF NOT EXISTS idx_ads_created_at ON ads(created_at);
-        CREATE INDEX IF NOT EXISTS idx_ads_price ON ads(price);
+	QueryUpdateUserPreferences = `
+        UPDATE users
+        SET preferences = preferences || $1::jsonb
+        WHERE id = $2
+        RETURNING preferences
+    `
+
+	QueryAddUsage = `
+        INSERT INTO usage_daily (user_id, day, requests)
+        SELECT u.user_id, u.day, u.requests
+        FROM unnest($1::int[], $2::date[], $3::bigint[]) AS u(user_id, day, requests)
+        WHERE EXISTS (SELECT 1 FROM users WHERE id = u.user_id)
+        ON CONFLICT (user_id, day) DO UPDATE
+        SET requests = usage_daily.requests + EXCLUDED.requests
+    `
+
+	QueryGetUsage = `
+        SELECT day, requests
+        FROM usage_daily
+        WHERE user_id = $1 AND day BETWEEN $2 AND $3
+        ORDER BY day
+    `
+
+	QueryGetUsageOn = `
+        SELECT requests
+        FROM usage_daily
+        WHERE user_id = $1 AND day = $2
+    `
+
+	QueryCreateAdTranslation = `
+        INSERT INTO ad_translations (ad_id, lang, title, text)
+        VALUES ($1, $2, $3, $4)
+    `
+
+	QueryGetAdTranslations = `
+        SELECT ad_id, lang, title, text
+        FROM ad_translations
+        WHERE ad_id = ANY($1)
+    `
+
+	QueryRegisterImage = `
+        INSERT INTO images (hash, name, size)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (hash) DO UPDATE SET updated_at = CURRENT_TIMESTAMP, deleted_at = NULL
+        RETURNING name, (xmax = 0) AS inserted
     `
+
+	QueryLinkAdImage = `
+        WITH image AS (
+            UPDATE images i
+            SET deleted_at = NULL
+            FROM ads a
+            WHERE a.id = $1 AND i.name = substring(a.image_url FROM '/uploads/([A-Za-z0-9_.-]+)$')
+            RETURNING i.hash
+        )
+        INSERT INTO ad_images (ad_id, hash)
+        SELECT $1, hash FROM image
+        ON CONFLICT (ad_id) DO UPDATE SET hash = EXCLUDED.hash
+    `
+
+	QueryLinkNewAdImages = `
+        WITH image AS (
+            UPDATE images
+            SET deleted_at = NULL
+            WHERE name IN (SELECT substring(url FROM '/uploads/([A-Za-z0-9_.-]+)$') FROM unnest($2::text[]) AS url)
+            RETURNING hash, name
+        )
+        INSERT INTO ad_images (ad_id, hash)
+        SELECT a.id, image.hash
+        FROM ads a
+        JOIN image ON image.name = substring(a.image_url FROM '/uploads/([A-Za-z0-9_.-]+)$')
+        WHERE a.id > $1 AND a.deleted_at IS NULL
+        ON CONFLICT (ad_id) DO NOTHING
+    `
+
+	QueryUnlinkAdImage = `DELETE FROM ad_images WHERE ad_id = $1`
+
+	QueryMaxAdID = `SELECT COALESCE(MAX(id), 0) FROM ads`
+
+	QueryMarkUnusedImages = `
+        UPDATE images
+        SET deleted_at = CURRENT_TIMESTAMP
+        WHERE hash IN (
+            SELECT i.hash
+            FROM images i
+            WHERE i.deleted_at IS NULL
+              AND i.updated_at < $1
+              AND NOT EXISTS (SELECT 1 FROM ad_images r WHERE r.hash = i.hash)
+            ORDER BY i.updated_at
+            LIMIT $2
+            FOR UPDATE SKIP LOCKED
+        )
+    `
+
+	QueryDeletedImages = `
+        SELECT hash
+        FROM images
+        WHERE deleted_at IS NOT NULL
+        ORDER BY deleted_at
+        LIMIT $1
+    `
+
+	QueryLockDeletedImage = `
+        SELECT name
+        FROM images
+        WHERE hash = $1 AND deleted_at IS NOT NULL
+        FOR UPDATE SKIP LOCKED
+    `
+
+	QueryImageReferenced = `SELECT EXISTS (SELECT 1 FROM ad_images WHERE hash = $1)`
+
+	QueryRestoreImage = `UPDATE images SET deleted_at = NULL WHERE hash = $1`
+
+	QueryDeleteImage = `DELETE FROM images WHERE hash = $1`
+
+	QueryHealthCheck = `SELECT 1`
+
+	QueryExistingUserIDs = `SELECT id FROM users WHERE id = ANY($1)`
 )
@@ -0,0 +1,116 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/YuarenArt/marketgo/pkg/logging"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ReplicaCheckInterval - как часто RunReplicaChecks проверяет реплики
+const ReplicaCheckInterval = 5 * time.Second
+
+// replica - пул соединений с репликой для чтения
+type replica struct {
+	host    string
+	pool    *pgxpool.Pool
+	healthy atomic.Bool
+}
+
+// WithReadReplicas направляет читающие запросы (пользователь по логину и ID, объявление,
+// списки и счётчики объявлений) по кругу на реплики dsns; изменяющие запросы выполняются на основном сервере.
+// Реплика, не прошедшая проверку, пропускается до следующей успешной проверки.
+// Пока исправных реплик нет, чтение идёт с основного сервера.
+func WithReadReplicas(dsns ...string) DBOption {
+	return func(cfg *dbConfig) {
+		cfg.replicaDSNs = append(cfg.replicaDSNs, dsns...)
+	}
+}
+
+// newReplica создаёт пул реплики с теми же размерами пула, что и у основного сервера
+func newReplica(ctx context.Context, dsn string, primary *pgxpool.Config) (*replica, error) {
+	cfg, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse replica DSN: %w", err)
+	}
+	cfg.ConnConfig.RuntimeParams["timezone"] = "UTC"
+	cfg.MaxConns = primary.MaxConns
+	cfg.MinConns = primary.MinConns
+	cfg.MaxConnLifetime = primary.MaxConnLifetime
+	cfg.MaxConnIdleTime = primary.MaxConnIdleTime
+
+	pool, err := pgxpool.NewWithConfig(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create replica pool: %w", err)
+	}
+	return &replica{host: cfg.ConnConfig.Host, pool: pool}, nil
+}
+
+// reader возвращает пул для читающего запроса: следующую по кругу исправную реплику или основной пул
+func (s *DBService) reader() *pgxpool.Pool {
+	n := uint64(len(s.replicas))
+	if n == 0 {
+		return s.pool
+	}
+	start := s.nextReplica.Add(1)
+	for i := uint64(0); i < n; i++ {
+		if r := s.replicas[(start+i)%n]; r.healthy.Load() {
+			return r.pool
+		}
+	}
+	return s.pool
+}
+
+// CheckReplicas выполняет SELECT 1 на каждой реплике и исключает из чтения не ответившие.
+// Возвращает количество исправных реплик.
+func (s *DBService) CheckReplicas(ctx context.Context) int {
+	healthy := 0
+	for _, r := range s.replicas {
+		if s.checkReplica(ctx, r) == nil {
+			healthy++
+		}
+	}
+	return healthy
+}
+
+func (s *DBService) checkReplica(ctx context.Context, r *replica) error {
+	ctx, cancel := context.WithTimeout(ctx, HealthCheckTimeout)
+	defer cancel()
+
+	var one int
+	err := r.pool.QueryRow(ctx, QueryHealthCheck).Scan(&one)
+	r.healthy.Store(err == nil)
+	return err
+}
+
+// RunReplicaChecks проверяет реплики каждые interval до отмены ctx; без реплик сразу возвращается
+func (s *DBService) RunReplicaChecks(ctx context.Context, interval time.Duration, logger logging.Logger) {
+	if len(s.replicas) == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		for _, r := range s.replicas {
+			wasHealthy := r.healthy.Load()
+			err := s.checkReplica(ctx, r)
+			switch {
+			case err != nil && wasHealthy:
+				logger.Warn("Read replica excluded after failed health check", "replica", r.host, "error", err)
+			case err == nil && !wasHealthy:
+				logger.Info("Read replica recovered", "replica", r.host)
+			}
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	ReservationStatusActive    = "active"
+	ReservationStatusCancelled = "cancelled"
+	ReservationStatusConfirmed = "confirmed"
+	ReservationStatusExpired   = "expired"
+
+	// Действия над бронированием для TransitionReservation
+	ReservationActionReserve = "reserve"
+	ReservationActionCancel  = "cancel"
+	ReservationActionConfirm = "confirm"
+
+	ErrMsgReservationNotFound  = "бронирование не найдено"
+	ErrMsgAlreadyReserved      = "объявление уже забронировано"
+	ErrMsgReserveOwnAd         = "нельзя забронировать собственное объявление"
+	ErrMsgAdNotAvailable       = "объявление недоступно для бронирования"
+	ErrMsgReservationNotActive = "бронирование уже завершено"
+	ErrMsgNotReservationParty  = "бронирование может отменить только покупатель или продавец"
+	ErrMsgInvalidReservation   = "неизвестное действие с бронированием"
+)
+
+var (
+	ErrReservationNotFound  = newKindError(ErrNotFound, ErrMsgReservationNotFound)
+	ErrAlreadyReserved      = newKindError(ErrConflict, ErrMsgAlreadyReserved)
+	ErrReserveOwnAd         = newKindError(ErrInvalid, ErrMsgReserveOwnAd)
+	ErrAdNotAvailable       = newKindError(ErrConflict, ErrMsgAdNotAvailable)
+	ErrReservationNotActive = newKindError(ErrConflict, ErrMsgReservationNotActive)
+	ErrNotReservationParty  = newKindError(ErrForbidden, ErrMsgNotReservationParty)
+	ErrInvalidReservation   = newKindError(ErrInvalid, ErrMsgInvalidReservation)
+)
+
+// Reservation представляет бронирование объявления покупателем.
+type Reservation struct {
+	ID        int       `json:"id"`
+	AdID      int       `json:"ad_id"`
+	BuyerID   int       `json:"buyer_id"`
+	Status    string    `json:"status"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ReservationTransition описывает действие пользователя UserID над бронированием объявления AdID.
+// ReservationID используется при подтверждении, ExpiresAt - при создании бронирования.
+type ReservationTransition struct {
+	Action        string
+	AdID          int
+	UserID        int
+	ReservationID int
+	ExpiresAt     time.Time
+}
+
+// TransitionReservation выполняет действие над бронированием в одной транзакции.
+// Объявление блокируется на время транзакции, просроченные бронирования предварительно истекают.
+//
+// Допустимые переходы:
+//   - reserve: нет активного бронирования -> active (только не владелец, только активное объявление);
+//   - cancel: active -> cancelled (покупатель или продавец);
+//   - confirm: active -> confirmed (только продавец), объявление переходит в sold с записью покупателя.
+func (s *DBService) TransitionReservation(ctx context.Context, t ReservationTransition) (Reservation, error) {
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return Reservation{}, fmt.Errorf("failed to begin transaction: %w", classifyError(err))
+	}
+	defer tx.Rollback(ctx)
+
+	var ownerID int
+	var adStatus string
+	if err := tx.QueryRow(ctx, QueryLockAdForReservation, t.AdID).Scan(&ownerID, &adStatus); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return Reservation{}, ErrAdNotFound
+		}
+		return Reservation{}, fmt.Errorf("failed to get ad: %w", classifyError(err))
+	}
+
+	if _, err := tx.Exec(ctx, QueryExpireAdReservations, t.AdID); err != nil {
+		return Reservation{}, fmt.Errorf("failed to expire reservations: %w", classifyError(err))
+	}
+
+	var r Reservation
+	switch t.Action {
+	case ReservationActionReserve:
+		if ownerID == t.UserID {
+			return Reservation{}, ErrReserveOwnAd
+		}
+		if adStatus != AdStatusActive {
+			return Reservation{}, ErrAdNotAvailable
+		}
+		if _, err := scanReservation(tx.QueryRow(ctx, QueryGetActiveReservation, t.AdID)); err == nil {
+			return Reservation{}, ErrAlreadyReserved
+		} else if !errors.Is(err, pgx.ErrNoRows) {
+			return Reservation{}, fmt.Errorf("failed to get reservation: %w", classifyError(err))
+		}
+		r, err = scanReservation(tx.QueryRow(ctx, QueryCreateReservation, t.AdID, t.UserID, t.ExpiresAt.UTC()))
+		if err != nil {
+			return Reservation{}, fmt.Errorf("failed to create reservation: %w", classifyError(err))
+		}
+
+	case ReservationActionCancel:
+		current, err := scanReservation(tx.QueryRow(ctx, QueryGetActiveReservation, t.AdID))
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return Reservation{}, ErrReservationNotFound
+			}
+			return Reservation{}, fmt.Errorf("failed to get reservation: %w", classifyError(err))
+		}
+		if t.UserID != current.BuyerID && t.UserID != ownerID {
+			return Reservation{}, ErrNotReservationParty
+		}
+		r, err = scanReservation(tx.QueryRow(ctx, QuerySetReservationStatus, current.ID, ReservationStatusCancelled))
+		if err != nil {
+			return Reservation{}, fmt.Errorf("failed to cancel reservation: %w", classifyError(err))
+		}
+
+	case ReservationActionConfirm:
+		current, err := scanReservation(tx.QueryRow(ctx, QueryGetReservation, t.ReservationID, t.AdID))
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return Reservation{}, ErrReservationNotFound
+			}
+			return Reservation{}, fmt.Errorf("failed to get reservation: %w", classifyError(err))
+		}
+		if t.UserID != ownerID {
+			return Reservation{}, ErrNotAdOwner
+		}
+		if current.Status != ReservationStatusActive {
+			return Reservation{}, ErrReservationNotActive
+		}
+		r, err = scanReservation(tx.QueryRow(ctx, QuerySetReservationStatus, current.ID, ReservationStatusConfirmed))
+		if err != nil {
+			return Reservation{}, fmt.Errorf("failed to confirm reservation: %w", classifyError(err))
+		}
+		if _, err := tx.Exec(ctx, QueryMarkAdSold, t.AdID, current.BuyerID); err != nil {
+			return Reservation{}, fmt.Errorf("failed to mark ad sold: %w", classifyError(err))
+		}
+
+	default:
+		return Reservation{}, ErrInvalidReservation
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return Reservation{}, fmt.Errorf("failed to commit transaction: %w", classifyError(err))
+	}
+	return r, nil
+}
+
+// ExpireReservations переводит просроченные активные бронирования в expired и возвращает их количество
+func (s *DBService) ExpireReservations(ctx context.Context) (int64, error) {
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+
+	tag, err := s.pool.Exec(ctx, QueryExpireReservations)
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire reservations: %w", classifyError(err))
+	}
+	return tag.RowsAffected(), nil
+}
+
+// scanReservation считывает бронирование из строки результата.
+func scanReservation(row pgx.Row) (Reservation, error) {
+	var r Reservation
+	err := row.Scan(&r.ID, &r.AdID, &r.BuyerID, &r.Status, &r.ExpiresAt, &r.CreatedAt, &r.UpdatedAt)
+	return r, err
+}
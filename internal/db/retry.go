@@ -0,0 +1,74 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	// DefaultRetryAttempts - сколько раз по умолчанию выполняется читающий запрос, включая первую попытку
+	DefaultRetryAttempts = 3
+	// DefaultRetryBase - задержка перед первым повтором по умолчанию; каждая следующая вдвое больше
+	DefaultRetryBase = 50 * time.Millisecond
+
+	pgSerializationFailure = "40001"
+)
+
+// WithRetry задаёт повтор читающих запросов при временных ошибках: обрыве соединения
+// или ошибке сериализации. Запрос выполняется не более attempts раз; перед n-м повтором
+// ждёт случайное время от base*2^(n-1)/2 до base*2^(n-1). attempts <= 1 отключает повторы.
+// Запросы, изменяющие данные, не повторяются.
+func WithRetry(attempts int, base time.Duration) DBOption {
+	return func(cfg *dbConfig) {
+		cfg.retryAttempts = attempts
+		cfg.retryBase = base
+	}
+}
+
+// WithRetryObserver вызывает observe перед каждым повтором запроса.
+// Используется для метрики db_retries_total.
+func WithRetryObserver(observe func()) DBOption {
+	return func(cfg *dbConfig) {
+		cfg.onRetry = observe
+	}
+}
+
+// isTransient сообщает, что запрос можно повторить: соединение оборвалось
+// или транзакция не прошла проверку сериализации. Истечение времени запроса не повторяется.
+func isTransient(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgSerializationFailure {
+		return true
+	}
+	return IsUnavailable(err) && !IsTimeout(err)
+}
+
+// retryRead выполняет читающий запрос query, повторяя его при временных ошибках согласно WithRetry.
+// Возвращает ошибку последней попытки.
+func (s *DBService) retryRead(ctx context.Context, query func() error) error {
+	err := query()
+	for attempt := 1; attempt < s.retryAttempts && err != nil && isTransient(err); attempt++ {
+		delay := s.retryBase << (attempt - 1)
+		if delay > 0 {
+			delay = delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+
+		if s.onRetry != nil {
+			s.onRetry()
+		}
+		err = query()
+	}
+	return err
+}
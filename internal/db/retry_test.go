@@ -0,0 +1,94 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+)
+
+// flakyQuery возвращает по очереди ошибки errs, затем nil, и считает вызовы
+type flakyQuery struct {
+	errs  []error
+	calls int
+}
+
+func (q *flakyQuery) run() error {
+	q.calls++
+	if q.calls <= len(q.errs) {
+		return q.errs[q.calls-1]
+	}
+	return nil
+}
+
+func TestRetryRead(t *testing.T) {
+	connReset := &pgconn.PgError{Code: "08006"}
+	serialization := &pgconn.PgError{Code: pgSerializationFailure}
+
+	newService := func(attempts int) (*DBService, *int) {
+		retries := 0
+		return &DBService{
+			retryAttempts: attempts,
+			retryBase:     time.Millisecond,
+			onRetry:       func() { retries++ },
+		}, &retries
+	}
+
+	t.Run("transient errors are retried", func(t *testing.T) {
+		s, retries := newService(3)
+		q := &flakyQuery{errs: []error{connReset, serialization}}
+
+		assert.NoError(t, s.retryRead(testCtx, q.run))
+		assert.Equal(t, 3, q.calls)
+		assert.Equal(t, 2, *retries)
+	})
+
+	t.Run("last error is returned when attempts run out", func(t *testing.T) {
+		s, _ := newService(2)
+		q := &flakyQuery{errs: []error{connReset, io.ErrUnexpectedEOF, connReset}}
+
+		assert.ErrorIs(t, s.retryRead(testCtx, q.run), io.ErrUnexpectedEOF)
+		assert.Equal(t, 2, q.calls)
+	})
+
+	t.Run("other errors are not retried", func(t *testing.T) {
+		for _, err := range []error{
+			pgx.ErrNoRows,
+			&pgconn.PgError{Code: pgUniqueViolation},
+			context.DeadlineExceeded,
+			context.Canceled,
+			errors.New("bad query"),
+		} {
+			s, retries := newService(3)
+			q := &flakyQuery{errs: []error{err}}
+
+			assert.ErrorIs(t, s.retryRead(testCtx, q.run), err)
+			assert.Equal(t, 1, q.calls, "error: %v", err)
+			assert.Zero(t, *retries)
+		}
+	})
+
+	t.Run("single attempt disables retries", func(t *testing.T) {
+		s, _ := newService(1)
+		q := &flakyQuery{errs: []error{connReset}}
+
+		assert.Error(t, s.retryRead(testCtx, q.run))
+		assert.Equal(t, 1, q.calls)
+	})
+
+	t.Run("cancelled context stops retries", func(t *testing.T) {
+		s, _ := newService(3)
+		s.retryBase = time.Minute
+		ctx, cancel := context.WithCancel(testCtx)
+		cancel()
+		q := &flakyQuery{errs: []error{connReset}}
+
+		assert.ErrorIs(t, s.retryRead(ctx, q.run), connReset)
+		assert.Equal(t, 1, q.calls)
+	})
+}
@@ -0,0 +1,68 @@
+package db
+
+import (
+	"context"
+	"fmt"
+)
+
+// SearchAds выполняет полнотекстовый поиск по заголовку и тексту активных объявлений
+// с фильтрами, аналогичными Ads. Возвращает ID найденных объявлений по убыванию
+// релевантности (при равной релевантности - по возрастанию id) и общее количество найденных.
+func (s *DBService) SearchAds(ctx context.Context, query string, filter AdsFilter, limit, offset int) ([]int, int, error) {
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+
+	where, args, err := adsConditions(filter, []any{query, limit, offset})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rows, err := s.pool.Query(ctx, fmt.Sprintf(QuerySearchAds, where), args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to search ads: %w", classifyError(err))
+	}
+	defer rows.Close()
+
+	ids := make([]int, 0, limit)
+	total := 0
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id, &total); err != nil {
+			return nil, 0, fmt.Errorf("failed to search ads: %w", classifyError(err))
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error during rows iteration: %w", classifyError(err))
+	}
+
+	if len(ids) == 0 && offset > 0 {
+		// Страница за пределами результатов: COUNT(*) OVER () не вернул строк
+		where, args, _ := adsConditions(filter, []any{query})
+		if err := s.pool.QueryRow(ctx, fmt.Sprintf(QueryCountSearchAds, where), args...).Scan(&total); err != nil {
+			return nil, 0, fmt.Errorf("failed to count ads: %w", classifyError(err))
+		}
+	}
+
+	return ids, total, nil
+}
+
+// AdsByIDs возвращает объявления с указанными ID в порядке ids, повторно применяя фильтр.
+// Используется для загрузки результатов внешнего поиска: удалённые и не подходящие
+// под фильтр объявления пропускаются, даже если индекс поиска ещё не обновлён.
+func (s *DBService) AdsByIDs(ctx context.Context, userID int, ids []int, filter AdsFilter) ([]Ad, error) {
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+
+	if len(ids) == 0 {
+		return []Ad{}, nil
+	}
+
+	where, args, err := adsConditions(filter, []any{userID, ids})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.readAds(ctx, fmt.Sprintf(QueryGetAdsByIDs, where), args)
+}
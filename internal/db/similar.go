@@ -0,0 +1,33 @@
+package db
+
+import (
+	"context"
+	"fmt"
+)
+
+// SimilarAds возвращает до limit активных объявлений, похожих на adID.
+// Исходное объявление и другие объявления его владельца не включаются.
+// Похожесть - совпадение слов заголовка adID с заголовком (вес выше) и текстом
+// кандидата; при равной похожести выше объявления с более близкой ценой.
+// Для отсутствующего или удалённого adID возвращается ErrAdNotFound.
+func (s *DBService) SimilarAds(ctx context.Context, adID, userID, limit int) ([]Ad, error) {
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+
+	ads, err := s.readAds(ctx, QueryGetSimilarAds, []any{userID, adID, limit})
+	if err != nil {
+		return nil, err
+	}
+	if len(ads) > 0 {
+		return ads, nil
+	}
+
+	var exists bool
+	if err := s.reader().QueryRow(ctx, QueryAdExists, adID).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("failed to get ad: %w", classifyError(err))
+	}
+	if !exists {
+		return nil, ErrAdNotFound
+	}
+	return ads, nil
+}
@@ -0,0 +1,70 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimilarAds(t *testing.T) {
+	require.NoError(t, clearTables(testCtx, testDB))
+	seller, err := testDB.CreateUser(testCtx, "similarseller", "pass")
+	require.NoError(t, err)
+	other, err := testDB.CreateUser(testCtx, "similarother", "pass")
+	require.NoError(t, err)
+
+	create := func(title, text string, price int64, userID int) Ad {
+		ad, err := testDB.CreateAd(testCtx, Ad{Title: title, Text: text, Price: price, UserID: userID})
+		require.NoError(t, err)
+		return ad
+	}
+	source := create("Велосипед горный Stels", "Алюминиевая рама", 10000, seller.ID)
+	create("Велосипед горный Forward", "Той же модели нет", 10000, seller.ID)
+	twoTerms := create("Велосипед горный Merida", "Почти новый", 30000, other.ID)
+	closePrice := create("Велосипед детский", "Для ребёнка", 11000, other.ID)
+	farPrice := create("Велосипед шоссейный", "Карбон", 50000, other.ID)
+	inText := create("Самокат", "Лучше, чем велосипед", 10000, other.ID)
+	create("Диван", "Угловой", 10000, other.ID)
+	sold := create("Велосипед горный Trek", "Продан", 10000, other.ID)
+	_, err = testDB.SetAdStatus(testCtx, sold.ID, other.ID, AdStatusSold)
+	require.NoError(t, err)
+
+	ids := func(ads []Ad) []int {
+		result := make([]int, 0, len(ads))
+		for _, ad := range ads {
+			result = append(result, ad.ID)
+		}
+		return result
+	}
+
+	t.Run("ranked by title overlap, then price proximity", func(t *testing.T) {
+		ads, err := testDB.SimilarAds(testCtx, source.ID, other.ID, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []int{twoTerms.ID, closePrice.ID, farPrice.ID, inText.ID}, ids(ads))
+		assert.True(t, ads[0].IsMine)
+		assert.Equal(t, "similarother", ads[0].Author)
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		ads, err := testDB.SimilarAds(testCtx, source.ID, other.ID, 2)
+		require.NoError(t, err)
+		assert.Equal(t, []int{twoTerms.ID, closePrice.ID}, ids(ads))
+	})
+
+	t.Run("no matches is an empty list", func(t *testing.T) {
+		sofa := create("Кресло", "Мягкое", 5000, seller.ID)
+		ads, err := testDB.SimilarAds(testCtx, sofa.ID, other.ID, 10)
+		require.NoError(t, err)
+		assert.Empty(t, ads)
+	})
+
+	t.Run("missing or deleted ad is not found", func(t *testing.T) {
+		_, err := testDB.SimilarAds(testCtx, 999999, other.ID, 10)
+		assert.ErrorIs(t, err, ErrAdNotFound)
+
+		require.NoError(t, testDB.DeleteAd(testCtx, source.ID, seller.ID))
+		_, err = testDB.SimilarAds(testCtx, source.ID, other.ID, 10)
+		assert.ErrorIs(t, err, ErrAdNotFound)
+	})
+}
@@ -0,0 +1,56 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// SitemapEntry описывает объявление в карте сайта.
+type SitemapEntry struct {
+	AdID    int
+	LastMod time.Time
+}
+
+// CountSitemapAds возвращает количество активных неудалённых объявлений.
+func (s *DBService) CountSitemapAds(ctx context.Context) (int, error) {
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+
+	var count int
+	err := s.retryRead(ctx, func() error {
+		return s.reader().QueryRow(ctx, QueryCountSitemapAds).Scan(&count)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count ads: %w", classifyError(err))
+	}
+	return count, nil
+}
+
+// SitemapAds возвращает активные неудалённые объявления, упорядоченные по ID,
+// с датой последнего изменения.
+func (s *DBService) SitemapAds(ctx context.Context, limit, offset int) ([]SitemapEntry, error) {
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := s.pool.Query(ctx, QueryGetSitemapAds, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query ads: %w", classifyError(err))
+	}
+	defer rows.Close()
+
+	entries := make([]SitemapEntry, 0)
+	for rows.Next() {
+		var e SitemapEntry
+		if err := rows.Scan(&e.AdID, &e.LastMod); err != nil {
+			return nil, fmt.Errorf("failed to query ads: %w", classifyError(err))
+		}
+		entries = append(entries, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during rows iteration: %w", classifyError(err))
+	}
+
+	return entries, nil
+}
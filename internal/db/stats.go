@@ -0,0 +1,118 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	StatsMetricAdsCreated      = "ads_created"
+	StatsMetricUsersRegistered = "users_registered"
+
+	ErrMsgInvalidStatsMetric = "метрика должна быть ads_created или users_registered"
+	ErrMsgInvalidStatsRange  = "начало периода должно быть не позже его конца"
+)
+
+var (
+	ErrInvalidStatsMetric = newKindError(ErrInvalid, ErrMsgInvalidStatsMetric)
+	ErrInvalidStatsRange  = newKindError(ErrInvalid, ErrMsgInvalidStatsRange)
+)
+
+// DailyStat представляет значение метрики за один день (UTC).
+type DailyStat struct {
+	Date  time.Time `json:"date"`
+	Value int64     `json:"value"`
+}
+
+// RollupDailyStats пересчитывает дневную статистику за дни с from по to включительно.
+// Операция идемпотентна: существующие строки перезаписываются (UPSERT).
+func (s *DBService) RollupDailyStats(ctx context.Context, from, to time.Time) error {
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+
+	from, to = truncateDay(from), truncateDay(to)
+	if from.After(to) {
+		return ErrInvalidStatsRange
+	}
+
+	if _, err := s.pool.Exec(ctx, QueryRollupDailyStats, from, to); err != nil {
+		return fmt.Errorf("failed to rollup daily stats: %w", classifyError(err))
+	}
+	return nil
+}
+
+// LastStatsDay возвращает последний день, для которого есть статистика.
+// Второе значение равно false, если статистика ещё не собиралась.
+func (s *DBService) LastStatsDay(ctx context.Context) (time.Time, bool, error) {
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+
+	var day *time.Time
+	if err := s.pool.QueryRow(ctx, QueryLastStatsDay).Scan(&day); err != nil {
+		return time.Time{}, false, fmt.Errorf("failed to get last stats day: %w", classifyError(err))
+	}
+	if day == nil {
+		return time.Time{}, false, nil
+	}
+	return truncateDay(*day), true, nil
+}
+
+// FirstActivityDay возвращает день первой регистрации или объявления.
+// Второе значение равно false, если в базе нет ни пользователей, ни объявлений.
+func (s *DBService) FirstActivityDay(ctx context.Context) (time.Time, bool, error) {
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+
+	var day *time.Time
+	if err := s.pool.QueryRow(ctx, QueryFirstActivityDay).Scan(&day); err != nil {
+		return time.Time{}, false, fmt.Errorf("failed to get first activity day: %w", classifyError(err))
+	}
+	if day == nil {
+		return time.Time{}, false, nil
+	}
+	return truncateDay(*day), true, nil
+}
+
+// DailyStats возвращает ряд значений метрики за каждый день с from по to включительно.
+// Дни без собранной статистики возвращаются с нулевым значением.
+func (s *DBService) DailyStats(ctx context.Context, metric string, from, to time.Time) ([]DailyStat, error) {
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+
+	if metric != StatsMetricAdsCreated && metric != StatsMetricUsersRegistered {
+		return nil, ErrInvalidStatsMetric
+	}
+	from, to = truncateDay(from), truncateDay(to)
+	if from.After(to) {
+		return nil, ErrInvalidStatsRange
+	}
+
+	query := fmt.Sprintf(QueryGetDailyStats, metric)
+	rows, err := s.pool.Query(ctx, query, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query daily stats: %w", classifyError(err))
+	}
+	defer rows.Close()
+
+	stats := make([]DailyStat, 0)
+	for rows.Next() {
+		var stat DailyStat
+		if err := rows.Scan(&stat.Date, &stat.Value); err != nil {
+			return nil, fmt.Errorf("failed to query daily stats: %w", classifyError(err))
+		}
+		stats = append(stats, stat)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during rows iteration: %w", classifyError(err))
+	}
+
+	return stats, nil
+}
+
+// truncateDay возвращает начало дня t в UTC.
+func truncateDay(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,53 @@
+package db
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// MaxTags - сколько тегов можно указать у объявления и в фильтре списка
+	MaxTags      = 10
+	maxTagLength = 30
+
+	ErrMsgInvalidTag  = "тег должен содержать от 1 до 30 символов"
+	ErrMsgTooManyTags = "у объявления может быть не больше 10 тегов"
+)
+
+var (
+	ErrInvalidTag  = newKindError(ErrInvalid, ErrMsgInvalidTag)
+	ErrTooManyTags = newKindError(ErrInvalid, ErrMsgTooManyTags)
+)
+
+// NormalizeTags приводит теги к нижнему регистру, убирает крайние пробелы и повторы, сохраняя порядок.
+// Ошибка называет первый неподходящий тег и распознаётся через errors.Is как ErrInvalidTag или ErrTooManyTags.
+func NormalizeTags(tags []string) ([]string, error) {
+	if len(tags) == 0 {
+		return nil, nil
+	}
+	normalized := make([]string, 0, len(tags))
+	for _, raw := range tags {
+		tag := strings.ToLower(strings.TrimSpace(raw))
+		if n := utf8.RuneCountInString(tag); n < 1 || n > maxTagLength {
+			return nil, newKindError(ErrInvalidTag, fmt.Sprintf("тег %q должен содержать от 1 до 30 символов", raw))
+		}
+		if slices.Contains(normalized, tag) {
+			continue
+		}
+		if len(normalized) == MaxTags {
+			return nil, newKindError(ErrTooManyTags, fmt.Sprintf("тег %q лишний: %s", raw, ErrMsgTooManyTags))
+		}
+		normalized = append(normalized, tag)
+	}
+	return normalized, nil
+}
+
+// nonNilTags заменяет nil пустым срезом: pgx передаёт nil как NULL, а столбец ads.tags NOT NULL
+func nonNilTags(tags []string) []string {
+	if tags == nil {
+		return []string{}
+	}
+	return tags
+}
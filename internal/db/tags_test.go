@@ -0,0 +1,178 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	t.Run("lowercased, trimmed and deduplicated in order", func(t *testing.T) {
+		tags, err := NormalizeTags([]string{" Торг ", "самовывоз", "ТОРГ", "Доставка"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"торг", "самовывоз", "доставка"}, tags)
+	})
+
+	t.Run("no tags", func(t *testing.T) {
+		tags, err := NormalizeTags(nil)
+		require.NoError(t, err)
+		assert.Empty(t, tags)
+	})
+
+	t.Run("length is counted in runes", func(t *testing.T) {
+		_, err := NormalizeTags([]string{strings.Repeat("я", 30)})
+		assert.NoError(t, err)
+
+		_, err = NormalizeTags([]string{"торг", strings.Repeat("я", 31)})
+		assert.ErrorIs(t, err, ErrInvalidTag)
+		assert.ErrorIs(t, err, ErrInvalid)
+		assert.Contains(t, Message(err), strings.Repeat("я", 31))
+	})
+
+	t.Run("blank tag names the tag", func(t *testing.T) {
+		_, err := NormalizeTags([]string{"торг", "  "})
+		assert.ErrorIs(t, err, ErrInvalidTag)
+		assert.Equal(t, `тег "  " должен содержать от 1 до 30 символов`, Message(err))
+	})
+
+	t.Run("at most ten distinct tags", func(t *testing.T) {
+		tags := make([]string, 0, 11)
+		for i := 0; i < 10; i++ {
+			tags = append(tags, fmt.Sprintf("tag%d", i))
+		}
+		_, err := NormalizeTags(append(tags, "TAG0"))
+		assert.NoError(t, err, "duplicates do not count")
+
+		_, err = NormalizeTags(append(tags, "лишний"))
+		assert.ErrorIs(t, err, ErrTooManyTags)
+		assert.Contains(t, Message(err), `"лишний"`)
+	})
+}
+
+func TestAdTags(t *testing.T) {
+	require.NoError(t, clearTables(testCtx, testDB))
+	user, err := testDB.CreateUser(testCtx, "taguser", "pass")
+	require.NoError(t, err)
+
+	create := func(title string, price int64, tags ...string) Ad {
+		ad, err := testDB.CreateAd(testCtx, Ad{Title: title, Text: "Text", Price: price, UserID: user.ID, Tags: tags})
+		require.NoError(t, err)
+		return ad
+	}
+	bike := create("Bike", 3000, "Торг", "самовывоз", "торг")
+	scooter := create("Scooter", 1000, "торг")
+	sofa := create("Sofa", 2000, "самовывоз")
+	create("Lamp", 500)
+
+	titles := func(ads []Ad) []string {
+		result := make([]string, 0, len(ads))
+		for _, ad := range ads {
+			result = append(result, ad.Title)
+		}
+		return result
+	}
+
+	t.Run("tags are normalized on create and returned", func(t *testing.T) {
+		assert.Equal(t, []string{"торг", "самовывоз"}, bike.Tags)
+
+		ad, err := testDB.Ad(testCtx, bike.ID, user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"торг", "самовывоз"}, ad.Tags)
+	})
+
+	t.Run("invalid tag is rejected", func(t *testing.T) {
+		_, err := testDB.CreateAd(testCtx, Ad{Title: "Bad", Text: "Text", Price: 100, UserID: user.ID, Tags: []string{""}})
+		assert.ErrorIs(t, err, ErrInvalidTag)
+	})
+
+	t.Run("tags use AND semantics", func(t *testing.T) {
+		ads, err := testDB.Ads(testCtx, user.ID, 1, 10, "price", "ASC", AdsFilter{MaxPrice: maxPrice, Tags: []string{"торг"}})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Scooter", "Bike"}, titles(ads))
+
+		ads, err = testDB.Ads(testCtx, user.ID, 1, 10, "price", "ASC", AdsFilter{MaxPrice: maxPrice, Tags: []string{"ТОРГ", "самовывоз"}})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Bike"}, titles(ads))
+	})
+
+	t.Run("tags compose with price range, sort and count", func(t *testing.T) {
+		filter := AdsFilter{MinPrice: 1500, MaxPrice: maxPrice, Tags: []string{"самовывоз"}}
+		ads, err := testDB.Ads(testCtx, user.ID, 1, 10, "title", "DESC", filter)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Sofa", "Bike"}, titles(ads))
+
+		count, err := testDB.CountAds(testCtx, filter)
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+
+		page, next, err := testDB.AdsAfterCursor(testCtx, user.ID, 1, "price", "ASC", filter, nil)
+		require.NoError(t, err)
+		require.NotNil(t, next)
+		assert.Equal(t, []string{"Sofa"}, titles(page))
+	})
+
+	t.Run("update replaces tags", func(t *testing.T) {
+		tags := []string{"Доставка"}
+		ad, err := testDB.UpdateAd(testCtx, scooter.ID, user.ID, AdUpdate{Tags: &tags})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"доставка"}, ad.Tags)
+
+		empty := []string{}
+		ad, err = testDB.UpdateAd(testCtx, sofa.ID, user.ID, AdUpdate{Tags: &empty})
+		require.NoError(t, err)
+		assert.Empty(t, ad.Tags)
+
+		ads, err := testDB.Ads(testCtx, user.ID, 1, 10, "price", "ASC", AdsFilter{MaxPrice: maxPrice, Tags: []string{"самовывоз"}})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Bike"}, titles(ads))
+	})
+
+	t.Run("invalid filter tag is rejected", func(t *testing.T) {
+		_, err := testDB.CountAds(testCtx, AdsFilter{MaxPrice: maxPrice, Tags: []string{strings.Repeat("x", 31)}})
+		assert.ErrorIs(t, err, ErrInvalidTag)
+	})
+}
+
+func TestAdTagsIndex(t *testing.T) {
+	require.NoError(t, clearTables(testCtx, testDB))
+	user, err := testDB.CreateUser(testCtx, "tagindex", "pass")
+	require.NoError(t, err)
+
+	const fixtureSize = 20_000
+	ads := make([]Ad, 0, fixtureSize)
+	for i := 0; i < fixtureSize; i++ {
+		ad := batchAd(user.ID, i)
+		ad.Tags = []string{fmt.Sprintf("common%d", i%5)}
+		if i%2000 == 0 {
+			ad.Tags = append(ad.Tags, "редкий")
+		}
+		ads = append(ads, ad)
+	}
+	inserted, err := testDB.CreateAdsBatch(testCtx, ads)
+	require.NoError(t, err)
+	require.EqualValues(t, fixtureSize, inserted)
+	require.NoError(t, testDB.Exec(testCtx, "ANALYZE ads"))
+
+	filter := AdsFilter{MaxPrice: maxPrice, Tags: []string{"редкий"}}
+	count, err := testDB.CountAds(testCtx, filter)
+	require.NoError(t, err)
+	assert.Equal(t, fixtureSize/2000, count)
+
+	where, args, err := adsConditions(filter, nil)
+	require.NoError(t, err)
+	rows, err := testDB.pool.Query(testCtx, "EXPLAIN "+fmt.Sprintf(QueryCountAds, where), args...)
+	require.NoError(t, err)
+	defer rows.Close()
+
+	var plan strings.Builder
+	for rows.Next() {
+		var line string
+		require.NoError(t, rows.Scan(&line))
+		plan.WriteString(line + "\n")
+	}
+	require.NoError(t, rows.Err())
+	assert.Contains(t, plan.String(), "idx_ads_tags", plan.String())
+}
@@ -0,0 +1,125 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	ErrMsgRefreshTokenNotFound = "refresh-токен не найден"
+	ErrMsgRefreshTokenRevoked  = "refresh-токен отозван"
+	ErrMsgRefreshTokenExpired  = "срок действия refresh-токена истёк"
+)
+
+var (
+	ErrRefreshTokenNotFound = newError(ErrMsgRefreshTokenNotFound)
+	ErrRefreshTokenRevoked  = newError(ErrMsgRefreshTokenRevoked)
+	ErrRefreshTokenExpired  = newError(ErrMsgRefreshTokenExpired)
+)
+
+// CreateRefreshToken сохраняет хеш refresh-токена пользователя userID со сроком действия до expiresAt.
+func (s *DBService) CreateRefreshToken(ctx context.Context, userID int, tokenHash string, expiresAt time.Time) error {
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+
+	if _, err := s.pool.Exec(ctx, QueryCreateRefreshToken, userID, tokenHash, expiresAt.UTC()); err != nil {
+		return fmt.Errorf("failed to create refresh token: %w", classifyError(err))
+	}
+	return nil
+}
+
+// RotateRefreshToken отзывает refresh-токен с хешем oldHash и выдаёт вместо него токен с хешем newHash
+// и сроком действия до expiresAt. Возвращает владельца токена.
+// Отозванный и просроченный токены не обмениваются.
+func (s *DBService) RotateRefreshToken(ctx context.Context, oldHash, newHash string, expiresAt time.Time) (int, error) {
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", classifyError(err))
+	}
+	defer tx.Rollback(ctx)
+
+	var (
+		id, userID int
+		expires    time.Time
+		revokedAt  *time.Time
+	)
+	if err := tx.QueryRow(ctx, QueryLockRefreshToken, oldHash).Scan(&id, &userID, &expires, &revokedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, ErrRefreshTokenNotFound
+		}
+		return 0, fmt.Errorf("failed to get refresh token: %w", classifyError(err))
+	}
+	if revokedAt != nil {
+		return 0, ErrRefreshTokenRevoked
+	}
+	if !expires.After(time.Now().UTC()) {
+		return 0, ErrRefreshTokenExpired
+	}
+
+	if _, err := tx.Exec(ctx, QueryRevokeRefreshToken, id); err != nil {
+		return 0, fmt.Errorf("failed to revoke refresh token: %w", classifyError(err))
+	}
+	if _, err := tx.Exec(ctx, QueryCreateRefreshToken, userID, newHash, expiresAt.UTC()); err != nil {
+		return 0, fmt.Errorf("failed to create refresh token: %w", classifyError(err))
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", classifyError(err))
+	}
+	return userID, nil
+}
+
+// RevokeUserRefreshToken отзывает refresh-токен с хешем tokenHash, принадлежащий пользователю userID.
+// Неизвестный или уже отозванный токен не считается ошибкой.
+func (s *DBService) RevokeUserRefreshToken(ctx context.Context, userID int, tokenHash string) error {
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+
+	if _, err := s.pool.Exec(ctx, QueryRevokeUserRefreshToken, tokenHash, userID); err != nil {
+		return fmt.Errorf("failed to revoke refresh token: %w", classifyError(err))
+	}
+	return nil
+}
+
+// RevokeToken вносит JWT-токен с идентификатором jti в список отозванных до истечения его срока expiresAt.
+func (s *DBService) RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+
+	if _, err := s.pool.Exec(ctx, QueryRevokeToken, jti, expiresAt.UTC()); err != nil {
+		return fmt.Errorf("failed to revoke token: %w", classifyError(err))
+	}
+	return nil
+}
+
+// IsTokenRevoked сообщает, отозван ли JWT-токен с идентификатором jti.
+func (s *DBService) IsTokenRevoked(ctx context.Context, jti string) (bool, error) {
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+
+	var revoked bool
+	if err := s.pool.QueryRow(ctx, QueryIsTokenRevoked, jti).Scan(&revoked); err != nil {
+		return false, fmt.Errorf("failed to check token revocation: %w", classifyError(err))
+	}
+	return revoked, nil
+}
+
+// DeleteExpiredRevocations удаляет из списка отозванных токены, истёкшие до before:
+// такие токены и так не проходят проверку. Возвращает количество удалённых записей.
+func (s *DBService) DeleteExpiredRevocations(ctx context.Context, before time.Time) (int64, error) {
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+
+	tag, err := s.pool.Exec(ctx, QueryDeleteExpiredRevocations, before.UTC())
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired revocations: %w", classifyError(err))
+	}
+	return tag.RowsAffected(), nil
+}
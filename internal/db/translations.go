@@ -0,0 +1,75 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+)
+
+const (
+	ErrMsgInvalidLang = "код языка должен состоять из двух строчных латинских букв (ISO 639-1)"
+)
+
+var (
+	ErrInvalidLang = newKindError(ErrInvalid, ErrMsgInvalidLang)
+)
+
+var langCodePattern = regexp.MustCompile(`^[a-z]{2}$`)
+
+// AdTranslation содержит заголовок и текст объявления на дополнительном языке.
+type AdTranslation struct {
+	Title string `json:"title"`
+	Text  string `json:"text"`
+}
+
+// AdTranslations возвращает переводы объявлений adIDs, сгруппированные по ID объявления и коду языка.
+func (s *DBService) AdTranslations(ctx context.Context, adIDs []int) (map[int]map[string]AdTranslation, error) {
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+
+	result := make(map[int]map[string]AdTranslation)
+	if len(adIDs) == 0 {
+		return result, nil
+	}
+
+	rows, err := s.pool.Query(ctx, QueryGetAdTranslations, adIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query translations: %w", classifyError(err))
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var adID int
+		var lang string
+		var tr AdTranslation
+		if err := rows.Scan(&adID, &lang, &tr.Title, &tr.Text); err != nil {
+			return nil, fmt.Errorf("failed to query translations: %w", classifyError(err))
+		}
+		if result[adID] == nil {
+			result[adID] = make(map[string]AdTranslation)
+		}
+		result[adID][lang] = tr
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during rows iteration: %w", classifyError(err))
+	}
+
+	return result, nil
+}
+
+// validateTranslations проверяет коды языков и длину полей переводов.
+func validateTranslations(translations map[string]AdTranslation) error {
+	for lang, tr := range translations {
+		if !langCodePattern.MatchString(lang) {
+			return ErrInvalidLang
+		}
+		if err := ValidateTitle(tr.Title); err != nil {
+			return err
+		}
+		if err := ValidateText(tr.Text); err != nil {
+			return err
+		}
+	}
+	return nil
+}
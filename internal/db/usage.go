@@ -0,0 +1,92 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// UsageDay - количество запросов пользователя к API за день (UTC)
+type UsageDay struct {
+	Date     time.Time `json:"date"`
+	Requests int64     `json:"requests"`
+}
+
+// UsageDelta - прирост счётчика запросов пользователя за день
+type UsageDelta struct {
+	UserID   int
+	Date     time.Time
+	Requests int64
+}
+
+// AddUsage прибавляет приросты к дневным счётчикам запросов одной операцией (UPSERT).
+// Приросты удалённых к этому моменту пользователей отбрасываются.
+func (s *DBService) AddUsage(ctx context.Context, deltas []UsageDelta) error {
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+
+	if len(deltas) == 0 {
+		return nil
+	}
+
+	userIDs := make([]int, 0, len(deltas))
+	dates := make([]time.Time, 0, len(deltas))
+	requests := make([]int64, 0, len(deltas))
+	for _, d := range deltas {
+		userIDs = append(userIDs, d.UserID)
+		dates = append(dates, d.Date)
+		requests = append(requests, d.Requests)
+	}
+
+	if _, err := s.pool.Exec(ctx, QueryAddUsage, userIDs, dates, requests); err != nil {
+		return fmt.Errorf("failed to add usage: %w", classifyError(err))
+	}
+	return nil
+}
+
+// Usage возвращает дневные счётчики запросов пользователя userID с from по to включительно.
+// Дни без запросов не возвращаются.
+func (s *DBService) Usage(ctx context.Context, userID int, from, to time.Time) ([]UsageDay, error) {
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := s.pool.Query(ctx, QueryGetUsage, userID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query usage: %w", classifyError(err))
+	}
+	defer rows.Close()
+
+	days := make([]UsageDay, 0)
+	for rows.Next() {
+		var d UsageDay
+		if err := rows.Scan(&d.Date, &d.Requests); err != nil {
+			return nil, fmt.Errorf("failed to query usage: %w", classifyError(err))
+		}
+		days = append(days, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during rows iteration: %w", classifyError(err))
+	}
+
+	return days, nil
+}
+
+// UsageOn возвращает количество запросов пользователя userID за день date.
+func (s *DBService) UsageOn(ctx context.Context, userID int, date time.Time) (int64, error) {
+	ctx, cancel := s.withQueryTimeout(ctx)
+	defer cancel()
+
+	var requests int64
+	err := s.pool.QueryRow(ctx, QueryGetUsageOn, userID, date).Scan(&requests)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to query usage: %w", classifyError(err))
+	}
+	return requests, nil
+}
@@ -0,0 +1,462 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/YuarenArt/marketgo/internal/db"
+	"github.com/YuarenArt/marketgo/pkg/logging"
+)
+
+const (
+	DefaultOpenSearchIndex = "ads"
+	DefaultBatchSize       = 500
+	DefaultFlushInterval   = time.Second
+	DefaultMaxRetries      = 3
+	DefaultMaxQueue        = 100_000
+
+	retryBaseDelay = 200 * time.Millisecond
+)
+
+var ErrQueueFull = errors.New("search index queue is full")
+
+// adsMapping - схема индекса объявлений, создаётся при первом запуске
+const adsMapping = `{"mappings": ` + adsProperties + `}`
+
+// adsProperties - поля индекса объявлений; новые поля добавляются в существующий индекс через _mapping
+const adsProperties = `{
+  "properties": {
+    "id":         {"type": "integer"},
+    "user_id":    {"type": "integer"},
+    "title":      {"type": "text"},
+    "text":       {"type": "text"},
+    "price":      {"type": "long"},
+    "status":     {"type": "keyword"},
+    "tags":       {"type": "keyword"},
+    "city":       {"type": "keyword"},
+    "created_at": {"type": "date"}
+  }
+}`
+
+// OpenSearchConfig содержит параметры подключения к OpenSearch/Elasticsearch
+type OpenSearchConfig struct {
+	URL      string
+	Index    string
+	Username string
+	Password string
+
+	// BatchSize - максимальное количество операций в одном bulk-запросе
+	BatchSize int
+	// FlushInterval - как часто отправляются накопленные изменения
+	FlushInterval time.Duration
+	// MaxRetries - сколько раз повторять bulk-запрос при сетевой ошибке или перегрузке кластера
+	MaxRetries int
+	// MaxQueue - максимальное количество неотправленных операций
+	MaxQueue int
+}
+
+// adDocument - документ объявления в индексе
+type adDocument struct {
+	ID        int       `json:"id"`
+	UserID    int       `json:"user_id"`
+	Title     string    `json:"title"`
+	Text      string    `json:"text"`
+	Price     int64     `json:"price"`
+	Status    string    `json:"status"`
+	Tags      []string  `json:"tags,omitempty"`
+	City      string    `json:"city,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// bulkOp - операция bulk API; doc == nil означает удаление
+type bulkOp struct {
+	id  int
+	doc *adDocument
+}
+
+// OpenSearch - поисковый индекс в OpenSearch/Elasticsearch.
+// Изменения объявлений накапливаются в очереди и отправляются bulk-запросами в Run.
+type OpenSearch struct {
+	cfg    OpenSearchConfig
+	client *http.Client
+
+	mu     sync.Mutex
+	queue  []bulkOp
+	notify chan struct{}
+}
+
+// NewOpenSearch создает индекс OpenSearch; нулевые параметры заменяются значениями по умолчанию
+func NewOpenSearch(cfg OpenSearchConfig) *OpenSearch {
+	cfg.URL = strings.TrimRight(cfg.URL, "/")
+	if cfg.Index == "" {
+		cfg.Index = DefaultOpenSearchIndex
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = DefaultBatchSize
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = DefaultFlushInterval
+	}
+	if cfg.MaxRetries <= 0 {
+		cfg.MaxRetries = DefaultMaxRetries
+	}
+	if cfg.MaxQueue <= 0 {
+		cfg.MaxQueue = DefaultMaxQueue
+	}
+	return &OpenSearch{
+		cfg:    cfg,
+		client: &http.Client{Timeout: 10 * time.Second},
+		notify: make(chan struct{}, 1),
+	}
+}
+
+// IndexAd ставит объявление в очередь на индексацию
+func (o *OpenSearch) IndexAd(_ context.Context, ad db.Ad) error {
+	return o.enqueue(bulkOp{id: ad.ID, doc: &adDocument{
+		ID:        ad.ID,
+		UserID:    ad.UserID,
+		Title:     ad.Title,
+		Text:      ad.Text,
+		Price:     ad.Price,
+		Status:    ad.Status,
+		Tags:      ad.Tags,
+		City:      strings.ToLower(ad.City),
+		CreatedAt: ad.CreatedAt,
+	}})
+}
+
+// DeleteAd ставит удаление объявления из индекса в очередь
+func (o *OpenSearch) DeleteAd(_ context.Context, adID int) error {
+	return o.enqueue(bulkOp{id: adID})
+}
+
+func (o *OpenSearch) enqueue(op bulkOp) error {
+	o.mu.Lock()
+	if len(o.queue) >= o.cfg.MaxQueue {
+		o.mu.Unlock()
+		return ErrQueueFull
+	}
+	o.queue = append(o.queue, op)
+	full := len(o.queue) >= o.cfg.BatchSize
+	o.mu.Unlock()
+
+	if full {
+		select {
+		case o.notify <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// Run создаёт индекс, если его нет, и затем отправляет накопленные изменения каждые FlushInterval
+// или при заполнении пачки. После отмены контекста очередь отправляется в последний раз.
+func (o *OpenSearch) Run(ctx context.Context, logger logging.Logger) {
+	for {
+		err := o.EnsureIndex(ctx)
+		if err == nil {
+			break
+		}
+		logger.Error("OpenSearch index bootstrap failed", "index", o.cfg.Index, "error", err)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(o.cfg.FlushInterval * 10):
+		}
+	}
+
+	ticker := time.NewTicker(o.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := o.Flush(flushCtx); err != nil {
+				logger.Error("Final OpenSearch flush failed", "error", err)
+			}
+			return
+		case <-ticker.C:
+		case <-o.notify:
+		}
+		if err := o.Flush(ctx); err != nil && ctx.Err() == nil {
+			logger.Error("OpenSearch flush failed", "error", err)
+		}
+	}
+}
+
+// EnsureIndex создаёт индекс с маппингом объявлений, если он ещё не существует,
+// а в существующий индекс добавляет недостающие поля
+func (o *OpenSearch) EnsureIndex(ctx context.Context) error {
+	resp, err := o.do(ctx, http.MethodHead, "/"+o.cfg.Index, "", nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return o.updateMapping(ctx)
+	}
+
+	resp, err = o.do(ctx, http.MethodPut, "/"+o.cfg.Index, "application/json", strings.NewReader(adsMapping))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && !isAlreadyExists(resp) {
+		return responseError("create index", resp)
+	}
+	return nil
+}
+
+// updateMapping добавляет в индекс, созданный прежней версией, поля, появившиеся в adsProperties.
+// Типы существующих полей не меняются.
+func (o *OpenSearch) updateMapping(ctx context.Context) error {
+	resp, err := o.do(ctx, http.MethodPut, "/"+o.cfg.Index+"/_mapping", "application/json", strings.NewReader(adsProperties))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return responseError("update mapping", resp)
+	}
+	return nil
+}
+
+// Flush отправляет все накопленные изменения пачками по BatchSize.
+// Операции, не принятые после MaxRetries попыток, возвращаются в очередь.
+func (o *OpenSearch) Flush(ctx context.Context) error {
+	for {
+		o.mu.Lock()
+		n := min(len(o.queue), o.cfg.BatchSize)
+		batch := o.queue[:n:n]
+		o.queue = o.queue[n:]
+		o.mu.Unlock()
+
+		if len(batch) == 0 {
+			return nil
+		}
+
+		if err := o.bulkWithRetry(ctx, batch); err != nil {
+			return err
+		}
+	}
+}
+
+func (o *OpenSearch) bulkWithRetry(ctx context.Context, batch []bulkOp) error {
+	delay := retryBaseDelay
+	var err error
+	for attempt := 0; attempt < o.cfg.MaxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				o.requeue(batch)
+				return ctx.Err()
+			case <-time.After(delay):
+			}
+			delay *= 2
+		}
+
+		batch, err = o.bulk(ctx, batch)
+		if err == nil && len(batch) == 0 {
+			return nil
+		}
+	}
+	o.requeue(batch)
+	if err == nil {
+		err = fmt.Errorf("bulk indexing: %d operations rejected", len(batch))
+	}
+	return err
+}
+
+// requeue возвращает неотправленные операции в начало очереди, не превышая MaxQueue
+func (o *OpenSearch) requeue(ops []bulkOp) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if free := o.cfg.MaxQueue - len(o.queue); len(ops) > free {
+		ops = ops[:max(free, 0)]
+	}
+	o.queue = append(append([]bulkOp(nil), ops...), o.queue...)
+}
+
+// bulk отправляет операции одним bulk-запросом и возвращает те, которые стоит повторить
+func (o *OpenSearch) bulk(ctx context.Context, ops []bulkOp) ([]bulkOp, error) {
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+	for _, op := range ops {
+		meta := map[string]any{"_index": o.cfg.Index, "_id": strconv.Itoa(op.id)}
+		if op.doc == nil {
+			if err := enc.Encode(map[string]any{"delete": meta}); err != nil {
+				return ops, err
+			}
+			continue
+		}
+		if err := enc.Encode(map[string]any{"index": meta}); err != nil {
+			return ops, err
+		}
+		if err := enc.Encode(op.doc); err != nil {
+			return ops, err
+		}
+	}
+
+	resp, err := o.do(ctx, http.MethodPost, "/_bulk", "application/x-ndjson", &body)
+	if err != nil {
+		return ops, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return ops, responseError("bulk", resp)
+	}
+
+	var result struct {
+		Errors bool                              `json:"errors"`
+		Items  []map[string]struct{ Status int } `json:"items"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return ops, fmt.Errorf("decode bulk response: %w", err)
+	}
+	if !result.Errors {
+		return nil, nil
+	}
+
+	var retry []bulkOp
+	for i, item := range result.Items {
+		for _, r := range item {
+			if (r.Status == http.StatusTooManyRequests || r.Status >= 500) && i < len(ops) {
+				retry = append(retry, ops[i])
+			}
+		}
+	}
+	return retry, nil
+}
+
+// Search ищет объявления по заголовку и тексту с фильтрами db.AdsFilter.
+// Результаты упорядочены по релевантности, при равной релевантности - по возрастанию id.
+func (o *OpenSearch) Search(ctx context.Context, query string, filter db.AdsFilter, page Page) (Result, error) {
+	tags, err := db.NormalizeTags(filter.Tags)
+	if err != nil {
+		return Result{}, err
+	}
+	cities, err := db.NormalizeCityFilter(filter.Cities)
+	if err != nil {
+		return Result{}, err
+	}
+	statuses := filter.Statuses
+	if len(statuses) == 0 {
+		statuses = []string{db.AdStatusActive}
+	}
+	filters := []any{
+		map[string]any{"range": map[string]any{"price": map[string]any{"gte": filter.MinPrice, "lte": filter.MaxPrice}}},
+		map[string]any{"terms": map[string]any{"status": statuses}},
+	}
+	created := map[string]any{}
+	if !filter.CreatedFrom.IsZero() {
+		created["gte"] = filter.CreatedFrom.UTC()
+	}
+	if !filter.CreatedTo.IsZero() {
+		created["lte"] = filter.CreatedTo.UTC()
+	}
+	if len(created) > 0 {
+		filters = append(filters, map[string]any{"range": map[string]any{"created_at": created}})
+	}
+	if filter.SellerID != 0 {
+		filters = append(filters, map[string]any{"term": map[string]any{"user_id": filter.SellerID}})
+	}
+	for _, tag := range tags {
+		filters = append(filters, map[string]any{"term": map[string]any{"tags": tag}})
+	}
+	if len(cities) > 0 {
+		filters = append(filters, map[string]any{"terms": map[string]any{"city": cities}})
+	}
+
+	body, err := json.Marshal(map[string]any{
+		"from":             (page.Page - 1) * page.Size,
+		"size":             page.Size,
+		"track_total_hits": true,
+		"_source":          false,
+		"query": map[string]any{
+			"bool": map[string]any{
+				"must": map[string]any{
+					"multi_match": map[string]any{"query": query, "fields": []string{"title^2", "text"}},
+				},
+				"filter": filters,
+			},
+		},
+		"sort": []any{
+			map[string]any{"_score": "desc"},
+			map[string]any{"id": "asc"},
+		},
+	})
+	if err != nil {
+		return Result{}, err
+	}
+
+	resp, err := o.do(ctx, http.MethodPost, "/"+o.cfg.Index+"/_search", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return Result{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Result{}, responseError("search", resp)
+	}
+
+	var result struct {
+		Hits struct {
+			Total struct {
+				Value int `json:"value"`
+			} `json:"total"`
+			Hits []struct {
+				ID string `json:"_id"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return Result{}, fmt.Errorf("decode search response: %w", err)
+	}
+
+	ids := make([]int, 0, len(result.Hits.Hits))
+	for _, hit := range result.Hits.Hits {
+		id, err := strconv.Atoi(hit.ID)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	return Result{IDs: ids, Total: result.Hits.Total.Value}, nil
+}
+
+func (o *OpenSearch) do(ctx context.Context, method, path, contentType string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, o.cfg.URL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if o.cfg.Username != "" {
+		req.SetBasicAuth(o.cfg.Username, o.cfg.Password)
+	}
+	return o.client.Do(req)
+}
+
+func isAlreadyExists(resp *http.Response) bool {
+	if resp.StatusCode != http.StatusBadRequest {
+		return false
+	}
+	data, _ := io.ReadAll(resp.Body)
+	return bytes.Contains(data, []byte("resource_already_exists_exception"))
+}
+
+func responseError(op string, resp *http.Response) error {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("opensearch %s: status %d: %s", op, resp.StatusCode, bytes.TrimSpace(data))
+}
@@ -0,0 +1,205 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/YuarenArt/marketgo/internal/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+var (
+	testDB         *db.DBService
+	testOpenSearch *OpenSearch
+	testCtx        context.Context
+	cancelFunc     context.CancelFunc
+)
+
+func TestMain(m *testing.M) {
+	testCtx, cancelFunc = context.WithCancel(context.Background())
+	defer cancelFunc()
+
+	postgresC, err := postgres.Run(testCtx,
+		"postgres:15-alpine",
+		postgres.WithDatabase("testdb"),
+		postgres.WithUsername("testuser"),
+		postgres.WithPassword("testpass"),
+		testcontainers.WithWaitStrategy(
+			wait.ForLog("database system is ready to accept connections").
+				WithOccurrence(2).
+				WithStartupTimeout(10*time.Second),
+		),
+	)
+	if err != nil {
+		fmt.Printf("Failed to start PostgreSQL container: %v\n", err)
+		os.Exit(1)
+	}
+
+	dsn, err := postgresC.ConnectionString(testCtx, "sslmode=disable")
+	if err != nil {
+		fmt.Printf("Failed to get connection string: %v\n", err)
+		_ = postgresC.Terminate(testCtx)
+		os.Exit(1)
+	}
+
+	testDB, err = db.NewDBService(testCtx, dsn)
+	if err != nil {
+		fmt.Printf("Failed to create DBService: %v\n", err)
+		_ = postgresC.Terminate(testCtx)
+		os.Exit(1)
+	}
+
+	openSearchC, err := testcontainers.GenericContainer(testCtx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        "opensearchproject/opensearch:2.11.1",
+			ExposedPorts: []string{"9200/tcp"},
+			Env: map[string]string{
+				"discovery.type":          "single-node",
+				"DISABLE_SECURITY_PLUGIN": "true",
+				"OPENSEARCH_JAVA_OPTS":    "-Xms512m -Xmx512m",
+			},
+			WaitingFor: wait.ForHTTP("/").WithPort("9200/tcp").WithStartupTimeout(3 * time.Minute),
+		},
+		Started: true,
+	})
+	if err != nil {
+		fmt.Printf("Failed to start OpenSearch container: %v\n", err)
+		_ = postgresC.Terminate(testCtx)
+		os.Exit(1)
+	}
+
+	endpoint, err := openSearchC.PortEndpoint(testCtx, "9200/tcp", "http")
+	if err != nil {
+		fmt.Printf("Failed to get OpenSearch address: %v\n", err)
+		_ = openSearchC.Terminate(testCtx)
+		_ = postgresC.Terminate(testCtx)
+		os.Exit(1)
+	}
+	testOpenSearch = NewOpenSearch(OpenSearchConfig{URL: endpoint})
+
+	exitCode := m.Run()
+	_ = openSearchC.Terminate(testCtx)
+	_ = postgresC.Terminate(testCtx)
+	os.Exit(exitCode)
+}
+
+// refresh делает проиндексированные документы доступными для поиска
+func refresh(t *testing.T) {
+	t.Helper()
+	resp, err := testOpenSearch.do(testCtx, http.MethodPost, "/"+testOpenSearch.cfg.Index+"/_refresh", "", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestOpenSearch(t *testing.T) {
+	require.NoError(t, testDB.Exec(testCtx, "TRUNCATE TABLE ads, users CASCADE"))
+	require.NoError(t, testOpenSearch.EnsureIndex(testCtx))
+	require.NoError(t, testOpenSearch.EnsureIndex(testCtx), "bootstrap is idempotent")
+
+	seller, err := testDB.CreateUser(testCtx, "searchseller", "pass")
+	require.NoError(t, err)
+
+	seed := []db.Ad{
+		{Title: "Acoustic guitar", Text: "Spruce top, great sound", Price: 15000, Tags: []string{"торг", "самовывоз"}, City: "Москва"},
+		{Title: "Guitar amplifier", Text: "Tube amp for electric guitar", Price: 30000, Tags: []string{"торг"}, City: "Казань"},
+		{Title: "Piano bench", Text: "Fits any piano, guitar stand included", Price: 5000, City: "москва"},
+		{Title: "Drum kit", Text: "Five piece kit", Price: 40000},
+		{Title: "Electric guitar", Text: "Solid body guitar with case", Price: 45000},
+	}
+	var ads []db.Ad
+	for _, ad := range seed {
+		ad.UserID = seller.ID
+		ad.ImageURL = "https://example.com/image.png"
+		created, err := testDB.CreateAd(testCtx, ad)
+		require.NoError(t, err)
+		ads = append(ads, created)
+	}
+	_, err = testDB.SetAdStatus(testCtx, ads[4].ID, seller.ID, db.AdStatusSold)
+	require.NoError(t, err)
+	ads[4].Status = db.AdStatusSold
+
+	for _, ad := range ads {
+		require.NoError(t, testOpenSearch.IndexAd(testCtx, ad))
+	}
+	require.NoError(t, testOpenSearch.Flush(testCtx))
+	refresh(t)
+
+	sqlIndex := NewSQLIndex(testDB)
+	page := Page{Page: 1, Size: 10}
+
+	t.Run("title matches rank above text matches", func(t *testing.T) {
+		result, err := testOpenSearch.Search(testCtx, "guitar", db.AdsFilter{MaxPrice: 100_000_000}, page)
+		require.NoError(t, err)
+		assert.Equal(t, 3, result.Total)
+		require.Len(t, result.IDs, 3)
+		assert.Equal(t, ads[2].ID, result.IDs[2], "text-only match ranks last")
+		assert.ElementsMatch(t, []int{ads[0].ID, ads[1].ID}, result.IDs[:2])
+	})
+
+	t.Run("filter parity with sql", func(t *testing.T) {
+		from := ads[0].CreatedAt.Add(-time.Hour)
+		filters := map[string]db.AdsFilter{
+			"price range":  {MinPrice: 10000, MaxPrice: 35000},
+			"all statuses": {MaxPrice: 100_000_000, Statuses: []string{db.AdStatusActive, db.AdStatusSold}},
+			"sold only":    {MaxPrice: 100_000_000, Statuses: []string{db.AdStatusSold}},
+			"created":      {MaxPrice: 100_000_000, CreatedFrom: from, CreatedTo: time.Now().Add(time.Hour)},
+			"empty range":  {MaxPrice: 100_000_000, CreatedTo: from},
+			"one tag":      {MaxPrice: 100_000_000, Tags: []string{"торг"}},
+			"all tags":     {MaxPrice: 100_000_000, Tags: []string{"Торг", "самовывоз"}},
+			"one city":     {MaxPrice: 100_000_000, Cities: []string{"МОСКВА"}},
+			"any city":     {MaxPrice: 100_000_000, Cities: []string{"москва", " Казань "}},
+		}
+		for name, filter := range filters {
+			t.Run(name, func(t *testing.T) {
+				want, err := sqlIndex.Search(testCtx, "guitar", filter, page)
+				require.NoError(t, err)
+				got, err := testOpenSearch.Search(testCtx, "guitar", filter, page)
+				require.NoError(t, err)
+
+				assert.Equal(t, want.Total, got.Total)
+				assert.Equal(t, sorted(want.IDs), sorted(got.IDs))
+			})
+		}
+	})
+
+	t.Run("pagination", func(t *testing.T) {
+		first, err := testOpenSearch.Search(testCtx, "guitar", db.AdsFilter{MaxPrice: 100_000_000}, Page{Page: 1, Size: 2})
+		require.NoError(t, err)
+		second, err := testOpenSearch.Search(testCtx, "guitar", db.AdsFilter{MaxPrice: 100_000_000}, Page{Page: 2, Size: 2})
+		require.NoError(t, err)
+		assert.Len(t, first.IDs, 2)
+		assert.Len(t, second.IDs, 1)
+		assert.Equal(t, 3, second.Total)
+		assert.NotContains(t, first.IDs, second.IDs[0])
+	})
+
+	t.Run("updates and deletes reach the index", func(t *testing.T) {
+		updated := ads[3]
+		updated.Title = "Drum kit with guitar pick"
+		require.NoError(t, testOpenSearch.IndexAd(testCtx, updated))
+		require.NoError(t, testOpenSearch.DeleteAd(testCtx, ads[0].ID))
+		require.NoError(t, testOpenSearch.Flush(testCtx))
+		refresh(t)
+
+		result, err := testOpenSearch.Search(testCtx, "guitar", db.AdsFilter{MaxPrice: 100_000_000}, page)
+		require.NoError(t, err)
+		assert.Contains(t, result.IDs, updated.ID)
+		assert.NotContains(t, result.IDs, ads[0].ID)
+	})
+}
+
+func sorted(ids []int) []int {
+	out := append([]int{}, ids...)
+	sort.Ints(out)
+	return out
+}
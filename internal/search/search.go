@@ -0,0 +1,69 @@
+// Package search содержит интерфейс поискового индекса объявлений и его реализации.
+package search
+
+import (
+	"context"
+
+	"github.com/YuarenArt/marketgo/internal/db"
+	"github.com/YuarenArt/marketgo/pkg/logging"
+)
+
+const (
+	BackendSQL        = "sql"
+	BackendOpenSearch = "opensearch"
+)
+
+// Page задаёт страницу результатов поиска, Page начинается с 1
+type Page struct {
+	Page int
+	Size int
+}
+
+// Result - ID найденных объявлений по убыванию релевантности и общее количество найденных
+type Result struct {
+	IDs   []int
+	Total int
+}
+
+// Index - поисковый индекс объявлений.
+// IndexAd и DeleteAd вызываются при изменении объявлений; реализации могут применять их асинхронно.
+type Index interface {
+	IndexAd(ctx context.Context, ad db.Ad) error
+	DeleteAd(ctx context.Context, adID int) error
+	Search(ctx context.Context, query string, filter db.AdsFilter, page Page) (Result, error)
+}
+
+// Runner реализуется индексами, которым нужна фоновая задача, например отправка изменений пачками
+type Runner interface {
+	Run(ctx context.Context, logger logging.Logger)
+}
+
+// AdSearcher выполняет полнотекстовый поиск по объявлениям; реализуется *db.DBService
+type AdSearcher interface {
+	SearchAds(ctx context.Context, query string, filter db.AdsFilter, limit, offset int) ([]int, int, error)
+}
+
+// SQLIndex ищет полнотекстовым поиском PostgreSQL по таблице ads; отдельный индекс не ведётся
+type SQLIndex struct {
+	db AdSearcher
+}
+
+// NewSQLIndex создает поисковый индекс поверх базы данных
+func NewSQLIndex(db AdSearcher) *SQLIndex {
+	return &SQLIndex{db: db}
+}
+
+// IndexAd ничего не делает: поиск выполняется по актуальным данным таблицы
+func (i *SQLIndex) IndexAd(context.Context, db.Ad) error { return nil }
+
+// DeleteAd ничего не делает: удалённые объявления исключаются запросом
+func (i *SQLIndex) DeleteAd(context.Context, int) error { return nil }
+
+// Search выполняет полнотекстовый поиск через DBService.SearchAds
+func (i *SQLIndex) Search(ctx context.Context, query string, filter db.AdsFilter, page Page) (Result, error) {
+	ids, total, err := i.db.SearchAds(ctx, query, filter, page.Size, (page.Page-1)*page.Size)
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{IDs: ids, Total: total}, nil
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCORSMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	serve := func(allowed []string, method, origin string) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.Use((&Server{}).corsMiddleware(allowed))
+		router.GET("/ads", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+		req := httptest.NewRequest(method, "/ads", nil)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		if method == http.MethodOptions {
+			req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	allowed := []string{"https://shop.example.com", "http://localhost:3000/"}
+
+	t.Run("allowed origin is echoed", func(t *testing.T) {
+		w := serve(allowed, http.MethodGet, "https://shop.example.com")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "https://shop.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Contains(t, w.Header().Get("Access-Control-Expose-Headers"), "X-Total-Count")
+		assert.Equal(t, "Origin", w.Header().Get("Vary"))
+	})
+
+	t.Run("configured trailing slash is ignored", func(t *testing.T) {
+		w := serve(allowed, http.MethodGet, "http://localhost:3000")
+		assert.Equal(t, "http://localhost:3000", w.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("disallowed origin gets no CORS headers", func(t *testing.T) {
+		w := serve(allowed, http.MethodGet, "https://evil.example.com")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "Origin", w.Header().Get("Vary"))
+	})
+
+	t.Run("preflight from allowed origin", func(t *testing.T) {
+		w := serve(allowed, http.MethodOptions, "https://shop.example.com")
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, "https://shop.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Contains(t, w.Header().Get("Access-Control-Allow-Headers"), "Authorization")
+		assert.Contains(t, w.Header().Get("Access-Control-Allow-Headers"), "X-Auth-Token")
+		assert.Contains(t, w.Header().Get("Access-Control-Allow-Methods"), http.MethodPatch)
+	})
+
+	t.Run("preflight from disallowed origin is forbidden", func(t *testing.T) {
+		w := serve(allowed, http.MethodOptions, "https://evil.example.com")
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("wildcard allows any origin", func(t *testing.T) {
+		w := serve([]string{"*"}, http.MethodGet, "https://anything.example.com")
+		assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("empty list disables CORS", func(t *testing.T) {
+		w := serve(nil, http.MethodGet, "https://shop.example.com")
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+	})
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/YuarenArt/marketgo/internal/db"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubModerator struct {
+	err     error
+	removed []int
+	by      []int
+}
+
+func (m *stubModerator) RemoveAd(_ context.Context, adID, moderatorID int) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.removed = append(m.removed, adID)
+	m.by = append(m.by, moderatorID)
+	return nil
+}
+
+func TestRemoveAd(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	serve := func(moderator *stubModerator, role, id string) *httptest.ResponseRecorder {
+		h, err := NewHandler()
+		require.NoError(t, err)
+		h.adModerator = moderator
+
+		router := gin.New()
+		router.DELETE("/admin/ads/:id",
+			func(c *gin.Context) {
+				c.Set("userID", 7)
+				c.Set("role", role)
+			},
+			h.RequireRole(db.RoleAdmin),
+			h.RemoveAd,
+		)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/admin/ads/"+id, nil))
+		return w
+	}
+
+	t.Run("admin removes any ad", func(t *testing.T) {
+		moderator := &stubModerator{}
+		w := serve(moderator, db.RoleAdmin, "42")
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, []int{42}, moderator.removed)
+		assert.Equal(t, []int{7}, moderator.by)
+	})
+
+	t.Run("unknown ad returns 404", func(t *testing.T) {
+		w := serve(&stubModerator{err: db.ErrAdNotFound}, db.RoleAdmin, "42")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.JSONEq(t, `{"code":"not_found","message":"`+db.ErrMsgAdNotFound+`"}`, w.Body.String())
+	})
+
+	t.Run("invalid id returns 400", func(t *testing.T) {
+		moderator := &stubModerator{}
+		w := serve(moderator, db.RoleAdmin, "abc")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Empty(t, moderator.removed)
+	})
+
+	t.Run("non-admin gets 403", func(t *testing.T) {
+		moderator := &stubModerator{}
+		w := serve(moderator, db.RoleUser, "42")
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Empty(t, moderator.removed)
+	})
+}
@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"github.com/YuarenArt/marketgo/pkg/logging"
+	"github.com/gin-gonic/gin"
+)
+
+// WithAuditLogger включает журнал аудита событий безопасности; nil отключает его
+func WithAuditLogger(a *logging.AuditLogger) HandlerOption {
+	return func(h *Handler) error {
+		h.auditLogger = a
+		return nil
+	}
+}
+
+// audit записывает event в журнал аудита, дополняя его IP клиента и идентификатором запроса
+func (h *Handler) audit(c *gin.Context, event logging.AuditEvent) {
+	event.IP = c.ClientIP()
+	event.RequestID = c.GetString(RequestIDKey)
+	h.auditLogger.Log(event)
+}
+
+// auditLoginFailure записывает неудачный вход с причиной reason
+func (h *Handler) auditLoginFailure(c *gin.Context, login, reason string) {
+	h.audit(c, logging.AuditEvent{Event: logging.AuditLoginFailure, Login: login, Details: map[string]any{"reason": reason}})
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/YuarenArt/marketgo/internal/db"
+	"github.com/YuarenArt/marketgo/internal/db/dbtest"
+	"github.com/YuarenArt/marketgo/internal/server/services"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// revocationOutageStore - хранилище, в котором недоступен список отозванных токенов
+type revocationOutageStore struct {
+	*dbtest.Store
+}
+
+func (revocationOutageStore) IsTokenRevoked(context.Context, string) (bool, error) {
+	return false, fmt.Errorf("failed to check token revocation: %w", db.ErrUnavailable)
+}
+
+// userOutageStore - хранилище, в котором недоступна проверка существования пользователя
+type userOutageStore struct {
+	*dbtest.Store
+}
+
+func (userOutageStore) UserByID(context.Context, int) (db.User, error) {
+	return db.User{}, fmt.Errorf("failed to get user: %w", db.ErrUnavailable)
+}
+
+func TestAuthMiddlewareDatabaseOutage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+
+	store := dbtest.NewStore()
+	input := services.InputUserInfo{Login: "outageuser", Password: "password123"}
+	authService := services.NewAuthService(store, "test-secret", 0, bcrypt.MinCost)
+	_, err := authService.Register(ctx, input)
+	require.NoError(t, err)
+	pair, err := authService.Authenticate(ctx, input)
+	require.NoError(t, err)
+
+	serveWith := func(storage services.UserStorage, availability *services.Availability) *httptest.ResponseRecorder {
+		h, err := NewHandler()
+		require.NoError(t, err)
+		h.availability = availability
+		h.authService = services.NewAuthService(storage, "test-secret", 0, bcrypt.MinCost)
+		h.authService.UseAvailability(availability)
+
+		router := gin.New()
+		router.GET("/me", h.AuthMiddleware(), h.AvailabilityMiddleware(), func(c *gin.Context) { c.Status(http.StatusNoContent) })
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		req.Header.Set(AuthHeader, pair.Token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	serve := func(storage services.UserStorage) *httptest.ResponseRecorder {
+		return serveWith(storage, nil)
+	}
+
+	t.Run("token passes with available database", func(t *testing.T) {
+		assert.Equal(t, http.StatusNoContent, serve(store).Code)
+	})
+
+	t.Run("unverifiable revocation is rejected", func(t *testing.T) {
+		w := serve(revocationOutageStore{store})
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.JSONEq(t, `{"code":"unavailable","message":"`+ErrServiceUnavailable+`"}`, w.Body.String())
+	})
+
+	t.Run("unverifiable user is rejected", func(t *testing.T) {
+		w := serve(userOutageStore{store})
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.JSONEq(t, `{"code":"unavailable","message":"`+ErrServiceUnavailable+`"}`, w.Body.String())
+	})
+
+	t.Run("known outage reaches degraded mode", func(t *testing.T) {
+		availability := services.NewAvailability(func(context.Context) error { return nil })
+		for i := 0; i < services.AvailabilityFailThreshold; i++ {
+			availability.Observe(db.ErrUnavailable)
+		}
+		require.False(t, availability.Available())
+
+		w := serveWith(revocationOutageStore{store}, availability)
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Equal(t, retryAfterSeconds, w.Header().Get("Retry-After"), "request is rejected by AvailabilityMiddleware, not by auth")
+	})
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BodyLimitMiddleware ограничивает тело запроса limit байтами; limit <= 0 отключает ограничение.
+// Запрос с большим Content-Length сразу получает 413 без чтения тела; тело без длины
+// читается через http.MaxBytesReader, и обработчик отвечает 413 через abortWithBindError.
+// В обоих случаях сервер закрывает соединение, не дочитывая остаток тела.
+// Маршруты skipRoutes (шаблоны, как в c.FullPath()) не ограничиваются: для них подключается свой лимит.
+func (h *Handler) BodyLimitMiddleware(limit int64, skipRoutes ...string) gin.HandlerFunc {
+	skip := make(map[string]bool, len(skipRoutes))
+	for _, route := range skipRoutes {
+		skip[route] = true
+	}
+
+	return func(c *gin.Context) {
+		if limit <= 0 || c.Request.Body == nil || skip[c.FullPath()] {
+			c.Next()
+			return
+		}
+
+		if c.Request.ContentLength > limit {
+			h.logger.Warn("Request body too large", "path", c.Request.URL.Path, "content_length", c.Request.ContentLength, "limit", limit)
+			c.Header("Connection", "close")
+			abortWithError(c, http.StatusRequestEntityTooLarge, ErrBodyTooLarge)
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+		c.Next()
+	}
+}
+
+// DecompressMiddleware распаковывает тело запроса с Content-Encoding: gzip, чтобы обработчики
+// читали JSON как обычно. Распакованное тело тоже ограничено limit байтами (limit <= 0 - без ограничения),
+// поэтому сжатая «бомба» получает 413 так же, как большое несжатое тело.
+// Тело с повреждённым заголовком gzip сразу получает 400; ошибки дальше в потоке обработчик
+// получает при разборе тела и отвечает 400 через abortWithBindError.
+func (h *Handler) DecompressMiddleware(limit int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Body == nil || !strings.EqualFold(strings.TrimSpace(c.GetHeader("Content-Encoding")), "gzip") {
+			c.Next()
+			return
+		}
+
+		zr, err := gzip.NewReader(c.Request.Body)
+		if err != nil {
+			h.logger.Warn("Malformed gzip request body", "path", c.Request.URL.Path, "error", err)
+			abortWithError(c, http.StatusBadRequest, ErrMalformedGzip)
+			return
+		}
+		defer zr.Close()
+
+		var body io.ReadCloser = io.NopCloser(zr)
+		if limit > 0 {
+			body = http.MaxBytesReader(c.Writer, body, limit)
+		}
+		c.Request.Body = body
+		c.Request.Header.Del("Content-Encoding")
+		c.Request.Header.Del("Content-Length")
+		c.Request.ContentLength = -1
+		c.Next()
+	}
+}
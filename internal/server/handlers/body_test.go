@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/YuarenArt/marketgo/internal/client"
+	"github.com/YuarenArt/marketgo/internal/db/dbtest"
+	"github.com/YuarenArt/marketgo/internal/server/services"
+	"github.com/YuarenArt/marketgo/pkg/logging"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBodyLimitMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const limit = 1024
+
+	h, err := NewHandler()
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.Use(h.BodyLimitMiddleware(limit))
+	router.POST("/echo", func(c *gin.Context) {
+		var req struct {
+			Text string `json:"text"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			abortWithBindError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"length": len(req.Text)})
+	})
+
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	payload := func(size int) string {
+		return `{"text":"` + strings.Repeat("a", size) + `"}`
+	}
+
+	post := func(t *testing.T, body io.Reader) *http.Response {
+		resp, err := http.Post(srv.URL+"/echo", "application/json", body)
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+
+	assertTooLarge := func(t *testing.T, resp *http.Response) {
+		assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+		assert.True(t, resp.Close, "connection must be closed instead of reading the rest of the body")
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"code":"body_too_large","message":"`+ErrBodyTooLarge+`"}`, string(body))
+	}
+
+	t.Run("body within limit is accepted", func(t *testing.T) {
+		resp := post(t, strings.NewReader(payload(100)))
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.False(t, resp.Close)
+	})
+
+	t.Run("oversized content length is rejected before reading", func(t *testing.T) {
+		assertTooLarge(t, post(t, strings.NewReader(payload(10*limit))))
+	})
+
+	t.Run("oversized chunked body is rejected", func(t *testing.T) {
+		// io.MultiReader скрывает размер тела, и клиент отправляет его без Content-Length
+		assertTooLarge(t, post(t, io.MultiReader(strings.NewReader(payload(10*limit)))))
+	})
+}
+
+func TestDecompressMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const limit = 4096
+
+	store := dbtest.NewStore()
+	user, err := store.CreateUser(context.Background(), "seller", "hash")
+	require.NoError(t, err)
+
+	h, err := NewHandler()
+	require.NoError(t, err)
+	h.adService = services.NewAdService(store)
+
+	var encoding string
+	router := gin.New()
+	router.Use(func(c *gin.Context) { encoding = c.GetHeader("Content-Encoding") },
+		h.BodyLimitMiddleware(limit), h.DecompressMiddleware(limit))
+	router.POST("/api/v1/ads", func(c *gin.Context) { c.Set("userID", user.ID) }, h.CreateAd)
+
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	post := func(t *testing.T, body []byte) *http.Response {
+		req, err := http.NewRequest(http.MethodPost, srv.URL+"/api/v1/ads", bytes.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Content-Encoding", "gzip")
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+
+	compress := func(t *testing.T, data string) []byte {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		_, err := zw.Write([]byte(data))
+		require.NoError(t, err)
+		require.NoError(t, zw.Close())
+		return buf.Bytes()
+	}
+
+	t.Run("client round trip with compressed create-ad", func(t *testing.T) {
+		c := client.NewClient(srv.URL, logging.NewLogger(nil), client.WithRequestCompression(256))
+		text := strings.Repeat("Горный велосипед. ", 100) + "Торг"
+		ad, err := c.PostAdd(context.Background(), &services.CreateAdRequest{
+			Title: "Велосипед", Text: text, ImageURL: "https://example.com/1.jpg", Price: 300,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "gzip", encoding)
+		assert.Equal(t, text, ad.Text)
+	})
+
+	t.Run("short bodies are sent uncompressed", func(t *testing.T) {
+		c := client.NewClient(srv.URL, logging.NewLogger(nil), client.WithRequestCompression(4096))
+		_, err := c.PostAdd(context.Background(), &services.CreateAdRequest{
+			Title: "Самокат", Text: "Детский", ImageURL: "https://example.com/2.jpg", Price: 100,
+		})
+		require.NoError(t, err)
+		assert.Empty(t, encoding)
+	})
+
+	t.Run("malformed gzip returns 400", func(t *testing.T) {
+		resp := post(t, []byte(`{"title": "not gzip"}`))
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"code":"invalid_input","message":"`+ErrMalformedGzip+`"}`, string(body))
+	})
+
+	t.Run("truncated gzip returns 400", func(t *testing.T) {
+		data := compress(t, `{"title": "Велосипед", "text": "Горный", "image_url": "https://example.com/1.jpg", "price": 300}`)
+		resp := post(t, data[:len(data)/2])
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("decompressed body is limited", func(t *testing.T) {
+		data := compress(t, `{"text":"`+strings.Repeat("a", 100*limit)+`"}`)
+		require.Less(t, len(data), limit)
+		resp := post(t, data)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+	})
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/YuarenArt/marketgo/internal/db"
+)
+
+// Результаты входа для метрики logins_total
+const (
+	loginResultSuccess = "success"
+	loginResultError   = "error"
+)
+
+// Причины неудачного создания объявления для метрики ads_create_failures_total
+const (
+	adFailureInvalidInput = "invalid_input"
+	adFailureTimeout      = "timeout"
+	adFailureUnavailable  = "unavailable"
+	adFailureInternal     = "internal"
+)
+
+// adCreateFailureReasons - причины по ошибкам-сентинелам db; набор значений ограничен,
+// поэтому метка не порождает неограниченного числа серий
+var adCreateFailureReasons = []struct {
+	err    error
+	reason string
+}{
+	{db.ErrInvalidPrice, "invalid_price"},
+	{db.ErrInvalidTitleLength, "invalid_title"},
+	{db.ErrInvalidTextLength, "invalid_text"},
+	{db.ErrInvalidImageURL, "invalid_image_url"},
+	{db.ErrInvalidUserID, "invalid_user_id"},
+	{db.ErrInvalidLang, "invalid_lang"},
+	{db.ErrUserNotFound, "user_not_found"},
+}
+
+// adCreateFailureReason возвращает причину неудачного создания объявления для метрики
+func adCreateFailureReason(err error) string {
+	for _, r := range adCreateFailureReasons {
+		if errors.Is(err, r.err) {
+			return r.reason
+		}
+	}
+	switch {
+	case db.IsTimeout(err):
+		return adFailureTimeout
+	case errors.Is(err, db.ErrUnavailable):
+		return adFailureUnavailable
+	case errors.Is(err, db.ErrInvalid):
+		return adFailureInvalidInput
+	default:
+		return adFailureInternal
+	}
+}
+
+func (h *Handler) observeRegistration() {
+	if h.metrics != nil {
+		h.metrics.UsersRegistered.Inc()
+	}
+}
+
+func (h *Handler) observeAdCreated() {
+	if h.metrics != nil {
+		h.metrics.AdsCreated.Inc()
+	}
+}
+
+func (h *Handler) observeAdCreateFailure(reason string) {
+	if h.metrics != nil {
+		h.metrics.AdsCreateFailures.WithLabelValues(reason).Inc()
+	}
+}
+
+func (h *Handler) observeAdsExpired(n int) {
+	if h.metrics != nil {
+		h.metrics.AdsExpired.Add(float64(n))
+	}
+}
+
+func (h *Handler) observeLogin(result string) {
+	if h.metrics != nil {
+		h.metrics.LoginCount.WithLabelValues(result).Inc()
+	}
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/YuarenArt/marketgo/internal/db/dbtest"
+	"github.com/YuarenArt/marketgo/internal/server/services"
+	"github.com/YuarenArt/marketgo/pkg/metrics"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestBusinessMetrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	store := dbtest.NewStore()
+	authService := services.NewAuthService(store, "secret", time.Minute, bcrypt.MinCost)
+	reg := prometheus.NewRegistry()
+
+	h, err := NewHandler(WithMetrics(metrics.NewMetrics(reg)))
+	require.NoError(t, err)
+	h.authService = authService
+	h.registrar = authService
+	h.adService = services.NewAdService(store)
+
+	router := gin.New()
+	router.POST("/register", h.Register)
+	router.POST("/login", h.Login)
+	router.POST("/ads/as/:user", func(c *gin.Context) {
+		if c.Param("user") == "ghost" {
+			c.Set("userID", 999)
+		} else {
+			c.Set("userID", 1)
+		}
+	}, h.CreateAd)
+
+	post := func(path, body string) int {
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	const ad = `{"title": "Велосипед", "text": "Горный", "image_url": "https://example.com/1.jpg", "price": 300}`
+
+	require.Equal(t, http.StatusOK, post("/register", `{"login": "metrics", "password": "s3cure-horse7"}`))
+	require.Equal(t, http.StatusOK, post("/login", `{"login": "metrics", "password": "s3cure-horse7"}`))
+	require.Equal(t, http.StatusUnauthorized, post("/login", `{"login": "metrics", "password": "wrong-horse7"}`))
+	require.Equal(t, http.StatusOK, post("/ads/as/owner", ad))
+	require.Equal(t, http.StatusOK, post("/ads/as/owner", ad))
+	require.Equal(t, http.StatusBadRequest, post("/ads/as/owner", `{"title": "Велосипед"}`))
+	require.Equal(t, http.StatusNotFound, post("/ads/as/ghost", ad))
+
+	expected := `
+# HELP ads_create_failures_total Количество неудачных попыток создать объявление по причине
+# TYPE ads_create_failures_total counter
+ads_create_failures_total{reason="invalid_input"} 1
+ads_create_failures_total{reason="user_not_found"} 1
+# HELP ads_created_total Количество созданных объявлений
+# TYPE ads_created_total counter
+ads_created_total 2
+# HELP logins_total Количество попыток входа по результату
+# TYPE logins_total counter
+logins_total{result="invalid_credentials"} 1
+logins_total{result="success"} 1
+# HELP users_registered_total Количество зарегистрированных пользователей
+# TYPE users_registered_total counter
+users_registered_total 1
+`
+	assert.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(expected),
+		"ads_create_failures_total", "ads_created_total", "logins_total", "users_registered_total"))
+}
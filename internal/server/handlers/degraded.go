@@ -0,0 +1,272 @@
+package handlers
+
+import (
+	"bytes"
+	"container/list"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/YuarenArt/marketgo/internal/server/services"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	ErrServiceUnavailable = "service temporarily unavailable"
+
+	// StaleWarning - значение заголовка Warning для ответов из кэша при недоступной базе данных
+	StaleWarning = `110 - "Response is Stale"`
+
+	// staleMaxBody - ответы длиннее не копируются в кэш
+	staleMaxBody = 1 << 20
+)
+
+// retryAfterSeconds - через сколько секунд клиенту стоит повторить запрос при недоступной базе данных
+var retryAfterSeconds = strconv.Itoa(int((services.AvailabilityCheckInterval * services.AvailabilityRecoverThreshold).Seconds()))
+
+type staleEntry struct {
+	key         string
+	contentType string
+	body        []byte
+}
+
+// staleCache хранит последние успешные ответы на GET-запросы для отдачи при недоступной базе данных.
+// При переполнении вытесняются давно не запрашивавшиеся ответы.
+type staleCache struct {
+	size int
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[string]*list.Element
+}
+
+func newStaleCache(size int) *staleCache {
+	return &staleCache{size: size, order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+func (s *staleCache) get(key string) (staleEntry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	el, ok := s.entries[key]
+	if !ok {
+		return staleEntry{}, false
+	}
+	s.order.MoveToFront(el)
+	return el.Value.(staleEntry), true
+}
+
+func (s *staleCache) put(entry staleEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if el, ok := s.entries[entry.key]; ok {
+		el.Value = entry
+		s.order.MoveToFront(el)
+		return
+	}
+	s.entries[entry.key] = s.order.PushFront(entry)
+	if s.order.Len() > s.size {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.entries, oldest.Value.(staleEntry).key)
+	}
+}
+
+// recordingWriter копирует тело ответа, чтобы сохранить его в staleCache.
+// Копирование прекращается, когда тело превышает staleMaxBody.
+type recordingWriter struct {
+	gin.ResponseWriter
+	body     bytes.Buffer
+	overflow bool
+}
+
+func (w *recordingWriter) Write(data []byte) (int, error) {
+	w.record(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.record([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Unwrap возвращает исходный ResponseWriter для http.ResponseController
+func (w *recordingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *recordingWriter) record(data []byte) {
+	if w.overflow {
+		return
+	}
+	if w.body.Len()+len(data) > staleMaxBody {
+		w.overflow = true
+		w.body = bytes.Buffer{}
+		return
+	}
+	w.body.Write(data)
+}
+
+// WithAvailability включает деградированный режим по состоянию трекера доступности базы данных
+func WithAvailability(availability *services.Availability) HandlerOption {
+	return func(h *Handler) error {
+		h.availability = availability
+		return nil
+	}
+}
+
+// WithStaleCache включает кэш последних ответов на GET-запросы размером size,
+// из которого отдаются ответы, пока база данных недоступна
+func WithStaleCache(size int) HandlerOption {
+	return func(h *Handler) error {
+		if size > 0 {
+			h.staleCache = newStaleCache(size)
+		}
+		return nil
+	}
+}
+
+// AvailabilityMiddleware переводит запросы в деградированный режим, пока база данных недоступна:
+// GET-запросы получают последний сохранённый ответ с заголовком Warning и полем stale (см. markStale),
+// остальные сразу завершаются 503 с Retry-After, не дожидаясь соединения из пула.
+func (h *Handler) AvailabilityMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if h.availability == nil {
+			c.Next()
+			return
+		}
+
+		// выгрузка CSV потоковая и слишком велика для кэша
+		cacheable := h.staleCache != nil && c.Request.Method == http.MethodGet && !strings.HasSuffix(c.FullPath(), exportRoute)
+		if h.availability.Available() {
+			if !cacheable {
+				c.Next()
+				return
+			}
+
+			w := &recordingWriter{ResponseWriter: c.Writer}
+			c.Writer = w
+			c.Next()
+			if w.Status() == http.StatusOK && !w.overflow {
+				h.staleCache.put(staleEntry{
+					key:         staleKey(c),
+					contentType: w.Header().Get("Content-Type"),
+					body:        w.body.Bytes(),
+				})
+			}
+			return
+		}
+
+		if cacheable {
+			if entry, ok := h.staleCache.get(staleKey(c)); ok {
+				h.logger.Warn("Serving stale response", "path", c.Request.URL.Path)
+				c.Header("Warning", StaleWarning)
+				c.Data(http.StatusOK, entry.contentType, markStale(entry))
+				c.Abort()
+				return
+			}
+		}
+
+		c.Header("Retry-After", retryAfterSeconds)
+		abortWithError(c, http.StatusServiceUnavailable, ErrServiceUnavailable)
+	}
+}
+
+// staleKey идентифицирует ответ по пользователю, адресу и предпочитаемым языкам
+func staleKey(c *gin.Context) string {
+	userID, _ := c.Get("userID")
+	return fmt.Sprintf("%v|%s|%s", userID, c.Request.URL.RequestURI(), c.GetHeader("Accept-Language"))
+}
+
+// markStale добавляет поле "stale": true в JSON-объект ответа, а JSON-массив оборачивает
+// в {"stale": true, "items": [...]}, как при include_meta; остальные ответы не меняются
+func markStale(entry staleEntry) []byte {
+	body := bytes.TrimSpace(entry.body)
+	if !strings.HasPrefix(entry.contentType, "application/json") || len(body) == 0 {
+		return entry.body
+	}
+
+	switch body[0] {
+	case '[':
+		out := append([]byte(`{"stale":true,"items":`), body...)
+		return append(out, '}')
+	case '{':
+		rest := bytes.TrimSpace(body[1:])
+		out := []byte(`{"stale":true`)
+		if len(rest) > 0 && rest[0] != '}' {
+			out = append(out, ',')
+		}
+		return append(out, rest...)
+	default:
+		return entry.body
+	}
+}
+
+// Ready сообщает о готовности сервиса принимать запросы
+// @Summary Проверка готовности
+// @Description Возвращает 503, пока база данных считается недоступной
+// @Tags health
+// @Produce json
+// @Success 200 {object} map[string]string
+// @Failure 503 {object} map[string]string
+// @Router /ready [get]
+func (h *Handler) Ready(c *gin.Context) {
+	if h.availability != nil && !h.availability.Available() {
+		c.Header("Retry-After", retryAfterSeconds)
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "unavailable"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ready"})
+}
+
+// DependencyStatus - состояние зависимости в ответе /readyz
+type DependencyStatus struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// ReadinessResponse - ответ /readyz: общее состояние и состояние каждой зависимости
+type ReadinessResponse struct {
+	Status       string                      `json:"status"`
+	Dependencies map[string]DependencyStatus `json:"dependencies"`
+}
+
+// Healthz сообщает, что процесс жив; зависимости не проверяются
+// @Summary Проверка живости
+// @Description Всегда возвращает 200, пока процесс обрабатывает запросы
+// @Tags health
+// @Produce json
+// @Success 200 {object} map[string]string
+// @Router /healthz [get]
+func (h *Handler) Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Readyz проверяет зависимости сервиса и сообщает, готов ли он принимать запросы
+// @Summary Проверка готовности зависимостей
+// @Description Выполняет запрос к базе данных; 503, если хотя бы одна зависимость не отвечает
+// @Tags health
+// @Produce json
+// @Success 200 {object} ReadinessResponse
+// @Failure 503 {object} ReadinessResponse
+// @Router /readyz [get]
+func (h *Handler) Readyz(c *gin.Context) {
+	resp := ReadinessResponse{Status: "ready", Dependencies: map[string]DependencyStatus{}}
+	if h.dbHealth != nil {
+		dep := DependencyStatus{Status: "up"}
+		if err := h.dbHealth.Health(c.Request.Context()); err != nil {
+			h.logger.Warn("Readiness: database health check failed", "error", err)
+			dep = DependencyStatus{Status: "down", Error: err.Error()}
+			resp.Status = "unavailable"
+		}
+		resp.Dependencies["database"] = dep
+	}
+
+	if resp.Status != "ready" {
+		c.Header("Retry-After", retryAfterSeconds)
+		c.JSON(http.StatusServiceUnavailable, resp)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
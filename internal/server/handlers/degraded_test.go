@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/YuarenArt/marketgo/internal/db"
+	"github.com/YuarenArt/marketgo/internal/db/dbtest"
+	"github.com/YuarenArt/marketgo/internal/server/services"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDegradedMode(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+
+	store := dbtest.NewStore()
+	seller, err := store.CreateUser(ctx, "seller", "hash")
+	require.NoError(t, err)
+	bike, err := store.CreateAd(ctx, db.Ad{Title: "Велосипед", Text: "Горный", ImageURL: "https://example.com/1.jpg", Price: 300, UserID: seller.ID})
+	require.NoError(t, err)
+	_, err = store.CreateAd(ctx, db.Ad{Title: "Диван", Text: "Угловой", ImageURL: "https://example.com/2.jpg", Price: 500, UserID: seller.ID})
+	require.NoError(t, err)
+
+	var pingFails atomic.Bool
+	availability := services.NewAvailability(func(context.Context) error {
+		if pingFails.Load() {
+			return db.ErrUnavailable
+		}
+		return nil
+	})
+
+	h, err := NewHandler()
+	require.NoError(t, err)
+	h.adService = services.NewAdService(store)
+	h.availability = availability
+	h.staleCache = newStaleCache(10)
+
+	auth := func(c *gin.Context) { c.Set("userID", seller.ID) }
+	router := gin.New()
+	router.GET("/ads", auth, h.AvailabilityMiddleware(), h.Ads)
+	router.GET("/ads/:id", auth, h.AvailabilityMiddleware(), h.Ad)
+	router.POST("/ads", auth, h.AvailabilityMiddleware(), h.CreateAd)
+
+	adsPath := "/ads?page=1&page_size=10"
+	adPath := fmt.Sprintf("/ads/%d", bike.ID)
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	adCount := func(t *testing.T) int {
+		n, err := store.CountUserAds(ctx, seller.ID)
+		require.NoError(t, err)
+		return n
+	}
+
+	require.Equal(t, http.StatusOK, serve(http.MethodGet, adsPath, "").Code)
+	require.Equal(t, http.StatusOK, serve(http.MethodGet, adPath, "").Code)
+
+	pingFails.Store(true)
+	for i := 0; i < services.AvailabilityFailThreshold; i++ {
+		availability.Check(ctx)
+	}
+	require.False(t, availability.Available())
+
+	t.Run("cached list is served stale", func(t *testing.T) {
+		w := serve(http.MethodGet, adsPath, "")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, StaleWarning, w.Header().Get("Warning"))
+
+		var body struct {
+			Stale bool    `json:"stale"`
+			Items []db.Ad `json:"items"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.True(t, body.Stale)
+		assert.Len(t, body.Items, 2)
+	})
+
+	t.Run("cached object is served stale", func(t *testing.T) {
+		w := serve(http.MethodGet, adPath, "")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, StaleWarning, w.Header().Get("Warning"))
+
+		var body struct {
+			Stale bool   `json:"stale"`
+			Title string `json:"title"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.True(t, body.Stale)
+		assert.Equal(t, bike.Title, body.Title)
+	})
+
+	t.Run("uncached read fails fast", func(t *testing.T) {
+		w := serve(http.MethodGet, "/ads?page=2&page_size=10", "")
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Equal(t, retryAfterSeconds, w.Header().Get("Retry-After"))
+	})
+
+	t.Run("write fails fast", func(t *testing.T) {
+		w := serve(http.MethodPost, "/ads", `{"title":"Стол","text":"Дубовый","image_url":"https://example.com/3.jpg","price":100}`)
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Equal(t, retryAfterSeconds, w.Header().Get("Retry-After"))
+		assert.JSONEq(t, `{"code":"unavailable","message":"`+ErrServiceUnavailable+`"}`, w.Body.String())
+		assert.Equal(t, 2, adCount(t), "write does not reach the database")
+	})
+
+	t.Run("recovery serves fresh responses", func(t *testing.T) {
+		pingFails.Store(false)
+		for i := 0; i < services.AvailabilityRecoverThreshold-1; i++ {
+			availability.Check(ctx)
+		}
+		assert.Equal(t, http.StatusServiceUnavailable, serve(http.MethodPost, "/ads", `{}`).Code, "recovery waits for consecutive successful checks")
+
+		availability.Check(ctx)
+		require.True(t, availability.Available())
+
+		w := serve(http.MethodGet, adsPath, "")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("Warning"))
+		var ads []db.Ad
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &ads))
+		assert.Len(t, ads, 2)
+
+		w = serve(http.MethodPost, "/ads", `{"title":"Стол","text":"Дубовый","image_url":"https://example.com/3.jpg","price":100}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, 3, adCount(t))
+	})
+}
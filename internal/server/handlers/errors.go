@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/YuarenArt/marketgo/internal/db"
+	"github.com/YuarenArt/marketgo/internal/server/services"
+	"github.com/YuarenArt/marketgo/pkg/apierror"
+	"github.com/gin-gonic/gin"
+)
+
+// ErrInternal - сообщение для непредвиденных ошибок; подробности пишутся только в лог
+const ErrInternal = "internal server error"
+
+// statusCodes - код ошибки по умолчанию для HTTP-статуса ответа
+var statusCodes = map[int]string{
+	http.StatusBadRequest:            apierror.CodeInvalidInput,
+	http.StatusUnauthorized:          apierror.CodeUnauthorized,
+	http.StatusForbidden:             apierror.CodeForbidden,
+	http.StatusNotFound:              apierror.CodeNotFound,
+	http.StatusConflict:              apierror.CodeConflict,
+	http.StatusRequestEntityTooLarge: apierror.CodeBodyTooLarge,
+	http.StatusTooManyRequests:       apierror.CodeRateLimited,
+	http.StatusServiceUnavailable:    apierror.CodeUnavailable,
+	http.StatusGatewayTimeout:        apierror.CodeTimeout,
+}
+
+// serviceErrorStatuses - статусы ошибок сервисов, которые сравниваются по тексту
+var serviceErrorStatuses = map[string]int{
+	services.ErrPasswordLength:    http.StatusBadRequest,
+	services.ErrInvalidTokenClaim: http.StatusBadRequest,
+	services.ErrInvalidCursor:     http.StatusBadRequest,
+	services.ErrSearchCursor:      http.StatusBadRequest,
+	services.ErrWrongPassword:     http.StatusForbidden,
+	services.ErrSitemapNotFound:   http.StatusNotFound,
+	services.ErrImageTooLarge:     http.StatusRequestEntityTooLarge,
+	services.ErrUnsupportedImage:  http.StatusBadRequest,
+}
+
+// abortWithError отвечает ошибкой с кодом, соответствующим статусу
+func abortWithError(c *gin.Context, status int, msg string) {
+	abortWithAPIError(c, status, apierror.Error{Code: codeForStatus(status), Message: msg})
+}
+
+// abortWithAPIError отвечает ошибкой apiErr как есть, например с особым кодом или Details
+func abortWithAPIError(c *gin.Context, status int, apiErr apierror.Error) {
+	c.AbortWithStatusJSON(status, apiErr)
+}
+
+func codeForStatus(status int) string {
+	if code, ok := statusCodes[status]; ok {
+		return code
+	}
+	return apierror.CodeInternal
+}
+
+// abortWithBindError отвечает на ошибку разбора тела запроса: 413, если тело превысило
+// лимит BodyLimitMiddleware, 400 с ошибками полей через respondError, иначе 400
+func abortWithBindError(c *gin.Context, err error) {
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		// gin оборачивает ResponseWriter, и MaxBytesReader не всегда может сам пометить
+		// соединение на закрытие, поэтому закрываем его явно
+		c.Header("Connection", "close")
+		abortWithError(c, http.StatusRequestEntityTooLarge, ErrBodyTooLarge)
+	case services.FieldErrors(err) != nil:
+		respondError(c, err)
+	default:
+		abortWithError(c, http.StatusBadRequest, services.ValidationMessage(err))
+	}
+}
+
+// respondError отвечает на ошибку сервиса или хранилища статусом и кодом по её виду:
+// 400 с details.fields для ошибок проверки полей запроса, 400, 403, 404, 409 по видам ошибок db
+// и известным ошибкам сервисов, 503 и 504 при недоступности базы данных. Остальные ошибки возвращаются как 500 без подробностей, чтобы не раскрывать детали PostgreSQL.
+func respondError(c *gin.Context, err error) {
+	if fields := services.FieldErrors(err); fields != nil {
+		abortWithAPIError(c, http.StatusBadRequest, apierror.Error{
+			Code:    apierror.CodeInvalidInput,
+			Message: services.ValidationMessage(err),
+			Details: map[string]any{"fields": fields},
+		})
+		return
+	}
+	switch {
+	case db.IsTimeout(err):
+		abortWithError(c, http.StatusGatewayTimeout, ErrQueryTimeout)
+	case errors.Is(err, db.ErrUnavailable):
+		abortWithError(c, http.StatusServiceUnavailable, ErrServiceUnavailable)
+	case errors.Is(err, db.ErrNotFound):
+		abortWithError(c, http.StatusNotFound, db.Message(err))
+	case errors.Is(err, db.ErrConflict):
+		abortWithError(c, http.StatusConflict, db.Message(err))
+	case errors.Is(err, db.ErrForbidden):
+		abortWithError(c, http.StatusForbidden, db.Message(err))
+	case errors.Is(err, db.ErrInvalid):
+		abortWithError(c, http.StatusBadRequest, db.Message(err))
+	default:
+		if status, ok := serviceErrorStatuses[err.Error()]; ok {
+			abortWithError(c, status, err.Error())
+			return
+		}
+		abortWithError(c, http.StatusInternalServerError, ErrInternal)
+	}
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/YuarenArt/marketgo/internal/db"
+	"github.com/YuarenArt/marketgo/internal/server/services"
+	"github.com/YuarenArt/marketgo/pkg/apierror"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRespondError(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	serve := func(err error) (int, apierror.Error) {
+		router := gin.New()
+		router.GET("/", func(c *gin.Context) { respondError(c, err) })
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		var body apierror.Error
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		return w.Code, body
+	}
+
+	tests := []struct {
+		name    string
+		err     error
+		status  int
+		code    string
+		message string
+	}{
+		{"not found", fmt.Errorf("get ad: %w", db.ErrAdNotFound), http.StatusNotFound, apierror.CodeNotFound, db.ErrMsgAdNotFound},
+		{"conflict", db.ErrLoginTaken, http.StatusConflict, apierror.CodeConflict, db.ErrMsgLoginTaken},
+		{"forbidden", db.ErrNotAdOwner, http.StatusForbidden, apierror.CodeForbidden, db.ErrMsgNotAdOwner},
+		{"invalid", db.ErrInvalidPriceRange, http.StatusBadRequest, apierror.CodeInvalidInput, db.ErrMsgInvalidPriceRange},
+		{"unavailable", db.ErrUnavailable, http.StatusServiceUnavailable, apierror.CodeUnavailable, ErrServiceUnavailable},
+		{"service error", errors.New(services.ErrWrongPassword), http.StatusForbidden, apierror.CodeForbidden, services.ErrWrongPassword},
+		{"unknown error is hidden", errors.New(`pq: relation "ads" does not exist`), http.StatusInternalServerError, apierror.CodeInternal, ErrInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := serve(tt.err)
+			assert.Equal(t, tt.status, status)
+			assert.Equal(t, tt.code, body.Code)
+			assert.Equal(t, tt.message, body.Message)
+		})
+	}
+}
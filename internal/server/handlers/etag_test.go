@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/YuarenArt/marketgo/internal/db"
+	"github.com/YuarenArt/marketgo/internal/db/dbtest"
+	"github.com/YuarenArt/marketgo/internal/server/services"
+	"github.com/YuarenArt/marketgo/pkg/compress"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdsETag(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+
+	store := dbtest.NewStore()
+	user, err := store.CreateUser(ctx, "seller", "hash")
+	require.NoError(t, err)
+	newAd := db.Ad{Title: "Велосипед", Text: "Горный", ImageURL: "https://example.com/1.jpg", Price: 300, UserID: user.ID}
+	_, err = store.CreateAd(ctx, newAd)
+	require.NoError(t, err)
+
+	h, err := NewHandler()
+	require.NoError(t, err)
+	h.adService = services.NewAdService(store)
+
+	router := gin.New()
+	router.Use(compress.Gzip(compress.Options{}))
+	router.GET("/ads", func(c *gin.Context) { c.Set("userID", user.ID) }, h.Ads)
+	router.GET("/ads/:id", func(c *gin.Context) { c.Set("userID", user.ID) }, h.Ad)
+
+	get := func(path, etag string, gzipped bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		if gzipped {
+			req.Header.Set("Accept-Encoding", "gzip")
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	for _, path := range []string{"/ads?count=false", "/ads?include_meta=true", "/ads/1"} {
+		t.Run(path, func(t *testing.T) {
+			first := get(path, "", false)
+			require.Equal(t, http.StatusOK, first.Code)
+			etag := first.Header().Get("ETag")
+			require.NotEmpty(t, etag)
+			assert.Regexp(t, `^W/"[0-9a-f]+"$`, etag)
+
+			t.Run("same etag for gzip", func(t *testing.T) {
+				w := get(path, "", true)
+				assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+				assert.Equal(t, etag, w.Header().Get("ETag"))
+			})
+
+			t.Run("hit returns 304", func(t *testing.T) {
+				w := get(path, etag, true)
+				assert.Equal(t, http.StatusNotModified, w.Code)
+				assert.Equal(t, etag, w.Header().Get("ETag"))
+			})
+
+			t.Run("miss returns body", func(t *testing.T) {
+				w := get(path, `W/"stale"`, false)
+				assert.Equal(t, http.StatusOK, w.Code)
+				assert.NotEmpty(t, w.Body.String())
+			})
+		})
+	}
+
+	t.Run("etag changes with the result set", func(t *testing.T) {
+		etag := get("/ads?count=false", "", false).Header().Get("ETag")
+		_, err := store.CreateAd(ctx, newAd)
+		require.NoError(t, err)
+
+		w := get("/ads?count=false", etag, false)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotEqual(t, etag, w.Header().Get("ETag"))
+	})
+}
+
+func TestETagMatches(t *testing.T) {
+	etag := `W/"abc"`
+	assert.True(t, etagMatches(`W/"abc"`, etag))
+	assert.True(t, etagMatches(`"abc"`, etag))
+	assert.True(t, etagMatches(`W/"x", W/"abc"`, etag))
+	assert.True(t, etagMatches(`*`, etag))
+	assert.False(t, etagMatches(``, etag))
+	assert.False(t, etagMatches(`W/"abd"`, etag))
+}
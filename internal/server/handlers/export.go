@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/YuarenArt/marketgo/internal/db"
+	"github.com/YuarenArt/marketgo/internal/server/services"
+	"github.com/YuarenArt/marketgo/pkg/apierror"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	ErrExportSearch = "q is not supported by export"
+
+	// exportRoute - маршрут выгрузки CSV относительно префикса API; TimeoutMiddleware его не ограничивает
+	exportRoute = "/ads/export.csv"
+
+	// exportFlushRows - через сколько строк накопленная часть выгрузки отправляется клиенту.
+	// Первая отправка уже больше GZIP_MIN_SIZE, поэтому сжатие остаётся включённым.
+	exportFlushRows = 1000
+)
+
+// exportHeader - столбцы CSV, выгружаемого ExportAds
+var exportHeader = []string{"id", "title", "price", "author", "created_at", "image_url", "status", "city"}
+
+// ExportAds выгружает объявления в CSV
+// @Summary Выгрузка объявлений в CSV
+// @Description Выгружает все объявления, подходящие под фильтры GET /ads, одним файлом CSV в порядке sort_by и sort_order.
+// @Description page, page_size и cursor не учитываются, поиск q не поддерживается. Ответ передаётся по мере чтения из базы
+// @Description и не ограничивается REQUEST_TIMEOUT и HTTP_WRITE_TIMEOUT.
+// @Description Если объявлений больше EXPORT_MAX_ROWS, ответ 413 с кодом export_too_large и details.max_rows.
+// @Tags ads
+// @Produce text/csv
+// @Security BearerAuth
+// @Param sort_by query string false "Поле сортировки" default(created_at)
+// @Param sort_order query string false "Порядок сортировки" Enums(ASC, DESC) default(DESC)
+// @Param min_price query int false "Минимальная цена"
+// @Param max_price query int false "Максимальная цена"
+// @Param status query string false "Статусы через запятую" default(active)
+// @Param tag query []string false "Теги; объявление должно иметь все указанные" collectionFormat(multi)
+// @Param city query string false "Города через запятую, без учёта регистра"
+// @Param created_from query string false "Созданы не раньше (RFC 3339)"
+// @Param created_to query string false "Созданы не позже (RFC 3339)"
+// @Success 200 {string} string
+// @Header 200 {string} Content-Disposition "attachment; filename=\"ads.csv\""
+// @Failure 400 {object} apierror.Error
+// @Failure 401 {object} apierror.Error
+// @Failure 413 {object} apierror.Error
+// @Router /ads/export.csv [get]
+func (h *Handler) ExportAds(c *gin.Context) {
+	logger := h.requestLogger(c)
+	userID, ok := c.Get("userID")
+	if !ok {
+		logger.Warn("ExportAds: unauthorized access")
+		abortWithError(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+
+	req, ok := parseAdsRequest(c, logger, "ExportAds")
+	if !ok {
+		return
+	}
+	if strings.TrimSpace(c.Query("q")) != "" {
+		abortWithError(c, http.StatusBadRequest, ErrExportSearch)
+		return
+	}
+	maxRows := h.exportMaxRows
+	if maxRows <= 0 {
+		maxRows = services.DefaultExportMaxRows
+	}
+
+	// Большая выгрузка идёт дольше HTTP_WRITE_TIMEOUT; без снятия срока сервер оборвал бы уже начатый файл
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		logger.Warn("ExportAds: failed to clear write deadline", "error", err)
+	}
+
+	// Заголовки отправляются с первой строкой: до неё ошибка ещё может стать ответом с кодом
+	cw := csv.NewWriter(c.Writer)
+	count := 0
+	started := false
+	start := func() error {
+		started = true
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Header("Content-Disposition", `attachment; filename="ads.csv"`)
+		c.Status(http.StatusOK)
+		return cw.Write(exportHeader)
+	}
+
+	err := h.adService.ExportAds(c, req, userID.(int), maxRows, func(ad db.Ad) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		record := []string{
+			strconv.Itoa(ad.ID),
+			ad.Title,
+			strconv.FormatInt(ad.Price, 10),
+			ad.Author,
+			ad.CreatedAt.Format(time.RFC3339),
+			ad.ImageURL,
+			ad.Status,
+			ad.City,
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+		count++
+		if count%exportFlushRows == 0 {
+			cw.Flush()
+			if err := cw.Error(); err != nil {
+				return err
+			}
+			c.Writer.Flush()
+		}
+		return nil
+	})
+	if err == nil && !started {
+		err = start()
+	}
+	if err == nil {
+		cw.Flush()
+		err = cw.Error()
+	}
+
+	switch {
+	case err == nil:
+		logger.Info("ExportAds: ads exported", "count", count)
+	case started:
+		// Статус уже отправлен: клиент получит файл без оставшихся строк
+		logger.Error("ExportAds: export interrupted", "count", count, "error", err)
+		c.Abort()
+	case err.Error() == services.ErrExportTooLarge:
+		logger.Warn("ExportAds: export too large", "max_rows", maxRows)
+		abortWithAPIError(c, http.StatusRequestEntityTooLarge, apierror.Error{
+			Code:    apierror.CodeExportTooLarge,
+			Message: services.ErrExportTooLarge,
+			Details: map[string]any{"max_rows": maxRows},
+		})
+	default:
+		logger.Warn("ExportAds: failed to export ads", "error", err)
+		respondError(c, err)
+	}
+}
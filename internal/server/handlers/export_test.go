@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/YuarenArt/marketgo/internal/db"
+	"github.com/YuarenArt/marketgo/internal/db/dbtest"
+	"github.com/YuarenArt/marketgo/internal/server/services"
+	"github.com/YuarenArt/marketgo/pkg/apierror"
+	"github.com/YuarenArt/marketgo/pkg/compress"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportAds(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+
+	store := dbtest.NewStore()
+	user, err := store.CreateUser(ctx, "seller", "hash")
+	require.NoError(t, err)
+	_, err = store.CreateAd(ctx, db.Ad{Title: `Стол "Дуб", раздвижной`, Text: "text", ImageURL: "https://example.com/1.jpg", Price: 300, UserID: user.ID, City: "Москва"})
+	require.NoError(t, err)
+	for i := 0; i < 2*exportFlushRows; i++ {
+		_, err := store.CreateAd(ctx, db.Ad{Title: fmt.Sprintf("Объявление %d", i), Text: "text", Price: 1000, UserID: user.ID})
+		require.NoError(t, err)
+	}
+
+	h, err := NewHandler()
+	require.NoError(t, err)
+	h.adService = services.NewAdService(store)
+
+	router := gin.New()
+	router.Use(compress.Gzip(compress.Options{MinSize: 1024}))
+	router.GET("/ads/export.csv", func(c *gin.Context) { c.Set("userID", user.ID) }, h.ExportAds)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	readCSV := func(t *testing.T, w *httptest.ResponseRecorder) [][]string {
+		t.Helper()
+		var body io.Reader = w.Body
+		if w.Header().Get("Content-Encoding") == "gzip" {
+			zr, err := gzip.NewReader(w.Body)
+			require.NoError(t, err)
+			body = zr
+		}
+		records, err := csv.NewReader(body).ReadAll()
+		require.NoError(t, err)
+		return records
+	}
+
+	t.Run("all matching ads are streamed compressed", func(t *testing.T) {
+		w := get("/ads/export.csv?page=2&page_size=10")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Equal(t, `attachment; filename="ads.csv"`, w.Header().Get("Content-Disposition"))
+		assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+
+		records := readCSV(t, w)
+		require.Len(t, records, 2*exportFlushRows+2, "pagination is ignored")
+		assert.Equal(t, exportHeader, records[0])
+	})
+
+	t.Run("filters and escaping", func(t *testing.T) {
+		w := get("/ads/export.csv?max_price=500")
+		require.Equal(t, http.StatusOK, w.Code)
+		records := readCSV(t, w)
+		require.Len(t, records, 2)
+		assert.Equal(t, `Стол "Дуб", раздвижной`, records[1][1])
+		assert.Equal(t, "300", records[1][2])
+		assert.Equal(t, "seller", records[1][3])
+		assert.Equal(t, "active", records[1][6])
+		assert.Equal(t, "Москва", records[1][7])
+	})
+
+	t.Run("empty result has a header row", func(t *testing.T) {
+		w := get("/ads/export.csv?city=Kazan")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, [][]string{exportHeader}, readCSV(t, w))
+	})
+
+	t.Run("row limit", func(t *testing.T) {
+		h.exportMaxRows = exportFlushRows
+		defer func() { h.exportMaxRows = 0 }()
+
+		w := get("/ads/export.csv")
+		require.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		var apiErr apierror.Error
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &apiErr))
+		assert.Equal(t, apierror.CodeExportTooLarge, apiErr.Code)
+		assert.EqualValues(t, exportFlushRows, apiErr.Details["max_rows"])
+
+		w = get("/ads/export.csv?max_price=500")
+		assert.Equal(t, http.StatusOK, w.Code, "filtered export fits the limit")
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		for _, path := range []string{
+			"/ads/export.csv?min_price=-1",
+			"/ads/export.csv?min_price=500&max_price=100",
+			"/ads/export.csv?sort_by=author",
+			"/ads/export.csv?q=table",
+		} {
+			w := get(path)
+			assert.Equal(t, http.StatusBadRequest, w.Code, path)
+		}
+	})
+}
+
+// slowExportStore отдаёт каждое объявление выгрузки через delay или по отмене контекста, как курсор базы данных
+type slowExportStore struct {
+	*dbtest.Store
+	delay time.Duration
+}
+
+func (s slowExportStore) StreamAds(ctx context.Context, userID, limit int, sortBy, sortOrder string, filter db.AdsFilter, fn func(db.Ad) error) error {
+	return s.Store.StreamAds(ctx, userID, limit, sortBy, sortOrder, filter, func(ad db.Ad) error {
+		select {
+		case <-time.After(s.delay):
+			return fn(ad)
+		case <-ctx.Done():
+			return fmt.Errorf("failed to stream ads: %w", ctx.Err())
+		}
+	})
+}
+
+func TestExportAdsOutlivesTimeouts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+
+	const (
+		timeout = 50 * time.Millisecond
+		ads     = 10
+	)
+
+	store := dbtest.NewStore()
+	user, err := store.CreateUser(ctx, "seller", "hash")
+	require.NoError(t, err)
+	for i := 0; i < ads; i++ {
+		_, err := store.CreateAd(ctx, db.Ad{Title: fmt.Sprintf("Объявление %d", i), Text: "text", Price: 1000, UserID: user.ID})
+		require.NoError(t, err)
+	}
+
+	run := func(t *testing.T, staleCache bool) {
+		h, err := NewHandler()
+		require.NoError(t, err)
+		h.adService = services.NewAdService(slowExportStore{Store: store, delay: timeout / 2})
+		if staleCache {
+			h.availability = services.NewAvailability(func(context.Context) error { return nil })
+			h.staleCache = newStaleCache(10)
+		}
+
+		router := gin.New()
+		router.ContextWithFallback = true
+		router.Use(compress.Gzip(compress.Options{MinSize: 1024}), h.TimeoutMiddleware(timeout))
+		router.GET("/api/v1/ads/export.csv", func(c *gin.Context) { c.Set("userID", user.ID) }, h.AvailabilityMiddleware(), h.ExportAds)
+
+		srv := httptest.NewUnstartedServer(router)
+		srv.Config.WriteTimeout = timeout
+		srv.Start()
+		defer srv.Close()
+
+		started := time.Now()
+		resp, err := http.Get(srv.URL + "/api/v1/ads/export.csv")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		records, err := csv.NewReader(resp.Body).ReadAll()
+		require.NoError(t, err)
+		assert.Greater(t, time.Since(started), 2*timeout, "export must run longer than the timeouts")
+		assert.Len(t, records, ads+1, "export is not cut by REQUEST_TIMEOUT or HTTP_WRITE_TIMEOUT")
+
+		if staleCache {
+			assert.Zero(t, h.staleCache.order.Len(), "export is not recorded in the stale cache")
+		}
+	}
+
+	t.Run("without stale cache", func(t *testing.T) { run(t, false) })
+	t.Run("with stale cache", func(t *testing.T) { run(t, true) })
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/YuarenArt/marketgo/internal/server/services"
+	"github.com/gin-gonic/gin"
+)
+
+// AddFavorite добавляет объявление в избранное текущего пользователя
+// @Summary Добавление в избранное
+// @Description Добавляет объявление в избранное. Повторное добавление ничего не меняет; своё объявление добавить можно.
+// @Tags favorites
+// @Security BearerAuth
+// @Param id path int true "ID объявления"
+// @Success 204
+// @Failure 400 {object} apierror.Error
+// @Failure 401 {object} apierror.Error
+// @Failure 404 {object} apierror.Error
+// @Router /ads/{id}/favorite [post]
+// @Security BearerAuth
+func (h *Handler) AddFavorite(c *gin.Context) {
+	userID, ok := c.Get("userID")
+	if !ok {
+		h.logger.Warn("AddFavorite: unauthorized access")
+		abortWithError(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+
+	adID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || adID <= 0 {
+		abortWithError(c, http.StatusBadRequest, ErrInvalidAdID)
+		return
+	}
+
+	if err := h.adService.AddFavorite(c, adID, userID.(int)); err != nil {
+		h.logger.Warn("AddFavorite: failed to add favorite", "user_id", userID, "ad_id", adID, "error", err)
+		respondError(c, err)
+		return
+	}
+
+	h.logger.Debug("AddFavorite: ad added to favorites", "user_id", userID, "ad_id", adID)
+	c.Status(http.StatusNoContent)
+}
+
+// RemoveFavorite убирает объявление из избранного текущего пользователя
+// @Summary Удаление из избранного
+// @Description Убирает объявление из избранного. Отсутствующая запись не считается ошибкой.
+// @Tags favorites
+// @Security BearerAuth
+// @Param id path int true "ID объявления"
+// @Success 204
+// @Failure 400 {object} apierror.Error
+// @Failure 401 {object} apierror.Error
+// @Router /ads/{id}/favorite [delete]
+// @Security BearerAuth
+func (h *Handler) RemoveFavorite(c *gin.Context) {
+	userID, ok := c.Get("userID")
+	if !ok {
+		h.logger.Warn("RemoveFavorite: unauthorized access")
+		abortWithError(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+
+	adID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || adID <= 0 {
+		abortWithError(c, http.StatusBadRequest, ErrInvalidAdID)
+		return
+	}
+
+	if err := h.adService.RemoveFavorite(c, adID, userID.(int)); err != nil {
+		h.logger.Warn("RemoveFavorite: failed to remove favorite", "user_id", userID, "ad_id", adID, "error", err)
+		respondError(c, err)
+		return
+	}
+
+	h.logger.Debug("RemoveFavorite: ad removed from favorites", "user_id", userID, "ad_id", adID)
+	c.Status(http.StatusNoContent)
+}
+
+// Favorites возвращает избранные объявления текущего пользователя
+// @Summary Получение избранного
+// @Description Возвращает избранные объявления во всех статусах, начиная с добавленных последними. Удалённые объявления не возвращаются.
+// @Tags favorites
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Номер страницы" default(1)
+// @Param page_size query int false "Размер страницы" default(10)
+// @Param Accept-Language header string false "Предпочитаемые языки; поле lang в ответе указывает отданный перевод"
+// @Success 200 {array} db.Ad
+// @Header 200 {string} Content-Encoding "gzip"
+// @Failure 400 {object} apierror.Error
+// @Failure 401 {object} apierror.Error
+// @Router /favorites [get]
+// @Security BearerAuth
+func (h *Handler) Favorites(c *gin.Context) {
+	userID, ok := c.Get("userID")
+	if !ok {
+		h.logger.Warn("Favorites: unauthorized access")
+		abortWithError(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+	if page < 1 || pageSize < 1 || pageSize > 100 {
+		abortWithError(c, http.StatusBadRequest, ErrInvalidPagination)
+		return
+	}
+
+	req := services.GetAdsRequest{
+		Page:      page,
+		PageSize:  pageSize,
+		Languages: services.ParseAcceptLanguage(c.GetHeader("Accept-Language")),
+	}
+	ads, err := h.adService.GetFavorites(c, req, userID.(int))
+	if err != nil {
+		h.logger.Warn("Favorites: failed to fetch favorites", "user_id", userID, "error", err)
+		respondError(c, err)
+		return
+	}
+
+	h.logger.Debug("Favorites: favorites fetched", "user_id", userID, "count", len(ads))
+	c.JSON(http.StatusOK, ads)
+}
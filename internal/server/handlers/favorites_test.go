@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/YuarenArt/marketgo/internal/client"
+	"github.com/YuarenArt/marketgo/internal/db"
+	"github.com/YuarenArt/marketgo/internal/db/dbtest"
+	"github.com/YuarenArt/marketgo/internal/server/services"
+	"github.com/YuarenArt/marketgo/pkg/logging"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFavorites(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+
+	store := dbtest.NewStore()
+	seller, err := store.CreateUser(ctx, "seller", "hash")
+	require.NoError(t, err)
+	buyer, err := store.CreateUser(ctx, "buyer", "hash")
+	require.NoError(t, err)
+	bike, err := store.CreateAd(ctx, db.Ad{Title: "Велосипед", Text: "Горный", ImageURL: "https://example.com/1.jpg", Price: 300, UserID: seller.ID})
+	require.NoError(t, err)
+	sofa, err := store.CreateAd(ctx, db.Ad{Title: "Диван", Text: "Угловой", ImageURL: "https://example.com/2.jpg", Price: 500, UserID: seller.ID})
+	require.NoError(t, err)
+
+	h, err := NewHandler()
+	require.NoError(t, err)
+	h.adService = services.NewAdService(store)
+
+	auth := func(c *gin.Context) { c.Set("userID", buyer.ID) }
+	router := gin.New()
+	router.GET("/api/v1/ads/:id", auth, h.Ad)
+	router.POST("/api/v1/ads/:id/favorite", auth, h.AddFavorite)
+	router.DELETE("/api/v1/ads/:id/favorite", auth, h.RemoveFavorite)
+	router.GET("/api/v1/favorites", auth, h.Favorites)
+
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+	c := client.NewClient(srv.URL, logging.NewLogger(nil))
+
+	t.Run("client adds, lists and removes favorites", func(t *testing.T) {
+		require.NoError(t, c.FavoriteAd(ctx, bike.ID))
+		require.NoError(t, c.FavoriteAd(ctx, bike.ID), "adding twice is idempotent")
+		require.NoError(t, c.FavoriteAd(ctx, sofa.ID))
+
+		ads, err := c.GetFavorites(ctx, services.GetAdsRequest{Page: 1, PageSize: 10})
+		require.NoError(t, err)
+		require.Len(t, ads, 2)
+		for _, ad := range ads {
+			assert.True(t, ad.IsFavorite)
+		}
+
+		ad, err := c.GetAd(ctx, bike.ID)
+		require.NoError(t, err)
+		assert.True(t, ad.IsFavorite)
+
+		require.NoError(t, c.UnfavoriteAd(ctx, bike.ID))
+		ads, err = c.GetFavorites(ctx, services.GetAdsRequest{Page: 1, PageSize: 10})
+		require.NoError(t, err)
+		require.Len(t, ads, 1)
+		assert.Equal(t, sofa.ID, ads[0].ID)
+	})
+
+	t.Run("missing ad is not found", func(t *testing.T) {
+		err := c.FavoriteAd(ctx, 999)
+		assert.ErrorIs(t, err, client.ErrNotFound)
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		for _, tc := range []struct {
+			method, path string
+		}{
+			{http.MethodPost, "/api/v1/ads/abc/favorite"},
+			{http.MethodDelete, "/api/v1/ads/0/favorite"},
+			{http.MethodGet, "/api/v1/favorites?page=0"},
+			{http.MethodGet, "/api/v1/favorites?page_size=101"},
+		} {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(tc.method, tc.path, nil))
+			assert.Equal(t, http.StatusBadRequest, w.Code, tc.path)
+		}
+	})
+}
@@ -2,34 +2,120 @@ package handlers
 
 import (
 	"context"
+	"crypto/rsa"
+	"errors"
+	"fmt"
 	"log/slog"
+	"math"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/YuarenArt/marketgo/internal/config"
 	"github.com/YuarenArt/marketgo/internal/db"
+	"github.com/YuarenArt/marketgo/internal/search"
 	"github.com/YuarenArt/marketgo/internal/server/services"
+	"github.com/YuarenArt/marketgo/internal/storage"
+	"github.com/YuarenArt/marketgo/pkg/apierror"
 	"github.com/YuarenArt/marketgo/pkg/logging"
+	"github.com/YuarenArt/marketgo/pkg/metrics"
 	"github.com/gin-gonic/gin"
 )
 
 const (
 	AuthHeader       = "X-Auth-Token"
+	NonceHeader      = "X-Request-Nonce"
 	ErrTokenRequired = "token required"
 	ErrInvalidToken  = "invalid token"
 	ErrUnauthorized  = "unauthorized"
+	ErrForbidden     = "insufficient permissions"
 	ErrInvalidCreds  = "invalid credentials"
+	ErrLoginExists   = "login already exists"
+	ErrInvalidAdID   = "invalid ad id"
+	ErrEmptyBody     = "request body must contain at least one field"
+	ErrInvalidDate   = "dates must be in YYYY-MM-DD format"
+	ErrStatsRange    = "requested period is too long"
+	ErrQueryTimeout  = "database query timed out"
+	ErrBodyTooLarge  = "request body too large"
+	ErrMalformedGzip = "malformed gzip request body"
+
+	ErrInvalidCreatedFrom = "created_from must be an RFC3339 timestamp, e.g. 2024-03-01T00:00:00Z"
+	ErrInvalidCreatedTo   = "created_to must be an RFC3339 timestamp, e.g. 2024-03-31T23:59:59Z"
+	ErrInvalidMinPrice    = "min_price must be a non-negative integer"
+	ErrInvalidMaxPrice    = "max_price must be a non-negative integer"
+	// ErrInvalidPriceRange - формат сообщения с переданными min_price и max_price
+	ErrInvalidPriceRange = "min_price (%d) must not exceed max_price (%d)"
+
+	ErrInvalidAnnouncementID = "invalid announcement id"
+	ErrInvalidNotificationID = "invalid notification id"
+	ErrInvalidReservationID  = "invalid reservation id"
+	ErrInvalidPagination     = "page must be positive and page_size must be between 1 and 100"
+
+	ErrInvalidUsageDays = "days must be between 1 and 90"
+
+	ErrNonceRequired  = "X-Request-Nonce header is required"
+	ErrInvalidNonce   = "X-Request-Nonce must not exceed 128 characters"
+	ErrReplayDetected = "request nonce already used"
+
+	RateLimitLimitHeader     = "X-RateLimit-Limit"
+	RateLimitRemainingHeader = "X-RateLimit-Remaining"
+	RateLimitResetHeader     = "X-RateLimit-Reset"
+	TotalCountHeader         = "X-Total-Count"
+
+	gzSuffix = ".gz"
+
+	loginFailureInvalidCreds = "invalid_credentials"
+	loginFailureLocked       = "locked"
 )
 
 // HandlerOption описывает функцию настройки Handler
 type HandlerOption func(h *Handler) error
 
+// adModerator удаляет объявления по решению администратора; выделен в интерфейс для подмены в тестах
+type adModerator interface {
+	RemoveAd(ctx context.Context, adID, moderatorID int) error
+}
+
+// registrar регистрирует пользователей; выделен в интерфейс для подмены в тестах
+type registrar interface {
+	Register(ctx context.Context, input services.InputUserInfo) (db.User, error)
+}
+
+// healthChecker проверяет, что зависимость отвечает; выделен в интерфейс для подмены в тестах
+type healthChecker interface {
+	Health(ctx context.Context) error
+}
+
 // Handler содержит бизнес-логику и доступ к сервисам
 type Handler struct {
-	authService *services.AuthService
-	adService   *services.AdService
-	logger      logging.Logger
+	authService         *services.AuthService
+	adService           *services.AdService
+	adModerator         adModerator
+	registrar           registrar
+	statsService        *services.StatsService
+	announcementService *services.AnnouncementService
+	notificationService *services.NotificationService
+	priceAlertService   *services.PriceAlertService
+	reservationService  *services.ReservationService
+	sitemapService      *services.SitemapService
+	feedService         *services.FeedService
+	exportMaxRows       int
+	imageService        *services.ImageService
+	nonceStore          services.NonceStore
+	usageService        *services.UsageService
+	searchIndex         search.Index
+	adEvents            services.AdEvents
+	availability        *services.Availability
+	dbHealth            healthChecker
+	dbService           *db.DBService
+	staleCache          *staleCache
+	protection          *services.Protection
+	loginLimiter        *services.LoginLimiter
+	captchaSiteKey      string
+	metrics             *metrics.Metrics
+	logger              logging.Logger
+	auditLogger         *logging.AuditLogger
 }
 
 // NewHandler создаёт Handler, применяя набор опций.
@@ -52,14 +138,117 @@ func WithConfig(ctx context.Context, dsn string, cfg *config.Config, dbOptions .
 	return func(h *Handler) error {
 
 		logger := logging.NewLogger(cfg)
+		if cfg.TokenTTL <= 0 {
+			return fmt.Errorf("TOKEN_TTL must be positive, got %s", cfg.TokenTTL)
+		}
+		if cfg.AdTTL <= 0 {
+			return fmt.Errorf("AD_TTL must be positive, got %s", cfg.AdTTL)
+		}
+		if err := services.ValidateBcryptCost(int(cfg.BcryptCost)); err != nil {
+			return fmt.Errorf("invalid BCRYPT_COST: %w", err)
+		}
+		var privateKey *rsa.PrivateKey
+		if cfg.JWTPrivateKeyFile != "" {
+			key, err := services.LoadRSAPrivateKey(cfg.JWTPrivateKeyFile)
+			if err != nil {
+				logger.Error("Failed to load JWT private key", "path", cfg.JWTPrivateKeyFile, "error", err)
+				return err
+			}
+			privateKey = key
+		}
+
+		var dbSvc *db.DBService
+		h.availability = services.NewAvailability(func(ctx context.Context) error { return dbSvc.Ping(ctx) })
+		dbOptions = append(dbOptions, db.WithErrorObserver(h.availability.Observe))
+		if h.metrics != nil {
+			dbOptions = append(dbOptions, db.WithRetryObserver(h.metrics.DBRetriesCount.Inc))
+		}
+
 		dbSvc, err := db.NewDBService(ctx, dsn, dbOptions...)
 		if err != nil {
 			logger.Error("Failed to init DBService", "error", err)
 			return err
 		}
 
-		h.authService = services.NewAuthService(dbSvc, cfg.JWTSecret)
+		if cfg.AdminLogin != "" {
+			// Пользователь может зарегистрироваться позже: тогда роль назначится при следующем запуске
+			if _, err := dbSvc.SetUserRole(ctx, cfg.AdminLogin, db.RoleAdmin); err != nil {
+				logger.Warn("Failed to promote admin user", "login", cfg.AdminLogin, "error", err)
+			} else {
+				logger.Info("Admin role granted", "login", cfg.AdminLogin)
+			}
+		}
+
+		if privateKey != nil {
+			h.authService = services.NewRSAAuthService(dbSvc, privateKey, cfg.TokenTTL, int(cfg.BcryptCost))
+		} else {
+			h.authService = services.NewAuthService(dbSvc, cfg.JWTSecret, cfg.TokenTTL, int(cfg.BcryptCost))
+		}
+		h.authService.UseAvailability(h.availability)
+		if cfg.LoginMaxFailures > 0 {
+			h.loginLimiter = services.NewLoginLimiter(int(cfg.LoginMaxFailures), cfg.LoginFailureWindow)
+			h.authService.UseLoginLimiter(h.loginLimiter)
+		}
+		if cfg.PasswordPolicy {
+			h.authService.UsePasswordPolicy()
+		}
 		h.adService = services.NewAdService(dbSvc)
+		if cfg.AdsCacheTTL > 0 {
+			h.adService.UseAdsCache(cfg.AdsCacheTTL, h.observeAdsCache)
+		}
+		h.adService.UseAdTTL(cfg.AdTTL)
+		if h.adEvents != nil {
+			h.adService.UseAdEvents(h.adEvents)
+		}
+		h.adModerator = h.adService
+		h.registrar = h.authService
+		h.dbHealth = dbSvc
+		h.dbService = dbSvc
+		h.statsService = services.NewStatsService(dbSvc)
+		h.announcementService = services.NewAnnouncementService(dbSvc)
+		h.notificationService = services.NewNotificationService(dbSvc)
+		h.priceAlertService = services.NewPriceAlertService(dbSvc)
+		h.reservationService = services.NewReservationService(dbSvc, cfg.ReservationTTL)
+		h.sitemapService = services.NewSitemapService(dbSvc, cfg.PublicBaseURL)
+		h.feedService = services.NewFeedService(dbSvc, cfg.PublicBaseURL)
+		uploads, err := storage.NewLocal(cfg.UploadDir)
+		if err != nil {
+			logger.Error("Failed to init upload storage", "dir", cfg.UploadDir, "error", err)
+			return err
+		}
+		h.imageService = services.NewImageService(uploads, cfg.PublicBaseURL)
+		h.imageService.UseRegistry(dbSvc, cfg.ImageGCGrace)
+		h.usageService = services.NewUsageService(dbSvc, cfg.DailyQuota)
+		h.exportMaxRows = int(cfg.ExportMaxRows)
+		if cfg.Search.Backend == search.BackendOpenSearch {
+			h.searchIndex = search.NewOpenSearch(search.OpenSearchConfig{
+				URL:      cfg.Search.OpenSearchURL,
+				Index:    cfg.Search.OpenSearchIndex,
+				Username: cfg.Search.OpenSearchUser,
+				Password: cfg.Search.OpenSearchPassword,
+			})
+			h.adService.UseSearchIndex(h.searchIndex)
+		}
+		if cfg.ReplayProtection {
+			h.nonceStore = services.NewMemoryNonceStore(cfg.ReplayNonceTTL)
+		}
+		if cfg.StaleCacheSize > 0 {
+			h.staleCache = newStaleCache(int(cfg.StaleCacheSize))
+		}
+		if bot := cfg.BotProtection; bot.Enabled {
+			var verifier services.CaptchaVerifier
+			if bot.CaptchaVerifyURL != "" {
+				verifier = services.NewSiteVerifyCaptcha(bot.CaptchaVerifyURL, bot.CaptchaSecret)
+			}
+			h.protection = services.NewProtection(services.ProtectionConfig{
+				Budget:         int(bot.Budget),
+				Window:         bot.Window,
+				TarpitAfter:    int(bot.TarpitAfter),
+				TarpitStep:     bot.TarpitStep,
+				TarpitMaxDelay: bot.TarpitMaxDelay,
+			}, services.NewPageWalkDetector(int(bot.PageWalkThreshold)), verifier)
+			h.captchaSiteKey = bot.CaptchaSiteKey
+		}
 		h.logger = logger
 		return nil
 	}
@@ -68,8 +257,38 @@ func WithConfig(ctx context.Context, dsn string, cfg *config.Config, dbOptions .
 // WithCustomDB позволяет передать готовый DBService вручную (без коннекта по DSN)
 func WithCustomDB(dbSvc *db.DBService) HandlerOption {
 	return func(h *Handler) error {
-		h.authService = services.NewAuthService(dbSvc, "") // можно позже перезадать secret
+		h.authService = services.NewAuthService(dbSvc, "", services.DefaultTokenTTL, services.DefaultBcryptCost) // можно позже перезадать secret
 		h.adService = services.NewAdService(dbSvc)
+		h.adModerator = h.adService
+		h.registrar = h.authService
+		h.dbHealth = dbSvc
+		h.dbService = dbSvc
+		h.statsService = services.NewStatsService(dbSvc)
+		h.announcementService = services.NewAnnouncementService(dbSvc)
+		h.notificationService = services.NewNotificationService(dbSvc)
+		h.priceAlertService = services.NewPriceAlertService(dbSvc)
+		h.reservationService = services.NewReservationService(dbSvc, services.DefaultReservationTTL)
+		h.sitemapService = services.NewSitemapService(dbSvc, "")
+		h.feedService = services.NewFeedService(dbSvc, "")
+		h.usageService = services.NewUsageService(dbSvc, services.DefaultDailyQuota)
+		return nil
+	}
+}
+
+// WithAdEvents передаёт события жизненного цикла объявлений в events, например диспетчеру вебхуков.
+// Указывается до WithConfig, который создаёт AdService.
+func WithAdEvents(events services.AdEvents) HandlerOption {
+	return func(h *Handler) error {
+		h.adEvents = events
+		return nil
+	}
+}
+
+// WithReplayProtection включает защиту от повторов с заданным хранилищем nonce,
+// например разделяемым между репликами вместо хранилища в памяти
+func WithReplayProtection(store services.NonceStore) HandlerOption {
+	return func(h *Handler) error {
+		h.nonceStore = store
 		return nil
 	}
 }
@@ -81,7 +300,54 @@ func WithLogger(l logging.Logger) HandlerOption {
 	}
 }
 
-// AuthMiddleware проверяет JWT и устанавливает userID в контекст запроса
+// StartBackgroundJobs запускает фоновые задачи сервисов.
+// Задачи завершаются при отмене ctx.
+func (h *Handler) StartBackgroundJobs(ctx context.Context) {
+	if h.authService != nil {
+		go h.authService.Run(ctx, services.RevocationCleanupInterval, h.logger)
+	}
+	if h.loginLimiter != nil {
+		go h.loginLimiter.Run(ctx, services.LoginLimiterCleanupInterval, h.logger)
+	}
+	if h.statsService != nil {
+		go h.statsService.Run(ctx, services.StatsRollupInterval, h.logger)
+	}
+	if h.notificationService != nil {
+		go h.notificationService.Run(ctx, services.NotificationCleanupInterval, h.logger)
+	}
+	if h.priceAlertService != nil {
+		go h.priceAlertService.Run(ctx, h.adService.PriceDrops(), h.logger)
+	}
+	if h.reservationService != nil {
+		go h.reservationService.Run(ctx, services.ReservationExpiryInterval, h.logger)
+	}
+	if h.adService != nil {
+		go h.adService.RunExpiry(ctx, services.AdExpiryInterval, h.logger, h.observeAdsExpired)
+	}
+	if h.usageService != nil {
+		go h.usageService.Run(ctx, services.UsageFlushInterval, h.logger)
+	}
+	if h.imageService != nil {
+		go h.imageService.RunGC(ctx, services.ImageGCInterval, h.logger)
+	}
+	if runner, ok := h.searchIndex.(search.Runner); ok {
+		go runner.Run(ctx, h.logger)
+	}
+	if h.availability != nil {
+		go h.availability.Run(ctx, services.AvailabilityCheckInterval, h.logger)
+	}
+	if h.protection != nil {
+		go h.protection.Run(ctx, services.ProtectionCleanupInterval, h.logger)
+	}
+	if h.dbService != nil {
+		go h.dbService.RunReplicaChecks(ctx, db.ReplicaCheckInterval, h.logger)
+	}
+}
+
+// AuthMiddleware проверяет JWT и устанавливает userID в контекст запроса.
+// Если отзыв токена или существование пользователя нельзя проверить из-за недоступной базы данных,
+// запрос получает 503 или 504, а не проходит. Пока трекер доступности уже считает базу недоступной,
+// проверяется только подпись токена, и запрос обрабатывает AvailabilityMiddleware.
 func (h *Handler) AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		header := c.GetHeader(AuthHeader)
@@ -91,85 +357,340 @@ func (h *Handler) AuthMiddleware() gin.HandlerFunc {
 		}
 
 		token := strings.TrimSpace(header)
-		userID, err := h.authService.ValidateToken(token)
+		claims, err := h.authService.ParseToken(c, token)
+		if err != nil {
+			switch {
+			case db.IsTimeout(err):
+				h.logger.Warn("AuthMiddleware: token check timed out", "error", err)
+				abortWithError(c, http.StatusGatewayTimeout, ErrQueryTimeout)
+				return
+			case db.IsUnavailable(err):
+				h.logger.Warn("AuthMiddleware: token check failed", "error", err)
+				abortWithError(c, http.StatusServiceUnavailable, ErrServiceUnavailable)
+				return
+			}
+			msg := ErrInvalidToken
+			if err.Error() == services.ErrTokenRevoked {
+				msg = services.ErrTokenRevoked
+			}
+			abortWithError(c, http.StatusUnauthorized, msg)
+			return
+		}
+
+		c.Set("userID", claims.UserID)
+		c.Set("role", claims.Role)
+		h.bindUserLogger(c, claims.UserID)
+		c.Next()
+	}
+}
+
+// RequireRole пропускает только пользователей с ролью role из токена, остальным отвечает 403.
+// Должен подключаться после AuthMiddleware.
+func (h *Handler) RequireRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("role") != role {
+			userID, _ := c.Get("userID")
+			h.logger.Warn("RequireRole: access denied", "user_id", userID, "required_role", role, "path", c.Request.URL.Path)
+			abortWithError(c, http.StatusForbidden, ErrForbidden)
+			return
+		}
+		c.Next()
+	}
+}
+
+// UsageMiddleware учитывает запрос пользователя в дневной квоте и добавляет заголовки X-RateLimit-*.
+// Квота мягкая: запросы сверх лимита не отклоняются. Должен подключаться после AuthMiddleware.
+func (h *Handler) UsageMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := c.Get("userID")
+		if !ok || h.usageService == nil {
+			c.Next()
+			return
+		}
+
+		quota := h.usageService.Record(c, userID.(int))
+		c.Header(RateLimitLimitHeader, strconv.FormatInt(quota.Limit, 10))
+		c.Header(RateLimitRemainingHeader, strconv.FormatInt(quota.Remaining, 10))
+		c.Header(RateLimitResetHeader, strconv.FormatInt(quota.Reset.Unix(), 10))
+		c.Next()
+	}
+}
+
+// ReplayMiddleware требует уникальный X-Request-Nonce у изменяющих запросов пользователя.
+// Повторный nonce в пределах TTL хранилища отклоняется с 409. Без хранилища nonce не проверяется.
+// Должен подключаться после AuthMiddleware.
+func (h *Handler) ReplayMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if h.nonceStore == nil {
+			c.Next()
+			return
+		}
+		switch c.Request.Method {
+		case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		default:
+			c.Next()
+			return
+		}
+
+		userID, ok := c.Get("userID")
+		if !ok {
+			abortWithError(c, http.StatusUnauthorized, ErrUnauthorized)
+			return
+		}
+
+		nonce := strings.TrimSpace(c.GetHeader(NonceHeader))
+		if nonce == "" {
+			abortWithError(c, http.StatusBadRequest, ErrNonceRequired)
+			return
+		}
+		if len(nonce) > services.MaxNonceLength {
+			abortWithError(c, http.StatusBadRequest, ErrInvalidNonce)
+			return
+		}
+
+		fresh, err := h.nonceStore.Remember(c, userID.(int), nonce)
 		if err != nil {
-			abortWithError(c, http.StatusUnauthorized, ErrInvalidToken)
+			h.logger.Error("ReplayMiddleware: failed to store nonce", "user_id", userID, "error", err)
+			respondError(c, err)
+			return
+		}
+		if !fresh {
+			h.logger.Warn("ReplayMiddleware: replay detected", "user_id", userID, "method", c.Request.Method, "path", c.Request.URL.Path)
+			abortWithAPIError(c, http.StatusConflict, apierror.Error{Code: apierror.CodeReplayDetected, Message: ErrReplayDetected})
 			return
 		}
 
-		c.Set("userID", userID)
 		c.Next()
 	}
 }
 
-// abortWithError - универсальная функция для возврата ошибки в JSON
-func abortWithError(c *gin.Context, status int, msg string) {
-	c.AbortWithStatusJSON(status, gin.H{"error": msg})
+// queryList возвращает значения query-параметра, переданные повторно
+// или через запятую, без пустых элементов
+func queryList(c *gin.Context, key string) []string {
+	var values []string
+	for _, raw := range c.QueryArray(key) {
+		for _, v := range strings.Split(raw, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+	}
+	return values
 }
 
 // Register регистрирует нового пользователя
 // @Summary Регистрация пользователя
-// @Description Регистрирует нового пользователя с указанным логином и паролем
+// @Description Регистрирует нового пользователя с указанным логином и паролем.
+// @Description При PASSWORD_POLICY=true пароль должен содержать букву и цифру, не содержать логин и не быть распространённым;
+// @Description иначе возвращается 400 {"code": "weak_password", "message": "...", "details": {"field": "password", "reason": "..."}}
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param input body services.InputUserInfo true "Данные пользователя"
 // @Success 200 {object} db.User
 // @Header 200 {string} Content-Encoding "gzip"
-// @Failure 400 {object} map[string]string
+// @Failure 400 {object} apierror.Error
+// @Failure 409 {object} apierror.Error
+// @Failure 503 {object} apierror.Error
+// @Failure 504 {object} apierror.Error
 // @Router /register [post]
 func (h *Handler) Register(c *gin.Context) {
-	h.logger.Debug("Register endpoint called")
+	logger := h.requestLogger(c)
+	logger.Debug("Register endpoint called")
 	var input services.InputUserInfo
 	if err := c.ShouldBindJSON(&input); err != nil {
-		h.logger.Warn("Register: invalid input", "error", err)
-		abortWithError(c, http.StatusBadRequest, err.Error())
+		logger.Warn("Register: invalid input", "error", err)
+		abortWithBindError(c, err)
 		return
 	}
 
-	h.logger.Debug("Register: input parsed", "login", input.Login)
-	user, err := h.authService.Register(c, input)
+	logger.Debug("Register: input parsed", "login", input.Login)
+	user, err := h.registrar.Register(c, input)
 	if err != nil {
-		h.logger.Warn("Register: failed to register", "login", input.Login, "error", err)
-		abortWithError(c, http.StatusBadRequest, err.Error())
+		if errors.Is(err, db.ErrLoginTaken) {
+			logger.Warn("Register: login already exists", "login", input.Login)
+			abortWithError(c, http.StatusConflict, ErrLoginExists)
+			return
+		}
+		var weak *services.WeakPasswordError
+		if errors.As(err, &weak) {
+			logger.Warn("Register: weak password", "login", input.Login, "reason", weak.Reason)
+			abortWithAPIError(c, http.StatusBadRequest, apierror.Error{
+				Code:    apierror.CodeWeakPassword,
+				Message: services.ErrWeakPassword,
+				Details: map[string]any{"field": "password", "reason": weak.Reason},
+			})
+			return
+		}
+		logger.Warn("Register: failed to register", "login", input.Login, "error", err)
+		respondError(c, err)
 		return
 	}
 
-	h.logger.Info("Register: user registered", "user_id", user.ID, "login", user.Login)
+	h.observeRegistration()
+	h.audit(c, logging.AuditEvent{Event: logging.AuditRegister, UserID: user.ID, Login: user.Login})
+	logger.Info("Register: user registered", "user_id", user.ID, "login", user.Login)
 	c.JSON(http.StatusOK, user)
 }
 
-// Login аутентифицирует пользователя и возвращает токен
+// Login аутентифицирует пользователя и возвращает токены
 // @Summary Аутентификация пользователя
-// @Description Аутентификация пользователя и возврат JWT
+// @Description Аутентификация пользователя и возврат JWT доступа со сроком 24 часа и refresh-токена для его обновления
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param input body services.InputUserInfo true "Данные пользователя"
-// @Success 200 {object} map[string]string
+// @Success 200 {object} services.TokenPair
 // @Header 200 {string} Content-Encoding "gzip"
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
+// @Failure 400 {object} apierror.Error
+// @Failure 401 {object} apierror.Error
+// @Failure 429 {object} apierror.Error
+// @Header 429 {string} Retry-After "Через сколько секунд можно повторить вход"
+// @Failure 503 {object} apierror.Error
+// @Failure 504 {object} apierror.Error
 // @Router /login [post]
 func (h *Handler) Login(c *gin.Context) {
-	h.logger.Debug("Login endpoint called")
+	logger := h.requestLogger(c)
+	logger.Debug("Login endpoint called")
 	var input services.InputUserInfo
 	if err := c.ShouldBindJSON(&input); err != nil {
-		h.logger.Warn("Login: invalid input", "error", err)
-		abortWithError(c, http.StatusBadRequest, err.Error())
+		logger.Warn("Login: invalid input", "error", err)
+		abortWithBindError(c, err)
 		return
 	}
 
-	h.logger.Debug("Login: input parsed", "login", input.Login)
-	token, err := h.authService.Authenticate(c, input)
+	logger.Debug("Login: input parsed", "login", input.Login)
+	tokens, err := h.authService.Authenticate(c, input)
 	if err != nil {
-		h.logger.Warn("Login: authentication failed", "login", input.Login, "error", err)
+		var locked *services.LoginLockedError
+		if errors.As(err, &locked) {
+			h.observeLoginFailure(loginFailureLocked)
+			h.observeLogin(loginFailureLocked)
+			h.auditLoginFailure(c, input.Login, loginFailureLocked)
+			logger.Warn("Login: login temporarily locked", "login", input.Login, "ip", c.ClientIP())
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(locked.RetryAfter.Seconds()))))
+			abortWithError(c, http.StatusTooManyRequests, services.ErrLoginLocked)
+			return
+		}
+		if db.IsTimeout(err) {
+			h.observeLogin(loginResultError)
+			logger.Error("Login: database query timed out", "login", input.Login, "error", err)
+			abortWithError(c, http.StatusGatewayTimeout, ErrQueryTimeout)
+			return
+		}
+		if errors.Is(err, db.ErrUnavailable) {
+			h.observeLogin(loginResultError)
+			logger.Error("Login: database unavailable", "login", input.Login, "error", err)
+			abortWithError(c, http.StatusServiceUnavailable, ErrServiceUnavailable)
+			return
+		}
+		h.observeLoginFailure(loginFailureInvalidCreds)
+		h.observeLogin(loginFailureInvalidCreds)
+		h.auditLoginFailure(c, input.Login, loginFailureInvalidCreds)
+		logger.Warn("Login: authentication failed", "login", input.Login, "error", err)
 		abortWithError(c, http.StatusUnauthorized, ErrInvalidCreds)
 		return
 	}
 
-	h.logger.Info("Login: user authenticated", "login", input.Login)
-	c.JSON(http.StatusOK, gin.H{"token": token})
+	h.observeLogin(loginResultSuccess)
+	h.audit(c, logging.AuditEvent{Event: logging.AuditLoginSuccess, UserID: tokens.UserID, Login: input.Login})
+	logger.Info("Login: user authenticated", "login", input.Login)
+	c.JSON(http.StatusOK, tokens)
+}
+
+// Logout отзывает текущий токен доступа
+// @Summary Выход
+// @Description Отзывает текущий JWT до истечения его срока; если передан refresh-токен, он тоже отзывается. Последующие запросы с этим JWT получают 401 "token revoked".
+// @Tags auth
+// @Accept json
+// @Security BearerAuth
+// @Param input body services.LogoutRequest false "Refresh-токен для отзыва"
+// @Success 204
+// @Failure 400 {object} apierror.Error
+// @Failure 401 {object} apierror.Error
+// @Router /logout [post]
+// @Security BearerAuth
+func (h *Handler) Logout(c *gin.Context) {
+	userID, ok := c.Get("userID")
+	if !ok {
+		h.logger.Warn("Logout: unauthorized access")
+		abortWithError(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+
+	var req services.LogoutRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			h.logger.Warn("Logout: invalid input", "user_id", userID, "error", err)
+			abortWithBindError(c, err)
+			return
+		}
+	}
+
+	token := strings.TrimSpace(c.GetHeader(AuthHeader))
+	if err := h.authService.Logout(c, token, req.RefreshToken); err != nil {
+		h.logger.Warn("Logout: failed to revoke token", "user_id", userID, "error", err)
+		respondError(c, err)
+		return
+	}
+
+	h.audit(c, logging.AuditEvent{Event: logging.AuditTokenRevoked, UserID: userID.(int)})
+	h.logger.Info("Logout: token revoked", "user_id", userID)
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) observeLoginFailure(reason string) {
+	if h.metrics != nil {
+		h.metrics.LoginFailureCount.WithLabelValues(reason).Inc()
+	}
+}
+
+func (h *Handler) observeAdsCache(hit bool) {
+	if h.metrics == nil {
+		return
+	}
+	if hit {
+		h.metrics.AdsCacheHits.Inc()
+	} else {
+		h.metrics.AdsCacheMisses.Inc()
+	}
+}
+
+// Refresh обменивает refresh-токен на новую пару токенов
+// @Summary Обновление токена
+// @Description Выдаёт новый JWT доступа и новый refresh-токен; переданный refresh-токен отзывается и повторно не принимается
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param input body services.RefreshRequest true "Refresh-токен"
+// @Success 200 {object} services.TokenPair
+// @Failure 400 {object} apierror.Error
+// @Failure 401 {object} apierror.Error
+// @Router /refresh [post]
+func (h *Handler) Refresh(c *gin.Context) {
+	var req services.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("Refresh: invalid input", "error", err)
+		abortWithBindError(c, err)
+		return
+	}
+
+	tokens, err := h.authService.Refresh(c, req.RefreshToken)
+	if err != nil {
+		h.logger.Warn("Refresh: failed to refresh token", "error", err)
+		switch {
+		case errors.Is(err, db.ErrRefreshTokenNotFound),
+			errors.Is(err, db.ErrRefreshTokenRevoked),
+			errors.Is(err, db.ErrRefreshTokenExpired),
+			errors.Is(err, db.ErrUserNotFound):
+			abortWithError(c, http.StatusUnauthorized, db.Message(err))
+		default:
+			respondError(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
 }
 
 // CreateAd создаёт объявление от авторизованного пользователя
@@ -182,98 +703,1415 @@ func (h *Handler) Login(c *gin.Context) {
 // @Param input body services.CreateAdRequest true "Данные объявления"
 // @Success 200 {object} db.Ad
 // @Header 200 {string} Content-Encoding "gzip"
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
+// @Failure 400 {object} apierror.Error
+// @Failure 401 {object} apierror.Error
+// @Failure 404 {object} apierror.Error
+// @Failure 503 {object} apierror.Error
+// @Failure 504 {object} apierror.Error
 // @Router /ads [post]
 // @Security BearerAuth
 func (h *Handler) CreateAd(c *gin.Context) {
-	h.logger.Debug("CreateAd endpoint called")
+	logger := h.requestLogger(c)
+	logger.Debug("CreateAd endpoint called")
 	userID, ok := c.Get("userID")
 	if !ok {
-		h.logger.Warn("CreateAd: unauthorized access")
+		logger.Warn("CreateAd: unauthorized access")
 		abortWithError(c, http.StatusUnauthorized, ErrUnauthorized)
 		return
 	}
 
 	var req services.CreateAdRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		h.logger.Warn("CreateAd: invalid input", "error", err)
-		abortWithError(c, http.StatusBadRequest, err.Error())
+		logger.Warn("CreateAd: invalid input", "error", err)
+		h.observeAdCreateFailure(adFailureInvalidInput)
+		abortWithBindError(c, err)
 		return
 	}
 
-	h.logger.Debug("CreateAd: input parsed", "user_id", userID, "title", req.Title)
+	logger.Debug("CreateAd: input parsed", "title", req.Title)
 	ad, err := h.adService.CreateAd(c, req, userID.(int))
 	if err != nil {
-		h.logger.Warn("CreateAd: failed to create ad", "user_id", userID, "error", err)
-		abortWithError(c, http.StatusBadRequest, err.Error())
+		logger.Warn("CreateAd: failed to create ad", "error", err)
+		h.observeAdCreateFailure(adCreateFailureReason(err))
+		respondError(c, err)
 		return
 	}
 
-	h.logger.Info("CreateAd: ad created", "ad_id", ad.ID, "user_id", ad.UserID, "title", ad.Title)
+	h.observeAdCreated()
+	logger.Info("CreateAd: ad created", "ad_id", ad.ID, "title", ad.Title)
 	c.JSON(http.StatusOK, ad)
 }
 
-// Ads возвращает список объявлений с фильтрацией
-// @Summary Получение списка объявлений
-// @Description Возвращает список объявлений с фильтрами и сортировкой
+// UpdateAd частично обновляет объявление владельца
+// @Summary Частичное обновление объявления
+// @Description Обновляет только переданные поля объявления. Доступно только владельцу.
 // @Tags ads
+// @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param page query int false "Номер страницы" default(1)
-// @Param page_size query int false "Размер страницы" default(10)
-// @Param sort_by query string false "Поле сортировки" default(created_at)
-// @Param sort_order query string false "Порядок сортировки" default(DESC)
-// @Param min_price query number false "Минимальная цена"
-// @Param max_price query number false "Максимальная цена"
-// @Success 200 {array} db.Ad
+// @Param id path int true "ID объявления"
+// @Param input body services.UpdateAdRequest true "Изменяемые поля объявления"
+// @Success 200 {object} db.Ad
 // @Header 200 {string} Content-Encoding "gzip"
-// @Failure 400 {object} map[string]string
-// @Failure 401 {object} map[string]string
-// @Router /ads [get]
+// @Failure 400 {object} apierror.Error
+// @Failure 401 {object} apierror.Error
+// @Failure 403 {object} apierror.Error
+// @Failure 404 {object} apierror.Error
+// @Router /ads/{id} [patch]
 // @Security BearerAuth
-func (h *Handler) Ads(c *gin.Context) {
-	h.logger.Debug("Ads endpoint called")
+func (h *Handler) UpdateAd(c *gin.Context) {
+	h.logger.Debug("UpdateAd endpoint called")
 	userID, ok := c.Get("userID")
 	if !ok {
-		h.logger.Warn("Ads: unauthorized access")
+		h.logger.Warn("UpdateAd: unauthorized access")
 		abortWithError(c, http.StatusUnauthorized, ErrUnauthorized)
 		return
 	}
 
-	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
-	sortBy := c.DefaultQuery("sort_by", "created_at")
-	sortOrder := c.DefaultQuery("sort_order", "DESC")
+	adID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || adID <= 0 {
+		h.logger.Warn("UpdateAd: invalid ad id", "id", c.Param("id"))
+		abortWithError(c, http.StatusBadRequest, ErrInvalidAdID)
+		return
+	}
 
-	var minPrice, maxPrice int64
-	if minStr := c.Query("min_price"); minStr != "" {
-		minPrice, _ = strconv.ParseInt(minStr, 10, 64)
+	var req services.UpdateAdRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("UpdateAd: invalid input", "error", err)
+		abortWithBindError(c, err)
+		return
 	}
-	if maxStr := c.Query("max_price"); maxStr != "" {
-		maxPrice, _ = strconv.ParseInt(maxStr, 10, 64)
+	if req.IsEmpty() {
+		h.logger.Warn("UpdateAd: empty body", "ad_id", adID)
+		abortWithError(c, http.StatusBadRequest, ErrEmptyBody)
+		return
+	}
+
+	h.logger.Debug("UpdateAd: input parsed", "user_id", userID, "ad_id", adID)
+	ad, err := h.adService.UpdateAd(c, adID, req, userID.(int))
+	if err != nil {
+		h.logger.Warn("UpdateAd: failed to update ad", "user_id", userID, "ad_id", adID, "error", err)
+		respondError(c, err)
+		return
 	}
 
-	h.logger.Debug("Ads: params", "user_id", userID, "page", page, "page_size", pageSize, "sort_by", sortBy, "sort_order", sortOrder, "min_price", minPrice, "max_price", maxPrice)
+	h.logger.Info("UpdateAd: ad updated", "ad_id", ad.ID, "user_id", ad.UserID)
+	c.JSON(http.StatusOK, ad)
+}
 
-	req := services.GetAdsRequest{
-		Page:      page,
-		PageSize:  pageSize,
-		SortBy:    sortBy,
-		SortOrder: sortOrder,
-		MinPrice:  minPrice,
-		MaxPrice:  maxPrice,
+// SetAdStatus меняет статус объявления владельца
+// @Summary Смена статуса объявления
+// @Description Переводит объявление в статус active, sold или archived. Доступно только владельцу.
+// @Tags ads
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID объявления"
+// @Param input body services.UpdateAdStatusRequest true "Новый статус"
+// @Success 200 {object} db.Ad
+// @Header 200 {string} Content-Encoding "gzip"
+// @Failure 400 {object} apierror.Error
+// @Failure 401 {object} apierror.Error
+// @Failure 403 {object} apierror.Error
+// @Failure 404 {object} apierror.Error
+// @Router /ads/{id}/status [post]
+// @Security BearerAuth
+func (h *Handler) SetAdStatus(c *gin.Context) {
+	h.logger.Debug("SetAdStatus endpoint called")
+	userID, ok := c.Get("userID")
+	if !ok {
+		h.logger.Warn("SetAdStatus: unauthorized access")
+		abortWithError(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
 	}
 
-	ads, err := h.adService.GetAds(c, req, userID.(int))
+	adID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || adID <= 0 {
+		h.logger.Warn("SetAdStatus: invalid ad id", "id", c.Param("id"))
+		abortWithError(c, http.StatusBadRequest, ErrInvalidAdID)
+		return
+	}
+
+	var req services.UpdateAdStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("SetAdStatus: invalid input", "error", err)
+		abortWithBindError(c, err)
+		return
+	}
+
+	ad, err := h.adService.SetAdStatus(c, adID, req, userID.(int))
 	if err != nil {
-		h.logger.Warn("Ads: failed to fetch ads", "user_id", userID, "error", err)
-		abortWithError(c, http.StatusBadRequest, err.Error())
+		h.logger.Warn("SetAdStatus: failed to change status", "user_id", userID, "ad_id", adID, "error", err)
+		respondError(c, err)
 		return
 	}
 
-	h.logger.Info("Ads: ads fetched", "count", len(ads), "user_id", userID)
-	c.JSON(http.StatusOK, ads)
+	h.logger.Info("SetAdStatus: status changed", "ad_id", ad.ID, "user_id", ad.UserID, "status", ad.Status)
+	c.JSON(http.StatusOK, ad)
+}
+
+// RenewAd продлевает публикацию объявления владельца
+// @Summary Продление объявления
+// @Description Продлевает срок публикации на AD_TTL от текущего момента. Архивное объявление снова становится активным, проданное продлить нельзя. Доступно только владельцу.
+// @Tags ads
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID объявления"
+// @Success 200 {object} db.Ad
+// @Header 200 {string} Content-Encoding "gzip"
+// @Failure 400 {object} apierror.Error
+// @Failure 401 {object} apierror.Error
+// @Failure 403 {object} apierror.Error
+// @Failure 404 {object} apierror.Error
+// @Failure 409 {object} apierror.Error
+// @Router /ads/{id}/renew [post]
+// @Security BearerAuth
+func (h *Handler) RenewAd(c *gin.Context) {
+	userID, ok := c.Get("userID")
+	if !ok {
+		h.logger.Warn("RenewAd: unauthorized access")
+		abortWithError(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+
+	adID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || adID <= 0 {
+		h.logger.Warn("RenewAd: invalid ad id", "id", c.Param("id"))
+		abortWithError(c, http.StatusBadRequest, ErrInvalidAdID)
+		return
+	}
+
+	ad, err := h.adService.RenewAd(c, adID, userID.(int))
+	if err != nil {
+		h.logger.Warn("RenewAd: failed to renew ad", "user_id", userID, "ad_id", adID, "error", err)
+		respondError(c, err)
+		return
+	}
+
+	h.logger.Info("RenewAd: ad renewed", "ad_id", ad.ID, "user_id", ad.UserID, "expires_at", ad.ExpiresAt)
+	c.JSON(http.StatusOK, ad)
+}
+
+// Ad возвращает объявление по ID
+// @Summary Получение объявления
+// @Description Возвращает объявление по ID. Удалённые объявления недоступны, в том числе владельцу.
+// @Tags ads
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID объявления"
+// @Param If-None-Match header string false "ETag из предыдущего ответа; при совпадении ответ 304 без тела"
+// @Success 200 {object} db.Ad
+// @Success 304
+// @Header 200 {string} Content-Encoding "gzip"
+// @Header 200 {string} ETag "Слабый ETag ответа"
+// @Failure 400 {object} apierror.Error
+// @Failure 401 {object} apierror.Error
+// @Failure 404 {object} apierror.Error
+// @Router /ads/{id} [get]
+// @Security BearerAuth
+func (h *Handler) Ad(c *gin.Context) {
+	userID, ok := c.Get("userID")
+	if !ok {
+		h.logger.Warn("Ad: unauthorized access")
+		abortWithError(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+
+	adID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || adID <= 0 {
+		h.logger.Warn("Ad: invalid ad id", "id", c.Param("id"))
+		abortWithError(c, http.StatusBadRequest, ErrInvalidAdID)
+		return
+	}
+
+	ad, err := h.adService.GetAd(c, adID, userID.(int))
+	if err != nil {
+		h.logger.Warn("Ad: failed to fetch ad", "ad_id", adID, "error", err)
+		respondError(c, err)
+		return
+	}
+
+	respondJSONWithETag(c, ad)
+}
+
+// SimilarAds возвращает объявления, похожие на указанное
+// @Summary Похожие объявления
+// @Description Возвращает активные объявления других продавцов, в заголовке или тексте которых есть слова из заголовка указанного объявления. Совпадения в заголовке важнее совпадений в тексте, при равной похожести выше объявления с более близкой ценой. limit больше 20 ограничивается 20.
+// @Tags ads
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID объявления"
+// @Param limit query int false "Максимальное количество объявлений" default(5)
+// @Param Accept-Language header string false "Предпочитаемые языки; поле lang в ответе указывает отданный перевод"
+// @Success 200 {array} db.Ad
+// @Header 200 {string} Content-Encoding "gzip"
+// @Failure 400 {object} apierror.Error
+// @Failure 401 {object} apierror.Error
+// @Failure 404 {object} apierror.Error
+// @Router /ads/{id}/similar [get]
+// @Security BearerAuth
+func (h *Handler) SimilarAds(c *gin.Context) {
+	userID, ok := c.Get("userID")
+	if !ok {
+		h.logger.Warn("SimilarAds: unauthorized access")
+		abortWithError(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+
+	adID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || adID <= 0 {
+		h.logger.Warn("SimilarAds: invalid ad id", "id", c.Param("id"))
+		abortWithError(c, http.StatusBadRequest, ErrInvalidAdID)
+		return
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(services.DefaultSimilarLimit)))
+
+	languages := services.ParseAcceptLanguage(c.GetHeader("Accept-Language"))
+	ads, err := h.adService.GetSimilarAds(c, adID, userID.(int), limit, languages)
+	if err != nil {
+		h.logger.Warn("SimilarAds: failed to fetch similar ads", "ad_id", adID, "error", err)
+		respondError(c, err)
+		return
+	}
+
+	h.logger.Debug("SimilarAds: similar ads fetched", "ad_id", adID, "count", len(ads))
+	c.JSON(http.StatusOK, ads)
+}
+
+// DeleteAd удаляет объявление владельца
+// @Summary Удаление объявления
+// @Description Помечает объявление удалённым. Доступно только владельцу.
+// @Tags ads
+// @Security BearerAuth
+// @Param id path int true "ID объявления"
+// @Success 204
+// @Failure 400 {object} apierror.Error
+// @Failure 401 {object} apierror.Error
+// @Failure 403 {object} apierror.Error
+// @Failure 404 {object} apierror.Error
+// @Router /ads/{id} [delete]
+// @Security BearerAuth
+func (h *Handler) DeleteAd(c *gin.Context) {
+	userID, ok := c.Get("userID")
+	if !ok {
+		h.logger.Warn("DeleteAd: unauthorized access")
+		abortWithError(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+
+	adID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || adID <= 0 {
+		h.logger.Warn("DeleteAd: invalid ad id", "id", c.Param("id"))
+		abortWithError(c, http.StatusBadRequest, ErrInvalidAdID)
+		return
+	}
+
+	if err := h.adService.DeleteAd(c, adID, userID.(int)); err != nil {
+		h.logger.Warn("DeleteAd: failed to delete ad", "user_id", userID, "ad_id", adID, "error", err)
+		respondError(c, err)
+		return
+	}
+
+	h.audit(c, logging.AuditEvent{Event: logging.AuditAdDeleted, UserID: userID.(int), Details: map[string]any{"ad_id": adID}})
+	h.logger.Info("DeleteAd: ad deleted", "ad_id", adID, "user_id", userID)
+	c.Status(http.StatusNoContent)
+}
+
+// RemoveAd удаляет любое объявление по решению администратора
+// @Summary Удаление объявления администратором
+// @Description Помечает объявление удалённым независимо от владельца и запоминает удалившего администратора. Доступно только роли admin.
+// @Tags admin
+// @Security BearerAuth
+// @Param id path int true "ID объявления"
+// @Success 204
+// @Failure 400 {object} apierror.Error
+// @Failure 401 {object} apierror.Error
+// @Failure 403 {object} apierror.Error
+// @Failure 404 {object} apierror.Error
+// @Router /admin/ads/{id} [delete]
+// @Security BearerAuth
+func (h *Handler) RemoveAd(c *gin.Context) {
+	adminID, ok := c.Get("userID")
+	if !ok {
+		h.logger.Warn("RemoveAd: unauthorized access")
+		abortWithError(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+
+	adID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || adID <= 0 {
+		h.logger.Warn("RemoveAd: invalid ad id", "id", c.Param("id"))
+		abortWithError(c, http.StatusBadRequest, ErrInvalidAdID)
+		return
+	}
+
+	if err := h.adModerator.RemoveAd(c, adID, adminID.(int)); err != nil {
+		h.logger.Warn("RemoveAd: failed to remove ad", "admin_id", adminID, "ad_id", adID, "error", err)
+		respondError(c, err)
+		return
+	}
+
+	h.audit(c, logging.AuditEvent{Event: logging.AuditAdRemoved, UserID: adminID.(int), Details: map[string]any{"ad_id": adID}})
+	h.logger.Info("RemoveAd: ad removed by admin", "admin_id", adminID, "ad_id", adID)
+	c.Status(http.StatusNoContent)
+}
+
+// ReserveAd бронирует объявление для текущего пользователя
+// @Summary Бронирование объявления
+// @Description Создаёт бронирование объявления покупателем. У объявления может быть только одно активное бронирование; оно истекает через настраиваемый срок.
+// @Tags reservations
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID объявления"
+// @Success 201 {object} db.Reservation
+// @Header 201 {string} Content-Encoding "gzip"
+// @Failure 400 {object} apierror.Error
+// @Failure 401 {object} apierror.Error
+// @Failure 404 {object} apierror.Error
+// @Failure 409 {object} apierror.Error
+// @Router /ads/{id}/reserve [post]
+// @Security BearerAuth
+func (h *Handler) ReserveAd(c *gin.Context) {
+	userID, ok := c.Get("userID")
+	if !ok {
+		h.logger.Warn("ReserveAd: unauthorized access")
+		abortWithError(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+
+	adID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || adID <= 0 {
+		abortWithError(c, http.StatusBadRequest, ErrInvalidAdID)
+		return
+	}
+
+	reservation, err := h.reservationService.Reserve(c, adID, userID.(int))
+	if err != nil {
+		h.logger.Warn("ReserveAd: failed to reserve ad", "user_id", userID, "ad_id", adID, "error", err)
+		respondError(c, err)
+		return
+	}
+
+	h.logger.Info("ReserveAd: ad reserved", "ad_id", adID, "reservation_id", reservation.ID, "buyer_id", userID)
+	c.JSON(http.StatusCreated, reservation)
+}
+
+// CancelReservation отменяет активное бронирование объявления
+// @Summary Отмена бронирования
+// @Description Отменяет активное бронирование объявления. Доступно покупателю и продавцу.
+// @Tags reservations
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID объявления"
+// @Success 200 {object} db.Reservation
+// @Header 200 {string} Content-Encoding "gzip"
+// @Failure 400 {object} apierror.Error
+// @Failure 401 {object} apierror.Error
+// @Failure 403 {object} apierror.Error
+// @Failure 404 {object} apierror.Error
+// @Router /ads/{id}/reserve [delete]
+// @Security BearerAuth
+func (h *Handler) CancelReservation(c *gin.Context) {
+	userID, ok := c.Get("userID")
+	if !ok {
+		h.logger.Warn("CancelReservation: unauthorized access")
+		abortWithError(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+
+	adID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || adID <= 0 {
+		abortWithError(c, http.StatusBadRequest, ErrInvalidAdID)
+		return
+	}
+
+	reservation, err := h.reservationService.Cancel(c, adID, userID.(int))
+	if err != nil {
+		h.logger.Warn("CancelReservation: failed to cancel reservation", "user_id", userID, "ad_id", adID, "error", err)
+		respondError(c, err)
+		return
+	}
+
+	h.logger.Info("CancelReservation: reservation cancelled", "ad_id", adID, "reservation_id", reservation.ID, "user_id", userID)
+	c.JSON(http.StatusOK, reservation)
+}
+
+// ConfirmReservation подтверждает бронирование продавцом
+// @Summary Подтверждение бронирования
+// @Description Подтверждает бронирование: объявление переходит в статус sold, покупатель сохраняется. Доступно только продавцу.
+// @Tags reservations
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID объявления"
+// @Param rid path int true "ID бронирования"
+// @Success 200 {object} db.Reservation
+// @Header 200 {string} Content-Encoding "gzip"
+// @Failure 400 {object} apierror.Error
+// @Failure 401 {object} apierror.Error
+// @Failure 403 {object} apierror.Error
+// @Failure 404 {object} apierror.Error
+// @Failure 409 {object} apierror.Error
+// @Router /ads/{id}/reservations/{rid}/confirm [post]
+// @Security BearerAuth
+func (h *Handler) ConfirmReservation(c *gin.Context) {
+	userID, ok := c.Get("userID")
+	if !ok {
+		h.logger.Warn("ConfirmReservation: unauthorized access")
+		abortWithError(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+
+	adID, err := strconv.Atoi(c.Param("id"))
+	if err != nil || adID <= 0 {
+		abortWithError(c, http.StatusBadRequest, ErrInvalidAdID)
+		return
+	}
+	reservationID, err := strconv.Atoi(c.Param("rid"))
+	if err != nil || reservationID <= 0 {
+		abortWithError(c, http.StatusBadRequest, ErrInvalidReservationID)
+		return
+	}
+
+	reservation, err := h.reservationService.Confirm(c, adID, reservationID, userID.(int))
+	if err != nil {
+		h.logger.Warn("ConfirmReservation: failed to confirm reservation", "user_id", userID, "ad_id", adID, "reservation_id", reservationID, "error", err)
+		respondError(c, err)
+		return
+	}
+
+	h.logger.Info("ConfirmReservation: reservation confirmed", "ad_id", adID, "reservation_id", reservation.ID, "buyer_id", reservation.BuyerID)
+	c.JSON(http.StatusOK, reservation)
+}
+
+// Ads возвращает список объявлений с фильтрацией
+// @Summary Получение списка объявлений
+// @Description Возвращает список объявлений с фильтрами и сортировкой
+// @Tags ads
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Номер страницы" default(1)
+// @Param page_size query int false "Размер страницы" default(10)
+// @Param sort_by query string false "Поле сортировки: created_at, price или title" Enums(created_at, price, title) default(created_at)
+// @Param sort_order query string false "Порядок сортировки" default(DESC)
+// @Param min_price query int false "Минимальная цена в копейках; неотрицательное целое"
+// @Param max_price query int false "Максимальная цена в копейках; неотрицательное целое не меньше min_price"
+// @Param status query string false "Статусы через запятую: active, sold, archived" default(active)
+// @Param created_from query string false "Создано не раньше (RFC3339, включительно)"
+// @Param created_to query string false "Создано не позже (RFC3339, включительно)"
+// @Param tag query []string false "Тег; параметр повторяется, возвращаются объявления со всеми указанными тегами" collectionFormat(multi)
+// @Param city query string false "Города через запятую без учёта регистра; возвращаются объявления из любого указанного города"
+// @Param Accept-Language header string false "Предпочитаемые языки; поле lang в ответе указывает отданный перевод"
+// @Param q query string false "Полнотекстовый поиск по заголовку и тексту; результаты упорядочены по релевантности, sort_by не учитывается"
+// @Param include_meta query bool false "Вернуть services.PagedAds с total, page, page_size и total_pages вместо массива"
+// @Param count query bool false "Считать общее количество объявлений для заголовка X-Total-Count" default(true)
+// @Param cursor query string false "Курсор из next_cursor; пустое значение - первая страница. Включает курсорную пагинацию: ответ имеет вид services.AdsPage, page игнорируется"
+// @Param If-None-Match header string false "ETag из предыдущего ответа; при совпадении ответ 304 без тела"
+// @Success 200 {array} db.Ad
+// @Success 304
+// @Header 200 {string} Content-Encoding "gzip"
+// @Header 200 {string} ETag "Слабый ETag ответа"
+// @Header 200 {int} X-Total-Count "Общее количество объявлений по фильтрам (если count не false)"
+// @Failure 400 {object} apierror.Error
+// @Failure 401 {object} apierror.Error
+// @Failure 503 {object} apierror.Error
+// @Failure 504 {object} apierror.Error
+// @Router /ads [get]
+// @Security BearerAuth
+func (h *Handler) Ads(c *gin.Context) {
+	logger := h.requestLogger(c)
+	logger.Debug("Ads endpoint called")
+	userID, ok := c.Get("userID")
+	if !ok {
+		logger.Warn("Ads: unauthorized access")
+		abortWithError(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+
+	req, ok := parseAdsRequest(c, logger, "Ads")
+	if !ok {
+		return
+	}
+	req.Languages = services.ParseAcceptLanguage(c.GetHeader("Accept-Language"))
+	req.Query = strings.TrimSpace(c.Query("q"))
+
+	if cursor, ok := c.GetQuery("cursor"); ok {
+		req.Cursor = cursor
+		adsPage, err := h.adService.GetAdsAfterCursor(c, req, userID.(int))
+		if err != nil {
+			logger.Warn("Ads: failed to fetch ads by cursor", "error", err)
+			respondError(c, err)
+			return
+		}
+
+		logger.Info("Ads: ads fetched by cursor", "count", len(adsPage.Ads))
+		respondJSONWithETag(c, adsPage)
+		return
+	}
+
+	includeMeta, _ := strconv.ParseBool(c.Query("include_meta"))
+	withCount := true
+	if count, err := strconv.ParseBool(c.Query("count")); err == nil {
+		withCount = count
+	}
+	if includeMeta || withCount {
+		paged, err := h.adService.GetAdsWithMeta(c, req, userID.(int))
+		if err != nil {
+			logger.Warn("Ads: failed to fetch ads", "error", err)
+			respondError(c, err)
+			return
+		}
+
+		logger.Info("Ads: ads fetched", "count", len(paged.Items), "total", paged.Total)
+		c.Header(TotalCountHeader, strconv.Itoa(paged.Total))
+		if includeMeta {
+			respondJSONWithETag(c, paged)
+		} else {
+			respondJSONWithETag(c, paged.Items)
+		}
+		return
+	}
+
+	ads, err := h.adService.GetAds(c, req, userID.(int))
+	if err != nil {
+		logger.Warn("Ads: failed to fetch ads", "error", err)
+		respondError(c, err)
+		return
+	}
+
+	logger.Info("Ads: ads fetched", "count", len(ads))
+	respondJSONWithETag(c, ads)
+}
+
+// parseAdsRequest разбирает пагинацию, сортировку и фильтры списка объявлений из query-параметров.
+// При некорректном значении отвечает 400 и возвращает false; name - префикс записей журнала.
+func parseAdsRequest(c *gin.Context, logger logging.Logger, name string) (services.GetAdsRequest, bool) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+	sortBy := c.DefaultQuery("sort_by", "created_at")
+	sortOrder := c.DefaultQuery("sort_order", "DESC")
+
+	var minPrice, maxPrice int64
+	if minStr := c.Query("min_price"); minStr != "" {
+		parsed, err := strconv.ParseInt(minStr, 10, 64)
+		if err != nil || parsed < 0 {
+			logger.Warn(name+": invalid min_price", "min_price", minStr)
+			abortWithError(c, http.StatusBadRequest, ErrInvalidMinPrice)
+			return services.GetAdsRequest{}, false
+		}
+		minPrice = parsed
+	}
+	if maxStr := c.Query("max_price"); maxStr != "" {
+		parsed, err := strconv.ParseInt(maxStr, 10, 64)
+		if err != nil || parsed < 0 {
+			logger.Warn(name+": invalid max_price", "max_price", maxStr)
+			abortWithError(c, http.StatusBadRequest, ErrInvalidMaxPrice)
+			return services.GetAdsRequest{}, false
+		}
+		maxPrice = parsed
+		if minPrice > maxPrice {
+			logger.Warn(name+": invalid price range", "min_price", minPrice, "max_price", maxPrice)
+			abortWithError(c, http.StatusBadRequest, fmt.Sprintf(ErrInvalidPriceRange, minPrice, maxPrice))
+			return services.GetAdsRequest{}, false
+		}
+	}
+	statuses := queryList(c, "status")
+	tags := c.QueryArray("tag")
+	cities := queryList(c, "city")
+
+	var createdFrom, createdTo time.Time
+	if fromStr := c.Query("created_from"); fromStr != "" {
+		parsed, err := time.Parse(time.RFC3339, fromStr)
+		if err != nil {
+			logger.Warn(name+": invalid created_from", "created_from", fromStr)
+			abortWithError(c, http.StatusBadRequest, ErrInvalidCreatedFrom)
+			return services.GetAdsRequest{}, false
+		}
+		createdFrom = parsed
+	}
+	if toStr := c.Query("created_to"); toStr != "" {
+		parsed, err := time.Parse(time.RFC3339, toStr)
+		if err != nil {
+			logger.Warn(name+": invalid created_to", "created_to", toStr)
+			abortWithError(c, http.StatusBadRequest, ErrInvalidCreatedTo)
+			return services.GetAdsRequest{}, false
+		}
+		createdTo = parsed
+	}
+
+	logger.Debug(name+": params", "page", page, "page_size", pageSize, "sort_by", sortBy, "sort_order", sortOrder, "min_price", minPrice, "max_price", maxPrice, "status", statuses, "tag", tags, "city", cities)
+
+	return services.GetAdsRequest{
+		Page:      page,
+		PageSize:  pageSize,
+		SortBy:    sortBy,
+		SortOrder: sortOrder,
+		MinPrice:  minPrice,
+		MaxPrice:  maxPrice,
+		Statuses:  statuses,
+		Tags:      tags,
+		Cities:    cities,
+
+		CreatedFrom: createdFrom,
+		CreatedTo:   createdTo,
+	}, true
+}
+
+// MyAds возвращает объявления текущего пользователя
+// @Summary Получение своих объявлений
+// @Description Возвращает объявления авторизованного пользователя с пагинацией и сортировкой
+// @Tags ads
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Номер страницы" default(1)
+// @Param page_size query int false "Размер страницы" default(10)
+// @Param sort_by query string false "Поле сортировки: created_at, price или title" Enums(created_at, price, title) default(created_at)
+// @Param sort_order query string false "Порядок сортировки" default(DESC)
+// @Success 200 {array} db.Ad
+// @Header 200 {string} Content-Encoding "gzip"
+// @Failure 400 {object} apierror.Error
+// @Failure 401 {object} apierror.Error
+// @Router /ads/my [get]
+// @Security BearerAuth
+func (h *Handler) MyAds(c *gin.Context) {
+	h.logger.Debug("MyAds endpoint called")
+	userID, ok := c.Get("userID")
+	if !ok {
+		h.logger.Warn("MyAds: unauthorized access")
+		abortWithError(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+
+	req := services.GetAdsRequest{
+		Page:      page,
+		PageSize:  pageSize,
+		SortBy:    c.DefaultQuery("sort_by", "created_at"),
+		SortOrder: c.DefaultQuery("sort_order", "DESC"),
+	}
+
+	h.logger.Debug("MyAds: params", "user_id", userID, "page", req.Page, "page_size", req.PageSize, "sort_by", req.SortBy, "sort_order", req.SortOrder)
+	ads, err := h.adService.GetMyAds(c, req, userID.(int))
+	if err != nil {
+		h.logger.Warn("MyAds: failed to fetch ads", "user_id", userID, "error", err)
+		respondError(c, err)
+		return
+	}
+
+	h.logger.Info("MyAds: ads fetched", "count", len(ads), "user_id", userID)
+	c.JSON(http.StatusOK, ads)
+}
+
+// Suggest возвращает подсказки для строки поиска
+// @Summary Подсказки для поиска
+// @Description Возвращает слова из заголовков объявлений, начинающиеся с указанного префикса, упорядоченные по частоте. Префикс короче 2 символов даёт пустой список.
+// @Tags ads
+// @Produce json
+// @Security BearerAuth
+// @Param q query string true "Префикс для поиска"
+// @Param limit query int false "Максимальное количество подсказок" default(8)
+// @Success 200 {array} string
+// @Header 200 {string} Content-Encoding "gzip"
+// @Failure 400 {object} apierror.Error
+// @Failure 401 {object} apierror.Error
+// @Router /ads/suggest [get]
+// @Security BearerAuth
+func (h *Handler) Suggest(c *gin.Context) {
+	h.logger.Debug("Suggest endpoint called")
+	q := c.Query("q")
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(services.DefaultSuggestLimit)))
+
+	words, err := h.adService.Suggest(c, q, limit)
+	if err != nil {
+		h.logger.Warn("Suggest: failed to fetch suggestions", "q", q, "error", err)
+		respondError(c, err)
+		return
+	}
+
+	h.logger.Debug("Suggest: suggestions fetched", "q", q, "count", len(words))
+	c.JSON(http.StatusOK, words)
+}
+
+// DailyStats возвращает временной ряд дневной статистики
+// @Summary Дневная статистика маркетплейса
+// @Description Возвращает количество созданных объявлений или зарегистрированных пользователей по дням (UTC). По умолчанию - последние 30 дней.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Param from query string false "Начало периода (YYYY-MM-DD)"
+// @Param to query string false "Конец периода (YYYY-MM-DD)"
+// @Param metric query string false "Метрика: ads_created или users_registered" default(ads_created)
+// @Success 200 {object} services.DailyStatsResponse
+// @Header 200 {string} Content-Encoding "gzip"
+// @Failure 400 {object} apierror.Error
+// @Failure 401 {object} apierror.Error
+// @Router /admin/stats/daily [get]
+// @Security BearerAuth
+func (h *Handler) DailyStats(c *gin.Context) {
+	h.logger.Debug("DailyStats endpoint called")
+
+	to := time.Now().UTC().Truncate(24 * time.Hour)
+	if toStr := c.Query("to"); toStr != "" {
+		parsed, err := time.Parse(services.StatsDateLayout, toStr)
+		if err != nil {
+			abortWithError(c, http.StatusBadRequest, ErrInvalidDate)
+			return
+		}
+		to = parsed
+	}
+	from := to.AddDate(0, 0, -(services.DefaultStatsDays - 1))
+	if fromStr := c.Query("from"); fromStr != "" {
+		parsed, err := time.Parse(services.StatsDateLayout, fromStr)
+		if err != nil {
+			abortWithError(c, http.StatusBadRequest, ErrInvalidDate)
+			return
+		}
+		from = parsed
+	}
+	if to.Sub(from) >= services.MaxStatsDays*24*time.Hour {
+		abortWithError(c, http.StatusBadRequest, ErrStatsRange)
+		return
+	}
+
+	req := services.GetDailyStatsRequest{
+		Metric: c.DefaultQuery("metric", db.StatsMetricAdsCreated),
+		From:   from,
+		To:     to,
+	}
+	stats, err := h.statsService.DailyStats(c, req)
+	if err != nil {
+		h.logger.Warn("DailyStats: failed to fetch stats", "metric", req.Metric, "error", err)
+		respondError(c, err)
+		return
+	}
+
+	h.logger.Debug("DailyStats: stats fetched", "metric", req.Metric, "points", len(stats.Points))
+	c.JSON(http.StatusOK, stats)
+}
+
+// Announcements возвращает действующие объявления администрации
+// @Summary Действующие объявления администрации
+// @Description Возвращает объявления администрации (например, о техработах), действующие в текущий момент, по убыванию важности. Не требует авторизации.
+// @Tags announcements
+// @Produce json
+// @Success 200 {array} db.Announcement
+// @Header 200 {string} Content-Encoding "gzip"
+// @Failure 500 {object} apierror.Error
+// @Router /announcements [get]
+func (h *Handler) Announcements(c *gin.Context) {
+	announcements, err := h.announcementService.Active(c)
+	if err != nil {
+		h.logger.Error("Announcements: failed to fetch announcements", "error", err)
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, announcements)
+}
+
+// SetUserRole назначает роль пользователю
+// @Summary Назначение роли
+// @Description Назначает пользователю роль user или admin. Новая роль действует после повторного входа пользователя.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param login path string true "Логин пользователя"
+// @Param input body services.SetRoleRequest true "Новая роль"
+// @Success 200 {object} db.User
+// @Failure 400 {object} apierror.Error
+// @Failure 401 {object} apierror.Error
+// @Failure 403 {object} apierror.Error
+// @Failure 404 {object} apierror.Error
+// @Router /admin/users/{login}/role [put]
+func (h *Handler) SetUserRole(c *gin.Context) {
+	login := c.Param("login")
+
+	var req services.SetRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("SetUserRole: invalid request body", "login", login, "error", err)
+		abortWithBindError(c, err)
+		return
+	}
+
+	user, err := h.authService.SetRole(c, login, req.Role)
+	if err != nil {
+		h.logger.Warn("SetUserRole: failed to set role", "login", login, "error", err)
+		respondError(c, err)
+		return
+	}
+
+	adminID := c.GetInt("userID")
+	h.audit(c, logging.AuditEvent{Event: logging.AuditRoleChanged, UserID: adminID, Login: login, Details: map[string]any{"role": user.Role}})
+	h.logger.Info("SetUserRole: user role changed", "admin_id", adminID, "login", login, "role", user.Role)
+	c.JSON(http.StatusOK, user)
+}
+
+// AdminAnnouncements возвращает все объявления администрации
+// @Summary Список объявлений администрации
+// @Description Возвращает все объявления администрации, включая запланированные и завершённые
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} db.Announcement
+// @Header 200 {string} Content-Encoding "gzip"
+// @Failure 401 {object} apierror.Error
+// @Failure 500 {object} apierror.Error
+// @Router /admin/announcements [get]
+// @Security BearerAuth
+func (h *Handler) AdminAnnouncements(c *gin.Context) {
+	announcements, err := h.announcementService.List(c)
+	if err != nil {
+		h.logger.Error("AdminAnnouncements: failed to fetch announcements", "error", err)
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, announcements)
+}
+
+// CreateAnnouncement создаёт объявление администрации
+// @Summary Создание объявления администрации
+// @Description Создаёт объявление, которое показывается клиентам с starts_at до ends_at
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param input body services.AnnouncementRequest true "Объявление администрации"
+// @Success 201 {object} db.Announcement
+// @Header 201 {string} Content-Encoding "gzip"
+// @Failure 400 {object} apierror.Error
+// @Failure 401 {object} apierror.Error
+// @Router /admin/announcements [post]
+// @Security BearerAuth
+func (h *Handler) CreateAnnouncement(c *gin.Context) {
+	var req services.AnnouncementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("CreateAnnouncement: invalid input", "error", err)
+		abortWithBindError(c, err)
+		return
+	}
+
+	announcement, err := h.announcementService.Create(c, req)
+	if err != nil {
+		h.logger.Warn("CreateAnnouncement: failed to create announcement", "error", err)
+		respondError(c, err)
+		return
+	}
+
+	h.logger.Info("CreateAnnouncement: announcement created", "announcement_id", announcement.ID, "severity", announcement.Severity)
+	c.JSON(http.StatusCreated, announcement)
+}
+
+// UpdateAnnouncement заменяет объявление администрации
+// @Summary Изменение объявления администрации
+// @Description Полностью заменяет текст, важность и интервал показа объявления
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID объявления администрации"
+// @Param input body services.AnnouncementRequest true "Объявление администрации"
+// @Success 200 {object} db.Announcement
+// @Header 200 {string} Content-Encoding "gzip"
+// @Failure 400 {object} apierror.Error
+// @Failure 401 {object} apierror.Error
+// @Failure 404 {object} apierror.Error
+// @Router /admin/announcements/{id} [put]
+// @Security BearerAuth
+func (h *Handler) UpdateAnnouncement(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		abortWithError(c, http.StatusBadRequest, ErrInvalidAnnouncementID)
+		return
+	}
+
+	var req services.AnnouncementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("UpdateAnnouncement: invalid input", "error", err)
+		abortWithBindError(c, err)
+		return
+	}
+
+	announcement, err := h.announcementService.Update(c, id, req)
+	if err != nil {
+		h.logger.Warn("UpdateAnnouncement: failed to update announcement", "announcement_id", id, "error", err)
+		respondError(c, err)
+		return
+	}
+
+	h.logger.Info("UpdateAnnouncement: announcement updated", "announcement_id", announcement.ID)
+	c.JSON(http.StatusOK, announcement)
+}
+
+// DeleteAnnouncement удаляет объявление администрации
+// @Summary Удаление объявления администрации
+// @Tags admin
+// @Security BearerAuth
+// @Param id path int true "ID объявления администрации"
+// @Success 204
+// @Failure 400 {object} apierror.Error
+// @Failure 401 {object} apierror.Error
+// @Failure 404 {object} apierror.Error
+// @Router /admin/announcements/{id} [delete]
+// @Security BearerAuth
+func (h *Handler) DeleteAnnouncement(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		abortWithError(c, http.StatusBadRequest, ErrInvalidAnnouncementID)
+		return
+	}
+
+	if err := h.announcementService.Delete(c, id); err != nil {
+		h.logger.Warn("DeleteAnnouncement: failed to delete announcement", "announcement_id", id, "error", err)
+		respondError(c, err)
+		return
+	}
+
+	h.logger.Info("DeleteAnnouncement: announcement deleted", "announcement_id", id)
+	c.Status(http.StatusNoContent)
+}
+
+// Notifications возвращает ленту уведомлений текущего пользователя
+// @Summary Лента уведомлений
+// @Description Возвращает уведомления пользователя: сначала непрочитанные, затем по убыванию даты
+// @Tags notifications
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Номер страницы" default(1)
+// @Param page_size query int false "Размер страницы" default(20)
+// @Success 200 {array} db.Notification
+// @Header 200 {string} Content-Encoding "gzip"
+// @Failure 400 {object} apierror.Error
+// @Failure 401 {object} apierror.Error
+// @Router /notifications [get]
+// @Security BearerAuth
+func (h *Handler) Notifications(c *gin.Context) {
+	userID, ok := c.Get("userID")
+	if !ok {
+		h.logger.Warn("Notifications: unauthorized access")
+		abortWithError(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "20"))
+	if page < 1 || pageSize < 1 || pageSize > 100 {
+		abortWithError(c, http.StatusBadRequest, ErrInvalidPagination)
+		return
+	}
+
+	req := services.GetNotificationsRequest{Page: page, PageSize: pageSize}
+	notifications, err := h.notificationService.List(c, req, userID.(int))
+	if err != nil {
+		h.logger.Warn("Notifications: failed to fetch notifications", "user_id", userID, "error", err)
+		respondError(c, err)
+		return
+	}
+
+	h.logger.Debug("Notifications: notifications fetched", "user_id", userID, "count", len(notifications))
+	c.JSON(http.StatusOK, notifications)
+}
+
+// ReadNotification отмечает уведомление прочитанным
+// @Summary Отметка уведомления прочитанным
+// @Tags notifications
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "ID уведомления"
+// @Success 200 {object} db.Notification
+// @Header 200 {string} Content-Encoding "gzip"
+// @Failure 400 {object} apierror.Error
+// @Failure 401 {object} apierror.Error
+// @Failure 404 {object} apierror.Error
+// @Router /notifications/{id}/read [post]
+// @Security BearerAuth
+func (h *Handler) ReadNotification(c *gin.Context) {
+	userID, ok := c.Get("userID")
+	if !ok {
+		h.logger.Warn("ReadNotification: unauthorized access")
+		abortWithError(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		abortWithError(c, http.StatusBadRequest, ErrInvalidNotificationID)
+		return
+	}
+
+	notification, err := h.notificationService.MarkRead(c, id, userID.(int))
+	if err != nil {
+		h.logger.Warn("ReadNotification: failed to mark notification read", "user_id", userID, "notification_id", id, "error", err)
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, notification)
+}
+
+// ReadAllNotifications отмечает прочитанными все уведомления пользователя
+// @Summary Отметка всех уведомлений прочитанными
+// @Tags notifications
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]int64
+// @Header 200 {string} Content-Encoding "gzip"
+// @Failure 401 {object} apierror.Error
+// @Router /notifications/read-all [post]
+// @Security BearerAuth
+func (h *Handler) ReadAllNotifications(c *gin.Context) {
+	userID, ok := c.Get("userID")
+	if !ok {
+		h.logger.Warn("ReadAllNotifications: unauthorized access")
+		abortWithError(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+
+	updated, err := h.notificationService.MarkAllRead(c, userID.(int))
+	if err != nil {
+		h.logger.Error("ReadAllNotifications: failed to mark notifications read", "user_id", userID, "error", err)
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"updated": updated})
+}
+
+// Profile возвращает учётную запись текущего пользователя
+// @Summary Профиль пользователя
+// @Description Возвращает id, логин, дату регистрации и количество объявлений текущего пользователя
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} services.Profile
+// @Header 200 {string} Content-Encoding "gzip"
+// @Failure 401 {object} apierror.Error
+// @Failure 404 {object} apierror.Error
+// @Router /users/me [get]
+// @Security BearerAuth
+func (h *Handler) Profile(c *gin.Context) {
+	userID, ok := c.Get("userID")
+	if !ok {
+		h.logger.Warn("Profile: unauthorized access")
+		abortWithError(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+
+	profile, err := h.authService.Profile(c, userID.(int))
+	if err != nil {
+		h.logger.Warn("Profile: failed to fetch profile", "user_id", userID, "error", err)
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// DeleteMe удаляет учётную запись текущего пользователя
+// @Summary Удаление учётной записи
+// @Description Удаляет учётную запись текущего пользователя вместе с объявлениями. Требует текущий пароль; после удаления токен перестаёт действовать.
+// @Tags users
+// @Accept json
+// @Security BearerAuth
+// @Param input body services.DeleteAccountRequest true "Текущий пароль"
+// @Success 204
+// @Failure 400 {object} apierror.Error
+// @Failure 401 {object} apierror.Error
+// @Failure 403 {object} apierror.Error
+// @Router /users/me [delete]
+// @Security BearerAuth
+func (h *Handler) DeleteMe(c *gin.Context) {
+	userID, ok := c.Get("userID")
+	if !ok {
+		h.logger.Warn("DeleteMe: unauthorized access")
+		abortWithError(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+
+	var req services.DeleteAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("DeleteMe: invalid request body", "user_id", userID, "error", err)
+		abortWithBindError(c, err)
+		return
+	}
+
+	if err := h.authService.DeleteAccount(c, userID.(int), req.Password); err != nil {
+		h.logger.Warn("DeleteMe: failed to delete account", "user_id", userID, "error", err)
+		respondError(c, err)
+		return
+	}
+
+	h.audit(c, logging.AuditEvent{Event: logging.AuditAccountDeleted, UserID: userID.(int)})
+	h.logger.Info("DeleteMe: account deleted", "user_id", userID)
+	c.Status(http.StatusNoContent)
+}
+
+// SellerProfile возвращает публичный профиль продавца
+// @Summary Профиль продавца
+// @Description Возвращает логин и дату регистрации продавца и его активные объявления с пагинацией и сортировкой
+// @Tags users
+// @Produce json
+// @Param login path string true "Логин продавца"
+// @Param page query int false "Номер страницы" default(1)
+// @Param page_size query int false "Размер страницы" default(10)
+// @Param sort_by query string false "Поле сортировки: created_at, price или title" Enums(created_at, price, title) default(created_at)
+// @Param sort_order query string false "Порядок сортировки" default(DESC)
+// @Success 200 {object} services.SellerProfile
+// @Failure 400 {object} apierror.Error
+// @Failure 404 {object} apierror.Error
+// @Router /users/{login} [get]
+func (h *Handler) SellerProfile(c *gin.Context) {
+	login := c.Param("login")
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+
+	req := services.GetAdsRequest{
+		Page:      page,
+		PageSize:  pageSize,
+		SortBy:    c.DefaultQuery("sort_by", "created_at"),
+		SortOrder: c.DefaultQuery("sort_order", "DESC"),
+		Languages: services.ParseAcceptLanguage(c.GetHeader("Accept-Language")),
+	}
+
+	profile, err := h.adService.GetSellerProfile(c, login, req)
+	if err != nil {
+		h.logger.Warn("SellerProfile: failed to fetch profile", "login", login, "error", err)
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// Preferences возвращает настройки уведомлений текущего пользователя
+// @Summary Настройки уведомлений
+// @Description Возвращает типы уведомлений, явно включённые или отключённые пользователем. Отсутствующие типы включены.
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]bool
+// @Header 200 {string} Content-Encoding "gzip"
+// @Failure 401 {object} apierror.Error
+// @Router /users/me/preferences [get]
+// @Security BearerAuth
+func (h *Handler) Preferences(c *gin.Context) {
+	userID, ok := c.Get("userID")
+	if !ok {
+		h.logger.Warn("Preferences: unauthorized access")
+		abortWithError(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+
+	prefs, err := h.notificationService.Preferences(c, userID.(int))
+	if err != nil {
+		h.logger.Warn("Preferences: failed to fetch preferences", "user_id", userID, "error", err)
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, prefs)
+}
+
+// UpdatePreferences изменяет настройки уведомлений текущего пользователя
+// @Summary Изменение настроек уведомлений
+// @Description Включает или отключает переданные типы уведомлений; остальные настройки сохраняются
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param input body map[string]bool true "Типы уведомлений и признак включения"
+// @Success 200 {object} map[string]bool
+// @Header 200 {string} Content-Encoding "gzip"
+// @Failure 400 {object} apierror.Error
+// @Failure 401 {object} apierror.Error
+// @Router /users/me/preferences [patch]
+// @Security BearerAuth
+func (h *Handler) UpdatePreferences(c *gin.Context) {
+	userID, ok := c.Get("userID")
+	if !ok {
+		h.logger.Warn("UpdatePreferences: unauthorized access")
+		abortWithError(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+
+	var req db.Preferences
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("UpdatePreferences: invalid input", "error", err)
+		abortWithBindError(c, err)
+		return
+	}
+	if len(req) == 0 {
+		abortWithError(c, http.StatusBadRequest, ErrEmptyBody)
+		return
+	}
+
+	prefs, err := h.notificationService.UpdatePreferences(c, req, userID.(int))
+	if err != nil {
+		h.logger.Warn("UpdatePreferences: failed to update preferences", "user_id", userID, "error", err)
+		respondError(c, err)
+		return
+	}
+
+	h.logger.Info("UpdatePreferences: preferences updated", "user_id", userID)
+	c.JSON(http.StatusOK, prefs)
+}
+
+// Usage возвращает использование API текущим пользователем
+// @Summary Использование API
+// @Description Возвращает количество запросов текущего пользователя по дням (UTC) за последние days дней и дневную квоту. Квота мягкая: заголовки X-RateLimit-* на авторизованных ответах показывают её остаток.
+// @Tags users
+// @Produce json
+// @Security BearerAuth
+// @Param days query int false "Количество дней, от 1 до 90" default(30)
+// @Success 200 {object} services.UsageResponse
+// @Header 200 {string} Content-Encoding "gzip"
+// @Failure 400 {object} apierror.Error
+// @Failure 401 {object} apierror.Error
+// @Router /users/me/usage [get]
+// @Security BearerAuth
+func (h *Handler) Usage(c *gin.Context) {
+	userID, ok := c.Get("userID")
+	if !ok {
+		h.logger.Warn("Usage: unauthorized access")
+		abortWithError(c, http.StatusUnauthorized, ErrUnauthorized)
+		return
+	}
+
+	days, err := strconv.Atoi(c.DefaultQuery("days", strconv.Itoa(services.DefaultUsageDays)))
+	if err != nil || days < 1 || days > services.MaxUsageDays {
+		abortWithError(c, http.StatusBadRequest, ErrInvalidUsageDays)
+		return
+	}
+
+	usage, err := h.usageService.Usage(c, userID.(int), days)
+	if err != nil {
+		h.logger.Warn("Usage: failed to fetch usage", "user_id", userID, "error", err)
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, usage)
+}
+
+// SitemapIndex возвращает индекс карты сайта
+// @Summary Индекс карты сайта
+// @Description Возвращает sitemap index со ссылками на файлы объявлений. Вариант .gz отдаётся сжатым.
+// @Tags seo
+// @Produce xml
+// @Success 200 {string} string
+// @Failure 500 {object} apierror.Error
+// @Router /sitemap.xml [get]
+// @Router /sitemap.xml.gz [get]
+func (h *Handler) SitemapIndex(c *gin.Context) {
+	sitemap, err := h.sitemapService.Index(c)
+	if err != nil {
+		h.logger.Error("SitemapIndex: failed to generate sitemap", "error", err)
+		respondError(c, err)
+		return
+	}
+
+	writeSitemap(c, sitemap, strings.HasSuffix(c.Request.URL.Path, gzSuffix))
+}
+
+// Sitemap возвращает файл карты сайта с объявлениями
+// @Summary Файл карты сайта
+// @Description Возвращает до 50 000 ссылок на активные объявления. Имя файла - ads-<n>.xml или ads-<n>.xml.gz.
+// @Tags seo
+// @Produce xml
+// @Param file path string true "Имя файла, например ads-1.xml"
+// @Success 200 {string} string
+// @Failure 404 {object} apierror.Error
+// @Router /sitemaps/{file} [get]
+func (h *Handler) Sitemap(c *gin.Context) {
+	file := c.Param("file")
+	gz := strings.HasSuffix(file, gzSuffix)
+
+	name := strings.TrimSuffix(strings.TrimPrefix(strings.TrimSuffix(file, gzSuffix), "ads-"), ".xml")
+	n, err := strconv.Atoi(name)
+	if err != nil || fmt.Sprintf("ads-%d.xml", n) != strings.TrimSuffix(file, gzSuffix) {
+		abortWithError(c, http.StatusNotFound, services.ErrSitemapNotFound)
+		return
+	}
+
+	sitemap, err := h.sitemapService.Chunk(c, n)
+	if err != nil {
+		h.logger.Error("Sitemap: failed to generate sitemap", "file", file, "error", err)
+		respondError(c, err)
+		return
+	}
+
+	writeSitemap(c, sitemap, gz)
+}
+
+// writeSitemap отдаёт карту сайта в обычном или сжатом виде
+func writeSitemap(c *gin.Context, sitemap services.Sitemap, gz bool) {
+	if gz {
+		c.Data(http.StatusOK, "application/gzip", sitemap.Gz)
+		return
+	}
+	c.Data(http.StatusOK, "application/xml; charset=utf-8", sitemap.XML)
+}
+
+// FeedRSS возвращает RSS-ленту новых объявлений
+// @Summary RSS-лента объявлений
+// @Description Возвращает 50 новых активных объявлений в формате RSS 2.0. Доступна без авторизации.
+// @Tags seo
+// @Produce xml
+// @Param city query string false "Города через запятую, без учёта регистра"
+// @Param tag query []string false "Теги; объявление должно иметь все указанные" collectionFormat(multi)
+// @Success 200 {string} string
+// @Header 200 {string} Cache-Control "public, max-age=300"
+// @Failure 400 {object} apierror.Error
+// @Failure 500 {object} apierror.Error
+// @Router /feed.rss [get]
+func (h *Handler) FeedRSS(c *gin.Context) {
+	feed, err := h.feedService.RSS(c, feedFilter(c))
+	if err != nil {
+		h.logger.Error("FeedRSS: failed to generate feed", "error", err)
+		respondError(c, err)
+		return
+	}
+
+	writeFeed(c, "application/rss+xml; charset=utf-8", feed)
+}
+
+// FeedAtom возвращает Atom-ленту новых объявлений
+// @Summary Atom-лента объявлений
+// @Description Возвращает 50 новых активных объявлений в формате Atom. Доступна без авторизации.
+// @Tags seo
+// @Produce xml
+// @Param city query string false "Города через запятую, без учёта регистра"
+// @Param tag query []string false "Теги; объявление должно иметь все указанные" collectionFormat(multi)
+// @Success 200 {string} string
+// @Header 200 {string} Cache-Control "public, max-age=300"
+// @Failure 400 {object} apierror.Error
+// @Failure 500 {object} apierror.Error
+// @Router /feed.atom [get]
+func (h *Handler) FeedAtom(c *gin.Context) {
+	feed, err := h.feedService.Atom(c, feedFilter(c))
+	if err != nil {
+		h.logger.Error("FeedAtom: failed to generate feed", "error", err)
+		respondError(c, err)
+		return
+	}
+
+	writeFeed(c, "application/atom+xml; charset=utf-8", feed)
+}
+
+// feedFilter разбирает фильтры ленты так же, как GET /ads
+func feedFilter(c *gin.Context) services.FeedFilter {
+	return services.FeedFilter{
+		Cities: queryList(c, "city"),
+		Tags:   c.QueryArray("tag"),
+	}
+}
+
+// writeFeed отдаёт ленту, разрешая кэшировать её на services.FeedMaxAge
+func writeFeed(c *gin.Context, contentType string, feed []byte) {
+	c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", int(services.FeedMaxAge.Seconds())))
+	c.Data(http.StatusOK, contentType, feed)
 }
 
 func (h *Handler) Log(level slog.Level, msg string, args ...interface{}) {
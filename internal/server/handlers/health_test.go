@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/YuarenArt/marketgo/pkg/buildinfo"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubHealth struct {
+	err error
+}
+
+func (s stubHealth) Health(context.Context) error {
+	return s.err
+}
+
+func TestHealthEndpoints(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	serve := func(checker healthChecker, path string) *httptest.ResponseRecorder {
+		h, err := NewHandler()
+		require.NoError(t, err)
+		h.dbHealth = checker
+
+		router := gin.New()
+		router.GET("/healthz", h.Healthz)
+		router.GET("/readyz", h.Readyz)
+		router.GET("/version", h.Version)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	t.Run("readyz reports healthy database", func(t *testing.T) {
+		w := serve(stubHealth{}, "/readyz")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"status":"ready","dependencies":{"database":{"status":"up"}}}`, w.Body.String())
+	})
+
+	t.Run("readyz returns 503 when database is down", func(t *testing.T) {
+		w := serve(stubHealth{err: errors.New("connection refused")}, "/readyz")
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.NotEmpty(t, w.Header().Get("Retry-After"))
+		assert.JSONEq(t,
+			`{"status":"unavailable","dependencies":{"database":{"status":"down","error":"connection refused"}}}`,
+			w.Body.String())
+	})
+
+	t.Run("healthz does not touch the database", func(t *testing.T) {
+		w := serve(stubHealth{err: errors.New("connection refused")}, "/healthz")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"status":"ok"}`, w.Body.String())
+	})
+}
+
+func TestVersionEndpoint(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	defer func(version, commit string) { buildinfo.Version, buildinfo.Commit = version, commit }(buildinfo.Version, buildinfo.Commit)
+	buildinfo.Version, buildinfo.Commit = "v1.2.0", "abc1234"
+
+	h, err := NewHandler()
+	require.NoError(t, err)
+	router := gin.New()
+	router.GET("/version", h.Version)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/version", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var info buildinfo.Info
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &info))
+	assert.Equal(t, "v1.2.0", info.Version)
+	assert.Equal(t, "abc1234", info.Commit)
+	assert.NotEmpty(t, info.GoVersion)
+}
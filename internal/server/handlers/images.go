@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/YuarenArt/marketgo/internal/server/services"
+	"github.com/YuarenArt/marketgo/internal/storage"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// ImageFormField - поле multipart-формы с загружаемым изображением
+	ImageFormField = "image"
+	// MaxUploadBodyBytes - ограничение тела запроса загрузки: изображение и служебные части формы
+	MaxUploadBodyBytes = services.MaxImageSize + 64<<10
+
+	ErrImageRequired = "multipart form field \"image\" with a file is required"
+	ErrFileNotFound  = "file not found"
+
+	// uploadCacheControl - имена загруженных файлов строятся из хеша содержимого, поэтому файл не меняется
+	uploadCacheControl = "public, max-age=31536000, immutable"
+)
+
+// WithImageStorage включает загрузку изображений в store; baseURL - публичный адрес сайта для ссылок
+func WithImageStorage(store storage.Storage, baseURL string) HandlerOption {
+	return func(h *Handler) error {
+		h.imageService = services.NewImageService(store, baseURL)
+		return nil
+	}
+}
+
+// UploadImage загружает изображение объявления
+// @Summary Загрузка изображения
+// @Description Принимает изображение JPEG, PNG или WebP до 5 МиБ в поле формы image и возвращает адрес, который передаётся в image_url объявления.
+// @Description Тип файла определяется по содержимому, а не по имени. Повторная загрузка того же файла возвращает тот же адрес.
+// @Tags ads
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param image formData file true "Изображение"
+// @Success 201 {object} services.UploadedImage
+// @Failure 400 {object} apierror.Error
+// @Failure 401 {object} apierror.Error
+// @Failure 413 {object} apierror.Error
+// @Router /ads/images [post]
+// @Security BearerAuth
+func (h *Handler) UploadImage(c *gin.Context) {
+	logger := h.requestLogger(c)
+	header, err := c.FormFile(ImageFormField)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			logger.Warn("UploadImage: request body too large", "error", err)
+			abortWithError(c, http.StatusRequestEntityTooLarge, services.ErrImageTooLarge)
+			return
+		}
+		logger.Warn("UploadImage: no image in form", "error", err)
+		abortWithError(c, http.StatusBadRequest, ErrImageRequired)
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		logger.Error("UploadImage: failed to open uploaded file", "error", err)
+		abortWithError(c, http.StatusInternalServerError, ErrInternal)
+		return
+	}
+	defer file.Close()
+
+	image, err := h.imageService.Upload(c, file)
+	if err != nil {
+		logger.Warn("UploadImage: failed to store image", "filename", header.Filename, "error", err)
+		respondError(c, err)
+		return
+	}
+
+	logger.Info("UploadImage: image uploaded", "name", image.Name, "size", image.Size, "content_type", image.ContentType)
+	c.JSON(http.StatusCreated, image)
+}
+
+// Upload отдаёт загруженный файл
+// @Summary Загруженный файл
+// @Description Отдаёт загруженное изображение. Файлы не меняются, поэтому кешируются на год (Cache-Control: immutable).
+// @Tags ads
+// @Produce image/jpeg,image/png,image/webp
+// @Param name path string true "Имя файла"
+// @Success 200 {file} file
+// @Failure 404 {object} apierror.Error
+// @Router /uploads/{name} [get]
+func (h *Handler) Upload(c *gin.Context) {
+	name := c.Param("name")
+	file, err := h.imageService.Open(c, name)
+	if errors.Is(err, storage.ErrNotFound) {
+		abortWithError(c, http.StatusNotFound, ErrFileNotFound)
+		return
+	}
+	if err != nil {
+		h.logger.Error("Upload: failed to open file", "name", name, "error", err)
+		abortWithError(c, http.StatusInternalServerError, ErrInternal)
+		return
+	}
+	defer file.Close()
+
+	c.Header("Cache-Control", uploadCacheControl)
+	c.Header("X-Content-Type-Options", "nosniff")
+	http.ServeContent(c.Writer, c.Request, name, file.ModTime(), file)
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/YuarenArt/marketgo/internal/client"
+	"github.com/YuarenArt/marketgo/internal/db"
+	"github.com/YuarenArt/marketgo/internal/db/dbtest"
+	"github.com/YuarenArt/marketgo/internal/server/services"
+	"github.com/YuarenArt/marketgo/internal/storage"
+	"github.com/YuarenArt/marketgo/pkg/logging"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImageUpload(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	store, err := storage.NewLocal(t.TempDir())
+	require.NoError(t, err)
+
+	h, err := NewHandler(WithImageStorage(store, "https://market.example"))
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.Use(h.BodyLimitMiddleware(1024, "/api/v1/ads/images"))
+	router.POST("/api/v1/ads/images", h.BodyLimitMiddleware(MaxUploadBodyBytes), h.UploadImage)
+	router.GET(services.UploadsPath+"/:name", h.Upload)
+
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	png := append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{0}, 4096)...)
+
+	postForm := func(t *testing.T, field, filename string, data []byte) *http.Response {
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		part, err := form.CreateFormFile(field, filename)
+		require.NoError(t, err)
+		_, err = part.Write(data)
+		require.NoError(t, err)
+		require.NoError(t, form.Close())
+
+		resp, err := http.Post(srv.URL+"/api/v1/ads/images", form.FormDataContentType(), &body)
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+
+	t.Run("client uploads a file and it is served with cache headers", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "bike.png")
+		require.NoError(t, os.WriteFile(path, png, 0o644))
+
+		c := client.NewClient(srv.URL, logging.NewLogger(nil), client.WithRequestCompression(256))
+		image, err := c.UploadImage(context.Background(), path)
+		require.NoError(t, err)
+		assert.Equal(t, "image/png", image.ContentType)
+		assert.Equal(t, "https://market.example/uploads/"+image.Name, image.URL)
+
+		resp, err := http.Get(srv.URL + services.UploadsPath + "/" + image.Name)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, uploadCacheControl, resp.Header.Get("Cache-Control"))
+		assert.Equal(t, "image/png", resp.Header.Get("Content-Type"))
+		data, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, png, data)
+	})
+
+	t.Run("extension does not decide the type", func(t *testing.T) {
+		resp := postForm(t, ImageFormField, "fake.png", []byte("#!/bin/sh\necho hi\n"))
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		var body map[string]string
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		assert.Equal(t, services.ErrUnsupportedImage, body["message"])
+	})
+
+	t.Run("missing form field", func(t *testing.T) {
+		resp := postForm(t, "file", "bike.png", png)
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("oversized upload", func(t *testing.T) {
+		resp := postForm(t, ImageFormField, "huge.png", append(png, make([]byte, services.MaxImageSize)...))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+	})
+
+	t.Run("unknown file", func(t *testing.T) {
+		resp, err := http.Get(srv.URL + services.UploadsPath + "/missing.png")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
+
+func TestImageDeduplication(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+
+	dir := t.TempDir()
+	uploads, err := storage.NewLocal(dir)
+	require.NoError(t, err)
+
+	now := time.Now().Add(-2 * time.Hour)
+	store := dbtest.NewStore()
+	store.Now = func() time.Time { return now }
+	seller, err := store.CreateUser(ctx, "seller", "hash")
+	require.NoError(t, err)
+
+	h, err := NewHandler(WithImageStorage(uploads, "https://market.example"))
+	require.NoError(t, err)
+	h.imageService.UseRegistry(store, time.Hour)
+
+	router := gin.New()
+	router.POST("/api/v1/ads/images", h.UploadImage)
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "bike.png")
+	require.NoError(t, os.WriteFile(path, append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{1}, 1024)...), 0o644))
+	c := client.NewClient(srv.URL, logging.NewLogger(nil))
+
+	files := func(t *testing.T) int {
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		return len(entries)
+	}
+
+	first, err := c.UploadImage(ctx, path)
+	require.NoError(t, err)
+	second, err := c.UploadImage(ctx, path)
+	require.NoError(t, err)
+	assert.Equal(t, first.URL, second.URL, "duplicate upload returns the existing URL")
+	assert.Equal(t, 1, files(t), "identical bytes are stored once")
+
+	ad, err := store.CreateAd(ctx, db.Ad{Title: "Велосипед", Text: "Горный", ImageURL: first.URL, Price: 300, UserID: seller.ID})
+	require.NoError(t, err)
+
+	removed, err := h.imageService.CollectGarbage(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, removed, "referenced image is kept")
+	assert.Equal(t, 1, files(t))
+
+	require.NoError(t, store.DeleteAd(ctx, ad.ID, seller.ID))
+	removed, err = h.imageService.CollectGarbage(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed, "image is removed after the last reference is gone")
+	assert.Equal(t, 0, files(t))
+
+	t.Run("upload after removal stores the file again", func(t *testing.T) {
+		image, err := c.UploadImage(ctx, path)
+		require.NoError(t, err)
+		assert.Equal(t, first.URL, image.URL)
+		assert.Equal(t, 1, files(t))
+	})
+}
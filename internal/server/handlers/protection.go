@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/YuarenArt/marketgo/internal/server/services"
+	"github.com/YuarenArt/marketgo/pkg/apierror"
+	"github.com/YuarenArt/marketgo/pkg/metrics"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// CaptchaHeader - заголовок с токеном пройденной CAPTCHA
+	CaptchaHeader = "X-Captcha-Token"
+
+	ErrChallengeRequired = "captcha verification required"
+	ErrTooManyRequests   = "too many requests"
+
+	protectionTarpitted  = "tarpitted"
+	protectionChallenged = "challenged"
+	protectionBlocked    = "blocked"
+)
+
+// WithBotProtection включает защиту неавторизованных запросов от перебора и ботов.
+// captchaSiteKey передаётся клиентам в ответе с требованием пройти CAPTCHA.
+func WithBotProtection(protection *services.Protection, captchaSiteKey string) HandlerOption {
+	return func(h *Handler) error {
+		h.protection = protection
+		h.captchaSiteKey = captchaSiteKey
+		return nil
+	}
+}
+
+// WithMetrics передаёт метрики Prometheus, которые пополняют middleware обработчика
+func WithMetrics(m *metrics.Metrics) HandlerOption {
+	return func(h *Handler) error {
+		h.metrics = m
+		return nil
+	}
+}
+
+// ProtectionMiddleware ограничивает неавторизованные запросы по IP.
+// Запросы с действительным токеном не ограничиваются. При подозрении на бота клиент получает
+// 429 {"code": "challenge_required", "details": {"challenge": "captcha", "site_key": ...}} и должен повторить
+// запрос с токеном CAPTCHA в заголовке X-Captcha-Token.
+func (h *Handler) ProtectionMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if h.protection == nil {
+			c.Next()
+			return
+		}
+		if token := strings.TrimSpace(c.GetHeader(AuthHeader)); token != "" {
+			if _, err := h.authService.ValidateToken(c, token); err == nil {
+				c.Next()
+				return
+			}
+		}
+
+		meta := services.RequestMeta{
+			IP:        c.ClientIP(),
+			Method:    c.Request.Method,
+			Path:      c.Request.URL.Path,
+			Query:     c.Request.URL.Query(),
+			UserAgent: c.Request.UserAgent(),
+		}
+		verdict, err := h.protection.Check(c, meta, c.GetHeader(CaptchaHeader))
+		if err != nil {
+			// Сбой проверки CAPTCHA не должен закрывать доступ к сервису
+			h.logger.Error("Bot protection check failed", "ip", meta.IP, "error", err)
+			c.Next()
+			return
+		}
+
+		switch verdict.Action {
+		case services.ProtectionChallenge:
+			h.observeProtection(protectionChallenged)
+			h.logger.Warn("Bot protection: challenge required", "ip", meta.IP, "path", meta.Path)
+			abortWithAPIError(c, http.StatusTooManyRequests, apierror.Error{
+				Code:    apierror.CodeChallengeRequired,
+				Message: ErrChallengeRequired,
+				Details: map[string]any{"challenge": "captcha", "site_key": h.captchaSiteKey},
+			})
+		case services.ProtectionBlock:
+			h.observeProtection(protectionBlocked)
+			h.logger.Warn("Bot protection: request budget exceeded", "ip", meta.IP, "path", meta.Path)
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(verdict.RetryAfter.Seconds()))))
+			abortWithError(c, http.StatusTooManyRequests, ErrTooManyRequests)
+		default:
+			if verdict.Delay > 0 {
+				h.observeProtection(protectionTarpitted)
+				timer := time.NewTimer(verdict.Delay)
+				defer timer.Stop()
+				select {
+				case <-timer.C:
+				case <-c.Request.Context().Done():
+					c.Abort()
+					return
+				}
+			}
+			c.Next()
+		}
+	}
+}
+
+func (h *Handler) observeProtection(action string) {
+	if h.metrics != nil {
+		h.metrics.BotProtectionCount.WithLabelValues(action).Inc()
+	}
+}
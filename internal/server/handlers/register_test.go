@@ -0,0 +1,132 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/YuarenArt/marketgo/internal/db"
+	"github.com/YuarenArt/marketgo/internal/db/dbtest"
+	"github.com/YuarenArt/marketgo/internal/server/services"
+	"github.com/YuarenArt/marketgo/pkg/logging"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type stubRegistrar struct {
+	err error
+}
+
+func (r stubRegistrar) Register(_ context.Context, input services.InputUserInfo) (db.User, error) {
+	if r.err != nil {
+		return db.User{}, r.err
+	}
+	return db.User{ID: 1, Login: input.Login, Role: db.RoleUser}, nil
+}
+
+func TestRegister(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	serve := func(reg registrar) *httptest.ResponseRecorder {
+		h, err := NewHandler()
+		require.NoError(t, err)
+		h.registrar = reg
+
+		router := gin.New()
+		router.POST("/register", h.Register)
+		w := httptest.NewRecorder()
+		body := strings.NewReader(`{"login": "taken", "password": "s3cure-horse7"}`)
+		req := httptest.NewRequest(http.MethodPost, "/register", body)
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("new login is registered", func(t *testing.T) {
+		w := serve(stubRegistrar{})
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("unavailable database returns 503", func(t *testing.T) {
+		w := serve(stubRegistrar{err: fmt.Errorf("failed to create user: %w", db.ErrUnavailable)})
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.JSONEq(t, `{"code":"unavailable","message":"`+ErrServiceUnavailable+`"}`, w.Body.String())
+	})
+
+	t.Run("duplicate login returns 409", func(t *testing.T) {
+		w := serve(stubRegistrar{err: db.ErrLoginTaken})
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.JSONEq(t, `{"code":"conflict","message":"login already exists"}`, w.Body.String())
+	})
+}
+
+func TestRegisterAudit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var buf bytes.Buffer
+	h, err := NewHandler(WithAuditLogger(logging.NewAuditLogger(&buf)))
+	require.NoError(t, err)
+	h.registrar = stubRegistrar{}
+
+	router := gin.New()
+	router.Use(h.RequestLoggerMiddleware())
+	router.POST("/register", h.Register)
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/register", strings.NewReader(`{"login": "alice", "password": "s3cure-horse7"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(RequestIDHeader, "req-42")
+	req.RemoteAddr = "203.0.113.5:4321"
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	assert.NotContains(t, buf.String(), "s3cure-horse7")
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, logging.AuditRegister, entry["event"])
+	assert.EqualValues(t, 1, entry["user_id"])
+	assert.Equal(t, "alice", entry["login"])
+	assert.Equal(t, "203.0.113.5", entry["ip"])
+	assert.Equal(t, "req-42", entry["request_id"])
+	assert.NotEmpty(t, entry["timestamp"])
+}
+
+func TestLoginAudit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+
+	store := dbtest.NewStore()
+	authService := services.NewAuthService(store, "secret", time.Minute, bcrypt.MinCost)
+	_, err := authService.Register(ctx, services.InputUserInfo{Login: "bob", Password: "s3cure-horse7"})
+	require.NoError(t, err)
+	alice, err := authService.Register(ctx, services.InputUserInfo{Login: "alice", Password: "s3cure-horse7"})
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	h, err := NewHandler(WithAuditLogger(logging.NewAuditLogger(&buf)))
+	require.NoError(t, err)
+	h.authService = authService
+
+	router := gin.New()
+	router.POST("/login", h.Login)
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/login", strings.NewReader(`{"login": "alice", "password": "s3cure-horse7"}`))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "user_id")
+
+	assert.NotContains(t, buf.String(), "s3cure-horse7")
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, logging.AuditLoginSuccess, entry["event"])
+	assert.EqualValues(t, alice.ID, entry["user_id"])
+	assert.Equal(t, "alice", entry["login"])
+}
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/YuarenArt/marketgo/pkg/logging"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	RequestIDHeader = "X-Request-ID"
+
+	// RequestIDKey - ключ идентификатора запроса в gin.Context
+	RequestIDKey = "requestID"
+	// loggerKey - ключ логгера запроса в gin.Context
+	loggerKey = "logger"
+
+	maxRequestIDLength = 128
+)
+
+// RequestLoggerMiddleware присваивает запросу идентификатор и кладёт в контекст логгер с полями
+// request_id, method и path. Идентификатор берётся из X-Request-ID, если клиент его передал,
+// иначе генерируется, и возвращается в том же заголовке ответа.
+// AuthMiddleware дополняет логгер полем user_id.
+func (h *Handler) RequestLoggerMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if requestID == "" || len(requestID) > maxRequestIDLength {
+			requestID = newRequestID()
+		}
+		c.Set(RequestIDKey, requestID)
+		c.Header(RequestIDHeader, requestID)
+
+		c.Set(loggerKey, h.logger.With(
+			"request_id", requestID,
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+		))
+		c.Next()
+	}
+}
+
+// requestLogger возвращает логгер запроса из контекста или общий логгер,
+// если RequestLoggerMiddleware не подключён
+func (h *Handler) requestLogger(c *gin.Context) logging.Logger {
+	if value, ok := c.Get(loggerKey); ok {
+		if logger, ok := value.(logging.Logger); ok {
+			return logger
+		}
+	}
+	return h.logger
+}
+
+// bindUserLogger добавляет user_id к логгеру запроса
+func (h *Handler) bindUserLogger(c *gin.Context, userID int) {
+	c.Set(loggerKey, h.requestLogger(c).With("user_id", userID))
+}
+
+// newRequestID генерирует случайный идентификатор запроса
+func newRequestID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/YuarenArt/marketgo/pkg/logging"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestLoggerMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var buf bytes.Buffer
+	h, err := NewHandler(WithLogger(logging.NewWriterLogger(&buf, logging.WithLevel(slog.LevelDebug))))
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.Use(h.RequestLoggerMiddleware())
+	router.GET("/ads", func(c *gin.Context) {
+		c.Set("userID", 42)
+		h.bindUserLogger(c, 42)
+		c.Next()
+	}, h.Ads)
+
+	serve := func(requestID string) *httptest.ResponseRecorder {
+		buf.Reset()
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/ads?created_from=yesterday", nil)
+		if requestID != "" {
+			req.Header.Set(RequestIDHeader, requestID)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("log entries carry request fields", func(t *testing.T) {
+		w := serve("")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		requestID := w.Header().Get(RequestIDHeader)
+		assert.Len(t, requestID, 16)
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		require.NotEmpty(t, lines)
+		for _, line := range lines {
+			var entry map[string]any
+			require.NoError(t, json.Unmarshal([]byte(line), &entry), line)
+			assert.Equal(t, requestID, entry["request_id"])
+			assert.Equal(t, http.MethodGet, entry["method"])
+			assert.Equal(t, "/ads", entry["path"])
+			assert.EqualValues(t, 42, entry["user_id"])
+		}
+	})
+
+	t.Run("client request id is kept", func(t *testing.T) {
+		w := serve("trace-123")
+		assert.Equal(t, "trace-123", w.Header().Get(RequestIDHeader))
+		assert.Contains(t, buf.String(), `"request_id":"trace-123"`)
+	})
+
+	t.Run("oversized request id is replaced", func(t *testing.T) {
+		w := serve(strings.Repeat("x", maxRequestIDLength+1))
+		assert.Len(t, w.Header().Get(RequestIDHeader), 16)
+	})
+}
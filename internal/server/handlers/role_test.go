@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/YuarenArt/marketgo/internal/db"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequireRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h, err := NewHandler()
+	require.NoError(t, err)
+
+	serve := func(role string, withRole bool) *httptest.ResponseRecorder {
+		router := gin.New()
+		router.GET("/admin/ping",
+			func(c *gin.Context) {
+				c.Set("userID", 1)
+				if withRole {
+					c.Set("role", role)
+				}
+			},
+			h.RequireRole(db.RoleAdmin),
+			func(c *gin.Context) { c.Status(http.StatusNoContent) },
+		)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/ping", nil))
+		return w
+	}
+
+	t.Run("admin passes", func(t *testing.T) {
+		assert.Equal(t, http.StatusNoContent, serve(db.RoleAdmin, true).Code)
+	})
+
+	t.Run("regular user is forbidden", func(t *testing.T) {
+		w := serve(db.RoleUser, true)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.JSONEq(t, `{"code":"forbidden","message":"`+ErrForbidden+`"}`, w.Body.String())
+	})
+
+	t.Run("missing role is forbidden", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, serve("", false).Code)
+	})
+}
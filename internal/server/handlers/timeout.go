@@ -0,0 +1,48 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	ErrRequestTimeout = "request timed out"
+
+	pprofPathPrefix = "/debug/pprof/"
+)
+
+// isUnlimitedRoute сообщает, что маршрут не ограничивается REQUEST_TIMEOUT:
+// профилирование длится столько, сколько задаёт параметр seconds, а выгрузка CSV - сколько нужно для всех строк
+func isUnlimitedRoute(c *gin.Context) bool {
+	return strings.HasPrefix(c.Request.URL.Path, pprofPathPrefix) || strings.HasSuffix(c.FullPath(), exportRoute)
+}
+
+// TimeoutMiddleware ограничивает контекст запроса временем timeout; timeout <= 0 отключает ограничение.
+// Запросы к базе данных, прерванные по этому сроку, обработчики возвращают как 504 через respondError.
+// Если обработчик завершился после истечения срока, ничего не записав, middleware отвечает 504 сам.
+// Профилирование /debug/pprof/ и выгрузка /ads/export.csv не ограничиваются.
+// Для gin.Context в роли context.Context роутеру нужен ContextWithFallback.
+func (h *Handler) TimeoutMiddleware(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if timeout <= 0 || isUnlimitedRoute(c) {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) && !c.Writer.Written() {
+			h.logger.Warn("Request timed out", "method", c.Request.Method, "path", c.Request.URL.Path, "timeout", timeout)
+			abortWithError(c, http.StatusGatewayTimeout, ErrRequestTimeout)
+		}
+	}
+}
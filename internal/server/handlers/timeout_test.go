@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// slowService отвечает через delay или по отмене контекста, как запрос к базе данных
+type slowService struct {
+	delay time.Duration
+}
+
+func (s slowService) Do(ctx context.Context) error {
+	select {
+	case <-time.After(s.delay):
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("failed to query ads: %w", ctx.Err())
+	}
+}
+
+func TestTimeoutMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	serve := func(timeout time.Duration, handler gin.HandlerFunc) *httptest.ResponseRecorder {
+		h, err := NewHandler()
+		require.NoError(t, err)
+
+		router := gin.New()
+		router.ContextWithFallback = true
+		router.Use(h.TimeoutMiddleware(timeout))
+		router.GET("/slow", handler)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/slow", nil))
+		return w
+	}
+
+	callService := func(svc slowService) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			if err := svc.Do(c); err != nil {
+				respondError(c, err)
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"status": "ok"})
+		}
+	}
+
+	t.Run("slow service returns 504", func(t *testing.T) {
+		w := serve(20*time.Millisecond, callService(slowService{delay: time.Minute}))
+		assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+		assert.JSONEq(t, `{"code":"timeout","message":"`+ErrQueryTimeout+`"}`, w.Body.String())
+	})
+
+	t.Run("fast service is not affected", func(t *testing.T) {
+		w := serve(time.Second, callService(slowService{}))
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("handler that wrote nothing after deadline gets 504", func(t *testing.T) {
+		w := serve(20*time.Millisecond, func(c *gin.Context) {
+			<-c.Request.Context().Done()
+		})
+		assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+		assert.JSONEq(t, `{"code":"timeout","message":"`+ErrRequestTimeout+`"}`, w.Body.String())
+	})
+
+	t.Run("zero timeout disables the deadline", func(t *testing.T) {
+		w := serve(0, func(c *gin.Context) {
+			_, hasDeadline := c.Deadline()
+			assert.False(t, hasDeadline)
+			c.Status(http.StatusNoContent)
+		})
+		assert.Equal(t, http.StatusNoContent, w.Code)
+	})
+}
@@ -0,0 +1,231 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/YuarenArt/marketgo/internal/db"
+	"github.com/YuarenArt/marketgo/internal/db/dbtest"
+	"github.com/YuarenArt/marketgo/internal/server/services"
+	"github.com/YuarenArt/marketgo/pkg/apierror"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBindValidationDetails(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h, err := NewHandler()
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.POST("/register", h.Register)
+	router.POST("/ads", func(c *gin.Context) { c.Set("userID", 1) }, h.CreateAd)
+
+	serve := func(path, body string) (int, map[string]services.FieldError) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		var resp struct {
+			apierror.Error
+			Details struct {
+				Fields []services.FieldError `json:"fields"`
+			} `json:"details"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, apierror.CodeInvalidInput, resp.Code)
+
+		fields := make(map[string]services.FieldError, len(resp.Details.Fields))
+		for _, f := range resp.Details.Fields {
+			fields[f.Field] = f
+		}
+		return w.Code, fields
+	}
+
+	t.Run("short password and missing login", func(t *testing.T) {
+		status, fields := serve("/register", `{"password": "short"}`)
+		assert.Equal(t, http.StatusBadRequest, status)
+		require.Contains(t, fields, "password")
+		require.Contains(t, fields, "login")
+		assert.Equal(t, "min", fields["password"].Rule)
+		assert.Equal(t, "должно содержать не менее 8 символов", fields["password"].Message)
+		assert.Equal(t, "required", fields["login"].Rule)
+	})
+
+	t.Run("missing title and invalid price", func(t *testing.T) {
+		status, fields := serve("/ads", `{"text": "Горный", "image_url": "https://example.com/1.jpg", "price": 0}`)
+		assert.Equal(t, http.StatusBadRequest, status)
+		require.Contains(t, fields, "title")
+		require.Contains(t, fields, "price")
+		assert.Equal(t, "required", fields["title"].Rule)
+		assert.Equal(t, "обязательное поле", fields["title"].Message)
+	})
+}
+
+func TestAdTagsValidation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	store := dbtest.NewStore()
+	user, err := store.CreateUser(context.Background(), "tagseller", "hash")
+	require.NoError(t, err)
+
+	h, err := NewHandler()
+	require.NoError(t, err)
+	h.adService = services.NewAdService(store)
+
+	router := gin.New()
+	auth := func(c *gin.Context) { c.Set("userID", user.ID) }
+	router.POST("/ads", auth, h.CreateAd)
+	router.GET("/ads", auth, h.Ads)
+
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve(http.MethodPost, "/ads", `{"title": "Велосипед", "text": "Горный", "image_url": "https://example.com/1.jpg", "price": 300, "tags": ["Торг", "самовывоз"]}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	serve(http.MethodPost, "/ads", `{"title": "Самокат", "text": "Детский", "image_url": "https://example.com/2.jpg", "price": 100, "tags": ["торг"]}`)
+
+	t.Run("invalid tag is named in the error", func(t *testing.T) {
+		long := strings.Repeat("б", 31)
+		w := serve(http.MethodPost, "/ads", `{"title": "Диван", "text": "Угловой", "image_url": "https://example.com/3.jpg", "price": 500, "tags": ["торг", "`+long+`"]}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		var resp apierror.Error
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Contains(t, resp.Message, long)
+	})
+
+	t.Run("repeated tag parameters are combined with AND", func(t *testing.T) {
+		w := serve(http.MethodGet, "/ads?tag=%D0%A2%D0%BE%D1%80%D0%B3&tag=%D1%81%D0%B0%D0%BC%D0%BE%D0%B2%D1%8B%D0%B2%D0%BE%D0%B7", "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var ads []db.Ad
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &ads))
+		require.Len(t, ads, 1)
+		assert.Equal(t, "Велосипед", ads[0].Title)
+		assert.Equal(t, []string{"торг", "самовывоз"}, ads[0].Tags)
+	})
+}
+
+func TestAdsPriceRangeValidation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	store := dbtest.NewStore()
+	user, err := store.CreateUser(context.Background(), "priceseller", "hash")
+	require.NoError(t, err)
+	_, err = store.CreateAd(context.Background(), db.Ad{Title: "Велосипед", Text: "Горный", ImageURL: "https://example.com/1.jpg", Price: 300, UserID: user.ID})
+	require.NoError(t, err)
+
+	h, err := NewHandler()
+	require.NoError(t, err)
+	h.adService = services.NewAdService(store)
+
+	router := gin.New()
+	router.GET("/ads", func(c *gin.Context) { c.Set("userID", user.ID) }, h.Ads)
+
+	get := func(query string) (*httptest.ResponseRecorder, apierror.Error) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ads?"+query, nil))
+		var resp apierror.Error
+		if w.Code != http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		}
+		return w, resp
+	}
+
+	tests := []struct {
+		name    string
+		query   string
+		message string
+	}{
+		{"min greater than max", "min_price=5000&max_price=100", "min_price (5000) must not exceed max_price (100)"},
+		{"negative min", "min_price=-1&max_price=100", ErrInvalidMinPrice},
+		{"negative max", "max_price=-100", ErrInvalidMaxPrice},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, resp := get(tt.query)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			assert.Equal(t, apierror.CodeInvalidInput, resp.Code)
+			assert.Equal(t, tt.message, resp.Message)
+		})
+	}
+
+	t.Run("min equal to max", func(t *testing.T) {
+		w, _ := get("min_price=300&max_price=300")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var ads []db.Ad
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &ads))
+		require.Len(t, ads, 1)
+		assert.Equal(t, int64(300), ads[0].Price)
+	})
+}
+
+func TestAdCityValidation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	store := dbtest.NewStore()
+	user, err := store.CreateUser(context.Background(), "cityseller", "hash")
+	require.NoError(t, err)
+
+	h, err := NewHandler()
+	require.NoError(t, err)
+	h.adService = services.NewAdService(store)
+
+	router := gin.New()
+	auth := func(c *gin.Context) { c.Set("userID", user.ID) }
+	router.POST("/ads", auth, h.CreateAd)
+	router.GET("/ads", auth, h.Ads)
+
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve(http.MethodPost, "/ads", `{"title": "Велосипед", "text": "Горный", "image_url": "https://example.com/1.jpg", "price": 300, "city": " Москва "}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var created db.Ad
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, "Москва", created.City)
+	serve(http.MethodPost, "/ads", `{"title": "Самокат", "text": "Детский", "image_url": "https://example.com/2.jpg", "price": 100, "city": "Казань"}`)
+	serve(http.MethodPost, "/ads", `{"title": "Диван", "text": "Угловой", "image_url": "https://example.com/3.jpg", "price": 500}`)
+
+	t.Run("too long city is rejected", func(t *testing.T) {
+		long := strings.Repeat("г", db.MaxCityLength+1)
+		w := serve(http.MethodPost, "/ads", `{"title": "Лампа", "text": "Настольная", "image_url": "https://example.com/4.jpg", "price": 100, "city": "`+long+`"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		var resp apierror.Error
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, db.ErrMsgInvalidCity, resp.Message)
+	})
+
+	t.Run("comma-separated cities ignore case", func(t *testing.T) {
+		w := serve(http.MethodGet, "/ads?sort_by=price&sort_order=ASC&city=%D0%BC%D0%BE%D1%81%D0%BA%D0%B2%D0%B0,%D0%9A%D0%90%D0%97%D0%90%D0%9D%D0%AC", "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var ads []db.Ad
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &ads))
+		require.Len(t, ads, 2)
+		assert.Equal(t, "Казань", ads[0].City)
+		assert.Equal(t, "Москва", ads[1].City)
+	})
+
+	t.Run("empty city keeps all ads", func(t *testing.T) {
+		w := serve(http.MethodGet, "/ads?city=", "")
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var ads []db.Ad
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &ads))
+		assert.Len(t, ads, 3)
+	})
+}
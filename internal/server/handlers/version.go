@@ -0,0 +1,19 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/YuarenArt/marketgo/pkg/buildinfo"
+	"github.com/gin-gonic/gin"
+)
+
+// Version возвращает сведения о сборке сервера
+// @Summary Версия сервера
+// @Description Версия, коммит и дата сборки, заданные через -ldflags, и версия Go
+// @Tags health
+// @Produce json
+// @Success 200 {object} buildinfo.Info
+// @Router /version [get]
+func (h *Handler) Version(c *gin.Context) {
+	c.JSON(http.StatusOK, buildinfo.Get())
+}
@@ -0,0 +1,51 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/YuarenArt/marketgo/internal/config"
+	"github.com/YuarenArt/marketgo/internal/server/handlers"
+	"github.com/YuarenArt/marketgo/pkg/metrics"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersionedRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	serve := func(legacy bool, path string) *httptest.ResponseRecorder {
+		h, err := handlers.NewHandler()
+		require.NoError(t, err)
+		s := &Server{router: gin.New(), config: &config.Config{LegacyRoutes: legacy}, handler: h, metrics: metrics.NewMetrics(nil)}
+		s.setupRoutes()
+
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	t.Run("versioned path is served", func(t *testing.T) {
+		w := serve(false, "/api/v1/ads")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Empty(t, w.Header().Get("Deprecation"))
+	})
+
+	t.Run("legacy path is deprecated alias", func(t *testing.T) {
+		w := serve(true, "/ads")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Equal(t, "true", w.Header().Get("Deprecation"))
+		assert.Equal(t, `</api/v1/ads>; rel="successor-version"`, w.Header().Get("Link"))
+	})
+
+	t.Run("legacy path is disabled", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, serve(false, "/ads").Code)
+	})
+
+	t.Run("probes stay unversioned", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, serve(false, "/healthz").Code)
+		assert.Equal(t, http.StatusNotFound, serve(false, "/api/v1/healthz").Code)
+	})
+}
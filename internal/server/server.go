@@ -5,22 +5,38 @@ import (
 	"errors"
 	"fmt"
 	"github.com/YuarenArt/marketgo/internal/config"
+	"github.com/YuarenArt/marketgo/internal/db"
 	"github.com/YuarenArt/marketgo/internal/server/handlers"
+	"github.com/YuarenArt/marketgo/internal/server/services"
+	"github.com/YuarenArt/marketgo/pkg/buildinfo"
+	"github.com/YuarenArt/marketgo/pkg/compress"
 	"github.com/YuarenArt/marketgo/pkg/logging"
 	"github.com/YuarenArt/marketgo/pkg/metrics"
-	"github.com/gin-contrib/gzip"
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	"net/http"
 	"net/http/pprof"
+	"slices"
+	"strings"
 	"time"
 
 	_ "github.com/YuarenArt/marketgo/docs"
 )
 
+const (
+	// APIPrefix - префикс версии API для бизнес-эндпоинтов
+	APIPrefix = "/api/v1"
+	// imageUploadRoute - загрузка изображений со своим ограничением размера тела вместо MAX_BODY_BYTES
+	imageUploadRoute = "/ads/images"
+)
+
 var (
-	excludedPaths = []string{"/metrics", "/debug/pprof/*"}
+	// uncompressedPrefixes - префиксы путей, ответы на которые не сжимаются: метрики и профили
+	uncompressedPrefixes = []string{"/metrics", "/debug/pprof/", services.UploadsPath + "/"}
+	// probePaths - проверки живости и готовности, которые не учитываются в метриках запросов,
+	// не пишутся в api.log и не сжимаются
+	probePaths = []string{"/healthz", "/readyz", "/ready"}
 )
 
 // Server представляет HTTP-сервер с роутером Gin и логгированием
@@ -36,6 +52,8 @@ type Server struct {
 // NewServer создаёт новый экземпляр Server
 func NewServer(cfg *config.Config, logger, apiLogger logging.Logger, handler *handlers.Handler, m *metrics.Metrics) *Server {
 	r := gin.New()
+	// Обработчики передают *gin.Context как context.Context; без fallback он не видит срок TimeoutMiddleware
+	r.ContextWithFallback = true
 	s := &Server{
 		router:    r,
 		logger:    logger,
@@ -46,11 +64,19 @@ func NewServer(cfg *config.Config, logger, apiLogger logging.Logger, handler *ha
 	}
 
 	r.Use(
-		s.loggingMiddleware,
-		s.corsMiddleware(),
+		handler.RequestLoggerMiddleware(),
+		s.loggingMiddleware(probePaths...),
+		s.corsMiddleware(cfg.AllowedOrigins),
 		gin.Recovery(),
-		s.metrics.Middleware(),
-		gzip.Gzip(gzip.DefaultCompression, gzip.WithExcludedPaths(excludedPaths)),
+		s.metrics.Middleware(probePaths...),
+		compress.Gzip(compress.Options{
+			Level:            int(cfg.GzipLevel),
+			MinSize:          int(cfg.GzipMinSize),
+			ExcludedPrefixes: slices.Concat(uncompressedPrefixes, probePaths),
+		}),
+		handler.TimeoutMiddleware(cfg.RequestTimeout),
+		handler.BodyLimitMiddleware(cfg.MaxBodyBytes, APIPrefix+imageUploadRoute, imageUploadRoute),
+		handler.DecompressMiddleware(cfg.MaxBodyBytes),
 	)
 	s.setupRoutes()
 
@@ -60,13 +86,20 @@ func NewServer(cfg *config.Config, logger, apiLogger logging.Logger, handler *ha
 // Start запускает HTTP-сервер и обрабатывает его завершение
 func (s *Server) Start(ctx context.Context) error {
 	addr := fmt.Sprintf(":%s", s.config.Port)
-	s.logger.Info("Starting server", "addr", addr)
+	info := buildinfo.Get()
+	s.logger.Info("Starting server", "addr", addr,
+		"version", info.Version, "commit", info.Commit, "build_date", info.BuildDate)
 
 	srv := &http.Server{
-		Addr:    addr,
-		Handler: s.router,
+		Addr:              addr,
+		Handler:           s.router,
+		ReadHeaderTimeout: s.config.ReadHeaderTimeout,
+		ReadTimeout:       s.config.ReadTimeout,
+		WriteTimeout:      s.config.WriteTimeout,
 	}
 
+	s.handler.StartBackgroundJobs(ctx)
+
 	go func() {
 		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			s.logger.Error("Server failed", "error", err)
@@ -89,22 +122,34 @@ func (s *Server) Start(ctx context.Context) error {
 }
 
 // setupRoutes настраивает маршруты HTTP-сервера.
-// Регистрирует эндпоинты для:
-// - Регистрации (/register)
-// - Входа (/login)
-// - Работы с объявлениями (/ads)
-// - Swagger-документации (/swagger/*any)
-// - Профилирования (/debug/pprof/*any, /debug/pprof/cmdline, /debug/pprof/profile, /debug/pprof/symbol, /debug/pprof/trace)
-// - Метрик Prometheus (/metrics)
+// Бизнес-эндпоинты регистрируются в apiRoutes под префиксом APIPrefix; при LegacyRoutes они же
+// доступны по старым путям без префикса как устаревшие. Без версии остаются:
+// - Проверки живости и готовности (/healthz, /readyz, /ready) и версия сборки (/version)
+// - Карта сайта (/sitemap.xml, /sitemaps/*), которую поисковые роботы ищут в корне
+// - Загруженные изображения (/uploads/:name), адреса которых сохраняются в объявлениях
+// - Swagger-документация (/swagger/*any)
+// - Профилирование (/debug/pprof/*any, /debug/pprof/cmdline, /debug/pprof/profile, /debug/pprof/symbol, /debug/pprof/trace)
+// - Метрики Prometheus (/metrics)
 func (s *Server) setupRoutes() {
 
-	s.router.POST("/register", s.handler.Register)
-	s.router.POST("/login", s.handler.Login)
+	s.router.GET("/healthz", s.handler.Healthz)
+	s.router.GET("/readyz", s.handler.Readyz)
+	s.router.GET("/ready", s.handler.Ready)
+	s.router.GET("/version", s.handler.Version)
+	s.router.GET(services.UploadsPath+"/:name", s.handler.Upload)
 
-	ads := s.router.Group("/ads", s.handler.AuthMiddleware())
+	s.apiRoutes(s.router.Group(APIPrefix))
+	if s.config.LegacyRoutes {
+		s.apiRoutes(s.router.Group("", s.deprecatedMiddleware(APIPrefix)))
+	}
+
+	public := s.router.Group("", s.handler.ProtectionMiddleware(), s.handler.AvailabilityMiddleware())
 	{
-		ads.POST("", s.handler.CreateAd)
-		ads.GET("", s.handler.Ads)
+		public.GET("/sitemap.xml", s.handler.SitemapIndex)
+		public.GET("/sitemap.xml.gz", s.handler.SitemapIndex)
+		public.GET("/sitemaps/:file", s.handler.Sitemap)
+		public.GET("/feed.rss", s.handler.FeedRSS)
+		public.GET("/feed.atom", s.handler.FeedAtom)
 	}
 
 	s.router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
@@ -119,6 +164,86 @@ func (s *Server) setupRoutes() {
 
 }
 
+// apiRoutes регистрирует бизнес-эндпоинты в группе rg:
+// - Регистрации (/register)
+// - Входа, обновления токенов и выхода (/login, /refresh, /logout)
+// - Объявлений администрации (/announcements)
+// - Работы с объявлениями (/ads) и избранным (/favorites)
+// - Уведомлений (/notifications) и настроек пользователя (/users)
+// - Административных отчётов и управления ролями (/admin), доступных только роли admin
+func (s *Server) apiRoutes(rg *gin.RouterGroup) {
+	public := rg.Group("", s.handler.ProtectionMiddleware(), s.handler.AvailabilityMiddleware())
+	{
+		public.POST("/register", s.handler.Register)
+		public.POST("/login", s.handler.Login)
+		public.POST("/refresh", s.handler.Refresh)
+		public.GET("/announcements", s.handler.Announcements)
+		public.GET("/users/:login", s.handler.SellerProfile)
+	}
+
+	rg.POST("/logout", s.handler.ProtectionMiddleware(), s.handler.AuthMiddleware(), s.handler.AvailabilityMiddleware(), s.handler.Logout)
+
+	ads := rg.Group("/ads", s.handler.ProtectionMiddleware(), s.handler.AuthMiddleware(), s.handler.AvailabilityMiddleware(), s.handler.UsageMiddleware(), s.handler.ReplayMiddleware())
+	{
+		ads.POST("", s.handler.CreateAd)
+		ads.GET("", s.handler.Ads)
+		ads.GET("/my", s.handler.MyAds)
+		ads.GET("/suggest", s.handler.Suggest)
+		ads.GET("/export.csv", s.handler.ExportAds)
+		ads.POST("/images", s.handler.BodyLimitMiddleware(handlers.MaxUploadBodyBytes), s.handler.UploadImage)
+		ads.GET("/:id", s.handler.Ad)
+		ads.GET("/:id/similar", s.handler.SimilarAds)
+		ads.PATCH("/:id", s.handler.UpdateAd)
+		ads.DELETE("/:id", s.handler.DeleteAd)
+		ads.POST("/:id/status", s.handler.SetAdStatus)
+		ads.POST("/:id/renew", s.handler.RenewAd)
+		ads.POST("/:id/reserve", s.handler.ReserveAd)
+		ads.DELETE("/:id/reserve", s.handler.CancelReservation)
+		ads.POST("/:id/reservations/:rid/confirm", s.handler.ConfirmReservation)
+		ads.POST("/:id/favorite", s.handler.AddFavorite)
+		ads.DELETE("/:id/favorite", s.handler.RemoveFavorite)
+	}
+
+	rg.GET("/favorites", s.handler.ProtectionMiddleware(), s.handler.AuthMiddleware(), s.handler.AvailabilityMiddleware(), s.handler.UsageMiddleware(), s.handler.ReplayMiddleware(), s.handler.Favorites)
+
+	notifications := rg.Group("/notifications", s.handler.ProtectionMiddleware(), s.handler.AuthMiddleware(), s.handler.AvailabilityMiddleware(), s.handler.UsageMiddleware(), s.handler.ReplayMiddleware())
+	{
+		notifications.GET("", s.handler.Notifications)
+		notifications.POST("/read-all", s.handler.ReadAllNotifications)
+		notifications.POST("/:id/read", s.handler.ReadNotification)
+	}
+
+	users := rg.Group("/users", s.handler.ProtectionMiddleware(), s.handler.AuthMiddleware(), s.handler.AvailabilityMiddleware(), s.handler.UsageMiddleware(), s.handler.ReplayMiddleware())
+	{
+		users.GET("/me", s.handler.Profile)
+		users.DELETE("/me", s.handler.DeleteMe)
+		users.GET("/me/preferences", s.handler.Preferences)
+		users.PATCH("/me/preferences", s.handler.UpdatePreferences)
+		users.GET("/me/usage", s.handler.Usage)
+	}
+
+	admin := rg.Group("/admin", s.handler.ProtectionMiddleware(), s.handler.AuthMiddleware(), s.handler.RequireRole(db.RoleAdmin), s.handler.AvailabilityMiddleware(), s.handler.UsageMiddleware(), s.handler.ReplayMiddleware())
+	{
+		admin.GET("/stats/daily", s.handler.DailyStats)
+		admin.GET("/announcements", s.handler.AdminAnnouncements)
+		admin.POST("/announcements", s.handler.CreateAnnouncement)
+		admin.PUT("/announcements/:id", s.handler.UpdateAnnouncement)
+		admin.DELETE("/announcements/:id", s.handler.DeleteAnnouncement)
+		admin.PUT("/users/:login/role", s.handler.SetUserRole)
+		admin.DELETE("/ads/:id", s.handler.RemoveAd)
+	}
+}
+
+// deprecatedMiddleware помечает запрос по старому пути без версии как устаревший:
+// заголовок Deprecation и ссылка Link на тот же путь под prefix
+func (s *Server) deprecatedMiddleware(prefix string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Deprecation", "true")
+		c.Header("Link", fmt.Sprintf(`<%s%s>; rel="successor-version"`, prefix, c.Request.URL.Path))
+		c.Next()
+	}
+}
+
 // setupMetrics настраивает маршрут для метрик Prometheus
 // @Summary Метрики Prometheus
 // @Description Возвращает метрики приложения в формате Prometheus. Не использует Gzip-компрессию.
@@ -127,34 +252,92 @@ func (s *Server) setupRoutes() {
 // @Success 200 {string} string
 // @Router /metrics [get]
 func (s *Server) setupMetrics() {
-	s.router.GET("/metrics", metrics.Handler())
+	s.router.GET("/metrics", metrics.Handler(s.metrics.Gatherer()))
 }
 
-// loggingMiddleware логирует каждый HTTP-запрос
-func (s *Server) loggingMiddleware(c *gin.Context) {
-	start := time.Now()
-	method := c.Request.Method
-	path := c.Request.URL.Path
+// loggingMiddleware логирует каждый HTTP-запрос, кроме запросов к skipPaths
+func (s *Server) loggingMiddleware(skipPaths ...string) gin.HandlerFunc {
+	skip := make(map[string]bool, len(skipPaths))
+	for _, path := range skipPaths {
+		skip[path] = true
+	}
+
+	return func(c *gin.Context) {
+		if skip[c.Request.URL.Path] {
+			c.Next()
+			return
+		}
 
-	c.Next()
+		start := time.Now()
+		method := c.Request.Method
+		path := c.Request.URL.Path
 
-	latency := time.Since(start)
-	status := c.Writer.Status()
+		c.Next()
 
-	s.apiLogger.Info("HTTP request",
-		"method", method,
-		"path", path,
-		"status", status,
-		"duration", latency,
-	)
+		latency := time.Since(start)
+		status := c.Writer.Status()
+
+		s.apiLogger.Info("HTTP request",
+			"request_id", c.GetString(handlers.RequestIDKey),
+			"method", method,
+			"path", path,
+			"status", status,
+			"duration", latency,
+			"user_agent", c.Request.UserAgent(),
+		)
+	}
 }
 
-// corsMiddleware добавляет заголовки для CORS
-func (s *Server) corsMiddleware() gin.HandlerFunc {
+// corsMiddleware добавляет заголовки для CORS.
+// Разрешается только источник из allowedOrigins: он возвращается в Access-Control-Allow-Origin,
+// "*" в списке разрешает любой источник. Остальные источники заголовков CORS не получают,
+// а их предварительные запросы OPTIONS - 403.
+func (s *Server) corsMiddleware(allowedOrigins []string) gin.HandlerFunc {
+	allowAny := false
+	origins := make(map[string]bool, len(allowedOrigins))
+	for _, origin := range allowedOrigins {
+		if origin == "*" {
+			allowAny = true
+		}
+		origins[strings.ToLower(strings.TrimSuffix(origin, "/"))] = true
+	}
+
+	allowHeaders := strings.Join([]string{
+		"Content-Type", "Authorization", "Accept-Language", "If-None-Match", "Content-Encoding",
+		handlers.AuthHeader, handlers.NonceHeader, handlers.CaptchaHeader, handlers.RequestIDHeader,
+	}, ", ")
+	exposeHeaders := strings.Join([]string{
+		handlers.TotalCountHeader,
+		handlers.RateLimitLimitHeader, handlers.RateLimitRemainingHeader, handlers.RateLimitResetHeader,
+		"Retry-After", "Warning", "Deprecation", "Link", "ETag", handlers.RequestIDHeader,
+	}, ", ")
+
 	return func(c *gin.Context) {
-		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, X-Auth-Token")
+		c.Writer.Header().Add("Vary", "Origin")
+
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+			return
+		}
+
+		if !allowAny && !origins[strings.ToLower(origin)] {
+			if c.Request.Method == http.MethodOptions {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.Next()
+			return
+		}
+
+		if allowAny {
+			c.Header("Access-Control-Allow-Origin", "*")
+		} else {
+			c.Header("Access-Control-Allow-Origin", origin)
+		}
+		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+		c.Header("Access-Control-Allow-Headers", allowHeaders)
+		c.Header("Access-Control-Expose-Headers", exposeHeaders)
 
 		if c.Request.Method == http.MethodOptions {
 			c.AbortWithStatus(http.StatusNoContent)
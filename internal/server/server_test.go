@@ -0,0 +1,73 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/YuarenArt/marketgo/internal/client"
+	"github.com/YuarenArt/marketgo/internal/config"
+	"github.com/YuarenArt/marketgo/internal/server/handlers"
+	"github.com/YuarenArt/marketgo/pkg/logging"
+	"github.com/YuarenArt/marketgo/pkg/metrics"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewServerTwice(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newServer := func() *Server {
+		h, err := handlers.NewHandler()
+		require.NoError(t, err)
+		logger := logging.NewLogger(nil)
+		return NewServer(&config.Config{}, logger, logger, h, metrics.NewMetrics(nil))
+	}
+
+	var first, second *Server
+	require.NotPanics(t, func() {
+		first = newServer()
+		second = newServer()
+	})
+
+	first.router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/ads", nil))
+
+	scrape := func(s *Server) string {
+		w := httptest.NewRecorder()
+		s.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+		require.Equal(t, http.StatusOK, w.Code)
+		return w.Body.String()
+	}
+
+	assert.Contains(t, scrape(first), `http_request_total{method="GET",path="/api/v1/ads",status="401"} 1`)
+	assert.NotContains(t, scrape(second), `path="/api/v1/ads"`, "servers do not share metrics")
+	assert.Contains(t, scrape(second), "go_goroutines")
+}
+
+func TestAccessLogUserAgent(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	h, err := handlers.NewHandler()
+	require.NoError(t, err)
+	logFile := filepath.Join(t.TempDir(), "api.log")
+	s := NewServer(&config.Config{}, logging.NewLogger(nil), logging.NewFileLogger(logFile), h, metrics.NewMetrics(nil))
+
+	srv := httptest.NewServer(s.router)
+	defer srv.Close()
+
+	_, err = client.NewClient(srv.URL, logging.NewLogger(nil)).GetServerVersion(context.Background())
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(logFile)
+	require.NoError(t, err)
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(bytes.TrimSpace(data), &entry))
+	assert.Equal(t, "/version", entry["path"])
+	assert.Equal(t, client.UserAgent(), entry["user_agent"])
+}
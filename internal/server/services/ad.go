@@ -2,8 +2,12 @@ package services
 
 import (
 	"context"
+	"time"
+	"unicode/utf8"
 
 	"github.com/YuarenArt/marketgo/internal/db"
+	"github.com/YuarenArt/marketgo/internal/search"
+	"github.com/YuarenArt/marketgo/pkg/webhook"
 )
 
 const (
@@ -14,46 +18,269 @@ const (
 
 // CreateAdRequest представляет запрос для создания объявления
 type CreateAdRequest struct {
-	Title    string `json:"title" binding:"required,min=2,max=100"`
-	Text     string `json:"text" binding:"required,min=1,max=2000"`
-	ImageURL string `json:"image_url" binding:"required,url"`
+	Title    string `json:"title" binding:"required,ad_title"`
+	Text     string `json:"text" binding:"required,ad_text"`
+	ImageURL string `json:"image_url" binding:"required,ad_image_url"`
 	Price    int64  `json:"price" binding:"required,gte=1,lte=100000000"`
+	// Translations - необязательные переводы объявления по двухбуквенным кодам языков
+	Translations map[string]AdTranslationRequest `json:"translations" binding:"omitempty,dive"`
+	// Tags - до 10 тегов длиной от 1 до 30 символов; приводятся к нижнему регистру, повторы отбрасываются
+	Tags []string `json:"tags"`
+	// City - город до 100 символов; крайние пробелы отбрасываются
+	City string `json:"city" binding:"omitempty,ad_city"`
+}
+
+// UpdateAdRequest представляет запрос для частичного обновления объявления.
+// Поля, не переданные в запросе, сохраняют текущие значения.
+type UpdateAdRequest struct {
+	Title    *string `json:"title,omitempty" binding:"omitempty,ad_title"`
+	Text     *string `json:"text,omitempty" binding:"omitempty,ad_text"`
+	ImageURL *string `json:"image_url,omitempty" binding:"omitempty,ad_image_url"`
+	Price    *int64  `json:"price,omitempty" binding:"omitempty,gte=1,lte=100000000"`
+	// Tags заменяет все теги объявления; пустой массив удаляет их
+	Tags *[]string `json:"tags,omitempty"`
+	// City заменяет город; пустая строка убирает его
+	City *string `json:"city,omitempty" binding:"omitempty,ad_city"`
+}
+
+// IsEmpty сообщает, что запрос не содержит ни одного поля
+func (r UpdateAdRequest) IsEmpty() bool {
+	return r.Title == nil && r.Text == nil && r.ImageURL == nil && r.Price == nil && r.Tags == nil && r.City == nil
 }
 
 // GetAdsRequest представляет запрос для получения списка объявлений
 type GetAdsRequest struct {
-	Page      int    `json:"page" binding:"required,gte=1"`
-	PageSize  int    `json:"page_size" binding:"required,gte=1,lte=100"`
-	SortBy    string `json:"sort_by" binding:"omitempty,oneof=created_at price"`
-	SortOrder string `json:"sort_order" binding:"omitempty,oneof=ASC DESC"`
-	MinPrice  int64  `json:"min_price" binding:"omitempty,gte=0"`
-	MaxPrice  int64  `json:"max_price" binding:"omitempty,gte=0"`
+	Page      int      `json:"page" binding:"required,gte=1"`
+	PageSize  int      `json:"page_size" binding:"required,gte=1,lte=100"`
+	SortBy    string   `json:"sort_by" binding:"omitempty,oneof=created_at price title"`
+	SortOrder string   `json:"sort_order" binding:"omitempty,oneof=ASC DESC"`
+	MinPrice  int64    `json:"min_price" binding:"omitempty,gte=0"`
+	MaxPrice  int64    `json:"max_price" binding:"omitempty,gte=0"`
+	Statuses  []string `json:"status" binding:"omitempty,dive,oneof=active sold archived"`
+	// Tags оставляет объявления, у которых есть все перечисленные теги
+	Tags []string `json:"tag"`
+	// Cities оставляет объявления из любого перечисленного города без учёта регистра
+	Cities []string `json:"city"`
+	// CreatedFrom и CreatedTo ограничивают дату создания включительно; нулевое значение - без ограничения
+	CreatedFrom time.Time `json:"created_from"`
+	CreatedTo   time.Time `json:"created_to"`
+	// Languages - предпочитаемые языки из Accept-Language в порядке убывания приоритета
+	Languages []string `json:"-"`
+	// Query - строка полнотекстового поиска; при заданном q сортировка по релевантности
+	Query string `json:"q"`
+	// Cursor - непрозрачный курсор из next_cursor для GetAdsAfterCursor; Page при этом не используется
+	Cursor string `json:"cursor"`
+}
+
+// UpdateAdStatusRequest представляет запрос на смену статуса объявления
+type UpdateAdStatusRequest struct {
+	Status string `json:"status" binding:"required,oneof=active sold archived"`
 }
 
 // AdService предоставляет методы для работы с объявлениями
 type AdService struct {
-	db *db.DBService
+	db         AdStorage
+	suggest    *suggestCache
+	priceDrops chan PriceDropEvent
+	search     search.Index
+	// listCache - кэш страниц GetAds; nil, пока не включён через UseAdsCache
+	listCache *adsCache
+	// adTTL - срок публикации нового или продлённого объявления
+	adTTL time.Duration
+	// events - получатель событий для вебхуков; nil, пока не включён через UseAdEvents
+	events AdEvents
+}
+
+// NewAdService создает новый экземпляр AdService.
+// Поиск по q выполняется средствами PostgreSQL, пока не задан другой индекс через UseSearchIndex.
+func NewAdService(db AdStorage) *AdService {
+	return &AdService{
+		db:         db,
+		suggest:    newSuggestCache(SuggestCacheTTL, SuggestCacheSize),
+		priceDrops: make(chan PriceDropEvent, PriceDropQueueSize),
+		search:     search.NewSQLIndex(db),
+		adTTL:      DefaultAdTTL,
+	}
+}
+
+// UseSearchIndex задаёт поисковый индекс для запросов с q и передаёт ему изменения объявлений
+func (s *AdService) UseSearchIndex(idx search.Index) {
+	s.search = idx
 }
 
-// NewAdService создает новый экземпляр AdService
-func NewAdService(db *db.DBService) *AdService {
-	return &AdService{db: db}
+// UseAdsCache включает кэширование страниц GetAds и GetAdsWithMeta на ttl.
+// Кэш очищается при любом изменении объявлений через AdService; observe получает попадания и промахи.
+func (s *AdService) UseAdsCache(ttl time.Duration, observe func(hit bool)) {
+	s.listCache = newAdsCache(ttl, observe)
+}
+
+// invalidateAds сбрасывает кэш страниц после изменения объявлений
+func (s *AdService) invalidateAds() {
+	if s.listCache != nil {
+		s.listCache.clear()
+	}
 }
 
 // CreateAd создает новое объявление, связанное с userID
 func (s *AdService) CreateAd(ctx context.Context, req CreateAdRequest, userID int) (db.Ad, error) {
 	ad := db.Ad{
+		Title:        req.Title,
+		Text:         req.Text,
+		ImageURL:     req.ImageURL,
+		Price:        req.Price,
+		UserID:       userID,
+		Translations: toDBTranslations(req.Translations),
+		Tags:         req.Tags,
+		City:         req.City,
+		ExpiresAt:    time.Now().Add(s.adTTL),
+	}
+	created, err := s.db.CreateAd(ctx, ad)
+	if err != nil {
+		return db.Ad{}, err
+	}
+	s.invalidateAds()
+
+	// Индекс обновляется асинхронно и не влияет на результат операции
+	_ = s.search.IndexAd(ctx, created)
+	s.publishAd(webhook.EventAdCreated, created)
+	return created, nil
+}
+
+// UpdateAd частично обновляет объявление adID, принадлежащее userID.
+// При снижении цены публикует PriceDropEvent.
+func (s *AdService) UpdateAd(ctx context.Context, adID int, req UpdateAdRequest, userID int) (db.Ad, error) {
+	var oldPrice int64
+	if req.Price != nil {
+		current, err := s.db.Ad(ctx, adID, userID)
+		if err != nil {
+			return db.Ad{}, err
+		}
+		oldPrice = current.Price
+	}
+
+	upd := db.AdUpdate{
 		Title:    req.Title,
 		Text:     req.Text,
 		ImageURL: req.ImageURL,
 		Price:    req.Price,
-		UserID:   userID,
+		Tags:     req.Tags,
+		City:     req.City,
+	}
+	ad, err := s.db.UpdateAd(ctx, adID, userID, upd)
+	if err != nil {
+		return db.Ad{}, err
 	}
-	return s.db.CreateAd(ctx, ad)
+	s.invalidateAds()
+
+	if req.Price != nil && ad.Price < oldPrice {
+		s.publishPriceDrop(PriceDropEvent{AdID: ad.ID, Title: ad.Title, OldPrice: oldPrice, NewPrice: ad.Price})
+	}
+	_ = s.search.IndexAd(ctx, ad)
+	s.publishAd(webhook.EventAdUpdated, ad)
+	return ad, nil
+}
+
+// PriceDrops возвращает канал событий о снижении цены
+func (s *AdService) PriceDrops() <-chan PriceDropEvent {
+	return s.priceDrops
 }
 
-// GetAds возвращает список объявлений с учетом фильтров и сортировки
+// publishPriceDrop публикует событие без блокировки; при переполненной очереди событие отбрасывается
+func (s *AdService) publishPriceDrop(ev PriceDropEvent) {
+	select {
+	case s.priceDrops <- ev:
+	default:
+	}
+}
+
+// GetAds возвращает список объявлений с учетом фильтров и сортировки.
+// Заголовок и текст отдаются на первом из req.Languages, для которого есть перевод.
 func (s *AdService) GetAds(ctx context.Context, req GetAdsRequest, userID int) ([]db.Ad, error) {
+	ads, _, err := s.getAds(ctx, req, userID)
+	return ads, err
+}
+
+// getAds возвращает страницу объявлений и, для поиска по q, общее количество найденных (иначе -1).
+// С включённым кэшем страница загружается без привязки к пользователю (userID 0) и кэшируется
+// по всем параметрам запроса, а is_mine и is_favorite вычисляются для userID при каждом обращении.
+func (s *AdService) getAds(ctx context.Context, req GetAdsRequest, userID int) ([]db.Ad, int, error) {
+	filter := applyAdsDefaults(&req)
+	if s.listCache == nil {
+		return s.loadAds(ctx, req, filter, userID)
+	}
+
+	key := adsCacheKey(req)
+	ads, total, ok := s.listCache.get(key)
+	if !ok {
+		var err error
+		if ads, total, err = s.loadAds(ctx, req, filter, 0); err != nil {
+			return nil, 0, err
+		}
+		s.listCache.set(key, ads, total)
+	}
+	ads, err := s.withFavorites(ctx, withIsMine(ads, userID), userID)
+	return ads, total, err
+}
+
+// loadAds загружает страницу объявлений из БД или поискового индекса.
+// При заданном q объявления ищутся в поисковом индексе и упорядочиваются по релевантности,
+// а затем загружаются из БД, где вычисляются is_mine и is_favorite и повторно проверяются фильтры.
+func (s *AdService) loadAds(ctx context.Context, req GetAdsRequest, filter db.AdsFilter, userID int) ([]db.Ad, int, error) {
+	total := -1
+	var ads []db.Ad
+	var err error
+	if req.Query != "" {
+		var result search.Result
+		result, err = s.search.Search(ctx, req.Query, filter, search.Page{Page: req.Page, Size: req.PageSize})
+		if err != nil {
+			return nil, 0, err
+		}
+		total = result.Total
+		ads, err = s.db.AdsByIDs(ctx, userID, result.IDs, filter)
+	} else {
+		ads, err = s.db.Ads(ctx, userID, req.Page, req.PageSize, req.SortBy, req.SortOrder, filter)
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ads, err = s.translateAds(ctx, ads, req.Languages)
+	return ads, total, err
+}
+
+// PagedAds - страница списка объявлений с метаданными пагинации
+type PagedAds struct {
+	Items      []db.Ad `json:"items"`
+	Total      int     `json:"total"`
+	Page       int     `json:"page"`
+	PageSize   int     `json:"page_size"`
+	TotalPages int     `json:"total_pages"`
+}
+
+// GetAdsWithMeta возвращает страницу объявлений как GetAds вместе с общим количеством объявлений и страниц
+func (s *AdService) GetAdsWithMeta(ctx context.Context, req GetAdsRequest, userID int) (PagedAds, error) {
+	ads, total, err := s.getAds(ctx, req, userID)
+	if err != nil {
+		return PagedAds{}, err
+	}
+	if total < 0 {
+		filter := applyAdsDefaults(&req)
+		if total, err = s.db.CountAds(ctx, filter); err != nil {
+			return PagedAds{}, err
+		}
+	}
+
+	return PagedAds{
+		Items:      ads,
+		Total:      total,
+		Page:       req.Page,
+		PageSize:   req.PageSize,
+		TotalPages: (total + req.PageSize - 1) / req.PageSize,
+	}, nil
+}
+
+// applyAdsDefaults подставляет сортировку и максимальную цену по умолчанию и возвращает фильтр запроса
+func applyAdsDefaults(req *GetAdsRequest) db.AdsFilter {
 	if req.SortBy == "" {
 		req.SortBy = DefaultSortBy
 	}
@@ -63,5 +290,130 @@ func (s *AdService) GetAds(ctx context.Context, req GetAdsRequest, userID int) (
 	if req.MaxPrice == 0 {
 		req.MaxPrice = DefaultMaxPrice
 	}
-	return s.db.Ads(ctx, userID, req.Page, req.PageSize, req.SortBy, req.SortOrder, req.MinPrice, req.MaxPrice)
+	return db.AdsFilter{
+		MinPrice:    req.MinPrice,
+		MaxPrice:    req.MaxPrice,
+		CreatedFrom: req.CreatedFrom,
+		CreatedTo:   req.CreatedTo,
+		Statuses:    req.Statuses,
+		Tags:        req.Tags,
+		Cities:      req.Cities,
+	}
+}
+
+// SetAdStatus меняет статус объявления adID, принадлежащего userID.
+// Владелец получает уведомление о смене статуса; ошибка уведомления не отменяет смену статуса.
+func (s *AdService) SetAdStatus(ctx context.Context, adID int, req UpdateAdStatusRequest, userID int) (db.Ad, error) {
+	ad, err := s.db.SetAdStatus(ctx, adID, userID, req.Status)
+	if err != nil {
+		return db.Ad{}, err
+	}
+	s.invalidateAds()
+
+	_, _, _ = s.db.CreateNotification(ctx, ad.UserID, db.NotificationTypeAdStatusChanged, AdStatusChangedPayload{
+		AdID:   ad.ID,
+		Title:  ad.Title,
+		Status: ad.Status,
+	})
+	_ = s.search.IndexAd(ctx, ad)
+	s.publishAd(webhook.EventAdUpdated, ad)
+	return ad, nil
+}
+
+// GetAd возвращает объявление adID; userID нужен для признаков is_mine и is_favorite
+func (s *AdService) GetAd(ctx context.Context, adID, userID int) (db.Ad, error) {
+	return s.db.Ad(ctx, adID, userID)
+}
+
+// DeleteAd удаляет объявление adID, принадлежащее userID
+func (s *AdService) DeleteAd(ctx context.Context, adID, userID int) error {
+	if err := s.db.DeleteAd(ctx, adID, userID); err != nil {
+		return err
+	}
+	s.invalidateAds()
+	_ = s.search.DeleteAd(ctx, adID)
+	s.publishAdDeleted(adID)
+	return nil
+}
+
+// RemoveAd удаляет объявление adID по решению модератора moderatorID независимо от владельца
+func (s *AdService) RemoveAd(ctx context.Context, adID, moderatorID int) error {
+	if err := s.db.RemoveAd(ctx, adID, moderatorID); err != nil {
+		return err
+	}
+	s.invalidateAds()
+	_ = s.search.DeleteAd(ctx, adID)
+	s.publishAdDeleted(adID)
+	return nil
+}
+
+// GetMyAds возвращает объявления пользователя userID с учетом пагинации и сортировки.
+// Фильтры по цене не применяются.
+func (s *AdService) GetMyAds(ctx context.Context, req GetAdsRequest, userID int) ([]db.Ad, error) {
+	if req.SortBy == "" {
+		req.SortBy = DefaultSortBy
+	}
+	if req.SortOrder == "" {
+		req.SortOrder = DefaultSortOrder
+	}
+	return s.db.AdsByUser(ctx, userID, req.Page, req.PageSize, req.SortBy, req.SortOrder)
+}
+
+// SellerProfile - публичный профиль продавца: открытые поля учётной записи и его активные объявления
+type SellerProfile struct {
+	Login     string    `json:"login"`
+	CreatedAt time.Time `json:"created_at"`
+	Ads       []db.Ad   `json:"ads"`
+}
+
+// GetSellerProfile возвращает профиль продавца login и страницу его активных объявлений
+// с пагинацией и сортировкой запроса; фильтры цены и статуса не применяются.
+func (s *AdService) GetSellerProfile(ctx context.Context, login string, req GetAdsRequest) (SellerProfile, error) {
+	seller, err := s.db.UserByLogin(ctx, login)
+	if err != nil {
+		return SellerProfile{}, err
+	}
+
+	applyAdsDefaults(&req)
+	filter := db.AdsFilter{MaxPrice: DefaultMaxPrice, SellerID: seller.ID}
+	ads, err := s.db.Ads(ctx, 0, req.Page, req.PageSize, req.SortBy, req.SortOrder, filter)
+	if err != nil {
+		return SellerProfile{}, err
+	}
+	if ads, err = s.translateAds(ctx, ads, req.Languages); err != nil {
+		return SellerProfile{}, err
+	}
+
+	return SellerProfile{Login: seller.Login, CreatedAt: seller.CreatedAt, Ads: ads}, nil
+}
+
+// Suggest возвращает подсказки для строки поиска по словам из заголовков объявлений.
+// Запросы короче MinSuggestRunes символов не обращаются к базе данных.
+// Результаты кэшируются по нормализованному префиксу на SuggestCacheTTL.
+func (s *AdService) Suggest(ctx context.Context, q string, limit int) ([]string, error) {
+	prefix := normalizeSuggestPrefix(q)
+	if utf8.RuneCountInString(prefix) < MinSuggestRunes {
+		return []string{}, nil
+	}
+	if limit <= 0 {
+		limit = DefaultSuggestLimit
+	}
+	if limit > MaxSuggestLimit {
+		limit = MaxSuggestLimit
+	}
+
+	words, ok := s.suggest.get(prefix)
+	if !ok {
+		var err error
+		words, err = s.db.SuggestTitleWords(ctx, prefix, MaxSuggestLimit)
+		if err != nil {
+			return nil, err
+		}
+		s.suggest.set(prefix, words)
+	}
+
+	if len(words) > limit {
+		words = words[:limit]
+	}
+	return words, nil
 }
@@ -185,9 +185,158 @@ func TestGetAds(t *testing.T) {
 		_, err := adService.GetAds(testCtx, req, user1.ID)
 		assert.ErrorIs(t, err, db.ErrInvalidSortOrder)
 	})
+
+	t.Run("pagination metadata", func(t *testing.T) {
+		req := GetAdsRequest{
+			Page:     2,
+			PageSize: 1,
+			SortBy:   "price",
+		}
+		paged, err := adService.GetAdsWithMeta(testCtx, req, user1.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 2, paged.Total)
+		assert.Equal(t, 2, paged.Page)
+		assert.Equal(t, 1, paged.PageSize)
+		assert.Equal(t, 2, paged.TotalPages)
+		require.Len(t, paged.Items, 1)
+		assert.Equal(t, ad2.Title, paged.Items[0].Title)
+
+		req.MinPrice = 1500
+		req.Page = 5
+		paged, err = adService.GetAdsWithMeta(testCtx, req, user1.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 1, paged.Total, "total honours the same filter as the list")
+		assert.Equal(t, 1, paged.TotalPages)
+		assert.NotNil(t, paged.Items)
+		assert.Empty(t, paged.Items)
+	})
+}
+
+func TestUpdateAd(t *testing.T) {
+	adService := NewAdService(testDB)
+
+	owner, err := testDB.CreateUser(testCtx, "patchowner", "hashedpass")
+	require.NoError(t, err)
+	other, err := testDB.CreateUser(testCtx, "patchother", "hashedpass")
+	require.NoError(t, err)
+
+	ad, err := adService.CreateAd(testCtx, CreateAdRequest{
+		Title:    "Patch me",
+		Text:     "Some text",
+		ImageURL: "https://example.com/patch.png",
+		Price:    1000,
+	}, owner.ID)
+	require.NoError(t, err)
+
+	t.Run("missing fields keep current values", func(t *testing.T) {
+		imageURL := "https://example.com/new.png"
+		updated, err := adService.UpdateAd(testCtx, ad.ID, UpdateAdRequest{ImageURL: &imageURL}, owner.ID)
+		require.NoError(t, err)
+		assert.Equal(t, imageURL, updated.ImageURL)
+		assert.Equal(t, ad.Title, updated.Title)
+		assert.Equal(t, ad.Text, updated.Text)
+		assert.Equal(t, ad.Price, updated.Price)
+	})
+
+	t.Run("empty request returns error", func(t *testing.T) {
+		req := UpdateAdRequest{}
+		assert.True(t, req.IsEmpty())
+		_, err := adService.UpdateAd(testCtx, ad.ID, req, owner.ID)
+		assert.ErrorIs(t, err, db.ErrEmptyUpdate)
+	})
+
+	t.Run("other user gets ownership error", func(t *testing.T) {
+		price := int64(1)
+		_, err := adService.UpdateAd(testCtx, ad.ID, UpdateAdRequest{Price: &price}, other.ID)
+		assert.ErrorIs(t, err, db.ErrNotAdOwner)
+	})
 }
 
 // clearTables очищает таблицы users и ads
 func clearTables(ctx context.Context, db *db.DBService) error {
 	return db.Exec(ctx, "TRUNCATE TABLE ads, users CASCADE")
 }
+
+func TestGetAdsAfterCursor(t *testing.T) {
+	adService := NewAdService(testDB)
+
+	err := clearTables(testCtx, testDB)
+	require.NoError(t, err)
+
+	user, err := testDB.CreateUser(testCtx, "cursoruser", "pass")
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		_, err := testDB.CreateAd(testCtx, db.Ad{Title: "Cursor ad", Text: "text", Price: 100, UserID: user.ID})
+		require.NoError(t, err)
+	}
+
+	req := GetAdsRequest{PageSize: 2}
+	first, err := adService.GetAdsAfterCursor(testCtx, req, user.ID)
+	require.NoError(t, err)
+	assert.Len(t, first.Ads, 2)
+	require.NotEmpty(t, first.NextCursor)
+
+	req.Cursor = first.NextCursor
+	second, err := adService.GetAdsAfterCursor(testCtx, req, user.ID)
+	require.NoError(t, err)
+	assert.Len(t, second.Ads, 1)
+	assert.Empty(t, second.NextCursor)
+
+	req.Cursor = "not-a-cursor"
+	_, err = adService.GetAdsAfterCursor(testCtx, req, user.ID)
+	assert.EqualError(t, err, ErrInvalidCursor)
+
+	req.Cursor = first.NextCursor
+	req.SortBy = "price"
+	_, err = adService.GetAdsAfterCursor(testCtx, req, user.ID)
+	assert.ErrorIs(t, err, db.ErrCursorMismatch)
+}
+
+func TestGetSellerProfile(t *testing.T) {
+	adService := NewAdService(testDB)
+	ctx := context.Background()
+
+	err := clearTables(ctx, testDB)
+	require.NoError(t, err)
+
+	seller, err := testDB.CreateUser(ctx, "publicseller", "pass")
+	require.NoError(t, err)
+	other, err := testDB.CreateUser(ctx, "otherseller", "pass")
+	require.NoError(t, err)
+
+	for _, price := range []int64{300, 100, 200} {
+		_, err := testDB.CreateAd(ctx, db.Ad{Title: "Seller ad", Text: "text", Price: price, UserID: seller.ID})
+		require.NoError(t, err)
+	}
+	sold, err := testDB.CreateAd(ctx, db.Ad{Title: "Sold ad", Text: "text", Price: 50, UserID: seller.ID})
+	require.NoError(t, err)
+	_, err = testDB.SetAdStatus(ctx, sold.ID, seller.ID, db.AdStatusSold)
+	require.NoError(t, err)
+	_, err = testDB.CreateAd(ctx, db.Ad{Title: "Foreign ad", Text: "text", Price: 150, UserID: other.ID})
+	require.NoError(t, err)
+
+	t.Run("returns public fields and active ads", func(t *testing.T) {
+		profile, err := adService.GetSellerProfile(ctx, "publicseller", GetAdsRequest{Page: 1, PageSize: 10, SortBy: "price", SortOrder: "ASC"})
+		require.NoError(t, err)
+		assert.Equal(t, "publicseller", profile.Login)
+		assert.False(t, profile.CreatedAt.IsZero())
+		require.Len(t, profile.Ads, 3)
+		assert.Equal(t, []int64{100, 200, 300}, []int64{profile.Ads[0].Price, profile.Ads[1].Price, profile.Ads[2].Price})
+		for _, ad := range profile.Ads {
+			assert.Equal(t, seller.ID, ad.UserID)
+			assert.Equal(t, db.AdStatusActive, ad.Status)
+		}
+	})
+
+	t.Run("pagination works correctly", func(t *testing.T) {
+		profile, err := adService.GetSellerProfile(ctx, "publicseller", GetAdsRequest{Page: 2, PageSize: 2, SortBy: "price", SortOrder: "ASC"})
+		require.NoError(t, err)
+		require.Len(t, profile.Ads, 1)
+		assert.Equal(t, int64(300), profile.Ads[0].Price)
+	})
+
+	t.Run("unknown login returns error", func(t *testing.T) {
+		_, err := adService.GetSellerProfile(ctx, "nosuchseller", GetAdsRequest{Page: 1, PageSize: 10})
+		assert.ErrorIs(t, err, db.ErrLoginNotFound)
+	})
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/YuarenArt/marketgo/internal/db"
+)
+
+// MaxAdsCacheEntries ограничивает число закэшированных страниц; при переполнении кэш очищается
+const MaxAdsCacheEntries = 1000
+
+// adsCacheEntry хранит страницу объявлений без признака is_mine и время её устаревания
+type adsCacheEntry struct {
+	ads       []db.Ad
+	total     int
+	expiresAt time.Time
+}
+
+// adsCache - потокобезопасный кэш страниц списка объявлений с TTL.
+// Страницы хранятся без привязки к пользователю, поэтому одна запись обслуживает всех;
+// is_mine вычисляется для каждого запроса. observe вызывается на каждое обращение с признаком попадания.
+type adsCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	now     func() time.Time
+	observe func(hit bool)
+	entries map[string]adsCacheEntry
+}
+
+// newAdsCache создает кэш страниц объявлений с заданным TTL; observe может быть nil
+func newAdsCache(ttl time.Duration, observe func(hit bool)) *adsCache {
+	if observe == nil {
+		observe = func(bool) {}
+	}
+	return &adsCache{
+		ttl:     ttl,
+		now:     time.Now,
+		observe: observe,
+		entries: make(map[string]adsCacheEntry),
+	}
+}
+
+// get возвращает копию страницы по ключу, если она есть и не устарела
+func (c *adsCache) get(key string) ([]db.Ad, int, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if ok && !c.now().Before(entry.expiresAt) {
+		delete(c.entries, key)
+		ok = false
+	}
+	c.observe(ok)
+	if !ok {
+		return nil, 0, false
+	}
+	return append([]db.Ad(nil), entry.ads...), entry.total, true
+}
+
+// set сохраняет копию страницы по ключу
+func (c *adsCache) set(key string, ads []db.Ad, total int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.entries) >= MaxAdsCacheEntries {
+		c.entries = make(map[string]adsCacheEntry)
+	}
+	c.entries[key] = adsCacheEntry{
+		ads:       append([]db.Ad(nil), ads...),
+		total:     total,
+		expiresAt: c.now().Add(c.ttl),
+	}
+}
+
+// clear удаляет все страницы; вызывается при любом изменении объявлений
+func (c *adsCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = make(map[string]adsCacheEntry)
+}
+
+// adsCacheKey составляет ключ кэша из всех параметров фильтрации, сортировки, пагинации и языков запроса.
+// req должен быть уже дополнен значениями по умолчанию через applyAdsDefaults.
+func adsCacheKey(req GetAdsRequest) string {
+	return fmt.Sprintf("%d|%d|%s|%s|%d|%d|%s|%d|%d|%s|%q|%q|%q",
+		req.Page, req.PageSize, req.SortBy, req.SortOrder, req.MinPrice, req.MaxPrice,
+		strings.Join(req.Statuses, ","), req.CreatedFrom.UnixNano(), req.CreatedTo.UnixNano(),
+		strings.Join(req.Languages, ","), req.Query, req.Tags, req.Cities)
+}
+
+// withIsMine возвращает ads с признаком is_mine для userID
+func withIsMine(ads []db.Ad, userID int) []db.Ad {
+	for i := range ads {
+		ads[i].IsMine = userID != 0 && ads[i].UserID == userID
+	}
+	return ads
+}
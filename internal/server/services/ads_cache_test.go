@@ -0,0 +1,77 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/YuarenArt/marketgo/internal/db/dbtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnitAdsCache(t *testing.T) {
+	ctx := context.Background()
+	store := dbtest.NewStore()
+	adService := NewAdService(store)
+
+	var hits, misses int
+	adService.UseAdsCache(time.Minute, func(hit bool) {
+		if hit {
+			hits++
+		} else {
+			misses++
+		}
+	})
+
+	owner, err := store.CreateUser(ctx, "cacheowner", "hash")
+	require.NoError(t, err)
+	other, err := store.CreateUser(ctx, "cacheother", "hash")
+	require.NoError(t, err)
+
+	_, err = adService.CreateAd(ctx, CreateAdRequest{Title: "Велосипед", Text: "Горный", ImageURL: "http://example.com/1.jpg", Price: 300}, owner.ID)
+	require.NoError(t, err)
+
+	req := GetAdsRequest{Page: 1, PageSize: 10}
+
+	t.Run("second request is served from cache with is_mine per user", func(t *testing.T) {
+		own, err := adService.GetAds(ctx, req, owner.ID)
+		require.NoError(t, err)
+		require.Len(t, own, 1)
+		assert.True(t, own[0].IsMine)
+
+		foreign, err := adService.GetAds(ctx, req, other.ID)
+		require.NoError(t, err)
+		require.Len(t, foreign, 1)
+		assert.False(t, foreign[0].IsMine)
+		assert.True(t, own[0].IsMine, "cached page is not shared with the first caller")
+
+		assert.Equal(t, 1, misses)
+		assert.Equal(t, 1, hits)
+	})
+
+	t.Run("different page is a separate entry", func(t *testing.T) {
+		_, err := adService.GetAds(ctx, GetAdsRequest{Page: 2, PageSize: 10}, owner.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 2, misses)
+	})
+
+	t.Run("create invalidates the cache", func(t *testing.T) {
+		_, err := adService.CreateAd(ctx, CreateAdRequest{Title: "Самокат", Text: "Детский", ImageURL: "http://example.com/2.jpg", Price: 100}, other.ID)
+		require.NoError(t, err)
+
+		ads, err := adService.GetAds(ctx, req, owner.ID)
+		require.NoError(t, err)
+		assert.Len(t, ads, 2)
+		assert.Equal(t, 3, misses)
+	})
+
+	t.Run("expired entry is reloaded", func(t *testing.T) {
+		adService.listCache.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+		defer func() { adService.listCache.now = time.Now }()
+
+		_, err := adService.GetAds(ctx, req, owner.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 4, misses)
+	})
+}
@@ -0,0 +1,116 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/YuarenArt/marketgo/internal/db"
+)
+
+// AnnouncementRequest представляет запрос на создание или замену объявления администрации
+type AnnouncementRequest struct {
+	Message  string     `json:"message" binding:"required,max=500"`
+	Severity string     `json:"severity" binding:"required,oneof=info warning critical"`
+	StartsAt time.Time  `json:"starts_at" binding:"required"`
+	EndsAt   *time.Time `json:"ends_at"`
+}
+
+// AnnouncementService управляет объявлениями администрации.
+// Полный список объявлений кэшируется в памяти и сбрасывается при любом изменении.
+type AnnouncementService struct {
+	db  *db.DBService
+	now func() time.Time
+
+	mu     sync.RWMutex
+	cache  []db.Announcement
+	cached bool
+}
+
+// NewAnnouncementService создает новый экземпляр AnnouncementService
+func NewAnnouncementService(db *db.DBService) *AnnouncementService {
+	return &AnnouncementService{db: db, now: time.Now}
+}
+
+// Active возвращает объявления, действующие в текущий момент, по убыванию важности
+func (s *AnnouncementService) Active(ctx context.Context) ([]db.Announcement, error) {
+	all, err := s.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	active := make([]db.Announcement, 0, len(all))
+	for _, a := range all {
+		if a.ActiveAt(now) {
+			active = append(active, a)
+		}
+	}
+	return active, nil
+}
+
+// List возвращает все объявления администрации, включая запланированные и завершённые
+func (s *AnnouncementService) List(ctx context.Context) ([]db.Announcement, error) {
+	s.mu.RLock()
+	if s.cached {
+		all := s.cache
+		s.mu.RUnlock()
+		return all, nil
+	}
+	s.mu.RUnlock()
+
+	all, err := s.db.Announcements(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.cache, s.cached = all, true
+	s.mu.Unlock()
+	return all, nil
+}
+
+// Create создаёт объявление администрации
+func (s *AnnouncementService) Create(ctx context.Context, req AnnouncementRequest) (db.Announcement, error) {
+	a, err := s.db.CreateAnnouncement(ctx, req.toAnnouncement())
+	if err != nil {
+		return db.Announcement{}, err
+	}
+	s.invalidate()
+	return a, nil
+}
+
+// Update заменяет объявление администрации id
+func (s *AnnouncementService) Update(ctx context.Context, id int, req AnnouncementRequest) (db.Announcement, error) {
+	a, err := s.db.UpdateAnnouncement(ctx, id, req.toAnnouncement())
+	if err != nil {
+		return db.Announcement{}, err
+	}
+	s.invalidate()
+	return a, nil
+}
+
+// Delete удаляет объявление администрации id
+func (s *AnnouncementService) Delete(ctx context.Context, id int) error {
+	if err := s.db.DeleteAnnouncement(ctx, id); err != nil {
+		return err
+	}
+	s.invalidate()
+	return nil
+}
+
+// invalidate сбрасывает кэш объявлений
+func (s *AnnouncementService) invalidate() {
+	s.mu.Lock()
+	s.cache, s.cached = nil, false
+	s.mu.Unlock()
+}
+
+func (r AnnouncementRequest) toAnnouncement() db.Announcement {
+	return db.Announcement{
+		Message:  r.Message,
+		Severity: r.Severity,
+		StartsAt: r.StartsAt,
+		EndsAt:   r.EndsAt,
+	}
+}
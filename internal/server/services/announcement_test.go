@@ -0,0 +1,104 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/YuarenArt/marketgo/internal/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnnouncements(t *testing.T) {
+	svc := NewAnnouncementService(testDB)
+
+	require.NoError(t, testDB.Exec(testCtx, "TRUNCATE TABLE announcements"))
+
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+
+	hourAgo := now.Add(-time.Hour)
+	inHour := now.Add(time.Hour)
+
+	create := func(message, severity string, startsAt time.Time, endsAt *time.Time) db.Announcement {
+		a, err := svc.Create(testCtx, AnnouncementRequest{
+			Message:  message,
+			Severity: severity,
+			StartsAt: startsAt,
+			EndsAt:   endsAt,
+		})
+		require.NoError(t, err)
+		return a
+	}
+
+	info := create("Новые категории", db.AnnouncementSeverityInfo, now.Add(-48*time.Hour), nil)
+	critical := create("Техработы", db.AnnouncementSeverityCritical, hourAgo, &inHour)
+	warning := create("Возможны задержки", db.AnnouncementSeverityWarning, hourAgo, nil)
+	create("Завершённые техработы", db.AnnouncementSeverityCritical, now.Add(-3*time.Hour), &hourAgo)
+	create("Плановые техработы", db.AnnouncementSeverityWarning, inHour, nil)
+
+	messages := func(list []db.Announcement) []string {
+		result := make([]string, 0, len(list))
+		for _, a := range list {
+			result = append(result, a.Message)
+		}
+		return result
+	}
+
+	t.Run("only announcements within time window are active", func(t *testing.T) {
+		active, err := svc.Active(testCtx)
+		require.NoError(t, err)
+		assert.Equal(t, []string{critical.Message, warning.Message, info.Message}, messages(active))
+
+		all, err := svc.List(testCtx)
+		require.NoError(t, err)
+		assert.Len(t, all, 5)
+	})
+
+	t.Run("window boundaries", func(t *testing.T) {
+		svc.now = func() time.Time { return inHour }
+		defer func() { svc.now = func() time.Time { return now } }()
+
+		active, err := svc.Active(testCtx)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Плановые техработы", warning.Message, info.Message}, messages(active))
+	})
+
+	t.Run("invalid window is rejected", func(t *testing.T) {
+		_, err := svc.Create(testCtx, AnnouncementRequest{
+			Message:  "Ошибка",
+			Severity: db.AnnouncementSeverityInfo,
+			StartsAt: now,
+			EndsAt:   &hourAgo,
+		})
+		assert.ErrorIs(t, err, db.ErrInvalidAnnouncementWindow)
+	})
+
+	t.Run("cache is invalidated on write", func(t *testing.T) {
+		_, err := svc.Active(testCtx)
+		require.NoError(t, err)
+
+		require.NoError(t, testDB.Exec(testCtx, "DELETE FROM announcements WHERE id = $1", warning.ID))
+		active, err := svc.Active(testCtx)
+		require.NoError(t, err)
+		assert.Contains(t, messages(active), warning.Message, "direct writes are not visible until invalidation")
+
+		_, err = svc.Update(testCtx, info.ID, AnnouncementRequest{
+			Message:  "Новые категории уже доступны",
+			Severity: db.AnnouncementSeverityInfo,
+			StartsAt: info.StartsAt,
+		})
+		require.NoError(t, err)
+
+		active, err = svc.Active(testCtx)
+		require.NoError(t, err)
+		assert.Equal(t, []string{critical.Message, "Новые категории уже доступны"}, messages(active))
+
+		require.NoError(t, svc.Delete(testCtx, critical.ID))
+		active, err = svc.Active(testCtx)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Новые категории уже доступны"}, messages(active))
+
+		assert.ErrorIs(t, svc.Delete(testCtx, critical.ID), db.ErrAnnouncementNotFound)
+	})
+}
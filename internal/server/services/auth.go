@@ -2,11 +2,18 @@ package services
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/YuarenArt/marketgo/internal/db"
+	"github.com/YuarenArt/marketgo/pkg/logging"
 	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -15,14 +22,23 @@ const (
 	ErrPasswordLength    = "password must be between 8 and 72 characters"
 	ErrInvalidToken      = "invalid token"
 	ErrInvalidTokenClaim = "invalid token claims"
-	ErrTokenExpired      = "token expired"
-	ErrTokenNotYetValid  = "token not valid yet"
-	ErrTokenIssuedFuture = "token issued in the future"
-	ErrInvalidIssuer     = "invalid issuer"
-	ErrInvalidAudience   = "invalid audience"
 	ErrInvalidUserID     = "invalid user_id claim"
+	ErrUserDeleted       = "user no longer exists"
+	ErrTokenRevoked      = "token revoked"
+	ErrWrongPassword     = "wrong password"
 	Issuer               = "auth-services"
 	Audience             = "marketgo-api"
+
+	// DefaultTokenTTL - срок действия JWT-токена доступа по умолчанию
+	DefaultTokenTTL = 24 * time.Hour
+	// DefaultBcryptCost - стоимость хеширования паролей по умолчанию
+	DefaultBcryptCost = bcrypt.DefaultCost
+	// TokenLeeway - допустимое расхождение часов при проверке exp, nbf и iat
+	TokenLeeway = time.Second
+	// RefreshTokenTTL - срок действия refresh-токена
+	RefreshTokenTTL = 30 * 24 * time.Hour
+	// RevocationCleanupInterval - как часто из списка отозванных удаляются истёкшие токены
+	RevocationCleanupInterval = time.Hour
 )
 
 // InputUserInfo представляет входные данные для регистрации и входа
@@ -31,106 +47,431 @@ type InputUserInfo struct {
 	Password string `json:"password" binding:"required,min=8,max=72"`
 }
 
+// DeleteAccountRequest - подтверждение удаления учётной записи текущим паролем
+type DeleteAccountRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+// LogoutRequest - refresh-токен, который нужно отозвать вместе с токеном доступа; необязателен
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// RefreshRequest - refresh-токен для обмена на новую пару токенов
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// TokenPair - токен доступа и refresh-токен, которым его можно обновить после истечения
+type TokenPair struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	// ExpiresIn - через сколько секунд истекает Token
+	ExpiresIn int64 `json:"expires_in"`
+	// UserID - владелец токенов, в ответ не попадает
+	UserID int `json:"-"`
+}
+
+// SetRoleRequest - новая роль пользователя
+type SetRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=user admin"`
+}
+
+// Profile - учётная запись пользователя с количеством его объявлений
+type Profile struct {
+	db.User
+	AdsCount int `json:"ads_count"`
+}
+
+// TokenClaims - содержимое JWT-токена доступа.
+// ID (jti) используется для отзыва и пуст у токенов, выданных до появления отзыва.
+type TokenClaims struct {
+	UserID int `json:"user_id"`
+	// Role - роль на момент выдачи токена; токены без роли считаются токенами обычного пользователя
+	Role string `json:"role,omitempty"`
+	jwt.RegisteredClaims
+}
+
 // AuthService отвечает за регистрацию, аутентификацию и валидацию JWT-токенов
 type AuthService struct {
-	db     *db.DBService
+	db     UserStorage
 	secret string
+	// privateKey включает подпись RS256 вместо HS256; токены проверяются открытым ключом
+	privateKey *rsa.PrivateKey
+	tokenTTL   time.Duration
+	bcryptCost int
+	// passwordPolicy включает проверку сложности пароля при регистрации
+	passwordPolicy bool
+	// limiter блокирует вход по логину после серии неудачных попыток; nil - без ограничений
+	limiter *LoginLimiter
+	// availability - трекер доступности базы данных; nil - база всегда считается доступной
+	availability *Availability
 }
 
-// NewAuthService создает новый экземпляр AuthService
-func NewAuthService(db *db.DBService, secret string) *AuthService {
-	return &AuthService{db: db, secret: secret}
+// NewAuthService создает новый экземпляр AuthService, выдающий токены доступа со сроком действия tokenTTL
+// и хеширующий пароли bcrypt со стоимостью bcryptCost.
+// Неположительный tokenTTL заменяется на DefaultTokenTTL, нулевой bcryptCost - на DefaultBcryptCost.
+func NewAuthService(db UserStorage, secret string, tokenTTL time.Duration, bcryptCost int) *AuthService {
+	if tokenTTL <= 0 {
+		tokenTTL = DefaultTokenTTL
+	}
+	if bcryptCost == 0 {
+		bcryptCost = DefaultBcryptCost
+	}
+	return &AuthService{db: db, secret: secret, tokenTTL: tokenTTL, bcryptCost: bcryptCost}
 }
 
-// Register регистрирует нового пользователя с хешированным паролем
+// BcryptCostError - стоимость bcrypt вне допустимого диапазона
+type BcryptCostError struct {
+	Cost int
+}
+
+func (e *BcryptCostError) Error() string {
+	return fmt.Sprintf("bcrypt cost must be between %d and %d, got %d", bcrypt.MinCost, bcrypt.MaxCost, e.Cost)
+}
+
+// ValidateBcryptCost возвращает *BcryptCostError, если cost вне диапазона bcrypt.MinCost..bcrypt.MaxCost
+func ValidateBcryptCost(cost int) error {
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		return &BcryptCostError{Cost: cost}
+	}
+	return nil
+}
+
+// NewRSAAuthService создает AuthService, подписывающий токены алгоритмом RS256 ключом privateKey.
+// Проверка токенов требует только открытого ключа, поэтому проверяющие сервисы не могут выпускать токены.
+func NewRSAAuthService(db UserStorage, privateKey *rsa.PrivateKey, tokenTTL time.Duration, bcryptCost int) *AuthService {
+	s := NewAuthService(db, "", tokenTTL, bcryptCost)
+	s.privateKey = privateKey
+	return s
+}
+
+// UseLoginLimiter включает временную блокировку входа по логину после серии неудачных попыток
+func (s *AuthService) UseLoginLimiter(limiter *LoginLimiter) {
+	s.limiter = limiter
+}
+
+// UseAvailability включает проверку токенов без обращения к базе данных, пока трекер availability
+// считает её недоступной: запрос доходит до деградированного режима, где чтения отдаются из кэша,
+// а записи сразу отклоняются с 503
+func (s *AuthService) UseAvailability(availability *Availability) {
+	s.availability = availability
+}
+
+// LoadRSAPrivateKey читает закрытый RSA-ключ в формате PEM (PKCS#1 или PKCS#8) из файла path
+func LoadRSAPrivateKey(path string) (*rsa.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private key: %w", err)
+	}
+	key, err := jwt.ParseRSAPrivateKeyFromPEM(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+	return key, nil
+}
+
+// signingMethod возвращает алгоритм подписи токенов, выбранный при создании сервиса
+func (s *AuthService) signingMethod() jwt.SigningMethod {
+	if s.privateKey != nil {
+		return jwt.SigningMethodRS256
+	}
+	return jwt.SigningMethodHS256
+}
+
+// signingKey возвращает ключ подписи токенов
+func (s *AuthService) signingKey() interface{} {
+	if s.privateKey != nil {
+		return s.privateKey
+	}
+	return []byte(s.secret)
+}
+
+// verificationKey возвращает ключ проверки подписи токенов
+func (s *AuthService) verificationKey() interface{} {
+	if s.privateKey != nil {
+		return &s.privateKey.PublicKey
+	}
+	return []byte(s.secret)
+}
+
+// Register регистрирует нового пользователя с хешированным паролем.
+// При включённой политике сложности слабый пароль отклоняется с *WeakPasswordError.
 func (s *AuthService) Register(ctx context.Context, input InputUserInfo) (db.User, error) {
 	if len(input.Password) < 8 || len(input.Password) > 72 {
 		return db.User{}, errors.New(ErrPasswordLength)
 	}
+	if s.passwordPolicy {
+		if err := checkPasswordStrength(input.Login, input.Password); err != nil {
+			return db.User{}, err
+		}
+	}
 
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
+	if err := ValidateBcryptCost(s.bcryptCost); err != nil {
+		return db.User{}, err
+	}
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(input.Password), s.bcryptCost)
 	if err != nil {
 		return db.User{}, err
 	}
 	return s.db.CreateUser(ctx, input.Login, string(hashedPassword))
 }
 
-// Authenticate проверяет логин и пароль, возвращает JWT-токен при успехе
-func (s *AuthService) Authenticate(ctx context.Context, input InputUserInfo) (string, error) {
+// Profile возвращает учётную запись пользователя userID
+func (s *AuthService) Profile(ctx context.Context, userID int) (Profile, error) {
+	user, err := s.db.UserByID(ctx, userID)
+	if err != nil {
+		return Profile{}, err
+	}
+	count, err := s.db.CountUserAds(ctx, userID)
+	if err != nil {
+		return Profile{}, err
+	}
+	return Profile{User: user, AdsCount: count}, nil
+}
+
+// Authenticate проверяет логин и пароль, возвращает JWT-токен доступа и refresh-токен при успехе.
+// Пока вход по логину заблокирован после серии неудач, возвращает *LoginLockedError без проверки пароля.
+func (s *AuthService) Authenticate(ctx context.Context, input InputUserInfo) (TokenPair, error) {
+	if s.limiter != nil {
+		if retryAfter := s.limiter.Locked(input.Login); retryAfter > 0 {
+			return TokenPair{}, &LoginLockedError{RetryAfter: retryAfter}
+		}
+	}
+
 	user, err := s.db.UserByLogin(ctx, input.Login)
 	if err != nil {
-		return "", err
+		if errors.Is(err, db.ErrLoginNotFound) {
+			s.loginFailed(input.Login)
+		}
+		return TokenPair{}, err
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(input.Password)); err != nil {
-		return "", err
+		s.loginFailed(input.Login)
+		return TokenPair{}, err
+	}
+	if s.limiter != nil {
+		s.limiter.Reset(input.Login)
+	}
+
+	refreshToken, refreshHash, err := newRefreshToken()
+	if err != nil {
+		return TokenPair{}, err
+	}
+	if err := s.db.CreateRefreshToken(ctx, user.ID, refreshHash, time.Now().Add(RefreshTokenTTL)); err != nil {
+		return TokenPair{}, err
+	}
+	return s.issueTokens(user, refreshToken)
+}
+
+// loginFailed учитывает неудачную попытку входа, если ограничитель попыток включён
+func (s *AuthService) loginFailed(login string) {
+	if s.limiter != nil {
+		s.limiter.Fail(login)
+	}
+}
+
+// Refresh обменивает refresh-токен на новую пару токенов; старый refresh-токен отзывается.
+// Возвращает db.ErrRefreshTokenNotFound, db.ErrRefreshTokenRevoked или db.ErrRefreshTokenExpired,
+// если токен нельзя обменять.
+func (s *AuthService) Refresh(ctx context.Context, refreshToken string) (TokenPair, error) {
+	newToken, newHash, err := newRefreshToken()
+	if err != nil {
+		return TokenPair{}, err
+	}
+	userID, err := s.db.RotateRefreshToken(ctx, hashRefreshToken(refreshToken), newHash, time.Now().Add(RefreshTokenTTL))
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	user, err := s.db.UserByID(ctx, userID)
+	if err != nil {
+		return TokenPair{}, err
+	}
+	return s.issueTokens(user, newToken)
+}
+
+// issueTokens подписывает токен доступа пользователя user и дополняет его refresh-токеном
+func (s *AuthService) issueTokens(user db.User, refreshToken string) (TokenPair, error) {
+	jti, err := newTokenID()
+	if err != nil {
+		return TokenPair{}, err
 	}
 
 	now := time.Now()
-	claims := jwt.MapClaims{
-		"user_id": float64(user.ID),
-		"iat":     now.Unix(),
-		"nbf":     now.Unix(),
-		"exp":     now.Add(24 * time.Hour).Unix(),
-		"iss":     Issuer,
-		"aud":     Audience,
+	claims := TokenClaims{
+		UserID: user.ID,
+		Role:   user.Role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.tokenTTL)),
+			Issuer:    Issuer,
+			Audience:  jwt.ClaimStrings{Audience},
+		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(s.secret))
+	token, err := jwt.NewWithClaims(s.signingMethod(), claims).SignedString(s.signingKey())
+	if err != nil {
+		return TokenPair{}, err
+	}
+	return TokenPair{Token: token, RefreshToken: refreshToken, ExpiresIn: int64(s.tokenTTL.Seconds()), UserID: user.ID}, nil
 }
 
-// ValidateToken проверяет корректность JWT-токена и возвращает user_id
-func (s *AuthService) ValidateToken(tokenString string) (int, error) {
-	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if token.Method != jwt.SigningMethodHS256 {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(s.secret), nil
-	}, jwt.WithValidMethods([]string{"HS256"}))
+// newRefreshToken создаёт случайный refresh-токен и его хеш для хранения в базе данных
+func newRefreshToken() (token, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token = base64.RawURLEncoding.EncodeToString(b)
+	return token, hashRefreshToken(token), nil
+}
+
+// newTokenID возвращает случайный идентификатор JWT-токена (jti)
+func newTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// hashRefreshToken возвращает SHA-256 хеш refresh-токена; сами токены в базе данных не хранятся
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
 
+// Logout отзывает токен доступа до истечения его срока, а также refresh-токен пользователя, если он передан.
+// Последующие запросы с отозванным токеном получают ошибку ErrTokenRevoked.
+func (s *AuthService) Logout(ctx context.Context, tokenString, refreshToken string) error {
+	claims, err := s.ParseToken(ctx, tokenString)
 	if err != nil {
-		return 0, err
+		return err
 	}
-	if !token.Valid {
-		return 0, errors.New(ErrInvalidToken)
+	if claims.ID == "" {
+		return errors.New(ErrInvalidTokenClaim)
 	}
 
-	claims, ok := token.Claims.(jwt.MapClaims)
-	if !ok {
-		return 0, errors.New(ErrInvalidTokenClaim)
+	if refreshToken != "" {
+		if err := s.db.RevokeUserRefreshToken(ctx, claims.UserID, hashRefreshToken(refreshToken)); err != nil {
+			return err
+		}
 	}
+	return s.db.RevokeToken(ctx, claims.ID, claims.ExpiresAt.Time)
+}
 
-	if err := validateRegisteredClaims(claims); err != nil {
-		return 0, err
+// Run удаляет истёкшие записи из списка отозванных токенов при запуске и затем каждые interval
+// до отмены контекста
+func (s *AuthService) Run(ctx context.Context, interval time.Duration, logger logging.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if deleted, err := s.db.DeleteExpiredRevocations(ctx, time.Now()); err != nil && ctx.Err() == nil {
+			logger.Error("Revocation cleanup failed", "error", err)
+		} else if err == nil {
+			logger.Debug("Revocation cleanup completed", "deleted", deleted)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
 	}
+}
+
+// SetRole назначает пользователю login роль role.
+// Новая роль попадает в токен при следующем входе пользователя или обновлении токена.
+func (s *AuthService) SetRole(ctx context.Context, login, role string) (db.User, error) {
+	return s.db.SetUserRole(ctx, login, role)
+}
 
-	userID, ok := claims["user_id"].(float64)
-	if !ok || userID <= 0 {
-		return 0, errors.New(ErrInvalidUserID)
+// DeleteAccount удаляет учётную запись userID после проверки текущего пароля
+func (s *AuthService) DeleteAccount(ctx context.Context, userID int, password string) error {
+	user, err := s.db.UserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	user, err = s.db.UserByLogin(ctx, user.Login)
+	if err != nil {
+		return err
 	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		return errors.New(ErrWrongPassword)
+	}
+	return s.db.DeleteUser(ctx, userID)
+}
 
-	return int(userID), nil
+// ValidateToken проверяет корректность JWT-токена, его отзыв и существование пользователя, возвращает user_id.
+// Если база данных недоступна, токен не принимается и возвращается ошибка базы данных;
+// если о её недоступности уже сообщил трекер из UseAvailability, отзыв и пользователь не проверяются.
+func (s *AuthService) ValidateToken(ctx context.Context, tokenString string) (int, error) {
+	claims, err := s.ParseToken(ctx, tokenString)
+	if err != nil {
+		return 0, err
+	}
+	return claims.UserID, nil
 }
 
-// validateRegisteredClaims выполняет валидацию стандартных полей токена
-func validateRegisteredClaims(claims jwt.MapClaims) error {
-	now := time.Now().Unix()
+// ParseToken проверяет JWT-токен так же, как ValidateToken, и возвращает его содержимое.
+// Подпись, срок действия, издатель и аудитория проверяются библиотекой jwt.
+func (s *AuthService) ParseToken(ctx context.Context, tokenString string) (TokenClaims, error) {
+	method := s.signingMethod()
+	var claims TokenClaims
+	token, err := jwt.ParseWithClaims(tokenString, &claims, func(token *jwt.Token) (interface{}, error) {
+		if token.Method != method {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return s.verificationKey(), nil
+	},
+		jwt.WithValidMethods([]string{method.Alg()}),
+		jwt.WithIssuer(Issuer),
+		jwt.WithAudience(Audience),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(TokenLeeway),
+	)
 
-	if exp, ok := claims["exp"].(float64); !ok || int64(exp) < now {
-		return errors.New(ErrTokenExpired)
+	if err != nil {
+		return TokenClaims{}, err
 	}
-	if nbf, ok := claims["nbf"].(float64); ok && int64(nbf) > now {
-		return errors.New(ErrTokenNotYetValid)
+	if !token.Valid {
+		return TokenClaims{}, errors.New(ErrInvalidToken)
 	}
-	if iat, ok := claims["iat"].(float64); ok && int64(iat) > now+60 {
-		return errors.New(ErrTokenIssuedFuture)
+
+	if claims.UserID <= 0 {
+		return TokenClaims{}, errors.New(ErrInvalidUserID)
 	}
-	if iss, ok := claims["iss"].(string); !ok || iss != Issuer {
-		return errors.New(ErrInvalidIssuer)
+	if claims.Role == "" {
+		claims.Role = db.RoleUser
 	}
-	if aud, ok := claims["aud"].(string); !ok || aud != Audience {
-		return errors.New(ErrInvalidAudience)
+
+	if s.availability != nil && !s.availability.Available() {
+		return claims, nil
 	}
-	return nil
+
+	if claims.ID != "" {
+		// при недоступной базе токен не принимается: иначе отозванный токен действовал бы до её восстановления
+		revoked, err := s.db.IsTokenRevoked(ctx, claims.ID)
+		if err != nil {
+			return TokenClaims{}, err
+		}
+		if revoked {
+			return TokenClaims{}, errors.New(ErrTokenRevoked)
+		}
+	}
+
+	if _, err := s.db.UserByID(ctx, claims.UserID); err != nil {
+		if errors.Is(err, db.ErrUserNotFound) {
+			return TokenClaims{}, errors.New(ErrUserDeleted)
+		}
+		return TokenClaims{}, err
+	}
+
+	return claims, nil
 }
@@ -2,8 +2,14 @@ package services
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -25,10 +31,19 @@ var (
 	secret     = "test-secret-key"
 )
 
+// testBcryptCost ускоряет хеширование паролей в тестах
+const testBcryptCost = bcrypt.MinCost
+
 func TestMain(m *testing.M) {
 	testCtx, cancelFunc = context.WithCancel(context.Background())
 	defer cancelFunc()
 
+	flag.Parse()
+	if testing.Short() {
+		// Без PostgreSQL: go test -short -run Unit запускает только тесты на dbtest.Store
+		os.Exit(m.Run())
+	}
+
 	var err error
 	postgresC, err = postgres.Run(testCtx,
 		"postgres:15-alpine",
@@ -68,15 +83,38 @@ func TestMain(m *testing.M) {
 
 func TestNewAuthService(t *testing.T) {
 	t.Run("create auth service successfully", func(t *testing.T) {
-		authService := NewAuthService(testDB, secret)
+		authService := NewAuthService(testDB, secret, DefaultTokenTTL, testBcryptCost)
 		assert.NotNil(t, authService)
 		assert.Equal(t, testDB, authService.db)
 		assert.Equal(t, secret, authService.secret)
+		assert.Equal(t, DefaultTokenTTL, authService.tokenTTL)
+	})
+
+	t.Run("non-positive ttl falls back to default", func(t *testing.T) {
+		assert.Equal(t, DefaultTokenTTL, NewAuthService(testDB, secret, 0, testBcryptCost).tokenTTL)
+		assert.Equal(t, DefaultTokenTTL, NewAuthService(testDB, secret, -time.Minute, testBcryptCost).tokenTTL)
+	})
+
+	t.Run("zero bcrypt cost falls back to default", func(t *testing.T) {
+		assert.Equal(t, DefaultBcryptCost, NewAuthService(testDB, secret, DefaultTokenTTL, 0).bcryptCost)
+	})
+
+	t.Run("out of range bcrypt cost is rejected", func(t *testing.T) {
+		assert.NoError(t, ValidateBcryptCost(bcrypt.MinCost))
+		assert.NoError(t, ValidateBcryptCost(bcrypt.MaxCost))
+
+		var costErr *BcryptCostError
+		require.ErrorAs(t, ValidateBcryptCost(bcrypt.MaxCost+1), &costErr)
+		assert.Equal(t, bcrypt.MaxCost+1, costErr.Cost)
+
+		authService := NewAuthService(testDB, secret, DefaultTokenTTL, bcrypt.MinCost-1)
+		_, err := authService.Register(testCtx, InputUserInfo{Login: "costuser", Password: "password123"})
+		assert.ErrorAs(t, err, &costErr)
 	})
 }
 
 func TestRegister(t *testing.T) {
-	authService := NewAuthService(testDB, secret)
+	authService := NewAuthService(testDB, secret, DefaultTokenTTL, testBcryptCost)
 
 	t.Run("register user successfully", func(t *testing.T) {
 		input := InputUserInfo{
@@ -119,7 +157,7 @@ func TestRegister(t *testing.T) {
 }
 
 func TestAuthenticate(t *testing.T) {
-	authService := NewAuthService(testDB, secret)
+	authService := NewAuthService(testDB, secret, DefaultTokenTTL, testBcryptCost)
 
 	// Подготовка: регистрируем пользователя
 	input := InputUserInfo{
@@ -130,22 +168,23 @@ func TestAuthenticate(t *testing.T) {
 	require.NoError(t, err)
 
 	t.Run("authenticate user successfully", func(t *testing.T) {
-		token, err := authService.Authenticate(testCtx, input)
+		tokens, err := authService.Authenticate(testCtx, input)
 		require.NoError(t, err)
-		assert.NotEmpty(t, token)
+		assert.NotEmpty(t, tokens.Token)
+		assert.NotEmpty(t, tokens.RefreshToken)
+		assert.Equal(t, int64(DefaultTokenTTL.Seconds()), tokens.ExpiresIn)
 
 		// Проверяем валидность токена
-		parsedToken, err := jwt.Parse(token, func(token *jwt.Token) (interface{}, error) {
+		var claims TokenClaims
+		parsedToken, err := jwt.ParseWithClaims(tokens.Token, &claims, func(token *jwt.Token) (interface{}, error) {
 			return []byte(secret), nil
 		})
 		require.NoError(t, err)
 		assert.True(t, parsedToken.Valid)
 
-		claims, ok := parsedToken.Claims.(jwt.MapClaims)
-		require.True(t, ok)
-		assert.Equal(t, "auth-services", claims["iss"])
-		assert.Equal(t, "marketgo-api", claims["aud"])
-		assert.Greater(t, claims["exp"], claims["iat"])
+		assert.Equal(t, "auth-services", claims.Issuer)
+		assert.Equal(t, jwt.ClaimStrings{"marketgo-api"}, claims.Audience)
+		assert.True(t, claims.ExpiresAt.After(claims.IssuedAt.Time))
 	})
 
 	t.Run("invalid login returns error", func(t *testing.T) {
@@ -163,12 +202,125 @@ func TestAuthenticate(t *testing.T) {
 			Password: "wrongpassword",
 		}
 		_, err := authService.Authenticate(testCtx, input)
+		assert.ErrorIs(t, err, bcrypt.ErrMismatchedHashAndPassword)
+	})
+
+	t.Run("stored hash matches registered password", func(t *testing.T) {
+		user, err := testDB.UserByLogin(testCtx, input.Login)
+		require.NoError(t, err)
+		require.NotEmpty(t, user.Password)
+		assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(input.Password)))
+	})
+}
+
+func TestProfile(t *testing.T) {
+	authService := NewAuthService(testDB, secret, DefaultTokenTTL, testBcryptCost)
+
+	err := clearTables(testCtx, testDB)
+	require.NoError(t, err)
+
+	user, err := authService.Register(testCtx, InputUserInfo{Login: "profileuser", Password: "password123"})
+	require.NoError(t, err)
+	_, err = testDB.CreateAd(testCtx, db.Ad{Title: "Ad", Text: "text", Price: 100, UserID: user.ID})
+	require.NoError(t, err)
+
+	t.Run("returns user with ads count", func(t *testing.T) {
+		profile, err := authService.Profile(testCtx, user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, user.ID, profile.ID)
+		assert.Equal(t, "profileuser", profile.Login)
+		assert.Equal(t, 1, profile.AdsCount)
+		assert.Empty(t, profile.Password)
+	})
+
+	t.Run("unknown user returns error", func(t *testing.T) {
+		_, err := authService.Profile(testCtx, 999999)
+		assert.ErrorIs(t, err, db.ErrUserNotFound)
+	})
+}
+
+func TestTokenTTL(t *testing.T) {
+	authService := NewAuthService(testDB, secret, time.Second, testBcryptCost)
+
+	input := InputUserInfo{Login: "shortlived", Password: "password123"}
+	user, err := authService.Register(testCtx, input)
+	require.NoError(t, err)
+	tokens, err := authService.Authenticate(testCtx, input)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), tokens.ExpiresIn)
+
+	userID, err := authService.ValidateToken(testCtx, tokens.Token)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, userID)
+
+	time.Sleep(2 * time.Second)
+	_, err = authService.ValidateToken(testCtx, tokens.Token)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "token is expired")
+}
+
+func TestRSAAuthService(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	keyFile := filepath.Join(t.TempDir(), "jwt.pem")
+	pemBytes := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	require.NoError(t, os.WriteFile(keyFile, pemBytes, 0o600))
+
+	loaded, err := LoadRSAPrivateKey(keyFile)
+	require.NoError(t, err)
+	authService := NewRSAAuthService(testDB, loaded, DefaultTokenTTL, testBcryptCost)
+	hmacService := NewAuthService(testDB, secret, DefaultTokenTTL, testBcryptCost)
+
+	input := InputUserInfo{Login: "rsauser", Password: "password123"}
+	user, err := authService.Register(testCtx, input)
+	require.NoError(t, err)
+	tokens, err := authService.Authenticate(testCtx, input)
+	require.NoError(t, err)
+
+	t.Run("token is signed with RS256", func(t *testing.T) {
+		parsed, err := jwt.Parse(tokens.Token, func(*jwt.Token) (interface{}, error) {
+			return &key.PublicKey, nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "RS256", parsed.Header["alg"])
+
+		userID, err := authService.ValidateToken(testCtx, tokens.Token)
+		require.NoError(t, err)
+		assert.Equal(t, user.ID, userID)
+	})
+
+	t.Run("HS256 service rejects RS256 token", func(t *testing.T) {
+		_, err := hmacService.ValidateToken(testCtx, tokens.Token)
+		assert.Error(t, err)
+	})
+
+	t.Run("RS256 service rejects HS256 tokens", func(t *testing.T) {
+		hmacTokens, err := hmacService.Authenticate(testCtx, input)
+		require.NoError(t, err)
+		_, err = authService.ValidateToken(testCtx, hmacTokens.Token)
+		assert.Error(t, err)
+
+		// Подпись открытым ключом как HMAC-секретом не должна приниматься
+		publicPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PUBLIC KEY", Bytes: x509.MarshalPKCS1PublicKey(&key.PublicKey)})
+		forged, err := jwt.NewWithClaims(jwt.SigningMethodHS256, testClaims(user.ID, time.Now())).SignedString(publicPEM)
+		require.NoError(t, err)
+		_, err = authService.ValidateToken(testCtx, forged)
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid key file returns error", func(t *testing.T) {
+		_, err := LoadRSAPrivateKey(filepath.Join(t.TempDir(), "missing.pem"))
+		assert.Error(t, err)
+
+		garbage := filepath.Join(t.TempDir(), "garbage.pem")
+		require.NoError(t, os.WriteFile(garbage, []byte("not a key"), 0o600))
+		_, err = LoadRSAPrivateKey(garbage)
 		assert.Error(t, err)
 	})
 }
 
 func TestValidateToken(t *testing.T) {
-	authService := NewAuthService(testDB, secret)
+	authService := NewAuthService(testDB, secret, DefaultTokenTTL, testBcryptCost)
 
 	// Подготовка: регистрируем пользователя и получаем токен
 	input := InputUserInfo{
@@ -177,73 +329,269 @@ func TestValidateToken(t *testing.T) {
 	}
 	user, err := authService.Register(testCtx, input)
 	require.NoError(t, err)
-	token, err := authService.Authenticate(testCtx, input)
+	tokens, err := authService.Authenticate(testCtx, input)
 	require.NoError(t, err)
+	token := tokens.Token
 
 	t.Run("validate token successfully", func(t *testing.T) {
-		userID, err := authService.ValidateToken(token)
+		userID, err := authService.ValidateToken(testCtx, token)
 		require.NoError(t, err)
 		assert.Equal(t, user.ID, userID)
 	})
 
 	t.Run("invalid token returns error", func(t *testing.T) {
-		_, err := authService.ValidateToken("invalid.token.string")
+		_, err := authService.ValidateToken(testCtx, "invalid.token.string")
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "token is malformed")
 	})
 
 	t.Run("expired token returns error", func(t *testing.T) {
-		now := time.Now()
-		claims := jwt.MapClaims{
-			"user_id": float64(user.ID),
-			"iat":     now.Add(-48 * time.Hour).Unix(),
-			"nbf":     now.Add(-48 * time.Hour).Unix(),
-			"exp":     now.Add(-24 * time.Hour).Unix(),
-			"iss":     "auth-services",
-			"aud":     "marketgo-api",
-		}
-		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-		tokenString, err := token.SignedString([]byte(secret))
-		require.NoError(t, err)
+		claims := testClaims(user.ID, time.Now().Add(-48*time.Hour))
+		claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-24 * time.Hour))
 
-		_, err = authService.ValidateToken(tokenString)
+		_, err := authService.ValidateToken(testCtx, signTestClaims(t, claims))
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "token is expired")
 	})
 
+	t.Run("token without exp returns error", func(t *testing.T) {
+		claims := testClaims(user.ID, time.Now())
+		claims.ExpiresAt = nil
+
+		_, err := authService.ValidateToken(testCtx, signTestClaims(t, claims))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "token is missing required claim")
+	})
+
+	t.Run("token issued in the future returns error", func(t *testing.T) {
+		claims := testClaims(user.ID, time.Now())
+		claims.IssuedAt = jwt.NewNumericDate(time.Now().Add(time.Hour))
+
+		_, err := authService.ValidateToken(testCtx, signTestClaims(t, claims))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "token used before issued")
+	})
+
 	t.Run("invalid issuer returns error", func(t *testing.T) {
-		claims := jwt.MapClaims{
-			"user_id": float64(user.ID),
-			"iat":     time.Now().Unix(),
-			"nbf":     time.Now().Unix(),
-			"exp":     time.Now().Add(24 * time.Hour).Unix(),
-			"iss":     "wrong-issuer",
-			"aud":     "marketgo-api",
-		}
-		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-		tokenString, err := token.SignedString([]byte(secret))
-		require.NoError(t, err)
+		claims := testClaims(user.ID, time.Now())
+		claims.Issuer = "wrong-issuer"
 
-		_, err = authService.ValidateToken(tokenString)
+		_, err := authService.ValidateToken(testCtx, signTestClaims(t, claims))
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "invalid issuer")
 	})
 
+	t.Run("invalid audience returns error", func(t *testing.T) {
+		claims := testClaims(user.ID, time.Now())
+		claims.Audience = jwt.ClaimStrings{"other-api"}
+
+		_, err := authService.ValidateToken(testCtx, signTestClaims(t, claims))
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid audience")
+	})
+
 	t.Run("invalid user_id returns error", func(t *testing.T) {
-		claims := jwt.MapClaims{
-			"user_id": -1.0, // отрицательный user_id
-			"iat":     time.Now().Unix(),
-			"nbf":     time.Now().Unix(),
-			"exp":     time.Now().Add(24 * time.Hour).Unix(),
-			"iss":     "auth-services",
-			"aud":     "marketgo-api",
-		}
-		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-		tokenString, err := token.SignedString([]byte(secret))
-		require.NoError(t, err)
+		claims := testClaims(-1, time.Now()) // отрицательный user_id
 
-		_, err = authService.ValidateToken(tokenString)
+		_, err := authService.ValidateToken(testCtx, signTestClaims(t, claims))
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "invalid user_id claim")
 	})
+
+	t.Run("token of deleted user returns error", func(t *testing.T) {
+		require.NoError(t, testDB.DeleteUser(testCtx, user.ID))
+		_, err := authService.ValidateToken(testCtx, token)
+		assert.EqualError(t, err, ErrUserDeleted)
+	})
+}
+
+func TestTokenRole(t *testing.T) {
+	authService := NewAuthService(testDB, secret, DefaultTokenTTL, testBcryptCost)
+
+	err := clearTables(testCtx, testDB)
+	require.NoError(t, err)
+
+	input := InputUserInfo{Login: "roleholder", Password: "password123"}
+	user, err := authService.Register(testCtx, input)
+	require.NoError(t, err)
+
+	t.Run("regular user token carries user role", func(t *testing.T) {
+		tokens, err := authService.Authenticate(testCtx, input)
+		require.NoError(t, err)
+		claims, err := authService.ParseToken(testCtx, tokens.Token)
+		require.NoError(t, err)
+		assert.Equal(t, user.ID, claims.UserID)
+		assert.Equal(t, db.RoleUser, claims.Role)
+	})
+
+	t.Run("promoted user gets admin role on next login", func(t *testing.T) {
+		_, err := authService.SetRole(testCtx, input.Login, db.RoleAdmin)
+		require.NoError(t, err)
+
+		tokens, err := authService.Authenticate(testCtx, input)
+		require.NoError(t, err)
+		claims, err := authService.ParseToken(testCtx, tokens.Token)
+		require.NoError(t, err)
+		assert.Equal(t, db.RoleAdmin, claims.Role)
+	})
+
+	t.Run("token without role claim is a user token", func(t *testing.T) {
+		parsed, err := authService.ParseToken(testCtx, signTestClaims(t, testClaims(user.ID, time.Now())))
+		require.NoError(t, err)
+		assert.Equal(t, db.RoleUser, parsed.Role)
+	})
+}
+
+func TestRefresh(t *testing.T) {
+	authService := NewAuthService(testDB, secret, DefaultTokenTTL, testBcryptCost)
+
+	err := clearTables(testCtx, testDB)
+	require.NoError(t, err)
+
+	input := InputUserInfo{Login: "refresher", Password: "password123"}
+	user, err := authService.Register(testCtx, input)
+	require.NoError(t, err)
+	tokens, err := authService.Authenticate(testCtx, input)
+	require.NoError(t, err)
+
+	var rotated TokenPair
+	t.Run("refresh returns a new pair", func(t *testing.T) {
+		rotated, err = authService.Refresh(testCtx, tokens.RefreshToken)
+		require.NoError(t, err)
+		assert.NotEqual(t, tokens.RefreshToken, rotated.RefreshToken)
+
+		userID, err := authService.ValidateToken(testCtx, rotated.Token)
+		require.NoError(t, err)
+		assert.Equal(t, user.ID, userID)
+	})
+
+	t.Run("rotated refresh token is revoked", func(t *testing.T) {
+		_, err := authService.Refresh(testCtx, tokens.RefreshToken)
+		assert.ErrorIs(t, err, db.ErrRefreshTokenRevoked)
+	})
+
+	t.Run("expired refresh token is rejected", func(t *testing.T) {
+		err := testDB.Exec(testCtx, "UPDATE refresh_tokens SET expires_at = $1 WHERE token_hash = $2",
+			time.Now().UTC().Add(-time.Minute), hashRefreshToken(rotated.RefreshToken))
+		require.NoError(t, err)
+
+		_, err = authService.Refresh(testCtx, rotated.RefreshToken)
+		assert.ErrorIs(t, err, db.ErrRefreshTokenExpired)
+	})
+
+	t.Run("unknown refresh token is rejected", func(t *testing.T) {
+		_, err := authService.Refresh(testCtx, "unknown")
+		assert.ErrorIs(t, err, db.ErrRefreshTokenNotFound)
+	})
+}
+
+func TestLogout(t *testing.T) {
+	authService := NewAuthService(testDB, secret, DefaultTokenTTL, testBcryptCost)
+
+	err := clearTables(testCtx, testDB)
+	require.NoError(t, err)
+
+	input := InputUserInfo{Login: "leaver", Password: "password123"}
+	user, err := authService.Register(testCtx, input)
+	require.NoError(t, err)
+	tokens, err := authService.Authenticate(testCtx, input)
+	require.NoError(t, err)
+	other, err := authService.Authenticate(testCtx, input)
+	require.NoError(t, err)
+
+	claims, err := authService.ParseToken(testCtx, tokens.Token)
+	require.NoError(t, err)
+	assert.NotEmpty(t, claims.ID)
+	assert.NotEqual(t, claims.ID, mustParse(t, authService, other.Token).ID, "every token gets its own jti")
+
+	t.Run("logout revokes the token", func(t *testing.T) {
+		require.NoError(t, authService.Logout(testCtx, tokens.Token, tokens.RefreshToken))
+
+		_, err := authService.ValidateToken(testCtx, tokens.Token)
+		assert.EqualError(t, err, ErrTokenRevoked)
+	})
+
+	t.Run("logout revokes the refresh token", func(t *testing.T) {
+		_, err := authService.Refresh(testCtx, tokens.RefreshToken)
+		assert.ErrorIs(t, err, db.ErrRefreshTokenRevoked)
+	})
+
+	t.Run("other sessions stay valid", func(t *testing.T) {
+		userID, err := authService.ValidateToken(testCtx, other.Token)
+		require.NoError(t, err)
+		assert.Equal(t, user.ID, userID)
+	})
+
+	t.Run("revoked token cannot log out again", func(t *testing.T) {
+		err := authService.Logout(testCtx, tokens.Token, "")
+		assert.EqualError(t, err, ErrTokenRevoked)
+	})
+
+	t.Run("expired revocations are cleaned up", func(t *testing.T) {
+		deleted, err := testDB.DeleteExpiredRevocations(testCtx, claims.ExpiresAt.Add(time.Second))
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), deleted)
+	})
+}
+
+// testClaims возвращает содержимое токена без роли и jti, выданного в момент issuedAt на сутки
+func testClaims(userID int, issuedAt time.Time) TokenClaims {
+	return TokenClaims{
+		UserID: userID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			IssuedAt:  jwt.NewNumericDate(issuedAt),
+			NotBefore: jwt.NewNumericDate(issuedAt),
+			ExpiresAt: jwt.NewNumericDate(issuedAt.Add(24 * time.Hour)),
+			Issuer:    Issuer,
+			Audience:  jwt.ClaimStrings{Audience},
+		},
+	}
+}
+
+func signTestClaims(t *testing.T, claims TokenClaims) string {
+	t.Helper()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	require.NoError(t, err)
+	return token
+}
+
+func mustParse(t *testing.T, authService *AuthService, token string) TokenClaims {
+	t.Helper()
+	claims, err := authService.ParseToken(testCtx, token)
+	require.NoError(t, err)
+	return claims
+}
+
+func TestDeleteAccount(t *testing.T) {
+	authService := NewAuthService(testDB, secret, DefaultTokenTTL, testBcryptCost)
+
+	err := clearTables(testCtx, testDB)
+	require.NoError(t, err)
+
+	input := InputUserInfo{Login: "deleteme", Password: "password123"}
+	user, err := authService.Register(testCtx, input)
+	require.NoError(t, err)
+	ad, err := testDB.CreateAd(testCtx, db.Ad{Title: "Ad", Text: "text", Price: 100, UserID: user.ID})
+	require.NoError(t, err)
+
+	t.Run("wrong password keeps the account", func(t *testing.T) {
+		err := authService.DeleteAccount(testCtx, user.ID, "wrongpassword")
+		assert.EqualError(t, err, ErrWrongPassword)
+		_, err = testDB.UserByID(testCtx, user.ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("deletes the account with its ads", func(t *testing.T) {
+		require.NoError(t, authService.DeleteAccount(testCtx, user.ID, input.Password))
+
+		_, err := testDB.UserByID(testCtx, user.ID)
+		assert.ErrorIs(t, err, db.ErrUserNotFound)
+		_, err = testDB.Ad(testCtx, ad.ID, 0)
+		assert.ErrorIs(t, err, db.ErrAdNotFound)
+	})
+
+	t.Run("deleting again returns not found", func(t *testing.T) {
+		err := authService.DeleteAccount(testCtx, user.ID, input.Password)
+		assert.ErrorIs(t, err, db.ErrUserNotFound)
+	})
 }
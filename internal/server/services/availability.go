@@ -0,0 +1,116 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/YuarenArt/marketgo/internal/db"
+	"github.com/YuarenArt/marketgo/pkg/logging"
+)
+
+const (
+	// AvailabilityCheckInterval - период проверки доступности базы данных
+	AvailabilityCheckInterval = 2 * time.Second
+	// AvailabilityFailThreshold - сколько ошибок подряд переводят базу в недоступные
+	AvailabilityFailThreshold = 3
+	// AvailabilityRecoverThreshold - сколько успешных проверок подряд возвращают базу в доступные
+	AvailabilityRecoverThreshold = 3
+
+	availabilityPingTimeout = time.Second
+)
+
+// Availability отслеживает доступность базы данных по результатам запросов и периодическим проверкам.
+// Состояние меняется с гистерезисом: база считается недоступной после AvailabilityFailThreshold
+// ошибок соединения подряд и доступной снова после AvailabilityRecoverThreshold успешных проверок подряд.
+type Availability struct {
+	ping             func(context.Context) error
+	failThreshold    int
+	recoverThreshold int
+
+	mu        sync.Mutex
+	down      bool
+	failures  int
+	successes int
+}
+
+// NewAvailability создает трекер доступности; ping проверяет соединение с базой данных
+func NewAvailability(ping func(context.Context) error) *Availability {
+	return &Availability{
+		ping:             ping,
+		failThreshold:    AvailabilityFailThreshold,
+		recoverThreshold: AvailabilityRecoverThreshold,
+	}
+}
+
+// Available сообщает, считается ли база данных доступной
+func (a *Availability) Available() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return !a.down
+}
+
+// Observe учитывает результат запроса к базе данных.
+// Ошибки, не связанные с недоступностью базы (например, нарушение ограничений), игнорируются.
+func (a *Availability) Observe(err error) {
+	if err != nil && !db.IsUnavailable(err) {
+		return
+	}
+	a.record(err == nil)
+}
+
+// Check проверяет соединение с базой данных и учитывает результат
+func (a *Availability) Check(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, availabilityPingTimeout)
+	defer cancel()
+	a.record(a.ping(ctx) == nil)
+}
+
+func (a *Availability) record(ok bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if ok {
+		a.failures = 0
+		if a.down {
+			a.successes++
+			if a.successes >= a.recoverThreshold {
+				a.down, a.successes = false, 0
+			}
+		}
+		return
+	}
+
+	a.successes = 0
+	if !a.down {
+		a.failures++
+		if a.failures >= a.failThreshold {
+			a.down, a.failures = true, 0
+		}
+	}
+}
+
+// Run периодически проверяет доступность базы данных до отмены ctx
+func (a *Availability) Run(ctx context.Context, interval time.Duration, logger logging.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	available := a.Available()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		a.Check(ctx)
+		if now := a.Available(); now != available && ctx.Err() == nil {
+			available = now
+			if available {
+				logger.Info("Database is available again")
+			} else {
+				logger.Error("Database is unavailable, serving in degraded mode")
+			}
+		}
+	}
+}
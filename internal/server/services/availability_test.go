@@ -0,0 +1,113 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/YuarenArt/marketgo/internal/db"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+func TestAvailabilityHysteresis(t *testing.T) {
+	var pingErr error
+	a := NewAvailability(func(context.Context) error { return pingErr })
+
+	t.Run("query errors unrelated to connectivity are ignored", func(t *testing.T) {
+		for i := 0; i < AvailabilityFailThreshold*2; i++ {
+			a.Observe(&pgconn.PgError{Code: "23505"})
+			a.Observe(context.Canceled)
+		}
+		assert.True(t, a.Available())
+	})
+
+	t.Run("success resets the failure streak", func(t *testing.T) {
+		pingErr = errors.New("connection refused")
+		for i := 0; i < AvailabilityFailThreshold-1; i++ {
+			a.Check(testCtx)
+		}
+		a.Observe(nil)
+		a.Check(testCtx)
+		assert.True(t, a.Available())
+	})
+
+	t.Run("goes down after consecutive failures", func(t *testing.T) {
+		for i := 0; i < AvailabilityFailThreshold; i++ {
+			a.Check(testCtx)
+		}
+		assert.False(t, a.Available())
+	})
+
+	t.Run("does not flap on a single success", func(t *testing.T) {
+		pingErr = nil
+		a.Check(testCtx)
+		pingErr = errors.New("connection refused")
+		a.Check(testCtx)
+		pingErr = nil
+		for i := 0; i < AvailabilityRecoverThreshold-1; i++ {
+			a.Check(testCtx)
+		}
+		assert.False(t, a.Available())
+
+		a.Check(testCtx)
+		assert.True(t, a.Available())
+	})
+}
+
+func TestAvailabilityDatabaseOutage(t *testing.T) {
+	container, err := postgres.Run(testCtx,
+		"postgres:15-alpine",
+		postgres.WithDatabase("outagedb"),
+		postgres.WithUsername("testuser"),
+		postgres.WithPassword("testpass"),
+		testcontainers.WithWaitStrategy(
+			wait.ForLog("database system is ready to accept connections").
+				WithOccurrence(2).
+				WithStartupTimeout(10*time.Second),
+		),
+	)
+	require.NoError(t, err)
+	defer func() { _ = container.Terminate(testCtx) }()
+
+	dsn, err := container.ConnectionString(testCtx, "sslmode=disable")
+	require.NoError(t, err)
+
+	var outageDB *db.DBService
+	a := NewAvailability(func(ctx context.Context) error { return outageDB.Ping(ctx) })
+	outageDB, err = db.NewDBService(testCtx, dsn, db.WithErrorObserver(a.Observe))
+	require.NoError(t, err)
+	defer outageDB.Close()
+
+	_, err = outageDB.CountAds(testCtx, db.AdsFilter{MaxPrice: DefaultMaxPrice})
+	require.NoError(t, err)
+	assert.True(t, a.Available())
+
+	require.NoError(t, container.Stop(testCtx, nil))
+
+	t.Run("outage is detected", func(t *testing.T) {
+		for i := 0; i < AvailabilityFailThreshold; i++ {
+			_, err := outageDB.CountAds(testCtx, db.AdsFilter{MaxPrice: DefaultMaxPrice})
+			require.Error(t, err)
+			assert.True(t, db.IsUnavailable(err), "unexpected error: %v", err)
+			assert.ErrorIs(t, err, db.ErrUnavailable)
+			a.Check(testCtx)
+		}
+		assert.False(t, a.Available())
+	})
+
+	t.Run("recovers after consecutive successful checks", func(t *testing.T) {
+		a.ping = testDB.Ping
+		for i := 0; i < AvailabilityRecoverThreshold-1; i++ {
+			a.Check(testCtx)
+			assert.False(t, a.Available())
+		}
+		a.Check(testCtx)
+		assert.True(t, a.Available())
+	})
+}
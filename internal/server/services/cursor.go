@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+
+	"github.com/YuarenArt/marketgo/internal/db"
+)
+
+const (
+	ErrInvalidCursor = "invalid cursor"
+	ErrSearchCursor  = "cursor pagination is not supported with q"
+)
+
+// AdsPage - страница ленты объявлений при курсорной пагинации
+type AdsPage struct {
+	Ads []db.Ad `json:"ads"`
+	// NextCursor передаётся в параметре cursor для получения следующей страницы; пустой на последней странице
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// GetAdsAfterCursor возвращает страницу объявлений, следующую за req.Cursor.
+// Пустой курсор означает первую страницу. Сортировка должна совпадать с той, для которой выдан курсор.
+func (s *AdService) GetAdsAfterCursor(ctx context.Context, req GetAdsRequest, userID int) (AdsPage, error) {
+	if req.Query != "" {
+		return AdsPage{}, errors.New(ErrSearchCursor)
+	}
+	filter := applyAdsDefaults(&req)
+
+	var after *db.AdsCursor
+	if req.Cursor != "" {
+		cursor, err := decodeCursor(req.Cursor)
+		if err != nil {
+			return AdsPage{}, err
+		}
+		after = &cursor
+	}
+
+	ads, next, err := s.db.AdsAfterCursor(ctx, userID, req.PageSize, req.SortBy, req.SortOrder, filter, after)
+	if err != nil {
+		return AdsPage{}, err
+	}
+	ads, err = s.translateAds(ctx, ads, req.Languages)
+	if err != nil {
+		return AdsPage{}, err
+	}
+
+	page := AdsPage{Ads: ads}
+	if page.Ads == nil {
+		page.Ads = []db.Ad{}
+	}
+	if next != nil {
+		if page.NextCursor, err = encodeCursor(*next); err != nil {
+			return AdsPage{}, err
+		}
+	}
+	return page, nil
+}
+
+func encodeCursor(cursor db.AdsCursor) (string, error) {
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeCursor(token string) (db.AdsCursor, error) {
+	var cursor db.AdsCursor
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return cursor, errors.New(ErrInvalidCursor)
+	}
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID <= 0 {
+		return cursor, errors.New(ErrInvalidCursor)
+	}
+	return cursor, nil
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/YuarenArt/marketgo/internal/db"
+	"github.com/YuarenArt/marketgo/pkg/logging"
+	"github.com/YuarenArt/marketgo/pkg/webhook"
+)
+
+const (
+	DefaultAdTTL     = db.DefaultAdTTL
+	AdExpiryInterval = time.Minute
+)
+
+// UseAdTTL задаёт срок публикации новых и продлённых объявлений; неположительное значение игнорируется
+func (s *AdService) UseAdTTL(ttl time.Duration) {
+	if ttl > 0 {
+		s.adTTL = ttl
+	}
+}
+
+// RenewAd продлевает публикацию объявления adID, принадлежащего userID, на срок публикации от текущего момента.
+// Архивное объявление снова становится активным.
+func (s *AdService) RenewAd(ctx context.Context, adID, userID int) (db.Ad, error) {
+	ad, err := s.db.RenewAd(ctx, adID, userID, time.Now().Add(s.adTTL))
+	if err != nil {
+		return db.Ad{}, err
+	}
+	s.invalidateAds()
+	_ = s.search.IndexAd(ctx, ad)
+	s.publishAd(webhook.EventAdUpdated, ad)
+	return ad, nil
+}
+
+// ExpireAds архивирует объявления с истёкшим сроком публикации и возвращает их количество
+func (s *AdService) ExpireAds(ctx context.Context) (int, error) {
+	ads, err := s.db.ExpireAds(ctx)
+	if err != nil || len(ads) == 0 {
+		return 0, err
+	}
+	s.invalidateAds()
+	for _, ad := range ads {
+		_ = s.search.IndexAd(ctx, ad)
+		s.publishAd(webhook.EventAdUpdated, ad)
+	}
+	return len(ads), nil
+}
+
+// RunExpiry архивирует истёкшие объявления при запуске и затем каждые interval до отмены контекста.
+// observe получает количество архивированных объявлений; может быть nil.
+func (s *AdService) RunExpiry(ctx context.Context, interval time.Duration, logger logging.Logger, observe func(expired int)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if expired, err := s.ExpireAds(ctx); err != nil && ctx.Err() == nil {
+			logger.Error("Ad expiry failed", "error", err)
+		} else if err == nil && expired > 0 {
+			logger.Info("Ads expired", "count", expired)
+			if observe != nil {
+				observe(expired)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
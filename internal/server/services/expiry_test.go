@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/YuarenArt/marketgo/internal/db"
+	"github.com/YuarenArt/marketgo/internal/db/dbtest"
+	"github.com/YuarenArt/marketgo/pkg/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnitAdExpiry(t *testing.T) {
+	ctx := context.Background()
+	store := dbtest.NewStore()
+	adService := NewAdService(store)
+	adService.UseAdTTL(50 * time.Millisecond)
+
+	seller, err := store.CreateUser(ctx, "expiryseller", "hash")
+	require.NoError(t, err)
+
+	listed := func(userID int) []int {
+		ads, err := adService.GetAds(ctx, GetAdsRequest{Page: 1, PageSize: 10}, userID)
+		require.NoError(t, err)
+		ids := make([]int, 0, len(ads))
+		for _, ad := range ads {
+			ids = append(ids, ad.ID)
+		}
+		return ids
+	}
+
+	bike, err := adService.CreateAd(ctx, CreateAdRequest{Title: "Велосипед", Text: "Горный", ImageURL: "http://example.com/1.jpg", Price: 300}, seller.ID)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(50*time.Millisecond), bike.ExpiresAt, time.Second)
+	assert.Equal(t, []int{bike.ID}, listed(0))
+
+	t.Run("expired ad is hidden before the worker archives it", func(t *testing.T) {
+		time.Sleep(60 * time.Millisecond)
+		assert.Empty(t, listed(0))
+
+		mine, err := adService.GetMyAds(ctx, GetAdsRequest{Page: 1, PageSize: 10}, seller.ID)
+		require.NoError(t, err)
+		require.Len(t, mine, 1, "owner still sees the expired ad")
+		assert.Equal(t, db.AdStatusActive, mine[0].Status)
+	})
+
+	t.Run("worker archives expired ads until cancelled", func(t *testing.T) {
+		runCtx, cancel := context.WithCancel(ctx)
+		var observed atomic.Int64
+		done := make(chan struct{})
+		go func() {
+			adService.RunExpiry(runCtx, 5*time.Millisecond, logging.NewLogger(nil), func(n int) { observed.Add(int64(n)) })
+			close(done)
+		}()
+
+		require.Eventually(t, func() bool { return observed.Load() == 1 }, time.Second, 5*time.Millisecond)
+		ad, err := adService.GetAd(ctx, bike.ID, seller.ID)
+		require.NoError(t, err)
+		assert.Equal(t, db.AdStatusArchived, ad.Status)
+
+		cancel()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("RunExpiry did not stop after cancellation")
+		}
+		assert.EqualValues(t, 1, observed.Load(), "archived ads are counted once")
+	})
+
+	t.Run("renew reactivates and pushes the expiry forward", func(t *testing.T) {
+		adService.UseAdTTL(time.Hour)
+		ad, err := adService.RenewAd(ctx, bike.ID, seller.ID)
+		require.NoError(t, err)
+		assert.Equal(t, db.AdStatusActive, ad.Status)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), ad.ExpiresAt, time.Second)
+		assert.Equal(t, []int{bike.ID}, listed(0))
+
+		expired, err := adService.ExpireAds(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, expired)
+	})
+
+	t.Run("renew errors", func(t *testing.T) {
+		buyer, err := store.CreateUser(ctx, "expirybuyer", "hash")
+		require.NoError(t, err)
+		_, err = adService.RenewAd(ctx, bike.ID, buyer.ID)
+		assert.ErrorIs(t, err, db.ErrNotAdOwner)
+
+		_, err = adService.RenewAd(ctx, 999, seller.ID)
+		assert.ErrorIs(t, err, db.ErrAdNotFound)
+
+		_, err = adService.SetAdStatus(ctx, bike.ID, UpdateAdStatusRequest{Status: db.AdStatusSold}, seller.ID)
+		require.NoError(t, err)
+		_, err = adService.RenewAd(ctx, bike.ID, seller.ID)
+		assert.ErrorIs(t, err, db.ErrAdNotRenewable)
+	})
+}
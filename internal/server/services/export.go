@@ -0,0 +1,32 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/YuarenArt/marketgo/internal/db"
+)
+
+const (
+	// DefaultExportMaxRows - максимальное количество объявлений в одной выгрузке по умолчанию
+	DefaultExportMaxRows = 500_000
+
+	ErrExportTooLarge = "export exceeds the row limit, narrow the filters"
+)
+
+// ExportAds передаёт write все объявления, подходящие под фильтры req, в порядке его сортировки;
+// страница и поисковый запрос req не учитываются. Объявления читаются из базы по одному,
+// поэтому выгрузка не собирается в памяти. Если подходящих объявлений больше maxRows,
+// возвращается ErrExportTooLarge до первого вызова write.
+func (s *AdService) ExportAds(ctx context.Context, req GetAdsRequest, userID, maxRows int, write func(db.Ad) error) error {
+	filter := applyAdsDefaults(&req)
+	total, err := s.db.CountAds(ctx, filter)
+	if err != nil {
+		return err
+	}
+	if total > maxRows {
+		return errors.New(ErrExportTooLarge)
+	}
+	// Объявления, созданные после подсчёта, не выводят выгрузку за предел
+	return s.db.StreamAds(ctx, userID, maxRows, req.SortBy, req.SortOrder, filter, write)
+}
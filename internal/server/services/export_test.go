@@ -0,0 +1,54 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/YuarenArt/marketgo/internal/db"
+	"github.com/YuarenArt/marketgo/internal/db/dbtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnitExportAds(t *testing.T) {
+	ctx := context.Background()
+	store := dbtest.NewStore()
+	adService := NewAdService(store)
+
+	seller, err := store.CreateUser(ctx, "exporter", "hash")
+	require.NoError(t, err)
+	for _, price := range []int64{300, 100, 200, 900} {
+		_, err := adService.CreateAd(ctx, CreateAdRequest{Title: "Стол", Text: "Дубовый", ImageURL: "http://example.com/1.jpg", Price: price}, seller.ID)
+		require.NoError(t, err)
+	}
+
+	export := func(req GetAdsRequest, maxRows int) ([]int64, error) {
+		var prices []int64
+		err := adService.ExportAds(ctx, req, seller.ID, maxRows, func(ad db.Ad) error {
+			prices = append(prices, ad.Price)
+			return nil
+		})
+		return prices, err
+	}
+
+	t.Run("all pages in request order", func(t *testing.T) {
+		prices, err := export(GetAdsRequest{Page: 2, PageSize: 1, SortBy: "price", SortOrder: "ASC", MaxPrice: 500}, 10)
+		require.NoError(t, err)
+		assert.Equal(t, []int64{100, 200, 300}, prices)
+	})
+
+	t.Run("limit is checked before writing", func(t *testing.T) {
+		prices, err := export(GetAdsRequest{}, 3)
+		assert.EqualError(t, err, ErrExportTooLarge)
+		assert.Empty(t, prices)
+
+		prices, err = export(GetAdsRequest{}, 4)
+		require.NoError(t, err)
+		assert.Len(t, prices, 4)
+	})
+
+	t.Run("invalid filters", func(t *testing.T) {
+		_, err := export(GetAdsRequest{MinPrice: 500, MaxPrice: 100}, 10)
+		assert.ErrorIs(t, err, db.ErrInvalidPriceRange)
+	})
+}
@@ -0,0 +1,49 @@
+package services
+
+import (
+	"context"
+
+	"github.com/YuarenArt/marketgo/internal/db"
+)
+
+// AddFavorite добавляет объявление adID в избранное пользователя userID.
+// Избранное не влияет на кэш страниц: is_favorite вычисляется для каждого запроса.
+func (s *AdService) AddFavorite(ctx context.Context, adID, userID int) error {
+	return s.db.AddFavorite(ctx, userID, adID)
+}
+
+// RemoveFavorite убирает объявление adID из избранного пользователя userID
+func (s *AdService) RemoveFavorite(ctx context.Context, adID, userID int) error {
+	return s.db.RemoveFavorite(ctx, userID, adID)
+}
+
+// GetFavorites возвращает страницу избранных объявлений пользователя userID,
+// начиная с добавленных последними. Сортировка и фильтры запроса не применяются.
+func (s *AdService) GetFavorites(ctx context.Context, req GetAdsRequest, userID int) ([]db.Ad, error) {
+	ads, err := s.db.Favorites(ctx, userID, req.Page, req.PageSize)
+	if err != nil {
+		return nil, err
+	}
+	return s.translateAds(ctx, ads, req.Languages)
+}
+
+// withFavorites отмечает is_favorite у объявлений из избранного userID.
+// Нужен для страниц из кэша, загруженных без привязки к пользователю.
+func (s *AdService) withFavorites(ctx context.Context, ads []db.Ad, userID int) ([]db.Ad, error) {
+	if userID == 0 || len(ads) == 0 {
+		return ads, nil
+	}
+
+	ids := make([]int, len(ads))
+	for i, ad := range ads {
+		ids[i] = ad.ID
+	}
+	favorites, err := s.db.FavoriteAdIDs(ctx, userID, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range ads {
+		ads[i].IsFavorite = favorites[ads[i].ID]
+	}
+	return ads, nil
+}
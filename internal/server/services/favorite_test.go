@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/YuarenArt/marketgo/internal/db"
+	"github.com/YuarenArt/marketgo/internal/db/dbtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnitFavorites(t *testing.T) {
+	ctx := context.Background()
+	store := dbtest.NewStore()
+	adService := NewAdService(store)
+
+	seller, err := store.CreateUser(ctx, "favseller", "hash")
+	require.NoError(t, err)
+	buyer, err := store.CreateUser(ctx, "favbuyer", "hash")
+	require.NoError(t, err)
+
+	bike, err := adService.CreateAd(ctx, CreateAdRequest{Title: "Велосипед", Text: "Горный", ImageURL: "http://example.com/1.jpg", Price: 300}, seller.ID)
+	require.NoError(t, err)
+	sofa, err := adService.CreateAd(ctx, CreateAdRequest{Title: "Диван", Text: "Угловой", ImageURL: "http://example.com/2.jpg", Price: 500}, seller.ID)
+	require.NoError(t, err)
+
+	t.Run("add, list and remove", func(t *testing.T) {
+		require.NoError(t, adService.AddFavorite(ctx, bike.ID, buyer.ID))
+		require.NoError(t, adService.AddFavorite(ctx, bike.ID, buyer.ID), "adding twice is idempotent")
+		require.NoError(t, adService.AddFavorite(ctx, sofa.ID, seller.ID), "own ad can be favorited")
+
+		ads, err := adService.GetFavorites(ctx, GetAdsRequest{Page: 1, PageSize: 10}, buyer.ID)
+		require.NoError(t, err)
+		require.Len(t, ads, 1)
+		assert.Equal(t, bike.ID, ads[0].ID)
+		assert.True(t, ads[0].IsFavorite)
+
+		require.NoError(t, adService.RemoveFavorite(ctx, bike.ID, buyer.ID))
+		require.NoError(t, adService.RemoveFavorite(ctx, bike.ID, buyer.ID), "removing twice is idempotent")
+		ads, err = adService.GetFavorites(ctx, GetAdsRequest{Page: 1, PageSize: 10}, buyer.ID)
+		require.NoError(t, err)
+		assert.Empty(t, ads)
+	})
+
+	t.Run("missing or deleted ad is not found", func(t *testing.T) {
+		assert.ErrorIs(t, adService.AddFavorite(ctx, 999, buyer.ID), db.ErrAdNotFound)
+
+		lamp, err := adService.CreateAd(ctx, CreateAdRequest{Title: "Лампа", Text: "Настольная", ImageURL: "http://example.com/3.jpg", Price: 100}, seller.ID)
+		require.NoError(t, err)
+		require.NoError(t, adService.AddFavorite(ctx, lamp.ID, buyer.ID))
+		require.NoError(t, adService.DeleteAd(ctx, lamp.ID, seller.ID))
+
+		assert.ErrorIs(t, adService.AddFavorite(ctx, lamp.ID, buyer.ID), db.ErrAdNotFound)
+		ads, err := adService.GetFavorites(ctx, GetAdsRequest{Page: 1, PageSize: 10}, buyer.ID)
+		require.NoError(t, err)
+		assert.Empty(t, ads)
+	})
+
+	t.Run("cached pages get is_favorite per user", func(t *testing.T) {
+		adService.UseAdsCache(time.Minute, nil)
+		defer func() { adService.listCache = nil }()
+		require.NoError(t, adService.AddFavorite(ctx, bike.ID, buyer.ID))
+
+		req := GetAdsRequest{Page: 1, PageSize: 10, SortBy: "price", SortOrder: "ASC"}
+		favorite := func(userID int) map[int]bool {
+			ads, err := adService.GetAds(ctx, req, userID)
+			require.NoError(t, err)
+			result := make(map[int]bool)
+			for _, ad := range ads {
+				result[ad.ID] = ad.IsFavorite
+			}
+			return result
+		}
+
+		assert.Equal(t, map[int]bool{bike.ID: true, sofa.ID: false}, favorite(buyer.ID))
+		assert.Equal(t, map[int]bool{bike.ID: false, sofa.ID: true}, favorite(seller.ID), "served from cache")
+
+		require.NoError(t, adService.RemoveFavorite(ctx, bike.ID, buyer.ID))
+		assert.Equal(t, map[int]bool{bike.ID: false, sofa.ID: false}, favorite(buyer.ID))
+	})
+}
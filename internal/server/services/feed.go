@@ -0,0 +1,200 @@
+package services
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/YuarenArt/marketgo/internal/db"
+)
+
+const (
+	// FeedSize - количество новых объявлений в ленте
+	FeedSize = 50
+	// FeedMaxAge - время, на которое клиенты и прокси могут кэшировать ленту
+	FeedMaxAge = 5 * time.Minute
+
+	feedTitle     = "marketgo: новые объявления"
+	atomNamespace = "http://www.w3.org/2005/Atom"
+)
+
+// FeedFilter ограничивает объявления ленты; значения имеют тот же смысл, что и в GET /ads
+type FeedFilter struct {
+	Cities []string
+	Tags   []string
+}
+
+// FeedService формирует RSS- и Atom-ленты новых активных объявлений
+type FeedService struct {
+	db      AdStorage
+	baseURL string
+	now     func() time.Time
+}
+
+// NewFeedService создает новый экземпляр FeedService.
+// baseURL - публичный адрес сайта, используемый в ссылках ленты.
+func NewFeedService(db AdStorage, baseURL string) *FeedService {
+	return &FeedService{
+		db:      db,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		now:     time.Now,
+	}
+}
+
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title       string    `xml:"title"`
+	Link        string    `xml:"link"`
+	Description string    `xml:"description"`
+	Language    string    `xml:"language"`
+	Items       []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	GUID        rssGUID `xml:"guid"`
+	Description string  `xml:"description"`
+	PubDate     string  `xml:"pubDate"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"feed"`
+	Xmlns   string      `xml:"xmlns,attr"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomEntry struct {
+	ID        string   `xml:"id"`
+	Title     string   `xml:"title"`
+	Link      atomLink `xml:"link"`
+	Published string   `xml:"published"`
+	Updated   string   `xml:"updated"`
+	Summary   string   `xml:"summary"`
+}
+
+// RSS возвращает ленту RSS 2.0 из FeedSize новых активных объявлений
+func (s *FeedService) RSS(ctx context.Context, filter FeedFilter) ([]byte, error) {
+	ads, err := s.ads(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return s.renderRSS(ads)
+}
+
+// Atom возвращает ленту Atom из FeedSize новых активных объявлений
+func (s *FeedService) Atom(ctx context.Context, filter FeedFilter) ([]byte, error) {
+	ads, err := s.ads(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return s.renderAtom(ads)
+}
+
+// ads возвращает новые активные объявления так, как их видит неавторизованный пользователь
+func (s *FeedService) ads(ctx context.Context, filter FeedFilter) ([]db.Ad, error) {
+	return s.db.Ads(ctx, 0, 1, FeedSize, "created_at", "DESC", db.AdsFilter{
+		MaxPrice: DefaultMaxPrice,
+		Statuses: []string{db.AdStatusActive},
+		Cities:   filter.Cities,
+		Tags:     filter.Tags,
+	})
+}
+
+func (s *FeedService) renderRSS(ads []db.Ad) ([]byte, error) {
+	feed := rssFeed{
+		Version: "2.0",
+		Channel: rssChannel{
+			Title:       feedTitle,
+			Link:        s.baseURL + "/",
+			Description: "Новые объявления маркетплейса",
+			Language:    "ru",
+			Items:       make([]rssItem, 0, len(ads)),
+		},
+	}
+	for _, ad := range ads {
+		link := s.adLink(ad)
+		feed.Channel.Items = append(feed.Channel.Items, rssItem{
+			Title:       ad.Title,
+			Link:        link,
+			GUID:        rssGUID{IsPermaLink: true, Value: link},
+			Description: feedDescription(ad),
+			PubDate:     ad.CreatedAt.UTC().Format(time.RFC1123Z),
+		})
+	}
+	return marshalFeed(feed)
+}
+
+func (s *FeedService) renderAtom(ads []db.Ad) ([]byte, error) {
+	// Лента обновляется вместе с самым новым объявлением; пустая - на момент генерации
+	updated := s.now()
+	if len(ads) > 0 {
+		updated = ads[0].CreatedAt
+	}
+
+	feed := atomFeed{
+		Xmlns:   atomNamespace,
+		ID:      s.baseURL + "/feed.atom",
+		Title:   feedTitle,
+		Updated: updated.UTC().Format(time.RFC3339),
+		Links: []atomLink{
+			{Rel: "self", Href: s.baseURL + "/feed.atom"},
+			{Rel: "alternate", Href: s.baseURL + "/"},
+		},
+		Entries: make([]atomEntry, 0, len(ads)),
+	}
+	for _, ad := range ads {
+		link := s.adLink(ad)
+		created := ad.CreatedAt.UTC().Format(time.RFC3339)
+		feed.Entries = append(feed.Entries, atomEntry{
+			ID:        link,
+			Title:     ad.Title,
+			Link:      atomLink{Rel: "alternate", Href: link},
+			Published: created,
+			Updated:   created,
+			Summary:   feedDescription(ad),
+		})
+	}
+	return marshalFeed(feed)
+}
+
+func (s *FeedService) adLink(ad db.Ad) string {
+	return fmt.Sprintf("%s/ads/%d", s.baseURL, ad.ID)
+}
+
+// feedDescription возвращает описание объявления в ленте: цену и город, если он указан
+func feedDescription(ad db.Ad) string {
+	if ad.City != "" {
+		return fmt.Sprintf("Цена: %d, город: %s", ad.Price, ad.City)
+	}
+	return fmt.Sprintf("Цена: %d", ad.Price)
+}
+
+func marshalFeed(v any) ([]byte, error) {
+	data, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]byte(xml.Header), data...), '\n'), nil
+}
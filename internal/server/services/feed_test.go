@@ -0,0 +1,90 @@
+package services
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/YuarenArt/marketgo/internal/db"
+	"github.com/YuarenArt/marketgo/internal/db/dbtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var updateGolden = flag.Bool("update", false, "перезаписать эталонные файлы в testdata")
+
+// assertGolden сравнивает got с testdata/name; с -update перезаписывает эталон
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *updateGolden {
+		require.NoError(t, os.WriteFile(path, got, 0o644))
+	}
+	want, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, string(want), string(got))
+}
+
+func TestUnitFeed(t *testing.T) {
+	ctx := context.Background()
+	store := dbtest.NewStore()
+	now := time.Date(2025, 1, 2, 12, 0, 0, 0, time.UTC)
+	store.Now = func() time.Time { return now }
+
+	seller, err := store.CreateUser(ctx, "feedseller", "hash")
+	require.NoError(t, err)
+
+	create := func(ad db.Ad) db.Ad {
+		ad.UserID = seller.ID
+		ad.ExpiresAt = now.Add(DefaultAdTTL)
+		created, err := store.CreateAd(ctx, ad)
+		require.NoError(t, err)
+		now = now.Add(time.Hour)
+		return created
+	}
+	create(db.Ad{Title: "Велосипед горный", Text: "Почти новый", Price: 15000, City: "Москва", Tags: []string{"спорт"}})
+	sold := create(db.Ad{Title: "Продано", Text: "text", Price: 100})
+	create(db.Ad{Title: "Стол & стулья <комплект>", Text: "text", Price: 4200, City: "Казань"})
+	_, err = store.SetAdStatus(ctx, sold.ID, seller.ID, db.AdStatusSold)
+	require.NoError(t, err)
+
+	svc := NewFeedService(store, "https://market.example/")
+	svc.now = func() time.Time { return now }
+
+	t.Run("rss lists newest active ads", func(t *testing.T) {
+		feed, err := svc.RSS(ctx, FeedFilter{})
+		require.NoError(t, err)
+		assertGolden(t, "feed.rss.golden", feed)
+	})
+
+	t.Run("atom lists newest active ads", func(t *testing.T) {
+		feed, err := svc.Atom(ctx, FeedFilter{})
+		require.NoError(t, err)
+		assertGolden(t, "feed.atom.golden", feed)
+	})
+
+	t.Run("city filter", func(t *testing.T) {
+		feed, err := svc.RSS(ctx, FeedFilter{Cities: []string{"москва"}})
+		require.NoError(t, err)
+		assertGolden(t, "feed_city.rss.golden", feed)
+	})
+
+	t.Run("empty atom feed is updated at generation time", func(t *testing.T) {
+		feed, err := svc.Atom(ctx, FeedFilter{Tags: []string{"нет-такого"}})
+		require.NoError(t, err)
+		assertGolden(t, "feed_empty.atom.golden", feed)
+	})
+
+	t.Run("size is limited", func(t *testing.T) {
+		for i := 0; i < FeedSize; i++ {
+			create(db.Ad{Title: "Объявление", Text: "text", Price: 1})
+		}
+		ads, err := svc.ads(ctx, FeedFilter{})
+		require.NoError(t, err)
+		assert.Len(t, ads, FeedSize)
+		assert.True(t, ads[0].CreatedAt.After(ads[FeedSize-1].CreatedAt), "newest first")
+	})
+}
@@ -0,0 +1,177 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/YuarenArt/marketgo/internal/storage"
+	"github.com/YuarenArt/marketgo/pkg/logging"
+)
+
+const (
+	// MaxImageSize - максимальный размер загружаемого изображения
+	MaxImageSize = 5 << 20
+
+	// UploadsPath - путь, по которому сервер отдаёт загруженные файлы
+	UploadsPath = "/uploads"
+
+	// ImageGCInterval - как часто удаляются изображения, на которые не ссылается ни одно объявление
+	ImageGCInterval = time.Hour
+	// DefaultImageGCGrace - сколько изображение без ссылок хранится после последней загрузки:
+	// за это время пользователь успевает создать объявление с полученным адресом
+	DefaultImageGCGrace = 24 * time.Hour
+
+	ErrImageTooLarge    = "image must not exceed 5 MiB"
+	ErrUnsupportedImage = "image must be a JPEG, PNG or WebP file"
+)
+
+// imageExtensions - расширения файлов по типу содержимого, определённому по сигнатуре
+var imageExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+}
+
+// UploadedImage - сохранённое изображение: имя в хранилище и публичный адрес для image_url
+type UploadedImage struct {
+	Name        string `json:"name"`
+	URL         string `json:"url"`
+	ContentType string `json:"content_type"`
+	Size        int    `json:"size"`
+}
+
+// ImageRegistry учитывает загруженные изображения по хешу содержимого и ссылки объявлений на них
+type ImageRegistry interface {
+	RegisterImage(ctx context.Context, hash, name string, size int) (stored string, created bool, err error)
+	CollectImages(ctx context.Context, unusedBefore time.Time, remove func(name string) error) ([]string, error)
+}
+
+// ImageService проверяет и сохраняет изображения объявлений
+type ImageService struct {
+	storage storage.Storage
+	baseURL string
+	// registry включает учёт изображений и удаление неиспользуемых; nil - файлы только сохраняются
+	registry ImageRegistry
+	grace    time.Duration
+}
+
+// NewImageService создаёт сервис, сохраняющий изображения в store.
+// baseURL - публичный адрес сайта, от которого строятся ссылки на UploadsPath.
+func NewImageService(store storage.Storage, baseURL string) *ImageService {
+	return &ImageService{storage: store, baseURL: strings.TrimSuffix(baseURL, "/")}
+}
+
+// UseRegistry включает учёт изображений в registry: повторная загрузка того же содержимого
+// не сохраняет файл заново, а RunGC удаляет изображения без ссылок через grace после последней загрузки.
+// Неположительный grace заменяется на DefaultImageGCGrace.
+func (s *ImageService) UseRegistry(registry ImageRegistry, grace time.Duration) {
+	if grace <= 0 {
+		grace = DefaultImageGCGrace
+	}
+	s.registry = registry
+	s.grace = grace
+}
+
+// Upload читает изображение из r и сохраняет его под именем из хеша содержимого,
+// поэтому повторная загрузка того же файла возвращает тот же адрес и не создаёт второй копии.
+// Тип определяется по сигнатуре содержимого, а не по имени файла.
+func (s *ImageService) Upload(ctx context.Context, r io.Reader) (UploadedImage, error) {
+	data, err := io.ReadAll(io.LimitReader(r, MaxImageSize+1))
+	if err != nil {
+		return UploadedImage{}, fmt.Errorf("read image: %w", err)
+	}
+	if len(data) > MaxImageSize {
+		return UploadedImage{}, errors.New(ErrImageTooLarge)
+	}
+
+	contentType := http.DetectContentType(data)
+	ext, ok := imageExtensions[contentType]
+	if !ok {
+		return UploadedImage{}, errors.New(ErrUnsupportedImage)
+	}
+
+	sum := sha256.Sum256(data)
+	name := hex.EncodeToString(sum[:16]) + ext
+	if err := s.save(ctx, hex.EncodeToString(sum[:]), name, data); err != nil {
+		return UploadedImage{}, err
+	}
+	return UploadedImage{
+		Name:        name,
+		URL:         s.baseURL + UploadsPath + "/" + name,
+		ContentType: contentType,
+		Size:        len(data),
+	}, nil
+}
+
+// save сохраняет файл, если изображения с хешем hash ещё нет в хранилище
+func (s *ImageService) save(ctx context.Context, hash, name string, data []byte) error {
+	if s.registry != nil {
+		_, created, err := s.registry.RegisterImage(ctx, hash, name, len(data))
+		if err != nil {
+			return err
+		}
+		if !created {
+			file, err := s.storage.Open(ctx, name)
+			if err == nil {
+				return file.Close()
+			}
+			if !errors.Is(err, storage.ErrNotFound) {
+				return fmt.Errorf("open image: %w", err)
+			}
+			// Запись есть, а файла нет: предыдущее сохранение или удаление не завершилось
+		}
+	}
+	if err := s.storage.Save(ctx, name, bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("save image: %w", err)
+	}
+	return nil
+}
+
+// CollectGarbage удаляет изображения, на которые не ссылается ни одно объявление и которые
+// не загружались дольше срока ожидания, и возвращает их количество. Без учёта изображений ничего не делает.
+func (s *ImageService) CollectGarbage(ctx context.Context) (int, error) {
+	if s.registry == nil {
+		return 0, nil
+	}
+	removed, err := s.registry.CollectImages(ctx, time.Now().Add(-s.grace), func(name string) error {
+		return s.storage.Delete(ctx, name)
+	})
+	return len(removed), err
+}
+
+// RunGC удаляет неиспользуемые изображения при запуске и затем каждые interval до отмены контекста.
+// Без учёта изображений сразу возвращается.
+func (s *ImageService) RunGC(ctx context.Context, interval time.Duration, logger logging.Logger) {
+	if s.registry == nil {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if removed, err := s.CollectGarbage(ctx); err != nil && ctx.Err() == nil {
+			logger.Error("Image garbage collection failed", "error", err)
+		} else if err == nil && removed > 0 {
+			logger.Info("Unused images removed", "count", removed)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Open открывает загруженный файл name; для неизвестного имени возвращает storage.ErrNotFound
+func (s *ImageService) Open(ctx context.Context, name string) (storage.File, error) {
+	return s.storage.Open(ctx, name)
+}
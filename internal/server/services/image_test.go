@@ -0,0 +1,61 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/YuarenArt/marketgo/internal/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pngHeader - сигнатура PNG, по которой определяется тип файла
+var pngHeader = []byte("\x89PNG\r\n\x1a\n")
+
+func TestUnitImageUpload(t *testing.T) {
+	ctx := context.Background()
+	store, err := storage.NewLocal(t.TempDir())
+	require.NoError(t, err)
+	service := NewImageService(store, "https://market.example/")
+
+	t.Run("png is stored under a content hash", func(t *testing.T) {
+		data := append(append([]byte{}, pngHeader...), "image data"...)
+		image, err := service.Upload(ctx, bytes.NewReader(data))
+		require.NoError(t, err)
+		assert.Equal(t, "image/png", image.ContentType)
+		assert.Equal(t, len(data), image.Size)
+		assert.True(t, strings.HasSuffix(image.Name, ".png"), image.Name)
+		assert.Equal(t, "https://market.example/uploads/"+image.Name, image.URL)
+
+		again, err := service.Upload(ctx, bytes.NewReader(data))
+		require.NoError(t, err)
+		assert.Equal(t, image.Name, again.Name)
+
+		file, err := service.Open(ctx, image.Name)
+		require.NoError(t, err)
+		defer file.Close()
+		stored, err := io.ReadAll(file)
+		require.NoError(t, err)
+		assert.Equal(t, data, stored)
+	})
+
+	t.Run("type is sniffed from content", func(t *testing.T) {
+		_, err := service.Upload(ctx, strings.NewReader("<html>not an image</html>"))
+		assert.EqualError(t, err, ErrUnsupportedImage)
+	})
+
+	t.Run("oversized image", func(t *testing.T) {
+		data := append(append([]byte{}, pngHeader...), make([]byte, MaxImageSize)...)
+		_, err := service.Upload(ctx, bytes.NewReader(data))
+		assert.EqualError(t, err, ErrImageTooLarge)
+	})
+
+	t.Run("unknown file", func(t *testing.T) {
+		_, err := service.Open(ctx, "missing.png")
+		assert.True(t, errors.Is(err, storage.ErrNotFound))
+	})
+}
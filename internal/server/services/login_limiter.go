@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/YuarenArt/marketgo/pkg/logging"
+)
+
+const (
+	ErrLoginLocked = "too many failed login attempts"
+
+	DefaultLoginMaxFailures     = 5
+	DefaultLoginFailureWindow   = 15 * time.Minute
+	LoginLimiterCleanupInterval = 5 * time.Minute
+
+	maxTrackedLogins = 100_000
+)
+
+// LoginLockedError возвращается Authenticate, пока вход по логину временно заблокирован
+type LoginLockedError struct {
+	// RetryAfter - через сколько можно повторить попытку входа
+	RetryAfter time.Duration
+}
+
+func (e *LoginLockedError) Error() string {
+	return ErrLoginLocked
+}
+
+type loginFailures struct {
+	windowStart time.Time
+	count       int
+}
+
+// LoginLimiter считает неудачные попытки входа по логину. После MaxFailures неудач в пределах Window
+// вход по логину блокируется до конца окна; успешный вход сбрасывает счётчик.
+type LoginLimiter struct {
+	maxFailures int
+	window      time.Duration
+	now         func() time.Time
+
+	mu       sync.Mutex
+	failures map[string]*loginFailures
+}
+
+// NewLoginLimiter создает ограничитель попыток входа; неположительные значения заменяются значениями по умолчанию
+func NewLoginLimiter(maxFailures int, window time.Duration) *LoginLimiter {
+	if maxFailures <= 0 {
+		maxFailures = DefaultLoginMaxFailures
+	}
+	if window <= 0 {
+		window = DefaultLoginFailureWindow
+	}
+	return &LoginLimiter{
+		maxFailures: maxFailures,
+		window:      window,
+		now:         time.Now,
+		failures:    make(map[string]*loginFailures),
+	}
+}
+
+// Locked возвращает, сколько ещё заблокирован вход по логину login; 0 - вход разрешён
+func (l *LoginLimiter) Locked(login string) time.Duration {
+	now := l.now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	f, ok := l.failures[login]
+	if !ok || f.count < l.maxFailures {
+		return 0
+	}
+	if remaining := f.windowStart.Add(l.window).Sub(now); remaining > 0 {
+		return remaining
+	}
+	delete(l.failures, login)
+	return 0
+}
+
+// Fail учитывает неудачную попытку входа по логину login
+func (l *LoginLimiter) Fail(login string) {
+	now := l.now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	f, ok := l.failures[login]
+	if !ok || now.Sub(f.windowStart) >= l.window {
+		if !ok && len(l.failures) >= maxTrackedLogins {
+			// Не даём карте расти бесконечно при переборе множества логинов
+			l.failures = make(map[string]*loginFailures)
+		}
+		f = &loginFailures{windowStart: now}
+		l.failures[login] = f
+	}
+	f.count++
+}
+
+// Reset сбрасывает счётчик неудач логина login после успешного входа
+func (l *LoginLimiter) Reset(login string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.failures, login)
+}
+
+// Run периодически удаляет счётчики с истекшим окном до отмены ctx
+func (l *LoginLimiter) Run(ctx context.Context, interval time.Duration, logger logging.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		now := l.now()
+		l.mu.Lock()
+		for login, f := range l.failures {
+			if now.Sub(f.windowStart) >= l.window {
+				delete(l.failures, login)
+			}
+		}
+		tracked := len(l.failures)
+		l.mu.Unlock()
+		logger.Debug("Login failure counters cleaned up", "logins", tracked)
+	}
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/YuarenArt/marketgo/internal/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLoginLimiter(maxFailures int, window time.Duration) (*LoginLimiter, *time.Time) {
+	l := NewLoginLimiter(maxFailures, window)
+	now := time.Now()
+	l.now = func() time.Time { return now }
+	return l, &now
+}
+
+func TestLoginLimiter(t *testing.T) {
+	t.Run("locks after max failures until the window ends", func(t *testing.T) {
+		l, now := newTestLoginLimiter(3, time.Minute)
+		for i := 0; i < 2; i++ {
+			l.Fail("victim")
+			assert.Zero(t, l.Locked("victim"))
+		}
+
+		*now = now.Add(20 * time.Second)
+		l.Fail("victim")
+		assert.Equal(t, 40*time.Second, l.Locked("victim"))
+		assert.Zero(t, l.Locked("other"), "failures are tracked per login")
+
+		*now = now.Add(40 * time.Second)
+		assert.Zero(t, l.Locked("victim"), "lock expires with the window")
+		l.Fail("victim")
+		assert.Zero(t, l.Locked("victim"), "a new window starts from scratch")
+	})
+
+	t.Run("failures outside the window are forgotten", func(t *testing.T) {
+		l, now := newTestLoginLimiter(2, time.Minute)
+		l.Fail("slow")
+		*now = now.Add(2 * time.Minute)
+		l.Fail("slow")
+		assert.Zero(t, l.Locked("slow"))
+	})
+
+	t.Run("reset clears failures", func(t *testing.T) {
+		l, _ := newTestLoginLimiter(2, time.Minute)
+		l.Fail("user")
+		l.Reset("user")
+		l.Fail("user")
+		assert.Zero(t, l.Locked("user"))
+	})
+}
+
+func TestAuthenticateLockout(t *testing.T) {
+	authService := NewAuthService(testDB, secret, DefaultTokenTTL, testBcryptCost)
+	limiter, now := newTestLoginLimiter(3, time.Minute)
+	authService.UseLoginLimiter(limiter)
+
+	input := InputUserInfo{Login: "lockeduser", Password: "password123"}
+	_, err := authService.Register(testCtx, input)
+	require.NoError(t, err)
+	wrong := InputUserInfo{Login: input.Login, Password: "wrongpassword"}
+
+	t.Run("successful login resets failures", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			_, err := authService.Authenticate(testCtx, wrong)
+			require.Error(t, err)
+		}
+		_, err := authService.Authenticate(testCtx, input)
+		require.NoError(t, err)
+
+		for i := 0; i < 2; i++ {
+			_, err := authService.Authenticate(testCtx, wrong)
+			require.Error(t, err)
+		}
+		_, err = authService.Authenticate(testCtx, input)
+		assert.NoError(t, err)
+	})
+
+	t.Run("lockout rejects even the correct password", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			_, err := authService.Authenticate(testCtx, wrong)
+			require.Error(t, err)
+		}
+
+		_, err := authService.Authenticate(testCtx, input)
+		var locked *LoginLockedError
+		require.ErrorAs(t, err, &locked)
+		assert.Equal(t, time.Minute, locked.RetryAfter)
+		assert.EqualError(t, err, ErrLoginLocked)
+	})
+
+	t.Run("login works again after the window", func(t *testing.T) {
+		*now = now.Add(time.Minute)
+		_, err := authService.Authenticate(testCtx, input)
+		assert.NoError(t, err)
+	})
+
+	t.Run("unknown logins are counted too", func(t *testing.T) {
+		ghost := InputUserInfo{Login: "ghostuser", Password: "password123"}
+		for i := 0; i < 3; i++ {
+			_, err := authService.Authenticate(testCtx, ghost)
+			require.ErrorIs(t, err, db.ErrLoginNotFound)
+		}
+		_, err := authService.Authenticate(testCtx, ghost)
+		var locked *LoginLockedError
+		assert.ErrorAs(t, err, &locked)
+	})
+}
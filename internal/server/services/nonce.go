@@ -0,0 +1,69 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultNonceTTL = 10 * time.Minute
+	MaxNonceLength  = 128
+)
+
+// NonceStore запоминает использованные пользователями nonce запросов.
+// Реализация должна хранить nonce не меньше своего TTL и быть потокобезопасной.
+type NonceStore interface {
+	// Remember сохраняет nonce пользователя userID и сообщает, использован ли он впервые
+	Remember(ctx context.Context, userID int, nonce string) (bool, error)
+}
+
+type nonceKey struct {
+	userID int
+	nonce  string
+}
+
+// MemoryNonceStore - NonceStore в памяти процесса. Устаревшие nonce удаляются не реже раза за TTL.
+type MemoryNonceStore struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	now       func() time.Time
+	seen      map[nonceKey]time.Time
+	lastSweep time.Time
+}
+
+// NewMemoryNonceStore создает хранилище nonce с заданным TTL; нулевое значение заменяется на DefaultNonceTTL
+func NewMemoryNonceStore(ttl time.Duration) *MemoryNonceStore {
+	if ttl <= 0 {
+		ttl = DefaultNonceTTL
+	}
+	return &MemoryNonceStore{
+		ttl:       ttl,
+		now:       time.Now,
+		seen:      make(map[nonceKey]time.Time),
+		lastSweep: time.Now(),
+	}
+}
+
+// Remember сохраняет nonce и возвращает false, если он уже использовался в пределах TTL
+func (s *MemoryNonceStore) Remember(_ context.Context, userID int, nonce string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if now.Sub(s.lastSweep) >= s.ttl {
+		for key, expiresAt := range s.seen {
+			if !now.Before(expiresAt) {
+				delete(s.seen, key)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	key := nonceKey{userID: userID, nonce: nonce}
+	if expiresAt, ok := s.seen[key]; ok && now.Before(expiresAt) {
+		return false, nil
+	}
+	s.seen[key] = now.Add(s.ttl)
+	return true, nil
+}
@@ -0,0 +1,52 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryNonceStore(t *testing.T) {
+	store := NewMemoryNonceStore(time.Minute)
+	now := time.Now()
+	store.now = func() time.Time { return now }
+
+	t.Run("first use is accepted", func(t *testing.T) {
+		fresh, err := store.Remember(testCtx, 1, "abc")
+		require.NoError(t, err)
+		assert.True(t, fresh)
+	})
+
+	t.Run("replay is rejected", func(t *testing.T) {
+		fresh, err := store.Remember(testCtx, 1, "abc")
+		require.NoError(t, err)
+		assert.False(t, fresh)
+	})
+
+	t.Run("nonces are scoped per user", func(t *testing.T) {
+		fresh, err := store.Remember(testCtx, 2, "abc")
+		require.NoError(t, err)
+		assert.True(t, fresh)
+	})
+
+	t.Run("nonce expires after ttl", func(t *testing.T) {
+		now = now.Add(59 * time.Second)
+		fresh, err := store.Remember(testCtx, 1, "abc")
+		require.NoError(t, err)
+		assert.False(t, fresh, "still within ttl")
+
+		now = now.Add(2 * time.Second)
+		fresh, err = store.Remember(testCtx, 1, "abc")
+		require.NoError(t, err)
+		assert.True(t, fresh)
+	})
+
+	t.Run("expired nonces are swept", func(t *testing.T) {
+		now = now.Add(2 * time.Minute)
+		_, err := store.Remember(testCtx, 3, "other")
+		require.NoError(t, err)
+		assert.Len(t, store.seen, 1)
+	})
+}
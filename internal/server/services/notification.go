@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/YuarenArt/marketgo/internal/db"
+	"github.com/YuarenArt/marketgo/pkg/logging"
+)
+
+const (
+	NotificationRetention       = 90 * 24 * time.Hour
+	NotificationCleanupInterval = 24 * time.Hour
+)
+
+// GetNotificationsRequest представляет запрос ленты уведомлений
+type GetNotificationsRequest struct {
+	Page     int `json:"page" binding:"required,gte=1"`
+	PageSize int `json:"page_size" binding:"required,gte=1,lte=100"`
+}
+
+// AdStatusChangedPayload - данные уведомления о смене статуса объявления
+type AdStatusChangedPayload struct {
+	AdID   int    `json:"ad_id"`
+	Title  string `json:"title"`
+	Status string `json:"status"`
+}
+
+// NotificationService управляет лентой уведомлений и настройками пользователей
+type NotificationService struct {
+	db  *db.DBService
+	now func() time.Time
+}
+
+// NewNotificationService создает новый экземпляр NotificationService
+func NewNotificationService(db *db.DBService) *NotificationService {
+	return &NotificationService{db: db, now: time.Now}
+}
+
+// Notify создаёт уведомление пользователю userID с учётом его настроек
+func (s *NotificationService) Notify(ctx context.Context, userID int, notificationType string, payload any) error {
+	_, _, err := s.db.CreateNotification(ctx, userID, notificationType, payload)
+	return err
+}
+
+// List возвращает уведомления пользователя, непрочитанные - первыми
+func (s *NotificationService) List(ctx context.Context, req GetNotificationsRequest, userID int) ([]db.Notification, error) {
+	return s.db.Notifications(ctx, userID, req.Page, req.PageSize)
+}
+
+// MarkRead отмечает уведомление id прочитанным
+func (s *NotificationService) MarkRead(ctx context.Context, id, userID int) (db.Notification, error) {
+	return s.db.MarkNotificationRead(ctx, userID, id)
+}
+
+// MarkAllRead отмечает прочитанными все уведомления пользователя
+func (s *NotificationService) MarkAllRead(ctx context.Context, userID int) (int64, error) {
+	return s.db.MarkAllNotificationsRead(ctx, userID)
+}
+
+// Preferences возвращает настройки уведомлений пользователя
+func (s *NotificationService) Preferences(ctx context.Context, userID int) (db.Preferences, error) {
+	return s.db.UserPreferences(ctx, userID)
+}
+
+// UpdatePreferences обновляет переданные настройки уведомлений, остальные сохраняются
+func (s *NotificationService) UpdatePreferences(ctx context.Context, prefs db.Preferences, userID int) (db.Preferences, error) {
+	return s.db.UpdateUserPreferences(ctx, userID, prefs)
+}
+
+// Cleanup удаляет уведомления, прочитанные более NotificationRetention назад
+func (s *NotificationService) Cleanup(ctx context.Context) (int64, error) {
+	return s.db.DeleteReadNotifications(ctx, s.now().Add(-NotificationRetention))
+}
+
+// Run выполняет Cleanup при запуске и затем каждые interval до отмены контекста
+func (s *NotificationService) Run(ctx context.Context, interval time.Duration, logger logging.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if deleted, err := s.Cleanup(ctx); err != nil && ctx.Err() == nil {
+			logger.Error("Notification cleanup failed", "error", err)
+		} else if err == nil {
+			logger.Debug("Notification cleanup completed", "deleted", deleted)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
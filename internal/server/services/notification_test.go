@@ -0,0 +1,92 @@
+package services
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/YuarenArt/marketgo/internal/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotifications(t *testing.T) {
+	adService := NewAdService(testDB)
+	svc := NewNotificationService(testDB)
+
+	err := clearTables(testCtx, testDB)
+	require.NoError(t, err)
+
+	owner, err := testDB.CreateUser(testCtx, "notifyowner", "pass")
+	require.NoError(t, err)
+	ad, err := testDB.CreateAd(testCtx, db.Ad{Title: "Bike", Text: "text", Price: 100, UserID: owner.ID})
+	require.NoError(t, err)
+
+	page := GetNotificationsRequest{Page: 1, PageSize: 10}
+
+	t.Run("status change notifies owner", func(t *testing.T) {
+		_, err := adService.SetAdStatus(testCtx, ad.ID, UpdateAdStatusRequest{Status: db.AdStatusSold}, owner.ID)
+		require.NoError(t, err)
+
+		list, err := svc.List(testCtx, page, owner.ID)
+		require.NoError(t, err)
+		require.Len(t, list, 1)
+		assert.Equal(t, db.NotificationTypeAdStatusChanged, list[0].Type)
+		assert.JSONEq(t, fmt.Sprintf(`{"ad_id": %d, "title": "Bike", "status": "sold"}`, ad.ID), string(list[0].Payload))
+	})
+
+	t.Run("disabled preference suppresses notifications", func(t *testing.T) {
+		prefs, err := svc.UpdatePreferences(testCtx, db.Preferences{db.NotificationTypeAdStatusChanged: false}, owner.ID)
+		require.NoError(t, err)
+		assert.False(t, prefs.Enabled(db.NotificationTypeAdStatusChanged))
+
+		_, err = adService.SetAdStatus(testCtx, ad.ID, UpdateAdStatusRequest{Status: db.AdStatusActive}, owner.ID)
+		require.NoError(t, err)
+
+		list, err := svc.List(testCtx, page, owner.ID)
+		require.NoError(t, err)
+		assert.Len(t, list, 1)
+
+		_, err = svc.UpdatePreferences(testCtx, db.Preferences{db.NotificationTypeAdStatusChanged: true}, owner.ID)
+		require.NoError(t, err)
+		_, err = adService.SetAdStatus(testCtx, ad.ID, UpdateAdStatusRequest{Status: db.AdStatusArchived}, owner.ID)
+		require.NoError(t, err)
+
+		list, err = svc.List(testCtx, page, owner.ID)
+		require.NoError(t, err)
+		assert.Len(t, list, 2)
+	})
+
+	t.Run("read state transitions", func(t *testing.T) {
+		list, err := svc.List(testCtx, page, owner.ID)
+		require.NoError(t, err)
+		require.Len(t, list, 2)
+
+		read, err := svc.MarkRead(testCtx, list[0].ID, owner.ID)
+		require.NoError(t, err)
+		assert.NotNil(t, read.ReadAt)
+
+		list, err = svc.List(testCtx, page, owner.ID)
+		require.NoError(t, err)
+		assert.Nil(t, list[0].ReadAt, "unread notifications come first")
+		assert.NotNil(t, list[1].ReadAt)
+
+		updated, err := svc.MarkAllRead(testCtx, owner.ID)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), updated)
+
+		_, err = svc.MarkRead(testCtx, 999999, owner.ID)
+		assert.ErrorIs(t, err, db.ErrNotificationNotFound)
+	})
+
+	t.Run("cleanup keeps recently read notifications", func(t *testing.T) {
+		deleted, err := svc.Cleanup(testCtx)
+		require.NoError(t, err)
+		assert.Zero(t, deleted)
+
+		svc.now = func() time.Time { return time.Now().Add(NotificationRetention + time.Hour) }
+		deleted, err = svc.Cleanup(testCtx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), deleted)
+	})
+}
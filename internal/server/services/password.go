@@ -0,0 +1,64 @@
+package services
+
+import (
+	"strings"
+	"unicode"
+)
+
+const (
+	ErrWeakPassword = "weak password"
+
+	WeakPasswordNoLetterOrDigit = "password must contain at least one letter and one digit"
+	WeakPasswordContainsLogin   = "password must not contain the login"
+	WeakPasswordTooCommon       = "password is too common"
+)
+
+// commonPasswords - самые распространённые пароли, проходящие остальные проверки политики
+var commonPasswords = map[string]struct{}{
+	"password1": {}, "password12": {}, "password123": {}, "passw0rd": {}, "p@ssw0rd": {},
+	"qwerty123": {}, "qwerty12": {}, "qwe12345": {}, "12345qwe": {}, "1q2w3e4r": {},
+	"1qaz2wsx": {}, "zaq12wsx": {}, "abc12345": {}, "a1b2c3d4": {}, "admin123": {},
+	"letmein1": {}, "welcome1": {}, "iloveyou1": {}, "trustno1": {}, "monkey123": {},
+	"dragon123": {}, "football1": {}, "baseball1": {}, "sunshine1": {}, "master123": {},
+}
+
+// WeakPasswordError возвращается Register, если пароль не соответствует политике сложности
+type WeakPasswordError struct {
+	// Reason - какое требование политики нарушено
+	Reason string
+}
+
+func (e *WeakPasswordError) Error() string {
+	return ErrWeakPassword + ": " + e.Reason
+}
+
+// UsePasswordPolicy включает проверку сложности пароля при регистрации:
+// пароль должен содержать букву и цифру, не содержать логин и не входить в список распространённых
+func (s *AuthService) UsePasswordPolicy() {
+	s.passwordPolicy = true
+}
+
+// checkPasswordStrength проверяет пароль password пользователя login по политике сложности
+func checkPasswordStrength(login, password string) error {
+	var hasLetter, hasDigit bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLetter(r):
+			hasLetter = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		}
+	}
+	if !hasLetter || !hasDigit {
+		return &WeakPasswordError{Reason: WeakPasswordNoLetterOrDigit}
+	}
+
+	lower := strings.ToLower(password)
+	if login != "" && strings.Contains(lower, strings.ToLower(login)) {
+		return &WeakPasswordError{Reason: WeakPasswordContainsLogin}
+	}
+	if _, ok := commonPasswords[lower]; ok {
+		return &WeakPasswordError{Reason: WeakPasswordTooCommon}
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckPasswordStrength(t *testing.T) {
+	cases := []struct {
+		name     string
+		login    string
+		password string
+		reason   string
+	}{
+		{"identical characters", "alice", "aaaaaaaa", WeakPasswordNoLetterOrDigit},
+		{"digits only", "alice", "12345678", WeakPasswordNoLetterOrDigit},
+		{"contains login", "alice", "xAlice2024", WeakPasswordContainsLogin},
+		{"common password", "alice", "Qwerty123", WeakPasswordTooCommon},
+		{"strong password", "alice", "tr0ub4dor&3", ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkPasswordStrength(tc.login, tc.password)
+			if tc.reason == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var weak *WeakPasswordError
+			require.ErrorAs(t, err, &weak)
+			assert.Equal(t, tc.reason, weak.Reason)
+		})
+	}
+}
+
+func TestRegisterPasswordPolicy(t *testing.T) {
+	err := clearTables(testCtx, testDB)
+	require.NoError(t, err)
+
+	t.Run("weak password is rejected when policy is on", func(t *testing.T) {
+		authService := NewAuthService(testDB, secret, DefaultTokenTTL, testBcryptCost)
+		authService.UsePasswordPolicy()
+
+		_, err := authService.Register(testCtx, InputUserInfo{Login: "weakuser", Password: "password123"})
+		var weak *WeakPasswordError
+		require.ErrorAs(t, err, &weak)
+		assert.Equal(t, WeakPasswordTooCommon, weak.Reason)
+
+		_, err = authService.Register(testCtx, InputUserInfo{Login: "weakuser", Password: "s3cure-horse"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("policy is off by default", func(t *testing.T) {
+		authService := NewAuthService(testDB, secret, DefaultTokenTTL, testBcryptCost)
+		_, err := authService.Register(testCtx, InputUserInfo{Login: "lenient", Password: "aaaaaaaa"})
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/YuarenArt/marketgo/internal/db"
+	"github.com/YuarenArt/marketgo/pkg/logging"
+)
+
+const (
+	// PriceDropDedupWindow - снижения цены в пределах окна объединяются в одно уведомление
+	PriceDropDedupWindow = 24 * time.Hour
+	// PriceDropQueueSize - размер буфера событий о снижении цены
+	PriceDropQueueSize = 256
+)
+
+// PriceDropEvent описывает снижение цены объявления
+type PriceDropEvent struct {
+	AdID     int
+	Title    string
+	OldPrice int64
+	NewPrice int64
+}
+
+// PriceAlertService уведомляет пользователей о снижении цены объявлений из избранного
+type PriceAlertService struct {
+	db  *db.DBService
+	now func() time.Time
+}
+
+// NewPriceAlertService создает новый экземпляр PriceAlertService
+func NewPriceAlertService(db *db.DBService) *PriceAlertService {
+	return &PriceAlertService{db: db, now: time.Now}
+}
+
+// Handle создаёт уведомления о снижении цены всем пользователям, добавившим объявление в избранное
+func (s *PriceAlertService) Handle(ctx context.Context, ev PriceDropEvent) error {
+	if ev.NewPrice >= ev.OldPrice {
+		return nil
+	}
+
+	userIDs, err := s.db.FavoritedBy(ctx, ev.AdID)
+	if err != nil {
+		return err
+	}
+
+	drop := db.PriceDropPayload{
+		AdID:     ev.AdID,
+		Title:    ev.Title,
+		OldPrice: ev.OldPrice,
+		NewPrice: ev.NewPrice,
+	}
+	since := s.now().Add(-PriceDropDedupWindow)
+	for _, userID := range userIDs {
+		if _, _, err := s.db.NotifyPriceDrop(ctx, userID, drop, since); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Run обрабатывает события из events до отмены контекста
+func (s *PriceAlertService) Run(ctx context.Context, events <-chan PriceDropEvent, logger logging.Logger) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev := <-events:
+			if err := s.Handle(ctx, ev); err != nil && ctx.Err() == nil {
+				logger.Error("Price drop alert failed", "ad_id", ev.AdID, "error", err)
+			}
+		}
+	}
+}
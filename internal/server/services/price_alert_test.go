@@ -0,0 +1,108 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/YuarenArt/marketgo/internal/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPriceAlerts(t *testing.T) {
+	adService := NewAdService(testDB)
+	alerts := NewPriceAlertService(testDB)
+	notifications := NewNotificationService(testDB)
+
+	err := clearTables(testCtx, testDB)
+	require.NoError(t, err)
+
+	seller, err := testDB.CreateUser(testCtx, "pricedropseller", "pass")
+	require.NoError(t, err)
+	buyer, err := testDB.CreateUser(testCtx, "pricedropbuyer", "pass")
+	require.NoError(t, err)
+
+	ad, err := testDB.CreateAd(testCtx, db.Ad{Title: "Sofa", Text: "text", Price: 10000, UserID: seller.ID})
+	require.NoError(t, err)
+	require.NoError(t, testDB.Exec(testCtx, "INSERT INTO favorites (user_id, ad_id) VALUES ($1, $2)", buyer.ID, ad.ID))
+
+	setPrice := func(price int64) {
+		_, err := adService.UpdateAd(testCtx, ad.ID, UpdateAdRequest{Price: &price}, seller.ID)
+		require.NoError(t, err)
+	}
+	drainEvent := func() {
+		select {
+		case ev := <-adService.PriceDrops():
+			require.NoError(t, alerts.Handle(testCtx, ev))
+		default:
+			t.Fatal("expected price drop event")
+		}
+	}
+	priceDrops := func() []db.PriceDropPayload {
+		list, err := notifications.List(testCtx, GetNotificationsRequest{Page: 1, PageSize: 10}, buyer.ID)
+		require.NoError(t, err)
+		payloads := make([]db.PriceDropPayload, 0, len(list))
+		for _, n := range list {
+			require.Equal(t, db.NotificationTypePriceDrop, n.Type)
+			var p db.PriceDropPayload
+			require.NoError(t, json.Unmarshal(n.Payload, &p))
+			payloads = append(payloads, p)
+		}
+		return payloads
+	}
+
+	t.Run("price drop notifies favoriters", func(t *testing.T) {
+		setPrice(9000)
+		drainEvent()
+
+		drops := priceDrops()
+		require.Len(t, drops, 1)
+		assert.Equal(t, db.PriceDropPayload{AdID: ad.ID, Title: "Sofa", OldPrice: 10000, NewPrice: 9000}, drops[0])
+
+		sellerList, err := notifications.List(testCtx, GetNotificationsRequest{Page: 1, PageSize: 10}, seller.ID)
+		require.NoError(t, err)
+		assert.Empty(t, sellerList)
+	})
+
+	t.Run("price increase publishes no event", func(t *testing.T) {
+		setPrice(9500)
+		select {
+		case ev := <-adService.PriceDrops():
+			t.Fatalf("unexpected event: %+v", ev)
+		default:
+		}
+		require.NoError(t, alerts.Handle(testCtx, PriceDropEvent{AdID: ad.ID, OldPrice: 9000, NewPrice: 9500}))
+		assert.Len(t, priceDrops(), 1)
+	})
+
+	t.Run("repeated drops within window are merged", func(t *testing.T) {
+		_, err := notifications.MarkAllRead(testCtx, buyer.ID)
+		require.NoError(t, err)
+
+		setPrice(8000)
+		drainEvent()
+
+		list, err := notifications.List(testCtx, GetNotificationsRequest{Page: 1, PageSize: 10}, buyer.ID)
+		require.NoError(t, err)
+		require.Len(t, list, 1)
+		assert.Nil(t, list[0].ReadAt, "merged alert is unread again")
+
+		drops := priceDrops()
+		assert.Equal(t, int64(10000), drops[0].OldPrice)
+		assert.Equal(t, int64(8000), drops[0].NewPrice)
+	})
+
+	t.Run("drop after window creates new alert", func(t *testing.T) {
+		alerts.now = func() time.Time { return time.Now().Add(PriceDropDedupWindow + time.Hour) }
+		defer func() { alerts.now = time.Now }()
+
+		setPrice(7000)
+		drainEvent()
+
+		drops := priceDrops()
+		require.Len(t, drops, 2)
+		assert.Equal(t, int64(8000), drops[0].OldPrice)
+		assert.Equal(t, int64(7000), drops[0].NewPrice)
+	})
+}
@@ -0,0 +1,294 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/YuarenArt/marketgo/pkg/logging"
+)
+
+const (
+	DefaultProtectionWindow   = time.Minute
+	ProtectionCleanupInterval = 5 * time.Minute
+
+	maxPageWalks = 100_000
+)
+
+// ProtectionAction - решение защиты по запросу
+type ProtectionAction int
+
+const (
+	// ProtectionAllow - запрос пропускается, возможно с задержкой
+	ProtectionAllow ProtectionAction = iota
+	// ProtectionChallenge - клиент должен пройти CAPTCHA и повторить запрос с токеном
+	ProtectionChallenge
+	// ProtectionBlock - бюджет запросов исчерпан до конца окна
+	ProtectionBlock
+)
+
+// ProtectionConfig задаёт пороги защиты от перебора для неавторизованных запросов.
+// Нулевые значения отключают соответствующую проверку.
+type ProtectionConfig struct {
+	// Budget - сколько запросов с одного IP допускается за Window
+	Budget int
+	Window time.Duration
+	// TarpitAfter - после скольких запросов в окне ответы начинают задерживаться
+	TarpitAfter int
+	// TarpitStep - на сколько растёт задержка с каждым следующим запросом
+	TarpitStep time.Duration
+	// TarpitMaxDelay - максимальная задержка ответа
+	TarpitMaxDelay time.Duration
+}
+
+// RequestMeta - сведения о запросе, по которым распознаются боты
+type RequestMeta struct {
+	IP        string
+	Method    string
+	Path      string
+	Query     url.Values
+	UserAgent string
+}
+
+// BotDetector распознаёт автоматические запросы.
+// При положительном ответе клиенту предлагается пройти CAPTCHA.
+type BotDetector interface {
+	Suspicious(meta RequestMeta) bool
+}
+
+// CaptchaVerifier проверяет токен пройденной клиентом CAPTCHA
+type CaptchaVerifier interface {
+	Verify(ctx context.Context, ip, token string) (bool, error)
+}
+
+// ProtectionVerdict - результат проверки запроса
+type ProtectionVerdict struct {
+	Action ProtectionAction
+	// Delay - задержка перед обработкой разрешённого запроса
+	Delay time.Duration
+	// RetryAfter - через сколько заблокированный клиент может повторить запрос
+	RetryAfter time.Duration
+}
+
+type protectionClient struct {
+	windowStart time.Time
+	count       int
+	challenged  bool
+}
+
+// Protection ограничивает неавторизованные запросы с одного IP: считает бюджет запросов в окне,
+// замедляет ответы после порога и при превышении бюджета или подозрении детектора требует CAPTCHA.
+// Без CaptchaVerifier превышение бюджета блокирует IP до конца окна, а детектор не используется.
+type Protection struct {
+	cfg      ProtectionConfig
+	detector BotDetector
+	verifier CaptchaVerifier
+	now      func() time.Time
+	jitter   func(time.Duration) time.Duration
+
+	mu      sync.Mutex
+	clients map[string]*protectionClient
+}
+
+// NewProtection создает защиту с заданными порогами; detector и verifier могут быть nil
+func NewProtection(cfg ProtectionConfig, detector BotDetector, verifier CaptchaVerifier) *Protection {
+	if cfg.Window <= 0 {
+		cfg.Window = DefaultProtectionWindow
+	}
+	return &Protection{
+		cfg:      cfg,
+		detector: detector,
+		verifier: verifier,
+		now:      time.Now,
+		jitter:   func(d time.Duration) time.Duration { return time.Duration(rand.Int63n(int64(d) + 1)) },
+		clients:  make(map[string]*protectionClient),
+	}
+}
+
+// Check учитывает запрос и возвращает решение по нему.
+// captchaToken - токен CAPTCHA, переданный клиентом после требования пройти проверку.
+func (p *Protection) Check(ctx context.Context, meta RequestMeta, captchaToken string) (ProtectionVerdict, error) {
+	now := p.now()
+
+	p.mu.Lock()
+	client, ok := p.clients[meta.IP]
+	if !ok || now.Sub(client.windowStart) >= p.cfg.Window {
+		challenged := ok && client.challenged
+		client = &protectionClient{windowStart: now, challenged: challenged}
+		p.clients[meta.IP] = client
+	}
+	challenged := client.challenged
+	p.mu.Unlock()
+
+	if challenged {
+		if captchaToken == "" || p.verifier == nil {
+			return ProtectionVerdict{Action: ProtectionChallenge}, nil
+		}
+		valid, err := p.verifier.Verify(ctx, meta.IP, captchaToken)
+		if err != nil {
+			return ProtectionVerdict{}, err
+		}
+		if !valid {
+			return ProtectionVerdict{Action: ProtectionChallenge}, nil
+		}
+
+		p.mu.Lock()
+		client.challenged = false
+		client.windowStart, client.count = now, 0
+		p.mu.Unlock()
+		return ProtectionVerdict{Action: ProtectionAllow}, nil
+	}
+
+	suspicious := p.verifier != nil && p.detector != nil && p.detector.Suspicious(meta)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	client.count++
+	if suspicious || (p.cfg.Budget > 0 && client.count > p.cfg.Budget) {
+		if p.verifier != nil {
+			client.challenged = true
+			return ProtectionVerdict{Action: ProtectionChallenge}, nil
+		}
+		return ProtectionVerdict{Action: ProtectionBlock, RetryAfter: client.windowStart.Add(p.cfg.Window).Sub(now)}, nil
+	}
+
+	return ProtectionVerdict{Action: ProtectionAllow, Delay: p.tarpit(client.count)}, nil
+}
+
+// tarpit возвращает случайную задержку для count-го запроса в окне.
+// Верхняя граница задержки растёт на TarpitStep с каждым запросом после TarpitAfter.
+func (p *Protection) tarpit(count int) time.Duration {
+	if p.cfg.TarpitAfter <= 0 || p.cfg.TarpitStep <= 0 || count <= p.cfg.TarpitAfter {
+		return 0
+	}
+	limit := time.Duration(count-p.cfg.TarpitAfter) * p.cfg.TarpitStep
+	if p.cfg.TarpitMaxDelay > 0 && limit > p.cfg.TarpitMaxDelay {
+		limit = p.cfg.TarpitMaxDelay
+	}
+	return limit/2 + p.jitter(limit/2)
+}
+
+// Run периодически удаляет счётчики клиентов с истекшим окном до отмены ctx
+func (p *Protection) Run(ctx context.Context, interval time.Duration, logger logging.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		now := p.now()
+		p.mu.Lock()
+		for ip, client := range p.clients {
+			if !client.challenged && now.Sub(client.windowStart) >= p.cfg.Window {
+				delete(p.clients, ip)
+			}
+		}
+		clients := len(p.clients)
+		p.mu.Unlock()
+		logger.Debug("Protection counters cleaned up", "clients", clients)
+	}
+}
+
+// PageWalkDetector считает подозрительными клиентов, последовательно перебирающих страницы списка:
+// Threshold запросов подряд к одному пути, где page каждый раз увеличивается на единицу.
+type PageWalkDetector struct {
+	Threshold int
+
+	mu    sync.Mutex
+	walks map[string]pageWalk
+}
+
+type pageWalk struct {
+	path   string
+	page   int
+	streak int
+}
+
+// NewPageWalkDetector создает детектор последовательного перебора страниц
+func NewPageWalkDetector(threshold int) *PageWalkDetector {
+	return &PageWalkDetector{Threshold: threshold, walks: make(map[string]pageWalk)}
+}
+
+// Suspicious реализует BotDetector
+func (d *PageWalkDetector) Suspicious(meta RequestMeta) bool {
+	if d.Threshold <= 0 {
+		return false
+	}
+	page, err := strconv.Atoi(meta.Query.Get("page"))
+	if err != nil {
+		return false
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	walk := d.walks[meta.IP]
+	if walk.path == meta.Path && page == walk.page+1 {
+		walk.streak++
+	} else {
+		walk.streak = 1
+	}
+	walk.path, walk.page = meta.Path, page
+	if walk.streak >= d.Threshold {
+		delete(d.walks, meta.IP)
+		return true
+	}
+	if len(d.walks) >= maxPageWalks {
+		// Не даём карте расти бесконечно при переборе с множества адресов
+		d.walks = make(map[string]pageWalk)
+	}
+	d.walks[meta.IP] = walk
+	return false
+}
+
+// SiteVerifyCaptcha проверяет токены CAPTCHA через siteverify API, общее для hCaptcha,
+// reCAPTCHA и Cloudflare Turnstile
+type SiteVerifyCaptcha struct {
+	URL    string
+	Secret string
+	client *http.Client
+}
+
+// NewSiteVerifyCaptcha создает проверку токенов по адресу verifyURL с секретным ключом secret
+func NewSiteVerifyCaptcha(verifyURL, secret string) *SiteVerifyCaptcha {
+	return &SiteVerifyCaptcha{URL: verifyURL, Secret: secret, client: &http.Client{Timeout: 5 * time.Second}}
+}
+
+// Verify реализует CaptchaVerifier
+func (v *SiteVerifyCaptcha) Verify(ctx context.Context, ip, token string) (bool, error) {
+	form := url.Values{"secret": {v.Secret}, "response": {token}, "remoteip": {ip}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.URL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("captcha verification failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("captcha verification failed: status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Success bool `json:"success"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return false, fmt.Errorf("captcha verification failed: %w", err)
+	}
+	return result.Success, nil
+}
@@ -0,0 +1,162 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubVerifier struct {
+	valid string
+}
+
+func (v stubVerifier) Verify(_ context.Context, _, token string) (bool, error) {
+	return token == v.valid, nil
+}
+
+func newTestProtection(cfg ProtectionConfig, detector BotDetector, verifier CaptchaVerifier) (*Protection, *time.Time) {
+	p := NewProtection(cfg, detector, verifier)
+	now := time.Now()
+	p.now = func() time.Time { return now }
+	p.jitter = func(d time.Duration) time.Duration { return d }
+	return p, &now
+}
+
+func TestProtectionBudget(t *testing.T) {
+	p, now := newTestProtection(ProtectionConfig{Budget: 3, Window: time.Minute}, nil, nil)
+	meta := RequestMeta{IP: "10.0.0.1", Path: "/register"}
+
+	for i := 0; i < 3; i++ {
+		verdict, err := p.Check(testCtx, meta, "")
+		require.NoError(t, err)
+		assert.Equal(t, ProtectionAllow, verdict.Action)
+	}
+
+	*now = now.Add(20 * time.Second)
+	verdict, err := p.Check(testCtx, meta, "")
+	require.NoError(t, err)
+	assert.Equal(t, ProtectionBlock, verdict.Action)
+	assert.Equal(t, 40*time.Second, verdict.RetryAfter)
+
+	verdict, err = p.Check(testCtx, RequestMeta{IP: "10.0.0.2", Path: "/register"}, "")
+	require.NoError(t, err)
+	assert.Equal(t, ProtectionAllow, verdict.Action, "budget is tracked per ip")
+
+	*now = now.Add(time.Minute)
+	verdict, err = p.Check(testCtx, meta, "")
+	require.NoError(t, err)
+	assert.Equal(t, ProtectionAllow, verdict.Action, "budget resets with the window")
+}
+
+func TestProtectionTarpit(t *testing.T) {
+	p, _ := newTestProtection(ProtectionConfig{
+		TarpitAfter:    2,
+		TarpitStep:     100 * time.Millisecond,
+		TarpitMaxDelay: 250 * time.Millisecond,
+	}, nil, nil)
+	meta := RequestMeta{IP: "10.0.0.1", Path: "/login"}
+
+	var delays []time.Duration
+	for i := 0; i < 6; i++ {
+		verdict, err := p.Check(testCtx, meta, "")
+		require.NoError(t, err)
+		require.Equal(t, ProtectionAllow, verdict.Action)
+		delays = append(delays, verdict.Delay)
+	}
+	assert.Equal(t, []time.Duration{
+		0, 0,
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+		250 * time.Millisecond,
+		250 * time.Millisecond,
+	}, delays)
+}
+
+func TestProtectionChallenge(t *testing.T) {
+	p, _ := newTestProtection(ProtectionConfig{Budget: 2}, nil, stubVerifier{valid: "solved"})
+	meta := RequestMeta{IP: "10.0.0.1", Path: "/register"}
+
+	for i := 0; i < 2; i++ {
+		verdict, err := p.Check(testCtx, meta, "")
+		require.NoError(t, err)
+		assert.Equal(t, ProtectionAllow, verdict.Action)
+	}
+
+	t.Run("budget crossing demands a captcha", func(t *testing.T) {
+		verdict, err := p.Check(testCtx, meta, "")
+		require.NoError(t, err)
+		assert.Equal(t, ProtectionChallenge, verdict.Action)
+
+		verdict, err = p.Check(testCtx, meta, "")
+		require.NoError(t, err)
+		assert.Equal(t, ProtectionChallenge, verdict.Action, "challenge persists until solved")
+	})
+
+	t.Run("invalid token keeps the challenge", func(t *testing.T) {
+		verdict, err := p.Check(testCtx, meta, "forged")
+		require.NoError(t, err)
+		assert.Equal(t, ProtectionChallenge, verdict.Action)
+	})
+
+	t.Run("valid token lifts the challenge and resets the budget", func(t *testing.T) {
+		verdict, err := p.Check(testCtx, meta, "solved")
+		require.NoError(t, err)
+		assert.Equal(t, ProtectionAllow, verdict.Action)
+
+		for i := 0; i < 2; i++ {
+			verdict, err = p.Check(testCtx, meta, "")
+			require.NoError(t, err)
+			assert.Equal(t, ProtectionAllow, verdict.Action)
+		}
+	})
+}
+
+func TestPageWalkDetector(t *testing.T) {
+	detector := NewPageWalkDetector(3)
+	p, _ := newTestProtection(ProtectionConfig{}, detector, stubVerifier{valid: "solved"})
+
+	walk := func(ip string, pages ...string) []ProtectionAction {
+		var actions []ProtectionAction
+		for _, page := range pages {
+			verdict, err := p.Check(testCtx, RequestMeta{IP: ip, Path: "/ads", Query: url.Values{"page": {page}}}, "")
+			require.NoError(t, err)
+			actions = append(actions, verdict.Action)
+		}
+		return actions
+	}
+
+	assert.Equal(t,
+		[]ProtectionAction{ProtectionAllow, ProtectionAllow, ProtectionAllow, ProtectionAllow},
+		walk("10.0.0.1", "1", "2", "5", "6"), "gaps break the streak")
+	assert.Equal(t,
+		[]ProtectionAction{ProtectionAllow, ProtectionAllow, ProtectionChallenge},
+		walk("10.0.0.2", "1", "2", "3"))
+	assert.False(t, detector.Suspicious(RequestMeta{IP: "10.0.0.3", Path: "/ads"}), "requests without page are ignored")
+}
+
+func TestSiteVerifyCaptcha(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		assert.Equal(t, "secret", r.PostForm.Get("secret"))
+		assert.Equal(t, "10.0.0.1", r.PostForm.Get("remoteip"))
+		_ = json.NewEncoder(w).Encode(map[string]bool{"success": r.PostForm.Get("response") == "solved"})
+	}))
+	defer srv.Close()
+
+	verifier := NewSiteVerifyCaptcha(srv.URL, "secret")
+
+	ok, err := verifier.Verify(testCtx, "10.0.0.1", "solved")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = verifier.Verify(testCtx, "10.0.0.1", "forged")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
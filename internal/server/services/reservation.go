@@ -0,0 +1,79 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/YuarenArt/marketgo/internal/db"
+	"github.com/YuarenArt/marketgo/pkg/logging"
+)
+
+const (
+	DefaultReservationTTL     = 48 * time.Hour
+	ReservationExpiryInterval = time.Minute
+)
+
+// ReservationService управляет бронированием объявлений покупателями
+type ReservationService struct {
+	db  *db.DBService
+	ttl time.Duration
+	now func() time.Time
+}
+
+// NewReservationService создает новый экземпляр ReservationService.
+// ttl задаёт срок действия бронирования; нулевое значение заменяется на DefaultReservationTTL.
+func NewReservationService(db *db.DBService, ttl time.Duration) *ReservationService {
+	if ttl <= 0 {
+		ttl = DefaultReservationTTL
+	}
+	return &ReservationService{db: db, ttl: ttl, now: time.Now}
+}
+
+// Reserve бронирует объявление adID для покупателя buyerID
+func (s *ReservationService) Reserve(ctx context.Context, adID, buyerID int) (db.Reservation, error) {
+	return s.db.TransitionReservation(ctx, db.ReservationTransition{
+		Action:    db.ReservationActionReserve,
+		AdID:      adID,
+		UserID:    buyerID,
+		ExpiresAt: s.now().Add(s.ttl),
+	})
+}
+
+// Cancel отменяет активное бронирование объявления adID; доступно покупателю и продавцу
+func (s *ReservationService) Cancel(ctx context.Context, adID, userID int) (db.Reservation, error) {
+	return s.db.TransitionReservation(ctx, db.ReservationTransition{
+		Action: db.ReservationActionCancel,
+		AdID:   adID,
+		UserID: userID,
+	})
+}
+
+// Confirm подтверждает бронирование reservationID продавцом sellerID; объявление становится проданным
+func (s *ReservationService) Confirm(ctx context.Context, adID, reservationID, sellerID int) (db.Reservation, error) {
+	return s.db.TransitionReservation(ctx, db.ReservationTransition{
+		Action:        db.ReservationActionConfirm,
+		AdID:          adID,
+		UserID:        sellerID,
+		ReservationID: reservationID,
+	})
+}
+
+// Run истекает просроченные бронирования при запуске и затем каждые interval до отмены контекста
+func (s *ReservationService) Run(ctx context.Context, interval time.Duration, logger logging.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if expired, err := s.db.ExpireReservations(ctx); err != nil && ctx.Err() == nil {
+			logger.Error("Reservation expiry failed", "error", err)
+		} else if err == nil && expired > 0 {
+			logger.Info("Reservations expired", "count", expired)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
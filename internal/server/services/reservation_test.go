@@ -0,0 +1,151 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/YuarenArt/marketgo/internal/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReservations(t *testing.T) {
+	svc := NewReservationService(testDB, time.Hour)
+
+	err := clearTables(testCtx, testDB)
+	require.NoError(t, err)
+
+	seller, err := testDB.CreateUser(testCtx, "reserveseller", "pass")
+	require.NoError(t, err)
+	buyer, err := testDB.CreateUser(testCtx, "reservebuyer", "pass")
+	require.NoError(t, err)
+	other, err := testDB.CreateUser(testCtx, "reserveother", "pass")
+	require.NoError(t, err)
+
+	newAd := func(t *testing.T) db.Ad {
+		ad, err := testDB.CreateAd(testCtx, db.Ad{Title: "Guitar", Text: "text", Price: 5000, UserID: seller.ID})
+		require.NoError(t, err)
+		return ad
+	}
+
+	t.Run("reserve", func(t *testing.T) {
+		ad := newAd(t)
+
+		_, err := svc.Reserve(testCtx, ad.ID, seller.ID)
+		assert.ErrorIs(t, err, db.ErrReserveOwnAd, "seller cannot reserve own ad")
+
+		_, err = svc.Reserve(testCtx, 999999, buyer.ID)
+		assert.ErrorIs(t, err, db.ErrAdNotFound)
+
+		r, err := svc.Reserve(testCtx, ad.ID, buyer.ID)
+		require.NoError(t, err)
+		assert.Equal(t, db.ReservationStatusActive, r.Status)
+		assert.Equal(t, buyer.ID, r.BuyerID)
+		assert.WithinDuration(t, time.Now().Add(time.Hour), r.ExpiresAt, time.Minute)
+
+		_, err = svc.Reserve(testCtx, ad.ID, other.ID)
+		assert.ErrorIs(t, err, db.ErrAlreadyReserved)
+		_, err = svc.Reserve(testCtx, ad.ID, buyer.ID)
+		assert.ErrorIs(t, err, db.ErrAlreadyReserved)
+
+		fetched, err := testDB.Ad(testCtx, ad.ID, other.ID)
+		require.NoError(t, err)
+		assert.True(t, fetched.Reserved, "reserved ads are badged but visible")
+		assert.Equal(t, db.AdStatusActive, fetched.Status)
+	})
+
+	t.Run("reserve inactive ad", func(t *testing.T) {
+		ad := newAd(t)
+		_, err := testDB.SetAdStatus(testCtx, ad.ID, seller.ID, db.AdStatusArchived)
+		require.NoError(t, err)
+
+		_, err = svc.Reserve(testCtx, ad.ID, buyer.ID)
+		assert.ErrorIs(t, err, db.ErrAdNotAvailable)
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		for name, canceller := range map[string]int{"buyer": buyer.ID, "seller": seller.ID} {
+			t.Run(name, func(t *testing.T) {
+				ad := newAd(t)
+				_, err := svc.Reserve(testCtx, ad.ID, buyer.ID)
+				require.NoError(t, err)
+
+				_, err = svc.Cancel(testCtx, ad.ID, other.ID)
+				assert.ErrorIs(t, err, db.ErrNotReservationParty)
+
+				r, err := svc.Cancel(testCtx, ad.ID, canceller)
+				require.NoError(t, err)
+				assert.Equal(t, db.ReservationStatusCancelled, r.Status)
+
+				_, err = svc.Cancel(testCtx, ad.ID, canceller)
+				assert.ErrorIs(t, err, db.ErrReservationNotFound)
+
+				_, err = svc.Confirm(testCtx, ad.ID, r.ID, seller.ID)
+				assert.ErrorIs(t, err, db.ErrReservationNotActive)
+
+				_, err = svc.Reserve(testCtx, ad.ID, other.ID)
+				assert.NoError(t, err, "cancelled reservation frees the ad")
+			})
+		}
+	})
+
+	t.Run("confirm", func(t *testing.T) {
+		ad := newAd(t)
+		r, err := svc.Reserve(testCtx, ad.ID, buyer.ID)
+		require.NoError(t, err)
+
+		_, err = svc.Confirm(testCtx, ad.ID, r.ID, buyer.ID)
+		assert.ErrorIs(t, err, db.ErrNotAdOwner)
+
+		_, err = svc.Confirm(testCtx, ad.ID, 999999, seller.ID)
+		assert.ErrorIs(t, err, db.ErrReservationNotFound)
+
+		confirmed, err := svc.Confirm(testCtx, ad.ID, r.ID, seller.ID)
+		require.NoError(t, err)
+		assert.Equal(t, db.ReservationStatusConfirmed, confirmed.Status)
+
+		sold, err := testDB.Ad(testCtx, ad.ID, seller.ID)
+		require.NoError(t, err)
+		assert.Equal(t, db.AdStatusSold, sold.Status)
+		assert.False(t, sold.Reserved)
+
+		assert.Equal(t, buyer.ID, confirmed.BuyerID)
+
+		_, err = svc.Confirm(testCtx, ad.ID, r.ID, seller.ID)
+		assert.ErrorIs(t, err, db.ErrReservationNotActive)
+		_, err = svc.Cancel(testCtx, ad.ID, buyer.ID)
+		assert.ErrorIs(t, err, db.ErrReservationNotFound)
+		_, err = svc.Reserve(testCtx, ad.ID, other.ID)
+		assert.ErrorIs(t, err, db.ErrAdNotAvailable)
+	})
+
+	t.Run("expire", func(t *testing.T) {
+		ad := newAd(t)
+		svc.now = func() time.Time { return time.Now().Add(-2 * time.Hour) }
+		r, err := svc.Reserve(testCtx, ad.ID, buyer.ID)
+		svc.now = time.Now
+		require.NoError(t, err)
+
+		fetched, err := testDB.Ad(testCtx, ad.ID, other.ID)
+		require.NoError(t, err)
+		assert.False(t, fetched.Reserved, "expired reservation no longer badges the ad")
+
+		_, err = svc.Confirm(testCtx, ad.ID, r.ID, seller.ID)
+		assert.ErrorIs(t, err, db.ErrReservationNotActive)
+
+		_, err = svc.Reserve(testCtx, ad.ID, other.ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("maintenance expires stale reservations", func(t *testing.T) {
+		ad := newAd(t)
+		svc.now = func() time.Time { return time.Now().Add(-2 * time.Hour) }
+		_, err := svc.Reserve(testCtx, ad.ID, buyer.ID)
+		svc.now = time.Now
+		require.NoError(t, err)
+
+		expired, err := testDB.ExpireReservations(testCtx)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), expired)
+	})
+}
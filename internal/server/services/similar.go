@@ -0,0 +1,29 @@
+package services
+
+import (
+	"context"
+
+	"github.com/YuarenArt/marketgo/internal/db"
+)
+
+const (
+	DefaultSimilarLimit = 5
+	MaxSimilarLimit     = 20
+)
+
+// GetSimilarAds возвращает до limit активных объявлений других продавцов, похожих на adID.
+// Неположительный limit заменяется на DefaultSimilarLimit, больший MaxSimilarLimit - ограничивается им.
+func (s *AdService) GetSimilarAds(ctx context.Context, adID, userID, limit int, languages []string) ([]db.Ad, error) {
+	if limit <= 0 {
+		limit = DefaultSimilarLimit
+	}
+	if limit > MaxSimilarLimit {
+		limit = MaxSimilarLimit
+	}
+
+	ads, err := s.db.SimilarAds(ctx, adID, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	return s.translateAds(ctx, ads, languages)
+}
@@ -0,0 +1,52 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/YuarenArt/marketgo/internal/db"
+	"github.com/YuarenArt/marketgo/internal/db/dbtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnitSimilarAds(t *testing.T) {
+	ctx := context.Background()
+	store := dbtest.NewStore()
+	adService := NewAdService(store)
+
+	seller, err := store.CreateUser(ctx, "simseller", "hash")
+	require.NoError(t, err)
+	other, err := store.CreateUser(ctx, "simother", "hash")
+	require.NoError(t, err)
+
+	create := func(title string, price int64, userID int) db.Ad {
+		ad, err := adService.CreateAd(ctx, CreateAdRequest{Title: title, Text: "Состояние хорошее", ImageURL: "http://example.com/1.jpg", Price: price}, userID)
+		require.NoError(t, err)
+		return ad
+	}
+	source := create("Велосипед горный", 1000, seller.ID)
+	create("Велосипед детский", 900, seller.ID)
+	for i := 0; i < MaxSimilarLimit+5; i++ {
+		create(fmt.Sprintf("Велосипед %d", i), int64(1000+i), other.ID)
+	}
+
+	t.Run("limit defaults and is capped", func(t *testing.T) {
+		ads, err := adService.GetSimilarAds(ctx, source.ID, other.ID, 0, nil)
+		require.NoError(t, err)
+		assert.Len(t, ads, DefaultSimilarLimit)
+
+		ads, err = adService.GetSimilarAds(ctx, source.ID, other.ID, 100, nil)
+		require.NoError(t, err)
+		assert.Len(t, ads, MaxSimilarLimit)
+		for _, ad := range ads {
+			assert.NotEqual(t, seller.ID, ad.UserID, "ads of the source owner are excluded")
+		}
+	})
+
+	t.Run("missing ad is not found", func(t *testing.T) {
+		_, err := adService.GetSimilarAds(ctx, 999, other.ID, 5, nil)
+		assert.ErrorIs(t, err, db.ErrAdNotFound)
+	})
+}
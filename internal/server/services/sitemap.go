@@ -0,0 +1,174 @@
+package services
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/YuarenArt/marketgo/internal/db"
+)
+
+const (
+	// SitemapChunkSize - максимальное количество URL в одном файле карты сайта
+	SitemapChunkSize = 50_000
+	// SitemapCacheTTL - карта сайта перегенерируется не чаще этого интервала
+	SitemapCacheTTL = time.Hour
+
+	sitemapNamespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+	sitemapIndexName = "sitemap.xml"
+
+	ErrSitemapNotFound = "sitemap not found"
+)
+
+// Sitemap - сгенерированный файл карты сайта в обычном и сжатом виде
+type Sitemap struct {
+	XML []byte
+	Gz  []byte
+}
+
+type sitemapURLSet struct {
+	XMLName xml.Name     `xml:"urlset"`
+	Xmlns   string       `xml:"xmlns,attr"`
+	URLs    []sitemapURL `xml:"url"`
+}
+
+type sitemapURL struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name     `xml:"sitemapindex"`
+	Xmlns    string       `xml:"xmlns,attr"`
+	Sitemaps []sitemapRef `xml:"sitemap"`
+}
+
+type sitemapRef struct {
+	Loc string `xml:"loc"`
+}
+
+type sitemapCacheEntry struct {
+	sitemap     Sitemap
+	generatedAt time.Time
+}
+
+// SitemapService лениво генерирует карту сайта по активным объявлениям и кэширует её в памяти
+type SitemapService struct {
+	db        *db.DBService
+	baseURL   string
+	chunkSize int
+	ttl       time.Duration
+	now       func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]sitemapCacheEntry
+}
+
+// NewSitemapService создает новый экземпляр SitemapService.
+// baseURL - публичный адрес сайта, используемый в ссылках карты сайта.
+func NewSitemapService(db *db.DBService, baseURL string) *SitemapService {
+	return &SitemapService{
+		db:        db,
+		baseURL:   strings.TrimRight(baseURL, "/"),
+		chunkSize: SitemapChunkSize,
+		ttl:       SitemapCacheTTL,
+		now:       time.Now,
+		cache:     make(map[string]sitemapCacheEntry),
+	}
+}
+
+// Index возвращает индекс карты сайта со ссылками на все файлы объявлений
+func (s *SitemapService) Index(ctx context.Context) (Sitemap, error) {
+	return s.cached(sitemapIndexName, func() ([]byte, error) {
+		count, err := s.db.CountSitemapAds(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		index := sitemapIndex{Xmlns: sitemapNamespace, Sitemaps: []sitemapRef{}}
+		for n := 1; n <= s.chunks(count); n++ {
+			index.Sitemaps = append(index.Sitemaps, sitemapRef{Loc: fmt.Sprintf("%s/sitemaps/%s", s.baseURL, chunkName(n))})
+		}
+		return marshalSitemap(index)
+	})
+}
+
+// Chunk возвращает n-й (начиная с 1) файл карты сайта с объявлениями
+func (s *SitemapService) Chunk(ctx context.Context, n int) (Sitemap, error) {
+	return s.cached(chunkName(n), func() ([]byte, error) {
+		count, err := s.db.CountSitemapAds(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if n < 1 || n > s.chunks(count) {
+			return nil, errors.New(ErrSitemapNotFound)
+		}
+
+		entries, err := s.db.SitemapAds(ctx, s.chunkSize, (n-1)*s.chunkSize)
+		if err != nil {
+			return nil, err
+		}
+
+		set := sitemapURLSet{Xmlns: sitemapNamespace, URLs: make([]sitemapURL, 0, len(entries))}
+		for _, e := range entries {
+			set.URLs = append(set.URLs, sitemapURL{
+				Loc:     fmt.Sprintf("%s/ads/%d", s.baseURL, e.AdID),
+				LastMod: e.LastMod.UTC().Format(time.RFC3339),
+			})
+		}
+		return marshalSitemap(set)
+	})
+}
+
+// cached возвращает файл из кэша или генерирует его, если кэш старше ttl
+func (s *SitemapService) cached(name string, generate func() ([]byte, error)) (Sitemap, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if entry, ok := s.cache[name]; ok && now.Sub(entry.generatedAt) < s.ttl {
+		return entry.sitemap, nil
+	}
+
+	data, err := generate()
+	if err != nil {
+		return Sitemap{}, err
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return Sitemap{}, err
+	}
+	if err := zw.Close(); err != nil {
+		return Sitemap{}, err
+	}
+
+	sitemap := Sitemap{XML: data, Gz: buf.Bytes()}
+	s.cache[name] = sitemapCacheEntry{sitemap: sitemap, generatedAt: now}
+	return sitemap, nil
+}
+
+// chunks возвращает количество файлов для count объявлений
+func (s *SitemapService) chunks(count int) int {
+	return (count + s.chunkSize - 1) / s.chunkSize
+}
+
+// chunkName возвращает имя n-го файла карты сайта
+func chunkName(n int) string {
+	return fmt.Sprintf("ads-%d.xml", n)
+}
+
+func marshalSitemap(v any) ([]byte, error) {
+	data, err := xml.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
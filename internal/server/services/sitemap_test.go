@@ -0,0 +1,109 @@
+package services
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/YuarenArt/marketgo/internal/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSitemap(t *testing.T) {
+	svc := NewSitemapService(testDB, "https://market.example/")
+	svc.chunkSize = 2
+
+	err := clearTables(testCtx, testDB)
+	require.NoError(t, err)
+
+	user, err := testDB.CreateUser(testCtx, "sitemapper", "pass")
+	require.NoError(t, err)
+
+	var ads []db.Ad
+	for i := 0; i < 4; i++ {
+		ad, err := testDB.CreateAd(testCtx, db.Ad{Title: fmt.Sprintf("Ad %d", i), Text: "text", Price: 100, UserID: user.ID})
+		require.NoError(t, err)
+		ads = append(ads, ad)
+	}
+	_, err = testDB.SetAdStatus(testCtx, ads[1].ID, user.ID, db.AdStatusSold)
+	require.NoError(t, err)
+	require.NoError(t, testDB.DeleteAd(testCtx, ads[2].ID, user.ID))
+	ad, err := testDB.CreateAd(testCtx, db.Ad{Title: "Ad 4", Text: "text", Price: 100, UserID: user.ID})
+	require.NoError(t, err)
+	ads = append(ads, ad)
+
+	now := time.Now()
+	svc.now = func() time.Time { return now }
+
+	t.Run("index lists chunks with public base url", func(t *testing.T) {
+		sitemap, err := svc.Index(testCtx)
+		require.NoError(t, err)
+
+		var index sitemapIndex
+		require.NoError(t, xml.Unmarshal(sitemap.XML, &index))
+		assert.Equal(t, sitemapNamespace, index.Xmlns)
+		require.Len(t, index.Sitemaps, 2)
+		assert.Equal(t, "https://market.example/sitemaps/ads-1.xml", index.Sitemaps[0].Loc)
+		assert.Equal(t, "https://market.example/sitemaps/ads-2.xml", index.Sitemaps[1].Loc)
+		assert.Equal(t, sitemap.XML, gunzip(t, sitemap.Gz))
+	})
+
+	t.Run("chunks contain only active ads split at chunk size", func(t *testing.T) {
+		first, err := svc.Chunk(testCtx, 1)
+		require.NoError(t, err)
+		var set sitemapURLSet
+		require.NoError(t, xml.Unmarshal(first.XML, &set))
+		require.Len(t, set.URLs, 2)
+		assert.Equal(t, fmt.Sprintf("https://market.example/ads/%d", ads[0].ID), set.URLs[0].Loc)
+		assert.Equal(t, fmt.Sprintf("https://market.example/ads/%d", ads[3].ID), set.URLs[1].Loc)
+		_, err = time.Parse(time.RFC3339, set.URLs[0].LastMod)
+		assert.NoError(t, err)
+
+		second, err := svc.Chunk(testCtx, 2)
+		require.NoError(t, err)
+		set = sitemapURLSet{}
+		require.NoError(t, xml.Unmarshal(second.XML, &set))
+		require.Len(t, set.URLs, 1)
+		assert.Equal(t, fmt.Sprintf("https://market.example/ads/%d", ads[4].ID), set.URLs[0].Loc)
+
+		_, err = svc.Chunk(testCtx, 3)
+		assert.EqualError(t, err, ErrSitemapNotFound)
+		_, err = svc.Chunk(testCtx, 0)
+		assert.EqualError(t, err, ErrSitemapNotFound)
+	})
+
+	t.Run("sitemap is regenerated at most once per ttl", func(t *testing.T) {
+		_, err := testDB.CreateAd(testCtx, db.Ad{Title: "Ad 5", Text: "text", Price: 100, UserID: user.ID})
+		require.NoError(t, err)
+		_, err = testDB.CreateAd(testCtx, db.Ad{Title: "Ad 6", Text: "text", Price: 100, UserID: user.ID})
+		require.NoError(t, err)
+
+		now = now.Add(SitemapCacheTTL - time.Minute)
+		sitemap, err := svc.Index(testCtx)
+		require.NoError(t, err)
+		var index sitemapIndex
+		require.NoError(t, xml.Unmarshal(sitemap.XML, &index))
+		assert.Len(t, index.Sitemaps, 2, "cached index is served within ttl")
+
+		now = now.Add(2 * time.Minute)
+		sitemap, err = svc.Index(testCtx)
+		require.NoError(t, err)
+		index = sitemapIndex{}
+		require.NoError(t, xml.Unmarshal(sitemap.XML, &index))
+		assert.Len(t, index.Sitemaps, 3)
+	})
+}
+
+func gunzip(t *testing.T, data []byte) []byte {
+	t.Helper()
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	out, err := io.ReadAll(zr)
+	require.NoError(t, err)
+	return out
+}
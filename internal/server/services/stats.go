@@ -0,0 +1,102 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/YuarenArt/marketgo/internal/db"
+	"github.com/YuarenArt/marketgo/pkg/logging"
+)
+
+const (
+	StatsRollupInterval = 24 * time.Hour
+	DefaultStatsDays    = 30
+	MaxStatsDays        = 366
+	StatsDateLayout     = "2006-01-02"
+)
+
+// GetDailyStatsRequest представляет запрос временного ряда дневной статистики
+type GetDailyStatsRequest struct {
+	Metric string
+	From   time.Time
+	To     time.Time
+}
+
+// DailyStatsResponse представляет временной ряд метрики для построения графика
+type DailyStatsResponse struct {
+	Metric string         `json:"metric"`
+	From   string         `json:"from"`
+	To     string         `json:"to"`
+	Points []db.DailyStat `json:"points"`
+}
+
+// StatsService собирает и отдаёт дневную статистику маркетплейса
+type StatsService struct {
+	db  *db.DBService
+	now func() time.Time
+}
+
+// NewStatsService создает новый экземпляр StatsService
+func NewStatsService(db *db.DBService) *StatsService {
+	return &StatsService{db: db, now: time.Now}
+}
+
+// Rollup пересчитывает статистику начиная с последнего собранного дня и до текущего (UTC).
+// Последний собранный день пересчитывается повторно, так как мог быть агрегирован не полностью,
+// а пропущенные из-за простоя дни заполняются автоматически.
+func (s *StatsService) Rollup(ctx context.Context) error {
+	today := s.now().UTC().Truncate(24 * time.Hour)
+
+	from, ok, err := s.db.LastStatsDay(ctx)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		from, ok, err = s.db.FirstActivityDay(ctx)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			from = today
+		}
+	}
+	if from.After(today) {
+		from = today
+	}
+
+	return s.db.RollupDailyStats(ctx, from, today)
+}
+
+// Run выполняет Rollup при запуске и затем каждые interval до отмены контекста
+func (s *StatsService) Run(ctx context.Context, interval time.Duration, logger logging.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Rollup(ctx); err != nil && ctx.Err() == nil {
+			logger.Error("Stats rollup failed", "error", err)
+		} else if err == nil {
+			logger.Debug("Stats rollup completed")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DailyStats возвращает временной ряд метрики за запрошенный период
+func (s *StatsService) DailyStats(ctx context.Context, req GetDailyStatsRequest) (DailyStatsResponse, error) {
+	points, err := s.db.DailyStats(ctx, req.Metric, req.From, req.To)
+	if err != nil {
+		return DailyStatsResponse{}, err
+	}
+	return DailyStatsResponse{
+		Metric: req.Metric,
+		From:   req.From.Format(StatsDateLayout),
+		To:     req.To.Format(StatsDateLayout),
+		Points: points,
+	}, nil
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/YuarenArt/marketgo/internal/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatsRollup(t *testing.T) {
+	statsService := NewStatsService(testDB)
+
+	require.NoError(t, clearTables(testCtx, testDB))
+	require.NoError(t, testDB.Exec(testCtx, "TRUNCATE TABLE daily_stats"))
+
+	day := func(d, h, m, s int) time.Time {
+		return time.Date(2024, time.March, d, h, m, s, 0, time.UTC)
+	}
+	seedUser := func(login string, createdAt time.Time) int {
+		require.NoError(t, testDB.Exec(testCtx,
+			"INSERT INTO users (login, password, created_at) VALUES ($1, 'hash', $2)", login, createdAt))
+		user, err := testDB.UserByLogin(testCtx, login)
+		require.NoError(t, err)
+		return user.ID
+	}
+	seedAd := func(userID int, createdAt time.Time) {
+		require.NoError(t, testDB.Exec(testCtx,
+			"INSERT INTO ads (title, text, image_url, price, user_id, created_at, expires_at) VALUES ('Ad', 'Text', '', 100, $1, $2, $2::timestamp + INTERVAL '30 days')",
+			userID, createdAt))
+	}
+	series := func(metric string, from, to time.Time) []int64 {
+		resp, err := statsService.DailyStats(testCtx, GetDailyStatsRequest{Metric: metric, From: from, To: to})
+		require.NoError(t, err)
+		values := make([]int64, 0, len(resp.Points))
+		for _, p := range resp.Points {
+			values = append(values, p.Value)
+		}
+		return values
+	}
+
+	u1 := seedUser("statsuser1", day(1, 10, 0, 0))
+	seedUser("statsuser2", day(3, 23, 59, 59))
+	seedAd(u1, day(3, 23, 59, 59))
+	seedAd(u1, day(4, 0, 0, 0))
+	seedAd(u1, day(4, 8, 0, 0))
+
+	t.Run("initial rollup starts from first activity and splits days in UTC", func(t *testing.T) {
+		statsService.now = func() time.Time { return day(5, 12, 0, 0) }
+		require.NoError(t, statsService.Rollup(testCtx))
+
+		assert.Equal(t, []int64{0, 0, 1, 2, 0}, series(db.StatsMetricAdsCreated, day(1, 0, 0, 0), day(5, 0, 0, 0)))
+		assert.Equal(t, []int64{1, 0, 1, 0, 0}, series(db.StatsMetricUsersRegistered, day(1, 0, 0, 0), day(5, 0, 0, 0)))
+
+		last, ok, err := testDB.LastStatsDay(testCtx)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, day(5, 0, 0, 0), last)
+	})
+
+	t.Run("missed days are backfilled after downtime", func(t *testing.T) {
+		u3 := seedUser("statsuser3", day(8, 9, 0, 0))
+		seedAd(u1, day(7, 15, 0, 0))
+		seedAd(u3, day(8, 9, 30, 0))
+
+		statsService.now = func() time.Time { return day(9, 1, 0, 0) }
+		require.NoError(t, statsService.Rollup(testCtx))
+
+		assert.Equal(t, []int64{0, 0, 1, 2, 0, 0, 1, 1, 0}, series(db.StatsMetricAdsCreated, day(1, 0, 0, 0), day(9, 0, 0, 0)))
+		assert.Equal(t, []int64{1, 0, 1, 0, 0, 0, 0, 1, 0}, series(db.StatsMetricUsersRegistered, day(1, 0, 0, 0), day(9, 0, 0, 0)))
+	})
+
+	t.Run("rollup is idempotent", func(t *testing.T) {
+		require.NoError(t, statsService.Rollup(testCtx))
+		require.NoError(t, statsService.Rollup(testCtx))
+
+		assert.Equal(t, []int64{0, 0, 1, 2, 0, 0, 1, 1, 0}, series(db.StatsMetricAdsCreated, day(1, 0, 0, 0), day(9, 0, 0, 0)))
+	})
+
+	t.Run("days outside collected range are zero", func(t *testing.T) {
+		assert.Equal(t, []int64{0, 0}, series(db.StatsMetricAdsCreated, day(10, 0, 0, 0), day(11, 0, 0, 0)))
+	})
+
+	t.Run("unknown metric returns error", func(t *testing.T) {
+		_, err := statsService.DailyStats(testCtx, GetDailyStatsRequest{Metric: "revenue", From: day(1, 0, 0, 0), To: day(2, 0, 0, 0)})
+		assert.ErrorIs(t, err, db.ErrInvalidStatsMetric)
+	})
+
+	t.Run("inverted range returns error", func(t *testing.T) {
+		_, err := statsService.DailyStats(testCtx, GetDailyStatsRequest{Metric: db.StatsMetricAdsCreated, From: day(2, 0, 0, 0), To: day(1, 0, 0, 0)})
+		assert.ErrorIs(t, err, db.ErrInvalidStatsRange)
+	})
+}
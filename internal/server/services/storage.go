@@ -0,0 +1,63 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/YuarenArt/marketgo/internal/db"
+)
+
+// UserStorage - хранилище пользователей и токенов, с которым работает AuthService.
+// Реализуется *db.DBService; в модульных тестах - dbtest.Store.
+type UserStorage interface {
+	CreateUser(ctx context.Context, login, hashedPassword string) (db.User, error)
+	UserByLogin(ctx context.Context, login string) (db.User, error)
+	UserByID(ctx context.Context, id int) (db.User, error)
+	SetUserRole(ctx context.Context, login, role string) (db.User, error)
+	DeleteUser(ctx context.Context, userID int) error
+	CountUserAds(ctx context.Context, userID int) (int, error)
+
+	CreateRefreshToken(ctx context.Context, userID int, tokenHash string, expiresAt time.Time) error
+	RotateRefreshToken(ctx context.Context, oldHash, newHash string, expiresAt time.Time) (int, error)
+	RevokeUserRefreshToken(ctx context.Context, userID int, tokenHash string) error
+	RevokeToken(ctx context.Context, jti string, expiresAt time.Time) error
+	IsTokenRevoked(ctx context.Context, jti string) (bool, error)
+	DeleteExpiredRevocations(ctx context.Context, before time.Time) (int64, error)
+}
+
+// AdStorage - хранилище объявлений, с которым работает AdService.
+// Реализуется *db.DBService; в модульных тестах - dbtest.Store.
+type AdStorage interface {
+	UserByLogin(ctx context.Context, login string) (db.User, error)
+
+	CreateAd(ctx context.Context, ad db.Ad) (db.Ad, error)
+	Ad(ctx context.Context, adID, userID int) (db.Ad, error)
+	Ads(ctx context.Context, userID, page, size int, sortBy, sortOrder string, filter db.AdsFilter) ([]db.Ad, error)
+	AdsAfterCursor(ctx context.Context, userID, size int, sortBy, sortOrder string, filter db.AdsFilter, after *db.AdsCursor) ([]db.Ad, *db.AdsCursor, error)
+	AdsByIDs(ctx context.Context, userID int, ids []int, filter db.AdsFilter) ([]db.Ad, error)
+	AdsByUser(ctx context.Context, userID, page, size int, sortBy, sortOrder string) ([]db.Ad, error)
+	CountAds(ctx context.Context, filter db.AdsFilter) (int, error)
+	UpdateAd(ctx context.Context, adID, userID int, upd db.AdUpdate) (db.Ad, error)
+	SetAdStatus(ctx context.Context, adID, userID int, status string) (db.Ad, error)
+	DeleteAd(ctx context.Context, adID, userID int) error
+	RemoveAd(ctx context.Context, adID, moderatorID int) error
+	RenewAd(ctx context.Context, adID, userID int, expiresAt time.Time) (db.Ad, error)
+	ExpireAds(ctx context.Context) ([]db.Ad, error)
+	SuggestTitleWords(ctx context.Context, prefix string, limit int) ([]string, error)
+	AdTranslations(ctx context.Context, adIDs []int) (map[int]map[string]db.AdTranslation, error)
+	SearchAds(ctx context.Context, query string, filter db.AdsFilter, limit, offset int) ([]int, int, error)
+	SimilarAds(ctx context.Context, adID, userID, limit int) ([]db.Ad, error)
+	StreamAds(ctx context.Context, userID, limit int, sortBy, sortOrder string, filter db.AdsFilter, fn func(db.Ad) error) error
+
+	AddFavorite(ctx context.Context, userID, adID int) error
+	RemoveFavorite(ctx context.Context, userID, adID int) error
+	Favorites(ctx context.Context, userID, page, size int) ([]db.Ad, error)
+	FavoriteAdIDs(ctx context.Context, userID int, adIDs []int) (map[int]bool, error)
+
+	CreateNotification(ctx context.Context, userID int, notificationType string, payload any) (db.Notification, bool, error)
+}
+
+var (
+	_ UserStorage = (*db.DBService)(nil)
+	_ AdStorage   = (*db.DBService)(nil)
+)
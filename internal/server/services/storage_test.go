@@ -0,0 +1,252 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/YuarenArt/marketgo/internal/db"
+	"github.com/YuarenArt/marketgo/internal/db/dbtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Тесты на dbtest.Store не требуют PostgreSQL: go test -short -run Unit ./internal/server/services/
+
+func TestUnitAuthFlow(t *testing.T) {
+	ctx := context.Background()
+	authService := NewAuthService(dbtest.NewStore(), secret, 0, testBcryptCost)
+	input := InputUserInfo{Login: "unituser", Password: "password123"}
+
+	user, err := authService.Register(ctx, input)
+	require.NoError(t, err)
+	assert.Equal(t, db.RoleUser, user.Role)
+
+	_, err = authService.Register(ctx, input)
+	assert.ErrorIs(t, err, db.ErrLoginTaken)
+
+	_, err = authService.Authenticate(ctx, InputUserInfo{Login: input.Login, Password: "wrong-password"})
+	assert.ErrorIs(t, err, bcrypt.ErrMismatchedHashAndPassword)
+
+	pair, err := authService.Authenticate(ctx, input)
+	require.NoError(t, err)
+	userID, err := authService.ValidateToken(ctx, pair.Token)
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, userID)
+
+	refreshed, err := authService.Refresh(ctx, pair.RefreshToken)
+	require.NoError(t, err)
+	_, err = authService.Refresh(ctx, pair.RefreshToken)
+	assert.ErrorIs(t, err, db.ErrRefreshTokenRevoked, "refresh token is single-use")
+
+	require.NoError(t, authService.Logout(ctx, refreshed.Token, refreshed.RefreshToken))
+	_, err = authService.ValidateToken(ctx, refreshed.Token)
+	assert.Error(t, err, "logged out token is rejected")
+}
+
+func TestUnitAdService(t *testing.T) {
+	ctx := context.Background()
+	store := dbtest.NewStore()
+	adService := NewAdService(store)
+
+	owner, err := store.CreateUser(ctx, "unitowner", "hash")
+	require.NoError(t, err)
+	other, err := store.CreateUser(ctx, "unitother", "hash")
+	require.NoError(t, err)
+
+	for _, req := range []CreateAdRequest{
+		{Title: "Велосипед", Text: "Горный", ImageURL: "http://example.com/1.jpg", Price: 300},
+		{Title: "Самокат", Text: "Детский", ImageURL: "http://example.com/2.jpg", Price: 100},
+		{Title: "Ролики", Text: "Размер 40", ImageURL: "http://example.com/3.jpg", Price: 200},
+	} {
+		_, err := adService.CreateAd(ctx, req, owner.ID)
+		require.NoError(t, err)
+	}
+
+	t.Run("list is sorted and marks own ads", func(t *testing.T) {
+		ads, err := adService.GetAds(ctx, GetAdsRequest{Page: 1, PageSize: 10, SortBy: "price", SortOrder: "ASC"}, owner.ID)
+		require.NoError(t, err)
+		require.Len(t, ads, 3)
+		assert.Equal(t, []int64{100, 200, 300}, []int64{ads[0].Price, ads[1].Price, ads[2].Price})
+		assert.True(t, ads[0].IsMine)
+		assert.Equal(t, owner.Login, ads[0].Author)
+	})
+
+	t.Run("cursor pages cover the feed", func(t *testing.T) {
+		req := GetAdsRequest{PageSize: 2, SortBy: "price", SortOrder: "DESC"}
+		first, err := adService.GetAdsAfterCursor(ctx, req, other.ID)
+		require.NoError(t, err)
+		require.Len(t, first.Ads, 2)
+		require.NotEmpty(t, first.NextCursor)
+
+		req.Cursor = first.NextCursor
+		second, err := adService.GetAdsAfterCursor(ctx, req, other.ID)
+		require.NoError(t, err)
+		require.Len(t, second.Ads, 1)
+		assert.Equal(t, int64(100), second.Ads[0].Price)
+		assert.Empty(t, second.NextCursor)
+	})
+
+	t.Run("status change notifies the owner", func(t *testing.T) {
+		_, err := adService.SetAdStatus(ctx, 1, UpdateAdStatusRequest{Status: db.AdStatusSold}, other.ID)
+		assert.ErrorIs(t, err, db.ErrNotAdOwner)
+
+		ad, err := adService.SetAdStatus(ctx, 1, UpdateAdStatusRequest{Status: db.AdStatusSold}, owner.ID)
+		require.NoError(t, err)
+		assert.Equal(t, db.AdStatusSold, ad.Status)
+
+		notifications := store.Notifications(owner.ID)
+		require.Len(t, notifications, 1)
+		var payload AdStatusChangedPayload
+		require.NoError(t, json.Unmarshal(notifications[0].Payload, &payload))
+		assert.Equal(t, AdStatusChangedPayload{AdID: 1, Title: "Велосипед", Status: db.AdStatusSold}, payload)
+	})
+
+	t.Run("search uses the storage", func(t *testing.T) {
+		ads, err := adService.GetAds(ctx, GetAdsRequest{Page: 1, PageSize: 10, Query: "детский"}, other.ID)
+		require.NoError(t, err)
+		require.Len(t, ads, 1)
+		assert.Equal(t, "Самокат", ads[0].Title)
+	})
+}
+
+func TestUnitAdTags(t *testing.T) {
+	ctx := context.Background()
+	store := dbtest.NewStore()
+	adService := NewAdService(store)
+	adService.UseAdsCache(time.Minute, nil)
+
+	owner, err := store.CreateUser(ctx, "tagowner", "hash")
+	require.NoError(t, err)
+
+	for _, req := range []CreateAdRequest{
+		{Title: "Велосипед", Text: "Горный", Price: 300, Tags: []string{"Торг", "самовывоз", "ТОРГ"}},
+		{Title: "Самокат", Text: "Детский", Price: 100, Tags: []string{"торг"}},
+		{Title: "Ролики", Text: "Размер 40", Price: 200},
+	} {
+		_, err := adService.CreateAd(ctx, req, owner.ID)
+		require.NoError(t, err)
+	}
+
+	titles := func(ads []db.Ad) []string {
+		result := make([]string, 0, len(ads))
+		for _, ad := range ads {
+			result = append(result, ad.Title)
+		}
+		return result
+	}
+
+	t.Run("tags are normalized", func(t *testing.T) {
+		ad, err := adService.GetAd(ctx, 1, owner.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"торг", "самовывоз"}, ad.Tags)
+	})
+
+	t.Run("filter composes with price and sort and is part of the cache key", func(t *testing.T) {
+		req := GetAdsRequest{Page: 1, PageSize: 10, SortBy: "price", SortOrder: "DESC"}
+		all, err := adService.GetAds(ctx, req, owner.ID)
+		require.NoError(t, err)
+		assert.Len(t, all, 3)
+
+		req.Tags = []string{"торг"}
+		tagged, err := adService.GetAdsWithMeta(ctx, req, owner.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Велосипед", "Самокат"}, titles(tagged.Items))
+		assert.Equal(t, 2, tagged.Total)
+
+		req.Tags = []string{"торг", "Самовывоз"}
+		both, err := adService.GetAds(ctx, req, owner.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Велосипед"}, titles(both))
+
+		req.Tags, req.MaxPrice = []string{"торг"}, 150
+		cheap, err := adService.GetAds(ctx, req, owner.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Самокат"}, titles(cheap))
+	})
+
+	t.Run("update replaces tags and names an invalid one", func(t *testing.T) {
+		tags := []string{"доставка", strings.Repeat("я", 31)}
+		_, err := adService.UpdateAd(ctx, 3, UpdateAdRequest{Tags: &tags}, owner.ID)
+		assert.ErrorIs(t, err, db.ErrInvalidTag)
+		assert.Contains(t, db.Message(err), strings.Repeat("я", 31))
+
+		tags = []string{"Доставка"}
+		ad, err := adService.UpdateAd(ctx, 3, UpdateAdRequest{Tags: &tags}, owner.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"доставка"}, ad.Tags)
+
+		ads, err := adService.GetAds(ctx, GetAdsRequest{Page: 1, PageSize: 10, Tags: []string{"доставка"}}, owner.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Ролики"}, titles(ads))
+	})
+}
+
+func TestUnitAdCity(t *testing.T) {
+	ctx := context.Background()
+	store := dbtest.NewStore()
+	adService := NewAdService(store)
+	adService.UseAdsCache(time.Minute, nil)
+
+	owner, err := store.CreateUser(ctx, "cityowner", "hash")
+	require.NoError(t, err)
+
+	for _, req := range []CreateAdRequest{
+		{Title: "Велосипед", Text: "Горный", Price: 300, City: " Москва "},
+		{Title: "Самокат", Text: "Детский", Price: 100, City: "Казань"},
+		{Title: "Ролики", Text: "Размер 40", Price: 200},
+	} {
+		_, err := adService.CreateAd(ctx, req, owner.ID)
+		require.NoError(t, err)
+	}
+
+	titles := func(ads []db.Ad) []string {
+		result := make([]string, 0, len(ads))
+		for _, ad := range ads {
+			result = append(result, ad.Title)
+		}
+		return result
+	}
+
+	t.Run("city is trimmed", func(t *testing.T) {
+		ad, err := adService.GetAd(ctx, 1, owner.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Москва", ad.City)
+	})
+
+	t.Run("filter ignores case and is part of the cache key", func(t *testing.T) {
+		req := GetAdsRequest{Page: 1, PageSize: 10, SortBy: "price", SortOrder: "ASC"}
+		all, err := adService.GetAds(ctx, req, owner.ID)
+		require.NoError(t, err)
+		assert.Len(t, all, 3)
+
+		req.Cities = []string{"МОСКВА"}
+		moscow, err := adService.GetAdsWithMeta(ctx, req, owner.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Велосипед"}, titles(moscow.Items))
+		assert.Equal(t, 1, moscow.Total)
+
+		req.Cities = []string{"москва", "казань"}
+		both, err := adService.GetAds(ctx, req, owner.ID)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"Самокат", "Велосипед"}, titles(both))
+	})
+
+	t.Run("update replaces and clears city", func(t *testing.T) {
+		city := strings.Repeat("я", db.MaxCityLength+1)
+		_, err := adService.UpdateAd(ctx, 2, UpdateAdRequest{City: &city}, owner.ID)
+		assert.ErrorIs(t, err, db.ErrInvalidCity)
+
+		city = ""
+		ad, err := adService.UpdateAd(ctx, 2, UpdateAdRequest{City: &city}, owner.ID)
+		require.NoError(t, err)
+		assert.Empty(t, ad.City)
+
+		ads, err := adService.GetAds(ctx, GetAdsRequest{Page: 1, PageSize: 10, Cities: []string{"казань"}}, owner.ID)
+		require.NoError(t, err)
+		assert.Empty(t, ads)
+	})
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultSuggestLimit = 8
+	MaxSuggestLimit     = 20
+	MinSuggestRunes     = 2
+	SuggestCacheTTL     = 30 * time.Second
+	// SuggestCacheSize - сколько префиксов хранит кэш подсказок; префиксы задают клиенты, поэтому кэш ограничен
+	SuggestCacheSize = 1000
+)
+
+// suggestEntry хранит закэшированные подсказки и время их устаревания
+type suggestEntry struct {
+	words     []string
+	expiresAt time.Time
+}
+
+// suggestCache - потокобезопасный кэш подсказок с TTL, ключ - нормализованный префикс.
+// Хранит не больше size префиксов.
+type suggestCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	size    int
+	now     func() time.Time
+	entries map[string]suggestEntry
+}
+
+// newSuggestCache создает кэш подсказок с заданным TTL и размером
+func newSuggestCache(ttl time.Duration, size int) *suggestCache {
+	return &suggestCache{
+		ttl:     ttl,
+		size:    size,
+		now:     time.Now,
+		entries: make(map[string]suggestEntry),
+	}
+}
+
+// get возвращает подсказки по префиксу, если они есть и не устарели
+func (c *suggestCache) get(prefix string) ([]string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[prefix]
+	if !ok {
+		return nil, false
+	}
+	if !c.now().Before(entry.expiresAt) {
+		delete(c.entries, prefix)
+		return nil, false
+	}
+	return entry.words, true
+}
+
+// set сохраняет подсказки по префиксу. Если кэш заполнен, удаляются устаревшие записи,
+// а если их нет - запись, которая устареет раньше остальных.
+func (c *suggestCache) set(prefix string, words []string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if _, ok := c.entries[prefix]; !ok && len(c.entries) >= c.size {
+		c.evict(now)
+	}
+	c.entries[prefix] = suggestEntry{words: words, expiresAt: now.Add(c.ttl)}
+}
+
+// evict освобождает место в заполненном кэше; вызывается под мьютексом
+func (c *suggestCache) evict(now time.Time) {
+	oldest := ""
+	var oldestAt time.Time
+	for prefix, entry := range c.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(c.entries, prefix)
+			continue
+		}
+		if oldest == "" || entry.expiresAt.Before(oldestAt) {
+			oldest, oldestAt = prefix, entry.expiresAt
+		}
+	}
+	if len(c.entries) >= c.size {
+		delete(c.entries, oldest)
+	}
+}
+
+// normalizeSuggestPrefix приводит префикс к виду, используемому в запросе и ключе кэша
+func normalizeSuggestPrefix(q string) string {
+	return strings.ToLower(strings.Join(strings.Fields(q), " "))
+}
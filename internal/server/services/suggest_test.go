@@ -0,0 +1,91 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/YuarenArt/marketgo/internal/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSuggest(t *testing.T) {
+	adService := NewAdService(testDB)
+
+	err := clearTables(testCtx, testDB)
+	require.NoError(t, err)
+
+	user, err := testDB.CreateUser(testCtx, "suggester", "pass")
+	require.NoError(t, err)
+
+	createAd := func(title string) {
+		_, err := testDB.CreateAd(testCtx, db.Ad{Title: title, Text: "text", Price: 100, UserID: user.ID})
+		require.NoError(t, err)
+	}
+	createAd("Велосипед детский")
+	createAd("Велосипед горный")
+	createAd("Веник")
+
+	t.Run("short query returns empty list", func(t *testing.T) {
+		words, err := adService.Suggest(testCtx, "в", DefaultSuggestLimit)
+		require.NoError(t, err)
+		assert.NotNil(t, words)
+		assert.Empty(t, words)
+
+		_, cached := adService.suggest.get("в")
+		assert.False(t, cached)
+	})
+
+	t.Run("cyrillic prefix is normalized", func(t *testing.T) {
+		words, err := adService.Suggest(testCtx, "  ВЕ ", DefaultSuggestLimit)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"велосипед", "веник"}, words)
+	})
+
+	t.Run("limit is applied to cached results", func(t *testing.T) {
+		words, err := adService.Suggest(testCtx, "ве", 1)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"велосипед"}, words)
+	})
+
+	t.Run("cached results are served until ttl expires", func(t *testing.T) {
+		now := time.Now()
+		adService.suggest.now = func() time.Time { return now }
+
+		words, err := adService.Suggest(testCtx, "ве", DefaultSuggestLimit)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"велосипед", "веник"}, words)
+
+		createAd("Веник березовый")
+		createAd("Веник дубовый")
+
+		words, err = adService.Suggest(testCtx, "ве", DefaultSuggestLimit)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"велосипед", "веник"}, words)
+
+		now = now.Add(SuggestCacheTTL)
+		words, err = adService.Suggest(testCtx, "ве", DefaultSuggestLimit)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"веник", "велосипед"}, words)
+	})
+
+	t.Run("cache keeps at most size prefixes", func(t *testing.T) {
+		now := time.Now()
+		cache := newSuggestCache(SuggestCacheTTL, 2)
+		cache.now = func() time.Time { return now }
+
+		cache.set("ве", []string{"велосипед"})
+		now = now.Add(time.Second)
+		cache.set("ди", []string{"диван"})
+		now = now.Add(time.Second)
+		cache.set("са", []string{"самокат"})
+		assert.Len(t, cache.entries, 2)
+		_, cached := cache.get("ве")
+		assert.False(t, cached, "entry expiring first is evicted")
+
+		now = now.Add(SuggestCacheTTL)
+		cache.set("ро", []string{"ролики"})
+		assert.Equal(t, map[string]suggestEntry{"ро": {words: []string{"ролики"}, expiresAt: now.Add(SuggestCacheTTL)}}, cache.entries,
+			"expired entries are swept when the cache is full")
+	})
+}
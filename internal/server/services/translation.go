@@ -0,0 +1,104 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/YuarenArt/marketgo/internal/db"
+)
+
+// AdTranslationRequest - перевод заголовка и текста объявления
+type AdTranslationRequest struct {
+	Title string `json:"title" binding:"required,ad_title"`
+	Text  string `json:"text" binding:"required,ad_text"`
+}
+
+// ParseAcceptLanguage разбирает заголовок Accept-Language и возвращает коды языков
+// в порядке убывания q-веса. Региональные подтеги отбрасываются ("en-US" -> "en"),
+// "*" и языки с q=0 пропускаются, повторы удаляются с сохранением наибольшего веса.
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		lang string
+		q    float64
+	}
+
+	var langs []weighted
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+		tag := strings.ToLower(strings.TrimSpace(fields[0]))
+		if i := strings.IndexAny(tag, "-_"); i >= 0 {
+			tag = tag[:i]
+		}
+		if len(tag) != 2 || tag[0] < 'a' || tag[0] > 'z' || tag[1] < 'a' || tag[1] > 'z' {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if v, ok := strings.CutPrefix(param, "q="); ok {
+				parsed, err := strconv.ParseFloat(v, 64)
+				if err != nil || parsed < 0 || parsed > 1 {
+					parsed = 0
+				}
+				q = parsed
+			}
+		}
+		if q == 0 || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		langs = append(langs, weighted{lang: tag, q: q})
+	}
+
+	sort.SliceStable(langs, func(i, j int) bool { return langs[i].q > langs[j].q })
+
+	result := make([]string, 0, len(langs))
+	for _, l := range langs {
+		result = append(result, l.lang)
+	}
+	return result
+}
+
+// translateAds подменяет заголовок и текст объявлений первым подходящим переводом из languages.
+// Если подходящего перевода нет, объявление остаётся на языке оригинала.
+func (s *AdService) translateAds(ctx context.Context, ads []db.Ad, languages []string) ([]db.Ad, error) {
+	if len(ads) == 0 || len(languages) == 0 {
+		return ads, nil
+	}
+
+	ids := make([]int, 0, len(ads))
+	for _, ad := range ads {
+		ids = append(ids, ad.ID)
+	}
+	translations, err := s.db.AdTranslations(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range ads {
+		for _, lang := range languages {
+			if tr, ok := translations[ads[i].ID][lang]; ok {
+				ads[i].Title = tr.Title
+				ads[i].Text = tr.Text
+				ads[i].Lang = lang
+				break
+			}
+		}
+	}
+	return ads, nil
+}
+
+func toDBTranslations(reqs map[string]AdTranslationRequest) map[string]db.AdTranslation {
+	if len(reqs) == 0 {
+		return nil
+	}
+	translations := make(map[string]db.AdTranslation, len(reqs))
+	for lang, tr := range reqs {
+		translations[lang] = db.AdTranslation{Title: tr.Title, Text: tr.Text}
+	}
+	return translations
+}
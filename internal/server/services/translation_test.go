@@ -0,0 +1,111 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/YuarenArt/marketgo/internal/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   []string
+	}{
+		{"empty header", "", []string{}},
+		{"single language", "ru", []string{"ru"}},
+		{"regional subtags are reduced", "en-US,en;q=0.9", []string{"en"}},
+		{"sorted by q", "de;q=0.5, fi, en;q=0.8", []string{"fi", "en", "de"}},
+		{"equal q keeps header order", "kk;q=0.7, ru;q=0.7", []string{"kk", "ru"}},
+		{"wildcard and q=0 are skipped", "*, fr;q=0, uk;q=0.3", []string{"uk"}},
+		{"malformed entries are skipped", "english, ru-RU;q=abc, e1, ;q=1, kk", []string{"kk"}},
+		{"case insensitive", "RU-ru", []string{"ru"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ParseAcceptLanguage(tt.header))
+		})
+	}
+}
+
+func TestGetAdsTranslations(t *testing.T) {
+	adService := NewAdService(testDB)
+
+	err := clearTables(testCtx, testDB)
+	require.NoError(t, err)
+
+	user, err := testDB.CreateUser(testCtx, "translator", "pass")
+	require.NoError(t, err)
+
+	ad, err := adService.CreateAd(testCtx, CreateAdRequest{
+		Title:    "Велосипед",
+		Text:     "Почти новый",
+		ImageURL: "https://example.com/bike.png",
+		Price:    1000,
+		Translations: map[string]AdTranslationRequest{
+			"kk": {Title: "Велосипед сатылады", Text: "Жаңа дерлік"},
+			"en": {Title: "Bicycle", Text: "Almost new"},
+		},
+	}, user.ID)
+	require.NoError(t, err)
+	assert.Len(t, ad.Translations, 2)
+
+	fetch := func(t *testing.T, header string) db.Ad {
+		t.Helper()
+		ads, err := adService.GetAds(testCtx, GetAdsRequest{
+			Page:      1,
+			PageSize:  10,
+			Languages: ParseAcceptLanguage(header),
+		}, user.ID)
+		require.NoError(t, err)
+		require.Len(t, ads, 1)
+		return ads[0]
+	}
+
+	t.Run("best translation by q-value", func(t *testing.T) {
+		got := fetch(t, "en;q=0.5, kk;q=0.9")
+		assert.Equal(t, "kk", got.Lang)
+		assert.Equal(t, "Велосипед сатылады", got.Title)
+		assert.Equal(t, "Жаңа дерлік", got.Text)
+	})
+
+	t.Run("falls through missing languages", func(t *testing.T) {
+		got := fetch(t, "de, en-GB;q=0.8")
+		assert.Equal(t, "en", got.Lang)
+		assert.Equal(t, "Bicycle", got.Title)
+	})
+
+	t.Run("original when nothing matches", func(t *testing.T) {
+		got := fetch(t, "fr, de;q=0.5")
+		assert.Empty(t, got.Lang)
+		assert.Equal(t, "Велосипед", got.Title)
+		assert.Equal(t, "Почти новый", got.Text)
+	})
+
+	t.Run("original without accept-language", func(t *testing.T) {
+		got := fetch(t, "")
+		assert.Empty(t, got.Lang)
+		assert.Equal(t, "Велосипед", got.Title)
+	})
+
+	t.Run("invalid translations are rejected", func(t *testing.T) {
+		req := CreateAdRequest{Title: "Bike", Text: "text", ImageURL: "https://example.com/a.png", Price: 100}
+
+		req.Translations = map[string]AdTranslationRequest{"english": {Title: "Bike", Text: "text"}}
+		_, err := adService.CreateAd(testCtx, req, user.ID)
+		assert.ErrorIs(t, err, db.ErrInvalidLang)
+
+		req.Translations = map[string]AdTranslationRequest{"en": {Title: "B", Text: "text"}}
+		_, err = adService.CreateAd(testCtx, req, user.ID)
+		assert.ErrorIs(t, err, db.ErrInvalidTitleLength)
+	})
+
+	t.Run("suggest matches translated titles", func(t *testing.T) {
+		words, err := testDB.SuggestTitleWords(testCtx, "bic", 10)
+		require.NoError(t, err)
+		assert.Contains(t, words, "bicycle")
+	})
+}
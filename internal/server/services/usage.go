@@ -0,0 +1,202 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/YuarenArt/marketgo/internal/db"
+	"github.com/YuarenArt/marketgo/pkg/logging"
+)
+
+const (
+	DefaultDailyQuota  = 10_000
+	UsageFlushInterval = time.Minute
+	DefaultUsageDays   = 30
+	MaxUsageDays       = 90
+
+	usageShards = 32
+)
+
+// Quota - состояние дневной квоты пользователя для заголовков X-RateLimit-*
+type Quota struct {
+	Limit     int64
+	Remaining int64
+	Reset     time.Time
+}
+
+// UsageResponse - использование API пользователем по дням и действующий лимит
+type UsageResponse struct {
+	DailyLimit int64         `json:"daily_limit"`
+	Days       []db.UsageDay `json:"days"`
+}
+
+type usageKey struct {
+	userID int
+	day    time.Time
+}
+
+// usageTotal - количество запросов пользователя за день с учётом уже сохранённых в БД
+type usageTotal struct {
+	day   time.Time
+	count int64
+}
+
+type usageShard struct {
+	mu      sync.Mutex
+	totals  map[int]usageTotal
+	pending map[usageKey]int64
+}
+
+// UsageService считает запросы пользователей к API по дням.
+// Счётчики агрегируются в памяти по шардам и периодически сбрасываются в таблицу usage_daily.
+// Квота мягкая: превышение отражается в заголовках, но запросы не отклоняются.
+type UsageService struct {
+	db    *db.DBService
+	limit int64
+	now   func() time.Time
+
+	shards [usageShards]usageShard
+}
+
+// NewUsageService создает новый экземпляр UsageService.
+// limit - дневная квота запросов; нулевое значение заменяется на DefaultDailyQuota.
+func NewUsageService(db *db.DBService, limit int64) *UsageService {
+	if limit <= 0 {
+		limit = DefaultDailyQuota
+	}
+	s := &UsageService{db: db, limit: limit, now: time.Now}
+	for i := range s.shards {
+		s.shards[i].totals = make(map[int]usageTotal)
+		s.shards[i].pending = make(map[usageKey]int64)
+	}
+	return s
+}
+
+// Record учитывает запрос пользователя userID и возвращает состояние его квоты.
+// Обращение к БД происходит только при первом запросе пользователя за день в этом процессе.
+func (s *UsageService) Record(ctx context.Context, userID int) Quota {
+	day := usageDay(s.now())
+	shard := &s.shards[uint(userID)%usageShards]
+
+	shard.mu.Lock()
+	total, ok := shard.totals[userID]
+	shard.mu.Unlock()
+
+	var stored int64
+	if !ok || !total.day.Equal(day) {
+		// Ошибка чтения не должна влиять на запрос: счёт начнётся с несохранённых запросов
+		stored, _ = s.db.UsageOn(ctx, userID, day)
+	}
+
+	shard.mu.Lock()
+	total, ok = shard.totals[userID]
+	if !ok || !total.day.Equal(day) {
+		total = usageTotal{day: day, count: stored}
+	}
+	total.count++
+	shard.totals[userID] = total
+	shard.pending[usageKey{userID: userID, day: day}]++
+	shard.mu.Unlock()
+
+	return s.quota(total.count)
+}
+
+// quota рассчитывает состояние квоты при count запросах за текущий день
+func (s *UsageService) quota(count int64) Quota {
+	remaining := s.limit - count
+	if remaining < 0 {
+		remaining = 0
+	}
+	return Quota{
+		Limit:     s.limit,
+		Remaining: remaining,
+		Reset:     usageDay(s.now()).AddDate(0, 0, 1),
+	}
+}
+
+// Flush сохраняет накопленные счётчики в БД. При ошибке счётчики возвращаются для следующей попытки.
+func (s *UsageService) Flush(ctx context.Context) error {
+	var deltas []db.UsageDelta
+	for i := range s.shards {
+		shard := &s.shards[i]
+		shard.mu.Lock()
+		pending := shard.pending
+		shard.pending = make(map[usageKey]int64)
+		shard.mu.Unlock()
+
+		for key, n := range pending {
+			deltas = append(deltas, db.UsageDelta{UserID: key.userID, Date: key.day, Requests: n})
+		}
+	}
+
+	if err := s.db.AddUsage(ctx, deltas); err != nil {
+		for _, d := range deltas {
+			shard := &s.shards[uint(d.UserID)%usageShards]
+			shard.mu.Lock()
+			shard.pending[usageKey{userID: d.UserID, day: d.Date}] += d.Requests
+			shard.mu.Unlock()
+		}
+		return err
+	}
+	return nil
+}
+
+// Usage возвращает количество запросов пользователя за последние days дней, включая сегодняшний.
+// В ряд входят все дни периода, в том числе без запросов, и ещё не сохранённые счётчики.
+func (s *UsageService) Usage(ctx context.Context, userID, days int) (UsageResponse, error) {
+	to := usageDay(s.now())
+	from := to.AddDate(0, 0, -(days - 1))
+
+	stored, err := s.db.Usage(ctx, userID, from, to)
+	if err != nil {
+		return UsageResponse{}, err
+	}
+	byDay := make(map[time.Time]int64, len(stored))
+	for _, d := range stored {
+		byDay[usageDay(d.Date)] = d.Requests
+	}
+
+	shard := &s.shards[uint(userID)%usageShards]
+	shard.mu.Lock()
+	for key, n := range shard.pending {
+		if key.userID == userID {
+			byDay[key.day] += n
+		}
+	}
+	shard.mu.Unlock()
+
+	series := make([]db.UsageDay, 0, days)
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		series = append(series, db.UsageDay{Date: day, Requests: byDay[day]})
+	}
+	return UsageResponse{DailyLimit: s.limit, Days: series}, nil
+}
+
+// Run сбрасывает счётчики в БД каждые interval и один раз после отмены контекста
+func (s *UsageService) Run(ctx context.Context, interval time.Duration, logger logging.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := s.Flush(flushCtx); err != nil {
+				logger.Error("Final usage flush failed", "error", err)
+			}
+			return
+		case <-ticker.C:
+			if err := s.Flush(ctx); err != nil && ctx.Err() == nil {
+				logger.Error("Usage flush failed", "error", err)
+			}
+		}
+	}
+}
+
+// usageDay возвращает начало дня t по UTC
+func usageDay(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
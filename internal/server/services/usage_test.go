@@ -0,0 +1,106 @@
+package services
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsage(t *testing.T) {
+	err := clearTables(testCtx, testDB)
+	require.NoError(t, err)
+
+	user, err := testDB.CreateUser(testCtx, "heavyuser", "pass")
+	require.NoError(t, err)
+	other, err := testDB.CreateUser(testCtx, "lightuser", "pass")
+	require.NoError(t, err)
+
+	now := time.Date(2024, 3, 10, 18, 30, 0, 0, time.UTC)
+	newService := func() *UsageService {
+		svc := NewUsageService(testDB, 5)
+		svc.now = func() time.Time { return now }
+		return svc
+	}
+	svc := newService()
+
+	t.Run("quota headers", func(t *testing.T) {
+		var quota Quota
+		for i := 0; i < 3; i++ {
+			quota = svc.Record(testCtx, user.ID)
+		}
+		assert.Equal(t, int64(5), quota.Limit)
+		assert.Equal(t, int64(2), quota.Remaining)
+		assert.Equal(t, time.Date(2024, 3, 11, 0, 0, 0, 0, time.UTC), quota.Reset)
+
+		for i := 0; i < 4; i++ {
+			quota = svc.Record(testCtx, user.ID)
+		}
+		assert.Equal(t, int64(0), quota.Remaining, "remaining never goes negative")
+
+		quota = svc.Record(testCtx, other.ID)
+		assert.Equal(t, int64(4), quota.Remaining, "quota is per user")
+	})
+
+	t.Run("concurrent records are all counted", func(t *testing.T) {
+		c := newService()
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				c.Record(testCtx, other.ID)
+			}()
+		}
+		wg.Wait()
+		usage, err := c.Usage(testCtx, other.ID, 1)
+		require.NoError(t, err)
+		require.Len(t, usage.Days, 1)
+		assert.Equal(t, int64(50), usage.Days[0].Requests)
+	})
+
+	t.Run("usage includes unflushed requests", func(t *testing.T) {
+		usage, err := svc.Usage(testCtx, user.ID, 3)
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), usage.DailyLimit)
+		require.Len(t, usage.Days, 3)
+		assert.Equal(t, time.Date(2024, 3, 8, 0, 0, 0, 0, time.UTC), usage.Days[0].Date)
+		assert.Equal(t, int64(0), usage.Days[0].Requests)
+		assert.Equal(t, int64(7), usage.Days[2].Requests)
+	})
+
+	t.Run("flush persists counters and survives restart", func(t *testing.T) {
+		require.NoError(t, svc.Flush(testCtx))
+		stored, err := testDB.UsageOn(testCtx, user.ID, time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC))
+		require.NoError(t, err)
+		assert.Equal(t, int64(7), stored)
+
+		require.NoError(t, svc.Flush(testCtx))
+		stored, err = testDB.UsageOn(testCtx, user.ID, time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC))
+		require.NoError(t, err)
+		assert.Equal(t, int64(7), stored, "repeated flush does not double count")
+
+		restarted := newService()
+		quota := restarted.Record(testCtx, user.ID)
+		assert.Equal(t, int64(0), quota.Remaining, "quota state is restored from the flushed counters")
+		usage, err := restarted.Usage(testCtx, user.ID, 1)
+		require.NoError(t, err)
+		assert.Equal(t, int64(8), usage.Days[0].Requests)
+	})
+
+	t.Run("new day resets quota", func(t *testing.T) {
+		now = now.Add(6 * time.Hour)
+		quota := svc.Record(testCtx, user.ID)
+		assert.Equal(t, int64(4), quota.Remaining)
+		assert.Equal(t, time.Date(2024, 3, 12, 0, 0, 0, 0, time.UTC), quota.Reset)
+
+		require.NoError(t, svc.Flush(testCtx))
+		usage, err := svc.Usage(testCtx, user.ID, 2)
+		require.NoError(t, err)
+		require.Len(t, usage.Days, 2)
+		assert.Equal(t, int64(7), usage.Days[0].Requests)
+		assert.Equal(t, int64(1), usage.Days[1].Requests)
+	})
+}
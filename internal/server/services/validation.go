@@ -0,0 +1,134 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/YuarenArt/marketgo/internal/db"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// adValidators - валидаторы полей объявления с теми же правилами, что и в db,
+// чтобы API и база данных не расходились
+var adValidators = map[string]struct {
+	validate func(string) error
+	err      error
+}{
+	"ad_title":     {db.ValidateTitle, db.ErrInvalidTitleLength},
+	"ad_text":      {db.ValidateText, db.ErrInvalidTextLength},
+	"ad_image_url": {db.ValidateImageURL, db.ErrInvalidImageURL},
+	"ad_city":      {db.ValidateCity, db.ErrInvalidCity},
+}
+
+// FieldError - ошибка проверки одного поля запроса
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+func init() {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+	// В ошибках поля называются так же, как в JSON запроса
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+	v.RegisterStructValidation(validatePriceRange, GetAdsRequest{})
+	for tag, rule := range adValidators {
+		validate := rule.validate
+		err := v.RegisterValidation(tag, func(fl validator.FieldLevel) bool {
+			return validate(fl.Field().String()) == nil
+		})
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// validatePriceRange отклоняет min_price больше max_price; нулевая max_price означает, что граница не задана
+func validatePriceRange(sl validator.StructLevel) {
+	req := sl.Current().Interface().(GetAdsRequest)
+	if req.MaxPrice > 0 && req.MinPrice > req.MaxPrice {
+		sl.ReportError(req.MinPrice, "min_price", "MinPrice", "price_range", "max_price")
+	}
+}
+
+// ValidationMessage возвращает текст ошибки разбора запроса для клиента:
+// для полей объявления - сообщение соответствующей ошибки db, для остальных
+// ошибок проверки - общее сообщение (подробности в FieldErrors), иначе err.Error()
+func ValidationMessage(err error) string {
+	var fieldErrs validator.ValidationErrors
+	if errors.As(err, &fieldErrs) {
+		for _, fieldErr := range fieldErrs {
+			if rule, ok := adValidators[fieldErr.Tag()]; ok {
+				return rule.err.Error()
+			}
+		}
+		return db.ErrMsgInvalid
+	}
+	return err.Error()
+}
+
+// FieldErrors переводит ошибки валидатора в список ошибок полей с сообщениями на русском.
+// Возвращает nil, если err не является ошибкой проверки полей.
+func FieldErrors(err error) []FieldError {
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		return nil
+	}
+	result := make([]FieldError, 0, len(fieldErrs))
+	for _, fieldErr := range fieldErrs {
+		result = append(result, FieldError{
+			Field:   fieldPath(fieldErr),
+			Rule:    fieldErr.Tag(),
+			Message: fieldMessage(fieldErr),
+		})
+	}
+	return result
+}
+
+// fieldPath возвращает путь к полю без имени структуры, например translations[en].title
+func fieldPath(fieldErr validator.FieldError) string {
+	_, path, ok := strings.Cut(fieldErr.Namespace(), ".")
+	if !ok {
+		return fieldErr.Field()
+	}
+	return path
+}
+
+func fieldMessage(fieldErr validator.FieldError) string {
+	if rule, ok := adValidators[fieldErr.Tag()]; ok {
+		return rule.err.Error()
+	}
+	isString := fieldErr.Kind() == reflect.String
+	switch fieldErr.Tag() {
+	case "required":
+		return "обязательное поле"
+	case "min", "gte":
+		if isString {
+			return fmt.Sprintf("должно содержать не менее %s символов", fieldErr.Param())
+		}
+		return fmt.Sprintf("должно быть не меньше %s", fieldErr.Param())
+	case "max", "lte":
+		if isString {
+			return fmt.Sprintf("должно содержать не более %s символов", fieldErr.Param())
+		}
+		return fmt.Sprintf("должно быть не больше %s", fieldErr.Param())
+	case "price_range":
+		return db.ErrMsgInvalidPriceRange
+	case "oneof":
+		return fmt.Sprintf("допустимые значения: %s", strings.Join(strings.Fields(fieldErr.Param()), ", "))
+	default:
+		return "некорректное значение"
+	}
+}
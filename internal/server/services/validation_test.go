@@ -0,0 +1,103 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/YuarenArt/marketgo/internal/db"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnitAdLengthValidators(t *testing.T) {
+	valid := CreateAdRequest{Title: "Велосипед", Text: "Горный", ImageURL: "http://example.com/1.jpg", Price: 300}
+
+	tests := []struct {
+		name    string
+		title   string
+		text    string
+		wantErr bool
+	}{
+		{"60 cyrillic letters title", strings.Repeat("ж", 60), valid.Text, false},
+		{"100 emoji title", strings.Repeat("🚲", 100), valid.Text, false},
+		{"101 cyrillic letters title", strings.Repeat("ж", 101), valid.Text, true},
+		{"one cyrillic letter title", "Я", valid.Text, true},
+		{"padded one letter title", " Я ", valid.Text, true},
+		{"2000 emoji text", valid.Title, strings.Repeat("🚲", 2000), false},
+		{"2001 cyrillic letters text", valid.Title, strings.Repeat("ж", 2001), true},
+		{"whitespace text", valid.Title, "  ", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := valid
+			req.Title, req.Text = tt.title, tt.text
+			createErr := binding.Validator.ValidateStruct(req)
+
+			update := UpdateAdRequest{Title: &tt.title, Text: &tt.text}
+			updateErr := binding.Validator.ValidateStruct(update)
+
+			translation := AdTranslationRequest{Title: tt.title, Text: tt.text}
+			translationErr := binding.Validator.ValidateStruct(translation)
+
+			if tt.wantErr {
+				assert.Error(t, createErr)
+				assert.Error(t, updateErr)
+				assert.Error(t, translationErr)
+			} else {
+				assert.NoError(t, createErr)
+				assert.NoError(t, updateErr)
+				assert.NoError(t, translationErr)
+			}
+		})
+	}
+}
+
+func TestUnitImageURLValidator(t *testing.T) {
+	valid := CreateAdRequest{Title: "Велосипед", Text: "Горный", ImageURL: "https://example.com/1.jpg", Price: 300}
+	assert.NoError(t, binding.Validator.ValidateStruct(valid))
+
+	for _, imageURL := range []string{
+		"example.com/1.jpg",
+		"data:image/png;base64,iVBORw0KGgo=",
+		"javascript:alert(1)",
+		"ftp://example.com/1.jpg",
+		"https://example.com/" + strings.Repeat("a", 181),
+	} {
+		req := valid
+		req.ImageURL = imageURL
+		err := binding.Validator.ValidateStruct(req)
+		if assert.Error(t, err, imageURL) {
+			assert.Equal(t, db.ErrMsgInvalidImageURL, ValidationMessage(err))
+		}
+	}
+}
+
+func TestUnitPriceRangeValidator(t *testing.T) {
+	valid := GetAdsRequest{Page: 1, PageSize: 10}
+
+	for _, tt := range []struct {
+		name     string
+		min, max int64
+		wantErr  bool
+	}{
+		{"no bounds", 0, 0, false},
+		{"only min", 5000, 0, false},
+		{"min equal to max", 300, 300, false},
+		{"min greater than max", 5000, 100, true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			req := valid
+			req.MinPrice, req.MaxPrice = tt.min, tt.max
+			err := binding.Validator.ValidateStruct(req)
+			if !tt.wantErr {
+				assert.NoError(t, err)
+				return
+			}
+			fields := FieldErrors(err)
+			if assert.Len(t, fields, 1) {
+				assert.Equal(t, FieldError{Field: "min_price", Rule: "price_range", Message: db.ErrMsgInvalidPriceRange}, fields[0])
+			}
+		})
+	}
+}
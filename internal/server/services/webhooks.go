@@ -0,0 +1,69 @@
+package services
+
+import (
+	"time"
+
+	"github.com/YuarenArt/marketgo/internal/db"
+	"github.com/YuarenArt/marketgo/pkg/webhook"
+)
+
+// AdEvents принимает события жизненного цикла объявлений; реализуется *webhook.Dispatcher.
+// Publish не должен блокироваться: он вызывается в обработчике запроса.
+type AdEvents interface {
+	Publish(eventType string, data any) error
+}
+
+// AdEventPayload - данные событий ad.created и ad.updated
+type AdEventPayload struct {
+	ID        int       `json:"id"`
+	Title     string    `json:"title"`
+	Text      string    `json:"text"`
+	ImageURL  string    `json:"image_url"`
+	Price     int64     `json:"price"`
+	UserID    int       `json:"user_id"`
+	Author    string    `json:"author"`
+	Status    string    `json:"status"`
+	Tags      []string  `json:"tags,omitempty"`
+	City      string    `json:"city,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// AdDeletedPayload - данные события ad.deleted
+type AdDeletedPayload struct {
+	ID int `json:"id"`
+}
+
+// UseAdEvents включает публикацию событий о создании, изменении и удалении объявлений
+func (s *AdService) UseAdEvents(events AdEvents) {
+	s.events = events
+}
+
+// publishAd публикует событие eventType с объявлением ad; ошибка публикации не влияет на операцию
+func (s *AdService) publishAd(eventType string, ad db.Ad) {
+	if s.events == nil {
+		return
+	}
+	_ = s.events.Publish(eventType, AdEventPayload{
+		ID:        ad.ID,
+		Title:     ad.Title,
+		Text:      ad.Text,
+		ImageURL:  ad.ImageURL,
+		Price:     ad.Price,
+		UserID:    ad.UserID,
+		Author:    ad.Author,
+		Status:    ad.Status,
+		Tags:      ad.Tags,
+		City:      ad.City,
+		CreatedAt: ad.CreatedAt,
+		ExpiresAt: ad.ExpiresAt,
+	})
+}
+
+// publishAdDeleted публикует событие ad.deleted
+func (s *AdService) publishAdDeleted(adID int) {
+	if s.events == nil {
+		return
+	}
+	_ = s.events.Publish(webhook.EventAdDeleted, AdDeletedPayload{ID: adID})
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/YuarenArt/marketgo/internal/db/dbtest"
+	"github.com/YuarenArt/marketgo/pkg/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordedEvent - событие, переданное в AdEvents
+type recordedEvent struct {
+	eventType string
+	data      any
+}
+
+type recordingEvents struct {
+	mu     sync.Mutex
+	events []recordedEvent
+}
+
+func (r *recordingEvents) Publish(eventType string, data any) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, recordedEvent{eventType: eventType, data: data})
+	return nil
+}
+
+func (r *recordingEvents) types() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	types := make([]string, 0, len(r.events))
+	for _, ev := range r.events {
+		types = append(types, ev.eventType)
+	}
+	return types
+}
+
+func TestUnitAdEvents(t *testing.T) {
+	ctx := context.Background()
+	store := dbtest.NewStore()
+	adService := NewAdService(store)
+	events := &recordingEvents{}
+	adService.UseAdEvents(events)
+
+	seller, err := store.CreateUser(ctx, "hookseller", "hash")
+	require.NoError(t, err)
+
+	ad, err := adService.CreateAd(ctx, CreateAdRequest{Title: "Велосипед", Text: "Горный", ImageURL: "http://example.com/1.jpg", Price: 300, City: "Москва"}, seller.ID)
+	require.NoError(t, err)
+	require.Len(t, events.events, 1)
+	payload, ok := events.events[0].data.(AdEventPayload)
+	require.True(t, ok)
+	assert.Equal(t, ad.ID, payload.ID)
+	assert.Equal(t, "Велосипед", payload.Title)
+	assert.Equal(t, "Москва", payload.City)
+	assert.Equal(t, "hookseller", payload.Author)
+
+	price := int64(250)
+	_, err = adService.UpdateAd(ctx, ad.ID, UpdateAdRequest{Price: &price}, seller.ID)
+	require.NoError(t, err)
+	_, err = adService.SetAdStatus(ctx, ad.ID, UpdateAdStatusRequest{Status: "archived"}, seller.ID)
+	require.NoError(t, err)
+	_, err = adService.RenewAd(ctx, ad.ID, seller.ID)
+	require.NoError(t, err)
+
+	_, err = adService.UpdateAd(ctx, ad.ID, UpdateAdRequest{Price: &price}, seller.ID+1)
+	require.Error(t, err, "failed operations publish nothing")
+
+	require.NoError(t, adService.DeleteAd(ctx, ad.ID, seller.ID))
+	assert.Equal(t, []string{
+		webhook.EventAdCreated,
+		webhook.EventAdUpdated,
+		webhook.EventAdUpdated,
+		webhook.EventAdUpdated,
+		webhook.EventAdDeleted,
+	}, events.types())
+	assert.Equal(t, AdDeletedPayload{ID: ad.ID}, events.events[4].data)
+}
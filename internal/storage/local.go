@@ -0,0 +1,94 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Local хранит файлы в каталоге на локальном диске
+type Local struct {
+	dir string
+}
+
+// NewLocal создаёт хранилище в каталоге dir, создавая его при необходимости
+func NewLocal(dir string) (*Local, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("create upload directory: %w", err)
+	}
+	return &Local{dir: dir}, nil
+}
+
+// Save записывает r во временный файл и переименовывает его, чтобы читатели не видели файл недописанным
+func (l *Local) Save(_ context.Context, name string, r io.Reader) error {
+	if !ValidName(name) {
+		return ErrInvalidName
+	}
+
+	tmp, err := os.CreateTemp(l.dir, ".upload-*")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write %s: %w", name, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write %s: %w", name, err)
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return fmt.Errorf("write %s: %w", name, err)
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(l.dir, name)); err != nil {
+		return fmt.Errorf("save %s: %w", name, err)
+	}
+	return nil
+}
+
+// Open открывает файл name для чтения
+func (l *Local) Open(_ context.Context, name string) (File, error) {
+	if !ValidName(name) {
+		return nil, ErrNotFound
+	}
+	file, err := os.Open(filepath.Join(l.dir, name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", name, err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("stat %s: %w", name, err)
+	}
+	return localFile{File: file, modTime: info.ModTime()}, nil
+}
+
+// Delete удаляет файл name; отсутствующий файл не считается ошибкой
+func (l *Local) Delete(_ context.Context, name string) error {
+	if !ValidName(name) {
+		return ErrInvalidName
+	}
+	err := os.Remove(filepath.Join(l.dir, name))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("delete %s: %w", name, err)
+	}
+	return nil
+}
+
+type localFile struct {
+	*os.File
+	modTime time.Time
+}
+
+func (f localFile) ModTime() time.Time { return f.modTime }
+
+var _ Storage = (*Local)(nil)
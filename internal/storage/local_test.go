@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocal(t *testing.T) {
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), "uploads")
+	store, err := NewLocal(dir)
+	require.NoError(t, err)
+
+	t.Run("save and open", func(t *testing.T) {
+		require.NoError(t, store.Save(ctx, "photo.png", strings.NewReader("first")))
+		require.NoError(t, store.Save(ctx, "photo.png", strings.NewReader("second")))
+
+		file, err := store.Open(ctx, "photo.png")
+		require.NoError(t, err)
+		defer file.Close()
+		data, err := io.ReadAll(file)
+		require.NoError(t, err)
+		assert.Equal(t, "second", string(data))
+		assert.False(t, file.ModTime().IsZero())
+
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Len(t, entries, 1, "temp files must not remain")
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		require.NoError(t, store.Save(ctx, "old.png", strings.NewReader("old")))
+		require.NoError(t, store.Delete(ctx, "old.png"))
+		_, err := store.Open(ctx, "old.png")
+		assert.ErrorIs(t, err, ErrNotFound)
+
+		assert.NoError(t, store.Delete(ctx, "old.png"), "missing file is not an error")
+		assert.ErrorIs(t, store.Delete(ctx, "../old.png"), ErrInvalidName)
+	})
+
+	t.Run("unknown file", func(t *testing.T) {
+		_, err := store.Open(ctx, "missing.png")
+		assert.ErrorIs(t, err, ErrNotFound)
+	})
+
+	t.Run("path traversal is rejected", func(t *testing.T) {
+		for _, name := range []string{"../escape.png", "a/b.png", "", ".hidden", "a.b.c"} {
+			assert.ErrorIs(t, store.Save(ctx, name, strings.NewReader("x")), ErrInvalidName, name)
+			_, err := store.Open(ctx, name)
+			assert.ErrorIs(t, err, ErrNotFound, name)
+		}
+		assert.NoFileExists(t, filepath.Join(filepath.Dir(dir), "escape.png"))
+	})
+}
@@ -0,0 +1,40 @@
+// Package storage содержит интерфейс хранилища загруженных файлов и его реализации.
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"regexp"
+	"time"
+)
+
+var (
+	// ErrNotFound - файла с таким именем нет в хранилище
+	ErrNotFound = errors.New("file not found")
+	// ErrInvalidName - имя файла содержит недопустимые символы, например разделители путей
+	ErrInvalidName = errors.New("invalid file name")
+)
+
+// namePattern - допустимые имена файлов: латинские буквы, цифры, "-" и "_" с необязательным расширением
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+(\.[A-Za-z0-9]+)?$`)
+
+// ValidName сообщает, можно ли сохранить файл под именем name
+func ValidName(name string) bool {
+	return len(name) <= 128 && namePattern.MatchString(name)
+}
+
+// File - открытый файл хранилища
+type File interface {
+	io.ReadSeekCloser
+	ModTime() time.Time
+}
+
+// Storage хранит загруженные файлы по именам.
+// Save перезаписывает существующий файл; Open возвращает ErrNotFound для неизвестного имени;
+// Delete неизвестного имени не считается ошибкой.
+type Storage interface {
+	Save(ctx context.Context, name string, r io.Reader) error
+	Open(ctx context.Context, name string) (File, error)
+	Delete(ctx context.Context, name string) error
+}
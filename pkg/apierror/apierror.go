@@ -0,0 +1,32 @@
+// Package apierror описывает тело ответа API с ошибкой, общее для сервера и клиента
+package apierror
+
+// Коды ошибок в поле code. Клиенты ветвятся по коду, а не по тексту сообщения.
+const (
+	CodeInvalidInput      = "invalid_input"
+	CodeWeakPassword      = "weak_password"
+	CodeUnauthorized      = "unauthorized"
+	CodeForbidden         = "forbidden"
+	CodeNotFound          = "not_found"
+	CodeConflict          = "conflict"
+	CodeReplayDetected    = "replay_detected"
+	CodeBodyTooLarge      = "body_too_large"
+	CodeExportTooLarge    = "export_too_large"
+	CodeRateLimited       = "rate_limited"
+	CodeChallengeRequired = "challenge_required"
+	CodeInternal          = "internal"
+	CodeUnavailable       = "unavailable"
+	CodeTimeout           = "timeout"
+)
+
+// Error - тело ответа с ошибкой. Details содержит дополнительные поля ошибки,
+// например field и reason для weak_password или challenge и site_key для challenge_required.
+type Error struct {
+	Code    string         `json:"code"`
+	Message string         `json:"message"`
+	Details map[string]any `json:"details,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Code + ": " + e.Message
+}
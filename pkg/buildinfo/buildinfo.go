@@ -0,0 +1,33 @@
+// Package buildinfo хранит сведения о сборке, которые задаются при компиляции через -ldflags:
+//
+//	go build -ldflags "-X github.com/YuarenArt/marketgo/pkg/buildinfo.Version=v1.2.0 \
+//		-X github.com/YuarenArt/marketgo/pkg/buildinfo.Commit=$(git rev-parse --short HEAD) \
+//		-X github.com/YuarenArt/marketgo/pkg/buildinfo.BuildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+package buildinfo
+
+import "runtime"
+
+// Значения по умолчанию используются при сборке без -ldflags, например в go run и тестах
+var (
+	Version   = "dev"
+	Commit    = "unknown"
+	BuildDate = "unknown"
+)
+
+// Info - сведения о сборке бинарника
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"build_date"`
+	GoVersion string `json:"go_version"`
+}
+
+// Get возвращает сведения о текущей сборке
+func Get() Info {
+	return Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildDate: BuildDate,
+		GoVersion: runtime.Version(),
+	}
+}
@@ -0,0 +1,187 @@
+// Package compress сжимает HTTP-ответы gzip с учётом Accept-Encoding клиента
+package compress
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Options задаёт параметры сжатия ответов
+type Options struct {
+	// Level - уровень сжатия compress/gzip; некорректное значение заменяется gzip.DefaultCompression
+	Level int
+	// MinSize - ответы короче MinSize байт отправляются без сжатия
+	MinSize int
+	// ExcludedPrefixes - префиксы путей, ответы на которые никогда не сжимаются
+	ExcludedPrefixes []string
+}
+
+// Gzip возвращает middleware, сжимающее ответы, если клиент принимает gzip.
+// Ответ буферизуется, пока не наберёт MinSize байт: короткие ответы уходят как есть,
+// а длинные сжимаются. Уже сжатые ответы (с Content-Encoding или application/gzip) не меняются.
+func Gzip(opts Options) gin.HandlerFunc {
+	level := opts.Level
+	if level < gzip.HuffmanOnly || level > gzip.BestCompression {
+		level = gzip.DefaultCompression
+	}
+	pool := &sync.Pool{New: func() any {
+		zw, _ := gzip.NewWriterLevel(io.Discard, level)
+		return zw
+	}}
+
+	return func(c *gin.Context) {
+		for _, prefix := range opts.ExcludedPrefixes {
+			if strings.HasPrefix(c.Request.URL.Path, prefix) {
+				c.Next()
+				return
+			}
+		}
+
+		c.Writer.Header().Add("Vary", "Accept-Encoding")
+		if !AcceptsGzip(c.GetHeader("Accept-Encoding")) {
+			c.Next()
+			return
+		}
+
+		w := &gzipWriter{ResponseWriter: c.Writer, pool: pool, minSize: opts.MinSize}
+		c.Writer = w
+		defer w.finish()
+		c.Next()
+	}
+}
+
+// AcceptsGzip сообщает, разрешает ли заголовок Accept-Encoding ответ в gzip.
+// Учитываются q-веса: "gzip;q=0" и "identity" без gzip запрещают сжатие, "*" разрешает его.
+func AcceptsGzip(acceptEncoding string) bool {
+	gzipQ, anyQ := -1.0, -1.0
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if name, value, ok := strings.Cut(strings.TrimSpace(params), "="); ok && strings.TrimSpace(name) == "q" {
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		switch strings.ToLower(strings.TrimSpace(coding)) {
+		case "gzip", "x-gzip":
+			gzipQ = q
+		case "*":
+			anyQ = q
+		}
+	}
+	if gzipQ >= 0 {
+		return gzipQ > 0
+	}
+	return anyQ > 0
+}
+
+// gzipWriter накапливает первые minSize байт ответа и по ним решает, сжимать ли ответ
+type gzipWriter struct {
+	gin.ResponseWriter
+	pool    *sync.Pool
+	minSize int
+	buf     []byte
+	decided bool
+	zw      *gzip.Writer
+}
+
+func (w *gzipWriter) Write(data []byte) (int, error) {
+	if w.decided {
+		return w.write(data)
+	}
+	w.buf = append(w.buf, data...)
+	if len(w.buf) >= w.minSize {
+		if err := w.decide(); err != nil {
+			return 0, err
+		}
+	}
+	return len(data), nil
+}
+
+func (w *gzipWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Written учитывает ещё не отправленный буфер, чтобы внешние middleware не писали ответ повторно
+func (w *gzipWriter) Written() bool {
+	return len(w.buf) > 0 || w.ResponseWriter.Written()
+}
+
+func (w *gzipWriter) Flush() {
+	if !w.decided {
+		_ = w.decide()
+	}
+	if w.zw != nil {
+		_ = w.zw.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// Unwrap возвращает исходный ResponseWriter для http.ResponseController
+func (w *gzipWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *gzipWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if w.decided || len(w.buf) > 0 {
+		return nil, nil, errors.New("compress: response already written")
+	}
+	w.decided = true
+	return w.ResponseWriter.Hijack()
+}
+
+// decide выбирает сжатие по накопленному буферу и отправляет его
+func (w *gzipWriter) decide() error {
+	w.decided = true
+	header := w.Header()
+	if len(w.buf) >= w.minSize && len(w.buf) > 0 && header.Get("Content-Encoding") == "" &&
+		header.Get("Content-Type") != "application/gzip" && bodyAllowed(w.Status()) {
+		header.Set("Content-Encoding", "gzip")
+		header.Del("Content-Length")
+		w.zw = w.pool.Get().(*gzip.Writer)
+		w.zw.Reset(w.ResponseWriter)
+	}
+
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	_, err := w.write(buf)
+	return err
+}
+
+func (w *gzipWriter) write(data []byte) (int, error) {
+	if w.zw != nil {
+		return w.zw.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+// finish отправляет короткий ответ без сжатия или завершает поток gzip
+func (w *gzipWriter) finish() {
+	if !w.decided {
+		_ = w.decide()
+	}
+	if w.zw != nil {
+		_ = w.zw.Close()
+		w.zw.Reset(io.Discard)
+		w.pool.Put(w.zw)
+		w.zw = nil
+	}
+}
+
+func bodyAllowed(status int) bool {
+	return status != http.StatusNoContent && status != http.StatusNotModified && status >= http.StatusOK
+}
@@ -0,0 +1,87 @@
+package compress
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGzip(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	large := strings.Repeat("marketgo ", 200)
+	router := gin.New()
+	router.Use(Gzip(Options{Level: gzip.BestSpeed, MinSize: 1024, ExcludedPrefixes: []string{"/debug/pprof/"}}))
+	router.GET("/small", func(c *gin.Context) { c.String(http.StatusBadRequest, "bad request") })
+	router.GET("/large", func(c *gin.Context) { c.String(http.StatusOK, large) })
+	router.GET("/empty", func(c *gin.Context) { c.AbortWithStatus(http.StatusNotModified) })
+	router.GET("/debug/pprof/heap", func(c *gin.Context) { c.String(http.StatusOK, large) })
+
+	serve := func(path, acceptEncoding string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if acceptEncoding != "" {
+			req.Header.Set("Accept-Encoding", acceptEncoding)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("large response is compressed", func(t *testing.T) {
+		w := serve("/large", "gzip, deflate")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+		assert.Contains(t, w.Header().Values("Vary"), "Accept-Encoding")
+
+		zr, err := gzip.NewReader(w.Body)
+		require.NoError(t, err)
+		body, err := io.ReadAll(zr)
+		require.NoError(t, err)
+		assert.Equal(t, large, string(body))
+	})
+
+	t.Run("small response is not compressed", func(t *testing.T) {
+		w := serve("/small", "gzip")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Empty(t, w.Header().Get("Content-Encoding"))
+		assert.Equal(t, "bad request", w.Body.String())
+	})
+
+	t.Run("empty response is not compressed", func(t *testing.T) {
+		w := serve("/empty", "gzip")
+		assert.Equal(t, http.StatusNotModified, w.Code)
+		assert.Empty(t, w.Header().Get("Content-Encoding"))
+		assert.Zero(t, w.Body.Len())
+	})
+
+	t.Run("profiles are never compressed", func(t *testing.T) {
+		w := serve("/debug/pprof/heap", "gzip")
+		assert.Empty(t, w.Header().Get("Content-Encoding"))
+		assert.Equal(t, large, w.Body.String())
+	})
+
+	for _, acceptEncoding := range []string{"", "identity", "gzip;q=0", "br, identity;q=0.5", "*;q=0"} {
+		t.Run("no gzip for "+acceptEncoding, func(t *testing.T) {
+			w := serve("/large", acceptEncoding)
+			assert.Empty(t, w.Header().Get("Content-Encoding"))
+			assert.Equal(t, large, w.Body.String())
+		})
+	}
+}
+
+func TestAcceptsGzip(t *testing.T) {
+	assert.True(t, AcceptsGzip("gzip"))
+	assert.True(t, AcceptsGzip("deflate, gzip;q=0.5"))
+	assert.True(t, AcceptsGzip("*"))
+	assert.True(t, AcceptsGzip("identity;q=1, *;q=0.1"))
+	assert.False(t, AcceptsGzip(""))
+	assert.False(t, AcceptsGzip("identity"))
+	assert.False(t, AcceptsGzip("gzip;q=0, *"))
+}
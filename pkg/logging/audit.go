@@ -0,0 +1,97 @@
+package logging
+
+import (
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// События журнала аудита
+const (
+	AuditRegister       = "register"
+	AuditLoginSuccess   = "login_success"
+	AuditLoginFailure   = "login_failure"
+	AuditTokenRevoked   = "token_revoked"
+	AuditAdDeleted      = "ad_deleted"
+	AuditAdRemoved      = "ad_removed"
+	AuditAccountDeleted = "account_deleted"
+	AuditRoleChanged    = "role_changed"
+)
+
+// redacted заменяет значения полей, похожих на пароли и токены
+const redacted = "[REDACTED]"
+
+// sensitiveKeys - части имён полей, значения которых не попадают в журнал аудита
+var sensitiveKeys = []string{"password", "token", "secret", "authorization"}
+
+// AuditEvent описывает событие безопасности. UserID и Login заполняются, если известны.
+type AuditEvent struct {
+	Event     string
+	UserID    int
+	Login     string
+	IP        string
+	RequestID string
+	// Details - дополнительные поля, например ad_id; поля с паролями и токенами заменяются на [REDACTED]
+	Details map[string]any
+}
+
+// AuditLogger пишет события безопасности в отдельный журнал строками JSON
+// {timestamp, event, user_id, login, ip, request_id, ...}. Нулевой *AuditLogger ничего не пишет.
+type AuditLogger struct {
+	logger *slog.Logger
+}
+
+// NewAuditLogger создаёт журнал аудита, пишущий в w
+func NewAuditLogger(w io.Writer) *AuditLogger {
+	handler := slog.NewJSONHandler(w, &slog.HandlerOptions{ReplaceAttr: auditAttr})
+	return &AuditLogger{logger: slog.New(handler)}
+}
+
+// Log записывает событие e
+func (a *AuditLogger) Log(e AuditEvent) {
+	if a == nil {
+		return
+	}
+	attrs := make([]any, 0, 8+2*len(e.Details))
+	if e.UserID > 0 {
+		attrs = append(attrs, "user_id", e.UserID)
+	}
+	if e.Login != "" {
+		attrs = append(attrs, "login", e.Login)
+	}
+	attrs = append(attrs, "ip", e.IP, "request_id", e.RequestID)
+	for key, value := range e.Details {
+		attrs = append(attrs, key, value)
+	}
+	a.logger.Info(e.Event, attrs...)
+}
+
+// auditAttr переименовывает служебные поля slog и скрывает чувствительные значения
+func auditAttr(groups []string, attr slog.Attr) slog.Attr {
+	if len(groups) == 0 {
+		switch attr.Key {
+		case slog.TimeKey:
+			attr.Key = "timestamp"
+			return attr
+		case slog.MessageKey:
+			attr.Key = "event"
+			return attr
+		case slog.LevelKey:
+			return slog.Attr{}
+		}
+	}
+	if isSensitive(attr.Key) {
+		return slog.String(attr.Key, redacted)
+	}
+	return attr
+}
+
+func isSensitive(key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return true
+		}
+	}
+	return false
+}
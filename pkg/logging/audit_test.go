@@ -0,0 +1,66 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditLogger(t *testing.T) {
+	var buf bytes.Buffer
+	NewAuditLogger(&buf).Log(AuditEvent{
+		Event:     AuditAdRemoved,
+		UserID:    7,
+		Login:     "admin",
+		IP:        "203.0.113.5",
+		RequestID: "req-1",
+		Details:   map[string]any{"ad_id": 42},
+	})
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "ad_removed", entry["event"])
+	assert.EqualValues(t, 7, entry["user_id"])
+	assert.Equal(t, "admin", entry["login"])
+	assert.Equal(t, "203.0.113.5", entry["ip"])
+	assert.Equal(t, "req-1", entry["request_id"])
+	assert.EqualValues(t, 42, entry["ad_id"])
+	_, err := time.Parse(time.RFC3339Nano, entry["timestamp"].(string))
+	assert.NoError(t, err)
+	assert.NotContains(t, entry, "level")
+	assert.NotContains(t, entry, "msg")
+}
+
+func TestAuditLoggerRedaction(t *testing.T) {
+	var buf bytes.Buffer
+	NewAuditLogger(&buf).Log(AuditEvent{
+		Event: AuditLoginFailure,
+		Login: "alice",
+		Details: map[string]any{
+			"password":      "s3cure-horse7",
+			"refresh_token": "rt-secret",
+			"Authorization": "Bearer jwt-secret",
+			"reason":        "invalid_credentials",
+		},
+	})
+
+	out := buf.String()
+	for _, secret := range []string{"s3cure-horse7", "rt-secret", "jwt-secret"} {
+		assert.NotContains(t, out, secret)
+	}
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "[REDACTED]", entry["password"])
+	assert.Equal(t, "[REDACTED]", entry["refresh_token"])
+	assert.Equal(t, "invalid_credentials", entry["reason"])
+	assert.NotContains(t, entry, "user_id", "unknown user id is omitted")
+}
+
+func TestAuditLoggerDisabled(t *testing.T) {
+	var a *AuditLogger
+	assert.NotPanics(t, func() { a.Log(AuditEvent{Event: AuditRegister}) })
+}
@@ -2,10 +2,12 @@ package logging
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/YuarenArt/marketgo/internal/config"
 )
@@ -17,24 +19,126 @@ type Logger interface {
 	Warn(msg string, keysAndValues ...interface{})
 	Error(msg string, keysAndValues ...interface{})
 	Log(level slog.Level, msg string, keysAndValues ...interface{})
+	// With возвращает логгер, добавляющий keysAndValues к каждой записи
+	With(keysAndValues ...interface{}) Logger
 }
 
+// Форматы записей журнала
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// Option настраивает создаваемый логгер
+type Option func(*options)
+
+type options struct {
+	level  slog.Level
+	format string
+}
+
+// WithLevel задаёт минимальный уровень записей; записи ниже него отбрасываются
+func WithLevel(level slog.Level) Option {
+	return func(o *options) {
+		o.level = level
+	}
+}
+
+// WithFormat задаёт формат записей: FormatJSON или FormatText (удобен при локальной разработке)
+func WithFormat(format string) Option {
+	return func(o *options) {
+		o.format = format
+	}
+}
+
+// WithConfig берёт уровень и формат из LOG_LEVEL и LOG_FORMAT.
+// Некорректные значения игнорируются: их отклоняет Validate при запуске.
+func WithConfig(cfg *config.Config) Option {
+	return func(o *options) {
+		if cfg == nil {
+			return
+		}
+		if level, err := ParseLevel(cfg.LogLevel); err == nil {
+			o.level = level
+		}
+		if format, err := ParseFormat(cfg.LogFormat); err == nil {
+			o.format = format
+		}
+	}
+}
+
+// ParseLevel разбирает уровень журнала: debug, info, warn или error; пустое значение - info
+func ParseLevel(value string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return 0, fmt.Errorf("unknown log level %q, expected debug, info, warn or error", value)
+	}
+}
+
+// ParseFormat разбирает формат журнала: json или text; пустое значение - json
+func ParseFormat(value string) (string, error) {
+	switch format := strings.ToLower(strings.TrimSpace(value)); format {
+	case "", FormatJSON:
+		return FormatJSON, nil
+	case FormatText:
+		return FormatText, nil
+	default:
+		return "", fmt.Errorf("unknown log format %q, expected json or text", value)
+	}
+}
+
+// Validate проверяет LOG_LEVEL и LOG_FORMAT, чтобы опечатка не оставила сервис без нужных записей
+func Validate(cfg *config.Config) error {
+	if _, err := ParseLevel(cfg.LogLevel); err != nil {
+		return fmt.Errorf("invalid LOG_LEVEL: %w", err)
+	}
+	if _, err := ParseFormat(cfg.LogFormat); err != nil {
+		return fmt.Errorf("invalid LOG_FORMAT: %w", err)
+	}
+	return nil
+}
+
+// NewLogger создает логгер, пишущий в stdout, с уровнем и форматом из cfg
 func NewLogger(cfg *config.Config) Logger {
-	return newSlogLogger(os.Stdout)
+	return newSlogLogger(os.Stdout, WithConfig(cfg))
+}
+
+// NewWriterLogger создает логгер, пишущий в w, например в os.Stderr, чтобы не смешивать лог с выводом программы
+func NewWriterLogger(w io.Writer, opts ...Option) Logger {
+	return newSlogLogger(w, opts...)
 }
 
 // NewFileLogger создает логгер, пишущий в файл
-func NewFileLogger(logFile string) Logger {
+func NewFileLogger(logFile string, opts ...Option) Logger {
 	writer := setupFileWriter(logFile)
-	return newSlogLogger(writer)
+	return newSlogLogger(writer, opts...)
 }
 
 type SlogLogger struct {
 	logger *slog.Logger
 }
 
-func newSlogLogger(writer io.Writer) Logger {
-	handler := slog.NewJSONHandler(writer, &slog.HandlerOptions{})
+func newSlogLogger(writer io.Writer, opts ...Option) Logger {
+	o := options{level: slog.LevelInfo, format: FormatJSON}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	handlerOptions := &slog.HandlerOptions{Level: o.level}
+	var handler slog.Handler
+	if o.format == FormatText {
+		handler = slog.NewTextHandler(writer, handlerOptions)
+	} else {
+		handler = slog.NewJSONHandler(writer, handlerOptions)
+	}
 	return &SlogLogger{
 		logger: slog.New(handler),
 	}
@@ -72,6 +176,10 @@ func (l *SlogLogger) Error(msg string, keysAndValues ...interface{}) {
 	l.logger.Error(msg, keysAndValues...)
 }
 
+func (l *SlogLogger) With(keysAndValues ...interface{}) Logger {
+	return &SlogLogger{logger: l.logger.With(keysAndValues...)}
+}
+
 func (l *SlogLogger) Log(level slog.Level, msg string, keysAndValues ...interface{}) {
 	if l != nil && l.logger != nil {
 		l.logger.Log(context.Background(), level, msg, keysAndValues...)
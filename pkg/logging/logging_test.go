@@ -0,0 +1,79 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/YuarenArt/marketgo/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func logAll(logger Logger) {
+	logger.Debug("debug message")
+	logger.Info("info message")
+	logger.Warn("warn message")
+	logger.Error("error message")
+}
+
+func TestLevelFiltering(t *testing.T) {
+	tests := []struct {
+		level string
+		want  []string
+	}{
+		{"debug", []string{"debug message", "info message", "warn message", "error message"}},
+		{"", []string{"info message", "warn message", "error message"}},
+		{"WARN", []string{"warn message", "error message"}},
+		{"error", []string{"error message"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.level, func(t *testing.T) {
+			var buf bytes.Buffer
+			logAll(NewWriterLogger(&buf, WithConfig(&config.Config{LogLevel: tt.level})))
+
+			var got []string
+			for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+				var entry map[string]any
+				require.NoError(t, json.Unmarshal([]byte(line), &entry), line)
+				got = append(got, entry["msg"].(string))
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestTextFormat(t *testing.T) {
+	var buf bytes.Buffer
+	NewWriterLogger(&buf, WithFormat(FormatText), WithLevel(slog.LevelDebug)).Debug("request", "path", "/ads")
+	assert.Contains(t, buf.String(), "level=DEBUG")
+	assert.Contains(t, buf.String(), "msg=request path=/ads")
+}
+
+func TestWith(t *testing.T) {
+	var buf bytes.Buffer
+	base := NewWriterLogger(&buf)
+	base.With("request_id", "r1").With("user_id", 7).Info("request handled", "status", 200)
+	base.Info("unbound")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &entry))
+	assert.Equal(t, "r1", entry["request_id"])
+	assert.EqualValues(t, 7, entry["user_id"])
+	assert.EqualValues(t, 200, entry["status"])
+	assert.NotContains(t, lines[1], "request_id", "With must not change the parent logger")
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Validate(&config.Config{}))
+	assert.NoError(t, Validate(&config.Config{LogLevel: "debug", LogFormat: "text"}))
+
+	err := Validate(&config.Config{LogLevel: "verbose"})
+	assert.ErrorContains(t, err, "LOG_LEVEL")
+	err = Validate(&config.Config{LogFormat: "xml"})
+	assert.ErrorContains(t, err, "LOG_FORMAT")
+}
@@ -0,0 +1,282 @@
+package logging
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/YuarenArt/marketgo/internal/config"
+)
+
+// backupTimeFormat - отметка времени в имени ротированного файла: api-20240301T120000.000000000.log
+const backupTimeFormat = "20060102T150405.000000000"
+
+// RotateOptions задаёт ротацию файла журнала
+type RotateOptions struct {
+	// MaxSize - размер файла в байтах, после которого он ротируется; 0 - без ротации
+	MaxSize int64
+	// MaxBackups - сколько ротированных файлов хранить; 0 - без ограничения
+	MaxBackups int
+	// MaxAge - сколько хранить ротированные файлы; 0 - без ограничения
+	MaxAge time.Duration
+	// Compress сжимает ротированные файлы gzip
+	Compress bool
+}
+
+// RotationFromConfig переводит настройки API_LOG_* в RotateOptions
+func RotationFromConfig(cfg config.APILogConfig) RotateOptions {
+	return RotateOptions{
+		MaxSize:    cfg.MaxSizeMB << 20,
+		MaxBackups: int(cfg.MaxBackups),
+		MaxAge:     cfg.MaxAge,
+		Compress:   cfg.Compress,
+	}
+}
+
+// RotatingWriter пишет в файл и, когда запись превысила бы MaxSize, переименовывает его
+// в api-<время>.log рядом с исходным и начинает новый. Сжатие и удаление старых копий
+// выполняются в фоне. Безопасен для одновременной записи из нескольких горутин.
+type RotatingWriter struct {
+	path string
+	opts RotateOptions
+	now  func() time.Time
+
+	mu     sync.Mutex
+	file   *os.File
+	size   int64
+	closed bool
+
+	// millMu упорядочивает фоновую обработку копий, millWG позволяет Close дождаться её
+	millMu sync.Mutex
+	millWG sync.WaitGroup
+}
+
+// NewRotatingWriter открывает path на дозапись, создавая каталог при необходимости
+func NewRotatingWriter(path string, opts RotateOptions) (*RotatingWriter, error) {
+	w := &RotatingWriter{path: path, opts: opts, now: time.Now}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("create log directory: %w", err)
+	}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Write записывает p целиком в текущий файл, предварительно ротируя его при превышении MaxSize
+func (w *RotatingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return 0, os.ErrClosed
+	}
+	if w.opts.MaxSize > 0 && w.size > 0 && w.size+int64(len(p)) > w.opts.MaxSize {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+// Sync сбрасывает текущий файл на диск
+func (w *RotatingWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return nil
+	}
+	return w.file.Sync()
+}
+
+// Close сбрасывает и закрывает файл и дожидается фонового сжатия и удаления копий.
+// Повторный вызов ничего не делает.
+func (w *RotatingWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	err := w.file.Sync()
+	if closeErr := w.file.Close(); err == nil {
+		err = closeErr
+	}
+	w.mu.Unlock()
+
+	w.millWG.Wait()
+	return err
+}
+
+func (w *RotatingWriter) open() error {
+	file, err := os.OpenFile(w.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("stat log file: %w", err)
+	}
+	w.file, w.size = file, info.Size()
+	return nil
+}
+
+// rotate переименовывает текущий файл в копию и открывает новый; вызывается под mu
+func (w *RotatingWriter) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return fmt.Errorf("close log file: %w", err)
+	}
+	backup := w.backupName(w.now().UTC())
+	if err := os.Rename(w.path, backup); err != nil {
+		return fmt.Errorf("rotate log file: %w", err)
+	}
+	if err := w.open(); err != nil {
+		return err
+	}
+
+	w.millWG.Add(1)
+	go func() {
+		defer w.millWG.Done()
+		w.mill()
+	}()
+	return nil
+}
+
+// backupName возвращает свободное имя копии для момента t
+func (w *RotatingWriter) backupName(t time.Time) string {
+	prefix, ext := w.backupParts()
+	for {
+		name := filepath.Join(filepath.Dir(w.path), prefix+t.Format(backupTimeFormat)+ext)
+		if _, err := os.Stat(name); os.IsNotExist(err) {
+			if _, err := os.Stat(name + ".gz"); os.IsNotExist(err) {
+				return name
+			}
+		}
+		t = t.Add(time.Nanosecond)
+	}
+}
+
+// backupParts возвращает префикс и расширение имён копий: "api-" и ".log" для api.log
+func (w *RotatingWriter) backupParts() (string, string) {
+	base := filepath.Base(w.path)
+	ext := filepath.Ext(base)
+	return strings.TrimSuffix(base, ext) + "-", ext
+}
+
+// mill удаляет копии сверх MaxBackups и старше MaxAge и сжимает оставшиеся.
+// Обрабатывает все копии сразу, поэтому порядок запуска фоновых вызовов не важен.
+func (w *RotatingWriter) mill() {
+	w.millMu.Lock()
+	defer w.millMu.Unlock()
+
+	files, err := w.backups()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "logging: list rotated logs: %v\n", err)
+		return
+	}
+	files, err = w.removeOld(files)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "logging: remove old logs: %v\n", err)
+	}
+	if !w.opts.Compress {
+		return
+	}
+	for _, file := range files {
+		if strings.HasSuffix(file.path, ".gz") {
+			continue
+		}
+		if err := compressFile(file.path); err != nil {
+			fmt.Fprintf(os.Stderr, "logging: compress %s: %v\n", file.path, err)
+		}
+	}
+}
+
+type backupFile struct {
+	path string
+	time time.Time
+}
+
+// backups возвращает ротированные копии от новых к старым
+func (w *RotatingWriter) backups() ([]backupFile, error) {
+	dir := filepath.Dir(w.path)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix, ext := w.backupParts()
+	var files []backupFile
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), ".gz")
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+			continue
+		}
+		t, err := time.Parse(backupTimeFormat, strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext))
+		if err != nil {
+			continue
+		}
+		files = append(files, backupFile{path: filepath.Join(dir, entry.Name()), time: t})
+	}
+	slices.SortFunc(files, func(a, b backupFile) int { return b.time.Compare(a.time) })
+	return files, nil
+}
+
+// removeOld удаляет из files копии сверх MaxBackups и старше MaxAge и возвращает оставшиеся
+func (w *RotatingWriter) removeOld(files []backupFile) ([]backupFile, error) {
+	cutoff := w.now().Add(-w.opts.MaxAge)
+	var kept []backupFile
+	var errs []error
+	for i, file := range files {
+		tooMany := w.opts.MaxBackups > 0 && i >= w.opts.MaxBackups
+		tooOld := w.opts.MaxAge > 0 && file.time.Before(cutoff)
+		if !tooMany && !tooOld {
+			kept = append(kept, file)
+			continue
+		}
+		if err := os.Remove(file.path); err != nil && !os.IsNotExist(err) {
+			errs = append(errs, err)
+		}
+	}
+	return kept, errors.Join(errs...)
+}
+
+// compressFile сжимает path в path.gz и удаляет исходный файл
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(dst)
+	if _, err := io.Copy(gz, src); err != nil {
+		dst.Close()
+		os.Remove(dst.Name())
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		dst.Close()
+		os.Remove(dst.Name())
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(dst.Name())
+		return err
+	}
+	src.Close()
+	return os.Remove(path)
+}
@@ -0,0 +1,155 @@
+package logging
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readLines возвращает строки файла журнала, распаковывая копии .gz
+func readLines(t *testing.T, path string) []string {
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var r io.Reader = file
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(file)
+		require.NoError(t, err)
+		defer gz.Close()
+		r = gz
+	}
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	require.NoError(t, scanner.Err())
+	return lines
+}
+
+func backupFiles(t *testing.T, dir string) []string {
+	matches, err := filepath.Glob(filepath.Join(dir, "api-*.log*"))
+	require.NoError(t, err)
+	return matches
+}
+
+func TestRotatingWriter(t *testing.T) {
+	line := strings.Repeat("x", 39) + "\n"
+
+	t.Run("rotates past max size", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "api.log")
+		w, err := NewRotatingWriter(path, RotateOptions{MaxSize: 100})
+		require.NoError(t, err)
+
+		for i := 0; i < 3; i++ {
+			_, err := io.WriteString(w, line)
+			require.NoError(t, err)
+		}
+		require.NoError(t, w.Close())
+
+		backups := backupFiles(t, dir)
+		require.Len(t, backups, 1)
+		assert.Len(t, readLines(t, backups[0]), 2)
+		assert.Len(t, readLines(t, path), 1)
+	})
+
+	t.Run("compresses and prunes backups", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "api.log")
+		w, err := NewRotatingWriter(path, RotateOptions{MaxSize: 50, MaxBackups: 2, Compress: true})
+		require.NoError(t, err)
+
+		for i := 0; i < 6; i++ {
+			_, err := fmt.Fprintf(w, "%038d\n", i)
+			require.NoError(t, err)
+		}
+		require.NoError(t, w.Close())
+
+		backups := backupFiles(t, dir)
+		require.Len(t, backups, 2)
+		for _, backup := range backups {
+			assert.True(t, strings.HasSuffix(backup, ".log.gz"), backup)
+		}
+		assert.Equal(t, []string{fmt.Sprintf("%038d", 3)}, readLines(t, backups[0]))
+		assert.Equal(t, []string{fmt.Sprintf("%038d", 4)}, readLines(t, backups[1]))
+		assert.Equal(t, []string{fmt.Sprintf("%038d", 5)}, readLines(t, path))
+	})
+
+	t.Run("removes backups older than max age", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "api.log")
+		now := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+		old := filepath.Join(dir, "api-"+now.Add(-48*time.Hour).Format(backupTimeFormat)+".log")
+		recent := filepath.Join(dir, "api-"+now.Add(-time.Hour).Format(backupTimeFormat)+".log")
+		unrelated := filepath.Join(dir, "api-notes.log")
+		for _, name := range []string{old, recent, unrelated} {
+			require.NoError(t, os.WriteFile(name, []byte(line), 0o644))
+		}
+
+		w, err := NewRotatingWriter(path, RotateOptions{MaxSize: 50, MaxAge: 24 * time.Hour})
+		require.NoError(t, err)
+		w.now = func() time.Time { return now }
+		for i := 0; i < 2; i++ {
+			_, err := io.WriteString(w, line)
+			require.NoError(t, err)
+		}
+		require.NoError(t, w.Close())
+
+		assert.NoFileExists(t, old)
+		assert.FileExists(t, recent)
+		assert.FileExists(t, unrelated)
+		assert.FileExists(t, filepath.Join(dir, "api-"+now.Format(backupTimeFormat)+".log"))
+	})
+
+	t.Run("concurrent writes keep lines intact", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "api.log")
+		w, err := NewRotatingWriter(path, RotateOptions{MaxSize: 1000})
+		require.NoError(t, err)
+		logger := NewWriterLogger(w)
+
+		var wg sync.WaitGroup
+		for g := 0; g < 8; g++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := 0; i < 50; i++ {
+					logger.Info("HTTP request", "goroutine", g, "i", i)
+				}
+			}()
+		}
+		wg.Wait()
+		require.NoError(t, w.Close())
+
+		total := len(readLines(t, path))
+		for _, backup := range backupFiles(t, dir) {
+			for _, l := range readLines(t, backup) {
+				assert.True(t, strings.HasPrefix(l, "{") && strings.HasSuffix(l, "}"), l)
+			}
+			total += len(readLines(t, backup))
+		}
+		assert.Equal(t, 8*50, total)
+		assert.NotEmpty(t, backupFiles(t, dir))
+	})
+
+	t.Run("write after close fails", func(t *testing.T) {
+		w, err := NewRotatingWriter(filepath.Join(t.TempDir(), "logs", "api.log"), RotateOptions{})
+		require.NoError(t, err)
+		require.NoError(t, w.Close())
+		require.NoError(t, w.Close())
+		_, err = io.WriteString(w, line)
+		assert.ErrorIs(t, err, os.ErrClosed)
+	})
+}
@@ -2,8 +2,10 @@ package metrics
 
 import (
 	"fmt"
+	"github.com/YuarenArt/marketgo/pkg/buildinfo"
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"time"
 )
@@ -13,10 +15,59 @@ type Metrics struct {
 	RequestDuration *prometheus.HistogramVec
 	RequestCount    *prometheus.CounterVec
 	ErrorCount      *prometheus.CounterVec
+	// BotProtectionCount - запросы, замедленные, заблокированные или получившие требование CAPTCHA
+	BotProtectionCount *prometheus.CounterVec
+	// LoginFailureCount - неудачные попытки входа по причине: неверные данные или временная блокировка
+	LoginFailureCount *prometheus.CounterVec
+	// DBRetriesCount - повторы читающих запросов к базе данных после временных ошибок
+	DBRetriesCount prometheus.Counter
+	// AdsCacheHits и AdsCacheMisses - обращения к кэшу страниц списка объявлений
+	AdsCacheHits   prometheus.Counter
+	AdsCacheMisses prometheus.Counter
+	// UsersRegistered - успешные регистрации пользователей
+	UsersRegistered prometheus.Counter
+	// AdsCreated и AdsCreateFailures - созданные объявления и неудачные попытки создания по причине
+	AdsCreated        prometheus.Counter
+	AdsCreateFailures *prometheus.CounterVec
+	// AdsExpired - объявления, переведённые в архив по истечении срока публикации
+	AdsExpired prometheus.Counter
+	// LoginCount - попытки входа по результату: success, invalid_credentials, locked, error
+	LoginCount *prometheus.CounterVec
+	// BuildInfo - постоянная метрика со значением 1, метки которой описывают сборку сервера
+	BuildInfo *prometheus.GaugeVec
+
+	gatherer prometheus.Gatherer
+}
+
+// UnmatchedPath - значение метки path для запросов, не совпавших ни с одним маршрутом
+const UnmatchedPath = "unmatched"
+
+// NewMetrics инициализирует метрики Prometheus и регистрирует их в reg.
+// При reg == nil создаётся отдельный реестр со стандартными метриками Go и процесса,
+// поэтому несколько экземпляров Metrics (например, серверов в тестах) не конфликтуют.
+// Gatherer возвращает reg, если он реализует prometheus.Gatherer (как *prometheus.Registry).
+func NewMetrics(reg prometheus.Registerer) *Metrics {
+	m := newMetrics()
+	if reg == nil {
+		registry := prometheus.NewRegistry()
+		registry.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+		reg = registry
+	}
+	m.gatherer = prometheus.DefaultGatherer
+	if g, ok := reg.(prometheus.Gatherer); ok {
+		m.gatherer = g
+	}
+
+	reg.MustRegister(
+		m.RequestDuration, m.RequestCount, m.ErrorCount, m.BotProtectionCount, m.LoginFailureCount, m.DBRetriesCount,
+		m.AdsCacheHits, m.AdsCacheMisses, m.UsersRegistered, m.AdsCreated, m.AdsCreateFailures, m.AdsExpired, m.LoginCount,
+		m.BuildInfo,
+	)
+	return m
 }
 
-// NewMetrics инициализирует метрики Prometheus
-func NewMetrics() *Metrics {
+// newMetrics создаёт метрики без регистрации
+func newMetrics() *Metrics {
 	m := &Metrics{
 		RequestDuration: prometheus.NewHistogramVec(
 			prometheus.HistogramOpts{
@@ -40,19 +91,105 @@ func NewMetrics() *Metrics {
 			},
 			[]string{"method", "path", "status"},
 		),
+		BotProtectionCount: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "bot_protection_total",
+				Help: "Количество запросов, обработанных защитой от ботов, по действию",
+			},
+			[]string{"action"},
+		),
+		LoginFailureCount: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "login_failures_total",
+				Help: "Количество неудачных попыток входа по причине",
+			},
+			[]string{"reason"},
+		),
+		DBRetriesCount: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Name: "db_retries_total",
+				Help: "Количество повторов запросов к базе данных после временных ошибок",
+			},
+		),
+		AdsCacheHits: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Name: "ads_cache_hits_total",
+				Help: "Количество страниц списка объявлений, отданных из кэша",
+			},
+		),
+		AdsCacheMisses: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Name: "ads_cache_misses_total",
+				Help: "Количество страниц списка объявлений, загруженных из базы данных мимо кэша",
+			},
+		),
+		UsersRegistered: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Name: "users_registered_total",
+				Help: "Количество зарегистрированных пользователей",
+			},
+		),
+		AdsCreated: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Name: "ads_created_total",
+				Help: "Количество созданных объявлений",
+			},
+		),
+		AdsCreateFailures: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "ads_create_failures_total",
+				Help: "Количество неудачных попыток создать объявление по причине",
+			},
+			[]string{"reason"},
+		),
+		AdsExpired: prometheus.NewCounter(
+			prometheus.CounterOpts{
+				Name: "ads_expired_total",
+				Help: "Количество объявлений, архивированных по истечении срока публикации",
+			},
+		),
+		LoginCount: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "logins_total",
+				Help: "Количество попыток входа по результату",
+			},
+			[]string{"result"},
+		),
+		BuildInfo: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "marketgo_build_info",
+				Help: "Сведения о сборке сервера; значение всегда 1",
+			},
+			[]string{"version", "commit", "build_date", "go_version"},
+		),
 	}
-
-	// Регистрация метрик в Prometheus
-	prometheus.MustRegister(m.RequestDuration, m.RequestCount, m.ErrorCount)
+	info := buildinfo.Get()
+	m.BuildInfo.WithLabelValues(info.Version, info.Commit, info.BuildDate, info.GoVersion).Set(1)
 	return m
 }
 
-// Middleware возвращает middleware для сбора метрик Prometheus
-func (m *Metrics) Middleware() gin.HandlerFunc {
+// Middleware возвращает middleware для сбора метрик Prometheus.
+// Метка path - шаблон маршрута (/ads/:id), а не адрес запроса, чтобы число серий не росло
+// с каждым ID; запросы без маршрута учитываются под UnmatchedPath.
+// Запросы к skipPaths не учитываются, чтобы частые служебные проверки не засоряли метрики.
+func (m *Metrics) Middleware(skipPaths ...string) gin.HandlerFunc {
+	skip := make(map[string]bool, len(skipPaths))
+	for _, path := range skipPaths {
+		skip[path] = true
+	}
+
 	return func(c *gin.Context) {
+		if skip[c.Request.URL.Path] {
+			c.Next()
+			return
+		}
+
 		start := time.Now()
 		method := c.Request.Method
-		path := c.Request.URL.Path
+		path := c.FullPath()
+		if path == "" {
+			path = UnmatchedPath
+		}
 
 		c.Next()
 
@@ -67,7 +204,12 @@ func (m *Metrics) Middleware() gin.HandlerFunc {
 	}
 }
 
-// Handler возвращает обработчик для эндпоинта Prometheus
-func Handler() gin.HandlerFunc {
-	return gin.WrapH(promhttp.Handler())
+// Gatherer возвращает источник метрик для Handler
+func (m *Metrics) Gatherer() prometheus.Gatherer {
+	return m.gatherer
+}
+
+// Handler возвращает обработчик для эндпоинта Prometheus, отдающий метрики из g
+func Handler(g prometheus.Gatherer) gin.HandlerFunc {
+	return gin.WrapH(promhttp.HandlerFor(g, promhttp.HandlerOpts{}))
 }
@@ -0,0 +1,30 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddlewareRouteLabels(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	m := newMetrics()
+
+	router := gin.New()
+	router.Use(m.Middleware("/healthz"))
+	router.GET("/ads/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.GET("/healthz", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	for _, path := range []string{"/ads/1", "/ads/2", "/wp-login.php", "/.env", "/healthz"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	assert.Equal(t, 2, testutil.CollectAndCount(m.RequestCount), "one series per route and one for unmatched")
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.RequestCount.WithLabelValues(http.MethodGet, "/ads/:id", "200")))
+	assert.Equal(t, 2.0, testutil.ToFloat64(m.RequestCount.WithLabelValues(http.MethodGet, UnmatchedPath, "404")))
+	assert.Equal(t, 1, testutil.CollectAndCount(m.ErrorCount))
+}
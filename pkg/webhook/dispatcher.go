@@ -0,0 +1,237 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/YuarenArt/marketgo/pkg/logging"
+)
+
+const (
+	DefaultQueueSize      = 1000
+	DefaultMaxAttempts    = 5
+	DefaultRetryBaseDelay = time.Second
+	DefaultTimeout        = 5 * time.Second
+)
+
+var (
+	ErrQueueFull = errors.New("webhook queue is full")
+	ErrClosed    = errors.New("webhook dispatcher is closed")
+)
+
+// Config содержит адреса получателей и параметры доставки
+type Config struct {
+	URLs   []string
+	Secret string
+	// QueueSize - сколько событий может ждать отправки; при переполнении новые события отбрасываются
+	QueueSize int
+	// MaxAttempts - сколько раз пытаться доставить событие на один адрес
+	MaxAttempts int
+	// RetryBaseDelay - пауза перед второй попыткой; перед каждой следующей она удваивается
+	RetryBaseDelay time.Duration
+	// Timeout - ограничение времени одного запроса
+	Timeout time.Duration
+}
+
+// Dispatcher отправляет события на все адреса из Config в фоновом обработчике.
+// Publish только ставит событие в очередь, поэтому не задерживает запрос, в котором оно возникло.
+// События доставляются по одному в порядке публикации.
+type Dispatcher struct {
+	cfg    Config
+	client *http.Client
+	logger logging.Logger
+
+	mu      sync.RWMutex
+	events  chan Event
+	started bool
+	closed  bool
+	done    chan struct{}
+
+	// ctx отменяется, если Close не дождался доставки: оставшиеся события сразу уходят в dead letter
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// NewDispatcher создаёт Dispatcher; нулевые параметры заменяются значениями по умолчанию.
+// Отправка начинается после Start.
+func NewDispatcher(cfg Config, logger logging.Logger) *Dispatcher {
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = DefaultQueueSize
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = DefaultMaxAttempts
+	}
+	if cfg.RetryBaseDelay <= 0 {
+		cfg.RetryBaseDelay = DefaultRetryBaseDelay
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	if logger == nil {
+		logger = logging.NewLogger(nil)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Dispatcher{
+		cfg:    cfg,
+		client: &http.Client{},
+		logger: logger,
+		events: make(chan Event, cfg.QueueSize),
+		done:   make(chan struct{}),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Publish ставит событие eventType с данными data в очередь без блокировки.
+// При переполненной очереди возвращает ErrQueueFull, после Close - ErrClosed.
+func (d *Dispatcher) Publish(eventType string, data any) error {
+	ev, err := NewEvent(eventType, data)
+	if err != nil {
+		return fmt.Errorf("encode webhook event: %w", err)
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.closed {
+		return ErrClosed
+	}
+	select {
+	case d.events <- ev:
+		return nil
+	default:
+		d.logger.Warn("Webhook queue is full, event dropped", "event_id", ev.ID, "event", ev.Type)
+		return ErrQueueFull
+	}
+}
+
+// Start запускает фоновый обработчик очереди; повторный вызов ничего не делает
+func (d *Dispatcher) Start() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.started || d.closed {
+		return
+	}
+	d.started = true
+	go d.run()
+}
+
+// Close перестаёт принимать события и ждёт, пока обработчик доставит очередь.
+// Если ctx истекает раньше, текущие повторы прерываются, а недоставленные события
+// записываются в журнал как dead letter. Вызывается после остановки HTTP-сервера,
+// когда новые события уже не публикуются.
+func (d *Dispatcher) Close(ctx context.Context) error {
+	d.mu.Lock()
+	started := d.started
+	if !d.closed {
+		d.closed = true
+		close(d.events)
+	}
+	d.mu.Unlock()
+
+	if !started {
+		d.cancel()
+		return nil
+	}
+	select {
+	case <-d.done:
+		d.cancel()
+		return nil
+	case <-ctx.Done():
+		d.cancel()
+		<-d.done
+		return ctx.Err()
+	}
+}
+
+func (d *Dispatcher) run() {
+	defer close(d.done)
+	for ev := range d.events {
+		d.dispatch(ev)
+	}
+}
+
+// dispatch отправляет событие на все адреса; неудачная доставка на один адрес не влияет на остальные
+func (d *Dispatcher) dispatch(ev Event) {
+	body, err := json.Marshal(ev)
+	if err != nil {
+		d.logger.Error("Webhook event encoding failed", "event_id", ev.ID, "event", ev.Type, "error", err)
+		return
+	}
+	signature := Sign(d.cfg.Secret, body)
+
+	for _, url := range d.cfg.URLs {
+		attempts, err := d.deliver(url, ev, body, signature)
+		if err != nil {
+			// Dead letter: тело события сохраняется в журнале для ручной повторной отправки
+			d.logger.Error("Webhook delivery failed", "event_id", ev.ID, "event", ev.Type, "url", url,
+				"attempts", attempts, "error", err, "payload", string(body))
+			continue
+		}
+		d.logger.Debug("Webhook delivered", "event_id", ev.ID, "event", ev.Type, "url", url, "attempts", attempts)
+	}
+}
+
+// deliver отправляет событие на url до MaxAttempts раз с экспоненциальной паузой между попытками
+// и возвращает число сделанных попыток. Ответы 4xx, кроме 408 и 429, не повторяются.
+func (d *Dispatcher) deliver(url string, ev Event, body []byte, signature string) (int, error) {
+	delay := d.cfg.RetryBaseDelay
+	var err error
+	for attempt := 1; attempt <= d.cfg.MaxAttempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-d.ctx.Done():
+				return attempt - 1, fmt.Errorf("%w (last error: %v)", d.ctx.Err(), err)
+			case <-time.After(delay):
+			}
+			delay *= 2
+		}
+
+		var retry bool
+		retry, err = d.post(url, ev, body, signature)
+		if err == nil {
+			return attempt, nil
+		}
+		if !retry {
+			return attempt, err
+		}
+	}
+	return d.cfg.MaxAttempts, err
+}
+
+// post выполняет одну попытку доставки и сообщает, стоит ли её повторить
+func (d *Dispatcher) post(url string, ev Event, body []byte, signature string) (bool, error) {
+	ctx, cancel := context.WithTimeout(d.ctx, d.cfg.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, signature)
+	req.Header.Set(EventHeader, ev.Type)
+	req.Header.Set(DeliveryHeader, ev.ID)
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return d.ctx.Err() == nil, err
+	}
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusTooManyRequests:
+		return true, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	case resp.StatusCode >= 400 && resp.StatusCode < 500:
+		return false, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	default:
+		return true, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+}
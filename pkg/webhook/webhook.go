@@ -0,0 +1,96 @@
+// Package webhook отправляет события маркетплейса на внешние адреса и проверяет их подпись.
+//
+// Каждое событие - JSON-объект Event в теле POST-запроса. Тело подписывается HMAC-SHA256
+// общим секретом, подпись передаётся в заголовке SignatureHeader в виде "sha256=<hex>".
+// Получатель проверяет её через Verify до разбора тела.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Типы событий жизненного цикла объявлений
+const (
+	EventAdCreated = "ad.created"
+	EventAdUpdated = "ad.updated"
+	EventAdDeleted = "ad.deleted"
+)
+
+// Заголовки запроса с событием
+const (
+	// SignatureHeader - подпись тела запроса, "sha256=" и HMAC-SHA256 в hex
+	SignatureHeader = "X-Marketgo-Signature"
+	// EventHeader - тип события, совпадает с полем type
+	EventHeader = "X-Marketgo-Event"
+	// DeliveryHeader - ID события, совпадает с полем id; одинаков у повторных попыток
+	DeliveryHeader = "X-Marketgo-Delivery"
+
+	signaturePrefix = "sha256="
+)
+
+// ErrInvalidSignature возвращается Verify, если подпись отсутствует или не совпадает
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// Event - тело запроса с событием. Data зависит от типа события.
+type Event struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// NewEvent создаёт событие eventType со случайным ID и данными data в JSON
+func NewEvent(eventType string, data any) (Event, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return Event{}, err
+	}
+	return Event{
+		ID:        newEventID(),
+		Type:      eventType,
+		CreatedAt: time.Now().UTC(),
+		Data:      raw,
+	}, nil
+}
+
+// Sign возвращает значение заголовка SignatureHeader для тела body
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify проверяет, что signature из заголовка SignatureHeader подписывает body секретом secret.
+// Тело нужно передавать в том виде, в каком оно получено, до разбора JSON.
+func Verify(secret string, body []byte, signature string) error {
+	sum, ok := strings.CutPrefix(signature, signaturePrefix)
+	if !ok {
+		return ErrInvalidSignature
+	}
+	got, err := hex.DecodeString(sum)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	if !hmac.Equal(got, mac.Sum(nil)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// newEventID генерирует случайный ID события
+func newEventID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,230 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/YuarenArt/marketgo/pkg/logging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSecret = "webhook-secret"
+
+// receiver - получатель событий, отвечающий статусами из statuses по очереди, затем 204
+type receiver struct {
+	t        *testing.T
+	statuses []int
+
+	mu       sync.Mutex
+	requests int
+	events   []Event
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, err := io.ReadAll(req.Body)
+	require.NoError(r.t, err)
+	assert.NoError(r.t, Verify(testSecret, body, req.Header.Get(SignatureHeader)))
+	assert.Equal(r.t, "application/json", req.Header.Get("Content-Type"))
+
+	var ev Event
+	require.NoError(r.t, json.Unmarshal(body, &ev))
+	assert.Equal(r.t, ev.Type, req.Header.Get(EventHeader))
+	assert.Equal(r.t, ev.ID, req.Header.Get(DeliveryHeader))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	status := http.StatusNoContent
+	if r.requests < len(r.statuses) {
+		status = r.statuses[r.requests]
+	}
+	r.requests++
+	if status < 300 {
+		r.events = append(r.events, ev)
+	}
+	w.WriteHeader(status)
+}
+
+func (r *receiver) delivered() ([]Event, int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Event(nil), r.events...), r.requests
+}
+
+// syncBuffer - буфер журнала, в который пишет обработчик очереди
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func newTestDispatcher(t *testing.T, urls ...string) (*Dispatcher, *syncBuffer) {
+	var logs syncBuffer
+	d := NewDispatcher(Config{
+		URLs:           urls,
+		Secret:         testSecret,
+		MaxAttempts:    3,
+		RetryBaseDelay: time.Millisecond,
+		Timeout:        time.Second,
+	}, logging.NewWriterLogger(&logs))
+	return d, &logs
+}
+
+func TestSignAndVerify(t *testing.T) {
+	body := []byte(`{"id":"1","type":"ad.created"}`)
+	signature := Sign(testSecret, body)
+	assert.Regexp(t, `^sha256=[0-9a-f]{64}$`, signature)
+	assert.NoError(t, Verify(testSecret, body, signature))
+
+	assert.ErrorIs(t, Verify("other-secret", body, signature), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify(testSecret, []byte(`{"id":"2","type":"ad.created"}`), signature), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify(testSecret, body, signature[len("sha256="):]), ErrInvalidSignature, "prefix is required")
+	assert.ErrorIs(t, Verify(testSecret, body, "sha256=zz"), ErrInvalidSignature)
+	assert.ErrorIs(t, Verify(testSecret, body, ""), ErrInvalidSignature)
+}
+
+func TestDispatcherDelivery(t *testing.T) {
+	t.Run("events are signed and delivered to every url in order", func(t *testing.T) {
+		first := &receiver{t: t}
+		second := &receiver{t: t}
+		srv1 := httptest.NewServer(first)
+		defer srv1.Close()
+		srv2 := httptest.NewServer(second)
+		defer srv2.Close()
+
+		d, _ := newTestDispatcher(t, srv1.URL, srv2.URL)
+		d.Start()
+		require.NoError(t, d.Publish(EventAdCreated, map[string]int{"id": 1}))
+		require.NoError(t, d.Publish(EventAdDeleted, map[string]int{"id": 1}))
+		require.NoError(t, d.Close(context.Background()))
+
+		for _, r := range []*receiver{first, second} {
+			events, _ := r.delivered()
+			require.Len(t, events, 2)
+			assert.Equal(t, EventAdCreated, events[0].Type)
+			assert.Equal(t, EventAdDeleted, events[1].Type)
+			assert.JSONEq(t, `{"id":1}`, string(events[0].Data))
+			assert.NotEqual(t, events[0].ID, events[1].ID)
+		}
+	})
+
+	t.Run("server errors are retried with the same delivery id", func(t *testing.T) {
+		r := &receiver{t: t, statuses: []int{http.StatusInternalServerError, http.StatusTooManyRequests}}
+		srv := httptest.NewServer(r)
+		defer srv.Close()
+
+		d, logs := newTestDispatcher(t, srv.URL)
+		d.Start()
+		require.NoError(t, d.Publish(EventAdUpdated, map[string]int{"id": 2}))
+		require.NoError(t, d.Close(context.Background()))
+
+		events, requests := r.delivered()
+		assert.Equal(t, 3, requests)
+		require.Len(t, events, 1)
+		assert.NotContains(t, logs.String(), "Webhook delivery failed")
+	})
+
+	t.Run("gives up after max attempts and logs a dead letter", func(t *testing.T) {
+		r := &receiver{t: t, statuses: []int{502, 502, 502, 502}}
+		srv := httptest.NewServer(r)
+		defer srv.Close()
+
+		d, logs := newTestDispatcher(t, srv.URL)
+		d.Start()
+		require.NoError(t, d.Publish(EventAdCreated, map[string]string{"title": "Велосипед"}))
+		require.NoError(t, d.Close(context.Background()))
+
+		_, requests := r.delivered()
+		assert.Equal(t, 3, requests)
+		assert.Contains(t, logs.String(), "Webhook delivery failed")
+		assert.Contains(t, logs.String(), `"attempts":3`)
+		assert.Contains(t, logs.String(), "Велосипед", "payload is kept for replay")
+	})
+
+	t.Run("client errors are not retried", func(t *testing.T) {
+		r := &receiver{t: t, statuses: []int{http.StatusBadRequest}}
+		srv := httptest.NewServer(r)
+		defer srv.Close()
+
+		d, logs := newTestDispatcher(t, srv.URL)
+		d.Start()
+		require.NoError(t, d.Publish(EventAdCreated, nil))
+		require.NoError(t, d.Close(context.Background()))
+
+		_, requests := r.delivered()
+		assert.Equal(t, 1, requests)
+		assert.Contains(t, logs.String(), "Webhook delivery failed")
+	})
+}
+
+func TestDispatcherShutdown(t *testing.T) {
+	t.Run("close drains queued events", func(t *testing.T) {
+		r := &receiver{t: t}
+		srv := httptest.NewServer(r)
+		defer srv.Close()
+
+		d, _ := newTestDispatcher(t, srv.URL)
+		for i := 0; i < 10; i++ {
+			require.NoError(t, d.Publish(EventAdCreated, map[string]int{"id": i}))
+		}
+		d.Start()
+		require.NoError(t, d.Close(context.Background()))
+
+		events, _ := r.delivered()
+		assert.Len(t, events, 10)
+		assert.ErrorIs(t, d.Publish(EventAdCreated, nil), ErrClosed)
+	})
+
+	t.Run("close deadline aborts retries", func(t *testing.T) {
+		var requests atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			requests.Add(1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer srv.Close()
+
+		var logs syncBuffer
+		d := NewDispatcher(Config{
+			URLs:           []string{srv.URL},
+			Secret:         testSecret,
+			MaxAttempts:    10,
+			RetryBaseDelay: time.Hour,
+		}, logging.NewWriterLogger(&logs))
+		d.Start()
+		require.NoError(t, d.Publish(EventAdCreated, nil))
+		require.NoError(t, d.Publish(EventAdUpdated, nil))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, d.Close(ctx), context.DeadlineExceeded)
+		assert.Equal(t, int32(1), requests.Load(), "second event is not sent after the deadline")
+		assert.Contains(t, logs.String(), EventAdCreated)
+		assert.Contains(t, logs.String(), EventAdUpdated)
+	})
+
+	t.Run("full queue drops events", func(t *testing.T) {
+		d := NewDispatcher(Config{QueueSize: 1}, logging.NewWriterLogger(io.Discard))
+		require.NoError(t, d.Publish(EventAdCreated, nil))
+		assert.ErrorIs(t, d.Publish(EventAdCreated, nil), ErrQueueFull)
+		require.NoError(t, d.Close(context.Background()), "close without start does not block")
+	})
+}